@@ -0,0 +1,62 @@
+package main
+
+import (
+	"encoding/json"
+	"log"
+	"time"
+
+	db "websocket-simple-chat-app/db/sqlc"
+	"websocket-simple-chat-app/hub"
+)
+
+// Delivery statuses of a private_message, as reported in its ack
+const (
+	ackStatusDelivered = "delivered" // Sent to the recipient's connections on this instance
+	ackStatusQueued    = "queued"    // The recipient disconnected moments ago: delivered when they reconnect
+	ackStatusStored    = "stored"    // The recipient is offline (or on another instance): fetched with GET /messages
+	ackStatusRejected  = "rejected"  // Invalid message, not stored. Retrying it unchanged fails again.
+	ackStatusFailed    = "failed"    // Server error, not stored. The client may retry.
+)
+
+// MessageAck confirms a private_message to the connection that sent it
+//
+//wsschema:server
+type MessageAck struct {
+	Type        string    `json:"type"`                    // "ack"
+	ClientMsgID string    `json:"client_msg_id,omitempty"` // Echoed from the private_message
+	Status      string    `json:"status"`
+	MessageID   int64     `json:"message_id,omitempty"` // Stored messages only
+	Error       string    `json:"error,omitempty"`      // Rejected and failed messages only
+	CreatedAt   time.Time `json:"created_at"`           // When the message was stored, or the time of the failure
+}
+
+// sendMessageAck acks a stored private message on the sending connection
+func sendMessageAck(client *hub.Client, clientMsgID string, storedMsg db.Message, status string) {
+	writeMessageAck(client, MessageAck{
+		Type:        "ack",
+		ClientMsgID: clientMsgID,
+		Status:      status,
+		MessageID:   storedMsg.ID,
+		CreatedAt:   storedMsg.CreatedAt,
+	})
+}
+
+// sendMessageNack tells the sending connection that a private message was not stored
+func sendMessageNack(client *hub.Client, clientMsgID string, status string, reason string) {
+	writeMessageAck(client, MessageAck{
+		Type:        "ack",
+		ClientMsgID: clientMsgID,
+		Status:      status,
+		Error:       reason,
+		CreatedAt:   time.Now().UTC(),
+	})
+}
+
+func writeMessageAck(client *hub.Client, ack MessageAck) {
+	jsonMsg, err := json.Marshal(ack)
+	if err != nil {
+		log.Printf("WS Error: Failed to marshal ack for user %d: %v", client.UserID, err)
+		return
+	}
+	client.Send(jsonMsg)
+}
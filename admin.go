@@ -0,0 +1,218 @@
+package main
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/csv"
+	"errors"
+	"io"
+	"log"
+	"math/big"
+	"net/http"
+	"slices"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+
+	db "websocket-simple-chat-app/db/sqlc"
+	"websocket-simple-chat-app/token"
+	"websocket-simple-chat-app/util/password"
+	"websocket-simple-chat-app/util/username"
+)
+
+// User roles
+const (
+	roleUser  = "user"
+	roleAdmin = "admin"
+)
+
+// Bulk import limits
+const (
+	maxImportRows             = 1000
+	temporaryPasswordLength   = 12
+	temporaryPasswordAlphabet = "abcdefghjkmnpqrstuvwxyzABCDEFGHJKMNPQRSTUVWXYZ23456789" // No look-alike characters
+)
+
+// --- Role Middleware ---
+
+// adminMiddleware only lets users with the admin role through. It must run after the authentication chain.
+func adminMiddleware(store *db.Queries) gin.HandlerFunc {
+	return roleMiddleware(store, roleAdmin)
+}
+
+// roleMiddleware only lets users with one of the given roles through. It must run after the authentication chain.
+// The role is read from the database so that role changes apply immediately.
+func roleMiddleware(store *db.Queries, roles ...string) gin.HandlerFunc {
+	required := strings.Join(roles, " or ") + " role required"
+	return func(ctx *gin.Context) {
+		payload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+
+		user, err := store.GetUserByID(context.Background(), payload.UserID)
+		if err != nil {
+			log.Printf("Error fetching user %d for role check: %v", payload.UserID, err)
+			ctx.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": required})
+			return
+		}
+		if !slices.Contains(roles, user.Role) || user.DeactivatedAt.Valid || accountSuspended(user) {
+			ctx.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": required})
+			return
+		}
+
+		ctx.Next()
+	}
+}
+
+// --- Bulk User Import ---
+
+// importUserRow is a single user to import
+type importUserRow struct {
+	Username string `json:"username"`
+	Password string `json:"password"` // Optional: a temporary password is generated when empty
+}
+
+// importUserResult is the outcome of importing a single row
+type importUserResult struct {
+	Row               int    `json:"row"` // 1-based position in the input
+	Username          string `json:"username"`
+	Status            string `json:"status"` // "created" or "error"
+	UserID            int32  `json:"user_id,omitempty"`
+	TemporaryPassword string `json:"temporary_password,omitempty"` // Only set when the password was generated
+	Error             string `json:"error,omitempty"`
+}
+
+// importUsersHandler creates accounts in bulk from a JSON body ({"users": [...]}) or a CSV body
+// (Content-Type: text/csv, columns: username[,password], optional header row).
+// Rows are independent: a failing row does not prevent the others from being created.
+func importUsersHandler(store *db.Queries, usernameRules username.Rules) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		// 1. Parse the rows
+		var rows []importUserRow
+		var err error
+		if strings.HasPrefix(c.ContentType(), "text/csv") {
+			rows, err = parseImportCSV(c.Request.Body)
+		} else {
+			var req struct {
+				Users []importUserRow `json:"users" binding:"required"`
+			}
+			err = c.ShouldBindJSON(&req)
+			rows = req.Users
+		}
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		if len(rows) == 0 || len(rows) > maxImportRows {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Import must contain between 1 and 1000 users"})
+			return
+		}
+
+		// 2. Create the accounts
+		results := make([]importUserResult, 0, len(rows))
+		created := 0
+		for i, row := range rows {
+			result := importUserResult{Row: i + 1, Username: strings.TrimSpace(row.Username), Status: "error"}
+
+			if result.Username == "" {
+				result.Error = "username is required"
+				results = append(results, result)
+				continue
+			}
+			if err := usernameRules.Validate(result.Username); err != nil {
+				result.Error = err.Error()
+				results = append(results, result)
+				continue
+			}
+
+			plaintext := row.Password
+			if plaintext == "" {
+				plaintext, err = generateTemporaryPassword()
+				if err != nil {
+					log.Printf("Error generating temporary password: %v", err)
+					result.Error = "failed to generate password"
+					results = append(results, result)
+					continue
+				}
+				result.TemporaryPassword = plaintext
+			}
+			hash, err := password.Hash(plaintext)
+			if err != nil {
+				result.TemporaryPassword = ""
+				if errors.Is(err, password.ErrTooLong) {
+					result.Error = "password is longer than 72 bytes"
+				} else {
+					log.Printf("Error hashing password of %q: %v", result.Username, err)
+					result.Error = "failed to create user"
+				}
+				results = append(results, result)
+				continue
+			}
+
+			user, err := store.CreateUser(context.Background(), db.CreateUserParams{
+				Username:     result.Username,
+				PasswordHash: hash,
+			})
+			if err != nil {
+				result.TemporaryPassword = ""
+				if db.IsUniqueViolation(err) {
+					result.Error = "username already exists"
+				} else {
+					log.Printf("Error importing user %q: %v", result.Username, err)
+					result.Error = "failed to create user"
+				}
+				results = append(results, result)
+				continue
+			}
+
+			result.Status = "created"
+			result.UserID = user.ID
+			results = append(results, result)
+			created++
+		}
+
+		log.Printf("Admin import: created %d of %d users", created, len(rows))
+		c.JSON(http.StatusOK, gin.H{"created": created, "failed": len(rows) - created, "results": results})
+	}
+}
+
+// parseImportCSV reads username[,password] records, skipping a "username" header row if present
+func parseImportCSV(r io.Reader) ([]importUserRow, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1 // Password column is optional
+	reader.TrimLeadingSpace = true
+
+	records, err := reader.ReadAll()
+	if err != nil {
+		return nil, err
+	}
+
+	rows := make([]importUserRow, 0, len(records))
+	for i, record := range records {
+		if i == 0 && len(record) > 0 && strings.EqualFold(strings.TrimSpace(record[0]), "username") {
+			continue // Header row
+		}
+
+		row := importUserRow{}
+		if len(record) > 0 {
+			row.Username = record[0]
+		}
+		if len(record) > 1 {
+			row.Password = record[1]
+		}
+		rows = append(rows, row)
+	}
+	return rows, nil
+}
+
+// generateTemporaryPassword returns a random password for imported accounts
+func generateTemporaryPassword() (string, error) {
+	alphabetSize := big.NewInt(int64(len(temporaryPasswordAlphabet)))
+	password := make([]byte, temporaryPasswordLength)
+	for i := range password {
+		n, err := rand.Int(rand.Reader, alphabetSize)
+		if err != nil {
+			return "", err
+		}
+		password[i] = temporaryPasswordAlphabet[n.Int64()]
+	}
+	return string(password), nil
+}
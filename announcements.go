@@ -0,0 +1,165 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	db "websocket-simple-chat-app/db/sqlc"
+	"websocket-simple-chat-app/hub"
+	"websocket-simple-chat-app/pagination"
+	"websocket-simple-chat-app/token"
+)
+
+// Announcement page sizes
+const (
+	announcementsDefaultLimit = 20
+	announcementsMaxLimit     = 100
+)
+
+// AnnouncementMessage is broadcast to every connected user when an admin posts an announcement
+//
+//wsschema:server
+type AnnouncementMessage struct {
+	Type           string    `json:"type"` // "announcement"
+	AnnouncementID int64     `json:"announcement_id"`
+	Content        string    `json:"content"`
+	CreatedAt      time.Time `json:"created_at"`
+}
+
+// AnnouncementSeenMessage is sent by clients once an announcement was displayed to the user
+//
+//wsschema:client
+type AnnouncementSeenMessage struct {
+	Type           string `json:"type"` // "announcement_seen"
+	AnnouncementID int64  `json:"announcement_id"`
+}
+
+// createAnnouncementHandler stores an announcement and broadcasts it to all connected users
+func createAnnouncementHandler(store *db.Queries, connectionHub *hub.Hub) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		payload := c.MustGet(authorizationPayloadKey).(*token.Payload)
+
+		type createAnnouncementRequest struct {
+			Content string `json:"content" binding:"required"`
+		}
+		var req createAnnouncementRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		announcement, err := store.CreateAnnouncement(context.Background(), db.CreateAnnouncementParams{
+			AuthorID: payload.UserID,
+			Content:  req.Content,
+		})
+		if err != nil {
+			log.Printf("Error creating announcement for admin %d: %v", payload.UserID, err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create announcement"})
+			return
+		}
+
+		jsonMsg, err := json.Marshal(AnnouncementMessage{
+			Type:           "announcement",
+			AnnouncementID: announcement.ID,
+			Content:        announcement.Content,
+			CreatedAt:      announcement.CreatedAt,
+		})
+		if err != nil {
+			log.Printf("Error marshalling announcement %d: %v", announcement.ID, err)
+		} else {
+			connectionHub.Broadcast(jsonMsg, 0)
+		}
+
+		log.Printf("Admin %d posted announcement %d", payload.UserID, announcement.ID)
+		c.JSON(http.StatusCreated, announcement)
+	}
+}
+
+// listAnnouncementsHandler returns the latest announcements with the time the user acknowledged each one,
+// so clients that were offline can show what they missed
+func listAnnouncementsHandler(store *db.Queries) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		payload := c.MustGet(authorizationPayloadKey).(*token.Payload)
+
+		page, beforeID, ok := parseIDPage(c, announcementsDefaultLimit, announcementsMaxLimit)
+		if !ok {
+			return
+		}
+
+		announcements, err := store.ListAnnouncementsForUser(context.Background(), db.ListAnnouncementsForUserParams{
+			UserID:    payload.UserID,
+			BeforeID:  beforeID,
+			PageLimit: page.FetchLimit(),
+		})
+		if err != nil {
+			log.Printf("Error listing announcements for user %d: %v", payload.UserID, err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to list announcements"})
+			return
+		}
+
+		announcements, nextCursor := pagination.Trim(announcements, page, func(a db.ListAnnouncementsForUserRow) string { return pagination.IDKey(a.ID) })
+		c.JSON(http.StatusOK, gin.H{"announcements": announcements, "next_cursor": nextCursor})
+	}
+}
+
+// listAnnouncementStatsHandler returns the reach of the latest announcements for admins
+func listAnnouncementStatsHandler(store *db.Queries) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		page, beforeID, ok := parseIDPage(c, announcementsDefaultLimit, announcementsMaxLimit)
+		if !ok {
+			return
+		}
+
+		stats, err := store.ListAnnouncementStats(context.Background(), db.ListAnnouncementStatsParams{
+			BeforeID:  beforeID,
+			PageLimit: page.FetchLimit(),
+		})
+		if err != nil {
+			log.Printf("Error fetching announcement stats: %v", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch announcement stats"})
+			return
+		}
+		stats, nextCursor := pagination.Trim(stats, page, func(s db.ListAnnouncementStatsRow) string { return pagination.IDKey(s.ID) })
+
+		type announcementReach struct {
+			db.ListAnnouncementStatsRow
+			ReachPercent float64 `json:"reach_percent"` // seen_count / audience_count * 100
+		}
+		results := make([]announcementReach, 0, len(stats))
+		for _, s := range stats {
+			reach := announcementReach{ListAnnouncementStatsRow: s}
+			if s.AudienceCount > 0 {
+				reach.ReachPercent = float64(s.SeenCount) / float64(s.AudienceCount) * 100
+			}
+			results = append(results, reach)
+		}
+
+		c.JSON(http.StatusOK, gin.H{"announcements": results, "next_cursor": nextCursor})
+	}
+}
+
+// handleAnnouncementSeen records a client's acknowledgement of an announcement. Repeated acks are ignored.
+func handleAnnouncementSeen(store *db.Queries, userID int32, payload []byte) {
+	var msg AnnouncementSeenMessage
+	if err := json.Unmarshal(payload, &msg); err != nil {
+		log.Printf("WS Error: Failed to unmarshal announcement_seen: %v. Payload: %s", err, string(payload))
+		return
+	}
+	if msg.AnnouncementID <= 0 {
+		log.Printf("WS Warning: Invalid announcement_seen from user %d: AnnouncementID=%d", userID, msg.AnnouncementID)
+		return
+	}
+
+	if _, err := store.MarkAnnouncementSeen(context.Background(), db.MarkAnnouncementSeenParams{
+		AnnouncementID: msg.AnnouncementID,
+		UserID:         userID,
+	}); err != nil {
+		// Unknown announcement IDs fail the foreign key
+		log.Printf("WS Warning: Failed to record announcement %d as seen by user %d: %v", msg.AnnouncementID, userID, err)
+	}
+}
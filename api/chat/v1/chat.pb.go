@@ -0,0 +1,5118 @@
+// The gRPC API of the chat server, served on GRPC_LISTEN_ADDR (see grpc.go and API_REFERENCE.md).
+//
+// ChatStream carries the WebSocket protocol as typed messages: each oneof field of ChatRequest and
+// ChatEvent is named after the WebSocket message type it stands for ("-" becomes "_"), and the
+// fields of its message after the keys of the WebSocket message (in snake_case where those are
+// camelCase, as in WebRTC signaling). Events keep the order and semantics of /ws; only the
+// encoding differs, and the "type" key is the oneof field.
+
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.6
+// 	protoc        v5.29.3
+// source: api/chat/v1/chat.proto
+
+package chatv1
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	structpb "google.golang.org/protobuf/types/known/structpb"
+	timestamppb "google.golang.org/protobuf/types/known/timestamppb"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+// ChatRequest is a message of the client. Requests without a message set are answered with an
+// invalid_json error event.
+type ChatRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Types that are valid to be assigned to Request:
+	//
+	//	*ChatRequest_PrivateMessage
+	//	*ChatRequest_TypingStart
+	//	*ChatRequest_TypingStop
+	//	*ChatRequest_MessageRead
+	//	*ChatRequest_ContactCard
+	//	*ChatRequest_DeleteMessage
+	//	*ChatRequest_Ping
+	//	*ChatRequest_Reauth
+	//	*ChatRequest_SetPresence
+	//	*ChatRequest_RoomMessage
+	//	*ChatRequest_RoomTypingStart
+	//	*ChatRequest_RoomTypingStop
+	//	*ChatRequest_SupportReply
+	//	*ChatRequest_AnnouncementSeen
+	//	*ChatRequest_Offer
+	//	*ChatRequest_Answer
+	//	*ChatRequest_IceCandidate
+	//	*ChatRequest_Hangup
+	Request       isChatRequest_Request `protobuf_oneof:"request"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ChatRequest) Reset() {
+	*x = ChatRequest{}
+	mi := &file_api_chat_v1_chat_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ChatRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ChatRequest) ProtoMessage() {}
+
+func (x *ChatRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_api_chat_v1_chat_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ChatRequest.ProtoReflect.Descriptor instead.
+func (*ChatRequest) Descriptor() ([]byte, []int) {
+	return file_api_chat_v1_chat_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *ChatRequest) GetRequest() isChatRequest_Request {
+	if x != nil {
+		return x.Request
+	}
+	return nil
+}
+
+func (x *ChatRequest) GetPrivateMessage() *PrivateMessage {
+	if x != nil {
+		if x, ok := x.Request.(*ChatRequest_PrivateMessage); ok {
+			return x.PrivateMessage
+		}
+	}
+	return nil
+}
+
+func (x *ChatRequest) GetTypingStart() *TypingIndicator {
+	if x != nil {
+		if x, ok := x.Request.(*ChatRequest_TypingStart); ok {
+			return x.TypingStart
+		}
+	}
+	return nil
+}
+
+func (x *ChatRequest) GetTypingStop() *TypingIndicator {
+	if x != nil {
+		if x, ok := x.Request.(*ChatRequest_TypingStop); ok {
+			return x.TypingStop
+		}
+	}
+	return nil
+}
+
+func (x *ChatRequest) GetMessageRead() *MessageRead {
+	if x != nil {
+		if x, ok := x.Request.(*ChatRequest_MessageRead); ok {
+			return x.MessageRead
+		}
+	}
+	return nil
+}
+
+func (x *ChatRequest) GetContactCard() *ContactCardRequest {
+	if x != nil {
+		if x, ok := x.Request.(*ChatRequest_ContactCard); ok {
+			return x.ContactCard
+		}
+	}
+	return nil
+}
+
+func (x *ChatRequest) GetDeleteMessage() *DeleteMessageRequest {
+	if x != nil {
+		if x, ok := x.Request.(*ChatRequest_DeleteMessage); ok {
+			return x.DeleteMessage
+		}
+	}
+	return nil
+}
+
+func (x *ChatRequest) GetPing() *Ping {
+	if x != nil {
+		if x, ok := x.Request.(*ChatRequest_Ping); ok {
+			return x.Ping
+		}
+	}
+	return nil
+}
+
+func (x *ChatRequest) GetReauth() *Reauth {
+	if x != nil {
+		if x, ok := x.Request.(*ChatRequest_Reauth); ok {
+			return x.Reauth
+		}
+	}
+	return nil
+}
+
+func (x *ChatRequest) GetSetPresence() *SetPresence {
+	if x != nil {
+		if x, ok := x.Request.(*ChatRequest_SetPresence); ok {
+			return x.SetPresence
+		}
+	}
+	return nil
+}
+
+func (x *ChatRequest) GetRoomMessage() *RoomMessageRequest {
+	if x != nil {
+		if x, ok := x.Request.(*ChatRequest_RoomMessage); ok {
+			return x.RoomMessage
+		}
+	}
+	return nil
+}
+
+func (x *ChatRequest) GetRoomTypingStart() *RoomTypingRequest {
+	if x != nil {
+		if x, ok := x.Request.(*ChatRequest_RoomTypingStart); ok {
+			return x.RoomTypingStart
+		}
+	}
+	return nil
+}
+
+func (x *ChatRequest) GetRoomTypingStop() *RoomTypingRequest {
+	if x != nil {
+		if x, ok := x.Request.(*ChatRequest_RoomTypingStop); ok {
+			return x.RoomTypingStop
+		}
+	}
+	return nil
+}
+
+func (x *ChatRequest) GetSupportReply() *SupportReply {
+	if x != nil {
+		if x, ok := x.Request.(*ChatRequest_SupportReply); ok {
+			return x.SupportReply
+		}
+	}
+	return nil
+}
+
+func (x *ChatRequest) GetAnnouncementSeen() *AnnouncementSeen {
+	if x != nil {
+		if x, ok := x.Request.(*ChatRequest_AnnouncementSeen); ok {
+			return x.AnnouncementSeen
+		}
+	}
+	return nil
+}
+
+func (x *ChatRequest) GetOffer() *Offer {
+	if x != nil {
+		if x, ok := x.Request.(*ChatRequest_Offer); ok {
+			return x.Offer
+		}
+	}
+	return nil
+}
+
+func (x *ChatRequest) GetAnswer() *Answer {
+	if x != nil {
+		if x, ok := x.Request.(*ChatRequest_Answer); ok {
+			return x.Answer
+		}
+	}
+	return nil
+}
+
+func (x *ChatRequest) GetIceCandidate() *IceCandidate {
+	if x != nil {
+		if x, ok := x.Request.(*ChatRequest_IceCandidate); ok {
+			return x.IceCandidate
+		}
+	}
+	return nil
+}
+
+func (x *ChatRequest) GetHangup() *Hangup {
+	if x != nil {
+		if x, ok := x.Request.(*ChatRequest_Hangup); ok {
+			return x.Hangup
+		}
+	}
+	return nil
+}
+
+type isChatRequest_Request interface {
+	isChatRequest_Request()
+}
+
+type ChatRequest_PrivateMessage struct {
+	PrivateMessage *PrivateMessage `protobuf:"bytes,1,opt,name=private_message,json=privateMessage,proto3,oneof"`
+}
+
+type ChatRequest_TypingStart struct {
+	TypingStart *TypingIndicator `protobuf:"bytes,2,opt,name=typing_start,json=typingStart,proto3,oneof"`
+}
+
+type ChatRequest_TypingStop struct {
+	TypingStop *TypingIndicator `protobuf:"bytes,3,opt,name=typing_stop,json=typingStop,proto3,oneof"`
+}
+
+type ChatRequest_MessageRead struct {
+	MessageRead *MessageRead `protobuf:"bytes,4,opt,name=message_read,json=messageRead,proto3,oneof"`
+}
+
+type ChatRequest_ContactCard struct {
+	ContactCard *ContactCardRequest `protobuf:"bytes,5,opt,name=contact_card,json=contactCard,proto3,oneof"`
+}
+
+type ChatRequest_DeleteMessage struct {
+	DeleteMessage *DeleteMessageRequest `protobuf:"bytes,6,opt,name=delete_message,json=deleteMessage,proto3,oneof"`
+}
+
+type ChatRequest_Ping struct {
+	Ping *Ping `protobuf:"bytes,7,opt,name=ping,proto3,oneof"`
+}
+
+type ChatRequest_Reauth struct {
+	Reauth *Reauth `protobuf:"bytes,8,opt,name=reauth,proto3,oneof"`
+}
+
+type ChatRequest_SetPresence struct {
+	SetPresence *SetPresence `protobuf:"bytes,9,opt,name=set_presence,json=setPresence,proto3,oneof"`
+}
+
+type ChatRequest_RoomMessage struct {
+	RoomMessage *RoomMessageRequest `protobuf:"bytes,10,opt,name=room_message,json=roomMessage,proto3,oneof"`
+}
+
+type ChatRequest_RoomTypingStart struct {
+	RoomTypingStart *RoomTypingRequest `protobuf:"bytes,11,opt,name=room_typing_start,json=roomTypingStart,proto3,oneof"`
+}
+
+type ChatRequest_RoomTypingStop struct {
+	RoomTypingStop *RoomTypingRequest `protobuf:"bytes,12,opt,name=room_typing_stop,json=roomTypingStop,proto3,oneof"`
+}
+
+type ChatRequest_SupportReply struct {
+	SupportReply *SupportReply `protobuf:"bytes,13,opt,name=support_reply,json=supportReply,proto3,oneof"`
+}
+
+type ChatRequest_AnnouncementSeen struct {
+	AnnouncementSeen *AnnouncementSeen `protobuf:"bytes,14,opt,name=announcement_seen,json=announcementSeen,proto3,oneof"`
+}
+
+type ChatRequest_Offer struct {
+	Offer *Offer `protobuf:"bytes,15,opt,name=offer,proto3,oneof"`
+}
+
+type ChatRequest_Answer struct {
+	Answer *Answer `protobuf:"bytes,16,opt,name=answer,proto3,oneof"`
+}
+
+type ChatRequest_IceCandidate struct {
+	IceCandidate *IceCandidate `protobuf:"bytes,17,opt,name=ice_candidate,json=iceCandidate,proto3,oneof"`
+}
+
+type ChatRequest_Hangup struct {
+	Hangup *Hangup `protobuf:"bytes,18,opt,name=hangup,proto3,oneof"`
+}
+
+func (*ChatRequest_PrivateMessage) isChatRequest_Request() {}
+
+func (*ChatRequest_TypingStart) isChatRequest_Request() {}
+
+func (*ChatRequest_TypingStop) isChatRequest_Request() {}
+
+func (*ChatRequest_MessageRead) isChatRequest_Request() {}
+
+func (*ChatRequest_ContactCard) isChatRequest_Request() {}
+
+func (*ChatRequest_DeleteMessage) isChatRequest_Request() {}
+
+func (*ChatRequest_Ping) isChatRequest_Request() {}
+
+func (*ChatRequest_Reauth) isChatRequest_Request() {}
+
+func (*ChatRequest_SetPresence) isChatRequest_Request() {}
+
+func (*ChatRequest_RoomMessage) isChatRequest_Request() {}
+
+func (*ChatRequest_RoomTypingStart) isChatRequest_Request() {}
+
+func (*ChatRequest_RoomTypingStop) isChatRequest_Request() {}
+
+func (*ChatRequest_SupportReply) isChatRequest_Request() {}
+
+func (*ChatRequest_AnnouncementSeen) isChatRequest_Request() {}
+
+func (*ChatRequest_Offer) isChatRequest_Request() {}
+
+func (*ChatRequest_Answer) isChatRequest_Request() {}
+
+func (*ChatRequest_IceCandidate) isChatRequest_Request() {}
+
+func (*ChatRequest_Hangup) isChatRequest_Request() {}
+
+type PrivateMessage struct {
+	state            protoimpl.MessageState `protogen:"open.v1"`
+	RecipientId      int32                  `protobuf:"varint,1,opt,name=recipient_id,json=recipientId,proto3" json:"recipient_id,omitempty"`
+	Content          string                 `protobuf:"bytes,2,opt,name=content,proto3" json:"content,omitempty"`
+	ContentType      string                 `protobuf:"bytes,3,opt,name=content_type,json=contentType,proto3" json:"content_type,omitempty"`                     // Optional, "text" if empty
+	ClientMsgId      string                 `protobuf:"bytes,4,opt,name=client_msg_id,json=clientMsgId,proto3" json:"client_msg_id,omitempty"`                   // Optional, chosen by the client and echoed in the ack
+	ReplyToMessageId int64                  `protobuf:"varint,5,opt,name=reply_to_message_id,json=replyToMessageId,proto3" json:"reply_to_message_id,omitempty"` // Optional, the message of the conversation this one replies to
+	unknownFields    protoimpl.UnknownFields
+	sizeCache        protoimpl.SizeCache
+}
+
+func (x *PrivateMessage) Reset() {
+	*x = PrivateMessage{}
+	mi := &file_api_chat_v1_chat_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *PrivateMessage) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PrivateMessage) ProtoMessage() {}
+
+func (x *PrivateMessage) ProtoReflect() protoreflect.Message {
+	mi := &file_api_chat_v1_chat_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PrivateMessage.ProtoReflect.Descriptor instead.
+func (*PrivateMessage) Descriptor() ([]byte, []int) {
+	return file_api_chat_v1_chat_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *PrivateMessage) GetRecipientId() int32 {
+	if x != nil {
+		return x.RecipientId
+	}
+	return 0
+}
+
+func (x *PrivateMessage) GetContent() string {
+	if x != nil {
+		return x.Content
+	}
+	return ""
+}
+
+func (x *PrivateMessage) GetContentType() string {
+	if x != nil {
+		return x.ContentType
+	}
+	return ""
+}
+
+func (x *PrivateMessage) GetClientMsgId() string {
+	if x != nil {
+		return x.ClientMsgId
+	}
+	return ""
+}
+
+func (x *PrivateMessage) GetReplyToMessageId() int64 {
+	if x != nil {
+		return x.ReplyToMessageId
+	}
+	return 0
+}
+
+// TypingIndicator is sent by clients with recipient_id, and by the server with sender_id and
+// created_at
+type TypingIndicator struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	RecipientId   int32                  `protobuf:"varint,1,opt,name=recipient_id,json=recipientId,proto3" json:"recipient_id,omitempty"`
+	SenderId      int32                  `protobuf:"varint,2,opt,name=sender_id,json=senderId,proto3" json:"sender_id,omitempty"`
+	CreatedAt     *timestamppb.Timestamp `protobuf:"bytes,3,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
+	Expired       bool                   `protobuf:"varint,4,opt,name=expired,proto3" json:"expired,omitempty"` // Set on a typing_stop the server sent because the indicator expired
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *TypingIndicator) Reset() {
+	*x = TypingIndicator{}
+	mi := &file_api_chat_v1_chat_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *TypingIndicator) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TypingIndicator) ProtoMessage() {}
+
+func (x *TypingIndicator) ProtoReflect() protoreflect.Message {
+	mi := &file_api_chat_v1_chat_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TypingIndicator.ProtoReflect.Descriptor instead.
+func (*TypingIndicator) Descriptor() ([]byte, []int) {
+	return file_api_chat_v1_chat_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *TypingIndicator) GetRecipientId() int32 {
+	if x != nil {
+		return x.RecipientId
+	}
+	return 0
+}
+
+func (x *TypingIndicator) GetSenderId() int32 {
+	if x != nil {
+		return x.SenderId
+	}
+	return 0
+}
+
+func (x *TypingIndicator) GetCreatedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.CreatedAt
+	}
+	return nil
+}
+
+func (x *TypingIndicator) GetExpired() bool {
+	if x != nil {
+		return x.Expired
+	}
+	return false
+}
+
+type MessageRead struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	SenderId      int32                  `protobuf:"varint,1,opt,name=sender_id,json=senderId,proto3" json:"sender_id,omitempty"`              // ID of the user whose messages were read
+	MessageIds    []int64                `protobuf:"varint,2,rep,packed,name=message_ids,json=messageIds,proto3" json:"message_ids,omitempty"` // Optional: only these messages were read
+	UpToId        int64                  `protobuf:"varint,3,opt,name=up_to_id,json=upToId,proto3" json:"up_to_id,omitempty"`                  // Optional: only the messages up to this ID were read
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *MessageRead) Reset() {
+	*x = MessageRead{}
+	mi := &file_api_chat_v1_chat_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *MessageRead) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*MessageRead) ProtoMessage() {}
+
+func (x *MessageRead) ProtoReflect() protoreflect.Message {
+	mi := &file_api_chat_v1_chat_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use MessageRead.ProtoReflect.Descriptor instead.
+func (*MessageRead) Descriptor() ([]byte, []int) {
+	return file_api_chat_v1_chat_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *MessageRead) GetSenderId() int32 {
+	if x != nil {
+		return x.SenderId
+	}
+	return 0
+}
+
+func (x *MessageRead) GetMessageIds() []int64 {
+	if x != nil {
+		return x.MessageIds
+	}
+	return nil
+}
+
+func (x *MessageRead) GetUpToId() int64 {
+	if x != nil {
+		return x.UpToId
+	}
+	return 0
+}
+
+type ContactCardRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	RecipientId   int32                  `protobuf:"varint,1,opt,name=recipient_id,json=recipientId,proto3" json:"recipient_id,omitempty"` // User receiving the card
+	UserId        int32                  `protobuf:"varint,2,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`                // User being shared
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ContactCardRequest) Reset() {
+	*x = ContactCardRequest{}
+	mi := &file_api_chat_v1_chat_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ContactCardRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ContactCardRequest) ProtoMessage() {}
+
+func (x *ContactCardRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_api_chat_v1_chat_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ContactCardRequest.ProtoReflect.Descriptor instead.
+func (*ContactCardRequest) Descriptor() ([]byte, []int) {
+	return file_api_chat_v1_chat_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *ContactCardRequest) GetRecipientId() int32 {
+	if x != nil {
+		return x.RecipientId
+	}
+	return 0
+}
+
+func (x *ContactCardRequest) GetUserId() int32 {
+	if x != nil {
+		return x.UserId
+	}
+	return 0
+}
+
+type DeleteMessageRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	MessageId     int64                  `protobuf:"varint,1,opt,name=message_id,json=messageId,proto3" json:"message_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DeleteMessageRequest) Reset() {
+	*x = DeleteMessageRequest{}
+	mi := &file_api_chat_v1_chat_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DeleteMessageRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DeleteMessageRequest) ProtoMessage() {}
+
+func (x *DeleteMessageRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_api_chat_v1_chat_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DeleteMessageRequest.ProtoReflect.Descriptor instead.
+func (*DeleteMessageRequest) Descriptor() ([]byte, []int) {
+	return file_api_chat_v1_chat_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *DeleteMessageRequest) GetMessageId() int64 {
+	if x != nil {
+		return x.MessageId
+	}
+	return 0
+}
+
+type Ping struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	ClientTime    *structpb.Value        `protobuf:"bytes,1,opt,name=client_time,json=clientTime,proto3" json:"client_time,omitempty"`  // Opaque client timestamp, echoed back in the pong
+	LastRttMs     float64                `protobuf:"fixed64,2,opt,name=last_rtt_ms,json=lastRttMs,proto3" json:"last_rtt_ms,omitempty"` // Optional: round-trip time the client measured for its previous ping
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Ping) Reset() {
+	*x = Ping{}
+	mi := &file_api_chat_v1_chat_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Ping) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Ping) ProtoMessage() {}
+
+func (x *Ping) ProtoReflect() protoreflect.Message {
+	mi := &file_api_chat_v1_chat_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Ping.ProtoReflect.Descriptor instead.
+func (*Ping) Descriptor() ([]byte, []int) {
+	return file_api_chat_v1_chat_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *Ping) GetClientTime() *structpb.Value {
+	if x != nil {
+		return x.ClientTime
+	}
+	return nil
+}
+
+func (x *Ping) GetLastRttMs() float64 {
+	if x != nil {
+		return x.LastRttMs
+	}
+	return 0
+}
+
+type Reauth struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Token         string                 `protobuf:"bytes,1,opt,name=token,proto3" json:"token,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Reauth) Reset() {
+	*x = Reauth{}
+	mi := &file_api_chat_v1_chat_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Reauth) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Reauth) ProtoMessage() {}
+
+func (x *Reauth) ProtoReflect() protoreflect.Message {
+	mi := &file_api_chat_v1_chat_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Reauth.ProtoReflect.Descriptor instead.
+func (*Reauth) Descriptor() ([]byte, []int) {
+	return file_api_chat_v1_chat_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *Reauth) GetToken() string {
+	if x != nil {
+		return x.Token
+	}
+	return ""
+}
+
+type SetPresence struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Presence      string                 `protobuf:"bytes,1,opt,name=presence,proto3" json:"presence,omitempty"` // available, away, busy, dnd or invisible
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SetPresence) Reset() {
+	*x = SetPresence{}
+	mi := &file_api_chat_v1_chat_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SetPresence) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SetPresence) ProtoMessage() {}
+
+func (x *SetPresence) ProtoReflect() protoreflect.Message {
+	mi := &file_api_chat_v1_chat_proto_msgTypes[8]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SetPresence.ProtoReflect.Descriptor instead.
+func (*SetPresence) Descriptor() ([]byte, []int) {
+	return file_api_chat_v1_chat_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *SetPresence) GetPresence() string {
+	if x != nil {
+		return x.Presence
+	}
+	return ""
+}
+
+type RoomMessageRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	RoomId        int64                  `protobuf:"varint,1,opt,name=room_id,json=roomId,proto3" json:"room_id,omitempty"`
+	Content       string                 `protobuf:"bytes,2,opt,name=content,proto3" json:"content,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RoomMessageRequest) Reset() {
+	*x = RoomMessageRequest{}
+	mi := &file_api_chat_v1_chat_proto_msgTypes[9]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RoomMessageRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RoomMessageRequest) ProtoMessage() {}
+
+func (x *RoomMessageRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_api_chat_v1_chat_proto_msgTypes[9]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RoomMessageRequest.ProtoReflect.Descriptor instead.
+func (*RoomMessageRequest) Descriptor() ([]byte, []int) {
+	return file_api_chat_v1_chat_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *RoomMessageRequest) GetRoomId() int64 {
+	if x != nil {
+		return x.RoomId
+	}
+	return 0
+}
+
+func (x *RoomMessageRequest) GetContent() string {
+	if x != nil {
+		return x.Content
+	}
+	return ""
+}
+
+type RoomTypingRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	RoomId        int64                  `protobuf:"varint,1,opt,name=room_id,json=roomId,proto3" json:"room_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RoomTypingRequest) Reset() {
+	*x = RoomTypingRequest{}
+	mi := &file_api_chat_v1_chat_proto_msgTypes[10]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RoomTypingRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RoomTypingRequest) ProtoMessage() {}
+
+func (x *RoomTypingRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_api_chat_v1_chat_proto_msgTypes[10]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RoomTypingRequest.ProtoReflect.Descriptor instead.
+func (*RoomTypingRequest) Descriptor() ([]byte, []int) {
+	return file_api_chat_v1_chat_proto_rawDescGZIP(), []int{10}
+}
+
+func (x *RoomTypingRequest) GetRoomId() int64 {
+	if x != nil {
+		return x.RoomId
+	}
+	return 0
+}
+
+type SupportReply struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	TicketId      int64                  `protobuf:"varint,1,opt,name=ticket_id,json=ticketId,proto3" json:"ticket_id,omitempty"`
+	Content       string                 `protobuf:"bytes,2,opt,name=content,proto3" json:"content,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SupportReply) Reset() {
+	*x = SupportReply{}
+	mi := &file_api_chat_v1_chat_proto_msgTypes[11]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SupportReply) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SupportReply) ProtoMessage() {}
+
+func (x *SupportReply) ProtoReflect() protoreflect.Message {
+	mi := &file_api_chat_v1_chat_proto_msgTypes[11]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SupportReply.ProtoReflect.Descriptor instead.
+func (*SupportReply) Descriptor() ([]byte, []int) {
+	return file_api_chat_v1_chat_proto_rawDescGZIP(), []int{11}
+}
+
+func (x *SupportReply) GetTicketId() int64 {
+	if x != nil {
+		return x.TicketId
+	}
+	return 0
+}
+
+func (x *SupportReply) GetContent() string {
+	if x != nil {
+		return x.Content
+	}
+	return ""
+}
+
+type AnnouncementSeen struct {
+	state          protoimpl.MessageState `protogen:"open.v1"`
+	AnnouncementId int64                  `protobuf:"varint,1,opt,name=announcement_id,json=announcementId,proto3" json:"announcement_id,omitempty"`
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
+}
+
+func (x *AnnouncementSeen) Reset() {
+	*x = AnnouncementSeen{}
+	mi := &file_api_chat_v1_chat_proto_msgTypes[12]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *AnnouncementSeen) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AnnouncementSeen) ProtoMessage() {}
+
+func (x *AnnouncementSeen) ProtoReflect() protoreflect.Message {
+	mi := &file_api_chat_v1_chat_proto_msgTypes[12]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AnnouncementSeen.ProtoReflect.Descriptor instead.
+func (*AnnouncementSeen) Descriptor() ([]byte, []int) {
+	return file_api_chat_v1_chat_proto_rawDescGZIP(), []int{12}
+}
+
+func (x *AnnouncementSeen) GetAnnouncementId() int64 {
+	if x != nil {
+		return x.AnnouncementId
+	}
+	return 0
+}
+
+type Offer struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Offer         *structpb.Value        `protobuf:"bytes,1,opt,name=offer,proto3" json:"offer,omitempty"`
+	SenderId      int32                  `protobuf:"varint,2,opt,name=sender_id,json=senderId,proto3" json:"sender_id,omitempty"`
+	ReceiverId    int32                  `protobuf:"varint,3,opt,name=receiver_id,json=receiverId,proto3" json:"receiver_id,omitempty"`
+	CreatedAt     *timestamppb.Timestamp `protobuf:"bytes,4,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Offer) Reset() {
+	*x = Offer{}
+	mi := &file_api_chat_v1_chat_proto_msgTypes[13]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Offer) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Offer) ProtoMessage() {}
+
+func (x *Offer) ProtoReflect() protoreflect.Message {
+	mi := &file_api_chat_v1_chat_proto_msgTypes[13]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Offer.ProtoReflect.Descriptor instead.
+func (*Offer) Descriptor() ([]byte, []int) {
+	return file_api_chat_v1_chat_proto_rawDescGZIP(), []int{13}
+}
+
+func (x *Offer) GetOffer() *structpb.Value {
+	if x != nil {
+		return x.Offer
+	}
+	return nil
+}
+
+func (x *Offer) GetSenderId() int32 {
+	if x != nil {
+		return x.SenderId
+	}
+	return 0
+}
+
+func (x *Offer) GetReceiverId() int32 {
+	if x != nil {
+		return x.ReceiverId
+	}
+	return 0
+}
+
+func (x *Offer) GetCreatedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.CreatedAt
+	}
+	return nil
+}
+
+type Answer struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Answer        *structpb.Value        `protobuf:"bytes,1,opt,name=answer,proto3" json:"answer,omitempty"`
+	SenderId      int32                  `protobuf:"varint,2,opt,name=sender_id,json=senderId,proto3" json:"sender_id,omitempty"`
+	ReceiverId    int32                  `protobuf:"varint,3,opt,name=receiver_id,json=receiverId,proto3" json:"receiver_id,omitempty"`
+	CreatedAt     *timestamppb.Timestamp `protobuf:"bytes,4,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Answer) Reset() {
+	*x = Answer{}
+	mi := &file_api_chat_v1_chat_proto_msgTypes[14]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Answer) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Answer) ProtoMessage() {}
+
+func (x *Answer) ProtoReflect() protoreflect.Message {
+	mi := &file_api_chat_v1_chat_proto_msgTypes[14]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Answer.ProtoReflect.Descriptor instead.
+func (*Answer) Descriptor() ([]byte, []int) {
+	return file_api_chat_v1_chat_proto_rawDescGZIP(), []int{14}
+}
+
+func (x *Answer) GetAnswer() *structpb.Value {
+	if x != nil {
+		return x.Answer
+	}
+	return nil
+}
+
+func (x *Answer) GetSenderId() int32 {
+	if x != nil {
+		return x.SenderId
+	}
+	return 0
+}
+
+func (x *Answer) GetReceiverId() int32 {
+	if x != nil {
+		return x.ReceiverId
+	}
+	return 0
+}
+
+func (x *Answer) GetCreatedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.CreatedAt
+	}
+	return nil
+}
+
+type IceCandidate struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Candidate     *structpb.Value        `protobuf:"bytes,1,opt,name=candidate,proto3" json:"candidate,omitempty"`
+	SenderId      int32                  `protobuf:"varint,2,opt,name=sender_id,json=senderId,proto3" json:"sender_id,omitempty"`
+	ReceiverId    int32                  `protobuf:"varint,3,opt,name=receiver_id,json=receiverId,proto3" json:"receiver_id,omitempty"`
+	CreatedAt     *timestamppb.Timestamp `protobuf:"bytes,4,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *IceCandidate) Reset() {
+	*x = IceCandidate{}
+	mi := &file_api_chat_v1_chat_proto_msgTypes[15]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *IceCandidate) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*IceCandidate) ProtoMessage() {}
+
+func (x *IceCandidate) ProtoReflect() protoreflect.Message {
+	mi := &file_api_chat_v1_chat_proto_msgTypes[15]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use IceCandidate.ProtoReflect.Descriptor instead.
+func (*IceCandidate) Descriptor() ([]byte, []int) {
+	return file_api_chat_v1_chat_proto_rawDescGZIP(), []int{15}
+}
+
+func (x *IceCandidate) GetCandidate() *structpb.Value {
+	if x != nil {
+		return x.Candidate
+	}
+	return nil
+}
+
+func (x *IceCandidate) GetSenderId() int32 {
+	if x != nil {
+		return x.SenderId
+	}
+	return 0
+}
+
+func (x *IceCandidate) GetReceiverId() int32 {
+	if x != nil {
+		return x.ReceiverId
+	}
+	return 0
+}
+
+func (x *IceCandidate) GetCreatedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.CreatedAt
+	}
+	return nil
+}
+
+type Hangup struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	SenderId      int32                  `protobuf:"varint,1,opt,name=sender_id,json=senderId,proto3" json:"sender_id,omitempty"`
+	ReceiverId    int32                  `protobuf:"varint,2,opt,name=receiver_id,json=receiverId,proto3" json:"receiver_id,omitempty"`
+	CreatedAt     *timestamppb.Timestamp `protobuf:"bytes,3,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Hangup) Reset() {
+	*x = Hangup{}
+	mi := &file_api_chat_v1_chat_proto_msgTypes[16]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Hangup) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Hangup) ProtoMessage() {}
+
+func (x *Hangup) ProtoReflect() protoreflect.Message {
+	mi := &file_api_chat_v1_chat_proto_msgTypes[16]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Hangup.ProtoReflect.Descriptor instead.
+func (*Hangup) Descriptor() ([]byte, []int) {
+	return file_api_chat_v1_chat_proto_rawDescGZIP(), []int{16}
+}
+
+func (x *Hangup) GetSenderId() int32 {
+	if x != nil {
+		return x.SenderId
+	}
+	return 0
+}
+
+func (x *Hangup) GetReceiverId() int32 {
+	if x != nil {
+		return x.ReceiverId
+	}
+	return 0
+}
+
+func (x *Hangup) GetCreatedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.CreatedAt
+	}
+	return nil
+}
+
+// ChatEvent is an event of the server
+type ChatEvent struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	Seq   int64                  `protobuf:"varint,1,opt,name=seq,proto3" json:"seq,omitempty"` // Per-user sequence number of queued events, see Offline Message Queueing
+	// Types that are valid to be assigned to Event:
+	//
+	//	*ChatEvent_Capabilities
+	//	*ChatEvent_IncomingMessage
+	//	*ChatEvent_Ack
+	//	*ChatEvent_DeliveryDeferred
+	//	*ChatEvent_Delivered
+	//	*ChatEvent_DeliveryReceipt
+	//	*ChatEvent_ReadReceiptUpdate
+	//	*ChatEvent_TypingStart
+	//	*ChatEvent_TypingStop
+	//	*ChatEvent_UserOnline
+	//	*ChatEvent_UserOffline
+	//	*ChatEvent_PresenceChanged
+	//	*ChatEvent_ContactCard
+	//	*ChatEvent_MessageDeleted
+	//	*ChatEvent_MessagesDeleted
+	//	*ChatEvent_MessageSync
+	//	*ChatEvent_ConversationCleared
+	//	*ChatEvent_ConversationLabelsChanged
+	//	*ChatEvent_ConversationUnarchived
+	//	*ChatEvent_ConversationUnmuted
+	//	*ChatEvent_RoomMessage
+	//	*ChatEvent_RoomMemberJoined
+	//	*ChatEvent_RoomMemberLeft
+	//	*ChatEvent_RoomOwnerChanged
+	//	*ChatEvent_RoomTyping
+	//	*ChatEvent_SupportMessage
+	//	*ChatEvent_SupportTicketUpdated
+	//	*ChatEvent_Announcement
+	//	*ChatEvent_LoginAnomaly
+	//	*ChatEvent_Pong
+	//	*ChatEvent_ReauthOk
+	//	*ChatEvent_ReauthFailed
+	//	*ChatEvent_TokenRenewed
+	//	*ChatEvent_RateLimited
+	//	*ChatEvent_RateWarning
+	//	*ChatEvent_Error
+	//	*ChatEvent_Batch
+	//	*ChatEvent_Offer
+	//	*ChatEvent_Answer
+	//	*ChatEvent_IceCandidate
+	//	*ChatEvent_Hangup
+	Event         isChatEvent_Event `protobuf_oneof:"event"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ChatEvent) Reset() {
+	*x = ChatEvent{}
+	mi := &file_api_chat_v1_chat_proto_msgTypes[17]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ChatEvent) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ChatEvent) ProtoMessage() {}
+
+func (x *ChatEvent) ProtoReflect() protoreflect.Message {
+	mi := &file_api_chat_v1_chat_proto_msgTypes[17]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ChatEvent.ProtoReflect.Descriptor instead.
+func (*ChatEvent) Descriptor() ([]byte, []int) {
+	return file_api_chat_v1_chat_proto_rawDescGZIP(), []int{17}
+}
+
+func (x *ChatEvent) GetSeq() int64 {
+	if x != nil {
+		return x.Seq
+	}
+	return 0
+}
+
+func (x *ChatEvent) GetEvent() isChatEvent_Event {
+	if x != nil {
+		return x.Event
+	}
+	return nil
+}
+
+func (x *ChatEvent) GetCapabilities() *Capabilities {
+	if x != nil {
+		if x, ok := x.Event.(*ChatEvent_Capabilities); ok {
+			return x.Capabilities
+		}
+	}
+	return nil
+}
+
+func (x *ChatEvent) GetIncomingMessage() *IncomingMessage {
+	if x != nil {
+		if x, ok := x.Event.(*ChatEvent_IncomingMessage); ok {
+			return x.IncomingMessage
+		}
+	}
+	return nil
+}
+
+func (x *ChatEvent) GetAck() *MessageAck {
+	if x != nil {
+		if x, ok := x.Event.(*ChatEvent_Ack); ok {
+			return x.Ack
+		}
+	}
+	return nil
+}
+
+func (x *ChatEvent) GetDeliveryDeferred() *DeliveryStatus {
+	if x != nil {
+		if x, ok := x.Event.(*ChatEvent_DeliveryDeferred); ok {
+			return x.DeliveryDeferred
+		}
+	}
+	return nil
+}
+
+func (x *ChatEvent) GetDelivered() *DeliveryStatus {
+	if x != nil {
+		if x, ok := x.Event.(*ChatEvent_Delivered); ok {
+			return x.Delivered
+		}
+	}
+	return nil
+}
+
+func (x *ChatEvent) GetDeliveryReceipt() *DeliveryReceipt {
+	if x != nil {
+		if x, ok := x.Event.(*ChatEvent_DeliveryReceipt); ok {
+			return x.DeliveryReceipt
+		}
+	}
+	return nil
+}
+
+func (x *ChatEvent) GetReadReceiptUpdate() *ReadReceiptUpdate {
+	if x != nil {
+		if x, ok := x.Event.(*ChatEvent_ReadReceiptUpdate); ok {
+			return x.ReadReceiptUpdate
+		}
+	}
+	return nil
+}
+
+func (x *ChatEvent) GetTypingStart() *TypingIndicator {
+	if x != nil {
+		if x, ok := x.Event.(*ChatEvent_TypingStart); ok {
+			return x.TypingStart
+		}
+	}
+	return nil
+}
+
+func (x *ChatEvent) GetTypingStop() *TypingIndicator {
+	if x != nil {
+		if x, ok := x.Event.(*ChatEvent_TypingStop); ok {
+			return x.TypingStop
+		}
+	}
+	return nil
+}
+
+func (x *ChatEvent) GetUserOnline() *UserStatus {
+	if x != nil {
+		if x, ok := x.Event.(*ChatEvent_UserOnline); ok {
+			return x.UserOnline
+		}
+	}
+	return nil
+}
+
+func (x *ChatEvent) GetUserOffline() *UserStatus {
+	if x != nil {
+		if x, ok := x.Event.(*ChatEvent_UserOffline); ok {
+			return x.UserOffline
+		}
+	}
+	return nil
+}
+
+func (x *ChatEvent) GetPresenceChanged() *UserStatus {
+	if x != nil {
+		if x, ok := x.Event.(*ChatEvent_PresenceChanged); ok {
+			return x.PresenceChanged
+		}
+	}
+	return nil
+}
+
+func (x *ChatEvent) GetContactCard() *ContactCardMessage {
+	if x != nil {
+		if x, ok := x.Event.(*ChatEvent_ContactCard); ok {
+			return x.ContactCard
+		}
+	}
+	return nil
+}
+
+func (x *ChatEvent) GetMessageDeleted() *MessageDeleted {
+	if x != nil {
+		if x, ok := x.Event.(*ChatEvent_MessageDeleted); ok {
+			return x.MessageDeleted
+		}
+	}
+	return nil
+}
+
+func (x *ChatEvent) GetMessagesDeleted() *MessagesDeleted {
+	if x != nil {
+		if x, ok := x.Event.(*ChatEvent_MessagesDeleted); ok {
+			return x.MessagesDeleted
+		}
+	}
+	return nil
+}
+
+func (x *ChatEvent) GetMessageSync() *MessageSync {
+	if x != nil {
+		if x, ok := x.Event.(*ChatEvent_MessageSync); ok {
+			return x.MessageSync
+		}
+	}
+	return nil
+}
+
+func (x *ChatEvent) GetConversationCleared() *ConversationCleared {
+	if x != nil {
+		if x, ok := x.Event.(*ChatEvent_ConversationCleared); ok {
+			return x.ConversationCleared
+		}
+	}
+	return nil
+}
+
+func (x *ChatEvent) GetConversationLabelsChanged() *ConversationLabelsChanged {
+	if x != nil {
+		if x, ok := x.Event.(*ChatEvent_ConversationLabelsChanged); ok {
+			return x.ConversationLabelsChanged
+		}
+	}
+	return nil
+}
+
+func (x *ChatEvent) GetConversationUnarchived() *ConversationUnarchived {
+	if x != nil {
+		if x, ok := x.Event.(*ChatEvent_ConversationUnarchived); ok {
+			return x.ConversationUnarchived
+		}
+	}
+	return nil
+}
+
+func (x *ChatEvent) GetConversationUnmuted() *ConversationUnmuted {
+	if x != nil {
+		if x, ok := x.Event.(*ChatEvent_ConversationUnmuted); ok {
+			return x.ConversationUnmuted
+		}
+	}
+	return nil
+}
+
+func (x *ChatEvent) GetRoomMessage() *RoomMessage {
+	if x != nil {
+		if x, ok := x.Event.(*ChatEvent_RoomMessage); ok {
+			return x.RoomMessage
+		}
+	}
+	return nil
+}
+
+func (x *ChatEvent) GetRoomMemberJoined() *RoomMembership {
+	if x != nil {
+		if x, ok := x.Event.(*ChatEvent_RoomMemberJoined); ok {
+			return x.RoomMemberJoined
+		}
+	}
+	return nil
+}
+
+func (x *ChatEvent) GetRoomMemberLeft() *RoomMembership {
+	if x != nil {
+		if x, ok := x.Event.(*ChatEvent_RoomMemberLeft); ok {
+			return x.RoomMemberLeft
+		}
+	}
+	return nil
+}
+
+func (x *ChatEvent) GetRoomOwnerChanged() *RoomOwnerChanged {
+	if x != nil {
+		if x, ok := x.Event.(*ChatEvent_RoomOwnerChanged); ok {
+			return x.RoomOwnerChanged
+		}
+	}
+	return nil
+}
+
+func (x *ChatEvent) GetRoomTyping() *RoomTyping {
+	if x != nil {
+		if x, ok := x.Event.(*ChatEvent_RoomTyping); ok {
+			return x.RoomTyping
+		}
+	}
+	return nil
+}
+
+func (x *ChatEvent) GetSupportMessage() *SupportMessage {
+	if x != nil {
+		if x, ok := x.Event.(*ChatEvent_SupportMessage); ok {
+			return x.SupportMessage
+		}
+	}
+	return nil
+}
+
+func (x *ChatEvent) GetSupportTicketUpdated() *SupportTicketUpdated {
+	if x != nil {
+		if x, ok := x.Event.(*ChatEvent_SupportTicketUpdated); ok {
+			return x.SupportTicketUpdated
+		}
+	}
+	return nil
+}
+
+func (x *ChatEvent) GetAnnouncement() *Announcement {
+	if x != nil {
+		if x, ok := x.Event.(*ChatEvent_Announcement); ok {
+			return x.Announcement
+		}
+	}
+	return nil
+}
+
+func (x *ChatEvent) GetLoginAnomaly() *LoginAnomaly {
+	if x != nil {
+		if x, ok := x.Event.(*ChatEvent_LoginAnomaly); ok {
+			return x.LoginAnomaly
+		}
+	}
+	return nil
+}
+
+func (x *ChatEvent) GetPong() *Pong {
+	if x != nil {
+		if x, ok := x.Event.(*ChatEvent_Pong); ok {
+			return x.Pong
+		}
+	}
+	return nil
+}
+
+func (x *ChatEvent) GetReauthOk() *ReauthResult {
+	if x != nil {
+		if x, ok := x.Event.(*ChatEvent_ReauthOk); ok {
+			return x.ReauthOk
+		}
+	}
+	return nil
+}
+
+func (x *ChatEvent) GetReauthFailed() *ReauthResult {
+	if x != nil {
+		if x, ok := x.Event.(*ChatEvent_ReauthFailed); ok {
+			return x.ReauthFailed
+		}
+	}
+	return nil
+}
+
+func (x *ChatEvent) GetTokenRenewed() *TokenRenewed {
+	if x != nil {
+		if x, ok := x.Event.(*ChatEvent_TokenRenewed); ok {
+			return x.TokenRenewed
+		}
+	}
+	return nil
+}
+
+func (x *ChatEvent) GetRateLimited() *RateLimited {
+	if x != nil {
+		if x, ok := x.Event.(*ChatEvent_RateLimited); ok {
+			return x.RateLimited
+		}
+	}
+	return nil
+}
+
+func (x *ChatEvent) GetRateWarning() *RateWarning {
+	if x != nil {
+		if x, ok := x.Event.(*ChatEvent_RateWarning); ok {
+			return x.RateWarning
+		}
+	}
+	return nil
+}
+
+func (x *ChatEvent) GetError() *Error {
+	if x != nil {
+		if x, ok := x.Event.(*ChatEvent_Error); ok {
+			return x.Error
+		}
+	}
+	return nil
+}
+
+func (x *ChatEvent) GetBatch() *Batch {
+	if x != nil {
+		if x, ok := x.Event.(*ChatEvent_Batch); ok {
+			return x.Batch
+		}
+	}
+	return nil
+}
+
+func (x *ChatEvent) GetOffer() *Offer {
+	if x != nil {
+		if x, ok := x.Event.(*ChatEvent_Offer); ok {
+			return x.Offer
+		}
+	}
+	return nil
+}
+
+func (x *ChatEvent) GetAnswer() *Answer {
+	if x != nil {
+		if x, ok := x.Event.(*ChatEvent_Answer); ok {
+			return x.Answer
+		}
+	}
+	return nil
+}
+
+func (x *ChatEvent) GetIceCandidate() *IceCandidate {
+	if x != nil {
+		if x, ok := x.Event.(*ChatEvent_IceCandidate); ok {
+			return x.IceCandidate
+		}
+	}
+	return nil
+}
+
+func (x *ChatEvent) GetHangup() *Hangup {
+	if x != nil {
+		if x, ok := x.Event.(*ChatEvent_Hangup); ok {
+			return x.Hangup
+		}
+	}
+	return nil
+}
+
+type isChatEvent_Event interface {
+	isChatEvent_Event()
+}
+
+type ChatEvent_Capabilities struct {
+	Capabilities *Capabilities `protobuf:"bytes,2,opt,name=capabilities,proto3,oneof"`
+}
+
+type ChatEvent_IncomingMessage struct {
+	IncomingMessage *IncomingMessage `protobuf:"bytes,3,opt,name=incoming_message,json=incomingMessage,proto3,oneof"`
+}
+
+type ChatEvent_Ack struct {
+	Ack *MessageAck `protobuf:"bytes,4,opt,name=ack,proto3,oneof"`
+}
+
+type ChatEvent_DeliveryDeferred struct {
+	DeliveryDeferred *DeliveryStatus `protobuf:"bytes,5,opt,name=delivery_deferred,json=deliveryDeferred,proto3,oneof"`
+}
+
+type ChatEvent_Delivered struct {
+	Delivered *DeliveryStatus `protobuf:"bytes,6,opt,name=delivered,proto3,oneof"`
+}
+
+type ChatEvent_DeliveryReceipt struct {
+	DeliveryReceipt *DeliveryReceipt `protobuf:"bytes,7,opt,name=delivery_receipt,json=deliveryReceipt,proto3,oneof"`
+}
+
+type ChatEvent_ReadReceiptUpdate struct {
+	ReadReceiptUpdate *ReadReceiptUpdate `protobuf:"bytes,8,opt,name=read_receipt_update,json=readReceiptUpdate,proto3,oneof"`
+}
+
+type ChatEvent_TypingStart struct {
+	TypingStart *TypingIndicator `protobuf:"bytes,9,opt,name=typing_start,json=typingStart,proto3,oneof"`
+}
+
+type ChatEvent_TypingStop struct {
+	TypingStop *TypingIndicator `protobuf:"bytes,10,opt,name=typing_stop,json=typingStop,proto3,oneof"`
+}
+
+type ChatEvent_UserOnline struct {
+	UserOnline *UserStatus `protobuf:"bytes,11,opt,name=user_online,json=userOnline,proto3,oneof"`
+}
+
+type ChatEvent_UserOffline struct {
+	UserOffline *UserStatus `protobuf:"bytes,12,opt,name=user_offline,json=userOffline,proto3,oneof"`
+}
+
+type ChatEvent_PresenceChanged struct {
+	PresenceChanged *UserStatus `protobuf:"bytes,13,opt,name=presence_changed,json=presenceChanged,proto3,oneof"`
+}
+
+type ChatEvent_ContactCard struct {
+	ContactCard *ContactCardMessage `protobuf:"bytes,14,opt,name=contact_card,json=contactCard,proto3,oneof"`
+}
+
+type ChatEvent_MessageDeleted struct {
+	MessageDeleted *MessageDeleted `protobuf:"bytes,15,opt,name=message_deleted,json=messageDeleted,proto3,oneof"`
+}
+
+type ChatEvent_MessagesDeleted struct {
+	MessagesDeleted *MessagesDeleted `protobuf:"bytes,16,opt,name=messages_deleted,json=messagesDeleted,proto3,oneof"`
+}
+
+type ChatEvent_MessageSync struct {
+	MessageSync *MessageSync `protobuf:"bytes,17,opt,name=message_sync,json=messageSync,proto3,oneof"`
+}
+
+type ChatEvent_ConversationCleared struct {
+	ConversationCleared *ConversationCleared `protobuf:"bytes,18,opt,name=conversation_cleared,json=conversationCleared,proto3,oneof"`
+}
+
+type ChatEvent_ConversationLabelsChanged struct {
+	ConversationLabelsChanged *ConversationLabelsChanged `protobuf:"bytes,19,opt,name=conversation_labels_changed,json=conversationLabelsChanged,proto3,oneof"`
+}
+
+type ChatEvent_ConversationUnarchived struct {
+	ConversationUnarchived *ConversationUnarchived `protobuf:"bytes,20,opt,name=conversation_unarchived,json=conversationUnarchived,proto3,oneof"`
+}
+
+type ChatEvent_ConversationUnmuted struct {
+	ConversationUnmuted *ConversationUnmuted `protobuf:"bytes,21,opt,name=conversation_unmuted,json=conversationUnmuted,proto3,oneof"`
+}
+
+type ChatEvent_RoomMessage struct {
+	RoomMessage *RoomMessage `protobuf:"bytes,22,opt,name=room_message,json=roomMessage,proto3,oneof"`
+}
+
+type ChatEvent_RoomMemberJoined struct {
+	RoomMemberJoined *RoomMembership `protobuf:"bytes,23,opt,name=room_member_joined,json=roomMemberJoined,proto3,oneof"`
+}
+
+type ChatEvent_RoomMemberLeft struct {
+	RoomMemberLeft *RoomMembership `protobuf:"bytes,24,opt,name=room_member_left,json=roomMemberLeft,proto3,oneof"`
+}
+
+type ChatEvent_RoomOwnerChanged struct {
+	RoomOwnerChanged *RoomOwnerChanged `protobuf:"bytes,25,opt,name=room_owner_changed,json=roomOwnerChanged,proto3,oneof"`
+}
+
+type ChatEvent_RoomTyping struct {
+	RoomTyping *RoomTyping `protobuf:"bytes,26,opt,name=room_typing,json=roomTyping,proto3,oneof"`
+}
+
+type ChatEvent_SupportMessage struct {
+	SupportMessage *SupportMessage `protobuf:"bytes,27,opt,name=support_message,json=supportMessage,proto3,oneof"`
+}
+
+type ChatEvent_SupportTicketUpdated struct {
+	SupportTicketUpdated *SupportTicketUpdated `protobuf:"bytes,28,opt,name=support_ticket_updated,json=supportTicketUpdated,proto3,oneof"`
+}
+
+type ChatEvent_Announcement struct {
+	Announcement *Announcement `protobuf:"bytes,29,opt,name=announcement,proto3,oneof"`
+}
+
+type ChatEvent_LoginAnomaly struct {
+	LoginAnomaly *LoginAnomaly `protobuf:"bytes,30,opt,name=login_anomaly,json=loginAnomaly,proto3,oneof"`
+}
+
+type ChatEvent_Pong struct {
+	Pong *Pong `protobuf:"bytes,31,opt,name=pong,proto3,oneof"`
+}
+
+type ChatEvent_ReauthOk struct {
+	ReauthOk *ReauthResult `protobuf:"bytes,32,opt,name=reauth_ok,json=reauthOk,proto3,oneof"`
+}
+
+type ChatEvent_ReauthFailed struct {
+	ReauthFailed *ReauthResult `protobuf:"bytes,33,opt,name=reauth_failed,json=reauthFailed,proto3,oneof"`
+}
+
+type ChatEvent_TokenRenewed struct {
+	TokenRenewed *TokenRenewed `protobuf:"bytes,34,opt,name=token_renewed,json=tokenRenewed,proto3,oneof"`
+}
+
+type ChatEvent_RateLimited struct {
+	RateLimited *RateLimited `protobuf:"bytes,35,opt,name=rate_limited,json=rateLimited,proto3,oneof"`
+}
+
+type ChatEvent_RateWarning struct {
+	RateWarning *RateWarning `protobuf:"bytes,36,opt,name=rate_warning,json=rateWarning,proto3,oneof"`
+}
+
+type ChatEvent_Error struct {
+	Error *Error `protobuf:"bytes,37,opt,name=error,proto3,oneof"`
+}
+
+type ChatEvent_Batch struct {
+	Batch *Batch `protobuf:"bytes,38,opt,name=batch,proto3,oneof"`
+}
+
+type ChatEvent_Offer struct {
+	Offer *Offer `protobuf:"bytes,39,opt,name=offer,proto3,oneof"`
+}
+
+type ChatEvent_Answer struct {
+	Answer *Answer `protobuf:"bytes,40,opt,name=answer,proto3,oneof"`
+}
+
+type ChatEvent_IceCandidate struct {
+	IceCandidate *IceCandidate `protobuf:"bytes,41,opt,name=ice_candidate,json=iceCandidate,proto3,oneof"`
+}
+
+type ChatEvent_Hangup struct {
+	Hangup *Hangup `protobuf:"bytes,42,opt,name=hangup,proto3,oneof"`
+}
+
+func (*ChatEvent_Capabilities) isChatEvent_Event() {}
+
+func (*ChatEvent_IncomingMessage) isChatEvent_Event() {}
+
+func (*ChatEvent_Ack) isChatEvent_Event() {}
+
+func (*ChatEvent_DeliveryDeferred) isChatEvent_Event() {}
+
+func (*ChatEvent_Delivered) isChatEvent_Event() {}
+
+func (*ChatEvent_DeliveryReceipt) isChatEvent_Event() {}
+
+func (*ChatEvent_ReadReceiptUpdate) isChatEvent_Event() {}
+
+func (*ChatEvent_TypingStart) isChatEvent_Event() {}
+
+func (*ChatEvent_TypingStop) isChatEvent_Event() {}
+
+func (*ChatEvent_UserOnline) isChatEvent_Event() {}
+
+func (*ChatEvent_UserOffline) isChatEvent_Event() {}
+
+func (*ChatEvent_PresenceChanged) isChatEvent_Event() {}
+
+func (*ChatEvent_ContactCard) isChatEvent_Event() {}
+
+func (*ChatEvent_MessageDeleted) isChatEvent_Event() {}
+
+func (*ChatEvent_MessagesDeleted) isChatEvent_Event() {}
+
+func (*ChatEvent_MessageSync) isChatEvent_Event() {}
+
+func (*ChatEvent_ConversationCleared) isChatEvent_Event() {}
+
+func (*ChatEvent_ConversationLabelsChanged) isChatEvent_Event() {}
+
+func (*ChatEvent_ConversationUnarchived) isChatEvent_Event() {}
+
+func (*ChatEvent_ConversationUnmuted) isChatEvent_Event() {}
+
+func (*ChatEvent_RoomMessage) isChatEvent_Event() {}
+
+func (*ChatEvent_RoomMemberJoined) isChatEvent_Event() {}
+
+func (*ChatEvent_RoomMemberLeft) isChatEvent_Event() {}
+
+func (*ChatEvent_RoomOwnerChanged) isChatEvent_Event() {}
+
+func (*ChatEvent_RoomTyping) isChatEvent_Event() {}
+
+func (*ChatEvent_SupportMessage) isChatEvent_Event() {}
+
+func (*ChatEvent_SupportTicketUpdated) isChatEvent_Event() {}
+
+func (*ChatEvent_Announcement) isChatEvent_Event() {}
+
+func (*ChatEvent_LoginAnomaly) isChatEvent_Event() {}
+
+func (*ChatEvent_Pong) isChatEvent_Event() {}
+
+func (*ChatEvent_ReauthOk) isChatEvent_Event() {}
+
+func (*ChatEvent_ReauthFailed) isChatEvent_Event() {}
+
+func (*ChatEvent_TokenRenewed) isChatEvent_Event() {}
+
+func (*ChatEvent_RateLimited) isChatEvent_Event() {}
+
+func (*ChatEvent_RateWarning) isChatEvent_Event() {}
+
+func (*ChatEvent_Error) isChatEvent_Event() {}
+
+func (*ChatEvent_Batch) isChatEvent_Event() {}
+
+func (*ChatEvent_Offer) isChatEvent_Event() {}
+
+func (*ChatEvent_Answer) isChatEvent_Event() {}
+
+func (*ChatEvent_IceCandidate) isChatEvent_Event() {}
+
+func (*ChatEvent_Hangup) isChatEvent_Event() {}
+
+type Capabilities struct {
+	state           protoimpl.MessageState `protogen:"open.v1"`
+	ProtocolVersion int32                  `protobuf:"varint,1,opt,name=protocol_version,json=protocolVersion,proto3" json:"protocol_version,omitempty"`
+	Features        []string               `protobuf:"bytes,2,rep,name=features,proto3" json:"features,omitempty"`
+	CreatedAt       *timestamppb.Timestamp `protobuf:"bytes,3,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
+	unknownFields   protoimpl.UnknownFields
+	sizeCache       protoimpl.SizeCache
+}
+
+func (x *Capabilities) Reset() {
+	*x = Capabilities{}
+	mi := &file_api_chat_v1_chat_proto_msgTypes[18]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Capabilities) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Capabilities) ProtoMessage() {}
+
+func (x *Capabilities) ProtoReflect() protoreflect.Message {
+	mi := &file_api_chat_v1_chat_proto_msgTypes[18]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Capabilities.ProtoReflect.Descriptor instead.
+func (*Capabilities) Descriptor() ([]byte, []int) {
+	return file_api_chat_v1_chat_proto_rawDescGZIP(), []int{18}
+}
+
+func (x *Capabilities) GetProtocolVersion() int32 {
+	if x != nil {
+		return x.ProtocolVersion
+	}
+	return 0
+}
+
+func (x *Capabilities) GetFeatures() []string {
+	if x != nil {
+		return x.Features
+	}
+	return nil
+}
+
+func (x *Capabilities) GetCreatedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.CreatedAt
+	}
+	return nil
+}
+
+type IncomingMessage struct {
+	state          protoimpl.MessageState `protogen:"open.v1"`
+	SenderId       int32                  `protobuf:"varint,1,opt,name=sender_id,json=senderId,proto3" json:"sender_id,omitempty"`
+	SenderUsername string                 `protobuf:"bytes,2,opt,name=sender_username,json=senderUsername,proto3" json:"sender_username,omitempty"`
+	Content        string                 `protobuf:"bytes,3,opt,name=content,proto3" json:"content,omitempty"`
+	ContentType    string                 `protobuf:"bytes,4,opt,name=content_type,json=contentType,proto3" json:"content_type,omitempty"` // Set for messages that are not plain text
+	Preview        string                 `protobuf:"bytes,5,opt,name=preview,proto3" json:"preview,omitempty"`                            // Short single-line text for notifications
+	CreatedAt      *timestamppb.Timestamp `protobuf:"bytes,6,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`       // When the message was stored
+	Muted          bool                   `protobuf:"varint,7,opt,name=muted,proto3" json:"muted,omitempty"`                               // The recipient muted this conversation or is in do not disturb (no alert should be shown)
+	Quiet          bool                   `protobuf:"varint,8,opt,name=quiet,proto3" json:"quiet,omitempty"`                               // During the recipient's quiet hours (no sound or alert should be shown)
+	ReplyTo        *QuotedMessage         `protobuf:"bytes,9,opt,name=reply_to,json=replyTo,proto3" json:"reply_to,omitempty"`             // The message this one replies to, if any
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
+}
+
+func (x *IncomingMessage) Reset() {
+	*x = IncomingMessage{}
+	mi := &file_api_chat_v1_chat_proto_msgTypes[19]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *IncomingMessage) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*IncomingMessage) ProtoMessage() {}
+
+func (x *IncomingMessage) ProtoReflect() protoreflect.Message {
+	mi := &file_api_chat_v1_chat_proto_msgTypes[19]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use IncomingMessage.ProtoReflect.Descriptor instead.
+func (*IncomingMessage) Descriptor() ([]byte, []int) {
+	return file_api_chat_v1_chat_proto_rawDescGZIP(), []int{19}
+}
+
+func (x *IncomingMessage) GetSenderId() int32 {
+	if x != nil {
+		return x.SenderId
+	}
+	return 0
+}
+
+func (x *IncomingMessage) GetSenderUsername() string {
+	if x != nil {
+		return x.SenderUsername
+	}
+	return ""
+}
+
+func (x *IncomingMessage) GetContent() string {
+	if x != nil {
+		return x.Content
+	}
+	return ""
+}
+
+func (x *IncomingMessage) GetContentType() string {
+	if x != nil {
+		return x.ContentType
+	}
+	return ""
+}
+
+func (x *IncomingMessage) GetPreview() string {
+	if x != nil {
+		return x.Preview
+	}
+	return ""
+}
+
+func (x *IncomingMessage) GetCreatedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.CreatedAt
+	}
+	return nil
+}
+
+func (x *IncomingMessage) GetMuted() bool {
+	if x != nil {
+		return x.Muted
+	}
+	return false
+}
+
+func (x *IncomingMessage) GetQuiet() bool {
+	if x != nil {
+		return x.Quiet
+	}
+	return false
+}
+
+func (x *IncomingMessage) GetReplyTo() *QuotedMessage {
+	if x != nil {
+		return x.ReplyTo
+	}
+	return nil
+}
+
+// QuotedMessage is the parent of a reply, as shown above it
+type QuotedMessage struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            int64                  `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	SenderId      int32                  `protobuf:"varint,2,opt,name=sender_id,json=senderId,proto3" json:"sender_id,omitempty"`
+	ContentType   string                 `protobuf:"bytes,3,opt,name=content_type,json=contentType,proto3" json:"content_type,omitempty"`
+	Preview       string                 `protobuf:"bytes,4,opt,name=preview,proto3" json:"preview,omitempty"` // Empty once the parent was deleted
+	CreatedAt     *timestamppb.Timestamp `protobuf:"bytes,5,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
+	Deleted       bool                   `protobuf:"varint,6,opt,name=deleted,proto3" json:"deleted,omitempty"` // The parent was deleted for everyone after the reply was sent
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *QuotedMessage) Reset() {
+	*x = QuotedMessage{}
+	mi := &file_api_chat_v1_chat_proto_msgTypes[20]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *QuotedMessage) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*QuotedMessage) ProtoMessage() {}
+
+func (x *QuotedMessage) ProtoReflect() protoreflect.Message {
+	mi := &file_api_chat_v1_chat_proto_msgTypes[20]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use QuotedMessage.ProtoReflect.Descriptor instead.
+func (*QuotedMessage) Descriptor() ([]byte, []int) {
+	return file_api_chat_v1_chat_proto_rawDescGZIP(), []int{20}
+}
+
+func (x *QuotedMessage) GetId() int64 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+func (x *QuotedMessage) GetSenderId() int32 {
+	if x != nil {
+		return x.SenderId
+	}
+	return 0
+}
+
+func (x *QuotedMessage) GetContentType() string {
+	if x != nil {
+		return x.ContentType
+	}
+	return ""
+}
+
+func (x *QuotedMessage) GetPreview() string {
+	if x != nil {
+		return x.Preview
+	}
+	return ""
+}
+
+func (x *QuotedMessage) GetCreatedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.CreatedAt
+	}
+	return nil
+}
+
+func (x *QuotedMessage) GetDeleted() bool {
+	if x != nil {
+		return x.Deleted
+	}
+	return false
+}
+
+type MessageAck struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	ClientMsgId   string                 `protobuf:"bytes,1,opt,name=client_msg_id,json=clientMsgId,proto3" json:"client_msg_id,omitempty"` // Echoed from the private_message
+	Status        string                 `protobuf:"bytes,2,opt,name=status,proto3" json:"status,omitempty"`
+	MessageId     int64                  `protobuf:"varint,3,opt,name=message_id,json=messageId,proto3" json:"message_id,omitempty"` // Stored messages only
+	Error         string                 `protobuf:"bytes,4,opt,name=error,proto3" json:"error,omitempty"`                           // Rejected and failed messages only
+	CreatedAt     *timestamppb.Timestamp `protobuf:"bytes,5,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`  // When the message was stored, or the time of the failure
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *MessageAck) Reset() {
+	*x = MessageAck{}
+	mi := &file_api_chat_v1_chat_proto_msgTypes[21]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *MessageAck) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*MessageAck) ProtoMessage() {}
+
+func (x *MessageAck) ProtoReflect() protoreflect.Message {
+	mi := &file_api_chat_v1_chat_proto_msgTypes[21]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use MessageAck.ProtoReflect.Descriptor instead.
+func (*MessageAck) Descriptor() ([]byte, []int) {
+	return file_api_chat_v1_chat_proto_rawDescGZIP(), []int{21}
+}
+
+func (x *MessageAck) GetClientMsgId() string {
+	if x != nil {
+		return x.ClientMsgId
+	}
+	return ""
+}
+
+func (x *MessageAck) GetStatus() string {
+	if x != nil {
+		return x.Status
+	}
+	return ""
+}
+
+func (x *MessageAck) GetMessageId() int64 {
+	if x != nil {
+		return x.MessageId
+	}
+	return 0
+}
+
+func (x *MessageAck) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+func (x *MessageAck) GetCreatedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.CreatedAt
+	}
+	return nil
+}
+
+type DeliveryStatus struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	MessageId     int64                  `protobuf:"varint,1,opt,name=message_id,json=messageId,proto3" json:"message_id,omitempty"`
+	RecipientId   int32                  `protobuf:"varint,2,opt,name=recipient_id,json=recipientId,proto3" json:"recipient_id,omitempty"`
+	CreatedAt     *timestamppb.Timestamp `protobuf:"bytes,3,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DeliveryStatus) Reset() {
+	*x = DeliveryStatus{}
+	mi := &file_api_chat_v1_chat_proto_msgTypes[22]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DeliveryStatus) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DeliveryStatus) ProtoMessage() {}
+
+func (x *DeliveryStatus) ProtoReflect() protoreflect.Message {
+	mi := &file_api_chat_v1_chat_proto_msgTypes[22]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DeliveryStatus.ProtoReflect.Descriptor instead.
+func (*DeliveryStatus) Descriptor() ([]byte, []int) {
+	return file_api_chat_v1_chat_proto_rawDescGZIP(), []int{22}
+}
+
+func (x *DeliveryStatus) GetMessageId() int64 {
+	if x != nil {
+		return x.MessageId
+	}
+	return 0
+}
+
+func (x *DeliveryStatus) GetRecipientId() int32 {
+	if x != nil {
+		return x.RecipientId
+	}
+	return 0
+}
+
+func (x *DeliveryStatus) GetCreatedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.CreatedAt
+	}
+	return nil
+}
+
+type DeliveryReceipt struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	MessageId     int64                  `protobuf:"varint,1,opt,name=message_id,json=messageId,proto3" json:"message_id,omitempty"`
+	RecipientId   int32                  `protobuf:"varint,2,opt,name=recipient_id,json=recipientId,proto3" json:"recipient_id,omitempty"`
+	DeliveredAt   *timestamppb.Timestamp `protobuf:"bytes,3,opt,name=delivered_at,json=deliveredAt,proto3" json:"delivered_at,omitempty"`
+	CreatedAt     *timestamppb.Timestamp `protobuf:"bytes,4,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DeliveryReceipt) Reset() {
+	*x = DeliveryReceipt{}
+	mi := &file_api_chat_v1_chat_proto_msgTypes[23]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DeliveryReceipt) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DeliveryReceipt) ProtoMessage() {}
+
+func (x *DeliveryReceipt) ProtoReflect() protoreflect.Message {
+	mi := &file_api_chat_v1_chat_proto_msgTypes[23]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DeliveryReceipt.ProtoReflect.Descriptor instead.
+func (*DeliveryReceipt) Descriptor() ([]byte, []int) {
+	return file_api_chat_v1_chat_proto_rawDescGZIP(), []int{23}
+}
+
+func (x *DeliveryReceipt) GetMessageId() int64 {
+	if x != nil {
+		return x.MessageId
+	}
+	return 0
+}
+
+func (x *DeliveryReceipt) GetRecipientId() int32 {
+	if x != nil {
+		return x.RecipientId
+	}
+	return 0
+}
+
+func (x *DeliveryReceipt) GetDeliveredAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.DeliveredAt
+	}
+	return nil
+}
+
+func (x *DeliveryReceipt) GetCreatedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.CreatedAt
+	}
+	return nil
+}
+
+type ReadReceiptUpdate struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	ReaderId      int32                  `protobuf:"varint,1,opt,name=reader_id,json=readerId,proto3" json:"reader_id,omitempty"` // ID of the user who read the messages
+	SenderId      int32                  `protobuf:"varint,2,opt,name=sender_id,json=senderId,proto3" json:"sender_id,omitempty"` // ID of the user whose messages were read
+	MessageIds    []int64                `protobuf:"varint,3,rep,packed,name=message_ids,json=messageIds,proto3" json:"message_ids,omitempty"`
+	CreatedAt     *timestamppb.Timestamp `protobuf:"bytes,4,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"` // When the messages were read
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ReadReceiptUpdate) Reset() {
+	*x = ReadReceiptUpdate{}
+	mi := &file_api_chat_v1_chat_proto_msgTypes[24]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ReadReceiptUpdate) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ReadReceiptUpdate) ProtoMessage() {}
+
+func (x *ReadReceiptUpdate) ProtoReflect() protoreflect.Message {
+	mi := &file_api_chat_v1_chat_proto_msgTypes[24]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ReadReceiptUpdate.ProtoReflect.Descriptor instead.
+func (*ReadReceiptUpdate) Descriptor() ([]byte, []int) {
+	return file_api_chat_v1_chat_proto_rawDescGZIP(), []int{24}
+}
+
+func (x *ReadReceiptUpdate) GetReaderId() int32 {
+	if x != nil {
+		return x.ReaderId
+	}
+	return 0
+}
+
+func (x *ReadReceiptUpdate) GetSenderId() int32 {
+	if x != nil {
+		return x.SenderId
+	}
+	return 0
+}
+
+func (x *ReadReceiptUpdate) GetMessageIds() []int64 {
+	if x != nil {
+		return x.MessageIds
+	}
+	return nil
+}
+
+func (x *ReadReceiptUpdate) GetCreatedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.CreatedAt
+	}
+	return nil
+}
+
+type UserStatus struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	UserId        int32                  `protobuf:"varint,1,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
+	CreatedAt     *timestamppb.Timestamp `protobuf:"bytes,2,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
+	LastSeenAt    *timestamppb.Timestamp `protobuf:"bytes,3,opt,name=last_seen_at,json=lastSeenAt,proto3" json:"last_seen_at,omitempty"` // user_offline only
+	Presence      string                 `protobuf:"bytes,4,opt,name=presence,proto3" json:"presence,omitempty"`                         // user_online and presence_changed
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *UserStatus) Reset() {
+	*x = UserStatus{}
+	mi := &file_api_chat_v1_chat_proto_msgTypes[25]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UserStatus) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UserStatus) ProtoMessage() {}
+
+func (x *UserStatus) ProtoReflect() protoreflect.Message {
+	mi := &file_api_chat_v1_chat_proto_msgTypes[25]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UserStatus.ProtoReflect.Descriptor instead.
+func (*UserStatus) Descriptor() ([]byte, []int) {
+	return file_api_chat_v1_chat_proto_rawDescGZIP(), []int{25}
+}
+
+func (x *UserStatus) GetUserId() int32 {
+	if x != nil {
+		return x.UserId
+	}
+	return 0
+}
+
+func (x *UserStatus) GetCreatedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.CreatedAt
+	}
+	return nil
+}
+
+func (x *UserStatus) GetLastSeenAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.LastSeenAt
+	}
+	return nil
+}
+
+func (x *UserStatus) GetPresence() string {
+	if x != nil {
+		return x.Presence
+	}
+	return ""
+}
+
+type ContactCard struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	UserId        int32                  `protobuf:"varint,1,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
+	Username      string                 `protobuf:"bytes,2,opt,name=username,proto3" json:"username,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ContactCard) Reset() {
+	*x = ContactCard{}
+	mi := &file_api_chat_v1_chat_proto_msgTypes[26]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ContactCard) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ContactCard) ProtoMessage() {}
+
+func (x *ContactCard) ProtoReflect() protoreflect.Message {
+	mi := &file_api_chat_v1_chat_proto_msgTypes[26]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ContactCard.ProtoReflect.Descriptor instead.
+func (*ContactCard) Descriptor() ([]byte, []int) {
+	return file_api_chat_v1_chat_proto_rawDescGZIP(), []int{26}
+}
+
+func (x *ContactCard) GetUserId() int32 {
+	if x != nil {
+		return x.UserId
+	}
+	return 0
+}
+
+func (x *ContactCard) GetUsername() string {
+	if x != nil {
+		return x.Username
+	}
+	return ""
+}
+
+type ContactCardMessage struct {
+	state          protoimpl.MessageState `protogen:"open.v1"`
+	SenderId       int32                  `protobuf:"varint,1,opt,name=sender_id,json=senderId,proto3" json:"sender_id,omitempty"`
+	SenderUsername string                 `protobuf:"bytes,2,opt,name=sender_username,json=senderUsername,proto3" json:"sender_username,omitempty"`
+	Card           *ContactCard           `protobuf:"bytes,3,opt,name=card,proto3" json:"card,omitempty"`
+	CreatedAt      *timestamppb.Timestamp `protobuf:"bytes,4,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
+}
+
+func (x *ContactCardMessage) Reset() {
+	*x = ContactCardMessage{}
+	mi := &file_api_chat_v1_chat_proto_msgTypes[27]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ContactCardMessage) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ContactCardMessage) ProtoMessage() {}
+
+func (x *ContactCardMessage) ProtoReflect() protoreflect.Message {
+	mi := &file_api_chat_v1_chat_proto_msgTypes[27]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ContactCardMessage.ProtoReflect.Descriptor instead.
+func (*ContactCardMessage) Descriptor() ([]byte, []int) {
+	return file_api_chat_v1_chat_proto_rawDescGZIP(), []int{27}
+}
+
+func (x *ContactCardMessage) GetSenderId() int32 {
+	if x != nil {
+		return x.SenderId
+	}
+	return 0
+}
+
+func (x *ContactCardMessage) GetSenderUsername() string {
+	if x != nil {
+		return x.SenderUsername
+	}
+	return ""
+}
+
+func (x *ContactCardMessage) GetCard() *ContactCard {
+	if x != nil {
+		return x.Card
+	}
+	return nil
+}
+
+func (x *ContactCardMessage) GetCreatedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.CreatedAt
+	}
+	return nil
+}
+
+type MessageDeleted struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	MessageId     int64                  `protobuf:"varint,1,opt,name=message_id,json=messageId,proto3" json:"message_id,omitempty"`
+	SenderId      int32                  `protobuf:"varint,2,opt,name=sender_id,json=senderId,proto3" json:"sender_id,omitempty"`
+	ReceiverId    int32                  `protobuf:"varint,3,opt,name=receiver_id,json=receiverId,proto3" json:"receiver_id,omitempty"`
+	Moderated     bool                   `protobuf:"varint,4,opt,name=moderated,proto3" json:"moderated,omitempty"`                 // Deleted by an admin, not by the sender
+	CreatedAt     *timestamppb.Timestamp `protobuf:"bytes,5,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"` // When the message was deleted
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *MessageDeleted) Reset() {
+	*x = MessageDeleted{}
+	mi := &file_api_chat_v1_chat_proto_msgTypes[28]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *MessageDeleted) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*MessageDeleted) ProtoMessage() {}
+
+func (x *MessageDeleted) ProtoReflect() protoreflect.Message {
+	mi := &file_api_chat_v1_chat_proto_msgTypes[28]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use MessageDeleted.ProtoReflect.Descriptor instead.
+func (*MessageDeleted) Descriptor() ([]byte, []int) {
+	return file_api_chat_v1_chat_proto_rawDescGZIP(), []int{28}
+}
+
+func (x *MessageDeleted) GetMessageId() int64 {
+	if x != nil {
+		return x.MessageId
+	}
+	return 0
+}
+
+func (x *MessageDeleted) GetSenderId() int32 {
+	if x != nil {
+		return x.SenderId
+	}
+	return 0
+}
+
+func (x *MessageDeleted) GetReceiverId() int32 {
+	if x != nil {
+		return x.ReceiverId
+	}
+	return 0
+}
+
+func (x *MessageDeleted) GetModerated() bool {
+	if x != nil {
+		return x.Moderated
+	}
+	return false
+}
+
+func (x *MessageDeleted) GetCreatedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.CreatedAt
+	}
+	return nil
+}
+
+type MessagesDeleted struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	SenderId      int32                  `protobuf:"varint,1,opt,name=sender_id,json=senderId,proto3" json:"sender_id,omitempty"`
+	ReceiverId    int32                  `protobuf:"varint,2,opt,name=receiver_id,json=receiverId,proto3" json:"receiver_id,omitempty"`
+	MessageIds    []int64                `protobuf:"varint,3,rep,packed,name=message_ids,json=messageIds,proto3" json:"message_ids,omitempty"` // Sorted
+	CreatedAt     *timestamppb.Timestamp `protobuf:"bytes,4,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`            // When the messages were deleted
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *MessagesDeleted) Reset() {
+	*x = MessagesDeleted{}
+	mi := &file_api_chat_v1_chat_proto_msgTypes[29]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *MessagesDeleted) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*MessagesDeleted) ProtoMessage() {}
+
+func (x *MessagesDeleted) ProtoReflect() protoreflect.Message {
+	mi := &file_api_chat_v1_chat_proto_msgTypes[29]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use MessagesDeleted.ProtoReflect.Descriptor instead.
+func (*MessagesDeleted) Descriptor() ([]byte, []int) {
+	return file_api_chat_v1_chat_proto_rawDescGZIP(), []int{29}
+}
+
+func (x *MessagesDeleted) GetSenderId() int32 {
+	if x != nil {
+		return x.SenderId
+	}
+	return 0
+}
+
+func (x *MessagesDeleted) GetReceiverId() int32 {
+	if x != nil {
+		return x.ReceiverId
+	}
+	return 0
+}
+
+func (x *MessagesDeleted) GetMessageIds() []int64 {
+	if x != nil {
+		return x.MessageIds
+	}
+	return nil
+}
+
+func (x *MessagesDeleted) GetCreatedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.CreatedAt
+	}
+	return nil
+}
+
+// MessageSync carries private messages a reconnecting client missed, oldest first
+type MessageSync struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Messages      []*SyncedMessage       `protobuf:"bytes,1,rep,name=messages,proto3" json:"messages,omitempty"`
+	LastId        int64                  `protobuf:"varint,2,opt,name=last_id,json=lastId,proto3" json:"last_id,omitempty"` // ID of the newest message so far, to resume from
+	Complete      bool                   `protobuf:"varint,3,opt,name=complete,proto3" json:"complete,omitempty"`           // False while more events follow, and when the sync was truncated
+	CreatedAt     *timestamppb.Timestamp `protobuf:"bytes,4,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *MessageSync) Reset() {
+	*x = MessageSync{}
+	mi := &file_api_chat_v1_chat_proto_msgTypes[30]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *MessageSync) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*MessageSync) ProtoMessage() {}
+
+func (x *MessageSync) ProtoReflect() protoreflect.Message {
+	mi := &file_api_chat_v1_chat_proto_msgTypes[30]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use MessageSync.ProtoReflect.Descriptor instead.
+func (*MessageSync) Descriptor() ([]byte, []int) {
+	return file_api_chat_v1_chat_proto_rawDescGZIP(), []int{30}
+}
+
+func (x *MessageSync) GetMessages() []*SyncedMessage {
+	if x != nil {
+		return x.Messages
+	}
+	return nil
+}
+
+func (x *MessageSync) GetLastId() int64 {
+	if x != nil {
+		return x.LastId
+	}
+	return 0
+}
+
+func (x *MessageSync) GetComplete() bool {
+	if x != nil {
+		return x.Complete
+	}
+	return false
+}
+
+func (x *MessageSync) GetCreatedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.CreatedAt
+	}
+	return nil
+}
+
+// SyncedMessage is a private message as returned by GET /messages. Unset timestamps and a zero
+// reply_to_message_id stand for null.
+type SyncedMessage struct {
+	state            protoimpl.MessageState `protogen:"open.v1"`
+	Id               int64                  `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	SenderId         int32                  `protobuf:"varint,2,opt,name=sender_id,json=senderId,proto3" json:"sender_id,omitempty"`
+	ReceiverId       int32                  `protobuf:"varint,3,opt,name=receiver_id,json=receiverId,proto3" json:"receiver_id,omitempty"`
+	Content          string                 `protobuf:"bytes,4,opt,name=content,proto3" json:"content,omitempty"`
+	ContentType      string                 `protobuf:"bytes,5,opt,name=content_type,json=contentType,proto3" json:"content_type,omitempty"`
+	CreatedAt        *timestamppb.Timestamp `protobuf:"bytes,6,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
+	ReadAt           *timestamppb.Timestamp `protobuf:"bytes,7,opt,name=read_at,json=readAt,proto3" json:"read_at,omitempty"`
+	DeletedAt        *timestamppb.Timestamp `protobuf:"bytes,8,opt,name=deleted_at,json=deletedAt,proto3" json:"deleted_at,omitempty"`
+	DeliveredAt      *timestamppb.Timestamp `protobuf:"bytes,9,opt,name=delivered_at,json=deliveredAt,proto3" json:"delivered_at,omitempty"`
+	ReplyToMessageId int64                  `protobuf:"varint,10,opt,name=reply_to_message_id,json=replyToMessageId,proto3" json:"reply_to_message_id,omitempty"`
+	State            string                 `protobuf:"bytes,11,opt,name=state,proto3" json:"state,omitempty"`                    // stored, delivered or read
+	ReplyTo          *QuotedMessage         `protobuf:"bytes,12,opt,name=reply_to,json=replyTo,proto3" json:"reply_to,omitempty"` // Only set on replies
+	unknownFields    protoimpl.UnknownFields
+	sizeCache        protoimpl.SizeCache
+}
+
+func (x *SyncedMessage) Reset() {
+	*x = SyncedMessage{}
+	mi := &file_api_chat_v1_chat_proto_msgTypes[31]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SyncedMessage) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SyncedMessage) ProtoMessage() {}
+
+func (x *SyncedMessage) ProtoReflect() protoreflect.Message {
+	mi := &file_api_chat_v1_chat_proto_msgTypes[31]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SyncedMessage.ProtoReflect.Descriptor instead.
+func (*SyncedMessage) Descriptor() ([]byte, []int) {
+	return file_api_chat_v1_chat_proto_rawDescGZIP(), []int{31}
+}
+
+func (x *SyncedMessage) GetId() int64 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+func (x *SyncedMessage) GetSenderId() int32 {
+	if x != nil {
+		return x.SenderId
+	}
+	return 0
+}
+
+func (x *SyncedMessage) GetReceiverId() int32 {
+	if x != nil {
+		return x.ReceiverId
+	}
+	return 0
+}
+
+func (x *SyncedMessage) GetContent() string {
+	if x != nil {
+		return x.Content
+	}
+	return ""
+}
+
+func (x *SyncedMessage) GetContentType() string {
+	if x != nil {
+		return x.ContentType
+	}
+	return ""
+}
+
+func (x *SyncedMessage) GetCreatedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.CreatedAt
+	}
+	return nil
+}
+
+func (x *SyncedMessage) GetReadAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.ReadAt
+	}
+	return nil
+}
+
+func (x *SyncedMessage) GetDeletedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.DeletedAt
+	}
+	return nil
+}
+
+func (x *SyncedMessage) GetDeliveredAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.DeliveredAt
+	}
+	return nil
+}
+
+func (x *SyncedMessage) GetReplyToMessageId() int64 {
+	if x != nil {
+		return x.ReplyToMessageId
+	}
+	return 0
+}
+
+func (x *SyncedMessage) GetState() string {
+	if x != nil {
+		return x.State
+	}
+	return ""
+}
+
+func (x *SyncedMessage) GetReplyTo() *QuotedMessage {
+	if x != nil {
+		return x.ReplyTo
+	}
+	return nil
+}
+
+type ConversationCleared struct {
+	state           protoimpl.MessageState `protogen:"open.v1"`
+	PartnerId       int32                  `protobuf:"varint,1,opt,name=partner_id,json=partnerId,proto3" json:"partner_id,omitempty"`
+	ClearedBeforeId int64                  `protobuf:"varint,2,opt,name=cleared_before_id,json=clearedBeforeId,proto3" json:"cleared_before_id,omitempty"` // Messages with an ID up to this one are hidden
+	CreatedAt       *timestamppb.Timestamp `protobuf:"bytes,3,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
+	unknownFields   protoimpl.UnknownFields
+	sizeCache       protoimpl.SizeCache
+}
+
+func (x *ConversationCleared) Reset() {
+	*x = ConversationCleared{}
+	mi := &file_api_chat_v1_chat_proto_msgTypes[32]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ConversationCleared) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ConversationCleared) ProtoMessage() {}
+
+func (x *ConversationCleared) ProtoReflect() protoreflect.Message {
+	mi := &file_api_chat_v1_chat_proto_msgTypes[32]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ConversationCleared.ProtoReflect.Descriptor instead.
+func (*ConversationCleared) Descriptor() ([]byte, []int) {
+	return file_api_chat_v1_chat_proto_rawDescGZIP(), []int{32}
+}
+
+func (x *ConversationCleared) GetPartnerId() int32 {
+	if x != nil {
+		return x.PartnerId
+	}
+	return 0
+}
+
+func (x *ConversationCleared) GetClearedBeforeId() int64 {
+	if x != nil {
+		return x.ClearedBeforeId
+	}
+	return 0
+}
+
+func (x *ConversationCleared) GetCreatedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.CreatedAt
+	}
+	return nil
+}
+
+type ConversationLabelsChanged struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	PartnerId     int32                  `protobuf:"varint,1,opt,name=partner_id,json=partnerId,proto3" json:"partner_id,omitempty"`
+	Labels        []string               `protobuf:"bytes,2,rep,name=labels,proto3" json:"labels,omitempty"` // All labels of the conversation now, sorted
+	CreatedAt     *timestamppb.Timestamp `protobuf:"bytes,3,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ConversationLabelsChanged) Reset() {
+	*x = ConversationLabelsChanged{}
+	mi := &file_api_chat_v1_chat_proto_msgTypes[33]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ConversationLabelsChanged) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ConversationLabelsChanged) ProtoMessage() {}
+
+func (x *ConversationLabelsChanged) ProtoReflect() protoreflect.Message {
+	mi := &file_api_chat_v1_chat_proto_msgTypes[33]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ConversationLabelsChanged.ProtoReflect.Descriptor instead.
+func (*ConversationLabelsChanged) Descriptor() ([]byte, []int) {
+	return file_api_chat_v1_chat_proto_rawDescGZIP(), []int{33}
+}
+
+func (x *ConversationLabelsChanged) GetPartnerId() int32 {
+	if x != nil {
+		return x.PartnerId
+	}
+	return 0
+}
+
+func (x *ConversationLabelsChanged) GetLabels() []string {
+	if x != nil {
+		return x.Labels
+	}
+	return nil
+}
+
+func (x *ConversationLabelsChanged) GetCreatedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.CreatedAt
+	}
+	return nil
+}
+
+type ConversationUnarchived struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	PartnerId     int32                  `protobuf:"varint,1,opt,name=partner_id,json=partnerId,proto3" json:"partner_id,omitempty"`
+	CreatedAt     *timestamppb.Timestamp `protobuf:"bytes,2,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ConversationUnarchived) Reset() {
+	*x = ConversationUnarchived{}
+	mi := &file_api_chat_v1_chat_proto_msgTypes[34]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ConversationUnarchived) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ConversationUnarchived) ProtoMessage() {}
+
+func (x *ConversationUnarchived) ProtoReflect() protoreflect.Message {
+	mi := &file_api_chat_v1_chat_proto_msgTypes[34]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ConversationUnarchived.ProtoReflect.Descriptor instead.
+func (*ConversationUnarchived) Descriptor() ([]byte, []int) {
+	return file_api_chat_v1_chat_proto_rawDescGZIP(), []int{34}
+}
+
+func (x *ConversationUnarchived) GetPartnerId() int32 {
+	if x != nil {
+		return x.PartnerId
+	}
+	return 0
+}
+
+func (x *ConversationUnarchived) GetCreatedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.CreatedAt
+	}
+	return nil
+}
+
+type ConversationUnmuted struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	PartnerId     int32                  `protobuf:"varint,1,opt,name=partner_id,json=partnerId,proto3" json:"partner_id,omitempty"`
+	CreatedAt     *timestamppb.Timestamp `protobuf:"bytes,2,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ConversationUnmuted) Reset() {
+	*x = ConversationUnmuted{}
+	mi := &file_api_chat_v1_chat_proto_msgTypes[35]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ConversationUnmuted) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ConversationUnmuted) ProtoMessage() {}
+
+func (x *ConversationUnmuted) ProtoReflect() protoreflect.Message {
+	mi := &file_api_chat_v1_chat_proto_msgTypes[35]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ConversationUnmuted.ProtoReflect.Descriptor instead.
+func (*ConversationUnmuted) Descriptor() ([]byte, []int) {
+	return file_api_chat_v1_chat_proto_rawDescGZIP(), []int{35}
+}
+
+func (x *ConversationUnmuted) GetPartnerId() int32 {
+	if x != nil {
+		return x.PartnerId
+	}
+	return 0
+}
+
+func (x *ConversationUnmuted) GetCreatedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.CreatedAt
+	}
+	return nil
+}
+
+type RoomMessage struct {
+	state          protoimpl.MessageState `protogen:"open.v1"`
+	MessageId      int64                  `protobuf:"varint,1,opt,name=message_id,json=messageId,proto3" json:"message_id,omitempty"`
+	RoomId         int64                  `protobuf:"varint,2,opt,name=room_id,json=roomId,proto3" json:"room_id,omitempty"`
+	SenderId       int32                  `protobuf:"varint,3,opt,name=sender_id,json=senderId,proto3" json:"sender_id,omitempty"`
+	SenderUsername string                 `protobuf:"bytes,4,opt,name=sender_username,json=senderUsername,proto3" json:"sender_username,omitempty"`
+	Content        string                 `protobuf:"bytes,5,opt,name=content,proto3" json:"content,omitempty"`
+	Preview        string                 `protobuf:"bytes,6,opt,name=preview,proto3" json:"preview,omitempty"`
+	CreatedAt      *timestamppb.Timestamp `protobuf:"bytes,7,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
+}
+
+func (x *RoomMessage) Reset() {
+	*x = RoomMessage{}
+	mi := &file_api_chat_v1_chat_proto_msgTypes[36]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RoomMessage) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RoomMessage) ProtoMessage() {}
+
+func (x *RoomMessage) ProtoReflect() protoreflect.Message {
+	mi := &file_api_chat_v1_chat_proto_msgTypes[36]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RoomMessage.ProtoReflect.Descriptor instead.
+func (*RoomMessage) Descriptor() ([]byte, []int) {
+	return file_api_chat_v1_chat_proto_rawDescGZIP(), []int{36}
+}
+
+func (x *RoomMessage) GetMessageId() int64 {
+	if x != nil {
+		return x.MessageId
+	}
+	return 0
+}
+
+func (x *RoomMessage) GetRoomId() int64 {
+	if x != nil {
+		return x.RoomId
+	}
+	return 0
+}
+
+func (x *RoomMessage) GetSenderId() int32 {
+	if x != nil {
+		return x.SenderId
+	}
+	return 0
+}
+
+func (x *RoomMessage) GetSenderUsername() string {
+	if x != nil {
+		return x.SenderUsername
+	}
+	return ""
+}
+
+func (x *RoomMessage) GetContent() string {
+	if x != nil {
+		return x.Content
+	}
+	return ""
+}
+
+func (x *RoomMessage) GetPreview() string {
+	if x != nil {
+		return x.Preview
+	}
+	return ""
+}
+
+func (x *RoomMessage) GetCreatedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.CreatedAt
+	}
+	return nil
+}
+
+type RoomMembership struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	RoomId        int64                  `protobuf:"varint,1,opt,name=room_id,json=roomId,proto3" json:"room_id,omitempty"`
+	UserId        int32                  `protobuf:"varint,2,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
+	Username      string                 `protobuf:"bytes,3,opt,name=username,proto3" json:"username,omitempty"`
+	CreatedAt     *timestamppb.Timestamp `protobuf:"bytes,4,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RoomMembership) Reset() {
+	*x = RoomMembership{}
+	mi := &file_api_chat_v1_chat_proto_msgTypes[37]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RoomMembership) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RoomMembership) ProtoMessage() {}
+
+func (x *RoomMembership) ProtoReflect() protoreflect.Message {
+	mi := &file_api_chat_v1_chat_proto_msgTypes[37]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RoomMembership.ProtoReflect.Descriptor instead.
+func (*RoomMembership) Descriptor() ([]byte, []int) {
+	return file_api_chat_v1_chat_proto_rawDescGZIP(), []int{37}
+}
+
+func (x *RoomMembership) GetRoomId() int64 {
+	if x != nil {
+		return x.RoomId
+	}
+	return 0
+}
+
+func (x *RoomMembership) GetUserId() int32 {
+	if x != nil {
+		return x.UserId
+	}
+	return 0
+}
+
+func (x *RoomMembership) GetUsername() string {
+	if x != nil {
+		return x.Username
+	}
+	return ""
+}
+
+func (x *RoomMembership) GetCreatedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.CreatedAt
+	}
+	return nil
+}
+
+type RoomOwnerChanged struct {
+	state           protoimpl.MessageState `protogen:"open.v1"`
+	RoomId          int64                  `protobuf:"varint,1,opt,name=room_id,json=roomId,proto3" json:"room_id,omitempty"`
+	OwnerId         int32                  `protobuf:"varint,2,opt,name=owner_id,json=ownerId,proto3" json:"owner_id,omitempty"`
+	PreviousOwnerId int32                  `protobuf:"varint,3,opt,name=previous_owner_id,json=previousOwnerId,proto3" json:"previous_owner_id,omitempty"`
+	Reason          string                 `protobuf:"bytes,4,opt,name=reason,proto3" json:"reason,omitempty"`
+	CreatedAt       *timestamppb.Timestamp `protobuf:"bytes,5,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
+	unknownFields   protoimpl.UnknownFields
+	sizeCache       protoimpl.SizeCache
+}
+
+func (x *RoomOwnerChanged) Reset() {
+	*x = RoomOwnerChanged{}
+	mi := &file_api_chat_v1_chat_proto_msgTypes[38]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RoomOwnerChanged) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RoomOwnerChanged) ProtoMessage() {}
+
+func (x *RoomOwnerChanged) ProtoReflect() protoreflect.Message {
+	mi := &file_api_chat_v1_chat_proto_msgTypes[38]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RoomOwnerChanged.ProtoReflect.Descriptor instead.
+func (*RoomOwnerChanged) Descriptor() ([]byte, []int) {
+	return file_api_chat_v1_chat_proto_rawDescGZIP(), []int{38}
+}
+
+func (x *RoomOwnerChanged) GetRoomId() int64 {
+	if x != nil {
+		return x.RoomId
+	}
+	return 0
+}
+
+func (x *RoomOwnerChanged) GetOwnerId() int32 {
+	if x != nil {
+		return x.OwnerId
+	}
+	return 0
+}
+
+func (x *RoomOwnerChanged) GetPreviousOwnerId() int32 {
+	if x != nil {
+		return x.PreviousOwnerId
+	}
+	return 0
+}
+
+func (x *RoomOwnerChanged) GetReason() string {
+	if x != nil {
+		return x.Reason
+	}
+	return ""
+}
+
+func (x *RoomOwnerChanged) GetCreatedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.CreatedAt
+	}
+	return nil
+}
+
+type RoomTyping struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	RoomId        int64                  `protobuf:"varint,1,opt,name=room_id,json=roomId,proto3" json:"room_id,omitempty"`
+	Count         int32                  `protobuf:"varint,2,opt,name=count,proto3" json:"count,omitempty"`    // Number of members typing, 0 once everyone stopped
+	Typists       []*RoomTypist          `protobuf:"bytes,3,rep,name=typists,proto3" json:"typists,omitempty"` // Empty when too many members type
+	CreatedAt     *timestamppb.Timestamp `protobuf:"bytes,4,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RoomTyping) Reset() {
+	*x = RoomTyping{}
+	mi := &file_api_chat_v1_chat_proto_msgTypes[39]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RoomTyping) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RoomTyping) ProtoMessage() {}
+
+func (x *RoomTyping) ProtoReflect() protoreflect.Message {
+	mi := &file_api_chat_v1_chat_proto_msgTypes[39]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RoomTyping.ProtoReflect.Descriptor instead.
+func (*RoomTyping) Descriptor() ([]byte, []int) {
+	return file_api_chat_v1_chat_proto_rawDescGZIP(), []int{39}
+}
+
+func (x *RoomTyping) GetRoomId() int64 {
+	if x != nil {
+		return x.RoomId
+	}
+	return 0
+}
+
+func (x *RoomTyping) GetCount() int32 {
+	if x != nil {
+		return x.Count
+	}
+	return 0
+}
+
+func (x *RoomTyping) GetTypists() []*RoomTypist {
+	if x != nil {
+		return x.Typists
+	}
+	return nil
+}
+
+func (x *RoomTyping) GetCreatedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.CreatedAt
+	}
+	return nil
+}
+
+type RoomTypist struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	UserId        int32                  `protobuf:"varint,1,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
+	Username      string                 `protobuf:"bytes,2,opt,name=username,proto3" json:"username,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RoomTypist) Reset() {
+	*x = RoomTypist{}
+	mi := &file_api_chat_v1_chat_proto_msgTypes[40]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RoomTypist) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RoomTypist) ProtoMessage() {}
+
+func (x *RoomTypist) ProtoReflect() protoreflect.Message {
+	mi := &file_api_chat_v1_chat_proto_msgTypes[40]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RoomTypist.ProtoReflect.Descriptor instead.
+func (*RoomTypist) Descriptor() ([]byte, []int) {
+	return file_api_chat_v1_chat_proto_rawDescGZIP(), []int{40}
+}
+
+func (x *RoomTypist) GetUserId() int32 {
+	if x != nil {
+		return x.UserId
+	}
+	return 0
+}
+
+func (x *RoomTypist) GetUsername() string {
+	if x != nil {
+		return x.Username
+	}
+	return ""
+}
+
+type SupportMessage struct {
+	state            protoimpl.MessageState `protogen:"open.v1"`
+	TicketId         int64                  `protobuf:"varint,1,opt,name=ticket_id,json=ticketId,proto3" json:"ticket_id,omitempty"`
+	TicketStatus     string                 `protobuf:"bytes,2,opt,name=ticket_status,json=ticketStatus,proto3" json:"ticket_status,omitempty"`
+	CustomerId       int32                  `protobuf:"varint,3,opt,name=customer_id,json=customerId,proto3" json:"customer_id,omitempty"`
+	CustomerUsername string                 `protobuf:"bytes,4,opt,name=customer_username,json=customerUsername,proto3" json:"customer_username,omitempty"`
+	MessageId        int64                  `protobuf:"varint,5,opt,name=message_id,json=messageId,proto3" json:"message_id,omitempty"`
+	Content          string                 `protobuf:"bytes,6,opt,name=content,proto3" json:"content,omitempty"`
+	CreatedAt        *timestamppb.Timestamp `protobuf:"bytes,7,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
+	unknownFields    protoimpl.UnknownFields
+	sizeCache        protoimpl.SizeCache
+}
+
+func (x *SupportMessage) Reset() {
+	*x = SupportMessage{}
+	mi := &file_api_chat_v1_chat_proto_msgTypes[41]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SupportMessage) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SupportMessage) ProtoMessage() {}
+
+func (x *SupportMessage) ProtoReflect() protoreflect.Message {
+	mi := &file_api_chat_v1_chat_proto_msgTypes[41]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SupportMessage.ProtoReflect.Descriptor instead.
+func (*SupportMessage) Descriptor() ([]byte, []int) {
+	return file_api_chat_v1_chat_proto_rawDescGZIP(), []int{41}
+}
+
+func (x *SupportMessage) GetTicketId() int64 {
+	if x != nil {
+		return x.TicketId
+	}
+	return 0
+}
+
+func (x *SupportMessage) GetTicketStatus() string {
+	if x != nil {
+		return x.TicketStatus
+	}
+	return ""
+}
+
+func (x *SupportMessage) GetCustomerId() int32 {
+	if x != nil {
+		return x.CustomerId
+	}
+	return 0
+}
+
+func (x *SupportMessage) GetCustomerUsername() string {
+	if x != nil {
+		return x.CustomerUsername
+	}
+	return ""
+}
+
+func (x *SupportMessage) GetMessageId() int64 {
+	if x != nil {
+		return x.MessageId
+	}
+	return 0
+}
+
+func (x *SupportMessage) GetContent() string {
+	if x != nil {
+		return x.Content
+	}
+	return ""
+}
+
+func (x *SupportMessage) GetCreatedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.CreatedAt
+	}
+	return nil
+}
+
+type SupportTicketUpdated struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	TicketId      int64                  `protobuf:"varint,1,opt,name=ticket_id,json=ticketId,proto3" json:"ticket_id,omitempty"`
+	Status        string                 `protobuf:"bytes,2,opt,name=status,proto3" json:"status,omitempty"`
+	AgentId       int32                  `protobuf:"varint,3,opt,name=agent_id,json=agentId,proto3" json:"agent_id,omitempty"` // 0 while the ticket is unclaimed
+	CreatedAt     *timestamppb.Timestamp `protobuf:"bytes,4,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SupportTicketUpdated) Reset() {
+	*x = SupportTicketUpdated{}
+	mi := &file_api_chat_v1_chat_proto_msgTypes[42]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SupportTicketUpdated) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SupportTicketUpdated) ProtoMessage() {}
+
+func (x *SupportTicketUpdated) ProtoReflect() protoreflect.Message {
+	mi := &file_api_chat_v1_chat_proto_msgTypes[42]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SupportTicketUpdated.ProtoReflect.Descriptor instead.
+func (*SupportTicketUpdated) Descriptor() ([]byte, []int) {
+	return file_api_chat_v1_chat_proto_rawDescGZIP(), []int{42}
+}
+
+func (x *SupportTicketUpdated) GetTicketId() int64 {
+	if x != nil {
+		return x.TicketId
+	}
+	return 0
+}
+
+func (x *SupportTicketUpdated) GetStatus() string {
+	if x != nil {
+		return x.Status
+	}
+	return ""
+}
+
+func (x *SupportTicketUpdated) GetAgentId() int32 {
+	if x != nil {
+		return x.AgentId
+	}
+	return 0
+}
+
+func (x *SupportTicketUpdated) GetCreatedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.CreatedAt
+	}
+	return nil
+}
+
+type Announcement struct {
+	state          protoimpl.MessageState `protogen:"open.v1"`
+	AnnouncementId int64                  `protobuf:"varint,1,opt,name=announcement_id,json=announcementId,proto3" json:"announcement_id,omitempty"`
+	Content        string                 `protobuf:"bytes,2,opt,name=content,proto3" json:"content,omitempty"`
+	CreatedAt      *timestamppb.Timestamp `protobuf:"bytes,3,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
+}
+
+func (x *Announcement) Reset() {
+	*x = Announcement{}
+	mi := &file_api_chat_v1_chat_proto_msgTypes[43]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Announcement) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Announcement) ProtoMessage() {}
+
+func (x *Announcement) ProtoReflect() protoreflect.Message {
+	mi := &file_api_chat_v1_chat_proto_msgTypes[43]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Announcement.ProtoReflect.Descriptor instead.
+func (*Announcement) Descriptor() ([]byte, []int) {
+	return file_api_chat_v1_chat_proto_rawDescGZIP(), []int{43}
+}
+
+func (x *Announcement) GetAnnouncementId() int64 {
+	if x != nil {
+		return x.AnnouncementId
+	}
+	return 0
+}
+
+func (x *Announcement) GetContent() string {
+	if x != nil {
+		return x.Content
+	}
+	return ""
+}
+
+func (x *Announcement) GetCreatedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.CreatedAt
+	}
+	return nil
+}
+
+type LoginAnomaly struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	IpAddress     string                 `protobuf:"bytes,1,opt,name=ip_address,json=ipAddress,proto3" json:"ip_address,omitempty"`
+	UserAgent     string                 `protobuf:"bytes,2,opt,name=user_agent,json=userAgent,proto3" json:"user_agent,omitempty"`
+	CreatedAt     *timestamppb.Timestamp `protobuf:"bytes,3,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *LoginAnomaly) Reset() {
+	*x = LoginAnomaly{}
+	mi := &file_api_chat_v1_chat_proto_msgTypes[44]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *LoginAnomaly) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*LoginAnomaly) ProtoMessage() {}
+
+func (x *LoginAnomaly) ProtoReflect() protoreflect.Message {
+	mi := &file_api_chat_v1_chat_proto_msgTypes[44]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use LoginAnomaly.ProtoReflect.Descriptor instead.
+func (*LoginAnomaly) Descriptor() ([]byte, []int) {
+	return file_api_chat_v1_chat_proto_rawDescGZIP(), []int{44}
+}
+
+func (x *LoginAnomaly) GetIpAddress() string {
+	if x != nil {
+		return x.IpAddress
+	}
+	return ""
+}
+
+func (x *LoginAnomaly) GetUserAgent() string {
+	if x != nil {
+		return x.UserAgent
+	}
+	return ""
+}
+
+func (x *LoginAnomaly) GetCreatedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.CreatedAt
+	}
+	return nil
+}
+
+type Pong struct {
+	state            protoimpl.MessageState `protogen:"open.v1"`
+	ClientTime       *structpb.Value        `protobuf:"bytes,1,opt,name=client_time,json=clientTime,proto3" json:"client_time,omitempty"` // Echo of the ping's client_time
+	ServerReceivedAt *timestamppb.Timestamp `protobuf:"bytes,2,opt,name=server_received_at,json=serverReceivedAt,proto3" json:"server_received_at,omitempty"`
+	CreatedAt        *timestamppb.Timestamp `protobuf:"bytes,3,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
+	unknownFields    protoimpl.UnknownFields
+	sizeCache        protoimpl.SizeCache
+}
+
+func (x *Pong) Reset() {
+	*x = Pong{}
+	mi := &file_api_chat_v1_chat_proto_msgTypes[45]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Pong) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Pong) ProtoMessage() {}
+
+func (x *Pong) ProtoReflect() protoreflect.Message {
+	mi := &file_api_chat_v1_chat_proto_msgTypes[45]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Pong.ProtoReflect.Descriptor instead.
+func (*Pong) Descriptor() ([]byte, []int) {
+	return file_api_chat_v1_chat_proto_rawDescGZIP(), []int{45}
+}
+
+func (x *Pong) GetClientTime() *structpb.Value {
+	if x != nil {
+		return x.ClientTime
+	}
+	return nil
+}
+
+func (x *Pong) GetServerReceivedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.ServerReceivedAt
+	}
+	return nil
+}
+
+func (x *Pong) GetCreatedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.CreatedAt
+	}
+	return nil
+}
+
+type ReauthResult struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	ExpiredAt     *timestamppb.Timestamp `protobuf:"bytes,1,opt,name=expired_at,json=expiredAt,proto3" json:"expired_at,omitempty"` // When the session now expires
+	Error         string                 `protobuf:"bytes,2,opt,name=error,proto3" json:"error,omitempty"`                          // Why the token was rejected
+	CreatedAt     *timestamppb.Timestamp `protobuf:"bytes,3,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ReauthResult) Reset() {
+	*x = ReauthResult{}
+	mi := &file_api_chat_v1_chat_proto_msgTypes[46]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ReauthResult) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ReauthResult) ProtoMessage() {}
+
+func (x *ReauthResult) ProtoReflect() protoreflect.Message {
+	mi := &file_api_chat_v1_chat_proto_msgTypes[46]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ReauthResult.ProtoReflect.Descriptor instead.
+func (*ReauthResult) Descriptor() ([]byte, []int) {
+	return file_api_chat_v1_chat_proto_rawDescGZIP(), []int{46}
+}
+
+func (x *ReauthResult) GetExpiredAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.ExpiredAt
+	}
+	return nil
+}
+
+func (x *ReauthResult) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+func (x *ReauthResult) GetCreatedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.CreatedAt
+	}
+	return nil
+}
+
+type TokenRenewed struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Token         string                 `protobuf:"bytes,1,opt,name=token,proto3" json:"token,omitempty"`
+	Payload       *TokenPayload          `protobuf:"bytes,2,opt,name=payload,proto3" json:"payload,omitempty"`
+	CreatedAt     *timestamppb.Timestamp `protobuf:"bytes,3,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *TokenRenewed) Reset() {
+	*x = TokenRenewed{}
+	mi := &file_api_chat_v1_chat_proto_msgTypes[47]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *TokenRenewed) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TokenRenewed) ProtoMessage() {}
+
+func (x *TokenRenewed) ProtoReflect() protoreflect.Message {
+	mi := &file_api_chat_v1_chat_proto_msgTypes[47]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TokenRenewed.ProtoReflect.Descriptor instead.
+func (*TokenRenewed) Descriptor() ([]byte, []int) {
+	return file_api_chat_v1_chat_proto_rawDescGZIP(), []int{47}
+}
+
+func (x *TokenRenewed) GetToken() string {
+	if x != nil {
+		return x.Token
+	}
+	return ""
+}
+
+func (x *TokenRenewed) GetPayload() *TokenPayload {
+	if x != nil {
+		return x.Payload
+	}
+	return nil
+}
+
+func (x *TokenRenewed) GetCreatedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.CreatedAt
+	}
+	return nil
+}
+
+type TokenPayload struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	UserId        int32                  `protobuf:"varint,2,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
+	Username      string                 `protobuf:"bytes,3,opt,name=username,proto3" json:"username,omitempty"`
+	IssuedAt      *timestamppb.Timestamp `protobuf:"bytes,4,opt,name=issued_at,json=issuedAt,proto3" json:"issued_at,omitempty"`
+	ExpiredAt     *timestamppb.Timestamp `protobuf:"bytes,5,opt,name=expired_at,json=expiredAt,proto3" json:"expired_at,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *TokenPayload) Reset() {
+	*x = TokenPayload{}
+	mi := &file_api_chat_v1_chat_proto_msgTypes[48]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *TokenPayload) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TokenPayload) ProtoMessage() {}
+
+func (x *TokenPayload) ProtoReflect() protoreflect.Message {
+	mi := &file_api_chat_v1_chat_proto_msgTypes[48]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TokenPayload.ProtoReflect.Descriptor instead.
+func (*TokenPayload) Descriptor() ([]byte, []int) {
+	return file_api_chat_v1_chat_proto_rawDescGZIP(), []int{48}
+}
+
+func (x *TokenPayload) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *TokenPayload) GetUserId() int32 {
+	if x != nil {
+		return x.UserId
+	}
+	return 0
+}
+
+func (x *TokenPayload) GetUsername() string {
+	if x != nil {
+		return x.Username
+	}
+	return ""
+}
+
+func (x *TokenPayload) GetIssuedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.IssuedAt
+	}
+	return nil
+}
+
+func (x *TokenPayload) GetExpiredAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.ExpiredAt
+	}
+	return nil
+}
+
+type RateLimited struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	MessageType   string                 `protobuf:"bytes,1,opt,name=message_type,json=messageType,proto3" json:"message_type,omitempty"` // Type of the request that was not handled
+	ClientMsgId   string                 `protobuf:"bytes,2,opt,name=client_msg_id,json=clientMsgId,proto3" json:"client_msg_id,omitempty"`
+	RetryAfterMs  int64                  `protobuf:"varint,3,opt,name=retry_after_ms,json=retryAfterMs,proto3" json:"retry_after_ms,omitempty"` // When the next request will be accepted
+	CreatedAt     *timestamppb.Timestamp `protobuf:"bytes,4,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RateLimited) Reset() {
+	*x = RateLimited{}
+	mi := &file_api_chat_v1_chat_proto_msgTypes[49]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RateLimited) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RateLimited) ProtoMessage() {}
+
+func (x *RateLimited) ProtoReflect() protoreflect.Message {
+	mi := &file_api_chat_v1_chat_proto_msgTypes[49]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RateLimited.ProtoReflect.Descriptor instead.
+func (*RateLimited) Descriptor() ([]byte, []int) {
+	return file_api_chat_v1_chat_proto_rawDescGZIP(), []int{49}
+}
+
+func (x *RateLimited) GetMessageType() string {
+	if x != nil {
+		return x.MessageType
+	}
+	return ""
+}
+
+func (x *RateLimited) GetClientMsgId() string {
+	if x != nil {
+		return x.ClientMsgId
+	}
+	return ""
+}
+
+func (x *RateLimited) GetRetryAfterMs() int64 {
+	if x != nil {
+		return x.RetryAfterMs
+	}
+	return 0
+}
+
+func (x *RateLimited) GetCreatedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.CreatedAt
+	}
+	return nil
+}
+
+type RateWarning struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Used          int32                  `protobuf:"varint,1,opt,name=used,proto3" json:"used,omitempty"`
+	Limit         int32                  `protobuf:"varint,2,opt,name=limit,proto3" json:"limit,omitempty"`
+	Remaining     int32                  `protobuf:"varint,3,opt,name=remaining,proto3" json:"remaining,omitempty"`
+	RatePerSecond float64                `protobuf:"fixed64,4,opt,name=rate_per_second,json=ratePerSecond,proto3" json:"rate_per_second,omitempty"`
+	ResetAfterMs  int64                  `protobuf:"varint,5,opt,name=reset_after_ms,json=resetAfterMs,proto3" json:"reset_after_ms,omitempty"`
+	CreatedAt     *timestamppb.Timestamp `protobuf:"bytes,6,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RateWarning) Reset() {
+	*x = RateWarning{}
+	mi := &file_api_chat_v1_chat_proto_msgTypes[50]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RateWarning) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RateWarning) ProtoMessage() {}
+
+func (x *RateWarning) ProtoReflect() protoreflect.Message {
+	mi := &file_api_chat_v1_chat_proto_msgTypes[50]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RateWarning.ProtoReflect.Descriptor instead.
+func (*RateWarning) Descriptor() ([]byte, []int) {
+	return file_api_chat_v1_chat_proto_rawDescGZIP(), []int{50}
+}
+
+func (x *RateWarning) GetUsed() int32 {
+	if x != nil {
+		return x.Used
+	}
+	return 0
+}
+
+func (x *RateWarning) GetLimit() int32 {
+	if x != nil {
+		return x.Limit
+	}
+	return 0
+}
+
+func (x *RateWarning) GetRemaining() int32 {
+	if x != nil {
+		return x.Remaining
+	}
+	return 0
+}
+
+func (x *RateWarning) GetRatePerSecond() float64 {
+	if x != nil {
+		return x.RatePerSecond
+	}
+	return 0
+}
+
+func (x *RateWarning) GetResetAfterMs() int64 {
+	if x != nil {
+		return x.ResetAfterMs
+	}
+	return 0
+}
+
+func (x *RateWarning) GetCreatedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.CreatedAt
+	}
+	return nil
+}
+
+// Error tells the client that a request was not handled
+type Error struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Code          string                 `protobuf:"bytes,1,opt,name=code,proto3" json:"code,omitempty"`                                  // e.g. "not_allowed", "message_too_long"
+	Error         string                 `protobuf:"bytes,2,opt,name=error,proto3" json:"error,omitempty"`                                // Human-readable detail, may change
+	MessageType   string                 `protobuf:"bytes,3,opt,name=message_type,json=messageType,proto3" json:"message_type,omitempty"` // Type of the request, if it had one
+	ClientMsgId   string                 `protobuf:"bytes,4,opt,name=client_msg_id,json=clientMsgId,proto3" json:"client_msg_id,omitempty"`
+	CreatedAt     *timestamppb.Timestamp `protobuf:"bytes,5,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Error) Reset() {
+	*x = Error{}
+	mi := &file_api_chat_v1_chat_proto_msgTypes[51]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Error) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Error) ProtoMessage() {}
+
+func (x *Error) ProtoReflect() protoreflect.Message {
+	mi := &file_api_chat_v1_chat_proto_msgTypes[51]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Error.ProtoReflect.Descriptor instead.
+func (*Error) Descriptor() ([]byte, []int) {
+	return file_api_chat_v1_chat_proto_rawDescGZIP(), []int{51}
+}
+
+func (x *Error) GetCode() string {
+	if x != nil {
+		return x.Code
+	}
+	return ""
+}
+
+func (x *Error) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+func (x *Error) GetMessageType() string {
+	if x != nil {
+		return x.MessageType
+	}
+	return ""
+}
+
+func (x *Error) GetClientMsgId() string {
+	if x != nil {
+		return x.ClientMsgId
+	}
+	return ""
+}
+
+func (x *Error) GetCreatedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.CreatedAt
+	}
+	return nil
+}
+
+// Batch carries the events held back for a low_bandwidth connection, oldest first
+type Batch struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Events        []*ChatEvent           `protobuf:"bytes,1,rep,name=events,proto3" json:"events,omitempty"`
+	CreatedAt     *timestamppb.Timestamp `protobuf:"bytes,2,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Batch) Reset() {
+	*x = Batch{}
+	mi := &file_api_chat_v1_chat_proto_msgTypes[52]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Batch) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Batch) ProtoMessage() {}
+
+func (x *Batch) ProtoReflect() protoreflect.Message {
+	mi := &file_api_chat_v1_chat_proto_msgTypes[52]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Batch.ProtoReflect.Descriptor instead.
+func (*Batch) Descriptor() ([]byte, []int) {
+	return file_api_chat_v1_chat_proto_rawDescGZIP(), []int{52}
+}
+
+func (x *Batch) GetEvents() []*ChatEvent {
+	if x != nil {
+		return x.Events
+	}
+	return nil
+}
+
+func (x *Batch) GetCreatedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.CreatedAt
+	}
+	return nil
+}
+
+var File_api_chat_v1_chat_proto protoreflect.FileDescriptor
+
+const file_api_chat_v1_chat_proto_rawDesc = "" +
+	"\n" +
+	"\x16api/chat/v1/chat.proto\x12\achat.v1\x1a\x1cgoogle/protobuf/struct.proto\x1a\x1fgoogle/protobuf/timestamp.proto\"\xc0\b\n" +
+	"\vChatRequest\x12B\n" +
+	"\x0fprivate_message\x18\x01 \x01(\v2\x17.chat.v1.PrivateMessageH\x00R\x0eprivateMessage\x12=\n" +
+	"\ftyping_start\x18\x02 \x01(\v2\x18.chat.v1.TypingIndicatorH\x00R\vtypingStart\x12;\n" +
+	"\vtyping_stop\x18\x03 \x01(\v2\x18.chat.v1.TypingIndicatorH\x00R\n" +
+	"typingStop\x129\n" +
+	"\fmessage_read\x18\x04 \x01(\v2\x14.chat.v1.MessageReadH\x00R\vmessageRead\x12@\n" +
+	"\fcontact_card\x18\x05 \x01(\v2\x1b.chat.v1.ContactCardRequestH\x00R\vcontactCard\x12F\n" +
+	"\x0edelete_message\x18\x06 \x01(\v2\x1d.chat.v1.DeleteMessageRequestH\x00R\rdeleteMessage\x12#\n" +
+	"\x04ping\x18\a \x01(\v2\r.chat.v1.PingH\x00R\x04ping\x12)\n" +
+	"\x06reauth\x18\b \x01(\v2\x0f.chat.v1.ReauthH\x00R\x06reauth\x129\n" +
+	"\fset_presence\x18\t \x01(\v2\x14.chat.v1.SetPresenceH\x00R\vsetPresence\x12@\n" +
+	"\froom_message\x18\n" +
+	" \x01(\v2\x1b.chat.v1.RoomMessageRequestH\x00R\vroomMessage\x12H\n" +
+	"\x11room_typing_start\x18\v \x01(\v2\x1a.chat.v1.RoomTypingRequestH\x00R\x0froomTypingStart\x12F\n" +
+	"\x10room_typing_stop\x18\f \x01(\v2\x1a.chat.v1.RoomTypingRequestH\x00R\x0eroomTypingStop\x12<\n" +
+	"\rsupport_reply\x18\r \x01(\v2\x15.chat.v1.SupportReplyH\x00R\fsupportReply\x12H\n" +
+	"\x11announcement_seen\x18\x0e \x01(\v2\x19.chat.v1.AnnouncementSeenH\x00R\x10announcementSeen\x12&\n" +
+	"\x05offer\x18\x0f \x01(\v2\x0e.chat.v1.OfferH\x00R\x05offer\x12)\n" +
+	"\x06answer\x18\x10 \x01(\v2\x0f.chat.v1.AnswerH\x00R\x06answer\x12<\n" +
+	"\rice_candidate\x18\x11 \x01(\v2\x15.chat.v1.IceCandidateH\x00R\ficeCandidate\x12)\n" +
+	"\x06hangup\x18\x12 \x01(\v2\x0f.chat.v1.HangupH\x00R\x06hangupB\t\n" +
+	"\arequest\"\xc3\x01\n" +
+	"\x0ePrivateMessage\x12!\n" +
+	"\frecipient_id\x18\x01 \x01(\x05R\vrecipientId\x12\x18\n" +
+	"\acontent\x18\x02 \x01(\tR\acontent\x12!\n" +
+	"\fcontent_type\x18\x03 \x01(\tR\vcontentType\x12\"\n" +
+	"\rclient_msg_id\x18\x04 \x01(\tR\vclientMsgId\x12-\n" +
+	"\x13reply_to_message_id\x18\x05 \x01(\x03R\x10replyToMessageId\"\xa6\x01\n" +
+	"\x0fTypingIndicator\x12!\n" +
+	"\frecipient_id\x18\x01 \x01(\x05R\vrecipientId\x12\x1b\n" +
+	"\tsender_id\x18\x02 \x01(\x05R\bsenderId\x129\n" +
+	"\n" +
+	"created_at\x18\x03 \x01(\v2\x1a.google.protobuf.TimestampR\tcreatedAt\x12\x18\n" +
+	"\aexpired\x18\x04 \x01(\bR\aexpired\"e\n" +
+	"\vMessageRead\x12\x1b\n" +
+	"\tsender_id\x18\x01 \x01(\x05R\bsenderId\x12\x1f\n" +
+	"\vmessage_ids\x18\x02 \x03(\x03R\n" +
+	"messageIds\x12\x18\n" +
+	"\bup_to_id\x18\x03 \x01(\x03R\x06upToId\"P\n" +
+	"\x12ContactCardRequest\x12!\n" +
+	"\frecipient_id\x18\x01 \x01(\x05R\vrecipientId\x12\x17\n" +
+	"\auser_id\x18\x02 \x01(\x05R\x06userId\"5\n" +
+	"\x14DeleteMessageRequest\x12\x1d\n" +
+	"\n" +
+	"message_id\x18\x01 \x01(\x03R\tmessageId\"_\n" +
+	"\x04Ping\x127\n" +
+	"\vclient_time\x18\x01 \x01(\v2\x16.google.protobuf.ValueR\n" +
+	"clientTime\x12\x1e\n" +
+	"\vlast_rtt_ms\x18\x02 \x01(\x01R\tlastRttMs\"\x1e\n" +
+	"\x06Reauth\x12\x14\n" +
+	"\x05token\x18\x01 \x01(\tR\x05token\")\n" +
+	"\vSetPresence\x12\x1a\n" +
+	"\bpresence\x18\x01 \x01(\tR\bpresence\"G\n" +
+	"\x12RoomMessageRequest\x12\x17\n" +
+	"\aroom_id\x18\x01 \x01(\x03R\x06roomId\x12\x18\n" +
+	"\acontent\x18\x02 \x01(\tR\acontent\",\n" +
+	"\x11RoomTypingRequest\x12\x17\n" +
+	"\aroom_id\x18\x01 \x01(\x03R\x06roomId\"E\n" +
+	"\fSupportReply\x12\x1b\n" +
+	"\tticket_id\x18\x01 \x01(\x03R\bticketId\x12\x18\n" +
+	"\acontent\x18\x02 \x01(\tR\acontent\";\n" +
+	"\x10AnnouncementSeen\x12'\n" +
+	"\x0fannouncement_id\x18\x01 \x01(\x03R\x0eannouncementId\"\xae\x01\n" +
+	"\x05Offer\x12,\n" +
+	"\x05offer\x18\x01 \x01(\v2\x16.google.protobuf.ValueR\x05offer\x12\x1b\n" +
+	"\tsender_id\x18\x02 \x01(\x05R\bsenderId\x12\x1f\n" +
+	"\vreceiver_id\x18\x03 \x01(\x05R\n" +
+	"receiverId\x129\n" +
+	"\n" +
+	"created_at\x18\x04 \x01(\v2\x1a.google.protobuf.TimestampR\tcreatedAt\"\xb1\x01\n" +
+	"\x06Answer\x12.\n" +
+	"\x06answer\x18\x01 \x01(\v2\x16.google.protobuf.ValueR\x06answer\x12\x1b\n" +
+	"\tsender_id\x18\x02 \x01(\x05R\bsenderId\x12\x1f\n" +
+	"\vreceiver_id\x18\x03 \x01(\x05R\n" +
+	"receiverId\x129\n" +
+	"\n" +
+	"created_at\x18\x04 \x01(\v2\x1a.google.protobuf.TimestampR\tcreatedAt\"\xbd\x01\n" +
+	"\fIceCandidate\x124\n" +
+	"\tcandidate\x18\x01 \x01(\v2\x16.google.protobuf.ValueR\tcandidate\x12\x1b\n" +
+	"\tsender_id\x18\x02 \x01(\x05R\bsenderId\x12\x1f\n" +
+	"\vreceiver_id\x18\x03 \x01(\x05R\n" +
+	"receiverId\x129\n" +
+	"\n" +
+	"created_at\x18\x04 \x01(\v2\x1a.google.protobuf.TimestampR\tcreatedAt\"\x81\x01\n" +
+	"\x06Hangup\x12\x1b\n" +
+	"\tsender_id\x18\x01 \x01(\x05R\bsenderId\x12\x1f\n" +
+	"\vreceiver_id\x18\x02 \x01(\x05R\n" +
+	"receiverId\x129\n" +
+	"\n" +
+	"created_at\x18\x03 \x01(\v2\x1a.google.protobuf.TimestampR\tcreatedAt\"\xc4\x14\n" +
+	"\tChatEvent\x12\x10\n" +
+	"\x03seq\x18\x01 \x01(\x03R\x03seq\x12;\n" +
+	"\fcapabilities\x18\x02 \x01(\v2\x15.chat.v1.CapabilitiesH\x00R\fcapabilities\x12E\n" +
+	"\x10incoming_message\x18\x03 \x01(\v2\x18.chat.v1.IncomingMessageH\x00R\x0fincomingMessage\x12'\n" +
+	"\x03ack\x18\x04 \x01(\v2\x13.chat.v1.MessageAckH\x00R\x03ack\x12F\n" +
+	"\x11delivery_deferred\x18\x05 \x01(\v2\x17.chat.v1.DeliveryStatusH\x00R\x10deliveryDeferred\x127\n" +
+	"\tdelivered\x18\x06 \x01(\v2\x17.chat.v1.DeliveryStatusH\x00R\tdelivered\x12E\n" +
+	"\x10delivery_receipt\x18\a \x01(\v2\x18.chat.v1.DeliveryReceiptH\x00R\x0fdeliveryReceipt\x12L\n" +
+	"\x13read_receipt_update\x18\b \x01(\v2\x1a.chat.v1.ReadReceiptUpdateH\x00R\x11readReceiptUpdate\x12=\n" +
+	"\ftyping_start\x18\t \x01(\v2\x18.chat.v1.TypingIndicatorH\x00R\vtypingStart\x12;\n" +
+	"\vtyping_stop\x18\n" +
+	" \x01(\v2\x18.chat.v1.TypingIndicatorH\x00R\n" +
+	"typingStop\x126\n" +
+	"\vuser_online\x18\v \x01(\v2\x13.chat.v1.UserStatusH\x00R\n" +
+	"userOnline\x128\n" +
+	"\fuser_offline\x18\f \x01(\v2\x13.chat.v1.UserStatusH\x00R\vuserOffline\x12@\n" +
+	"\x10presence_changed\x18\r \x01(\v2\x13.chat.v1.UserStatusH\x00R\x0fpresenceChanged\x12@\n" +
+	"\fcontact_card\x18\x0e \x01(\v2\x1b.chat.v1.ContactCardMessageH\x00R\vcontactCard\x12B\n" +
+	"\x0fmessage_deleted\x18\x0f \x01(\v2\x17.chat.v1.MessageDeletedH\x00R\x0emessageDeleted\x12E\n" +
+	"\x10messages_deleted\x18\x10 \x01(\v2\x18.chat.v1.MessagesDeletedH\x00R\x0fmessagesDeleted\x129\n" +
+	"\fmessage_sync\x18\x11 \x01(\v2\x14.chat.v1.MessageSyncH\x00R\vmessageSync\x12Q\n" +
+	"\x14conversation_cleared\x18\x12 \x01(\v2\x1c.chat.v1.ConversationClearedH\x00R\x13conversationCleared\x12d\n" +
+	"\x1bconversation_labels_changed\x18\x13 \x01(\v2\".chat.v1.ConversationLabelsChangedH\x00R\x19conversationLabelsChanged\x12Z\n" +
+	"\x17conversation_unarchived\x18\x14 \x01(\v2\x1f.chat.v1.ConversationUnarchivedH\x00R\x16conversationUnarchived\x12Q\n" +
+	"\x14conversation_unmuted\x18\x15 \x01(\v2\x1c.chat.v1.ConversationUnmutedH\x00R\x13conversationUnmuted\x129\n" +
+	"\froom_message\x18\x16 \x01(\v2\x14.chat.v1.RoomMessageH\x00R\vroomMessage\x12G\n" +
+	"\x12room_member_joined\x18\x17 \x01(\v2\x17.chat.v1.RoomMembershipH\x00R\x10roomMemberJoined\x12C\n" +
+	"\x10room_member_left\x18\x18 \x01(\v2\x17.chat.v1.RoomMembershipH\x00R\x0eroomMemberLeft\x12I\n" +
+	"\x12room_owner_changed\x18\x19 \x01(\v2\x19.chat.v1.RoomOwnerChangedH\x00R\x10roomOwnerChanged\x126\n" +
+	"\vroom_typing\x18\x1a \x01(\v2\x13.chat.v1.RoomTypingH\x00R\n" +
+	"roomTyping\x12B\n" +
+	"\x0fsupport_message\x18\x1b \x01(\v2\x17.chat.v1.SupportMessageH\x00R\x0esupportMessage\x12U\n" +
+	"\x16support_ticket_updated\x18\x1c \x01(\v2\x1d.chat.v1.SupportTicketUpdatedH\x00R\x14supportTicketUpdated\x12;\n" +
+	"\fannouncement\x18\x1d \x01(\v2\x15.chat.v1.AnnouncementH\x00R\fannouncement\x12<\n" +
+	"\rlogin_anomaly\x18\x1e \x01(\v2\x15.chat.v1.LoginAnomalyH\x00R\floginAnomaly\x12#\n" +
+	"\x04pong\x18\x1f \x01(\v2\r.chat.v1.PongH\x00R\x04pong\x124\n" +
+	"\treauth_ok\x18  \x01(\v2\x15.chat.v1.ReauthResultH\x00R\breauthOk\x12<\n" +
+	"\rreauth_failed\x18! \x01(\v2\x15.chat.v1.ReauthResultH\x00R\freauthFailed\x12<\n" +
+	"\rtoken_renewed\x18\" \x01(\v2\x15.chat.v1.TokenRenewedH\x00R\ftokenRenewed\x129\n" +
+	"\frate_limited\x18# \x01(\v2\x14.chat.v1.RateLimitedH\x00R\vrateLimited\x129\n" +
+	"\frate_warning\x18$ \x01(\v2\x14.chat.v1.RateWarningH\x00R\vrateWarning\x12&\n" +
+	"\x05error\x18% \x01(\v2\x0e.chat.v1.ErrorH\x00R\x05error\x12&\n" +
+	"\x05batch\x18& \x01(\v2\x0e.chat.v1.BatchH\x00R\x05batch\x12&\n" +
+	"\x05offer\x18' \x01(\v2\x0e.chat.v1.OfferH\x00R\x05offer\x12)\n" +
+	"\x06answer\x18( \x01(\v2\x0f.chat.v1.AnswerH\x00R\x06answer\x12<\n" +
+	"\rice_candidate\x18) \x01(\v2\x15.chat.v1.IceCandidateH\x00R\ficeCandidate\x12)\n" +
+	"\x06hangup\x18* \x01(\v2\x0f.chat.v1.HangupH\x00R\x06hangupB\a\n" +
+	"\x05event\"\x90\x01\n" +
+	"\fCapabilities\x12)\n" +
+	"\x10protocol_version\x18\x01 \x01(\x05R\x0fprotocolVersion\x12\x1a\n" +
+	"\bfeatures\x18\x02 \x03(\tR\bfeatures\x129\n" +
+	"\n" +
+	"created_at\x18\x03 \x01(\v2\x1a.google.protobuf.TimestampR\tcreatedAt\"\xc8\x02\n" +
+	"\x0fIncomingMessage\x12\x1b\n" +
+	"\tsender_id\x18\x01 \x01(\x05R\bsenderId\x12'\n" +
+	"\x0fsender_username\x18\x02 \x01(\tR\x0esenderUsername\x12\x18\n" +
+	"\acontent\x18\x03 \x01(\tR\acontent\x12!\n" +
+	"\fcontent_type\x18\x04 \x01(\tR\vcontentType\x12\x18\n" +
+	"\apreview\x18\x05 \x01(\tR\apreview\x129\n" +
+	"\n" +
+	"created_at\x18\x06 \x01(\v2\x1a.google.protobuf.TimestampR\tcreatedAt\x12\x14\n" +
+	"\x05muted\x18\a \x01(\bR\x05muted\x12\x14\n" +
+	"\x05quiet\x18\b \x01(\bR\x05quiet\x121\n" +
+	"\breply_to\x18\t \x01(\v2\x16.chat.v1.QuotedMessageR\areplyTo\"\xce\x01\n" +
+	"\rQuotedMessage\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\x03R\x02id\x12\x1b\n" +
+	"\tsender_id\x18\x02 \x01(\x05R\bsenderId\x12!\n" +
+	"\fcontent_type\x18\x03 \x01(\tR\vcontentType\x12\x18\n" +
+	"\apreview\x18\x04 \x01(\tR\apreview\x129\n" +
+	"\n" +
+	"created_at\x18\x05 \x01(\v2\x1a.google.protobuf.TimestampR\tcreatedAt\x12\x18\n" +
+	"\adeleted\x18\x06 \x01(\bR\adeleted\"\xb8\x01\n" +
+	"\n" +
+	"MessageAck\x12\"\n" +
+	"\rclient_msg_id\x18\x01 \x01(\tR\vclientMsgId\x12\x16\n" +
+	"\x06status\x18\x02 \x01(\tR\x06status\x12\x1d\n" +
+	"\n" +
+	"message_id\x18\x03 \x01(\x03R\tmessageId\x12\x14\n" +
+	"\x05error\x18\x04 \x01(\tR\x05error\x129\n" +
+	"\n" +
+	"created_at\x18\x05 \x01(\v2\x1a.google.protobuf.TimestampR\tcreatedAt\"\x8d\x01\n" +
+	"\x0eDeliveryStatus\x12\x1d\n" +
+	"\n" +
+	"message_id\x18\x01 \x01(\x03R\tmessageId\x12!\n" +
+	"\frecipient_id\x18\x02 \x01(\x05R\vrecipientId\x129\n" +
+	"\n" +
+	"created_at\x18\x03 \x01(\v2\x1a.google.protobuf.TimestampR\tcreatedAt\"\xcd\x01\n" +
+	"\x0fDeliveryReceipt\x12\x1d\n" +
+	"\n" +
+	"message_id\x18\x01 \x01(\x03R\tmessageId\x12!\n" +
+	"\frecipient_id\x18\x02 \x01(\x05R\vrecipientId\x12=\n" +
+	"\fdelivered_at\x18\x03 \x01(\v2\x1a.google.protobuf.TimestampR\vdeliveredAt\x129\n" +
+	"\n" +
+	"created_at\x18\x04 \x01(\v2\x1a.google.protobuf.TimestampR\tcreatedAt\"\xa9\x01\n" +
+	"\x11ReadReceiptUpdate\x12\x1b\n" +
+	"\treader_id\x18\x01 \x01(\x05R\breaderId\x12\x1b\n" +
+	"\tsender_id\x18\x02 \x01(\x05R\bsenderId\x12\x1f\n" +
+	"\vmessage_ids\x18\x03 \x03(\x03R\n" +
+	"messageIds\x129\n" +
+	"\n" +
+	"created_at\x18\x04 \x01(\v2\x1a.google.protobuf.TimestampR\tcreatedAt\"\xba\x01\n" +
+	"\n" +
+	"UserStatus\x12\x17\n" +
+	"\auser_id\x18\x01 \x01(\x05R\x06userId\x129\n" +
+	"\n" +
+	"created_at\x18\x02 \x01(\v2\x1a.google.protobuf.TimestampR\tcreatedAt\x12<\n" +
+	"\flast_seen_at\x18\x03 \x01(\v2\x1a.google.protobuf.TimestampR\n" +
+	"lastSeenAt\x12\x1a\n" +
+	"\bpresence\x18\x04 \x01(\tR\bpresence\"B\n" +
+	"\vContactCard\x12\x17\n" +
+	"\auser_id\x18\x01 \x01(\x05R\x06userId\x12\x1a\n" +
+	"\busername\x18\x02 \x01(\tR\busername\"\xbf\x01\n" +
+	"\x12ContactCardMessage\x12\x1b\n" +
+	"\tsender_id\x18\x01 \x01(\x05R\bsenderId\x12'\n" +
+	"\x0fsender_username\x18\x02 \x01(\tR\x0esenderUsername\x12(\n" +
+	"\x04card\x18\x03 \x01(\v2\x14.chat.v1.ContactCardR\x04card\x129\n" +
+	"\n" +
+	"created_at\x18\x04 \x01(\v2\x1a.google.protobuf.TimestampR\tcreatedAt\"\xc6\x01\n" +
+	"\x0eMessageDeleted\x12\x1d\n" +
+	"\n" +
+	"message_id\x18\x01 \x01(\x03R\tmessageId\x12\x1b\n" +
+	"\tsender_id\x18\x02 \x01(\x05R\bsenderId\x12\x1f\n" +
+	"\vreceiver_id\x18\x03 \x01(\x05R\n" +
+	"receiverId\x12\x1c\n" +
+	"\tmoderated\x18\x04 \x01(\bR\tmoderated\x129\n" +
+	"\n" +
+	"created_at\x18\x05 \x01(\v2\x1a.google.protobuf.TimestampR\tcreatedAt\"\xab\x01\n" +
+	"\x0fMessagesDeleted\x12\x1b\n" +
+	"\tsender_id\x18\x01 \x01(\x05R\bsenderId\x12\x1f\n" +
+	"\vreceiver_id\x18\x02 \x01(\x05R\n" +
+	"receiverId\x12\x1f\n" +
+	"\vmessage_ids\x18\x03 \x03(\x03R\n" +
+	"messageIds\x129\n" +
+	"\n" +
+	"created_at\x18\x04 \x01(\v2\x1a.google.protobuf.TimestampR\tcreatedAt\"\xb1\x01\n" +
+	"\vMessageSync\x122\n" +
+	"\bmessages\x18\x01 \x03(\v2\x16.chat.v1.SyncedMessageR\bmessages\x12\x17\n" +
+	"\alast_id\x18\x02 \x01(\x03R\x06lastId\x12\x1a\n" +
+	"\bcomplete\x18\x03 \x01(\bR\bcomplete\x129\n" +
+	"\n" +
+	"created_at\x18\x04 \x01(\v2\x1a.google.protobuf.TimestampR\tcreatedAt\"\xfc\x03\n" +
+	"\rSyncedMessage\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\x03R\x02id\x12\x1b\n" +
+	"\tsender_id\x18\x02 \x01(\x05R\bsenderId\x12\x1f\n" +
+	"\vreceiver_id\x18\x03 \x01(\x05R\n" +
+	"receiverId\x12\x18\n" +
+	"\acontent\x18\x04 \x01(\tR\acontent\x12!\n" +
+	"\fcontent_type\x18\x05 \x01(\tR\vcontentType\x129\n" +
+	"\n" +
+	"created_at\x18\x06 \x01(\v2\x1a.google.protobuf.TimestampR\tcreatedAt\x123\n" +
+	"\aread_at\x18\a \x01(\v2\x1a.google.protobuf.TimestampR\x06readAt\x129\n" +
+	"\n" +
+	"deleted_at\x18\b \x01(\v2\x1a.google.protobuf.TimestampR\tdeletedAt\x12=\n" +
+	"\fdelivered_at\x18\t \x01(\v2\x1a.google.protobuf.TimestampR\vdeliveredAt\x12-\n" +
+	"\x13reply_to_message_id\x18\n" +
+	" \x01(\x03R\x10replyToMessageId\x12\x14\n" +
+	"\x05state\x18\v \x01(\tR\x05state\x121\n" +
+	"\breply_to\x18\f \x01(\v2\x16.chat.v1.QuotedMessageR\areplyTo\"\x9b\x01\n" +
+	"\x13ConversationCleared\x12\x1d\n" +
+	"\n" +
+	"partner_id\x18\x01 \x01(\x05R\tpartnerId\x12*\n" +
+	"\x11cleared_before_id\x18\x02 \x01(\x03R\x0fclearedBeforeId\x129\n" +
+	"\n" +
+	"created_at\x18\x03 \x01(\v2\x1a.google.protobuf.TimestampR\tcreatedAt\"\x8d\x01\n" +
+	"\x19ConversationLabelsChanged\x12\x1d\n" +
+	"\n" +
+	"partner_id\x18\x01 \x01(\x05R\tpartnerId\x12\x16\n" +
+	"\x06labels\x18\x02 \x03(\tR\x06labels\x129\n" +
+	"\n" +
+	"created_at\x18\x03 \x01(\v2\x1a.google.protobuf.TimestampR\tcreatedAt\"r\n" +
+	"\x16ConversationUnarchived\x12\x1d\n" +
+	"\n" +
+	"partner_id\x18\x01 \x01(\x05R\tpartnerId\x129\n" +
+	"\n" +
+	"created_at\x18\x02 \x01(\v2\x1a.google.protobuf.TimestampR\tcreatedAt\"o\n" +
+	"\x13ConversationUnmuted\x12\x1d\n" +
+	"\n" +
+	"partner_id\x18\x01 \x01(\x05R\tpartnerId\x129\n" +
+	"\n" +
+	"created_at\x18\x02 \x01(\v2\x1a.google.protobuf.TimestampR\tcreatedAt\"\xfa\x01\n" +
+	"\vRoomMessage\x12\x1d\n" +
+	"\n" +
+	"message_id\x18\x01 \x01(\x03R\tmessageId\x12\x17\n" +
+	"\aroom_id\x18\x02 \x01(\x03R\x06roomId\x12\x1b\n" +
+	"\tsender_id\x18\x03 \x01(\x05R\bsenderId\x12'\n" +
+	"\x0fsender_username\x18\x04 \x01(\tR\x0esenderUsername\x12\x18\n" +
+	"\acontent\x18\x05 \x01(\tR\acontent\x12\x18\n" +
+	"\apreview\x18\x06 \x01(\tR\apreview\x129\n" +
+	"\n" +
+	"created_at\x18\a \x01(\v2\x1a.google.protobuf.TimestampR\tcreatedAt\"\x99\x01\n" +
+	"\x0eRoomMembership\x12\x17\n" +
+	"\aroom_id\x18\x01 \x01(\x03R\x06roomId\x12\x17\n" +
+	"\auser_id\x18\x02 \x01(\x05R\x06userId\x12\x1a\n" +
+	"\busername\x18\x03 \x01(\tR\busername\x129\n" +
+	"\n" +
+	"created_at\x18\x04 \x01(\v2\x1a.google.protobuf.TimestampR\tcreatedAt\"\xc5\x01\n" +
+	"\x10RoomOwnerChanged\x12\x17\n" +
+	"\aroom_id\x18\x01 \x01(\x03R\x06roomId\x12\x19\n" +
+	"\bowner_id\x18\x02 \x01(\x05R\aownerId\x12*\n" +
+	"\x11previous_owner_id\x18\x03 \x01(\x05R\x0fpreviousOwnerId\x12\x16\n" +
+	"\x06reason\x18\x04 \x01(\tR\x06reason\x129\n" +
+	"\n" +
+	"created_at\x18\x05 \x01(\v2\x1a.google.protobuf.TimestampR\tcreatedAt\"\xa5\x01\n" +
+	"\n" +
+	"RoomTyping\x12\x17\n" +
+	"\aroom_id\x18\x01 \x01(\x03R\x06roomId\x12\x14\n" +
+	"\x05count\x18\x02 \x01(\x05R\x05count\x12-\n" +
+	"\atypists\x18\x03 \x03(\v2\x13.chat.v1.RoomTypistR\atypists\x129\n" +
+	"\n" +
+	"created_at\x18\x04 \x01(\v2\x1a.google.protobuf.TimestampR\tcreatedAt\"A\n" +
+	"\n" +
+	"RoomTypist\x12\x17\n" +
+	"\auser_id\x18\x01 \x01(\x05R\x06userId\x12\x1a\n" +
+	"\busername\x18\x02 \x01(\tR\busername\"\x94\x02\n" +
+	"\x0eSupportMessage\x12\x1b\n" +
+	"\tticket_id\x18\x01 \x01(\x03R\bticketId\x12#\n" +
+	"\rticket_status\x18\x02 \x01(\tR\fticketStatus\x12\x1f\n" +
+	"\vcustomer_id\x18\x03 \x01(\x05R\n" +
+	"customerId\x12+\n" +
+	"\x11customer_username\x18\x04 \x01(\tR\x10customerUsername\x12\x1d\n" +
+	"\n" +
+	"message_id\x18\x05 \x01(\x03R\tmessageId\x12\x18\n" +
+	"\acontent\x18\x06 \x01(\tR\acontent\x129\n" +
+	"\n" +
+	"created_at\x18\a \x01(\v2\x1a.google.protobuf.TimestampR\tcreatedAt\"\xa1\x01\n" +
+	"\x14SupportTicketUpdated\x12\x1b\n" +
+	"\tticket_id\x18\x01 \x01(\x03R\bticketId\x12\x16\n" +
+	"\x06status\x18\x02 \x01(\tR\x06status\x12\x19\n" +
+	"\bagent_id\x18\x03 \x01(\x05R\aagentId\x129\n" +
+	"\n" +
+	"created_at\x18\x04 \x01(\v2\x1a.google.protobuf.TimestampR\tcreatedAt\"\x8c\x01\n" +
+	"\fAnnouncement\x12'\n" +
+	"\x0fannouncement_id\x18\x01 \x01(\x03R\x0eannouncementId\x12\x18\n" +
+	"\acontent\x18\x02 \x01(\tR\acontent\x129\n" +
+	"\n" +
+	"created_at\x18\x03 \x01(\v2\x1a.google.protobuf.TimestampR\tcreatedAt\"\x87\x01\n" +
+	"\fLoginAnomaly\x12\x1d\n" +
+	"\n" +
+	"ip_address\x18\x01 \x01(\tR\tipAddress\x12\x1d\n" +
+	"\n" +
+	"user_agent\x18\x02 \x01(\tR\tuserAgent\x129\n" +
+	"\n" +
+	"created_at\x18\x03 \x01(\v2\x1a.google.protobuf.TimestampR\tcreatedAt\"\xc4\x01\n" +
+	"\x04Pong\x127\n" +
+	"\vclient_time\x18\x01 \x01(\v2\x16.google.protobuf.ValueR\n" +
+	"clientTime\x12H\n" +
+	"\x12server_received_at\x18\x02 \x01(\v2\x1a.google.protobuf.TimestampR\x10serverReceivedAt\x129\n" +
+	"\n" +
+	"created_at\x18\x03 \x01(\v2\x1a.google.protobuf.TimestampR\tcreatedAt\"\x9a\x01\n" +
+	"\fReauthResult\x129\n" +
+	"\n" +
+	"expired_at\x18\x01 \x01(\v2\x1a.google.protobuf.TimestampR\texpiredAt\x12\x14\n" +
+	"\x05error\x18\x02 \x01(\tR\x05error\x129\n" +
+	"\n" +
+	"created_at\x18\x03 \x01(\v2\x1a.google.protobuf.TimestampR\tcreatedAt\"\x90\x01\n" +
+	"\fTokenRenewed\x12\x14\n" +
+	"\x05token\x18\x01 \x01(\tR\x05token\x12/\n" +
+	"\apayload\x18\x02 \x01(\v2\x15.chat.v1.TokenPayloadR\apayload\x129\n" +
+	"\n" +
+	"created_at\x18\x03 \x01(\v2\x1a.google.protobuf.TimestampR\tcreatedAt\"\xc7\x01\n" +
+	"\fTokenPayload\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12\x17\n" +
+	"\auser_id\x18\x02 \x01(\x05R\x06userId\x12\x1a\n" +
+	"\busername\x18\x03 \x01(\tR\busername\x127\n" +
+	"\tissued_at\x18\x04 \x01(\v2\x1a.google.protobuf.TimestampR\bissuedAt\x129\n" +
+	"\n" +
+	"expired_at\x18\x05 \x01(\v2\x1a.google.protobuf.TimestampR\texpiredAt\"\xb5\x01\n" +
+	"\vRateLimited\x12!\n" +
+	"\fmessage_type\x18\x01 \x01(\tR\vmessageType\x12\"\n" +
+	"\rclient_msg_id\x18\x02 \x01(\tR\vclientMsgId\x12$\n" +
+	"\x0eretry_after_ms\x18\x03 \x01(\x03R\fretryAfterMs\x129\n" +
+	"\n" +
+	"created_at\x18\x04 \x01(\v2\x1a.google.protobuf.TimestampR\tcreatedAt\"\xde\x01\n" +
+	"\vRateWarning\x12\x12\n" +
+	"\x04used\x18\x01 \x01(\x05R\x04used\x12\x14\n" +
+	"\x05limit\x18\x02 \x01(\x05R\x05limit\x12\x1c\n" +
+	"\tremaining\x18\x03 \x01(\x05R\tremaining\x12&\n" +
+	"\x0frate_per_second\x18\x04 \x01(\x01R\rratePerSecond\x12$\n" +
+	"\x0ereset_after_ms\x18\x05 \x01(\x03R\fresetAfterMs\x129\n" +
+	"\n" +
+	"created_at\x18\x06 \x01(\v2\x1a.google.protobuf.TimestampR\tcreatedAt\"\xb3\x01\n" +
+	"\x05Error\x12\x12\n" +
+	"\x04code\x18\x01 \x01(\tR\x04code\x12\x14\n" +
+	"\x05error\x18\x02 \x01(\tR\x05error\x12!\n" +
+	"\fmessage_type\x18\x03 \x01(\tR\vmessageType\x12\"\n" +
+	"\rclient_msg_id\x18\x04 \x01(\tR\vclientMsgId\x129\n" +
+	"\n" +
+	"created_at\x18\x05 \x01(\v2\x1a.google.protobuf.TimestampR\tcreatedAt\"n\n" +
+	"\x05Batch\x12*\n" +
+	"\x06events\x18\x01 \x03(\v2\x12.chat.v1.ChatEventR\x06events\x129\n" +
+	"\n" +
+	"created_at\x18\x02 \x01(\v2\x1a.google.protobuf.TimestampR\tcreatedAt2B\n" +
+	"\x04Chat\x12:\n" +
+	"\n" +
+	"ChatStream\x12\x14.chat.v1.ChatRequest\x1a\x12.chat.v1.ChatEvent(\x010\x01B.Z,websocket-simple-chat-app/api/chat/v1;chatv1b\x06proto3"
+
+var (
+	file_api_chat_v1_chat_proto_rawDescOnce sync.Once
+	file_api_chat_v1_chat_proto_rawDescData []byte
+)
+
+func file_api_chat_v1_chat_proto_rawDescGZIP() []byte {
+	file_api_chat_v1_chat_proto_rawDescOnce.Do(func() {
+		file_api_chat_v1_chat_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_api_chat_v1_chat_proto_rawDesc), len(file_api_chat_v1_chat_proto_rawDesc)))
+	})
+	return file_api_chat_v1_chat_proto_rawDescData
+}
+
+var file_api_chat_v1_chat_proto_msgTypes = make([]protoimpl.MessageInfo, 53)
+var file_api_chat_v1_chat_proto_goTypes = []any{
+	(*ChatRequest)(nil),               // 0: chat.v1.ChatRequest
+	(*PrivateMessage)(nil),            // 1: chat.v1.PrivateMessage
+	(*TypingIndicator)(nil),           // 2: chat.v1.TypingIndicator
+	(*MessageRead)(nil),               // 3: chat.v1.MessageRead
+	(*ContactCardRequest)(nil),        // 4: chat.v1.ContactCardRequest
+	(*DeleteMessageRequest)(nil),      // 5: chat.v1.DeleteMessageRequest
+	(*Ping)(nil),                      // 6: chat.v1.Ping
+	(*Reauth)(nil),                    // 7: chat.v1.Reauth
+	(*SetPresence)(nil),               // 8: chat.v1.SetPresence
+	(*RoomMessageRequest)(nil),        // 9: chat.v1.RoomMessageRequest
+	(*RoomTypingRequest)(nil),         // 10: chat.v1.RoomTypingRequest
+	(*SupportReply)(nil),              // 11: chat.v1.SupportReply
+	(*AnnouncementSeen)(nil),          // 12: chat.v1.AnnouncementSeen
+	(*Offer)(nil),                     // 13: chat.v1.Offer
+	(*Answer)(nil),                    // 14: chat.v1.Answer
+	(*IceCandidate)(nil),              // 15: chat.v1.IceCandidate
+	(*Hangup)(nil),                    // 16: chat.v1.Hangup
+	(*ChatEvent)(nil),                 // 17: chat.v1.ChatEvent
+	(*Capabilities)(nil),              // 18: chat.v1.Capabilities
+	(*IncomingMessage)(nil),           // 19: chat.v1.IncomingMessage
+	(*QuotedMessage)(nil),             // 20: chat.v1.QuotedMessage
+	(*MessageAck)(nil),                // 21: chat.v1.MessageAck
+	(*DeliveryStatus)(nil),            // 22: chat.v1.DeliveryStatus
+	(*DeliveryReceipt)(nil),           // 23: chat.v1.DeliveryReceipt
+	(*ReadReceiptUpdate)(nil),         // 24: chat.v1.ReadReceiptUpdate
+	(*UserStatus)(nil),                // 25: chat.v1.UserStatus
+	(*ContactCard)(nil),               // 26: chat.v1.ContactCard
+	(*ContactCardMessage)(nil),        // 27: chat.v1.ContactCardMessage
+	(*MessageDeleted)(nil),            // 28: chat.v1.MessageDeleted
+	(*MessagesDeleted)(nil),           // 29: chat.v1.MessagesDeleted
+	(*MessageSync)(nil),               // 30: chat.v1.MessageSync
+	(*SyncedMessage)(nil),             // 31: chat.v1.SyncedMessage
+	(*ConversationCleared)(nil),       // 32: chat.v1.ConversationCleared
+	(*ConversationLabelsChanged)(nil), // 33: chat.v1.ConversationLabelsChanged
+	(*ConversationUnarchived)(nil),    // 34: chat.v1.ConversationUnarchived
+	(*ConversationUnmuted)(nil),       // 35: chat.v1.ConversationUnmuted
+	(*RoomMessage)(nil),               // 36: chat.v1.RoomMessage
+	(*RoomMembership)(nil),            // 37: chat.v1.RoomMembership
+	(*RoomOwnerChanged)(nil),          // 38: chat.v1.RoomOwnerChanged
+	(*RoomTyping)(nil),                // 39: chat.v1.RoomTyping
+	(*RoomTypist)(nil),                // 40: chat.v1.RoomTypist
+	(*SupportMessage)(nil),            // 41: chat.v1.SupportMessage
+	(*SupportTicketUpdated)(nil),      // 42: chat.v1.SupportTicketUpdated
+	(*Announcement)(nil),              // 43: chat.v1.Announcement
+	(*LoginAnomaly)(nil),              // 44: chat.v1.LoginAnomaly
+	(*Pong)(nil),                      // 45: chat.v1.Pong
+	(*ReauthResult)(nil),              // 46: chat.v1.ReauthResult
+	(*TokenRenewed)(nil),              // 47: chat.v1.TokenRenewed
+	(*TokenPayload)(nil),              // 48: chat.v1.TokenPayload
+	(*RateLimited)(nil),               // 49: chat.v1.RateLimited
+	(*RateWarning)(nil),               // 50: chat.v1.RateWarning
+	(*Error)(nil),                     // 51: chat.v1.Error
+	(*Batch)(nil),                     // 52: chat.v1.Batch
+	(*timestamppb.Timestamp)(nil),     // 53: google.protobuf.Timestamp
+	(*structpb.Value)(nil),            // 54: google.protobuf.Value
+}
+var file_api_chat_v1_chat_proto_depIdxs = []int32{
+	1,   // 0: chat.v1.ChatRequest.private_message:type_name -> chat.v1.PrivateMessage
+	2,   // 1: chat.v1.ChatRequest.typing_start:type_name -> chat.v1.TypingIndicator
+	2,   // 2: chat.v1.ChatRequest.typing_stop:type_name -> chat.v1.TypingIndicator
+	3,   // 3: chat.v1.ChatRequest.message_read:type_name -> chat.v1.MessageRead
+	4,   // 4: chat.v1.ChatRequest.contact_card:type_name -> chat.v1.ContactCardRequest
+	5,   // 5: chat.v1.ChatRequest.delete_message:type_name -> chat.v1.DeleteMessageRequest
+	6,   // 6: chat.v1.ChatRequest.ping:type_name -> chat.v1.Ping
+	7,   // 7: chat.v1.ChatRequest.reauth:type_name -> chat.v1.Reauth
+	8,   // 8: chat.v1.ChatRequest.set_presence:type_name -> chat.v1.SetPresence
+	9,   // 9: chat.v1.ChatRequest.room_message:type_name -> chat.v1.RoomMessageRequest
+	10,  // 10: chat.v1.ChatRequest.room_typing_start:type_name -> chat.v1.RoomTypingRequest
+	10,  // 11: chat.v1.ChatRequest.room_typing_stop:type_name -> chat.v1.RoomTypingRequest
+	11,  // 12: chat.v1.ChatRequest.support_reply:type_name -> chat.v1.SupportReply
+	12,  // 13: chat.v1.ChatRequest.announcement_seen:type_name -> chat.v1.AnnouncementSeen
+	13,  // 14: chat.v1.ChatRequest.offer:type_name -> chat.v1.Offer
+	14,  // 15: chat.v1.ChatRequest.answer:type_name -> chat.v1.Answer
+	15,  // 16: chat.v1.ChatRequest.ice_candidate:type_name -> chat.v1.IceCandidate
+	16,  // 17: chat.v1.ChatRequest.hangup:type_name -> chat.v1.Hangup
+	53,  // 18: chat.v1.TypingIndicator.created_at:type_name -> google.protobuf.Timestamp
+	54,  // 19: chat.v1.Ping.client_time:type_name -> google.protobuf.Value
+	54,  // 20: chat.v1.Offer.offer:type_name -> google.protobuf.Value
+	53,  // 21: chat.v1.Offer.created_at:type_name -> google.protobuf.Timestamp
+	54,  // 22: chat.v1.Answer.answer:type_name -> google.protobuf.Value
+	53,  // 23: chat.v1.Answer.created_at:type_name -> google.protobuf.Timestamp
+	54,  // 24: chat.v1.IceCandidate.candidate:type_name -> google.protobuf.Value
+	53,  // 25: chat.v1.IceCandidate.created_at:type_name -> google.protobuf.Timestamp
+	53,  // 26: chat.v1.Hangup.created_at:type_name -> google.protobuf.Timestamp
+	18,  // 27: chat.v1.ChatEvent.capabilities:type_name -> chat.v1.Capabilities
+	19,  // 28: chat.v1.ChatEvent.incoming_message:type_name -> chat.v1.IncomingMessage
+	21,  // 29: chat.v1.ChatEvent.ack:type_name -> chat.v1.MessageAck
+	22,  // 30: chat.v1.ChatEvent.delivery_deferred:type_name -> chat.v1.DeliveryStatus
+	22,  // 31: chat.v1.ChatEvent.delivered:type_name -> chat.v1.DeliveryStatus
+	23,  // 32: chat.v1.ChatEvent.delivery_receipt:type_name -> chat.v1.DeliveryReceipt
+	24,  // 33: chat.v1.ChatEvent.read_receipt_update:type_name -> chat.v1.ReadReceiptUpdate
+	2,   // 34: chat.v1.ChatEvent.typing_start:type_name -> chat.v1.TypingIndicator
+	2,   // 35: chat.v1.ChatEvent.typing_stop:type_name -> chat.v1.TypingIndicator
+	25,  // 36: chat.v1.ChatEvent.user_online:type_name -> chat.v1.UserStatus
+	25,  // 37: chat.v1.ChatEvent.user_offline:type_name -> chat.v1.UserStatus
+	25,  // 38: chat.v1.ChatEvent.presence_changed:type_name -> chat.v1.UserStatus
+	27,  // 39: chat.v1.ChatEvent.contact_card:type_name -> chat.v1.ContactCardMessage
+	28,  // 40: chat.v1.ChatEvent.message_deleted:type_name -> chat.v1.MessageDeleted
+	29,  // 41: chat.v1.ChatEvent.messages_deleted:type_name -> chat.v1.MessagesDeleted
+	30,  // 42: chat.v1.ChatEvent.message_sync:type_name -> chat.v1.MessageSync
+	32,  // 43: chat.v1.ChatEvent.conversation_cleared:type_name -> chat.v1.ConversationCleared
+	33,  // 44: chat.v1.ChatEvent.conversation_labels_changed:type_name -> chat.v1.ConversationLabelsChanged
+	34,  // 45: chat.v1.ChatEvent.conversation_unarchived:type_name -> chat.v1.ConversationUnarchived
+	35,  // 46: chat.v1.ChatEvent.conversation_unmuted:type_name -> chat.v1.ConversationUnmuted
+	36,  // 47: chat.v1.ChatEvent.room_message:type_name -> chat.v1.RoomMessage
+	37,  // 48: chat.v1.ChatEvent.room_member_joined:type_name -> chat.v1.RoomMembership
+	37,  // 49: chat.v1.ChatEvent.room_member_left:type_name -> chat.v1.RoomMembership
+	38,  // 50: chat.v1.ChatEvent.room_owner_changed:type_name -> chat.v1.RoomOwnerChanged
+	39,  // 51: chat.v1.ChatEvent.room_typing:type_name -> chat.v1.RoomTyping
+	41,  // 52: chat.v1.ChatEvent.support_message:type_name -> chat.v1.SupportMessage
+	42,  // 53: chat.v1.ChatEvent.support_ticket_updated:type_name -> chat.v1.SupportTicketUpdated
+	43,  // 54: chat.v1.ChatEvent.announcement:type_name -> chat.v1.Announcement
+	44,  // 55: chat.v1.ChatEvent.login_anomaly:type_name -> chat.v1.LoginAnomaly
+	45,  // 56: chat.v1.ChatEvent.pong:type_name -> chat.v1.Pong
+	46,  // 57: chat.v1.ChatEvent.reauth_ok:type_name -> chat.v1.ReauthResult
+	46,  // 58: chat.v1.ChatEvent.reauth_failed:type_name -> chat.v1.ReauthResult
+	47,  // 59: chat.v1.ChatEvent.token_renewed:type_name -> chat.v1.TokenRenewed
+	49,  // 60: chat.v1.ChatEvent.rate_limited:type_name -> chat.v1.RateLimited
+	50,  // 61: chat.v1.ChatEvent.rate_warning:type_name -> chat.v1.RateWarning
+	51,  // 62: chat.v1.ChatEvent.error:type_name -> chat.v1.Error
+	52,  // 63: chat.v1.ChatEvent.batch:type_name -> chat.v1.Batch
+	13,  // 64: chat.v1.ChatEvent.offer:type_name -> chat.v1.Offer
+	14,  // 65: chat.v1.ChatEvent.answer:type_name -> chat.v1.Answer
+	15,  // 66: chat.v1.ChatEvent.ice_candidate:type_name -> chat.v1.IceCandidate
+	16,  // 67: chat.v1.ChatEvent.hangup:type_name -> chat.v1.Hangup
+	53,  // 68: chat.v1.Capabilities.created_at:type_name -> google.protobuf.Timestamp
+	53,  // 69: chat.v1.IncomingMessage.created_at:type_name -> google.protobuf.Timestamp
+	20,  // 70: chat.v1.IncomingMessage.reply_to:type_name -> chat.v1.QuotedMessage
+	53,  // 71: chat.v1.QuotedMessage.created_at:type_name -> google.protobuf.Timestamp
+	53,  // 72: chat.v1.MessageAck.created_at:type_name -> google.protobuf.Timestamp
+	53,  // 73: chat.v1.DeliveryStatus.created_at:type_name -> google.protobuf.Timestamp
+	53,  // 74: chat.v1.DeliveryReceipt.delivered_at:type_name -> google.protobuf.Timestamp
+	53,  // 75: chat.v1.DeliveryReceipt.created_at:type_name -> google.protobuf.Timestamp
+	53,  // 76: chat.v1.ReadReceiptUpdate.created_at:type_name -> google.protobuf.Timestamp
+	53,  // 77: chat.v1.UserStatus.created_at:type_name -> google.protobuf.Timestamp
+	53,  // 78: chat.v1.UserStatus.last_seen_at:type_name -> google.protobuf.Timestamp
+	26,  // 79: chat.v1.ContactCardMessage.card:type_name -> chat.v1.ContactCard
+	53,  // 80: chat.v1.ContactCardMessage.created_at:type_name -> google.protobuf.Timestamp
+	53,  // 81: chat.v1.MessageDeleted.created_at:type_name -> google.protobuf.Timestamp
+	53,  // 82: chat.v1.MessagesDeleted.created_at:type_name -> google.protobuf.Timestamp
+	31,  // 83: chat.v1.MessageSync.messages:type_name -> chat.v1.SyncedMessage
+	53,  // 84: chat.v1.MessageSync.created_at:type_name -> google.protobuf.Timestamp
+	53,  // 85: chat.v1.SyncedMessage.created_at:type_name -> google.protobuf.Timestamp
+	53,  // 86: chat.v1.SyncedMessage.read_at:type_name -> google.protobuf.Timestamp
+	53,  // 87: chat.v1.SyncedMessage.deleted_at:type_name -> google.protobuf.Timestamp
+	53,  // 88: chat.v1.SyncedMessage.delivered_at:type_name -> google.protobuf.Timestamp
+	20,  // 89: chat.v1.SyncedMessage.reply_to:type_name -> chat.v1.QuotedMessage
+	53,  // 90: chat.v1.ConversationCleared.created_at:type_name -> google.protobuf.Timestamp
+	53,  // 91: chat.v1.ConversationLabelsChanged.created_at:type_name -> google.protobuf.Timestamp
+	53,  // 92: chat.v1.ConversationUnarchived.created_at:type_name -> google.protobuf.Timestamp
+	53,  // 93: chat.v1.ConversationUnmuted.created_at:type_name -> google.protobuf.Timestamp
+	53,  // 94: chat.v1.RoomMessage.created_at:type_name -> google.protobuf.Timestamp
+	53,  // 95: chat.v1.RoomMembership.created_at:type_name -> google.protobuf.Timestamp
+	53,  // 96: chat.v1.RoomOwnerChanged.created_at:type_name -> google.protobuf.Timestamp
+	40,  // 97: chat.v1.RoomTyping.typists:type_name -> chat.v1.RoomTypist
+	53,  // 98: chat.v1.RoomTyping.created_at:type_name -> google.protobuf.Timestamp
+	53,  // 99: chat.v1.SupportMessage.created_at:type_name -> google.protobuf.Timestamp
+	53,  // 100: chat.v1.SupportTicketUpdated.created_at:type_name -> google.protobuf.Timestamp
+	53,  // 101: chat.v1.Announcement.created_at:type_name -> google.protobuf.Timestamp
+	53,  // 102: chat.v1.LoginAnomaly.created_at:type_name -> google.protobuf.Timestamp
+	54,  // 103: chat.v1.Pong.client_time:type_name -> google.protobuf.Value
+	53,  // 104: chat.v1.Pong.server_received_at:type_name -> google.protobuf.Timestamp
+	53,  // 105: chat.v1.Pong.created_at:type_name -> google.protobuf.Timestamp
+	53,  // 106: chat.v1.ReauthResult.expired_at:type_name -> google.protobuf.Timestamp
+	53,  // 107: chat.v1.ReauthResult.created_at:type_name -> google.protobuf.Timestamp
+	48,  // 108: chat.v1.TokenRenewed.payload:type_name -> chat.v1.TokenPayload
+	53,  // 109: chat.v1.TokenRenewed.created_at:type_name -> google.protobuf.Timestamp
+	53,  // 110: chat.v1.TokenPayload.issued_at:type_name -> google.protobuf.Timestamp
+	53,  // 111: chat.v1.TokenPayload.expired_at:type_name -> google.protobuf.Timestamp
+	53,  // 112: chat.v1.RateLimited.created_at:type_name -> google.protobuf.Timestamp
+	53,  // 113: chat.v1.RateWarning.created_at:type_name -> google.protobuf.Timestamp
+	53,  // 114: chat.v1.Error.created_at:type_name -> google.protobuf.Timestamp
+	17,  // 115: chat.v1.Batch.events:type_name -> chat.v1.ChatEvent
+	53,  // 116: chat.v1.Batch.created_at:type_name -> google.protobuf.Timestamp
+	0,   // 117: chat.v1.Chat.ChatStream:input_type -> chat.v1.ChatRequest
+	17,  // 118: chat.v1.Chat.ChatStream:output_type -> chat.v1.ChatEvent
+	118, // [118:119] is the sub-list for method output_type
+	117, // [117:118] is the sub-list for method input_type
+	117, // [117:117] is the sub-list for extension type_name
+	117, // [117:117] is the sub-list for extension extendee
+	0,   // [0:117] is the sub-list for field type_name
+}
+
+func init() { file_api_chat_v1_chat_proto_init() }
+func file_api_chat_v1_chat_proto_init() {
+	if File_api_chat_v1_chat_proto != nil {
+		return
+	}
+	file_api_chat_v1_chat_proto_msgTypes[0].OneofWrappers = []any{
+		(*ChatRequest_PrivateMessage)(nil),
+		(*ChatRequest_TypingStart)(nil),
+		(*ChatRequest_TypingStop)(nil),
+		(*ChatRequest_MessageRead)(nil),
+		(*ChatRequest_ContactCard)(nil),
+		(*ChatRequest_DeleteMessage)(nil),
+		(*ChatRequest_Ping)(nil),
+		(*ChatRequest_Reauth)(nil),
+		(*ChatRequest_SetPresence)(nil),
+		(*ChatRequest_RoomMessage)(nil),
+		(*ChatRequest_RoomTypingStart)(nil),
+		(*ChatRequest_RoomTypingStop)(nil),
+		(*ChatRequest_SupportReply)(nil),
+		(*ChatRequest_AnnouncementSeen)(nil),
+		(*ChatRequest_Offer)(nil),
+		(*ChatRequest_Answer)(nil),
+		(*ChatRequest_IceCandidate)(nil),
+		(*ChatRequest_Hangup)(nil),
+	}
+	file_api_chat_v1_chat_proto_msgTypes[17].OneofWrappers = []any{
+		(*ChatEvent_Capabilities)(nil),
+		(*ChatEvent_IncomingMessage)(nil),
+		(*ChatEvent_Ack)(nil),
+		(*ChatEvent_DeliveryDeferred)(nil),
+		(*ChatEvent_Delivered)(nil),
+		(*ChatEvent_DeliveryReceipt)(nil),
+		(*ChatEvent_ReadReceiptUpdate)(nil),
+		(*ChatEvent_TypingStart)(nil),
+		(*ChatEvent_TypingStop)(nil),
+		(*ChatEvent_UserOnline)(nil),
+		(*ChatEvent_UserOffline)(nil),
+		(*ChatEvent_PresenceChanged)(nil),
+		(*ChatEvent_ContactCard)(nil),
+		(*ChatEvent_MessageDeleted)(nil),
+		(*ChatEvent_MessagesDeleted)(nil),
+		(*ChatEvent_MessageSync)(nil),
+		(*ChatEvent_ConversationCleared)(nil),
+		(*ChatEvent_ConversationLabelsChanged)(nil),
+		(*ChatEvent_ConversationUnarchived)(nil),
+		(*ChatEvent_ConversationUnmuted)(nil),
+		(*ChatEvent_RoomMessage)(nil),
+		(*ChatEvent_RoomMemberJoined)(nil),
+		(*ChatEvent_RoomMemberLeft)(nil),
+		(*ChatEvent_RoomOwnerChanged)(nil),
+		(*ChatEvent_RoomTyping)(nil),
+		(*ChatEvent_SupportMessage)(nil),
+		(*ChatEvent_SupportTicketUpdated)(nil),
+		(*ChatEvent_Announcement)(nil),
+		(*ChatEvent_LoginAnomaly)(nil),
+		(*ChatEvent_Pong)(nil),
+		(*ChatEvent_ReauthOk)(nil),
+		(*ChatEvent_ReauthFailed)(nil),
+		(*ChatEvent_TokenRenewed)(nil),
+		(*ChatEvent_RateLimited)(nil),
+		(*ChatEvent_RateWarning)(nil),
+		(*ChatEvent_Error)(nil),
+		(*ChatEvent_Batch)(nil),
+		(*ChatEvent_Offer)(nil),
+		(*ChatEvent_Answer)(nil),
+		(*ChatEvent_IceCandidate)(nil),
+		(*ChatEvent_Hangup)(nil),
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_api_chat_v1_chat_proto_rawDesc), len(file_api_chat_v1_chat_proto_rawDesc)),
+			NumEnums:      0,
+			NumMessages:   53,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_api_chat_v1_chat_proto_goTypes,
+		DependencyIndexes: file_api_chat_v1_chat_proto_depIdxs,
+		MessageInfos:      file_api_chat_v1_chat_proto_msgTypes,
+	}.Build()
+	File_api_chat_v1_chat_proto = out.File
+	file_api_chat_v1_chat_proto_goTypes = nil
+	file_api_chat_v1_chat_proto_depIdxs = nil
+}
@@ -0,0 +1,152 @@
+package main
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"errors"
+	"log"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	db "websocket-simple-chat-app/db/sqlc"
+	"websocket-simple-chat-app/hub"
+)
+
+// API keys let integrations (dashboards, cron jobs) act as an account without a PASETO token.
+// Only the SHA-256 of a key is stored; the key itself is returned once, when it is created. Keys
+// are checked by the api_key authenticator (see auth.go).
+const (
+	apiKeyHeader     = "X-API-Key"
+	apiKeyPrefix     = "chat_"
+	apiKeyRandomSize = 32 // Bytes of randomness, hex encoded in the key
+)
+
+// generateAPIKey returns a new random API key
+func generateAPIKey() (string, error) {
+	random := make([]byte, apiKeyRandomSize)
+	if _, err := rand.Read(random); err != nil {
+		return "", err
+	}
+	return apiKeyPrefix + hex.EncodeToString(random), nil
+}
+
+// hashAPIKey returns the stored form of an API key
+func hashAPIKey(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])
+}
+
+// errInvalidAPIKey is returned for unknown and revoked API keys
+var errInvalidAPIKey = errors.New("invalid API key")
+
+// lookupAPIKey returns the active API key and its account. The account must be active and not
+// suspended.
+func lookupAPIKey(store *db.Queries, key string) (db.ApiKey, db.User, error) {
+	apiKey, err := store.GetActiveAPIKeyByHash(context.Background(), hashAPIKey(key))
+	if err != nil {
+		if err != sql.ErrNoRows {
+			log.Printf("Error looking up API key: %v", err)
+		}
+		return db.ApiKey{}, db.User{}, errInvalidAPIKey
+	}
+
+	account, err := store.GetUserByID(context.Background(), apiKey.UserID)
+	if err != nil || account.DeactivatedAt.Valid || accountSuspended(account) {
+		return db.ApiKey{}, db.User{}, errors.New("API key account is deactivated or suspended")
+	}
+	return apiKey, account, nil
+}
+
+// issueAPIKey creates an API key acting as the account and returns it with its stored form
+func issueAPIKey(store *db.Queries, userID int32, name string, scopes []string) (string, db.ApiKey, error) {
+	key, err := generateAPIKey()
+	if err != nil {
+		return "", db.ApiKey{}, err
+	}
+
+	apiKey, err := store.CreateAPIKey(context.Background(), db.CreateAPIKeyParams{
+		UserID:  userID,
+		Name:    name,
+		KeyHash: hashAPIKey(key),
+		Scopes:  normalizeAPIKeyScopes(scopes),
+	})
+	if err != nil {
+		return "", db.ApiKey{}, err
+	}
+	return key, apiKey, nil
+}
+
+func newCreatedAPIKey(apiKey db.ApiKey, key string) gin.H {
+	return gin.H{
+		"id":         apiKey.ID,
+		"user_id":    apiKey.UserID,
+		"name":       apiKey.Name,
+		"scopes":     apiKey.Scopes,
+		"created_at": apiKey.CreatedAt,
+		"key":        key,
+	}
+}
+
+// createAPIKeyHandler issues an API key acting as the given account. The key is only returned in this response.
+func createAPIKeyHandler(store *db.Queries) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		type createAPIKeyRequest struct {
+			UserID int32    `json:"user_id" binding:"required,min=1"`
+			Name   string   `json:"name" binding:"required,max=100"`                 // What the key is used for
+			Scopes []string `json:"scopes" binding:"omitempty,dive,oneof=send read"` // Both by default
+		}
+		var req createAPIKeyRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		account, err := store.GetUserByID(context.Background(), req.UserID)
+		if err != nil || account.DeactivatedAt.Valid || account.Role == roleGuest {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "user_id is not an active account"})
+			return
+		}
+
+		key, apiKey, err := issueAPIKey(store, account.ID, req.Name, req.Scopes)
+		if err != nil {
+			log.Printf("Error creating API key for user %d: %v", account.ID, err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create API key"})
+			return
+		}
+
+		log.Printf("API key %d created for user %d", apiKey.ID, account.ID)
+		c.JSON(http.StatusCreated, newCreatedAPIKey(apiKey, key))
+	}
+}
+
+// revokeAPIKeyHandler revokes an API key. Requests using it are rejected from then on, and the
+// WebSocket connections of its account are closed; those using other credentials reconnect.
+func revokeAPIKeyHandler(store *db.Queries, connectionHub *hub.Hub) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		keyID, err := strconv.ParseInt(c.Param("key_id"), 10, 64)
+		if err != nil || keyID <= 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid API key ID"})
+			return
+		}
+
+		userID, err := store.RevokeAPIKey(context.Background(), keyID)
+		if err != nil {
+			if err == sql.ErrNoRows {
+				c.JSON(http.StatusNotFound, gin.H{"error": "API key not found or already revoked"})
+				return
+			}
+			log.Printf("Error revoking API key %d: %v", keyID, err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to revoke API key"})
+			return
+		}
+
+		connectionHub.DisconnectUser(userID, wsCloseKicked, "API key revoked")
+		log.Printf("API key %d of user %d revoked", keyID, userID)
+		c.Status(http.StatusNoContent)
+	}
+}
@@ -0,0 +1,357 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"websocket-simple-chat-app/bruteforce"
+	"websocket-simple-chat-app/config"
+	db "websocket-simple-chat-app/db/sqlc"
+	"websocket-simple-chat-app/ldap"
+	"websocket-simple-chat-app/metrics"
+	"websocket-simple-chat-app/token"
+)
+
+// HTTP requests are authenticated by a chain of authenticators per route group, configured with
+// AUTH_METHODS and friends (see config.Config). The authenticators are tried in order: the first
+// one that finds its kind of credentials in the request decides, and the others are not tried.
+// Whatever the method, handlers find a *token.Payload under authorizationPayloadKey and the method
+// under authorizationMethodKey. The /ws handshake and reauth only accept PASETO tokens.
+
+// Authentication method names, as used in the configuration
+const (
+	authMethodBearer = "bearer"
+	authMethodCookie = "cookie"
+	authMethodAPIKey = "api_key"
+	authMethodLDAP   = "ldap"
+)
+
+// authorizationMethodKey is the gin context key of the method that authenticated the request
+const authorizationMethodKey = "authorization_method"
+
+// LDAP brute-force protection: block an IP for 15 minutes after 10 failed binds within 5 minutes
+const (
+	ldapAuthMaxFailures   = 10
+	ldapAuthFailureWindow = 5 * time.Minute
+	ldapAuthBlockDuration = 15 * time.Minute
+	ldapAuthTimeout       = 5 * time.Second
+)
+
+// errNoCredentials is returned by authenticators when the request has none of their credentials
+var errNoCredentials = errors.New("no credentials")
+
+// authFailure is an authentication error answered with another status than 401
+type authFailure struct {
+	status  int
+	message string
+}
+
+func (f *authFailure) Error() string { return f.message }
+
+// authenticator checks one kind of credentials
+type authenticator interface {
+	// authenticate returns the payload of the request's user, errNoCredentials if the request
+	// carries none of its credentials, or why the credentials were refused
+	authenticate(c *gin.Context) (*token.Payload, error)
+}
+
+// --- Authenticators ---
+
+// bearerAuthenticator accepts PASETO access tokens in the Authorization header. Tokens revoked by
+// logging out are refused.
+type bearerAuthenticator struct {
+	tokenMaker token.Maker
+	store      *db.Queries
+}
+
+func (a bearerAuthenticator) authenticate(c *gin.Context) (*token.Payload, error) {
+	authorizationHeader := c.GetHeader(authorizationHeaderKey)
+	if len(authorizationHeader) == 0 {
+		return nil, errNoCredentials
+	}
+
+	fields := strings.Fields(authorizationHeader)
+	if len(fields) < 2 {
+		return nil, errors.New("invalid authorization header format")
+	}
+
+	authorizationType := strings.ToLower(fields[0])
+	if authorizationType != authorizationTypeBearer {
+		// Basic credentials are the ldap method's
+		if authorizationType == "basic" {
+			return nil, errNoCredentials
+		}
+		return nil, fmt.Errorf("unsupported authorization type %s", authorizationType)
+	}
+	return verifyAccessToken(a.tokenMaker, a.store, fields[1])
+}
+
+// cookieAuthenticator accepts the PASETO access token in a cookie, set at login (see
+// setAuthCookie). The cookie is SameSite=Strict, so other sites cannot make requests with it.
+type cookieAuthenticator struct {
+	name       string
+	tokenMaker token.Maker
+	store      *db.Queries
+}
+
+func (a cookieAuthenticator) authenticate(c *gin.Context) (*token.Payload, error) {
+	accessToken, err := c.Cookie(a.name)
+	if err != nil || accessToken == "" {
+		return nil, errNoCredentials
+	}
+	return verifyAccessToken(a.tokenMaker, a.store, accessToken)
+}
+
+// verifyAccessToken verifies a PASETO access token and checks that it was not revoked. Tokens
+// issued before a deactivation or suspension stay valid until they expire, so the account is
+// checked as well, like on /ws.
+func verifyAccessToken(tokenMaker token.Maker, store *db.Queries, accessToken string) (*token.Payload, error) {
+	payload, err := tokenMaker.VerifyToken(accessToken)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkTokenRevoked(store, payload); err != nil {
+		if !errors.Is(err, errTokenRevoked) {
+			log.Printf("Error checking revocation of token %s: %v", payload.ID, err)
+			return nil, &authFailure{http.StatusInternalServerError, "Failed to verify token"}
+		}
+		return nil, err
+	}
+
+	account, err := store.GetUserByID(context.Background(), payload.UserID)
+	if err != nil {
+		if err != sql.ErrNoRows {
+			log.Printf("Error fetching user %d for token %s: %v", payload.UserID, payload.ID, err)
+			return nil, &authFailure{http.StatusInternalServerError, "Failed to verify token"}
+		}
+		return nil, &authFailure{http.StatusForbidden, "account deactivated"}
+	}
+	if account.DeactivatedAt.Valid {
+		return nil, &authFailure{http.StatusForbidden, "account deactivated"}
+	}
+	if accountSuspended(account) {
+		return nil, &authFailure{http.StatusForbidden, "account suspended"}
+	}
+	return payload, nil
+}
+
+// apiKeyAuthenticator accepts API keys in the X-API-Key header (see api_keys.go). Their scopes are
+// checked by requireScope (see bots.go).
+type apiKeyAuthenticator struct {
+	store *db.Queries
+}
+
+func (a apiKeyAuthenticator) authenticate(c *gin.Context) (*token.Payload, error) {
+	key := c.GetHeader(apiKeyHeader)
+	if key == "" {
+		return nil, errNoCredentials
+	}
+
+	apiKey, account, err := lookupAPIKey(a.store, key)
+	if err != nil {
+		return nil, err
+	}
+	c.Set(apiKeyScopesKey, apiKey.Scopes) // For requireScope
+	return accountPayload(account), nil
+}
+
+// ldapAuthenticator accepts HTTP Basic credentials that bind to the LDAP directory. The username
+// must also be a local account, which the request acts as. IPs failing too often are blocked.
+type ldapAuthenticator struct {
+	client *ldap.Client
+	store  *db.Queries
+	guard  *bruteforce.Guard
+}
+
+func (a ldapAuthenticator) authenticate(c *gin.Context) (*token.Payload, error) {
+	username, password, ok := c.Request.BasicAuth()
+	if !ok {
+		return nil, errNoCredentials
+	}
+	if blocked, retryAfter := a.guard.Blocked(c.ClientIP()); blocked {
+		c.Header("Retry-After", strconv.Itoa(int(math.Ceil(retryAfter.Seconds()))))
+		return nil, &authFailure{http.StatusTooManyRequests, "Too many failed authentications, try again later"}
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), ldapAuthTimeout)
+	defer cancel()
+	if err := a.client.Authenticate(ctx, username, password); err != nil {
+		if errors.Is(err, ldap.ErrInvalidCredentials) {
+			if a.guard.RecordFailure(c.ClientIP()) {
+				log.Printf("Auth Warning: Blocking IP %s for %v after %d failed LDAP binds", c.ClientIP(), ldapAuthBlockDuration, ldapAuthMaxFailures)
+			}
+			return nil, errors.New("invalid credentials")
+		}
+		log.Printf("Error checking LDAP credentials of %q: %v", username, err)
+		return nil, &authFailure{http.StatusServiceUnavailable, "Failed to verify credentials"}
+	}
+	a.guard.RecordSuccess(c.ClientIP())
+
+	account, err := a.store.GetUserByUsername(context.Background(), username)
+	if err != nil {
+		if err != sql.ErrNoRows {
+			log.Printf("Error fetching account of LDAP user %q: %v", username, err)
+			return nil, &authFailure{http.StatusInternalServerError, "Failed to verify credentials"}
+		}
+		return nil, errors.New("no account for this directory user")
+	}
+	if account.DeactivatedAt.Valid || accountSuspended(account) {
+		return nil, errors.New("account is deactivated or suspended")
+	}
+	return accountPayload(account), nil
+}
+
+// accountPayload is the payload of a request authenticated without a token. It has no token ID,
+// so there is nothing to log out from.
+func accountPayload(account db.User) *token.Payload {
+	now := time.Now().UTC()
+	return &token.Payload{UserID: account.ID, Username: account.Username, IssuedAt: now, ExpiredAt: now}
+}
+
+// --- Chains ---
+
+// namedAuthenticator is an authenticator with its method name
+type namedAuthenticator struct {
+	method string
+	authenticator
+}
+
+// authChain is the list of authenticators of a route group, tried in order
+type authChain []namedAuthenticator
+
+// middleware authenticates requests with the chain and stores the payload and method in the
+// context. Requests without credentials of any method are refused with 401.
+func (chain authChain) middleware() gin.HandlerFunc {
+	methods := make([]string, len(chain))
+	for i, a := range chain {
+		methods[i] = a.method
+	}
+	missing := "no credentials provided, accepted: " + strings.Join(methods, ", ")
+	if len(chain) == 1 && chain[0].method == authMethodBearer {
+		missing = "authorization header is not provided"
+	}
+
+	return func(ctx *gin.Context) {
+		for _, a := range chain {
+			payload, err := a.authenticate(ctx)
+			if errors.Is(err, errNoCredentials) {
+				continue
+			}
+			if err != nil {
+				var failure *authFailure
+				if errors.As(err, &failure) {
+					ctx.AbortWithStatusJSON(failure.status, gin.H{"error": failure.message})
+					return
+				}
+				ctx.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+				return
+			}
+
+			ctx.Set(authorizationPayloadKey, payload)
+			ctx.Set(authorizationMethodKey, a.method)
+			ctx.Next()
+			return
+		}
+		ctx.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": missing})
+	}
+}
+
+// authChains builds the chains of the configured methods. The LDAP client and guard are only
+// created when a chain uses them.
+type authChains struct {
+	cfg        config.Config
+	tokenMaker token.Maker
+	store      *db.Queries
+
+	ldap ldapAuthenticator
+}
+
+func newAuthChains(cfg config.Config, tokenMaker token.Maker, store *db.Queries) *authChains {
+	return &authChains{cfg: cfg, tokenMaker: tokenMaker, store: store}
+}
+
+// build returns the chain of the methods, or an error for unknown methods or missing settings
+func (b *authChains) build(methods []string) (authChain, error) {
+	chain := make(authChain, 0, len(methods))
+	for _, method := range methods {
+		var a authenticator
+		switch method {
+		case authMethodBearer:
+			a = bearerAuthenticator{tokenMaker: b.tokenMaker, store: b.store}
+		case authMethodCookie:
+			a = cookieAuthenticator{name: b.cfg.AuthCookieName, tokenMaker: b.tokenMaker, store: b.store}
+		case authMethodAPIKey:
+			a = apiKeyAuthenticator{store: b.store}
+		case authMethodLDAP:
+			if b.ldap.client == nil {
+				if b.cfg.LDAPURL == "" || b.cfg.LDAPUserDN == "" {
+					return nil, errors.New("the ldap method needs LDAP_URL and LDAP_USER_DN")
+				}
+				client, err := ldap.New(b.cfg.LDAPURL, b.cfg.LDAPUserDN)
+				if err != nil {
+					return nil, err
+				}
+				b.ldap = ldapAuthenticator{
+					client: client,
+					store:  b.store,
+					guard:  bruteforce.NewGuard(ldapAuthMaxFailures, ldapAuthFailureWindow, ldapAuthBlockDuration),
+				}
+				metrics.RegisterBruteForceGuard("ldap", b.ldap.guard)
+				go func(guard *bruteforce.Guard) {
+					for range time.Tick(wsAuthSweepInterval) {
+						guard.Sweep()
+					}
+				}(b.ldap.guard)
+			}
+			a = b.ldap
+		default:
+			return nil, fmt.Errorf("unknown authentication method %q", method)
+		}
+		chain = append(chain, namedAuthenticator{method: method, authenticator: a})
+	}
+	return chain, nil
+}
+
+// middleware builds the chain of a setting at startup: invalid settings stop the server
+func (b *authChains) middleware(setting string, methods []string) gin.HandlerFunc {
+	chain, err := b.build(methods)
+	if err != nil {
+		log.Fatalf("invalid %s: %v", setting, err)
+	}
+	return chain.middleware()
+}
+
+// cookieEnabled reports whether any route group accepts the cookie method
+func cookieEnabled(cfg config.Config) bool {
+	for _, methods := range [][]string{cfg.AuthMethods, cfg.AdminAuthMethods, cfg.SupportAuthMethods, cfg.IntegrationAuthMethods} {
+		for _, method := range methods {
+			if method == authMethodCookie {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// setAuthCookie stores the access token in the cookie of the cookie method. It is not readable by
+// scripts and only sent over HTTPS to this site.
+func setAuthCookie(c *gin.Context, name string, accessToken string, expiresAt time.Time) {
+	c.SetSameSite(http.SameSiteStrictMode)
+	c.SetCookie(name, accessToken, int(time.Until(expiresAt).Seconds()), "/", "", true, true)
+}
+
+// clearAuthCookie deletes the cookie of the cookie method
+func clearAuthCookie(c *gin.Context, name string) {
+	c.SetSameSite(http.SameSiteStrictMode)
+	c.SetCookie(name, "", -1, "/", "", true, true)
+}
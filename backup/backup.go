@@ -0,0 +1,358 @@
+// Package backup writes and restores logical dumps of the chat database: every table of the
+// schema (users, messages, rooms, sessions, settings, ...) as JSON, taken from one consistent
+// snapshot, compressed and encrypted with a passphrase (see crypt.go). A dump is restored into a
+// fresh database at the same schema version, e.g. on a new server. Uploads are not supported yet, so
+// there are no attachments to back up.
+//
+// Inside the encryption, a dump is gzip-compressed JSON: a Header, then one Record per row.
+package backup
+
+import (
+	"compress/gzip"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+// FormatVersion is the version of the dump format written by Dump
+const FormatVersion = 1
+
+// restoreBatchSize is the number of rows inserted per statement on restore
+const restoreBatchSize = 500
+
+var (
+	// ErrNotEmpty is returned when restoring into a database that already has data
+	ErrNotEmpty = errors.New("backup: the database is not empty, restore into a fresh one")
+	// ErrSchemaMismatch is returned when the database is not at the schema version of the dump
+	ErrSchemaMismatch = errors.New("backup: the database schema version differs from the dump's")
+)
+
+// Header describes a dump. It is the first value of the dump.
+type Header struct {
+	Format        int              `json:"format"`
+	SchemaVersion uint             `json:"schema_version"` // The migration the dumped database was at
+	CreatedAt     time.Time        `json:"created_at"`
+	Tables        map[string]int64 `json:"tables"` // Rows per table
+}
+
+// Record is a row of a table
+type Record struct {
+	Table string          `json:"table"`
+	Row   json.RawMessage `json:"row"`
+}
+
+// Dump writes a dump of the database to w. All tables are read in one read-only transaction, so the
+// dump is consistent while the server keeps running.
+func Dump(ctx context.Context, conn *sql.DB, w io.Writer) (Header, error) {
+	tx, err := conn.BeginTx(ctx, &sql.TxOptions{Isolation: sql.LevelRepeatableRead, ReadOnly: true})
+	if err != nil {
+		return Header{}, err
+	}
+	defer tx.Rollback()
+
+	header := Header{Format: FormatVersion, CreatedAt: time.Now().UTC(), Tables: make(map[string]int64)}
+	if header.SchemaVersion, err = schemaVersion(ctx, tx); err != nil {
+		return Header{}, err
+	}
+	tables, err := listTables(ctx, tx)
+	if err != nil {
+		return Header{}, err
+	}
+	for _, table := range tables {
+		var count int64
+		if err := tx.QueryRowContext(ctx, "SELECT COUNT(*) FROM "+pq.QuoteIdentifier(table)).Scan(&count); err != nil {
+			return Header{}, fmt.Errorf("counting %s: %w", table, err)
+		}
+		header.Tables[table] = count
+	}
+
+	gz := gzip.NewWriter(w)
+	encoder := json.NewEncoder(gz)
+	if err := encoder.Encode(header); err != nil {
+		return Header{}, err
+	}
+	for _, table := range tables {
+		if err := dumpTable(ctx, tx, encoder, table); err != nil {
+			return Header{}, fmt.Errorf("dumping %s: %w", table, err)
+		}
+	}
+	if err := gz.Close(); err != nil {
+		return Header{}, err
+	}
+	return header, nil
+}
+
+func dumpTable(ctx context.Context, tx *sql.Tx, encoder *json.Encoder, table string) error {
+	rows, err := tx.QueryContext(ctx, "SELECT row_to_json(t)::text FROM "+pq.QuoteIdentifier(table)+" t")
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var row string
+		if err := rows.Scan(&row); err != nil {
+			return err
+		}
+		if err := encoder.Encode(Record{Table: table, Row: json.RawMessage(row)}); err != nil {
+			return err
+		}
+	}
+	return rows.Err()
+}
+
+// ReadHeader returns the header of a dump without restoring it, e.g. to migrate the target
+// database to its schema version first
+func ReadHeader(r io.Reader) (Header, error) {
+	gz, err := gzip.NewReader(r)
+	if err != nil {
+		return Header{}, err
+	}
+	var header Header
+	if err := json.NewDecoder(gz).Decode(&header); err != nil {
+		return Header{}, err
+	}
+	return header, checkHeader(header)
+}
+
+func checkHeader(header Header) error {
+	if header.Format != FormatVersion {
+		return fmt.Errorf("backup: unsupported dump format %d", header.Format)
+	}
+	return nil
+}
+
+// Restore loads a dump into conn, a database migrated to the dump's schema version and without
+// data. Everything is restored in one transaction: on error the database is left empty. Foreign
+// keys are dropped while loading and added back at the end, which checks them, and sequences are
+// moved past the restored IDs.
+func Restore(ctx context.Context, conn *sql.DB, r io.Reader) (Header, error) {
+	gz, err := gzip.NewReader(r)
+	if err != nil {
+		return Header{}, err
+	}
+	decoder := json.NewDecoder(gz)
+	var header Header
+	if err := decoder.Decode(&header); err != nil {
+		return Header{}, err
+	}
+	if err := checkHeader(header); err != nil {
+		return Header{}, err
+	}
+
+	tx, err := conn.BeginTx(ctx, nil)
+	if err != nil {
+		return Header{}, err
+	}
+	defer tx.Rollback()
+
+	version, err := schemaVersion(ctx, tx)
+	if err != nil {
+		return Header{}, err
+	}
+	if version != header.SchemaVersion {
+		return Header{}, fmt.Errorf("%w (database %d, dump %d)", ErrSchemaMismatch, version, header.SchemaVersion)
+	}
+	tables, err := listTables(ctx, tx)
+	if err != nil {
+		return Header{}, err
+	}
+	for _, table := range tables {
+		var hasRows bool
+		if err := tx.QueryRowContext(ctx, "SELECT EXISTS (SELECT 1 FROM "+pq.QuoteIdentifier(table)+")").Scan(&hasRows); err != nil {
+			return Header{}, err
+		}
+		if hasRows {
+			return Header{}, ErrNotEmpty
+		}
+	}
+
+	foreignKeys, err := dropForeignKeys(ctx, tx)
+	if err != nil {
+		return Header{}, fmt.Errorf("dropping foreign keys: %w", err)
+	}
+	restored, err := insertRecords(ctx, tx, decoder, tables)
+	if err != nil {
+		return Header{}, err
+	}
+	for table, count := range header.Tables {
+		if restored[table] != count {
+			return Header{}, fmt.Errorf("backup: %s has %d rows in the dump, header says %d", table, restored[table], count)
+		}
+	}
+	for _, fk := range foreignKeys {
+		if _, err := tx.ExecContext(ctx, fmt.Sprintf("ALTER TABLE %s ADD CONSTRAINT %s %s", fk.table, pq.QuoteIdentifier(fk.name), fk.definition)); err != nil {
+			return Header{}, fmt.Errorf("restoring foreign key %s: %w", fk.name, err)
+		}
+	}
+	if err := resetSequences(ctx, tx); err != nil {
+		return Header{}, fmt.Errorf("resetting sequences: %w", err)
+	}
+	return header, tx.Commit()
+}
+
+// insertRecords inserts the records of the decoder in batches per table and returns the rows
+// inserted per table
+func insertRecords(ctx context.Context, tx *sql.Tx, decoder *json.Decoder, tables []string) (map[string]int64, error) {
+	known := make(map[string]bool, len(tables))
+	for _, table := range tables {
+		known[table] = true
+	}
+	restored := make(map[string]int64)
+	var batch []json.RawMessage
+	batchTable := ""
+
+	flush := func() error {
+		if len(batch) == 0 {
+			return nil
+		}
+		rows, err := json.Marshal(batch)
+		if err != nil {
+			return err
+		}
+		quoted := pq.QuoteIdentifier(batchTable)
+		query := fmt.Sprintf("INSERT INTO %s SELECT * FROM json_populate_recordset(NULL::%s, $1)", quoted, quoted)
+		if _, err := tx.ExecContext(ctx, query, string(rows)); err != nil {
+			return fmt.Errorf("restoring %s: %w", batchTable, err)
+		}
+		restored[batchTable] += int64(len(batch))
+		batch = batch[:0]
+		return nil
+	}
+
+	for {
+		var record Record
+		err := decoder.Decode(&record)
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		if !known[record.Table] {
+			return nil, fmt.Errorf("backup: the dump has rows of unknown table %q", record.Table)
+		}
+		if record.Table != batchTable || len(batch) == restoreBatchSize {
+			if err := flush(); err != nil {
+				return nil, err
+			}
+			batchTable = record.Table
+		}
+		batch = append(batch, record.Row)
+	}
+	if err := flush(); err != nil {
+		return nil, err
+	}
+	return restored, nil
+}
+
+type foreignKey struct {
+	table      string // Quoted by PostgreSQL if needed
+	name       string
+	definition string
+}
+
+func dropForeignKeys(ctx context.Context, tx *sql.Tx) ([]foreignKey, error) {
+	rows, err := tx.QueryContext(ctx, `SELECT conrelid::regclass::text, conname, pg_get_constraintdef(oid)
+FROM pg_constraint
+WHERE contype = 'f' AND connamespace = 'public'::regnamespace
+ORDER BY conname`)
+	if err != nil {
+		return nil, err
+	}
+	var foreignKeys []foreignKey
+	for rows.Next() {
+		var fk foreignKey
+		if err := rows.Scan(&fk.table, &fk.name, &fk.definition); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		foreignKeys = append(foreignKeys, fk)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	for _, fk := range foreignKeys {
+		if _, err := tx.ExecContext(ctx, fmt.Sprintf("ALTER TABLE %s DROP CONSTRAINT %s", fk.table, pq.QuoteIdentifier(fk.name))); err != nil {
+			return nil, err
+		}
+	}
+	return foreignKeys, nil
+}
+
+// resetSequences moves the sequences of serial columns past the highest restored value
+func resetSequences(ctx context.Context, tx *sql.Tx) error {
+	rows, err := tx.QueryContext(ctx, `SELECT table_name, column_name FROM information_schema.columns
+WHERE table_schema = 'public' AND column_default LIKE 'nextval(%'`)
+	if err != nil {
+		return err
+	}
+	var columns [][2]string
+	for rows.Next() {
+		var table, column string
+		if err := rows.Scan(&table, &column); err != nil {
+			rows.Close()
+			return err
+		}
+		columns = append(columns, [2]string{table, column})
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	for _, c := range columns {
+		table, column := pq.QuoteIdentifier(c[0]), pq.QuoteIdentifier(c[1])
+		query := fmt.Sprintf("SELECT setval(pg_get_serial_sequence($1, $2), COALESCE(MAX(%s), 0) + 1, false) FROM %s", column, table)
+		if _, err := tx.ExecContext(ctx, query, table, c[1]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// schemaVersion returns the migration version of the database, which must not be dirty
+func schemaVersion(ctx context.Context, tx *sql.Tx) (uint, error) {
+	var version int64
+	var dirty bool
+	err := tx.QueryRowContext(ctx, "SELECT version, dirty FROM schema_migrations LIMIT 1").Scan(&version, &dirty)
+	if err != nil {
+		return 0, fmt.Errorf("reading the schema version: %w", err)
+	}
+	if dirty {
+		return 0, fmt.Errorf("backup: schema version %d is dirty, fix the failed migration first", version)
+	}
+	return uint(version), nil
+}
+
+// derivedTables are maintained by triggers from other tables, so they are rebuilt while those are
+// restored rather than dumped
+var derivedTables = []string{"conversation_summaries"}
+
+// listTables returns the tables of the schema, without golang-migrate's and the derived ones
+func listTables(ctx context.Context, tx *sql.Tx) ([]string, error) {
+	rows, err := tx.QueryContext(ctx, `SELECT table_name FROM information_schema.tables
+WHERE table_schema = 'public' AND table_type = 'BASE TABLE' AND table_name <> 'schema_migrations'
+  AND table_name <> ALL($1)
+ORDER BY table_name`, pq.Array(derivedTables))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var tables []string
+	for rows.Next() {
+		var table string
+		if err := rows.Scan(&table); err != nil {
+			return nil, err
+		}
+		tables = append(tables, table)
+	}
+	return tables, rows.Err()
+}
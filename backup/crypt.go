@@ -0,0 +1,224 @@
+package backup
+
+import (
+	"bufio"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"golang.org/x/crypto/scrypt"
+)
+
+// Backup files are encrypted with AES-256-GCM under a key derived from a passphrase with scrypt. The
+// content is sealed in chunks, so files of any size are written and read as streams:
+//
+//	magic (8) | salt (16) | nonce prefix (7) | chunks
+//	chunk: ciphertext length (4, big endian) | ciphertext of up to chunkSize bytes
+//
+// The nonce of a chunk is the prefix, the chunk's number (4, big endian) and a byte that is 1 for
+// the last chunk only, so reordered, dropped or truncated chunks fail to open.
+
+const (
+	magic            = "CHATBAK1"
+	saltSize         = 16
+	noncePrefixSize  = 7
+	chunkSize        = 64 * 1024
+	minPassphraseLen = 12
+)
+
+// scrypt parameters, as recommended for interactive use in 2017 and still above the minimum
+const (
+	scryptN = 1 << 15
+	scryptR = 8
+	scryptP = 1
+)
+
+var (
+	// ErrPassphraseTooShort is returned for passphrases shorter than 12 bytes
+	ErrPassphraseTooShort = fmt.Errorf("backup: the passphrase must have at least %d characters", minPassphraseLen)
+	// ErrNotBackup is returned when a file does not start like a backup
+	ErrNotBackup = errors.New("backup: not a backup file")
+	// ErrDecrypt is returned for a wrong passphrase or a damaged or truncated file
+	ErrDecrypt = errors.New("backup: wrong passphrase, or the file is damaged or truncated")
+)
+
+func newAEAD(passphrase string, salt []byte) (cipher.AEAD, error) {
+	key, err := scrypt.Key([]byte(passphrase), salt, scryptN, scryptR, scryptP, 32)
+	if err != nil {
+		return nil, err
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+func chunkNonce(prefix []byte, counter uint32, last bool) []byte {
+	nonce := make([]byte, 0, 12)
+	nonce = append(nonce, prefix...)
+	nonce = binary.BigEndian.AppendUint32(nonce, counter)
+	if last {
+		return append(nonce, 1)
+	}
+	return append(nonce, 0)
+}
+
+// encryptWriter seals what is written to it chunk by chunk. Close seals the last chunk.
+type encryptWriter struct {
+	w       io.Writer
+	aead    cipher.AEAD
+	prefix  []byte
+	counter uint32
+	buf     []byte
+}
+
+// NewEncryptWriter returns a writer that encrypts everything written to it into w. Close must be
+// called to write the last chunk; it does not close w.
+func NewEncryptWriter(w io.Writer, passphrase string) (io.WriteCloser, error) {
+	if len(passphrase) < minPassphraseLen {
+		return nil, ErrPassphraseTooShort
+	}
+	header := make([]byte, len(magic)+saltSize+noncePrefixSize)
+	copy(header, magic)
+	if _, err := rand.Read(header[len(magic):]); err != nil {
+		return nil, err
+	}
+	salt := header[len(magic) : len(magic)+saltSize]
+	aead, err := newAEAD(passphrase, salt)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := w.Write(header); err != nil {
+		return nil, err
+	}
+	return &encryptWriter{w: w, aead: aead, prefix: header[len(magic)+saltSize:], buf: make([]byte, 0, chunkSize)}, nil
+}
+
+func (e *encryptWriter) Write(p []byte) (int, error) {
+	written := 0
+	for len(p) > 0 {
+		// A full buffer is only sealed once more data follows: the last chunk is sealed by Close
+		if len(e.buf) == chunkSize {
+			if err := e.seal(false); err != nil {
+				return written, err
+			}
+		}
+		n := copy(e.buf[len(e.buf):chunkSize], p)
+		e.buf = e.buf[:len(e.buf)+n]
+		p = p[n:]
+		written += n
+	}
+	return written, nil
+}
+
+func (e *encryptWriter) Close() error {
+	return e.seal(true)
+}
+
+func (e *encryptWriter) seal(last bool) error {
+	ciphertext := e.aead.Seal(nil, chunkNonce(e.prefix, e.counter, last), e.buf, nil)
+	var length [4]byte
+	binary.BigEndian.PutUint32(length[:], uint32(len(ciphertext)))
+	if _, err := e.w.Write(length[:]); err != nil {
+		return err
+	}
+	if _, err := e.w.Write(ciphertext); err != nil {
+		return err
+	}
+	e.counter++
+	e.buf = e.buf[:0]
+	return nil
+}
+
+// decryptReader opens the chunks of a backup file one at a time
+type decryptReader struct {
+	r       *bufio.Reader
+	aead    cipher.AEAD
+	prefix  []byte
+	counter uint32
+	plain   []byte
+	done    bool
+}
+
+// NewDecryptReader returns a reader of the content of the encrypted backup file r. Reads fail with
+// ErrDecrypt if the passphrase is wrong or the file was modified or cut off.
+func NewDecryptReader(r io.Reader, passphrase string) (io.Reader, error) {
+	br := bufio.NewReader(r)
+	header := make([]byte, len(magic)+saltSize+noncePrefixSize)
+	if _, err := io.ReadFull(br, header); err != nil || string(header[:len(magic)]) != magic {
+		return nil, ErrNotBackup
+	}
+	aead, err := newAEAD(passphrase, header[len(magic):len(magic)+saltSize])
+	if err != nil {
+		return nil, err
+	}
+	return &decryptReader{r: br, aead: aead, prefix: header[len(magic)+saltSize:]}, nil
+}
+
+func (d *decryptReader) Read(p []byte) (int, error) {
+	for len(d.plain) == 0 {
+		if d.done {
+			return 0, io.EOF
+		}
+		if err := d.open(); err != nil {
+			return 0, err
+		}
+	}
+	n := copy(p, d.plain)
+	d.plain = d.plain[n:]
+	return n, nil
+}
+
+// open reads and opens the next chunk. A chunk that only opens as the last one ends the stream.
+func (d *decryptReader) open() error {
+	var length [4]byte
+	if _, err := io.ReadFull(d.r, length[:]); err != nil {
+		return ErrDecrypt // The file ends before its last chunk
+	}
+	size := binary.BigEndian.Uint32(length[:])
+	if size > chunkSize+uint32(d.aead.Overhead()) {
+		return ErrDecrypt
+	}
+	ciphertext := make([]byte, size)
+	if _, err := io.ReadFull(d.r, ciphertext); err != nil {
+		return ErrDecrypt
+	}
+
+	plain, err := d.aead.Open(nil, chunkNonce(d.prefix, d.counter, false), ciphertext, nil)
+	if err != nil {
+		plain, err = d.aead.Open(nil, chunkNonce(d.prefix, d.counter, true), ciphertext, nil)
+		if err != nil {
+			return ErrDecrypt
+		}
+		if _, err := d.r.Peek(1); err != io.EOF {
+			return ErrDecrypt // Data after the last chunk
+		}
+		d.done = true
+	}
+	d.counter++
+	d.plain = plain
+	return nil
+}
+
+// LoadPassphrase reads the passphrase from file, without its trailing newline, or from the
+// BACKUP_PASSPHRASE environment variable when file is empty
+func LoadPassphrase(file string) (string, error) {
+	if file == "" {
+		if passphrase := os.Getenv("BACKUP_PASSPHRASE"); passphrase != "" {
+			return passphrase, nil
+		}
+		return "", errors.New("backup: set BACKUP_PASSPHRASE or pass a passphrase file")
+	}
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimRight(string(data), "\r\n"), nil
+}
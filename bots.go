@@ -0,0 +1,211 @@
+package main
+
+import (
+	"context"
+	"crypto/rand"
+	"database/sql"
+	"encoding/hex"
+	"log"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/gin-gonic/gin"
+
+	db "websocket-simple-chat-app/db/sqlc"
+	"websocket-simple-chat-app/hub"
+	"websocket-simple-chat-app/token"
+	"websocket-simple-chat-app/util/password"
+	"websocket-simple-chat-app/util/username"
+	"websocket-simple-chat-app/wordfilter"
+)
+
+// Bots are accounts for chatbots and integrations. They cannot log in: they authenticate with API
+// keys, on the REST endpoints of INTEGRATION_AUTH_METHODS and on /ws with the X-API-Key header.
+// What a key may do is limited by its scopes, which apply to any account's keys:
+//   - send: post messages (R6, POST /messages, and sending on /ws);
+//   - read: connect to /ws and receive events, and read messages where api_key is accepted.
+
+// roleBot is the role of bot accounts
+const roleBot = "bot"
+
+// API key scopes
+const (
+	apiKeyScopeSend = "send"
+	apiKeyScopeRead = "read"
+)
+
+// apiKeyScopesKey is the gin context key of the scopes of the API key that authenticated the request
+const apiKeyScopesKey = "api_key_scopes"
+
+// defaultAPIKeyScopes are the scopes of keys created without any
+var defaultAPIKeyScopes = []string{apiKeyScopeSend, apiKeyScopeRead}
+
+// apiKeyReadOnlyMessageTypes lists the WebSocket message types of connections whose API key lacks
+// the send scope
+var apiKeyReadOnlyMessageTypes = map[string]bool{
+	"ping": true,
+}
+
+// apiKeyAllowsMessageType reports whether a connection authenticated with an API key of the scopes
+// may send the WebSocket message type. API key sessions cannot reauth.
+func apiKeyAllowsMessageType(scopes []string, msgType string) bool {
+	if msgType == "reauth" {
+		return false
+	}
+	return slices.Contains(scopes, apiKeyScopeSend) || apiKeyReadOnlyMessageTypes[msgType]
+}
+
+// normalizeAPIKeyScopes returns the scopes sorted without duplicates, or the default scopes
+func normalizeAPIKeyScopes(scopes []string) []string {
+	if len(scopes) == 0 {
+		return defaultAPIKeyScopes
+	}
+	scopes = slices.Clone(scopes)
+	slices.Sort(scopes)
+	return slices.Compact(scopes)
+}
+
+// requireScope refuses requests authenticated with an API key lacking the scope. Other methods
+// are not limited by scopes.
+func requireScope(scope string) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		scopes, ok := ctx.Get(apiKeyScopesKey)
+		if ok && !slices.Contains(scopes.([]string), scope) {
+			ctx.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "API key lacks the '" + scope + "' scope"})
+			return
+		}
+		ctx.Next()
+	}
+}
+
+// --- Admin Endpoints ---
+
+// createBotHandler creates a bot account and its first API key, which is only returned in this
+// response
+func createBotHandler(store *db.Queries, usernameRules username.Rules) gin.HandlerFunc {
+	type createBotRequest struct {
+		Username string   `json:"username" binding:"required"`
+		Scopes   []string `json:"scopes" binding:"omitempty,dive,oneof=send read"` // Of the key, both by default
+	}
+
+	return func(c *gin.Context) {
+		var req createBotRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		req.Username = strings.TrimSpace(req.Username)
+		if err := usernameRules.Validate(req.Username); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		// Nobody knows the password, so the bot cannot log in
+		secret := make([]byte, 32)
+		if _, err := rand.Read(secret); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create bot"})
+			return
+		}
+		hash, err := password.Hash(hex.EncodeToString(secret))
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create bot"})
+			return
+		}
+
+		bot, err := store.CreateBotUser(context.Background(), db.CreateBotUserParams{
+			Username:     req.Username,
+			PasswordHash: hash,
+		})
+		if err != nil {
+			if db.IsUniqueViolation(err) {
+				c.JSON(http.StatusConflict, gin.H{"error": "Username already exists"})
+				return
+			}
+			log.Printf("Error creating bot %q: %v", req.Username, err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create bot"})
+			return
+		}
+
+		key, apiKey, err := issueAPIKey(store, bot.ID, "default", req.Scopes)
+		if err != nil {
+			log.Printf("Error creating API key for bot %d: %v", bot.ID, err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Bot created, but not its API key"})
+			return
+		}
+
+		log.Printf("Bot %s (ID: %d) created with API key %d", bot.Username, bot.ID, apiKey.ID)
+		c.JSON(http.StatusCreated, gin.H{
+			"bot":     newModeratedUser(bot),
+			"api_key": newCreatedAPIKey(apiKey, key),
+		})
+	}
+}
+
+// --- Integration Endpoints ---
+
+// sendMessageHandler sends a private text message as the authenticated account, for bots and
+// integrations. It is delivered like a message sent over WebSocket.
+func sendMessageHandler(store *db.Queries, connectionHub *hub.Hub, wordFilter *wordfilter.Filter, unarchive bool) gin.HandlerFunc {
+	type sendMessageRequest struct {
+		RecipientID int32  `json:"recipient_id" binding:"required,min=1"`
+		Content     string `json:"content" binding:"required"`
+	}
+
+	return func(c *gin.Context) {
+		payload := c.MustGet(authorizationPayloadKey).(*token.Payload)
+
+		var req sendMessageRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		if utf8.RuneCountInString(req.Content) > maxMessageLength {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "content exceeds " + strconv.Itoa(maxMessageLength) + " characters"})
+			return
+		}
+		if req.RecipientID == payload.UserID {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Cannot send a message to yourself"})
+			return
+		}
+
+		account, err := store.GetUserByID(context.Background(), payload.UserID)
+		if err != nil {
+			log.Printf("Error fetching sender %d: %v", payload.UserID, err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to send message"})
+			return
+		}
+		recipient, err := store.GetUserByID(context.Background(), req.RecipientID)
+		if err != nil {
+			if err == sql.ErrNoRows {
+				c.JSON(http.StatusNotFound, gin.H{"error": "Recipient not found"})
+				return
+			}
+			log.Printf("Error fetching recipient %d: %v", req.RecipientID, err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to send message"})
+			return
+		}
+		if recipient.DeactivatedAt.Valid || recipient.Role == roleSupport {
+			c.JSON(http.StatusUnprocessableEntity, gin.H{"error": "Recipient cannot receive messages"})
+			return
+		}
+		if err := checkQuarantineSend(store, account.ID); err != nil {
+			c.JSON(http.StatusTooManyRequests, gin.H{"error": err.Error()})
+			return
+		}
+
+		storedMsg, err := sendPrivateMessageAs(store, connectionHub, wordFilter, account, recipient.ID, req.Content, unarchive)
+		if err != nil {
+			if err == errBlockedWords {
+				c.JSON(http.StatusUnprocessableEntity, gin.H{"error": err.Error()})
+				return
+			}
+			log.Printf("Error sending message of user %d to user %d: %v", account.ID, recipient.ID, err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to send message"})
+			return
+		}
+		c.JSON(http.StatusCreated, storedMsg)
+	}
+}
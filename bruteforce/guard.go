@@ -0,0 +1,148 @@
+package bruteforce
+
+import (
+	"sync"
+	"time"
+)
+
+// attempts tracks the failed attempts of a single IP
+type attempts struct {
+	failures     int
+	windowStart  time.Time
+	blockedUntil time.Time
+}
+
+// Stats contains the counters exposed by the Guard
+type Stats struct {
+	TotalFailures int64 `json:"total_failures"`
+	TotalBlocks   int64 `json:"total_blocks"`
+	Rejected      int64 `json:"rejected"`
+	CurrentBlocks int   `json:"current_blocks"`
+}
+
+// Guard counts failed authentications per IP and temporarily blocks IPs that fail too often
+type Guard struct {
+	maxFailures   int
+	window        time.Duration
+	blockDuration time.Duration
+
+	entries map[string]*attempts
+	stats   Stats
+
+	mu sync.Mutex
+}
+
+// NewGuard creates a Guard that blocks an IP for blockDuration once it reaches
+// maxFailures failed attempts within window
+func NewGuard(maxFailures int, window time.Duration, blockDuration time.Duration) *Guard {
+	return &Guard{
+		maxFailures:   maxFailures,
+		window:        window,
+		blockDuration: blockDuration,
+		entries:       make(map[string]*attempts),
+	}
+}
+
+// Blocked reports whether the IP is currently blocked and for how long.
+// Every blocked check is counted as a rejected attempt.
+func (g *Guard) Blocked(ip string) (bool, time.Duration) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	entry, ok := g.entries[ip]
+	if !ok {
+		return false, 0
+	}
+
+	remaining := time.Until(entry.blockedUntil)
+	if remaining <= 0 {
+		return false, 0
+	}
+
+	g.stats.Rejected++
+	return true, remaining
+}
+
+// RecordFailure registers a failed attempt for the IP.
+// It returns true if this failure caused the IP to be blocked.
+func (g *Guard) RecordFailure(ip string) bool {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	now := time.Now()
+	g.stats.TotalFailures++
+
+	entry, ok := g.entries[ip]
+	if !ok || now.Sub(entry.windowStart) > g.window {
+		entry = &attempts{windowStart: now}
+		g.entries[ip] = entry
+	}
+	entry.failures++
+
+	if entry.failures < g.maxFailures {
+		return false
+	}
+
+	// Block the IP and start a fresh window once the block expires
+	entry.blockedUntil = now.Add(g.blockDuration)
+	entry.failures = 0
+	entry.windowStart = entry.blockedUntil
+	g.stats.TotalBlocks++
+	return true
+}
+
+// RecordSuccess forgets the expired block of the IP after a successful authentication. Failures
+// within the window are kept, so that a valid credential between guesses does not reset the count.
+func (g *Guard) RecordSuccess(ip string) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	entry, ok := g.entries[ip]
+	if ok && entry.failures == 0 && time.Now().After(entry.blockedUntil) {
+		delete(g.entries, ip)
+	}
+}
+
+// BlockedIPs returns the currently blocked IPs with the time their block expires
+func (g *Guard) BlockedIPs() map[string]time.Time {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	now := time.Now()
+	blocked := make(map[string]time.Time)
+	for ip, entry := range g.entries {
+		if entry.blockedUntil.After(now) {
+			blocked[ip] = entry.blockedUntil
+		}
+	}
+	return blocked
+}
+
+// Stats returns a snapshot of the guard counters
+func (g *Guard) Stats() Stats {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	stats := g.stats
+	now := time.Now()
+	for _, entry := range g.entries {
+		if entry.blockedUntil.After(now) {
+			stats.CurrentBlocks++
+		}
+	}
+	return stats
+}
+
+// Sweep removes entries whose window and block have both expired.
+// It should be called periodically to keep memory bounded.
+func (g *Guard) Sweep() {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	now := time.Now()
+	for ip, entry := range g.entries {
+		if now.After(entry.blockedUntil) && now.Sub(entry.windowStart) > g.window {
+			delete(g.entries, ip)
+		}
+	}
+}
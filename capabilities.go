@@ -0,0 +1,165 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"websocket-simple-chat-app/hub"
+)
+
+// Optional client features, declared in the capabilities query parameter of /ws
+const (
+	capabilityContactCards = "contact_cards"            // Renders contact_card events; otherwise cards arrive as incoming_message text
+	capabilityReactions    = "reactions"                // Reserved for reaction events
+	capabilityEditing      = "editing"                  // Reserved for message edit events
+	capabilitySync         = hub.CapabilitySync         // Acks events with POST /sync/ack and gets unacked ones replayed on connect
+	capabilityContentTypes = "content_types"            // Renders structured content types; otherwise they arrive as a text preview
+	capabilityLowBandwidth = hub.CapabilityLowBandwidth // Gets fewer and smaller events, see lowBandwidthMode
+)
+
+var knownCapabilities = map[string]bool{
+	capabilityContactCards: true,
+	capabilityReactions:    true,
+	capabilityEditing:      true,
+	capabilitySync:         true,
+	capabilityContentTypes: true,
+	capabilityLowBandwidth: true,
+}
+
+// lowBandwidthMode is applied to connections with the low_bandwidth capability, e.g. clients on
+// metered mobile connections: typing indicators are not sent, read receipts and presence changes
+// arrive in one batch event every 10 seconds (a user going offline and online again within a
+// batch is only reported once), and fields the client can do without are left out.
+var lowBandwidthMode = hub.LowBandwidthMode{
+	Suppress: map[string]bool{
+		"typing_start": true,
+		"typing_stop":  true,
+		"room_typing":  true,
+	},
+	Batch: map[string]bool{
+		"read_receipt_update": true,
+		"delivery_receipt":    true,
+		"user_online":         true,
+		"user_offline":        true,
+		"presence_changed":    true,
+	},
+	BatchInterval: 10 * time.Second,
+	StripFields:   []string{"preview"},
+	CoalesceKey:   presenceCoalesceKey,
+}
+
+// presenceCoalesceKey makes the last presence change of a user within a batch supersede the others
+func presenceCoalesceKey(eventType string, message []byte) string {
+	if eventType != "user_online" && eventType != "user_offline" && eventType != "presence_changed" {
+		return ""
+	}
+	var event UserStatusBroadcast
+	if err := json.Unmarshal(message, &event); err != nil {
+		return ""
+	}
+	return "presence:" + strconv.Itoa(int(event.UserID))
+}
+
+// legacyCapabilities are assumed for clients that do not declare any, which predate negotiation
+var legacyCapabilities = []string{capabilityContactCards}
+
+var errUnsupportedProtocolVersion = errors.New("unsupported protocol version")
+
+// CapabilitiesMessage tells a new connection what the server accepted from its declaration
+//
+//wsschema:server
+type CapabilitiesMessage struct {
+	Type            string    `json:"type"` // "capabilities"
+	ProtocolVersion int       `json:"protocol_version"`
+	Features        []string  `json:"features"`
+	CreatedAt       time.Time `json:"created_at"`
+}
+
+// negotiateCapabilities reads the capabilities (comma-separated features) and protocol_version (highest
+// version the client speaks) query parameters of a /ws request. Unknown features are ignored.
+func negotiateCapabilities(c *gin.Context) (hub.Capabilities, error) {
+	features, declared := c.GetQuery("capabilities")
+	return parseCapabilities(c.Query("protocol_version"), features, declared)
+}
+
+// parseCapabilities negotiates the capabilities of a connection from the protocol version and
+// comma-separated features the client declared. declared is false when it declared no features.
+func parseCapabilities(versionStr string, features string, declared bool) (hub.Capabilities, error) {
+	negotiated := hub.Capabilities{ProtocolVersion: wsProtocolVersions[0], Features: make(map[string]bool)}
+
+	if versionStr != "" {
+		version, err := strconv.Atoi(versionStr)
+		if err != nil || version < wsProtocolVersions[len(wsProtocolVersions)-1] {
+			return hub.Capabilities{}, errUnsupportedProtocolVersion
+		}
+		negotiated.ProtocolVersion = min(version, wsProtocolVersions[0])
+	}
+
+	requested := legacyCapabilities
+	if declared {
+		requested = strings.Split(features, ",")
+	}
+	for _, feature := range requested {
+		feature = strings.TrimSpace(feature)
+		if knownCapabilities[feature] {
+			negotiated.Features[feature] = true
+		}
+	}
+	return negotiated, nil
+}
+
+// sendCapabilities confirms the negotiated capabilities on a new connection
+func sendCapabilities(client *hub.Client) {
+	features := make([]string, 0, len(client.Capabilities.Features))
+	for feature := range client.Capabilities.Features {
+		features = append(features, feature)
+	}
+	sort.Strings(features)
+
+	jsonMsg, err := json.Marshal(CapabilitiesMessage{
+		Type:            "capabilities",
+		ProtocolVersion: client.Capabilities.ProtocolVersion,
+		Features:        features,
+		CreatedAt:       time.Now().UTC(),
+	})
+	if err != nil {
+		log.Printf("WS Error: Failed to marshal capabilities for user %d: %v", client.UserID, err)
+		return
+	}
+	client.Send(jsonMsg)
+}
+
+// sendContactCard delivers a contact card to every connection of the recipient. Connections without
+// the contact_cards capability get it as a plain incoming_message instead.
+func sendContactCard(connectionHub *hub.Hub, recipientID int32, msg ContactCardMessage) {
+	cardJSON, err := json.Marshal(msg)
+	if err != nil {
+		log.Printf("WS Error: Failed to marshal contact card for user %d: %v", recipientID, err)
+		return
+	}
+	textJSON, err := json.Marshal(OutgoingWsMessage{
+		Type:           "incoming_message",
+		SenderID:       msg.SenderID,
+		SenderUsername: msg.SenderUsername,
+		Content:        contactCardText(msg.Card),
+		CreatedAt:      msg.CreatedAt,
+	})
+	if err != nil {
+		log.Printf("WS Error: Failed to marshal contact card fallback for user %d: %v", recipientID, err)
+		return
+	}
+
+	connectionHub.SendOrQueueRendered(recipientID, func(capabilities hub.Capabilities) []byte {
+		if capabilities.Supports(capabilityContactCards) {
+			return cardJSON
+		}
+		return textJSON
+	})
+}
@@ -0,0 +1,63 @@
+package main
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"websocket-simple-chat-app/config"
+)
+
+// maxMessageLength is the longest private message, room message or support reply accepted, in
+// characters. It is set from MAX_MESSAGE_LENGTH at startup, before the server accepts requests.
+var maxMessageLength = config.DefaultMaxMessageLength
+
+// wsProtocolVersions lists the WebSocket message formats the server speaks, newest first
+var wsProtocolVersions = []int{1}
+
+// ClientConfig is returned by GET /config so clients can configure themselves instead of hardcoding limits
+type ClientConfig struct {
+	AppName                    string          `json:"app_name"`
+	MaxMessageLength           int             `json:"max_message_length"`
+	AccessTokenDurationSeconds int64           `json:"access_token_duration_seconds"`
+	MessagesPageMaxLimit       int             `json:"messages_page_max_limit"`
+	Attachments                AttachmentLimit `json:"attachments"`
+	Features                   map[string]bool `json:"features"`
+	ContentTypes               []string        `json:"content_types"`
+	WsProtocolVersions         []int           `json:"ws_protocol_versions"`
+}
+
+// AttachmentLimit describes which files clients may upload. Uploads are not supported yet.
+type AttachmentLimit struct {
+	Enabled      bool     `json:"enabled"`
+	AllowedTypes []string `json:"allowed_types"`
+	MaxSizeBytes int64    `json:"max_size_bytes"`
+}
+
+// newClientConfig collects the client-relevant settings of this deployment. appName is the displayed name.
+func newClientConfig(appName string, sessions sessionConfig, gifsEnabled bool, guestsEnabled bool, pushEnabled bool) ClientConfig {
+	return ClientConfig{
+		AppName:                    appName,
+		MaxMessageLength:           maxMessageLength,
+		AccessTokenDurationSeconds: int64(sessions.AccessTokenDuration.Seconds()),
+		MessagesPageMaxLimit:       messagesMaxLimit,
+		Attachments:                AttachmentLimit{AllowedTypes: []string{}},
+		Features: map[string]bool{
+			"gif_search":         gifsEnabled,
+			"guest_accounts":     guestsEnabled,
+			"push_notifications": pushEnabled,
+			"sliding_sessions":   sessions.SlidingSessions,
+			"support_inbox":      true,
+			"announcements":      true,
+		},
+		ContentTypes:       sendableContentTypes(),
+		WsProtocolVersions: wsProtocolVersions,
+	}
+}
+
+// clientConfigHandler serves the deployment's client configuration. It is public so clients can read it before login.
+func clientConfigHandler(config ClientConfig) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.JSON(http.StatusOK, config)
+	}
+}
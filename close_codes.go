@@ -0,0 +1,23 @@
+package main
+
+import (
+	"github.com/gorilla/websocket"
+)
+
+// WebSocket close codes sent by the server. Client SDKs branch on the code; the close reason is
+// a human-readable detail and may change.
+const (
+	wsCloseAuthFailed     = 4000                     // Missing or invalid token: log in again
+	wsCloseTokenExpired   = 4001                     // The session's token (or guest account) expired without reauth
+	wsCloseKicked         = 4002                     // The session was ended by the server or an admin: reconnecting is allowed
+	wsCloseBanned         = 4003                     // The account is deactivated or unknown: do not reconnect
+	wsCloseProtocolError  = 4004                     // Unsupported protocol version or malformed handshake
+	wsCloseRateLimited    = 4005                     // The client sent too much: reconnect after a delay
+	wsCloseServerShutdown = websocket.CloseGoingAway // The server is restarting: reconnect with backoff
+)
+
+// rejectConnection closes a connection that was never registered with the hub.
+// Registered connections are closed through hub.Client.Close so pending messages are written first.
+func rejectConnection(conn *websocket.Conn, code int, reason string) {
+	conn.WriteMessage(websocket.CloseMessage, websocket.FormatCloseMessage(code, reason))
+}
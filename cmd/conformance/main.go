@@ -0,0 +1,47 @@
+// Command conformance runs the WebSocket protocol conformance suite against a running server:
+//
+//	go run ./cmd/conformance -server http://localhost:8080
+//	go run ./cmd/conformance -server http://localhost:8080 -run 'private_message/.*'
+//
+// It exits with status 1 if a case fails.
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"regexp"
+
+	"websocket-simple-chat-app/conformance"
+)
+
+func main() {
+	serverURL := flag.String("server", "http://localhost:8080", "Base URL of the server under test")
+	run := flag.String("run", "", "Only run the cases whose name matches this regular expression")
+	flag.Parse()
+
+	var filter *regexp.Regexp
+	if *run != "" {
+		var err error
+		if filter, err = regexp.Compile(*run); err != nil {
+			log.Fatalf("Invalid -run pattern: %v", err)
+		}
+	}
+
+	failed := 0
+	results := conformance.Run(context.Background(), *serverURL, filter, func(result conformance.Result) {
+		if result.Err != nil {
+			failed++
+			fmt.Printf("FAIL  %-45s %6dms  %v\n", result.Name, result.Duration.Milliseconds(), result.Err)
+			return
+		}
+		fmt.Printf("PASS  %-45s %6dms\n", result.Name, result.Duration.Milliseconds())
+	})
+
+	fmt.Printf("\n%d passed, %d failed\n", len(results)-failed, failed)
+	if failed > 0 {
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,91 @@
+// Command migrate manages the database schema with the migrations embedded in the server, for
+// deployments that run the server with MIGRATE_ON_STARTUP=false or need to roll back:
+//
+//	go run ./cmd/migrate up           apply all pending migrations
+//	go run ./cmd/migrate down 1       revert the last migration
+//	go run ./cmd/migrate goto 12      migrate up or down to version 12
+//	go run ./cmd/migrate version      print the current version
+//	go run ./cmd/migrate force 29     set the version without migrating, e.g. after fixing a failed migration by hand
+//
+// The database is DB_SOURCE, as for the server, unless -db is given.
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"strconv"
+
+	"github.com/golang-migrate/migrate/v4"
+
+	"websocket-simple-chat-app/config"
+	"websocket-simple-chat-app/db/migrations"
+)
+
+func main() {
+	dsn := os.Getenv("DB_SOURCE")
+	if dsn == "" {
+		dsn = config.DefaultDBSource
+	}
+	flag.StringVar(&dsn, "db", dsn, "PostgreSQL connection URL")
+	flag.Usage = func() {
+		fmt.Fprintln(flag.CommandLine.Output(), "Usage: migrate [-db url] up | down <n> | goto <version> | version | force <version>")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+	if flag.NArg() == 0 {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	m, err := migrations.New(dsn)
+	if err != nil {
+		log.Fatalf("Cannot open the database: %v", err)
+	}
+	defer m.Close()
+
+	command, arg := flag.Arg(0), flag.Arg(1)
+	switch command {
+	case "up":
+		err = m.Up()
+	case "down":
+		var steps int
+		if steps, err = strconv.Atoi(arg); err != nil || steps <= 0 {
+			log.Fatalf("down needs a positive number of migrations to revert, got %q", arg)
+		}
+		err = m.Steps(-steps)
+	case "goto":
+		var version uint64
+		if version, err = strconv.ParseUint(arg, 10, 32); err != nil {
+			log.Fatalf("goto needs a version, got %q", arg)
+		}
+		err = m.Migrate(uint(version))
+	case "force":
+		var version int
+		if version, err = strconv.Atoi(arg); err != nil {
+			log.Fatalf("force needs a version, got %q", arg)
+		}
+		err = m.Force(version)
+	case "version":
+	default:
+		flag.Usage()
+		os.Exit(2)
+	}
+	if err != nil && !errors.Is(err, migrate.ErrNoChange) {
+		log.Fatalf("%s failed: %v", command, err)
+	}
+
+	version, dirty, err := m.Version()
+	switch {
+	case errors.Is(err, migrate.ErrNilVersion):
+		fmt.Println("No migration applied")
+	case err != nil:
+		log.Fatalf("Cannot read the version: %v", err)
+	case dirty:
+		fmt.Printf("Version %d (dirty: the migration failed, fix it and run force)\n", version)
+	default:
+		fmt.Printf("Version %d\n", version)
+	}
+}
@@ -0,0 +1,111 @@
+// Command restore loads a dump written by cmd/backup into a fresh database, e.g. on a new server:
+//
+//	BACKUP_PASSPHRASE='...' go run ./cmd/restore -in chat-2025-01-31.bak
+//
+// The database must exist and have no data. It is first migrated to the schema version of the dump;
+// start the server afterwards, which applies newer migrations as usual. The restore runs in one
+// transaction: if it fails, the database is left without data. The database is DB_SOURCE, as for
+// the server, unless -db is given.
+package main
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+
+	"github.com/golang-migrate/migrate/v4"
+	_ "github.com/lib/pq"
+
+	"websocket-simple-chat-app/backup"
+	"websocket-simple-chat-app/config"
+	"websocket-simple-chat-app/db/migrations"
+)
+
+func main() {
+	dsn := os.Getenv("DB_SOURCE")
+	if dsn == "" {
+		dsn = config.DefaultDBSource
+	}
+	flag.StringVar(&dsn, "db", dsn, "PostgreSQL connection URL")
+	in := flag.String("in", "", "Dump file to restore (required)")
+	passphraseFile := flag.String("passphrase-file", "", "File holding the passphrase, instead of BACKUP_PASSPHRASE")
+	flag.Parse()
+	if *in == "" {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	passphrase, err := backup.LoadPassphrase(*passphraseFile)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	// 1. Migrate the database to the dump's schema version
+	header, err := readDump(*in, passphrase, backup.ReadHeader)
+	if err != nil {
+		log.Fatalf("Cannot read %s: %v", *in, err)
+	}
+	if err := migrateTo(dsn, header.SchemaVersion); err != nil {
+		log.Fatalf("Cannot migrate the database to version %d: %v", header.SchemaVersion, err)
+	}
+
+	// 2. Load the data
+	conn, err := sql.Open("postgres", dsn)
+	if err != nil {
+		log.Fatalf("Cannot open the database: %v", err)
+	}
+	defer conn.Close()
+	header, err = readDump(*in, passphrase, func(r io.Reader) (backup.Header, error) {
+		return backup.Restore(context.Background(), conn, r)
+	})
+	if err != nil {
+		log.Fatalf("Restore failed: %v", err)
+	}
+
+	var rows int64
+	for _, count := range header.Tables {
+		rows += count
+	}
+	fmt.Printf("Restored %s from %s: schema version %d, %d tables, %d rows\n",
+		*in, header.CreatedAt.Format("2006-01-02 15:04:05 MST"), header.SchemaVersion, len(header.Tables), rows)
+}
+
+// readDump decrypts the dump file and hands its content to read
+func readDump(path string, passphrase string, read func(io.Reader) (backup.Header, error)) (backup.Header, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return backup.Header{}, err
+	}
+	defer file.Close()
+	decrypted, err := backup.NewDecryptReader(file, passphrase)
+	if err != nil {
+		return backup.Header{}, err
+	}
+	return read(decrypted)
+}
+
+// migrateTo migrates a fresh database up to version. A database at another version is refused.
+func migrateTo(dsn string, version uint) error {
+	m, err := migrations.New(dsn)
+	if err != nil {
+		return err
+	}
+	defer m.Close()
+
+	current, _, err := m.Version()
+	if err == nil && current != version {
+		return fmt.Errorf("the database is at version %d already", current)
+	}
+	if err != nil && !errors.Is(err, migrate.ErrNilVersion) {
+		return err
+	}
+	if err := m.Migrate(version); err != nil && !errors.Is(err, migrate.ErrNoChange) {
+		return err
+	}
+	return nil
+}
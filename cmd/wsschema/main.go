@@ -0,0 +1,408 @@
+// Command wsschema generates ws_schema.json, the machine-readable description of the WebSocket
+// messages served at GET /ws/schema, from the Go sources:
+//
+//	go run ./cmd/wsschema -o ws_schema.json . hub token db/sqlc
+//
+// It is run by go generate in the server package. A message struct is a struct whose doc comment
+// ends with a //wsschema:client, //wsschema:server or //wsschema:both directive (the direction it
+// travels in), and whose "type" field comment lists its message types in double quotes. Structs
+// used by the fields of a message are described under "definitions". Types are looked up in the
+// given package directories; other types are described as "any".
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"log"
+	"os"
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+const directivePrefix = "//wsschema:"
+
+var directions = map[string]bool{"client": true, "server": true, "both": true}
+
+// Schema is the content of ws_schema.json
+type Schema struct {
+	Messages    []Message             `json:"messages"`
+	Definitions map[string]Definition `json:"definitions"`
+}
+
+// Message describes a message struct
+type Message struct {
+	Name        string   `json:"name"`      // Go name of the struct
+	Types       []string `json:"types"`     // Values of its "type" field
+	Direction   string   `json:"direction"` // client (client to server), server (server to client) or both
+	Description string   `json:"description,omitempty"`
+	Fields      []Field  `json:"fields"`
+}
+
+// Definition describes a struct used by message fields
+type Definition struct {
+	Description string  `json:"description,omitempty"`
+	Fields      []Field `json:"fields"`
+}
+
+// Field describes a JSON field
+type Field struct {
+	Name   string `json:"name"`    // JSON name
+	GoName string `json:"go_name"` // Name of the Go field
+	Type
+	Optional    bool   `json:"optional,omitempty"` // Omitted when empty (omitempty)
+	Description string `json:"description,omitempty"`
+}
+
+// Type describes a JSON value
+type Type struct {
+	Type     string `json:"type"`             // string, integer, number, boolean, array, object or any
+	Format   string `json:"format,omitempty"` // e.g. int32, int64, float64, date-time
+	Ref      string `json:"ref,omitempty"`    // Definition of an object
+	Items    *Type  `json:"items,omitempty"`  // Element type of an array
+	Values   *Type  `json:"values,omitempty"` // Value type of an object used as a map
+	Nullable bool   `json:"nullable,omitempty"`
+	GoType   string `json:"go_type"`
+}
+
+// typeSpec is a type declaration and the package it was found in
+type typeSpec struct {
+	pkg  string
+	spec *ast.TypeSpec
+	doc  *ast.CommentGroup
+}
+
+type generator struct {
+	types       map[string]typeSpec // By package-qualified name, e.g. "hub.BatchMessage"
+	definitions map[string]Definition
+	pending     []string // Definitions referenced but not described yet
+}
+
+var quoted = regexp.MustCompile(`"([^"]+)"`)
+
+// sqlNullValues are the value types of the database/sql null wrappers, which are encoded as objects
+// with the value in the field named after the type (e.g. NullTime.Time) and Valid
+var sqlNullValues = map[string]Type{
+	"sql.NullString":  {Type: "string", GoType: "string"},
+	"sql.NullBool":    {Type: "boolean", GoType: "bool"},
+	"sql.NullInt32":   {Type: "integer", Format: "int32", GoType: "int32"},
+	"sql.NullInt64":   {Type: "integer", Format: "int64", GoType: "int64"},
+	"sql.NullFloat64": {Type: "number", Format: "float64", GoType: "float64"},
+	"sql.NullTime":    {Type: "string", Format: "date-time", GoType: "time.Time"},
+}
+
+func main() {
+	output := flag.String("o", "ws_schema.json", "output file")
+	flag.Parse()
+	if flag.NArg() == 0 {
+		log.Fatal("wsschema: no package directories given")
+	}
+
+	g := &generator{types: make(map[string]typeSpec), definitions: make(map[string]Definition)}
+	for _, dir := range flag.Args() {
+		if err := g.parseDir(dir); err != nil {
+			log.Fatalf("wsschema: %v", err)
+		}
+	}
+
+	schema, err := g.schema()
+	if err != nil {
+		log.Fatalf("wsschema: %v", err)
+	}
+	data, err := json.MarshalIndent(schema, "", "  ")
+	if err != nil {
+		log.Fatalf("wsschema: %v", err)
+	}
+	if err := os.WriteFile(*output, append(data, '\n'), 0o644); err != nil {
+		log.Fatalf("wsschema: %v", err)
+	}
+}
+
+// parseDir records the type declarations of the package in dir
+func (g *generator) parseDir(dir string) error {
+	fset := token.NewFileSet()
+	pkgs, err := parser.ParseDir(fset, dir, func(info os.FileInfo) bool {
+		return !strings.HasSuffix(info.Name(), "_test.go")
+	}, parser.ParseComments)
+	if err != nil {
+		return err
+	}
+	if len(pkgs) != 1 {
+		return fmt.Errorf("%s: expected one package, found %d", dir, len(pkgs))
+	}
+
+	for pkgName, pkg := range pkgs {
+		for _, file := range pkg.Files {
+			for _, decl := range file.Decls {
+				gen, ok := decl.(*ast.GenDecl)
+				if !ok || gen.Tok != token.TYPE {
+					continue
+				}
+				for _, spec := range gen.Specs {
+					ts := spec.(*ast.TypeSpec)
+					doc := ts.Doc
+					if doc == nil && len(gen.Specs) == 1 {
+						doc = gen.Doc
+					}
+					g.types[pkgName+"."+ts.Name.Name] = typeSpec{pkg: pkgName, spec: ts, doc: doc}
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// schema describes the message structs, sorted by their first type, and the structs they use
+func (g *generator) schema() (Schema, error) {
+	schema := Schema{Messages: []Message{}}
+	for _, qualified := range sortedKeys(g.types) {
+		t := g.types[qualified]
+		direction := directive(t.doc)
+		if direction == "" {
+			continue
+		}
+		if !directions[direction] {
+			return schema, fmt.Errorf("%s: unknown direction %q", qualified, direction)
+		}
+		structType, ok := t.spec.Type.(*ast.StructType)
+		if !ok {
+			return schema, fmt.Errorf("%s: only structs can be messages", qualified)
+		}
+
+		message := Message{Name: t.spec.Name.Name, Direction: direction, Description: description(t.doc)}
+		fields, err := g.fields(t.pkg, structType)
+		if err != nil {
+			return schema, fmt.Errorf("%s: %v", qualified, err)
+		}
+		message.Fields = fields
+		for _, field := range structType.Fields.List {
+			if len(field.Names) == 1 && jsonName(field, field.Names[0].Name) == "type" {
+				for _, match := range quoted.FindAllStringSubmatch(field.Comment.Text(), -1) {
+					message.Types = append(message.Types, match[1])
+				}
+			}
+		}
+		if len(message.Types) == 0 {
+			return schema, fmt.Errorf(`%s: the comment of the "type" field lists no message type`, qualified)
+		}
+		schema.Messages = append(schema.Messages, message)
+	}
+	sort.SliceStable(schema.Messages, func(i, j int) bool {
+		return schema.Messages[i].Types[0] < schema.Messages[j].Types[0]
+	})
+
+	for len(g.pending) > 0 {
+		qualified := g.pending[0]
+		g.pending = g.pending[1:]
+		t := g.types[qualified]
+		fields, err := g.fields(t.pkg, t.spec.Type.(*ast.StructType))
+		if err != nil {
+			return schema, fmt.Errorf("%s: %v", qualified, err)
+		}
+		g.definitions[t.spec.Name.Name] = Definition{Description: description(t.doc), Fields: fields}
+	}
+	schema.Definitions = g.definitions
+	return schema, nil
+}
+
+// fields describes the JSON fields of a struct. Embedded structs are inlined.
+func (g *generator) fields(pkg string, structType *ast.StructType) ([]Field, error) {
+	fields := []Field{}
+	for _, field := range structType.Fields.List {
+		if len(field.Names) == 0 {
+			t, ok := g.types[g.qualify(pkg, field.Type)]
+			if !ok {
+				return nil, fmt.Errorf("embedded %s is not in the given packages", source(field.Type))
+			}
+			embedded, ok := t.spec.Type.(*ast.StructType)
+			if !ok {
+				return nil, fmt.Errorf("embedded %s is not a struct", source(field.Type))
+			}
+			inlined, err := g.fields(t.pkg, embedded)
+			if err != nil {
+				return nil, err
+			}
+			fields = append(fields, inlined...)
+			continue
+		}
+
+		for _, name := range field.Names {
+			if !name.IsExported() {
+				continue
+			}
+			jsonField := jsonName(field, name.Name)
+			if jsonField == "-" {
+				continue
+			}
+			fields = append(fields, Field{
+				Name:        jsonField,
+				GoName:      name.Name,
+				Type:        g.describe(pkg, field.Type),
+				Optional:    hasOption(field, "omitempty"),
+				Description: strings.TrimSpace(strings.ReplaceAll(field.Comment.Text(), "\n", " ")),
+			})
+		}
+	}
+	return fields, nil
+}
+
+// describe maps a Go type expression of package pkg to a JSON type
+func (g *generator) describe(pkg string, expr ast.Expr) Type {
+	goType := source(expr)
+	switch e := expr.(type) {
+	case *ast.StarExpr:
+		t := g.describe(pkg, e.X)
+		t.Nullable = true
+		t.GoType = goType
+		return t
+	case *ast.ArrayType:
+		if ident, ok := e.Elt.(*ast.Ident); ok && ident.Name == "byte" {
+			return Type{Type: "string", Format: "base64", GoType: goType}
+		}
+		items := g.describe(pkg, e.Elt)
+		return Type{Type: "array", Items: &items, Nullable: e.Len == nil, GoType: goType}
+	case *ast.MapType:
+		values := g.describe(pkg, e.Value)
+		return Type{Type: "object", Values: &values, Nullable: true, GoType: goType}
+	case *ast.InterfaceType:
+		return Type{Type: "any", GoType: goType}
+	case *ast.StructType:
+		return Type{Type: "object", GoType: goType}
+	}
+
+	switch goType {
+	case "string":
+		return Type{Type: "string", GoType: goType}
+	case "bool":
+		return Type{Type: "boolean", GoType: goType}
+	case "int", "int8", "int16", "int32", "int64", "uint", "uint8", "uint16", "uint32", "uint64":
+		return Type{Type: "integer", Format: goType, GoType: goType}
+	case "float32", "float64":
+		return Type{Type: "number", Format: goType, GoType: goType}
+	case "time.Time":
+		return Type{Type: "string", Format: "date-time", GoType: goType}
+	case "uuid.UUID":
+		return Type{Type: "string", Format: "uuid", GoType: goType}
+	case "time.Duration":
+		return Type{Type: "integer", Format: "nanoseconds", GoType: goType}
+	case "json.RawMessage", "any":
+		return Type{Type: "any", GoType: goType}
+	}
+	if value, ok := sqlNullValues[goType]; ok {
+		name := strings.TrimPrefix(goType, "sql.")
+		g.definitions[name] = Definition{
+			Description: goType + ": the value is only set when Valid is true",
+			Fields: []Field{
+				{Name: strings.TrimPrefix(name, "Null"), GoName: strings.TrimPrefix(name, "Null"), Type: value},
+				{Name: "Valid", GoName: "Valid", Type: Type{Type: "boolean", GoType: "bool"}},
+			},
+		}
+		return Type{Type: "object", Ref: name, GoType: goType}
+	}
+
+	qualified := g.qualify(pkg, expr)
+	t, ok := g.types[qualified]
+	if !ok {
+		return Type{Type: "any", GoType: goType}
+	}
+	if _, isStruct := t.spec.Type.(*ast.StructType); !isStruct {
+		described := g.describe(t.pkg, t.spec.Type)
+		described.GoType = goType
+		return described
+	}
+	name := t.spec.Name.Name
+	if _, ok := g.definitions[name]; !ok {
+		g.definitions[name] = Definition{} // Reserved, described by schema
+		g.pending = append(g.pending, qualified)
+	}
+	return Type{Type: "object", Ref: name, GoType: goType}
+}
+
+// qualify returns the package-qualified name of a named type of package pkg
+func (g *generator) qualify(pkg string, expr ast.Expr) string {
+	switch e := expr.(type) {
+	case *ast.Ident:
+		return pkg + "." + e.Name
+	case *ast.SelectorExpr:
+		return source(e)
+	case *ast.StarExpr:
+		return g.qualify(pkg, e.X)
+	}
+	return ""
+}
+
+// source renders a type expression as in the source
+func source(expr ast.Expr) string {
+	switch e := expr.(type) {
+	case *ast.Ident:
+		return e.Name
+	case *ast.SelectorExpr:
+		return source(e.X) + "." + e.Sel.Name
+	case *ast.StarExpr:
+		return "*" + source(e.X)
+	case *ast.ArrayType:
+		return "[]" + source(e.Elt)
+	case *ast.MapType:
+		return "map[" + source(e.Key) + "]" + source(e.Value)
+	case *ast.InterfaceType:
+		return "any"
+	}
+	return fmt.Sprintf("%T", expr)
+}
+
+// directive returns the direction given by the //wsschema: directive of a doc comment
+func directive(doc *ast.CommentGroup) string {
+	if doc == nil {
+		return ""
+	}
+	for _, comment := range doc.List {
+		if direction, ok := strings.CutPrefix(comment.Text, directivePrefix); ok {
+			return strings.TrimSpace(direction)
+		}
+	}
+	return ""
+}
+
+// description returns a doc comment as a single line, without directives
+func description(doc *ast.CommentGroup) string {
+	return strings.Join(strings.Fields(doc.Text()), " ")
+}
+
+func jsonName(field *ast.Field, goName string) string {
+	name, _, _ := strings.Cut(tag(field), ",")
+	if name == "" {
+		return goName
+	}
+	return name
+}
+
+func hasOption(field *ast.Field, option string) bool {
+	_, options, _ := strings.Cut(tag(field), ",")
+	for _, o := range strings.Split(options, ",") {
+		if o == option {
+			return true
+		}
+	}
+	return false
+}
+
+func tag(field *ast.Field) string {
+	if field.Tag == nil {
+		return ""
+	}
+	return reflect.StructTag(strings.Trim(field.Tag.Value, "`")).Get("json")
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,575 @@
+package conformance
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+)
+
+// Close codes of the protocol (see "Close Codes" in API_REFERENCE.md)
+const (
+	closeAuthFailed    = 4000
+	closeProtocolError = 4004
+)
+
+// Cases are the protocol checks, in the order of the documentation
+var Cases = []Case{
+	{Name: "handshake/capabilities_first", Run: caseCapabilitiesFirst},
+	{Name: "handshake/auth_message", Run: caseAuthMessage},
+	{Name: "handshake/missing_token", Run: caseMissingToken},
+	{Name: "handshake/invalid_token", Run: caseInvalidToken},
+	{Name: "handshake/unsupported_protocol_version", Run: caseUnsupportedProtocolVersion},
+	{Name: "handshake/invalid_since", Run: caseInvalidSince},
+	{Name: "presence/user_online", Run: caseUserOnline},
+	{Name: "private_message/delivered", Run: casePrivateMessageDelivered},
+	{Name: "private_message/rejected", Run: casePrivateMessageRejected},
+	{Name: "private_message/stored_and_synced", Run: casePrivateMessageStoredAndSynced},
+	{Name: "delete_message", Run: caseDeleteMessage},
+	{Name: "typing", Run: caseTyping},
+	{Name: "message_read", Run: caseMessageRead},
+	{Name: "contact_card", Run: caseContactCard},
+	{Name: "room_message", Run: caseRoomMessage},
+	{Name: "ping", Run: casePing},
+	{Name: "reauth", Run: caseReauth},
+	{Name: "rate_limited", Run: caseRateLimited},
+	{Name: "unknown_type", Run: caseUnknownType},
+}
+
+// twoUsers signs up two accounts and connects both
+func twoUsers(ctx context.Context, env *Env) (alice Session, aliceConn *Conn, bob Session, bobConn *Conn, err error) {
+	if alice, err = env.NewUser(ctx, "alice"); err != nil {
+		return
+	}
+	if bob, err = env.NewUser(ctx, "bob"); err != nil {
+		return
+	}
+	if aliceConn, err = env.Connect(ctx, alice, nil); err != nil {
+		return
+	}
+	if bobConn, err = env.Connect(ctx, bob, nil); err != nil {
+		aliceConn.Close()
+	}
+	return
+}
+
+// expectCloseCode dials with the query and checks the close code of the rejection
+func expectCloseCode(ctx context.Context, env *Env, token string, query url.Values, want int) error {
+	conn, err := env.Client.Dial(ctx, token, query)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	code, err := conn.ExpectClose(eventTimeout)
+	if err != nil {
+		return err
+	}
+	if code != want {
+		return fmt.Errorf("close code %d, want %d", code, want)
+	}
+	return nil
+}
+
+// --- Handshake ---
+
+func caseCapabilitiesFirst(ctx context.Context, env *Env) error {
+	user, err := env.NewUser(ctx, "caps")
+	if err != nil {
+		return err
+	}
+	conn, err := env.Client.Dial(ctx, user.Token, url.Values{"capabilities": {"sync"}})
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	event, err := conn.Next(eventTimeout)
+	if err != nil {
+		return err
+	}
+	if event.Type() != "capabilities" || event.Number("protocol_version") < 1 {
+		return fmt.Errorf("unexpected first event %v", event)
+	}
+	features, _ := event["features"].([]any)
+	if len(features) != 1 || features[0] != "sync" {
+		return fmt.Errorf("features %v, want [sync]", features)
+	}
+	return nil
+}
+
+func caseAuthMessage(ctx context.Context, env *Env) error {
+	user, err := env.NewUser(ctx, "authmsg")
+	if err != nil {
+		return err
+	}
+	conn, err := env.Client.Dial(ctx, "", nil)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	if err := conn.Send(map[string]any{"type": "auth", "token": user.Token}); err != nil {
+		return err
+	}
+	_, err = conn.WaitFor("capabilities", eventTimeout)
+	return err
+}
+
+// caseMissingToken opens a connection without a token and sends something else than "auth" first
+func caseMissingToken(ctx context.Context, env *Env) error {
+	conn, err := env.Client.Dial(ctx, "", nil)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	if err := conn.Send(map[string]any{"type": "ping"}); err != nil {
+		return err
+	}
+	code, err := conn.ExpectClose(eventTimeout)
+	if err != nil {
+		return err
+	}
+	if code != closeAuthFailed {
+		return fmt.Errorf("close code %d, want %d", code, closeAuthFailed)
+	}
+	return nil
+}
+
+func caseInvalidToken(ctx context.Context, env *Env) error {
+	return expectCloseCode(ctx, env, "v2.local.not-a-token", nil, closeAuthFailed)
+}
+
+func caseUnsupportedProtocolVersion(ctx context.Context, env *Env) error {
+	user, err := env.NewUser(ctx, "proto")
+	if err != nil {
+		return err
+	}
+	return expectCloseCode(ctx, env, user.Token, url.Values{"protocol_version": {"0"}}, closeProtocolError)
+}
+
+func caseInvalidSince(ctx context.Context, env *Env) error {
+	user, err := env.NewUser(ctx, "since")
+	if err != nil {
+		return err
+	}
+	return expectCloseCode(ctx, env, user.Token, url.Values{"since": {"yesterday"}}, closeProtocolError)
+}
+
+// --- Presence ---
+
+func caseUserOnline(ctx context.Context, env *Env) error {
+	watcher, err := env.NewUser(ctx, "watch")
+	if err != nil {
+		return err
+	}
+	watcherConn, err := env.Connect(ctx, watcher, nil)
+	if err != nil {
+		return err
+	}
+	defer watcherConn.Close()
+
+	user, err := env.NewUser(ctx, "online")
+	if err != nil {
+		return err
+	}
+	conn, err := env.Connect(ctx, user, nil)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	for {
+		event, err := watcherConn.WaitFor("user_online", eventTimeout)
+		if err != nil {
+			return err
+		}
+		if int32(event.Number("userId")) == user.UserID {
+			return nil
+		}
+	}
+}
+
+// --- Private Messages ---
+
+func casePrivateMessageDelivered(ctx context.Context, env *Env) error {
+	_, aliceConn, bob, bobConn, err := twoUsers(ctx, env)
+	if err != nil {
+		return err
+	}
+	defer aliceConn.Close()
+	defer bobConn.Close()
+
+	err = aliceConn.Send(map[string]any{"type": "private_message", "recipient_id": bob.UserID, "content": "hello", "client_msg_id": "m1"})
+	if err != nil {
+		return err
+	}
+	ack, err := aliceConn.WaitFor("ack", eventTimeout)
+	if err != nil {
+		return err
+	}
+	if ack.String("status") != "delivered" || ack.String("client_msg_id") != "m1" || ack.Number("message_id") == 0 {
+		return fmt.Errorf("unexpected ack %v", ack)
+	}
+
+	incoming, err := bobConn.WaitFor("incoming_message", eventTimeout)
+	if err != nil {
+		return err
+	}
+	if incoming.String("content") != "hello" {
+		return fmt.Errorf("unexpected incoming_message %v", incoming)
+	}
+	return nil
+}
+
+func casePrivateMessageRejected(ctx context.Context, env *Env) error {
+	alice, err := env.NewUser(ctx, "reject")
+	if err != nil {
+		return err
+	}
+	conn, err := env.Connect(ctx, alice, nil)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	invalid := []map[string]any{
+		{"type": "private_message", "recipient_id": alice.UserID, "content": ""},
+		{"type": "private_message", "recipient_id": alice.UserID, "content": "x", "content_type": "hologram"},
+		{"type": "private_message", "recipient_id": alice.UserID, "content": "{}", "content_type": "poll"},
+	}
+	for i, msg := range invalid {
+		msg["client_msg_id"] = "bad" + strconv.Itoa(i)
+		if err := conn.Send(msg); err != nil {
+			return err
+		}
+		ack, err := conn.WaitFor("ack", eventTimeout)
+		if err != nil {
+			return err
+		}
+		if ack.String("status") != "rejected" || ack.String("client_msg_id") != msg["client_msg_id"] || ack.String("error") == "" {
+			return fmt.Errorf("message %v: unexpected ack %v", msg, ack)
+		}
+	}
+	return nil
+}
+
+func casePrivateMessageStoredAndSynced(ctx context.Context, env *Env) error {
+	alice, err := env.NewUser(ctx, "sender")
+	if err != nil {
+		return err
+	}
+	bob, err := env.NewUser(ctx, "offline")
+	if err != nil {
+		return err
+	}
+	aliceConn, err := env.Connect(ctx, alice, nil)
+	if err != nil {
+		return err
+	}
+	defer aliceConn.Close()
+
+	if err := aliceConn.Send(map[string]any{"type": "private_message", "recipient_id": bob.UserID, "content": "while you were away"}); err != nil {
+		return err
+	}
+	ack, err := aliceConn.WaitFor("ack", eventTimeout)
+	if err != nil {
+		return err
+	}
+	if ack.String("status") != "stored" {
+		return fmt.Errorf("ack status %q, want stored", ack.String("status"))
+	}
+
+	bobConn, err := env.Connect(ctx, bob, url.Values{"since": {"0"}})
+	if err != nil {
+		return err
+	}
+	defer bobConn.Close()
+	sync, err := bobConn.WaitFor("message_sync", eventTimeout)
+	if err != nil {
+		return err
+	}
+	messages, _ := sync["messages"].([]any)
+	if len(messages) != 1 || sync["complete"] != true || sync.Number("last_id") != ack.Number("message_id") {
+		return fmt.Errorf("unexpected message_sync %v", sync)
+	}
+	return nil
+}
+
+func caseDeleteMessage(ctx context.Context, env *Env) error {
+	_, aliceConn, bob, bobConn, err := twoUsers(ctx, env)
+	if err != nil {
+		return err
+	}
+	defer aliceConn.Close()
+	defer bobConn.Close()
+
+	if err := aliceConn.Send(map[string]any{"type": "private_message", "recipient_id": bob.UserID, "content": "oops"}); err != nil {
+		return err
+	}
+	ack, err := aliceConn.WaitFor("ack", eventTimeout)
+	if err != nil {
+		return err
+	}
+	if err := aliceConn.Send(map[string]any{"type": "delete_message", "message_id": ack.Number("message_id")}); err != nil {
+		return err
+	}
+
+	for _, conn := range []*Conn{aliceConn, bobConn} {
+		deleted, err := conn.WaitFor("message_deleted", eventTimeout)
+		if err != nil {
+			return err
+		}
+		if deleted.Number("message_id") != ack.Number("message_id") {
+			return fmt.Errorf("unexpected message_deleted %v", deleted)
+		}
+	}
+	return nil
+}
+
+func caseTyping(ctx context.Context, env *Env) error {
+	alice, aliceConn, bob, bobConn, err := twoUsers(ctx, env)
+	if err != nil {
+		return err
+	}
+	defer aliceConn.Close()
+	defer bobConn.Close()
+
+	for _, typingType := range []string{"typing_start", "typing_stop"} {
+		if err := aliceConn.Send(map[string]any{"type": typingType, "recipient_id": bob.UserID}); err != nil {
+			return err
+		}
+		event, err := bobConn.WaitFor(typingType, eventTimeout)
+		if err != nil {
+			return err
+		}
+		if int32(event.Number("sender_id")) != alice.UserID {
+			return fmt.Errorf("unexpected %s %v", typingType, event)
+		}
+	}
+	return nil
+}
+
+func caseMessageRead(ctx context.Context, env *Env) error {
+	alice, aliceConn, bob, bobConn, err := twoUsers(ctx, env)
+	if err != nil {
+		return err
+	}
+	defer aliceConn.Close()
+	defer bobConn.Close()
+
+	var messageIDs []float64
+	for _, content := range []string{"first", "second"} {
+		if err := aliceConn.Send(map[string]any{"type": "private_message", "recipient_id": bob.UserID, "content": content}); err != nil {
+			return err
+		}
+		ack, err := aliceConn.WaitFor("ack", eventTimeout)
+		if err != nil {
+			return err
+		}
+		messageIDs = append(messageIDs, ack.Number("message_id"))
+	}
+
+	// Reading up to the first message leaves the second unread, a plain message_read reads the rest
+	reads := []map[string]any{
+		{"type": "message_read", "sender_id": alice.UserID, "up_to_id": messageIDs[0]},
+		{"type": "message_read", "sender_id": alice.UserID},
+	}
+	for i, read := range reads {
+		if err := bobConn.Send(read); err != nil {
+			return err
+		}
+		receipt, err := aliceConn.WaitFor("read_receipt_update", eventTimeout)
+		if err != nil {
+			return err
+		}
+		readIDs, _ := receipt["message_ids"].([]any)
+		if int32(receipt.Number("reader_id")) != bob.UserID || len(readIDs) != 1 || readIDs[0] != messageIDs[i] {
+			return fmt.Errorf("unexpected read_receipt_update %v after %v", receipt, read)
+		}
+	}
+	return nil
+}
+
+func caseContactCard(ctx context.Context, env *Env) error {
+	alice, aliceConn, bob, bobConn, err := twoUsers(ctx, env)
+	if err != nil {
+		return err
+	}
+	defer aliceConn.Close()
+	defer bobConn.Close()
+
+	// Clients that declare no capabilities get contact_card events (legacy behaviour)
+	if err := aliceConn.Send(map[string]any{"type": "contact_card", "recipient_id": bob.UserID, "user_id": alice.UserID}); err != nil {
+		return err
+	}
+	card, err := bobConn.WaitFor("contact_card", eventTimeout)
+	if err != nil {
+		return err
+	}
+	shared, _ := card["card"].(map[string]any)
+	if shared["username"] != alice.Username {
+		return fmt.Errorf("unexpected contact_card %v", card)
+	}
+	return nil
+}
+
+// --- Rooms ---
+
+func caseRoomMessage(ctx context.Context, env *Env) error {
+	alice, aliceConn, bob, bobConn, err := twoUsers(ctx, env)
+	if err != nil {
+		return err
+	}
+	defer aliceConn.Close()
+	defer bobConn.Close()
+
+	var room struct {
+		ID int64 `json:"id"`
+	}
+	if err := env.Client.Call(ctx, http.MethodPost, "/rooms", alice.Token, map[string]string{"name": "conformance"}, &room); err != nil {
+		return err
+	}
+	// Rooms are private by default: bob needs an invite
+	if err := env.Client.Call(ctx, http.MethodPost, fmt.Sprintf("/rooms/%d/join", room.ID), bob.Token, nil, nil); err == nil {
+		return fmt.Errorf("joined private room %d without an invite", room.ID)
+	}
+	if err := env.Client.Call(ctx, http.MethodPost, fmt.Sprintf("/rooms/%d/invites", room.ID), alice.Token, map[string]any{"user_id": bob.UserID}, nil); err != nil {
+		return err
+	}
+	if err := env.Client.Call(ctx, http.MethodPost, fmt.Sprintf("/rooms/%d/join", room.ID), bob.Token, nil, nil); err != nil {
+		return err
+	}
+
+	if err := aliceConn.Send(map[string]any{"type": "room_message", "room_id": room.ID, "content": "hi all"}); err != nil {
+		return err
+	}
+	event, err := bobConn.WaitFor("room_message", eventTimeout)
+	if err != nil {
+		return err
+	}
+	if int64(event.Number("room_id")) != room.ID || event.String("content") != "hi all" {
+		return fmt.Errorf("unexpected room_message %v", event)
+	}
+	return nil
+}
+
+// --- Session ---
+
+func casePing(ctx context.Context, env *Env) error {
+	user, err := env.NewUser(ctx, "ping")
+	if err != nil {
+		return err
+	}
+	conn, err := env.Connect(ctx, user, nil)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	if err := conn.Send(map[string]any{"type": "ping", "client_time": 12345}); err != nil {
+		return err
+	}
+	pong, err := conn.WaitFor("pong", eventTimeout)
+	if err != nil {
+		return err
+	}
+	if pong.Number("client_time") != 12345 || pong.String("server_received_at") == "" {
+		return fmt.Errorf("unexpected pong %v", pong)
+	}
+	return nil
+}
+
+func caseReauth(ctx context.Context, env *Env) error {
+	user, err := env.NewUser(ctx, "reauth")
+	if err != nil {
+		return err
+	}
+	conn, err := env.Connect(ctx, user, nil)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	if err := conn.Send(map[string]any{"type": "reauth", "token": "v2.local.not-a-token"}); err != nil {
+		return err
+	}
+	if _, err := conn.WaitFor("reauth_failed", eventTimeout); err != nil {
+		return err
+	}
+
+	fresh, err := env.Client.Login(ctx, user.Username, casePassword)
+	if err != nil {
+		return err
+	}
+	if err := conn.Send(map[string]any{"type": "reauth", "token": fresh.Token}); err != nil {
+		return err
+	}
+	_, err = conn.WaitFor("reauth_ok", eventTimeout)
+	return err
+}
+
+func caseUnknownType(ctx context.Context, env *Env) error {
+	user, err := env.NewUser(ctx, "unknown")
+	if err != nil {
+		return err
+	}
+	conn, err := env.Connect(ctx, user, nil)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	// A message type the server does not know is answered with an error, and the connection stays usable
+	if err := conn.Send(map[string]any{"type": "not_a_message_type"}); err != nil {
+		return err
+	}
+	errorEvent, err := conn.WaitFor("error", eventTimeout)
+	if err != nil {
+		return err
+	}
+	if errorEvent.String("code") != "unknown_type" || errorEvent.String("message_type") != "not_a_message_type" {
+		return fmt.Errorf("unexpected error event %v", errorEvent)
+	}
+	if err := conn.Send(map[string]any{"type": "ping", "client_time": 1}); err != nil {
+		return err
+	}
+	_, err = conn.WaitFor("pong", eventTimeout)
+	return err
+}
+
+func caseRateLimited(ctx context.Context, env *Env) error {
+	user, err := env.NewUser(ctx, "flood")
+	if err != nil {
+		return err
+	}
+	conn, err := env.Connect(ctx, user, nil)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	// More than the default burst (30), fewer than the messages that get a connection closed
+	for i := range 60 {
+		if err := conn.Send(map[string]any{"type": "ping", "client_time": i}); err != nil {
+			return err
+		}
+	}
+	// Clients are warned before their messages are refused
+	warning, err := conn.WaitFor("rate_warning", eventTimeout)
+	if err != nil {
+		return err
+	}
+	if warning.Number("limit") <= 0 || warning.Number("remaining") >= warning.Number("limit") || warning.Number("reset_after_ms") <= 0 {
+		return fmt.Errorf("unexpected rate_warning %v", warning)
+	}
+	event, err := conn.WaitFor("rate_limited", eventTimeout)
+	if err != nil {
+		return err
+	}
+	if event.String("message_type") != "ping" || event.Number("retry_after_ms") <= 0 {
+		return fmt.Errorf("unexpected rate_limited %v", event)
+	}
+	return nil
+}
@@ -0,0 +1,201 @@
+package conformance
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+// Client is a minimal reference client of the chat API: the HTTP calls needed to get a token and
+// the WebSocket protocol. It only relies on documented behaviour (API_REFERENCE.md).
+type Client struct {
+	ServerURL string // e.g. "http://localhost:8080"
+	HTTP      *http.Client
+}
+
+// NewClient creates a client for the server at serverURL
+func NewClient(serverURL string) *Client {
+	return &Client{ServerURL: strings.TrimRight(serverURL, "/"), HTTP: &http.Client{Timeout: 10 * time.Second}}
+}
+
+// Session is a logged-in user
+type Session struct {
+	UserID   int32
+	Username string
+	Token    string
+}
+
+// Signup creates an account and logs it in
+func (c *Client) Signup(ctx context.Context, username string, password string) (Session, error) {
+	credentials := map[string]string{"username": username, "password": password}
+	if err := c.Call(ctx, http.MethodPost, "/users", "", credentials, nil); err != nil {
+		return Session{}, fmt.Errorf("signup: %w", err)
+	}
+	return c.Login(ctx, username, password)
+}
+
+// Login logs a user in
+func (c *Client) Login(ctx context.Context, username string, password string) (Session, error) {
+	var resp struct {
+		Token   string `json:"token"`
+		Payload struct {
+			UserID   int32  `json:"user_id"`
+			Username string `json:"username"`
+		} `json:"payload"`
+	}
+	credentials := map[string]string{"username": username, "password": password}
+	if err := c.Call(ctx, http.MethodPost, "/login", "", credentials, &resp); err != nil {
+		return Session{}, fmt.Errorf("login: %w", err)
+	}
+	return Session{UserID: resp.Payload.UserID, Username: resp.Payload.Username, Token: resp.Token}, nil
+}
+
+// Call sends a JSON request, authenticated with token when set, and decodes a 2xx response into out
+func (c *Client) Call(ctx context.Context, method string, path string, token string, body any, out any) error {
+	var reader io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		reader = bytes.NewReader(data)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, c.ServerURL+path, reader)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+
+	resp, err := c.HTTP.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		var errBody struct {
+			Error string `json:"error"`
+		}
+		json.NewDecoder(resp.Body).Decode(&errBody)
+		return fmt.Errorf("%s %s returned %d: %s", method, path, resp.StatusCode, errBody.Error)
+	}
+	if out != nil && resp.StatusCode != http.StatusNoContent {
+		return json.NewDecoder(resp.Body).Decode(out)
+	}
+	return nil
+}
+
+// --- WebSocket ---
+
+// Event is a decoded server event
+type Event map[string]any
+
+// Type returns the event type
+func (e Event) Type() string {
+	return e.String("type")
+}
+
+// String returns a string field, or "" if it is missing
+func (e Event) String(key string) string {
+	value, _ := e[key].(string)
+	return value
+}
+
+// Number returns a number field, or 0 if it is missing
+func (e Event) Number(key string) float64 {
+	value, _ := e[key].(float64)
+	return value
+}
+
+// Conn is a WebSocket connection to the server. It is not safe for concurrent use.
+type Conn struct {
+	ws *websocket.Conn
+}
+
+// Dial opens a WebSocket connection with the token in the Authorization header and extra query
+// parameters (capabilities, protocol_version, since). An empty token omits the header: the first
+// message must then be "auth".
+func (c *Client) Dial(ctx context.Context, token string, query url.Values) (*Conn, error) {
+	if query == nil {
+		query = url.Values{}
+	}
+	header := http.Header{}
+	if token != "" {
+		header.Set("Authorization", "Bearer "+token)
+	}
+	wsURL := strings.Replace(c.ServerURL, "http", "ws", 1) + "/ws?" + query.Encode()
+
+	ws, _, err := websocket.DefaultDialer.DialContext(ctx, wsURL, header)
+	if err != nil {
+		return nil, err
+	}
+	return &Conn{ws: ws}, nil
+}
+
+// Send writes a JSON message
+func (c *Conn) Send(msg any) error {
+	return c.ws.WriteJSON(msg)
+}
+
+// Next returns the next event
+func (c *Conn) Next(timeout time.Duration) (Event, error) {
+	c.ws.SetReadDeadline(time.Now().Add(timeout))
+	_, data, err := c.ws.ReadMessage()
+	if err != nil {
+		return nil, err
+	}
+	var event Event
+	if err := json.Unmarshal(data, &event); err != nil {
+		return nil, fmt.Errorf("invalid event %s: %w", data, err)
+	}
+	return event, nil
+}
+
+// WaitFor skips events until one of the given type arrives
+func (c *Conn) WaitFor(eventType string, timeout time.Duration) (Event, error) {
+	deadline := time.Now().Add(timeout)
+	for {
+		remaining := time.Until(deadline)
+		if remaining <= 0 {
+			return nil, fmt.Errorf("no %s event within %s", eventType, timeout)
+		}
+		event, err := c.Next(remaining)
+		if err != nil {
+			return nil, fmt.Errorf("waiting for %s: %w", eventType, err)
+		}
+		if event.Type() == eventType {
+			return event, nil
+		}
+	}
+}
+
+// ExpectClose reads until the server closes the connection and returns the close code
+func (c *Conn) ExpectClose(timeout time.Duration) (int, error) {
+	c.ws.SetReadDeadline(time.Now().Add(timeout))
+	for {
+		if _, _, err := c.ws.ReadMessage(); err != nil {
+			var closeErr *websocket.CloseError
+			if errors.As(err, &closeErr) {
+				return closeErr.Code, nil
+			}
+			return 0, fmt.Errorf("connection ended without close frame: %w", err)
+		}
+	}
+}
+
+// Close closes the connection
+func (c *Conn) Close() error {
+	return c.ws.Close()
+}
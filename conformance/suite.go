@@ -0,0 +1,86 @@
+// Package conformance drives a running server through the documented WebSocket protocol and checks
+// its answers and close codes. The cases double as executable protocol documentation: each one is
+// the shortest exchange showing a message type at work. Run them with cmd/conformance before and
+// after protocol changes. The server under test needs signup and login rate limits
+// (SIGNUP_RATE_LIMIT, LOGIN_RATE_LIMIT) high enough for the accounts a run creates, and the default
+// WebSocket message limits.
+package conformance
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"regexp"
+	"strconv"
+	"time"
+)
+
+// eventTimeout is how long a case waits for an expected event
+const eventTimeout = 5 * time.Second
+
+// casePassword is the password of the accounts the cases create
+const casePassword = "conformance-password"
+
+// Case is one protocol check
+type Case struct {
+	Name string
+	Run  func(ctx context.Context, env *Env) error
+}
+
+// Result is the outcome of a case
+type Result struct {
+	Name     string
+	Err      error // nil if the case passed
+	Duration time.Duration
+}
+
+// Env is what the cases of a run share
+type Env struct {
+	Client *Client
+	prefix string // Makes the usernames of a run unique
+	users  int
+}
+
+// NewUser signs up a fresh account
+func (e *Env) NewUser(ctx context.Context, name string) (Session, error) {
+	e.users++
+	return e.Client.Signup(ctx, fmt.Sprintf("cf%s%s%d", e.prefix, name, e.users), casePassword)
+}
+
+// Connect opens a connection for the session and checks that the capabilities event comes first
+func (e *Env) Connect(ctx context.Context, session Session, query url.Values) (*Conn, error) {
+	conn, err := e.Client.Dial(ctx, session.Token, query)
+	if err != nil {
+		return nil, err
+	}
+	event, err := conn.Next(eventTimeout)
+	if err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("no capabilities event: %w", err)
+	}
+	if event.Type() != "capabilities" {
+		conn.Close()
+		return nil, fmt.Errorf("first event is %q, want capabilities", event.Type())
+	}
+	return conn, nil
+}
+
+// Run executes the cases whose name matches filter (all if nil) against the server, reporting
+// each result as it completes. Cases create their own accounts and run one after the other.
+func Run(ctx context.Context, serverURL string, filter *regexp.Regexp, report func(Result)) []Result {
+	env := &Env{Client: NewClient(serverURL), prefix: strconv.FormatInt(time.Now().Unix()%1e6, 36)}
+
+	var results []Result
+	for _, c := range Cases {
+		if filter != nil && !filter.MatchString(c.Name) {
+			continue
+		}
+		started := time.Now()
+		result := Result{Name: c.Name, Err: c.Run(ctx, env), Duration: time.Since(started)}
+		results = append(results, result)
+		if report != nil {
+			report(result)
+		}
+	}
+	return results
+}
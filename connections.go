@@ -0,0 +1,258 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"time"
+	"unicode/utf8"
+
+	db "websocket-simple-chat-app/db/sqlc"
+	"websocket-simple-chat-app/hub"
+	"websocket-simple-chat-app/presence"
+	"websocket-simple-chat-app/token"
+	"websocket-simple-chat-app/ws"
+)
+
+// chatServer holds what the connections of every transport share. /ws and the gRPC ChatStream (see
+// grpc.go) authenticate their clients their own way, then register them and process the messages
+// they send the same way: a gRPC client is a hub client like any WebSocket connection.
+type chatServer struct {
+	store           *db.Queries
+	hub             *hub.Hub
+	presenceTracker presence.Tracker
+	typing          *typingTracker
+	roomTyping      *roomTypingTracker
+	dispatcher      *ws.Dispatcher
+	tokenMaker      token.Maker
+	sessions        sessionConfig
+}
+
+// connect registers an authenticated client whose write pump runs, marks its user online if it is
+// their first connection, and catches it up on what it missed. The returned function must be
+// called once the client is gone: it unregisters it and marks the user offline if it was their
+// last connection.
+func (s *chatServer) connect(client *hub.Client, account db.User, syncSince int64, syncRequested bool) (disconnect func()) {
+	userID := account.ID
+	username := account.Username
+	sendCapabilities(client)
+
+	// Register connection with the hub
+	isFirstConnection := s.hub.Register(client)
+
+	// Update status to online ONLY if it's the first connection for this user, on any instance
+	var err error
+	if isFirstConnection {
+		isFirstConnection, err = s.presenceTracker.Connect(context.Background(), userID)
+		if err != nil {
+			log.Printf("WS Error: Failed to record presence of user %d: %v", userID, err)
+			isFirstConnection = true
+		}
+	}
+	if isFirstConnection {
+		err = s.store.UpdateUserStatus(context.Background(), db.UpdateUserStatusParams{
+			ID:     userID,
+			Status: "online",
+		})
+		if err != nil {
+			log.Printf("WS Error: Failed to update user %d status to online: %v\n", userID, err)
+			// Decide if we should close the connection here or just log
+		} else {
+			log.Printf("User %s (ID: %d) connected (first WS connection)\n", username, userID)
+
+			// --- Broadcast User Online Status (invisible or hidden users stay offline to others) ---
+			if shownOnline(s.store, account) {
+				onlineMsg := UserStatusBroadcast{Type: "user_online", UserID: userID, CreatedAt: time.Now().UTC(), Presence: account.Presence}
+				// Broadcast to everyone *except* the user who just connected
+				broadcastPresenceEvent(s.hub, onlineMsg, userID)
+				log.Printf("Broadcasted user_online for User %s (ID: %d)", username, userID)
+			}
+			// --- End Broadcast ---
+		}
+	} else {
+		log.Printf("User %s (ID: %d) connected (additional WS connection)\n", username, userID)
+	}
+
+	// Catch the client up on the messages it missed while offline
+	if syncRequested {
+		sendMissedMessages(s.store, client, syncSince)
+	}
+
+	// Senders of messages deferred while the user was away learn that they landed
+	reportDeferredDeliveries(s.store, s.hub, userID)
+
+	// --- Handle Disconnect ---
+	return func() {
+		isLastConnection := s.hub.Unregister(client)
+		if isLastConnection {
+			stopUserTyping(s.hub, s.typing, userID)
+			s.roomTyping.RemoveUser(userID)
+			isLastConnection, err = s.presenceTracker.Disconnect(context.Background(), userID)
+			if err != nil {
+				log.Printf("WS Error: Failed to record presence of user %d: %v", userID, err)
+				isLastConnection = true
+			}
+		}
+		if isLastConnection {
+			err = s.store.UpdateUserStatus(context.Background(), db.UpdateUserStatusParams{
+				ID:     userID,
+				Status: "offline",
+			})
+			if err != nil {
+				log.Printf("WS Error: Failed to update user %d status to offline on disconnect: %v\n", userID, err)
+			} else {
+				log.Printf("User %s (ID: %d) disconnected (last WS connection)\n", username, userID)
+
+				// --- Broadcast User Offline Status (invisible or hidden users already look offline) ---
+				// The presence and settings may have changed since connecting
+				current, err := s.store.GetUserByID(context.Background(), userID)
+				if err != nil || shownOnline(s.store, current) {
+					// Broadcast to all remaining clients (no exclusion needed)
+					broadcastPresenceEvent(s.hub, newUserOfflineBroadcast(userID), 0)
+					log.Printf("Broadcasted user_offline for User %s (ID: %d)", username, userID)
+				}
+				// --- End Broadcast ---
+			}
+		} else {
+			log.Printf("User %s (ID: %d) disconnected (still has other WS connections)\n", username, userID)
+		}
+	}
+}
+
+// chatConnection is the read side of one registered client: it processes the messages the client
+// sends, one at a time
+type chatConnection struct {
+	server   *chatServer
+	client   *hub.Client
+	session  *ws.Session
+	userID   int32
+	username string
+	guest    bool
+
+	apiKeySession bool     // Authenticated with an API key rather than a token
+	apiKeyScopes  []string // Of the API key, if any
+
+	rateLimitViolations int  // Rate limited messages in a row
+	rateWarned          bool // A rate_warning was sent since the user was last below the threshold
+}
+
+// newConnection starts the session of a registered client. Token sessions are closed when the token
+// expires unless extended first; API key sessions last until the key is revoked. The returned
+// function stops the session timer.
+func (s *chatServer) newConnection(client *hub.Client, payload *token.Payload, account db.User, apiKeySession bool, apiKeyScopes []string) (*chatConnection, func()) {
+	var sessionTimer *time.Timer
+	stop := func() {}
+	if !apiKeySession {
+		sessionTimer = startSessionTimer(client, payload.ExpiredAt)
+		stop = func() { sessionTimer.Stop() }
+	}
+	session := ws.NewSession(payload, func(renewed *token.Payload) {
+		sessionTimer.Reset(time.Until(renewed.ExpiredAt)) // Only token sessions can reauth
+	})
+
+	return &chatConnection{
+		server:        s,
+		client:        client,
+		session:       session,
+		userID:        payload.UserID,
+		username:      payload.Username,
+		guest:         account.Role == roleGuest,
+		apiKeySession: apiKeySession,
+		apiKeyScopes:  apiKeyScopes,
+	}, stop
+}
+
+// handleMessage processes a text message of the client: it is checked, rate limited and dispatched
+// by type (see ws_handlers.go). Problems are reported to the client as error events.
+func (cc *chatConnection) handleMessage(p []byte, receivedAt time.Time) {
+	s, client, username, userID := cc.server, cc.client, cc.username, cc.userID
+
+	// Sliding sessions: activity keeps the session's token fresh (guest tokens end with the account)
+	if !cc.guest && !cc.apiKeySession && s.sessions.shouldRenew(cc.session.Token) {
+		renewed, renewErr := renewSessionToken(s.tokenMaker, client, cc.session.Token, s.sessions.AccessTokenDuration)
+		if renewErr != nil {
+			log.Printf("WS Error: Failed to renew token of user %d: %v", userID, renewErr)
+		} else {
+			cc.session.Renew(renewed)
+		}
+	}
+
+	// 1. Text frames must be UTF-8; json.Unmarshal would silently replace invalid bytes
+	if !utf8.Valid(p) {
+		log.Printf("WS Warning: Message from %s (ID: %d) is not valid UTF-8", username, userID)
+		sendWsError(client, wsErrorInvalidUTF8, "", "", "message is not valid UTF-8")
+		return
+	}
+
+	// 2. Unmarshal into a generic map to check the type first
+	var genericMsg map[string]any
+	if err := json.Unmarshal(p, &genericMsg); err != nil {
+		log.Printf("WS Error: Failed to unmarshal generic message from %s (ID: %d): %v. Payload: %s", username, userID, err, string(p))
+		sendWsError(client, wsErrorInvalidJSON, "", "", "message is not a JSON object")
+		return
+	}
+
+	// 3. Check the message type
+	msgType, ok := genericMsg["type"].(string)
+	if !ok {
+		log.Printf("WS Error: Message type is missing or not a string from %s (ID: %d). Payload: %s", username, userID, string(p))
+		sendWsError(client, wsErrorInvalidJSON, "", "", "'type' is missing or not a string")
+		return
+	}
+	clientMsgID, _ := genericMsg["client_msg_id"].(string)
+
+	log.Printf("Received message type '%s' from %s (ID: %d)", msgType, username, userID)
+
+	if cc.guest && !guestAllowedMessageTypes[msgType] {
+		log.Printf("WS Warning: Guest %s (ID: %d) is not allowed to send '%s'", username, userID, msgType)
+		sendWsError(client, wsErrorNotAllowed, msgType, clientMsgID, "guests cannot send this message type")
+		return
+	}
+	if cc.apiKeySession && !apiKeyAllowsMessageType(cc.apiKeyScopes, msgType) {
+		log.Printf("WS Warning: API key session of %s (ID: %d) is not allowed to send '%s'", username, userID, msgType)
+		sendWsError(client, wsErrorNotAllowed, msgType, clientMsgID, "the API key does not allow this message type")
+		return
+	}
+
+	// The rate limit is per user, over all their connections
+	usage := client.AllowMessage()
+	if !usage.Allowed {
+		cc.rateLimitViolations++
+		if cc.rateLimitViolations == wsRateLimitMaxViolations {
+			log.Printf("WS Warning: Closing connection of %s (ID: %d) after %d rate limited messages", username, userID, cc.rateLimitViolations)
+			client.Close(wsCloseRateLimited, "rate limit exceeded")
+		}
+		if cc.rateLimitViolations >= wsRateLimitMaxViolations {
+			return // Closing
+		}
+		sendRateLimited(client, msgType, clientMsgID, usage.RetryAfter)
+		return
+	}
+	cc.rateLimitViolations = 0
+
+	// Warn once when the user gets close to the limit, again after the bucket refilled
+	if !nearRateLimit(usage) {
+		cc.rateWarned = false
+	} else if !cc.rateWarned {
+		sendRateWarning(client, usage)
+		cc.rateWarned = true
+	}
+
+	// 4. Handle based on type (see ws_handlers.go)
+	handled := s.dispatcher.Dispatch(&ws.Context{
+		Hub:        s.hub,
+		Store:      s.store,
+		Client:     client,
+		Session:    cc.session,
+		UserID:     userID,
+		Username:   username,
+		Guest:      cc.guest,
+		Type:       msgType,
+		Message:    p,
+		ReceivedAt: receivedAt,
+	})
+	if !handled {
+		log.Printf("WS Warning: Received unhandled message type '%s' from %s (ID: %d)", msgType, username, userID)
+		sendWsError(client, wsErrorUnknownType, msgType, clientMsgID, "unknown message type")
+	}
+}
@@ -0,0 +1,255 @@
+package main
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"sort"
+	"strings"
+
+	"websocket-simple-chat-app/hub"
+	"websocket-simple-chat-app/util/preview"
+)
+
+// Message content types. The messages.content_type column is free-form: a new kind of message only
+// needs an entry in contentTypes.
+const (
+	contentTypeText        = "text"
+	contentTypeMarkdown    = "markdown"
+	contentTypeAttachment  = "attachment"
+	contentTypeSystem      = "system"
+	contentTypePoll        = "poll"
+	contentTypeLocation    = "location"
+	contentTypeEncrypted   = "encrypted"
+	contentTypeContactCard = "contact_card"
+)
+
+// Poll limits
+const (
+	pollMinOptions = 2
+	pollMaxOptions = 10
+)
+
+// contentTypeSpec holds the validation and rendering rules of a content type
+type contentTypeSpec struct {
+	clientSendable bool                        // Clients may send it in a private_message; otherwise only the server creates it
+	validate       func(content string) error  // Checks the content on top of the length limit; nil accepts any text
+	preview        func(content string) string // Plain-text rendering for clients without the content_types capability; nil shows the content as is
+}
+
+var contentTypes = map[string]contentTypeSpec{
+	contentTypeText:        {clientSendable: true},
+	contentTypeMarkdown:    {clientSendable: true},
+	contentTypeAttachment:  {clientSendable: true, validate: validateAttachment, preview: previewAttachment},
+	contentTypeSystem:      {},
+	contentTypePoll:        {clientSendable: true, validate: validatePoll, preview: previewPoll},
+	contentTypeLocation:    {clientSendable: true, validate: validateLocation, preview: previewLocation},
+	contentTypeEncrypted:   {clientSendable: true, validate: validateEncrypted, preview: func(string) string { return "[Encrypted message]" }},
+	contentTypeContactCard: {preview: previewContactCard},
+}
+
+// validateClientContent checks a message a client wants to send. An empty type means text.
+// It returns the content type to store.
+func validateClientContent(contentType string, content string) (string, error) {
+	if contentType == "" {
+		contentType = contentTypeText
+	}
+	spec, ok := contentTypes[contentType]
+	if !ok || !spec.clientSendable {
+		return "", fmt.Errorf("unsupported content_type %q", contentType)
+	}
+	if spec.validate != nil {
+		if err := spec.validate(content); err != nil {
+			return "", fmt.Errorf("invalid %s content: %w", contentType, err)
+		}
+	}
+	return contentType, nil
+}
+
+// sendableContentTypes lists the content types clients may send, sorted
+func sendableContentTypes() []string {
+	var sendable []string
+	for contentType, spec := range contentTypes {
+		if spec.clientSendable {
+			sendable = append(sendable, contentType)
+		}
+	}
+	sort.Strings(sendable)
+	return sendable
+}
+
+// contentPreview renders a message as plain text, e.g. for clients that do not know its content type
+func contentPreview(contentType string, content string) string {
+	if spec, ok := contentTypes[contentType]; ok && spec.preview != nil {
+		return spec.preview(content)
+	}
+	return content
+}
+
+// messagePreviewBudget bounds the previews of messages in events and conversation lists
+var messagePreviewBudget = preview.Budget{Graphemes: 120, Bytes: 512}
+
+// messagePreview renders a message as a short single-line text: structured content as its text
+// preview, markdown without its syntax, cut between characters with an ellipsis when too long
+func messagePreview(contentType string, content string) string {
+	text := contentPreview(contentType, content)
+	if contentType == contentTypeMarkdown {
+		text = preview.StripMarkdown(text)
+	}
+	return messagePreviewBudget.Truncate(preview.SingleLine(text))
+}
+
+// isPlainContent reports whether a content type reads fine as text, so every client gets it unchanged
+func isPlainContent(contentType string) bool {
+	return contentTypes[contentType].preview == nil
+}
+
+// renderIncomingMessage marshals an incoming_message for each kind of connection: connections with the
+// content_types capability get the content and its type, the others a text preview. Plain text is the
+// same for both.
+func renderIncomingMessage(msg OutgoingWsMessage, contentType string) (func(hub.Capabilities) []byte, error) {
+	if contentType != contentTypeText {
+		msg.ContentType = contentType
+	}
+	msg.Preview = messagePreview(contentType, msg.Content)
+	richJSON, err := json.Marshal(msg)
+	if err != nil {
+		return nil, err
+	}
+	if isPlainContent(contentType) {
+		return func(hub.Capabilities) []byte { return richJSON }, nil
+	}
+
+	fallback := msg
+	fallback.ContentType = ""
+	fallback.Content = contentPreview(contentType, msg.Content)
+	textJSON, err := json.Marshal(fallback)
+	if err != nil {
+		return nil, err
+	}
+	return func(capabilities hub.Capabilities) []byte {
+		if capabilities.Supports(capabilityContentTypes) {
+			return richJSON
+		}
+		return textJSON
+	}, nil
+}
+
+// --- Structured Contents ---
+
+// AttachmentContent is the content of an attachment message: a file uploaded elsewhere
+type AttachmentContent struct {
+	URL       string `json:"url"` // https only
+	Name      string `json:"name"`
+	MimeType  string `json:"mime_type"`
+	SizeBytes int64  `json:"size_bytes"`
+}
+
+// PollContent is the content of a poll message
+type PollContent struct {
+	Question string   `json:"question"`
+	Options  []string `json:"options"`
+}
+
+// LocationContent is the content of a location message
+type LocationContent struct {
+	Latitude  float64 `json:"latitude"`
+	Longitude float64 `json:"longitude"`
+	Label     string  `json:"label,omitempty"`
+}
+
+// decodeContent unmarshals a JSON content, rejecting unknown fields
+func decodeContent(content string, target any) error {
+	decoder := json.NewDecoder(strings.NewReader(content))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(target); err != nil {
+		return errors.New("content must be a JSON object of the documented shape")
+	}
+	return nil
+}
+
+func validateAttachment(content string) error {
+	var attachment AttachmentContent
+	if err := decodeContent(content, &attachment); err != nil {
+		return err
+	}
+	parsed, err := url.Parse(attachment.URL)
+	if err != nil || parsed.Scheme != "https" || parsed.Host == "" {
+		return errors.New("url must be an https URL")
+	}
+	if strings.TrimSpace(attachment.Name) == "" || attachment.SizeBytes < 0 {
+		return errors.New("name is required and size_bytes must not be negative")
+	}
+	return nil
+}
+
+func previewAttachment(content string) string {
+	var attachment AttachmentContent
+	json.Unmarshal([]byte(content), &attachment)
+	return fmt.Sprintf("[Attachment] %s %s", attachment.Name, attachment.URL)
+}
+
+func validatePoll(content string) error {
+	var poll PollContent
+	if err := decodeContent(content, &poll); err != nil {
+		return err
+	}
+	if strings.TrimSpace(poll.Question) == "" {
+		return errors.New("question is required")
+	}
+	if len(poll.Options) < pollMinOptions || len(poll.Options) > pollMaxOptions {
+		return fmt.Errorf("a poll needs %d to %d options", pollMinOptions, pollMaxOptions)
+	}
+	for _, option := range poll.Options {
+		if strings.TrimSpace(option) == "" {
+			return errors.New("options must not be empty")
+		}
+	}
+	return nil
+}
+
+func previewPoll(content string) string {
+	var poll PollContent
+	json.Unmarshal([]byte(content), &poll)
+	return fmt.Sprintf("[Poll] %s (%s)", poll.Question, strings.Join(poll.Options, " / "))
+}
+
+func validateLocation(content string) error {
+	var location LocationContent
+	if err := decodeContent(content, &location); err != nil {
+		return err
+	}
+	if location.Latitude < -90 || location.Latitude > 90 || location.Longitude < -180 || location.Longitude > 180 {
+		return errors.New("latitude must be within [-90, 90] and longitude within [-180, 180]")
+	}
+	return nil
+}
+
+func previewLocation(content string) string {
+	var location LocationContent
+	json.Unmarshal([]byte(content), &location)
+	if location.Label != "" {
+		return fmt.Sprintf("[Location] %s (%.5f, %.5f)", location.Label, location.Latitude, location.Longitude)
+	}
+	return fmt.Sprintf("[Location] %.5f, %.5f", location.Latitude, location.Longitude)
+}
+
+// validateEncrypted only checks the envelope: the server cannot read end-to-end encrypted messages
+func validateEncrypted(content string) error {
+	if _, err := base64.StdEncoding.DecodeString(content); err != nil {
+		return errors.New("content must be standard base64")
+	}
+	return nil
+}
+
+func previewContactCard(content string) string {
+	var card ContactCard
+	json.Unmarshal([]byte(content), &card)
+	return contactCardText(card)
+}
+
+func contactCardText(card ContactCard) string {
+	return fmt.Sprintf("Shared contact: %s (user #%d)", card.Username, card.UserID)
+}
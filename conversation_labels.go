@@ -0,0 +1,135 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"log"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/gin-gonic/gin"
+
+	db "websocket-simple-chat-app/db/sqlc"
+	"websocket-simple-chat-app/hub"
+	"websocket-simple-chat-app/token"
+)
+
+// Users sort their conversations with labels (e.g. work, family), which only they see. GET
+// /conversations can be filtered by label. A change is sent to all of the user's connections as a
+// conversation_labels_changed event, so their other devices stay in sync.
+const (
+	maxConversationLabels = 10 // Per conversation
+	maxLabelLength        = 50 // Characters
+)
+
+// ConversationLabelsChangedMessage is sent to a user's sessions after they changed the labels of a conversation
+//
+//wsschema:server
+type ConversationLabelsChangedMessage struct {
+	Type      string    `json:"type"`       // "conversation_labels_changed"
+	PartnerID int32     `json:"partner_id"` // The partner whose conversation was relabeled
+	Labels    []string  `json:"labels"`     // All labels of the conversation now, sorted; empty if none
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// normalizeLabels trims the labels and drops duplicates and returns them sorted
+func normalizeLabels(labels []string) ([]string, error) {
+	normalized := make([]string, 0, len(labels))
+	for _, label := range labels {
+		label = strings.TrimSpace(label)
+		if label == "" {
+			return nil, errors.New("labels must not be empty")
+		}
+		if utf8.RuneCountInString(label) > maxLabelLength {
+			return nil, errors.New("labels are limited to 50 characters")
+		}
+		if !slices.Contains(normalized, label) {
+			normalized = append(normalized, label)
+		}
+	}
+	if len(normalized) > maxConversationLabels {
+		return nil, errors.New("a conversation can have at most 10 labels")
+	}
+	slices.Sort(normalized)
+	return normalized, nil
+}
+
+// conversationLabels returns the user's labels of the conversations with the given partners
+func conversationLabels(store *db.Queries, userID int32, partnerIDs []int32) (map[int32][]string, error) {
+	rows, err := store.ListConversationLabelsForPartners(context.Background(), db.ListConversationLabelsForPartnersParams{
+		UserID:     userID,
+		PartnerIds: partnerIDs,
+	})
+	if err != nil {
+		return nil, err
+	}
+	labels := make(map[int32][]string)
+	for _, row := range rows {
+		labels[row.PartnerID] = append(labels[row.PartnerID], row.Label)
+	}
+	return labels, nil
+}
+
+// setConversationLabelsHandler replaces the labels of a conversation of the authenticated user
+func setConversationLabelsHandler(store *db.Queries, connectionHub *hub.Hub) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		type setConversationLabelsRequest struct {
+			Labels []string `json:"labels" binding:"required"` // Empty to remove all labels
+		}
+		payload := c.MustGet(authorizationPayloadKey).(*token.Payload)
+
+		partnerID, ok := parsePartnerIDParam(c, store)
+		if !ok {
+			return
+		}
+
+		var req setConversationLabelsRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		labels, err := normalizeLabels(req.Labels)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		err = store.SetConversationLabels(context.Background(), db.SetConversationLabelsParams{
+			UserID:    payload.UserID,
+			PartnerID: partnerID,
+			Labels:    labels,
+		})
+		if err != nil {
+			log.Printf("Error labeling conversation %d for user %d: %v", partnerID, payload.UserID, err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to set labels"})
+			return
+		}
+
+		sendJSONToUser(connectionHub, payload.UserID, ConversationLabelsChangedMessage{
+			Type:      "conversation_labels_changed",
+			PartnerID: partnerID,
+			Labels:    labels,
+			CreatedAt: time.Now().UTC(),
+		})
+		c.JSON(http.StatusOK, gin.H{"partner_id": partnerID, "labels": labels})
+	}
+}
+
+// listLabelsHandler returns the labels the authenticated user uses, with their number of conversations
+func listLabelsHandler(store *db.Queries) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		payload := c.MustGet(authorizationPayloadKey).(*token.Payload)
+
+		labels, err := store.ListUserLabels(context.Background(), payload.UserID)
+		if err != nil {
+			log.Printf("Error listing labels for user %d: %v", payload.UserID, err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to list labels"})
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{"labels": labels})
+	}
+}
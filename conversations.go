@@ -0,0 +1,496 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	db "websocket-simple-chat-app/db/sqlc"
+	"websocket-simple-chat-app/hub"
+	"websocket-simple-chat-app/pagination"
+	"websocket-simple-chat-app/token"
+)
+
+// muteDurations maps the accepted mute durations to their length (0 means forever)
+var muteDurations = map[string]time.Duration{
+	"1h":      time.Hour,
+	"8h":      8 * time.Hour,
+	"forever": 0,
+}
+
+// muteSweepInterval is how often expired mutes are removed
+const muteSweepInterval = time.Minute
+
+// GET /conversations page sizes
+const (
+	conversationsDefaultLimit = 20
+	conversationsMaxLimit     = 100
+)
+
+// ConversationUnmutedMessage is sent to a user's sessions when one of their mutes expires
+//
+//wsschema:server
+type ConversationUnmutedMessage struct {
+	Type      string    `json:"type"`       // "conversation_unmuted"
+	PartnerID int32     `json:"partner_id"` // The conversation partner that is no longer muted
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// ConversationUnarchivedMessage is sent to a user's sessions when a conversation is automatically unarchived
+//
+//wsschema:server
+type ConversationUnarchivedMessage struct {
+	Type      string    `json:"type"`       // "conversation_unarchived"
+	PartnerID int32     `json:"partner_id"` // The partner whose conversation is back in the main list
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// ConversationClearedMessage is sent to a user's own sessions after they cleared a conversation
+//
+//wsschema:server
+type ConversationClearedMessage struct {
+	Type            string    `json:"type"`              // "conversation_cleared"
+	PartnerID       int32     `json:"partner_id"`        // The partner whose conversation was cleared
+	ClearedBeforeID int64     `json:"cleared_before_id"` // Messages with an ID up to this one are hidden
+	CreatedAt       time.Time `json:"created_at"`
+}
+
+// conversationMuteResponse is the API representation of a mute (muted_until is null when muted forever)
+type conversationMuteResponse struct {
+	PartnerID  int32      `json:"partner_id"`
+	MutedUntil *time.Time `json:"muted_until"`
+}
+
+func newConversationMuteResponse(mute db.ConversationMute) conversationMuteResponse {
+	response := conversationMuteResponse{PartnerID: mute.PartnerID}
+	if mute.MutedUntil.Valid {
+		response.MutedUntil = &mute.MutedUntil.Time
+	}
+	return response
+}
+
+// parsePartnerIDParam reads the :partner_id path parameter and checks that the user exists.
+// It writes the error response itself and returns false on failure.
+func parsePartnerIDParam(c *gin.Context, store *db.Queries) (int32, bool) {
+	partnerID, err := strconv.ParseInt(c.Param("partner_id"), 10, 32)
+	if err != nil || partnerID <= 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid 'partner_id' format"})
+		return 0, false
+	}
+
+	if _, err := store.GetUserByID(context.Background(), int32(partnerID)); err != nil {
+		if err == sql.ErrNoRows {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Partner not found"})
+			return 0, false
+		}
+		log.Printf("Error fetching partner %d: %v", partnerID, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch partner"})
+		return 0, false
+	}
+
+	return int32(partnerID), true
+}
+
+// --- Conversation List ---
+
+// conversationResponse is a conversation list entry
+type conversationResponse struct {
+	db.ListConversationsRow
+	MutedUntil         *time.Time `json:"muted_until"` // Replaces the nullable column: null when not muted or muted forever
+	LastMessagePreview string     `json:"last_message_preview"`
+	Labels             []string   `json:"labels"` // The user's labels of the conversation, see conversation_labels.go
+}
+
+// listConversationsHandler returns everyone the authenticated user has chatted with, with the last message
+// and the number of unread messages, most recently active conversation first. Archived conversations
+// are left out, unless the archived query parameter is true, which returns only them. The optional
+// label query parameter only returns the conversations with that label.
+func listConversationsHandler(store *db.Queries) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		payload := c.MustGet(authorizationPayloadKey).(*token.Payload)
+
+		page, beforeID, ok := parseIDPage(c, conversationsDefaultLimit, conversationsMaxLimit)
+		if !ok {
+			return
+		}
+		archived := false
+		if value := c.Query("archived"); value != "" {
+			parsed, err := strconv.ParseBool(value)
+			if err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid 'archived', must be true or false"})
+				return
+			}
+			archived = parsed
+		}
+
+		conversations, err := store.ListConversations(context.Background(), db.ListConversationsParams{
+			UserID:    payload.UserID,
+			Archived:  archived,
+			BeforeID:  beforeID,
+			Label:     strings.TrimSpace(c.Query("label")),
+			PageLimit: page.FetchLimit(),
+		})
+		if err != nil {
+			log.Printf("Error listing conversations for user %d: %v", payload.UserID, err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to list conversations"})
+			return
+		}
+
+		conversations, nextCursor := pagination.Trim(conversations, page, func(r db.ListConversationsRow) string { return pagination.IDKey(r.LastMessageID) })
+		partnerIDs := make([]int32, len(conversations))
+		for i, conversation := range conversations {
+			partnerIDs[i] = conversation.PartnerID
+		}
+		labels, err := conversationLabels(store, payload.UserID, partnerIDs)
+		if err != nil {
+			log.Printf("Error listing conversation labels for user %d: %v", payload.UserID, err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to list conversations"})
+			return
+		}
+
+		response := make([]conversationResponse, len(conversations))
+		for i, conversation := range conversations {
+			response[i] = conversationResponse{
+				ListConversationsRow: conversation,
+				LastMessagePreview:   messagePreview(conversation.LastMessageContentType, conversation.LastMessageContent),
+				Labels:               labels[conversation.PartnerID],
+			}
+			if conversation.MutedUntil.Valid {
+				response[i].MutedUntil = &conversation.MutedUntil.Time
+			}
+			if response[i].Labels == nil {
+				response[i].Labels = []string{}
+			}
+		}
+		c.JSON(http.StatusOK, gin.H{"conversations": response, "next_cursor": nextCursor})
+	}
+}
+
+// --- Conversation Mutes ---
+
+// muteConversationHandler mutes a conversation for the authenticated user
+func muteConversationHandler(store *db.Queries) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		type muteConversationRequest struct {
+			Duration string `json:"duration" binding:"required"` // "1h", "8h" or "forever"
+		}
+		payload := c.MustGet(authorizationPayloadKey).(*token.Payload)
+
+		partnerID, ok := parsePartnerIDParam(c, store)
+		if !ok {
+			return
+		}
+
+		var req muteConversationRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		duration, ok := muteDurations[req.Duration]
+		if !ok {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid 'duration', must be one of: 1h, 8h, forever"})
+			return
+		}
+
+		var mutedUntil sql.NullTime
+		if duration > 0 {
+			mutedUntil = sql.NullTime{Time: time.Now().UTC().Add(duration), Valid: true}
+		}
+
+		mute, err := store.UpsertConversationMute(context.Background(), db.UpsertConversationMuteParams{
+			UserID:     payload.UserID,
+			PartnerID:  partnerID,
+			MutedUntil: mutedUntil,
+		})
+		if err != nil {
+			log.Printf("Error muting conversation %d for user %d: %v", partnerID, payload.UserID, err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to mute conversation"})
+			return
+		}
+
+		c.JSON(http.StatusOK, newConversationMuteResponse(mute))
+	}
+}
+
+// unmuteConversationHandler removes the mute of a conversation for the authenticated user
+func unmuteConversationHandler(store *db.Queries) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		payload := c.MustGet(authorizationPayloadKey).(*token.Payload)
+
+		partnerID, ok := parsePartnerIDParam(c, store)
+		if !ok {
+			return
+		}
+
+		err := store.DeleteConversationMute(context.Background(), db.DeleteConversationMuteParams{
+			UserID:    payload.UserID,
+			PartnerID: partnerID,
+		})
+		if err != nil {
+			log.Printf("Error unmuting conversation %d for user %d: %v", partnerID, payload.UserID, err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to unmute conversation"})
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{"message": "Conversation unmuted"})
+	}
+}
+
+// listConversationMutesHandler returns the active mutes of the authenticated user
+func listConversationMutesHandler(store *db.Queries) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		payload := c.MustGet(authorizationPayloadKey).(*token.Payload)
+
+		mutes, err := store.ListActiveConversationMutes(context.Background(), payload.UserID)
+		if err != nil {
+			log.Printf("Error listing mutes for user %d: %v", payload.UserID, err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to list muted conversations"})
+			return
+		}
+
+		responses := make([]conversationMuteResponse, 0, len(mutes))
+		for _, mute := range mutes {
+			responses = append(responses, newConversationMuteResponse(mute))
+		}
+
+		c.JSON(http.StatusOK, gin.H{"muted_conversations": responses})
+	}
+}
+
+// isConversationMuted reports whether userID currently has the conversation with partnerID muted.
+// Errors are logged and treated as not muted.
+func isConversationMuted(store *db.Queries, userID int32, partnerID int32) bool {
+	_, err := store.GetActiveConversationMute(context.Background(), db.GetActiveConversationMuteParams{
+		UserID:    userID,
+		PartnerID: partnerID,
+	})
+	if err != nil {
+		if err != sql.ErrNoRows {
+			log.Printf("Error checking mute of conversation %d for user %d: %v", partnerID, userID, err)
+		}
+		return false
+	}
+	return true
+}
+
+// runMuteSweeper periodically deletes expired mutes and tells the affected users' sessions
+func runMuteSweeper(store *db.Queries, connectionHub *hub.Hub) {
+	for range time.Tick(muteSweepInterval) {
+		expired, err := store.DeleteExpiredConversationMutes(context.Background())
+		if err != nil {
+			log.Printf("Error sweeping expired conversation mutes: %v", err)
+			continue
+		}
+
+		for _, mute := range expired {
+			sendJSONToUser(connectionHub, mute.UserID, ConversationUnmutedMessage{
+				Type:      "conversation_unmuted",
+				PartnerID: mute.PartnerID,
+				CreatedAt: time.Now().UTC(),
+			})
+		}
+		if len(expired) > 0 {
+			log.Printf("Expired %d conversation mutes", len(expired))
+		}
+	}
+}
+
+// --- Conversation Archives ---
+
+// archiveConversationHandler moves a conversation out of the authenticated user's main list
+func archiveConversationHandler(store *db.Queries) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		payload := c.MustGet(authorizationPayloadKey).(*token.Payload)
+
+		partnerID, ok := parsePartnerIDParam(c, store)
+		if !ok {
+			return
+		}
+
+		err := store.ArchiveConversation(context.Background(), db.ArchiveConversationParams{
+			UserID:    payload.UserID,
+			PartnerID: partnerID,
+		})
+		if err != nil {
+			log.Printf("Error archiving conversation %d for user %d: %v", partnerID, payload.UserID, err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to archive conversation"})
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{"message": "Conversation archived"})
+	}
+}
+
+// unarchiveConversationHandler moves a conversation back into the authenticated user's main list
+func unarchiveConversationHandler(store *db.Queries) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		payload := c.MustGet(authorizationPayloadKey).(*token.Payload)
+
+		partnerID, ok := parsePartnerIDParam(c, store)
+		if !ok {
+			return
+		}
+
+		_, err := store.UnarchiveConversation(context.Background(), db.UnarchiveConversationParams{
+			UserID:    payload.UserID,
+			PartnerID: partnerID,
+		})
+		if err != nil {
+			log.Printf("Error unarchiving conversation %d for user %d: %v", partnerID, payload.UserID, err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to unarchive conversation"})
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{"message": "Conversation unarchived"})
+	}
+}
+
+// listArchivedConversationsHandler returns the archived conversations of the authenticated user
+func listArchivedConversationsHandler(store *db.Queries) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		payload := c.MustGet(authorizationPayloadKey).(*token.Payload)
+
+		archives, err := store.ListArchivedConversations(context.Background(), payload.UserID)
+		if err != nil {
+			log.Printf("Error listing archived conversations for user %d: %v", payload.UserID, err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to list archived conversations"})
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{"archived_conversations": archives})
+	}
+}
+
+// unarchiveOnIncomingMessage unarchives the recipient's conversation with the sender after a new message,
+// telling the recipient's sessions when the conversation actually moved back. It does nothing unless
+// enabled (UNARCHIVE_ON_NEW_MESSAGE).
+func unarchiveOnIncomingMessage(store *db.Queries, connectionHub *hub.Hub, recipientID int32, senderID int32, enabled bool) {
+	if !enabled {
+		return
+	}
+
+	unarchived, err := store.UnarchiveConversation(context.Background(), db.UnarchiveConversationParams{
+		UserID:    recipientID,
+		PartnerID: senderID,
+	})
+	if err != nil {
+		log.Printf("Error unarchiving conversation %d for user %d: %v", senderID, recipientID, err)
+		return
+	}
+	if unarchived == 0 {
+		return
+	}
+
+	sendJSONToUser(connectionHub, recipientID, ConversationUnarchivedMessage{
+		Type:      "conversation_unarchived",
+		PartnerID: senderID,
+		CreatedAt: time.Now().UTC(),
+	})
+}
+
+// --- Conversation Clearing ---
+
+// clearConversationHandler hides the current history of a conversation for the authenticated user only.
+// The partner's copy is left untouched.
+func clearConversationHandler(store *db.Queries, connectionHub *hub.Hub) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		payload := c.MustGet(authorizationPayloadKey).(*token.Payload)
+
+		partnerID, ok := parsePartnerIDParam(c, store)
+		if !ok {
+			return
+		}
+
+		cleared, err := store.ClearConversation(context.Background(), db.ClearConversationParams{
+			UserID:    payload.UserID,
+			PartnerID: partnerID,
+		})
+		if err != nil {
+			log.Printf("Error clearing conversation %d for user %d: %v", partnerID, payload.UserID, err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to clear conversation"})
+			return
+		}
+
+		// Keep the user's other devices in sync
+		sendJSONToUser(connectionHub, payload.UserID, ConversationClearedMessage{
+			Type:            "conversation_cleared",
+			PartnerID:       partnerID,
+			ClearedBeforeID: cleared.ClearedBeforeID,
+			CreatedAt:       cleared.ClearedAt,
+		})
+
+		c.JSON(http.StatusOK, gin.H{"message": "Conversation cleared", "cleared_before_id": cleared.ClearedBeforeID})
+	}
+}
+
+// --- Conversation Typing Privacy ---
+
+// hideTypingHandler stops sending the authenticated user's typing indicators to the partner
+func hideTypingHandler(store *db.Queries) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		payload := c.MustGet(authorizationPayloadKey).(*token.Payload)
+
+		partnerID, ok := parsePartnerIDParam(c, store)
+		if !ok {
+			return
+		}
+
+		err := store.HideTypingFromPartner(context.Background(), db.HideTypingFromPartnerParams{
+			UserID:    payload.UserID,
+			PartnerID: partnerID,
+		})
+		if err != nil {
+			log.Printf("Error hiding typing of user %d from %d: %v", payload.UserID, partnerID, err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update typing privacy"})
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{"message": "Typing indicators hidden from this partner"})
+	}
+}
+
+// showTypingHandler sends the authenticated user's typing indicators to the partner again
+func showTypingHandler(store *db.Queries) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		payload := c.MustGet(authorizationPayloadKey).(*token.Payload)
+
+		partnerID, ok := parsePartnerIDParam(c, store)
+		if !ok {
+			return
+		}
+
+		_, err := store.ShowTypingToPartner(context.Background(), db.ShowTypingToPartnerParams{
+			UserID:    payload.UserID,
+			PartnerID: partnerID,
+		})
+		if err != nil {
+			log.Printf("Error showing typing of user %d to %d: %v", payload.UserID, partnerID, err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update typing privacy"})
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{"message": "Typing indicators shown to this partner"})
+	}
+}
+
+// listTypingPrivacyHandler returns the conversations in which the authenticated user hides their typing
+func listTypingPrivacyHandler(store *db.Queries) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		payload := c.MustGet(authorizationPayloadKey).(*token.Payload)
+
+		optOuts, err := store.ListTypingOptOuts(context.Background(), payload.UserID)
+		if err != nil {
+			log.Printf("Error listing typing privacy for user %d: %v", payload.UserID, err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to list typing privacy"})
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{"hidden_typing": optOuts})
+	}
+}
@@ -0,0 +1,111 @@
+package main
+
+import (
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+
+	"github.com/gin-contrib/cors"
+	"github.com/gin-gonic/gin"
+
+	"websocket-simple-chat-app/config"
+)
+
+// Browsers get a CORS policy per route group, configured with CORS_ALLOWED_ORIGINS and friends
+// (see config.Config):
+//   - the public endpoints (signup, login, the endpoints of signed-in users, integrations);
+//   - /admin and /support, which can be limited to the origins of the admin UI;
+//   - /ws, whose upgrades browsers do not preflight: the Origin header is checked by the upgrader;
+//   - server-to-server endpoints (SCIM, incoming webhooks, probes, metrics), without CORS headers,
+//     so browsers cannot call them from other sites.
+// The policies are applied by one global middleware rather than per gin group, because preflight
+// OPTIONS requests match no route and would never reach a group's middleware.
+
+// corsMethods are the methods the HTTP endpoints use
+var corsMethods = []string{"GET", "POST", "PUT", "PATCH", "DELETE", "OPTIONS"}
+
+// corsHeaders are the request headers clients may send, extended with CORS_ALLOW_HEADERS
+var corsHeaders = []string{"Origin", "Content-Type", "Accept", "Authorization", apiKeyHeader, idempotencyKeyHeader}
+
+// corsExposeHeaders are the response headers scripts may read
+var corsExposeHeaders = []string{"Retry-After", "Idempotent-Replayed"}
+
+// Path prefixes of the route groups, see corsMiddleware
+var (
+	corsAdminPrefixes  = []string{"/admin", "/support"}
+	corsServerPrefixes = []string{"/ws", "/scim", "/hooks", "/metrics", "/healthz", "/readyz"}
+)
+
+// newCORSPolicy returns the middleware of one policy, or an error for invalid origins
+func newCORSPolicy(cfg config.Config, origins []string) (gin.HandlerFunc, error) {
+	policy := cors.Config{
+		// No origins configured allows any origin (useful for development with file:// URLs)
+		AllowOrigins:    origins,
+		AllowAllOrigins: len(origins) == 0,
+		AllowMethods:    corsMethods,
+		AllowHeaders:    append(slices.Clone(corsHeaders), cfg.CORSAllowHeaders...),
+		ExposeHeaders:   corsExposeHeaders,
+		// For the cookie authentication method
+		AllowCredentials: true,
+		MaxAge:           cfg.CORSMaxAge,
+	}
+	if err := policy.Validate(); err != nil {
+		return nil, err
+	}
+	return cors.New(policy), nil
+}
+
+// corsMiddleware applies the policy of each request's route group
+func corsMiddleware(cfg config.Config) (gin.HandlerFunc, error) {
+	public, err := newCORSPolicy(cfg, cfg.CORSAllowedOrigins)
+	if err != nil {
+		return nil, err
+	}
+	admin, err := newCORSPolicy(cfg, cfg.CORSAdminAllowedOrigins)
+	if err != nil {
+		return nil, err
+	}
+
+	return func(c *gin.Context) {
+		path := c.Request.URL.Path
+		switch {
+		case hasPathPrefix(path, corsServerPrefixes):
+			// No CORS headers
+		case hasPathPrefix(path, corsAdminPrefixes):
+			admin(c)
+		default:
+			public(c)
+		}
+	}, nil
+}
+
+// hasPathPrefix reports whether the path is one of the prefixes or below one
+func hasPathPrefix(path string, prefixes []string) bool {
+	for _, prefix := range prefixes {
+		if path == prefix || strings.HasPrefix(path, prefix+"/") {
+			return true
+		}
+	}
+	return false
+}
+
+// wsOriginChecker returns the upgrader's origin check. Clients that send no Origin header (not
+// browsers) and same-origin pages are always accepted; no origins configured accepts any origin.
+func wsOriginChecker(origins []string) func(r *http.Request) bool {
+	return func(r *http.Request) bool {
+		origin := r.Header.Get("Origin")
+		if origin == "" || len(origins) == 0 || slices.Contains(origins, "*") {
+			return true
+		}
+		if u, err := url.Parse(origin); err == nil && strings.EqualFold(u.Host, r.Host) {
+			return true
+		}
+		for _, allowed := range origins {
+			if strings.EqualFold(origin, allowed) {
+				return true
+			}
+		}
+		return false
+	}
+}
@@ -0,0 +1,11 @@
+CREATE TABLE "login_history" (
+  "id" bigserial PRIMARY KEY,
+  "user_id" int NOT NULL,
+  "ip_address" varchar(45) NOT NULL,
+  "user_agent" text NOT NULL,
+  "created_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+ALTER TABLE "login_history" ADD FOREIGN KEY ("user_id") REFERENCES "users" ("id");
+
+CREATE INDEX idx_login_history_user_id ON login_history (user_id);
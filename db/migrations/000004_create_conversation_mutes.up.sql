@@ -0,0 +1,15 @@
+CREATE TABLE "conversation_mutes" (
+  "user_id" int NOT NULL,
+  "partner_id" int NOT NULL,
+  "muted_until" timestamptz,
+  "created_at" timestamptz NOT NULL DEFAULT (now()),
+  PRIMARY KEY ("user_id", "partner_id")
+);
+
+COMMENT ON COLUMN "conversation_mutes"."muted_until" IS 'NULL means muted forever';
+
+ALTER TABLE "conversation_mutes" ADD FOREIGN KEY ("user_id") REFERENCES "users" ("id");
+
+ALTER TABLE "conversation_mutes" ADD FOREIGN KEY ("partner_id") REFERENCES "users" ("id");
+
+CREATE INDEX idx_conversation_mutes_muted_until ON conversation_mutes (muted_until);
@@ -0,0 +1,10 @@
+CREATE TABLE "conversation_archives" (
+  "user_id" int NOT NULL,
+  "partner_id" int NOT NULL,
+  "created_at" timestamptz NOT NULL DEFAULT (now()),
+  PRIMARY KEY ("user_id", "partner_id")
+);
+
+ALTER TABLE "conversation_archives" ADD FOREIGN KEY ("user_id") REFERENCES "users" ("id");
+
+ALTER TABLE "conversation_archives" ADD FOREIGN KEY ("partner_id") REFERENCES "users" ("id");
@@ -0,0 +1,13 @@
+CREATE TABLE "conversation_clears" (
+  "user_id" int NOT NULL,
+  "partner_id" int NOT NULL,
+  "cleared_before_id" bigint NOT NULL,
+  "cleared_at" timestamptz NOT NULL DEFAULT (now()),
+  PRIMARY KEY ("user_id", "partner_id")
+);
+
+COMMENT ON COLUMN "conversation_clears"."cleared_before_id" IS 'Messages with an id up to and including this one are hidden from user_id';
+
+ALTER TABLE "conversation_clears" ADD FOREIGN KEY ("user_id") REFERENCES "users" ("id");
+
+ALTER TABLE "conversation_clears" ADD FOREIGN KEY ("partner_id") REFERENCES "users" ("id");
@@ -0,0 +1,3 @@
+ALTER TABLE "users" ADD COLUMN "deactivated_at" timestamptz;
+
+COMMENT ON COLUMN "users"."deactivated_at" IS 'NULL while the account is active';
@@ -0,0 +1,5 @@
+DROP INDEX IF EXISTS idx_users_expires_at;
+
+ALTER TABLE "users" DROP COLUMN IF EXISTS "expires_at";
+
+COMMENT ON COLUMN "users"."role" IS 'user or admin';
@@ -0,0 +1,7 @@
+ALTER TABLE "users" ADD COLUMN "expires_at" timestamptz;
+
+COMMENT ON COLUMN "users"."role" IS 'user, admin or guest';
+
+COMMENT ON COLUMN "users"."expires_at" IS 'Guests only: the account and its data are deleted after this time';
+
+CREATE INDEX idx_users_expires_at ON users (expires_at);
@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS "support_tickets";
+
+COMMENT ON COLUMN "users"."role" IS 'user, admin or guest';
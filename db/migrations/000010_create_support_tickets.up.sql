@@ -0,0 +1,29 @@
+CREATE TABLE "support_tickets" (
+  "id" bigserial PRIMARY KEY,
+  "support_user_id" int NOT NULL,
+  "customer_id" int NOT NULL,
+  "agent_id" int,
+  "status" varchar(20) NOT NULL DEFAULT 'open',
+  "created_at" timestamptz NOT NULL DEFAULT (now()),
+  "claimed_at" timestamptz,
+  "closed_at" timestamptz
+);
+
+COMMENT ON COLUMN "support_tickets"."support_user_id" IS 'The support identity the customer wrote to';
+
+COMMENT ON COLUMN "support_tickets"."agent_id" IS 'NULL until an agent claims the ticket';
+
+COMMENT ON COLUMN "support_tickets"."status" IS 'open, claimed or closed';
+
+COMMENT ON COLUMN "users"."role" IS 'user, admin, guest, agent or support';
+
+ALTER TABLE "support_tickets" ADD FOREIGN KEY ("support_user_id") REFERENCES "users" ("id");
+
+ALTER TABLE "support_tickets" ADD FOREIGN KEY ("customer_id") REFERENCES "users" ("id");
+
+ALTER TABLE "support_tickets" ADD FOREIGN KEY ("agent_id") REFERENCES "users" ("id");
+
+-- A customer has at most one active ticket per support identity
+CREATE UNIQUE INDEX idx_support_tickets_active ON support_tickets (support_user_id, customer_id) WHERE status <> 'closed';
+
+CREATE INDEX idx_support_tickets_status ON support_tickets (status);
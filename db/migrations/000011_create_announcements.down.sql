@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS "announcement_receipts";
+
+DROP TABLE IF EXISTS "announcements";
@@ -0,0 +1,21 @@
+CREATE TABLE "announcements" (
+  "id" bigserial PRIMARY KEY,
+  "author_id" int NOT NULL,
+  "content" text NOT NULL,
+  "created_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+CREATE TABLE "announcement_receipts" (
+  "announcement_id" bigint NOT NULL,
+  "user_id" int NOT NULL,
+  "seen_at" timestamptz NOT NULL DEFAULT (now()),
+  PRIMARY KEY ("announcement_id", "user_id")
+);
+
+COMMENT ON TABLE "announcement_receipts" IS 'One row per user that acknowledged an announcement';
+
+ALTER TABLE "announcements" ADD FOREIGN KEY ("author_id") REFERENCES "users" ("id");
+
+ALTER TABLE "announcement_receipts" ADD FOREIGN KEY ("announcement_id") REFERENCES "announcements" ("id") ON DELETE CASCADE;
+
+ALTER TABLE "announcement_receipts" ADD FOREIGN KEY ("user_id") REFERENCES "users" ("id");
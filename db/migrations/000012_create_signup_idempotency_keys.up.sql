@@ -0,0 +1,10 @@
+CREATE TABLE "signup_idempotency_keys" (
+  "key" varchar(255) PRIMARY KEY,
+  "user_id" int NOT NULL,
+  "username" varchar(50) NOT NULL,
+  "created_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+COMMENT ON COLUMN "signup_idempotency_keys"."key" IS 'Idempotency-Key header sent with POST /users';
+
+ALTER TABLE "signup_idempotency_keys" ADD FOREIGN KEY ("user_id") REFERENCES "users" ("id") ON DELETE CASCADE;
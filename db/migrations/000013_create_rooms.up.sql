@@ -0,0 +1,37 @@
+CREATE TABLE "rooms" (
+  "id" bigserial PRIMARY KEY,
+  "name" varchar(100) NOT NULL,
+  "created_by" int NOT NULL,
+  "created_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+CREATE TABLE "room_members" (
+  "room_id" bigint NOT NULL,
+  "user_id" int NOT NULL,
+  "joined_at" timestamptz NOT NULL DEFAULT (now()),
+  PRIMARY KEY ("room_id", "user_id")
+);
+
+CREATE TABLE "room_messages" (
+  "id" bigserial PRIMARY KEY,
+  "room_id" bigint NOT NULL,
+  "sender_id" int NOT NULL,
+  "content" text NOT NULL,
+  "created_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+COMMENT ON TABLE "room_members" IS 'Users currently in a room; leaving deletes the row';
+
+ALTER TABLE "rooms" ADD FOREIGN KEY ("created_by") REFERENCES "users" ("id");
+
+ALTER TABLE "room_members" ADD FOREIGN KEY ("room_id") REFERENCES "rooms" ("id") ON DELETE CASCADE;
+
+ALTER TABLE "room_members" ADD FOREIGN KEY ("user_id") REFERENCES "users" ("id");
+
+ALTER TABLE "room_messages" ADD FOREIGN KEY ("room_id") REFERENCES "rooms" ("id") ON DELETE CASCADE;
+
+ALTER TABLE "room_messages" ADD FOREIGN KEY ("sender_id") REFERENCES "users" ("id");
+
+CREATE INDEX idx_room_members_user_id ON room_members (user_id);
+
+CREATE INDEX idx_room_messages_room_id ON room_messages (room_id, id);
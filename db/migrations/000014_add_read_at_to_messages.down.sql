@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_messages_unread;
+
+ALTER TABLE "messages" DROP COLUMN IF EXISTS "read_at";
@@ -0,0 +1,5 @@
+ALTER TABLE "messages" ADD COLUMN "read_at" timestamptz;
+
+COMMENT ON COLUMN "messages"."read_at" IS 'When the receiver read the message, NULL while unread';
+
+CREATE INDEX idx_messages_unread ON messages (receiver_id, sender_id) WHERE read_at IS NULL;
@@ -0,0 +1,14 @@
+CREATE TABLE "api_keys" (
+  "id" bigserial PRIMARY KEY,
+  "user_id" int NOT NULL,
+  "name" varchar(100) NOT NULL,
+  "key_hash" varchar(64) UNIQUE NOT NULL,
+  "created_at" timestamptz NOT NULL DEFAULT (now()),
+  "revoked_at" timestamptz
+);
+
+COMMENT ON COLUMN "api_keys"."user_id" IS 'The account the key acts as';
+
+COMMENT ON COLUMN "api_keys"."key_hash" IS 'Hex SHA-256 of the key; the key itself is only shown once';
+
+ALTER TABLE "api_keys" ADD FOREIGN KEY ("user_id") REFERENCES "users" ("id");
@@ -0,0 +1,3 @@
+ALTER TABLE "users" ADD COLUMN "last_seen_at" timestamptz;
+
+COMMENT ON COLUMN "users"."last_seen_at" IS 'When the user last came online or went offline, NULL if never connected';
@@ -0,0 +1,4 @@
+-- Hashes cannot be turned back into passwords: after a rollback every account needs a new password
+ALTER TABLE "users" RENAME COLUMN "password_hash" TO "password_plaintext";
+
+COMMENT ON COLUMN "users"."password_plaintext" IS 'Practice only!';
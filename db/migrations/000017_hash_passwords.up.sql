@@ -0,0 +1,8 @@
+-- pgcrypto's bcrypt ("bf") hashes are verified by golang.org/x/crypto/bcrypt
+CREATE EXTENSION IF NOT EXISTS pgcrypto;
+
+ALTER TABLE "users" RENAME COLUMN "password_plaintext" TO "password_hash";
+
+UPDATE "users" SET "password_hash" = crypt("password_hash", gen_salt('bf', 10));
+
+COMMENT ON COLUMN "users"."password_hash" IS 'bcrypt hash of the password';
@@ -0,0 +1,19 @@
+CREATE TABLE "sessions" (
+  "id" uuid PRIMARY KEY,
+  "user_id" int NOT NULL,
+  "user_agent" text NOT NULL,
+  "client_ip" varchar(45) NOT NULL,
+  "expires_at" timestamptz NOT NULL,
+  "created_at" timestamptz NOT NULL DEFAULT (now()),
+  "revoked_at" timestamptz
+);
+
+COMMENT ON TABLE "sessions" IS 'One row per refresh token; the ID is the refresh token''s payload ID';
+
+COMMENT ON COLUMN "sessions"."revoked_at" IS 'Set when the refresh token was used (rotated) or revoked';
+
+ALTER TABLE "sessions" ADD FOREIGN KEY ("user_id") REFERENCES "users" ("id");
+
+CREATE INDEX idx_sessions_user_id ON sessions (user_id);
+
+CREATE INDEX idx_sessions_expires_at ON sessions (expires_at);
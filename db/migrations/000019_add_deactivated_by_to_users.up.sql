@@ -0,0 +1,3 @@
+ALTER TABLE "users" ADD COLUMN "deactivated_by" integer REFERENCES "users" ("id") ON DELETE SET NULL;
+
+COMMENT ON COLUMN "users"."deactivated_by" IS 'Who deactivated the account: the user themselves, an admin, or NULL for the identity provider';
@@ -0,0 +1,3 @@
+-- Usernames are unique regardless of case. Fails if existing accounts differ only by case:
+-- rename them before migrating.
+CREATE UNIQUE INDEX "users_username_lower_idx" ON "users" (lower("username"));
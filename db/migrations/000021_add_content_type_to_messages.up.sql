@@ -0,0 +1,4 @@
+-- Validated by the application's content type registry, so new kinds of messages need no migration
+ALTER TABLE "messages" ADD COLUMN "content_type" varchar(20) NOT NULL DEFAULT 'text';
+
+COMMENT ON COLUMN "messages"."content_type" IS 'How to interpret content: text, markdown, attachment, system, poll, location, encrypted or contact_card';
@@ -0,0 +1,4 @@
+-- Messages deleted for everyone are kept but no longer returned by the API
+ALTER TABLE "messages" ADD COLUMN "deleted_at" timestamptz;
+
+COMMENT ON COLUMN "messages"."deleted_at" IS 'When the sender deleted the message for everyone, NULL otherwise';
@@ -0,0 +1,10 @@
+CREATE TABLE "quarantined_users" (
+  "user_id" int PRIMARY KEY,
+  "reason" text NOT NULL,
+  "ip_address" varchar(45) NOT NULL,
+  "created_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+COMMENT ON TABLE "quarantined_users" IS 'Accounts created or first used from a suspicious IP: limited sends until an admin verifies them';
+
+ALTER TABLE "quarantined_users" ADD FOREIGN KEY ("user_id") REFERENCES "users" ("id") ON DELETE CASCADE;
@@ -0,0 +1,12 @@
+-- Rooms are administered by their owner, initially the creator
+ALTER TABLE "rooms" ADD COLUMN "owner_id" int;
+
+UPDATE "rooms" SET "owner_id" = "created_by";
+
+ALTER TABLE "rooms" ALTER COLUMN "owner_id" SET NOT NULL;
+
+ALTER TABLE "rooms" ADD FOREIGN KEY ("owner_id") REFERENCES "users" ("id");
+
+COMMENT ON COLUMN "rooms"."owner_id" IS 'The user who administers the room, passed on when they leave or are deactivated';
+
+CREATE INDEX idx_rooms_owner_id ON rooms (owner_id);
@@ -0,0 +1,23 @@
+CREATE TABLE "share_links" (
+  "id" bigserial PRIMARY KEY,
+  "user_id" int NOT NULL,
+  "partner_id" int NOT NULL,
+  "first_message_id" bigint NOT NULL,
+  "last_message_id" bigint NOT NULL,
+  "token_hash" varchar(64) UNIQUE NOT NULL,
+  "created_at" timestamptz NOT NULL DEFAULT (now()),
+  "expires_at" timestamptz NOT NULL,
+  "revoked_at" timestamptz
+);
+
+COMMENT ON TABLE "share_links" IS 'Public read-only links to a message or a range of messages of a conversation';
+
+COMMENT ON COLUMN "share_links"."user_id" IS 'The user who shared the messages; the link shows what they can see';
+
+COMMENT ON COLUMN "share_links"."token_hash" IS 'Hex SHA-256 of the link token; the token itself is only shown once';
+
+CREATE INDEX ON "share_links" ("user_id");
+
+ALTER TABLE "share_links" ADD FOREIGN KEY ("user_id") REFERENCES "users" ("id") ON DELETE CASCADE;
+
+ALTER TABLE "share_links" ADD FOREIGN KEY ("partner_id") REFERENCES "users" ("id") ON DELETE CASCADE;
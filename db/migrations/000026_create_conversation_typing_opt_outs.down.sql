@@ -0,0 +1 @@
+DROP TABLE IF EXISTS "conversation_typing_opt_outs";
@@ -0,0 +1,12 @@
+CREATE TABLE "conversation_typing_opt_outs" (
+  "user_id" int NOT NULL,
+  "partner_id" int NOT NULL,
+  "created_at" timestamptz NOT NULL DEFAULT (now()),
+  PRIMARY KEY ("user_id", "partner_id")
+);
+
+COMMENT ON TABLE "conversation_typing_opt_outs" IS 'Conversations in which the user does not send typing indicators to the partner';
+
+ALTER TABLE "conversation_typing_opt_outs" ADD FOREIGN KEY ("user_id") REFERENCES "users" ("id");
+
+ALTER TABLE "conversation_typing_opt_outs" ADD FOREIGN KEY ("partner_id") REFERENCES "users" ("id");
@@ -0,0 +1,5 @@
+ALTER TABLE "messages" ADD COLUMN "reply_to_message_id" bigint;
+
+COMMENT ON COLUMN "messages"."reply_to_message_id" IS 'The message of the same conversation this one replies to, NULL otherwise';
+
+ALTER TABLE "messages" ADD FOREIGN KEY ("reply_to_message_id") REFERENCES "messages" ("id") ON DELETE SET NULL;
@@ -0,0 +1,16 @@
+CREATE TABLE "device_tokens" (
+  "id" bigserial PRIMARY KEY,
+  "user_id" int NOT NULL,
+  "platform" varchar(10) NOT NULL,
+  "token" varchar(512) UNIQUE NOT NULL,
+  "created_at" timestamptz NOT NULL DEFAULT (now()),
+  "updated_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+COMMENT ON TABLE "device_tokens" IS 'Push notification tokens of the devices users are logged in on';
+
+COMMENT ON COLUMN "device_tokens"."platform" IS 'Push service of the token: fcm or apns';
+
+CREATE INDEX ON "device_tokens" ("user_id");
+
+ALTER TABLE "device_tokens" ADD FOREIGN KEY ("user_id") REFERENCES "users" ("id") ON DELETE CASCADE;
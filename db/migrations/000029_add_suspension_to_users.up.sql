@@ -0,0 +1,7 @@
+ALTER TABLE "users" ADD COLUMN "suspended_until" timestamptz;
+
+ALTER TABLE "users" ADD COLUMN "suspension_reason" varchar(500) NOT NULL DEFAULT '';
+
+COMMENT ON COLUMN "users"."suspended_until" IS 'The account cannot log in or connect before this time, NULL if never suspended';
+
+COMMENT ON COLUMN "users"."suspension_reason" IS 'Why an admin suspended the account, shown to the user';
@@ -0,0 +1,9 @@
+DROP INDEX IF EXISTS idx_login_history_created_at;
+
+DROP INDEX IF EXISTS idx_room_messages_created_at;
+
+DROP INDEX IF EXISTS idx_messages_created_at;
+
+DROP TABLE IF EXISTS "usage_monthly";
+
+DROP TABLE IF EXISTS "usage_daily";
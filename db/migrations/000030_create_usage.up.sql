@@ -0,0 +1,33 @@
+CREATE TABLE "usage_daily" (
+  "day" date PRIMARY KEY,
+  "active_users" int NOT NULL,
+  "messages" bigint NOT NULL,
+  "storage_bytes" bigint NOT NULL,
+  "recorded_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+CREATE TABLE "usage_monthly" (
+  "month" date PRIMARY KEY,
+  "active_users" int NOT NULL,
+  "messages" bigint NOT NULL,
+  "peak_storage_bytes" bigint NOT NULL,
+  "rolled_up_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+COMMENT ON TABLE "usage_daily" IS 'Usage per UTC day, recorded again until the day is over';
+
+COMMENT ON COLUMN "usage_daily"."active_users" IS 'Users who logged in or sent a message that day';
+
+COMMENT ON COLUMN "usage_daily"."storage_bytes" IS 'Size of the stored message contents when the day was last recorded';
+
+COMMENT ON TABLE "usage_monthly" IS 'Usage per UTC month, rolled up from usage_daily';
+
+COMMENT ON COLUMN "usage_monthly"."month" IS 'First day of the month';
+
+COMMENT ON COLUMN "usage_monthly"."active_users" IS 'Distinct users who logged in or sent a message that month';
+
+CREATE INDEX idx_messages_created_at ON messages (created_at);
+
+CREATE INDEX idx_room_messages_created_at ON room_messages (created_at);
+
+CREATE INDEX idx_login_history_created_at ON login_history (created_at);
@@ -0,0 +1 @@
+COMMENT ON COLUMN "users"."last_seen_at" IS 'When the user last came online or went offline, NULL if never connected';
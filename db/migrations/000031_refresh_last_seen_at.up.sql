@@ -0,0 +1 @@
+COMMENT ON COLUMN "users"."last_seen_at" IS 'When the user was last connected (refreshed while online), NULL if never connected';
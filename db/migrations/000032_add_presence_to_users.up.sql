@@ -0,0 +1,3 @@
+ALTER TABLE "users" ADD COLUMN "presence" varchar(10) NOT NULL DEFAULT 'available';
+
+COMMENT ON COLUMN "users"."presence" IS 'Presence chosen by the user: available, away, busy, dnd or invisible';
@@ -0,0 +1,16 @@
+CREATE TABLE "deferred_deliveries" (
+  "message_id" bigint PRIMARY KEY,
+  "sender_id" int NOT NULL,
+  "recipient_id" int NOT NULL,
+  "created_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+COMMENT ON TABLE "deferred_deliveries" IS 'Private messages that reached neither a connection nor a device of the recipient; the sender is told when the recipient connects';
+
+CREATE INDEX ON "deferred_deliveries" ("recipient_id");
+
+ALTER TABLE "deferred_deliveries" ADD FOREIGN KEY ("message_id") REFERENCES "messages" ("id") ON DELETE CASCADE;
+
+ALTER TABLE "deferred_deliveries" ADD FOREIGN KEY ("sender_id") REFERENCES "users" ("id") ON DELETE CASCADE;
+
+ALTER TABLE "deferred_deliveries" ADD FOREIGN KEY ("recipient_id") REFERENCES "users" ("id") ON DELETE CASCADE;
@@ -0,0 +1,6 @@
+ALTER TABLE "messages" ADD COLUMN "delivered_at" timestamptz;
+
+-- Messages read before delivery was tracked were delivered too
+UPDATE "messages" SET "delivered_at" = "read_at" WHERE "read_at" IS NOT NULL;
+
+COMMENT ON COLUMN "messages"."delivered_at" IS 'When a connection of the receiver first accepted the message, NULL until then';
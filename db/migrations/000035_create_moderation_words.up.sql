@@ -0,0 +1,18 @@
+CREATE TABLE "moderation_words" (
+  "id" bigserial PRIMARY KEY,
+  "pattern" varchar(200) NOT NULL,
+  "is_regex" boolean NOT NULL DEFAULT false,
+  "severity" varchar(10) NOT NULL,
+  "created_by" int,
+  "created_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+COMMENT ON TABLE "moderation_words" IS 'Words and regular expressions the moderation filter masks (low) or rejects (high) in messages';
+
+COMMENT ON COLUMN "moderation_words"."is_regex" IS 'The pattern is a regular expression (RE2 syntax) instead of a word';
+
+COMMENT ON COLUMN "moderation_words"."created_by" IS 'The admin who added the entry, NULL once deleted';
+
+CREATE UNIQUE INDEX ON "moderation_words" ("pattern", "is_regex");
+
+ALTER TABLE "moderation_words" ADD FOREIGN KEY ("created_by") REFERENCES "users" ("id") ON DELETE SET NULL;
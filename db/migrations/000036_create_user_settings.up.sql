@@ -0,0 +1,17 @@
+CREATE TABLE "user_settings" (
+  "user_id" int PRIMARY KEY,
+  "read_receipts" boolean NOT NULL DEFAULT true,
+  "typing_indicators" boolean NOT NULL DEFAULT true,
+  "online_visibility" boolean NOT NULL DEFAULT true,
+  "updated_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+COMMENT ON TABLE "user_settings" IS 'Privacy settings of users who changed them; users without a row use the defaults';
+
+COMMENT ON COLUMN "user_settings"."read_receipts" IS 'Senders learn when the user read their messages';
+
+COMMENT ON COLUMN "user_settings"."typing_indicators" IS 'Partners and rooms see the user typing';
+
+COMMENT ON COLUMN "user_settings"."online_visibility" IS 'Others see the user online and their last-seen time refreshed, as with presence other than invisible';
+
+ALTER TABLE "user_settings" ADD FOREIGN KEY ("user_id") REFERENCES "users" ("id") ON DELETE CASCADE;
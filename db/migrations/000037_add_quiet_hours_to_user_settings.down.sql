@@ -0,0 +1,5 @@
+ALTER TABLE "user_settings" DROP COLUMN "quiet_hours_timezone";
+
+ALTER TABLE "user_settings" DROP COLUMN "quiet_hours_end";
+
+ALTER TABLE "user_settings" DROP COLUMN "quiet_hours_start";
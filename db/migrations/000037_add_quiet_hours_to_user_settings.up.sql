@@ -0,0 +1,11 @@
+ALTER TABLE "user_settings" ADD COLUMN "quiet_hours_start" varchar(5);
+
+ALTER TABLE "user_settings" ADD COLUMN "quiet_hours_end" varchar(5);
+
+ALTER TABLE "user_settings" ADD COLUMN "quiet_hours_timezone" varchar(64);
+
+COMMENT ON COLUMN "user_settings"."quiet_hours_start" IS 'Local time (HH:MM) quiet hours start at, NULL without quiet hours';
+
+COMMENT ON COLUMN "user_settings"."quiet_hours_end" IS 'Local time (HH:MM) quiet hours end at, before the start for quiet hours over midnight';
+
+COMMENT ON COLUMN "user_settings"."quiet_hours_timezone" IS 'IANA time zone of the quiet hours, e.g. Europe/Berlin';
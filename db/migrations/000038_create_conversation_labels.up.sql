@@ -0,0 +1,15 @@
+CREATE TABLE "conversation_labels" (
+  "user_id" int NOT NULL,
+  "partner_id" int NOT NULL,
+  "label" varchar(50) NOT NULL,
+  "created_at" timestamptz NOT NULL DEFAULT (now()),
+  PRIMARY KEY ("user_id", "partner_id", "label")
+);
+
+COMMENT ON TABLE "conversation_labels" IS 'Labels (e.g. work, family) a user put on their conversation with a partner';
+
+CREATE INDEX ON "conversation_labels" ("user_id", "label");
+
+ALTER TABLE "conversation_labels" ADD FOREIGN KEY ("user_id") REFERENCES "users" ("id") ON DELETE CASCADE;
+
+ALTER TABLE "conversation_labels" ADD FOREIGN KEY ("partner_id") REFERENCES "users" ("id") ON DELETE CASCADE;
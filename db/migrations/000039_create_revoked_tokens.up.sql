@@ -0,0 +1,14 @@
+CREATE TABLE "revoked_tokens" (
+  "id" uuid PRIMARY KEY,
+  "user_id" int NOT NULL,
+  "expires_at" timestamptz NOT NULL,
+  "revoked_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+COMMENT ON TABLE "revoked_tokens" IS 'Access tokens revoked before they expire, e.g. by logging out; the ID is the token''s payload ID';
+
+COMMENT ON COLUMN "revoked_tokens"."expires_at" IS 'When the token expires anyway; the row can be deleted from then on';
+
+ALTER TABLE "revoked_tokens" ADD FOREIGN KEY ("user_id") REFERENCES "users" ("id") ON DELETE CASCADE;
+
+CREATE INDEX idx_revoked_tokens_expires_at ON revoked_tokens (expires_at);
@@ -0,0 +1,30 @@
+CREATE TABLE "legal_holds" (
+  "id" bigserial PRIMARY KEY,
+  "user_id" int NOT NULL,
+  "partner_id" int,
+  "reason" text NOT NULL,
+  "created_by" int NOT NULL,
+  "created_at" timestamptz NOT NULL DEFAULT (now()),
+  "released_by" int,
+  "released_at" timestamptz,
+  CHECK ("partner_id" IS NULL OR "user_id" < "partner_id")
+);
+
+COMMENT ON TABLE "legal_holds" IS 'Users or conversations whose history must be preserved, e.g. for litigation; released holds are kept as a record';
+
+COMMENT ON COLUMN "legal_holds"."partner_id" IS 'Set for a hold on the conversation of user_id and partner_id (user_id < partner_id), NULL for a hold on all of user_id''s history';
+
+COMMENT ON COLUMN "legal_holds"."released_at" IS 'When an admin released the hold, NULL while it is active';
+
+ALTER TABLE "legal_holds" ADD FOREIGN KEY ("user_id") REFERENCES "users" ("id");
+
+ALTER TABLE "legal_holds" ADD FOREIGN KEY ("partner_id") REFERENCES "users" ("id");
+
+ALTER TABLE "legal_holds" ADD FOREIGN KEY ("created_by") REFERENCES "users" ("id");
+
+ALTER TABLE "legal_holds" ADD FOREIGN KEY ("released_by") REFERENCES "users" ("id");
+
+-- One active hold per user or conversation
+CREATE UNIQUE INDEX idx_legal_holds_active ON legal_holds (user_id, COALESCE(partner_id, 0)) WHERE released_at IS NULL;
+
+CREATE INDEX idx_legal_holds_partner_id ON legal_holds (partner_id) WHERE released_at IS NULL;
@@ -0,0 +1,34 @@
+CREATE TABLE "webhooks" (
+  "id" bigserial PRIMARY KEY,
+  "name" varchar(100) NOT NULL,
+  "user_id" int NOT NULL,
+  "room_id" bigint,
+  "recipient_id" int,
+  "token_hash" varchar(64) UNIQUE NOT NULL,
+  "rate_limit" int NOT NULL,
+  "created_by" int NOT NULL,
+  "created_at" timestamptz NOT NULL DEFAULT (now()),
+  "last_used_at" timestamptz,
+  "revoked_at" timestamptz,
+  CHECK (("room_id" IS NULL) <> ("recipient_id" IS NULL))
+);
+
+COMMENT ON TABLE "webhooks" IS 'Incoming webhooks: external services post messages into one room or conversation through POST /hooks/:hook_token';
+
+COMMENT ON COLUMN "webhooks"."user_id" IS 'The (bot) account messages are posted as';
+
+COMMENT ON COLUMN "webhooks"."room_id" IS 'The room messages are posted to, NULL for a conversation';
+
+COMMENT ON COLUMN "webhooks"."recipient_id" IS 'The user messages are sent to, NULL for a room';
+
+COMMENT ON COLUMN "webhooks"."token_hash" IS 'Hex SHA-256 of the hook token; the token itself is only shown once';
+
+COMMENT ON COLUMN "webhooks"."rate_limit" IS 'Messages per minute';
+
+ALTER TABLE "webhooks" ADD FOREIGN KEY ("user_id") REFERENCES "users" ("id");
+
+ALTER TABLE "webhooks" ADD FOREIGN KEY ("room_id") REFERENCES "rooms" ("id") ON DELETE CASCADE;
+
+ALTER TABLE "webhooks" ADD FOREIGN KEY ("recipient_id") REFERENCES "users" ("id");
+
+ALTER TABLE "webhooks" ADD FOREIGN KEY ("created_by") REFERENCES "users" ("id");
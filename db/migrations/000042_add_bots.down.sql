@@ -0,0 +1,3 @@
+COMMENT ON COLUMN "users"."role" IS 'user, admin, guest, agent or support';
+
+ALTER TABLE "api_keys" DROP COLUMN IF EXISTS "scopes";
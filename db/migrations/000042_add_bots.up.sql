@@ -0,0 +1,5 @@
+ALTER TABLE "api_keys" ADD COLUMN "scopes" varchar(20)[] NOT NULL DEFAULT '{send,read}';
+
+COMMENT ON COLUMN "api_keys"."scopes" IS 'What the key may do: send and/or read';
+
+COMMENT ON COLUMN "users"."role" IS 'user, admin, guest, agent, support or bot';
@@ -0,0 +1,21 @@
+DROP TRIGGER IF EXISTS conversation_summaries_conversation_cleared ON conversation_clears;
+
+DROP TRIGGER IF EXISTS conversation_summaries_messages_deleted ON messages;
+
+DROP TRIGGER IF EXISTS conversation_summaries_messages_updated ON messages;
+
+DROP TRIGGER IF EXISTS conversation_summaries_messages_inserted ON messages;
+
+DROP FUNCTION IF EXISTS conversation_summaries_conversation_cleared();
+
+DROP FUNCTION IF EXISTS conversation_summaries_messages_deleted();
+
+DROP FUNCTION IF EXISTS conversation_summaries_messages_updated();
+
+DROP FUNCTION IF EXISTS conversation_summaries_messages_inserted();
+
+DROP FUNCTION IF EXISTS refresh_conversation_summary(int, int);
+
+DROP INDEX IF EXISTS idx_messages_conversation;
+
+DROP TABLE IF EXISTS "conversation_summaries";
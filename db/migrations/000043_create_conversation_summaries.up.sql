@@ -0,0 +1,187 @@
+CREATE TABLE "conversation_summaries" (
+  "user_id" int NOT NULL,
+  "partner_id" int NOT NULL,
+  "last_message_id" bigint NOT NULL,
+  "last_message_sender_id" int NOT NULL,
+  "last_message_content" text NOT NULL,
+  "last_message_content_type" varchar(20) NOT NULL,
+  "last_message_at" timestamptz NOT NULL,
+  "unread_count" int NOT NULL DEFAULT 0,
+  PRIMARY KEY ("user_id", "partner_id")
+);
+
+COMMENT ON TABLE "conversation_summaries" IS 'The conversation list of each user, maintained by triggers on messages and conversation_clears in the transaction of every write. Derived data: not part of backups.';
+
+COMMENT ON COLUMN "conversation_summaries"."last_message_id" IS 'The newest message of the conversation user_id can see (not deleted, not cleared)';
+
+COMMENT ON COLUMN "conversation_summaries"."unread_count" IS 'Messages from partner_id that user_id has not read';
+
+-- GET /conversations pages by last message, newest first
+CREATE INDEX idx_conversation_summaries_user_last_message ON conversation_summaries (user_id, last_message_id);
+
+-- The latest messages of a conversation, in either direction
+CREATE INDEX idx_messages_conversation ON messages (LEAST(sender_id, receiver_id), GREATEST(sender_id, receiver_id), id);
+
+ALTER TABLE "conversation_summaries" ADD FOREIGN KEY ("user_id") REFERENCES "users" ("id") ON DELETE CASCADE;
+
+ALTER TABLE "conversation_summaries" ADD FOREIGN KEY ("partner_id") REFERENCES "users" ("id") ON DELETE CASCADE;
+
+-- Writes to the summaries of a conversation take a transaction-level advisory lock on it (keyed
+-- by its two user IDs, lowest first), so a recompute cannot miss a message inserted concurrently
+
+-- Recomputes the summary user_id has of the conversation with partner_id, or deletes it when no
+-- message of the conversation is visible to user_id anymore
+CREATE FUNCTION refresh_conversation_summary(summary_user_id int, summary_partner_id int) RETURNS void
+LANGUAGE plpgsql AS $$
+DECLARE
+  cleared_before bigint;
+  latest messages%ROWTYPE;
+BEGIN
+  PERFORM pg_advisory_xact_lock(LEAST(summary_user_id, summary_partner_id), GREATEST(summary_user_id, summary_partner_id));
+
+  SELECT COALESCE(MAX(cleared_before_id), 0) INTO cleared_before
+  FROM conversation_clears
+  WHERE user_id = summary_user_id AND partner_id = summary_partner_id;
+
+  SELECT * INTO latest FROM messages
+  WHERE LEAST(sender_id, receiver_id) = LEAST(summary_user_id, summary_partner_id)
+    AND GREATEST(sender_id, receiver_id) = GREATEST(summary_user_id, summary_partner_id)
+    AND id > cleared_before
+    AND deleted_at IS NULL
+  ORDER BY LEAST(sender_id, receiver_id), GREATEST(sender_id, receiver_id), id DESC
+  LIMIT 1;
+
+  IF NOT FOUND THEN
+    DELETE FROM conversation_summaries
+    WHERE user_id = summary_user_id AND partner_id = summary_partner_id;
+    RETURN;
+  END IF;
+
+  INSERT INTO conversation_summaries (
+    user_id, partner_id, last_message_id, last_message_sender_id, last_message_content,
+    last_message_content_type, last_message_at, unread_count
+  ) VALUES (
+    summary_user_id, summary_partner_id, latest.id, latest.sender_id, latest.content,
+    latest.content_type, latest.created_at,
+    (
+      SELECT COUNT(*) FROM messages
+      WHERE sender_id = summary_partner_id AND receiver_id = summary_user_id
+        AND read_at IS NULL AND deleted_at IS NULL AND id > cleared_before
+    )
+  )
+  ON CONFLICT (user_id, partner_id) DO UPDATE SET
+    last_message_id = EXCLUDED.last_message_id,
+    last_message_sender_id = EXCLUDED.last_message_sender_id,
+    last_message_content = EXCLUDED.last_message_content,
+    last_message_content_type = EXCLUDED.last_message_content_type,
+    last_message_at = EXCLUDED.last_message_at,
+    unread_count = EXCLUDED.unread_count;
+END;
+$$;
+
+-- New messages move both participants' summaries forward and add to the receiver's unread count,
+-- without reading the conversation again
+CREATE FUNCTION conversation_summaries_messages_inserted() RETURNS trigger
+LANGUAGE plpgsql AS $$
+BEGIN
+  PERFORM pg_advisory_xact_lock(c.low_id, c.high_id)
+  FROM (
+    SELECT DISTINCT LEAST(sender_id, receiver_id) AS low_id, GREATEST(sender_id, receiver_id) AS high_id
+    FROM inserted_messages
+    ORDER BY low_id, high_id
+  ) c;
+
+  INSERT INTO conversation_summaries AS cs (
+    user_id, partner_id, last_message_id, last_message_sender_id, last_message_content,
+    last_message_content_type, last_message_at, unread_count
+  )
+  SELECT DISTINCT ON (v.user_id, v.partner_id)
+    v.user_id, v.partner_id, m.id, m.sender_id, m.content, m.content_type, m.created_at,
+    COUNT(*) FILTER (WHERE m.receiver_id = v.user_id AND m.read_at IS NULL) OVER (PARTITION BY v.user_id, v.partner_id)
+  FROM inserted_messages m
+  CROSS JOIN LATERAL (VALUES (m.sender_id, m.receiver_id), (m.receiver_id, m.sender_id)) AS v (user_id, partner_id)
+  LEFT JOIN conversation_clears cc ON cc.user_id = v.user_id AND cc.partner_id = v.partner_id
+  WHERE m.deleted_at IS NULL
+    -- Restored backups may insert messages a user cleared before
+    AND m.id > COALESCE(cc.cleared_before_id, 0)
+  ORDER BY v.user_id, v.partner_id, m.id DESC
+  ON CONFLICT (user_id, partner_id) DO UPDATE SET
+    last_message_id = GREATEST(cs.last_message_id, EXCLUDED.last_message_id),
+    last_message_sender_id = CASE WHEN EXCLUDED.last_message_id > cs.last_message_id THEN EXCLUDED.last_message_sender_id ELSE cs.last_message_sender_id END,
+    last_message_content = CASE WHEN EXCLUDED.last_message_id > cs.last_message_id THEN EXCLUDED.last_message_content ELSE cs.last_message_content END,
+    last_message_content_type = CASE WHEN EXCLUDED.last_message_id > cs.last_message_id THEN EXCLUDED.last_message_content_type ELSE cs.last_message_content_type END,
+    last_message_at = CASE WHEN EXCLUDED.last_message_id > cs.last_message_id THEN EXCLUDED.last_message_at ELSE cs.last_message_at END,
+    unread_count = cs.unread_count + EXCLUDED.unread_count;
+  RETURN NULL;
+END;
+$$;
+
+-- Reads, deletions and edits recompute the conversations they touch. Delivery receipts and other
+-- updates leave the summaries alone.
+CREATE FUNCTION conversation_summaries_messages_updated() RETURNS trigger
+LANGUAGE plpgsql AS $$
+BEGIN
+  PERFORM refresh_conversation_summary(p.user_id, p.partner_id)
+  FROM (
+    SELECT DISTINCT v.user_id, v.partner_id
+    FROM new_messages n
+    JOIN old_messages o ON o.id = n.id
+    CROSS JOIN LATERAL (VALUES (n.sender_id, n.receiver_id), (n.receiver_id, n.sender_id)) AS v (user_id, partner_id)
+    WHERE n.read_at IS DISTINCT FROM o.read_at
+      OR n.deleted_at IS DISTINCT FROM o.deleted_at
+      OR n.content IS DISTINCT FROM o.content
+      OR n.content_type IS DISTINCT FROM o.content_type
+    ORDER BY v.user_id, v.partner_id
+  ) p;
+  RETURN NULL;
+END;
+$$;
+
+CREATE FUNCTION conversation_summaries_messages_deleted() RETURNS trigger
+LANGUAGE plpgsql AS $$
+BEGIN
+  PERFORM refresh_conversation_summary(p.user_id, p.partner_id)
+  FROM (
+    SELECT DISTINCT v.user_id, v.partner_id
+    FROM old_messages o
+    CROSS JOIN LATERAL (VALUES (o.sender_id, o.receiver_id), (o.receiver_id, o.sender_id)) AS v (user_id, partner_id)
+    ORDER BY v.user_id, v.partner_id
+  ) p;
+  RETURN NULL;
+END;
+$$;
+
+CREATE FUNCTION conversation_summaries_conversation_cleared() RETURNS trigger
+LANGUAGE plpgsql AS $$
+BEGIN
+  PERFORM refresh_conversation_summary(NEW.user_id, NEW.partner_id);
+  RETURN NULL;
+END;
+$$;
+
+CREATE TRIGGER conversation_summaries_messages_inserted
+AFTER INSERT ON messages
+REFERENCING NEW TABLE AS inserted_messages
+FOR EACH STATEMENT EXECUTE FUNCTION conversation_summaries_messages_inserted();
+
+CREATE TRIGGER conversation_summaries_messages_updated
+AFTER UPDATE ON messages
+REFERENCING OLD TABLE AS old_messages NEW TABLE AS new_messages
+FOR EACH STATEMENT EXECUTE FUNCTION conversation_summaries_messages_updated();
+
+CREATE TRIGGER conversation_summaries_messages_deleted
+AFTER DELETE ON messages
+REFERENCING OLD TABLE AS old_messages
+FOR EACH STATEMENT EXECUTE FUNCTION conversation_summaries_messages_deleted();
+
+CREATE TRIGGER conversation_summaries_conversation_cleared
+AFTER INSERT OR UPDATE ON conversation_clears
+FOR EACH ROW EXECUTE FUNCTION conversation_summaries_conversation_cleared();
+
+-- Summaries of the existing conversations
+SELECT refresh_conversation_summary(pairs.user_id, pairs.partner_id)
+FROM (
+  SELECT sender_id AS user_id, receiver_id AS partner_id FROM messages
+  UNION
+  SELECT receiver_id, sender_id FROM messages
+) pairs;
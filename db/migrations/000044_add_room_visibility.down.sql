@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS "room_invites";
+
+ALTER TABLE "rooms" DROP COLUMN IF EXISTS "is_public";
@@ -0,0 +1,20 @@
+-- Existing rooms become private: their members stay, newcomers need an invite
+ALTER TABLE "rooms" ADD COLUMN "is_public" boolean NOT NULL DEFAULT false;
+
+COMMENT ON COLUMN "rooms"."is_public" IS 'Anyone can join, guests included; private rooms need an invite';
+
+CREATE TABLE "room_invites" (
+  "room_id" bigint NOT NULL,
+  "user_id" int NOT NULL,
+  "invited_by" int NOT NULL,
+  "created_at" timestamptz NOT NULL DEFAULT (now()),
+  PRIMARY KEY ("room_id", "user_id")
+);
+
+COMMENT ON TABLE "room_invites" IS 'Pending invites to private rooms; joining deletes the row';
+
+ALTER TABLE "room_invites" ADD FOREIGN KEY ("room_id") REFERENCES "rooms" ("id") ON DELETE CASCADE;
+
+ALTER TABLE "room_invites" ADD FOREIGN KEY ("user_id") REFERENCES "users" ("id") ON DELETE CASCADE;
+
+ALTER TABLE "room_invites" ADD FOREIGN KEY ("invited_by") REFERENCES "users" ("id") ON DELETE CASCADE;
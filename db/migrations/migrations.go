@@ -0,0 +1,42 @@
+// Package migrations embeds the SQL schema migrations in the binary and applies them with
+// golang-migrate. The files follow its naming (<version>_<name>.up.sql / .down.sql); sqlc reads the
+// same files to generate the store. The applied version is kept in the schema_migrations table.
+package migrations
+
+import (
+	"embed"
+	"errors"
+
+	"github.com/golang-migrate/migrate/v4"
+	_ "github.com/golang-migrate/migrate/v4/database/postgres" // postgres:// URLs
+	"github.com/golang-migrate/migrate/v4/source/iofs"
+)
+
+//go:embed *.sql
+var files embed.FS
+
+// New creates a migrator for the database at dsn, a PostgreSQL connection URL. It opens its own
+// connection, released by Close. While migrating it holds an advisory lock, so instances starting
+// at the same time take turns.
+func New(dsn string) (*migrate.Migrate, error) {
+	source, err := iofs.New(files, ".")
+	if err != nil {
+		return nil, err
+	}
+	return migrate.NewWithSourceInstance("iofs", source, dsn)
+}
+
+// Up applies the migrations the database does not have yet and returns the resulting version
+func Up(dsn string) (uint, error) {
+	m, err := New(dsn)
+	if err != nil {
+		return 0, err
+	}
+	defer m.Close()
+
+	if err := m.Up(); err != nil && !errors.Is(err, migrate.ErrNoChange) {
+		return 0, err
+	}
+	version, _, err := m.Version()
+	return version, err
+}
@@ -0,0 +1,35 @@
+-- name: CreateAnnouncement :one
+INSERT INTO announcements (
+  author_id,
+  content
+) VALUES (
+  $1, $2
+) RETURNING *;
+
+-- name: ListAnnouncementsForUser :many
+-- Recent announcements with whether the user has acknowledged them
+SELECT a.id, a.author_id, a.content, a.created_at, r.seen_at
+FROM announcements a
+LEFT JOIN announcement_receipts r ON r.announcement_id = a.id AND r.user_id = sqlc.arg(user_id)
+WHERE sqlc.arg(before_id)::bigint = 0 OR a.id < sqlc.arg(before_id)::bigint
+ORDER BY a.id DESC
+LIMIT sqlc.arg(page_limit);
+
+-- name: MarkAnnouncementSeen :execrows
+INSERT INTO announcement_receipts (
+  announcement_id,
+  user_id
+) VALUES (
+  $1, $2
+) ON CONFLICT (announcement_id, user_id) DO NOTHING;
+
+-- name: ListAnnouncementStats :many
+-- Reach of recent announcements: seen_count out of the active, non-guest accounts that existed when it was sent
+SELECT a.id, a.author_id, a.content, a.created_at,
+  (SELECT count(*) FROM announcement_receipts r WHERE r.announcement_id = a.id) AS seen_count,
+  (SELECT count(*) FROM users u
+   WHERE u.created_at <= a.created_at AND u.deactivated_at IS NULL AND u.role <> 'guest') AS audience_count
+FROM announcements a
+WHERE sqlc.arg(before_id)::bigint = 0 OR a.id < sqlc.arg(before_id)::bigint
+ORDER BY a.id DESC
+LIMIT sqlc.arg(page_limit);
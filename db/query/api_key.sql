@@ -0,0 +1,21 @@
+-- name: CreateAPIKey :one
+INSERT INTO api_keys (
+  user_id,
+  name,
+  key_hash,
+  scopes
+) VALUES (
+  $1, $2, $3, $4
+) RETURNING *;
+
+-- name: GetActiveAPIKeyByHash :one
+SELECT * FROM api_keys
+WHERE key_hash = $1 AND revoked_at IS NULL
+LIMIT 1;
+
+-- name: RevokeAPIKey :one
+-- Returns the account of the key, whose connections are closed
+UPDATE api_keys
+SET revoked_at = now()
+WHERE id = $1 AND revoked_at IS NULL
+RETURNING user_id;
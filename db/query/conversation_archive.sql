@@ -0,0 +1,17 @@
+-- name: ArchiveConversation :exec
+INSERT INTO conversation_archives (
+  user_id,
+  partner_id
+) VALUES (
+  $1, $2
+)
+ON CONFLICT (user_id, partner_id) DO NOTHING;
+
+-- name: UnarchiveConversation :execrows
+DELETE FROM conversation_archives
+WHERE user_id = $1 AND partner_id = $2;
+
+-- name: ListArchivedConversations :many
+SELECT * FROM conversation_archives
+WHERE user_id = $1
+ORDER BY created_at DESC;
@@ -0,0 +1,14 @@
+-- name: ClearConversation :one
+INSERT INTO conversation_clears (
+  user_id,
+  partner_id,
+  cleared_before_id
+)
+SELECT $1, $2, COALESCE(MAX(m.id), 0)::bigint
+FROM messages m
+WHERE (m.sender_id = $1 AND m.receiver_id = $2)
+   OR (m.sender_id = $2 AND m.receiver_id = $1)
+ON CONFLICT (user_id, partner_id) DO UPDATE
+SET cleared_before_id = EXCLUDED.cleared_before_id,
+    cleared_at = now()
+RETURNING *;
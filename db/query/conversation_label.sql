@@ -0,0 +1,26 @@
+-- name: SetConversationLabels :exec
+-- Replaces the labels of the conversation with the given ones, in one statement
+WITH removed AS (
+  DELETE FROM conversation_labels
+  WHERE user_id = sqlc.arg(user_id) AND partner_id = sqlc.arg(partner_id)
+    AND NOT (label = ANY(sqlc.arg(labels)::text[]))
+)
+INSERT INTO conversation_labels (
+  user_id,
+  partner_id,
+  label
+)
+SELECT sqlc.arg(user_id)::int, sqlc.arg(partner_id)::int, unnest(sqlc.arg(labels)::text[])
+ON CONFLICT (user_id, partner_id, label) DO NOTHING;
+
+-- name: ListConversationLabelsForPartners :many
+SELECT * FROM conversation_labels
+WHERE user_id = sqlc.arg(user_id) AND partner_id = ANY(sqlc.arg(partner_ids)::int[])
+ORDER BY partner_id, label;
+
+-- name: ListUserLabels :many
+-- The user's labels with the number of conversations carrying each
+SELECT label, COUNT(*) AS conversations FROM conversation_labels
+WHERE user_id = $1
+GROUP BY label
+ORDER BY label;
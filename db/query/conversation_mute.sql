@@ -0,0 +1,32 @@
+-- name: UpsertConversationMute :one
+INSERT INTO conversation_mutes (
+  user_id,
+  partner_id,
+  muted_until
+) VALUES (
+  $1, $2, $3
+)
+ON CONFLICT (user_id, partner_id) DO UPDATE
+SET muted_until = EXCLUDED.muted_until
+RETURNING *;
+
+-- name: DeleteConversationMute :exec
+DELETE FROM conversation_mutes
+WHERE user_id = $1 AND partner_id = $2;
+
+-- name: GetActiveConversationMute :one
+SELECT * FROM conversation_mutes
+WHERE user_id = $1 AND partner_id = $2
+  AND (muted_until IS NULL OR muted_until > now())
+LIMIT 1;
+
+-- name: ListActiveConversationMutes :many
+SELECT * FROM conversation_mutes
+WHERE user_id = $1
+  AND (muted_until IS NULL OR muted_until > now())
+ORDER BY created_at DESC;
+
+-- name: DeleteExpiredConversationMutes :many
+DELETE FROM conversation_mutes
+WHERE muted_until IS NOT NULL AND muted_until <= now()
+RETURNING user_id, partner_id;
@@ -0,0 +1,33 @@
+-- name: ListConversations :many
+-- One row per conversation partner with the latest message the user can see, most recently active
+-- first. Reads the summaries the triggers of migration 43 maintain, not the messages.
+SELECT
+  cs.partner_id,
+  u.username AS partner_username,
+  cs.last_message_id,
+  cs.last_message_sender_id,
+  cs.last_message_content,
+  cs.last_message_content_type,
+  cs.last_message_at,
+  cs.unread_count::bigint AS unread_count,
+  ca.user_id IS NOT NULL AS archived,
+  cm.user_id IS NOT NULL AS muted,
+  cm.muted_until
+FROM conversation_summaries cs
+JOIN users u ON u.id = cs.partner_id
+LEFT JOIN conversation_archives ca ON ca.user_id = cs.user_id AND ca.partner_id = cs.partner_id
+-- Mutes are swept a minute after they expire, so expired ones are left out here
+LEFT JOIN conversation_mutes cm ON cm.user_id = cs.user_id AND cm.partner_id = cs.partner_id
+  AND (cm.muted_until IS NULL OR cm.muted_until > now())
+WHERE cs.user_id = sqlc.arg(user_id)
+  -- Either the archived conversations or the others
+  AND (ca.user_id IS NOT NULL) = sqlc.arg(archived)::boolean
+  -- Keyset pagination on the last message: 0 starts from the most recent conversation
+  AND (sqlc.arg(before_id)::bigint = 0 OR cs.last_message_id < sqlc.arg(before_id)::bigint)
+  -- Optionally only the conversations with a label
+  AND (sqlc.arg(label)::text = '' OR EXISTS (
+    SELECT 1 FROM conversation_labels cl
+    WHERE cl.user_id = cs.user_id AND cl.partner_id = cs.partner_id AND cl.label = sqlc.arg(label)::text
+  ))
+ORDER BY cs.last_message_id DESC
+LIMIT sqlc.arg(page_limit);
@@ -0,0 +1,27 @@
+-- name: HideTypingFromPartner :exec
+INSERT INTO conversation_typing_opt_outs (
+  user_id,
+  partner_id
+) VALUES (
+  $1, $2
+)
+ON CONFLICT (user_id, partner_id) DO NOTHING;
+
+-- name: ShowTypingToPartner :execrows
+DELETE FROM conversation_typing_opt_outs
+WHERE user_id = $1 AND partner_id = $2;
+
+-- name: IsTypingHiddenFromPartner :one
+-- Also true when the user turned typing indicators off for everyone
+SELECT EXISTS (
+  SELECT 1 FROM conversation_typing_opt_outs
+  WHERE user_id = $1 AND partner_id = $2
+) OR EXISTS (
+  SELECT 1 FROM user_settings
+  WHERE user_id = $1 AND NOT typing_indicators
+) AS hidden;
+
+-- name: ListTypingOptOuts :many
+SELECT * FROM conversation_typing_opt_outs
+WHERE user_id = $1
+ORDER BY created_at DESC;
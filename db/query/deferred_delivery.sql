@@ -0,0 +1,15 @@
+-- name: CreateDeferredDelivery :exec
+INSERT INTO deferred_deliveries (
+  message_id,
+  sender_id,
+  recipient_id
+) VALUES (
+  $1, $2, $3
+)
+ON CONFLICT (message_id) DO NOTHING;
+
+-- name: DeleteDeferredDeliveries :many
+-- Takes the deferred messages of a recipient who connected
+DELETE FROM deferred_deliveries
+WHERE recipient_id = $1
+RETURNING *;
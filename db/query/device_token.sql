@@ -0,0 +1,27 @@
+-- name: UpsertDeviceToken :one
+-- A token belongs to the account last logged in on the device
+INSERT INTO device_tokens (
+  user_id,
+  platform,
+  token
+) VALUES (
+  $1, $2, $3
+)
+ON CONFLICT (token) DO UPDATE
+SET user_id = EXCLUDED.user_id,
+    platform = EXCLUDED.platform,
+    updated_at = now()
+RETURNING *;
+
+-- name: ListDeviceTokens :many
+SELECT * FROM device_tokens
+WHERE user_id = $1
+ORDER BY updated_at DESC;
+
+-- name: DeleteDeviceToken :execrows
+DELETE FROM device_tokens
+WHERE token = $1;
+
+-- name: DeleteUserDeviceToken :execrows
+DELETE FROM device_tokens
+WHERE user_id = $1 AND token = $2;
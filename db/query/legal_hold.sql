@@ -0,0 +1,46 @@
+-- name: CreateLegalHold :one
+-- Returns no row if the user or conversation is already on hold
+INSERT INTO legal_holds (
+  user_id,
+  partner_id,
+  reason,
+  created_by
+) VALUES (
+  $1, $2, $3, $4
+)
+ON CONFLICT DO NOTHING
+RETURNING *;
+
+-- name: GetLegalHold :one
+SELECT * FROM legal_holds
+WHERE id = $1;
+
+-- name: ListLegalHolds :many
+-- Newest first, released holds included
+SELECT * FROM legal_holds
+ORDER BY id DESC
+LIMIT sqlc.arg(page_limit);
+
+-- name: ReleaseLegalHold :one
+UPDATE legal_holds
+SET released_at = now(), released_by = $2
+WHERE id = $1 AND released_at IS NULL
+RETURNING *;
+
+-- name: ListHeldMessages :many
+-- Private messages of the user, or with a partner only those of their conversation, deleted ones
+-- included, oldest first
+SELECT * FROM messages
+WHERE (sender_id = sqlc.arg(user_id) OR receiver_id = sqlc.arg(user_id))
+  AND (sqlc.arg(partner_id)::int = 0 OR sender_id = sqlc.arg(partner_id) OR receiver_id = sqlc.arg(partner_id))
+  AND id > sqlc.arg(after_id)::bigint
+ORDER BY id
+LIMIT sqlc.arg(page_limit);
+
+-- name: ListHeldRoomMessages :many
+-- Room messages the user sent, oldest first
+SELECT * FROM room_messages
+WHERE sender_id = sqlc.arg(user_id)
+  AND id > sqlc.arg(after_id)::bigint
+ORDER BY id
+LIMIT sqlc.arg(page_limit);
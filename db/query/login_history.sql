@@ -0,0 +1,23 @@
+-- name: CreateLoginHistory :one
+INSERT INTO login_history (
+  user_id,
+  ip_address,
+  user_agent
+) VALUES (
+  $1, $2, $3
+) RETURNING *;
+
+-- name: CountLoginHistory :one
+SELECT count(*) FROM login_history
+WHERE user_id = $1;
+
+-- name: CountLoginHistoryForDevice :one
+SELECT count(*) FROM login_history
+WHERE user_id = $1 AND ip_address = $2 AND user_agent = $3;
+
+-- name: ListLoginHistory :many
+SELECT * FROM login_history
+WHERE user_id = sqlc.arg(user_id)
+  AND (sqlc.arg(before_id)::bigint = 0 OR id < sqlc.arg(before_id)::bigint)
+ORDER BY id DESC
+LIMIT sqlc.arg(page_limit);
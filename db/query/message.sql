@@ -2,15 +2,71 @@
 INSERT INTO messages (
   sender_id,
   receiver_id,
-  content
+  content,
+  content_type,
+  reply_to_message_id
 ) VALUES (
-  $1, $2, $3
+  $1, $2, $3, $4, $5
 ) RETURNING *;
 
 -- name: GetMessagesBetweenUsers :many
 SELECT * FROM messages
-WHERE (sender_id = $1 AND receiver_id = $2)
-   OR (sender_id = $2 AND receiver_id = $1)
-ORDER BY created_at DESC -- Order by newest first for pagination
-LIMIT $3 -- Page size
-OFFSET $4; -- Offset for pagination
\ No newline at end of file
+WHERE ((sender_id = sqlc.arg(user_id) AND receiver_id = sqlc.arg(partner_id))
+   OR (sender_id = sqlc.arg(partner_id) AND receiver_id = sqlc.arg(user_id)))
+  AND deleted_at IS NULL
+  -- Hide messages the requesting user cleared from their side of the conversation
+  AND id > COALESCE((
+    SELECT cleared_before_id FROM conversation_clears
+    WHERE user_id = sqlc.arg(user_id) AND partner_id = sqlc.arg(partner_id)
+  ), 0)
+  -- Keyset pagination: 0 starts from the newest message
+  AND (sqlc.arg(before_id)::bigint = 0 OR id < sqlc.arg(before_id)::bigint)
+ORDER BY id DESC -- Newest first
+LIMIT sqlc.arg(page_limit);
+
+-- name: MarkMessagesRead :many
+-- Marks unread messages of a conversation the reader received as read and returns their IDs:
+-- those up to up_to_id (0 for no limit) that are in message_ids (empty or NULL for all)
+UPDATE messages
+SET read_at = now(), delivered_at = COALESCE(delivered_at, now())
+WHERE sender_id = sqlc.arg(sender_id) AND receiver_id = sqlc.arg(reader_id)
+  AND read_at IS NULL AND deleted_at IS NULL
+  AND (sqlc.arg(up_to_id)::bigint = 0 OR id <= sqlc.arg(up_to_id)::bigint)
+  AND (coalesce(cardinality(sqlc.arg(message_ids)::bigint[]), 0) = 0 OR id = ANY(sqlc.arg(message_ids)::bigint[]))
+RETURNING id;
+
+-- name: MarkMessageDelivered :one
+-- Records that a connection of the receiver accepted the message. No row if it was delivered before.
+UPDATE messages
+SET delivered_at = now()
+WHERE id = $1 AND delivered_at IS NULL
+RETURNING delivered_at;
+
+-- name: GetMessage :one
+SELECT * FROM messages
+WHERE id = $1;
+
+-- name: DeleteMessage :one
+-- Soft-deletes a message for both parties
+UPDATE messages
+SET deleted_at = now()
+WHERE id = $1 AND deleted_at IS NULL
+RETURNING *;
+
+-- name: ListMessagesSince :many
+-- Messages of all conversations of the user after a message ID, oldest first, for clients catching up on reconnect
+SELECT m.* FROM messages m
+LEFT JOIN conversation_clears cc ON cc.user_id = sqlc.arg(user_id)
+  AND cc.partner_id = CASE WHEN m.sender_id = sqlc.arg(user_id) THEN m.receiver_id ELSE m.sender_id END
+WHERE (m.sender_id = sqlc.arg(user_id) OR m.receiver_id = sqlc.arg(user_id))
+  AND m.id > sqlc.arg(after_id)::bigint
+  -- Skip what the user cleared and messages deleted for everyone
+  AND m.id > COALESCE(cc.cleared_before_id, 0)
+  AND m.deleted_at IS NULL
+ORDER BY m.id
+LIMIT sqlc.arg(page_limit);
+
+-- name: ListMessagesByIDs :many
+-- The messages replies quote, including deleted ones
+SELECT * FROM messages
+WHERE id = ANY(sqlc.arg(ids)::bigint[]);
@@ -0,0 +1,23 @@
+-- name: DeleteConversationMessages :many
+-- Soft-deletes messages the sender sent to the receiver and returns their IDs: those in
+-- message_ids, or for an empty list those from from_id to to_id
+UPDATE messages
+SET deleted_at = now()
+WHERE sender_id = sqlc.arg(sender_id) AND receiver_id = sqlc.arg(receiver_id)
+  AND deleted_at IS NULL
+  AND (id = ANY(sqlc.arg(message_ids)::bigint[])
+    OR (coalesce(cardinality(sqlc.arg(message_ids)::bigint[]), 0) = 0
+      AND id BETWEEN sqlc.arg(from_id)::bigint AND sqlc.arg(to_id)::bigint))
+RETURNING id;
+
+-- name: MarkConversationMessagesRead :many
+-- Marks unread messages the reader received from the sender as read and returns their IDs: those
+-- in message_ids, or for an empty list those from from_id to to_id
+UPDATE messages
+SET read_at = now(), delivered_at = COALESCE(delivered_at, now())
+WHERE sender_id = sqlc.arg(sender_id) AND receiver_id = sqlc.arg(reader_id)
+  AND read_at IS NULL AND deleted_at IS NULL
+  AND (id = ANY(sqlc.arg(message_ids)::bigint[])
+    OR (coalesce(cardinality(sqlc.arg(message_ids)::bigint[]), 0) = 0
+      AND id BETWEEN sqlc.arg(from_id)::bigint AND sqlc.arg(to_id)::bigint))
+RETURNING id;
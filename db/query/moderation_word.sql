@@ -0,0 +1,23 @@
+-- name: ListModerationWords :many
+SELECT * FROM moderation_words
+ORDER BY id;
+
+-- name: CreateModerationWord :one
+INSERT INTO moderation_words (
+  pattern,
+  is_regex,
+  severity,
+  created_by
+) VALUES (
+  $1, $2, $3, $4
+) RETURNING *;
+
+-- name: UpdateModerationWordSeverity :one
+UPDATE moderation_words
+SET severity = $2
+WHERE id = $1
+RETURNING *;
+
+-- name: DeleteModerationWord :execrows
+DELETE FROM moderation_words
+WHERE id = $1;
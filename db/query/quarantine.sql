@@ -0,0 +1,32 @@
+-- name: QuarantineUser :exec
+INSERT INTO quarantined_users (
+  user_id,
+  reason,
+  ip_address
+) VALUES (
+  $1, $2, $3
+)
+ON CONFLICT (user_id) DO NOTHING;
+
+-- name: GetQuarantine :one
+SELECT * FROM quarantined_users
+WHERE user_id = $1;
+
+-- name: ReleaseQuarantine :execrows
+DELETE FROM quarantined_users
+WHERE user_id = $1;
+
+-- name: ListQuarantinedUsers :many
+-- Newest first, with the username for the admin list
+SELECT q.user_id, u.username, q.reason, q.ip_address, q.created_at
+FROM quarantined_users q
+JOIN users u ON u.id = q.user_id
+ORDER BY q.created_at DESC
+LIMIT sqlc.arg(page_limit);
+
+-- name: CountRecentSends :one
+-- Private and room messages the user sent after the given time
+SELECT (
+  (SELECT count(*) FROM messages WHERE sender_id = sqlc.arg(user_id) AND created_at > sqlc.arg(since))
+  + (SELECT count(*) FROM room_messages WHERE sender_id = sqlc.arg(user_id) AND created_at > sqlc.arg(since))
+)::bigint AS sends;
@@ -0,0 +1,95 @@
+-- name: CreateRoom :one
+INSERT INTO rooms (
+  name,
+  created_by,
+  owner_id,
+  is_public
+) VALUES (
+  sqlc.arg(name), sqlc.arg(created_by), sqlc.arg(created_by), sqlc.arg(is_public)
+) RETURNING *;
+
+-- name: GetRoom :one
+SELECT * FROM rooms
+WHERE id = $1 LIMIT 1;
+
+-- name: ListRoomsOwnedBy :many
+SELECT * FROM rooms
+WHERE owner_id = $1
+ORDER BY id;
+
+-- name: SetRoomOwner :one
+UPDATE rooms
+SET owner_id = sqlc.arg(owner_id)
+WHERE id = sqlc.arg(room_id)
+RETURNING *;
+
+-- name: GetRoomSuccessor :one
+-- The member who inherits the room from its owner: the longest-standing active member, guests excluded
+SELECT m.user_id FROM room_members m
+JOIN users u ON u.id = m.user_id
+WHERE m.room_id = sqlc.arg(room_id) AND m.user_id <> sqlc.arg(owner_id)
+  AND u.deactivated_at IS NULL AND u.role <> 'guest'
+ORDER BY m.joined_at, m.user_id
+LIMIT 1;
+
+-- name: ListRoomsForUser :many
+-- Rooms the user is a member of, oldest first
+SELECT r.* FROM rooms r
+JOIN room_members m ON m.room_id = r.id
+WHERE m.user_id = sqlc.arg(user_id)
+  AND r.id > sqlc.arg(after_id)::bigint
+ORDER BY r.id
+LIMIT sqlc.arg(page_limit);
+
+-- name: AddRoomMember :execrows
+INSERT INTO room_members (
+  room_id,
+  user_id
+) VALUES (
+  $1, $2
+) ON CONFLICT (room_id, user_id) DO NOTHING;
+
+-- name: RemoveRoomMember :execrows
+DELETE FROM room_members
+WHERE room_id = $1 AND user_id = $2;
+
+-- name: IsRoomMember :one
+SELECT EXISTS (
+  SELECT 1 FROM room_members
+  WHERE room_id = $1 AND user_id = $2
+) AS is_member;
+
+-- name: ListRoomMemberIDs :many
+SELECT user_id FROM room_members
+WHERE room_id = $1;
+
+-- name: CreateRoomInvite :execrows
+-- Inviting someone twice keeps the first invite
+INSERT INTO room_invites (
+  room_id,
+  user_id,
+  invited_by
+) VALUES (
+  $1, $2, $3
+) ON CONFLICT (room_id, user_id) DO NOTHING;
+
+-- name: DeleteRoomInvite :execrows
+DELETE FROM room_invites
+WHERE room_id = $1 AND user_id = $2;
+
+-- name: CreateRoomMessage :one
+INSERT INTO room_messages (
+  room_id,
+  sender_id,
+  content
+) VALUES (
+  $1, $2, $3
+) RETURNING *;
+
+-- name: ListRoomMessages :many
+SELECT * FROM room_messages
+WHERE room_id = sqlc.arg(room_id)
+  -- Keyset pagination: 0 starts from the newest message
+  AND (sqlc.arg(before_id)::bigint = 0 OR id < sqlc.arg(before_id)::bigint)
+ORDER BY id DESC -- Newest first
+LIMIT sqlc.arg(page_limit);
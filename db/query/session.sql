@@ -0,0 +1,24 @@
+-- name: CreateSession :one
+INSERT INTO sessions (
+  id,
+  user_id,
+  user_agent,
+  client_ip,
+  expires_at
+) VALUES (
+  $1, $2, $3, $4, $5
+) RETURNING *;
+
+-- name: GetSession :one
+SELECT * FROM sessions
+WHERE id = $1 LIMIT 1;
+
+-- name: RevokeSession :execrows
+-- Revokes a session once; 0 rows means it was already used or revoked
+UPDATE sessions
+SET revoked_at = now()
+WHERE id = $1 AND revoked_at IS NULL;
+
+-- name: DeleteExpiredSessions :execrows
+DELETE FROM sessions
+WHERE expires_at <= now();
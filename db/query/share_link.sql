@@ -0,0 +1,46 @@
+-- name: CreateShareLink :one
+INSERT INTO share_links (
+  user_id,
+  partner_id,
+  first_message_id,
+  last_message_id,
+  token_hash,
+  expires_at
+) VALUES (
+  $1, $2, $3, $4, $5, $6
+) RETURNING *;
+
+-- name: GetActiveShareLinkByHash :one
+SELECT * FROM share_links
+WHERE token_hash = $1 AND revoked_at IS NULL AND expires_at > now()
+LIMIT 1;
+
+-- name: ListActiveShareLinks :many
+-- The user's links that still work, newest first
+SELECT * FROM share_links
+WHERE user_id = sqlc.arg(user_id) AND revoked_at IS NULL AND expires_at > now()
+ORDER BY id DESC
+LIMIT sqlc.arg(page_limit);
+
+-- name: RevokeShareLink :execrows
+UPDATE share_links
+SET revoked_at = now()
+WHERE id = sqlc.arg(id) AND user_id = sqlc.arg(user_id) AND revoked_at IS NULL;
+
+-- name: ListSharedMessages :many
+-- Messages of a conversation from first_message_id to last_message_id, oldest first, as the sharing
+-- user sees them: without deleted messages, what they cleared and end-to-end encrypted contents
+SELECT m.id, m.sender_id, u.username AS sender_username, m.content, m.content_type, m.created_at
+FROM messages m
+JOIN users u ON u.id = m.sender_id
+WHERE ((m.sender_id = sqlc.arg(user_id) AND m.receiver_id = sqlc.arg(partner_id))
+   OR (m.sender_id = sqlc.arg(partner_id) AND m.receiver_id = sqlc.arg(user_id)))
+  AND m.id BETWEEN sqlc.arg(first_message_id)::bigint AND sqlc.arg(last_message_id)::bigint
+  AND m.deleted_at IS NULL
+  AND m.content_type <> 'encrypted'
+  AND m.id > COALESCE((
+    SELECT cleared_before_id FROM conversation_clears
+    WHERE user_id = sqlc.arg(user_id) AND partner_id = sqlc.arg(partner_id)
+  ), 0)
+ORDER BY m.id
+LIMIT sqlc.arg(page_limit);
@@ -0,0 +1,12 @@
+-- name: CreateSignupIdempotencyKey :exec
+INSERT INTO signup_idempotency_keys (
+  key,
+  user_id,
+  username
+) VALUES (
+  $1, $2, $3
+) ON CONFLICT (key) DO NOTHING;
+
+-- name: GetSignupIdempotencyKey :one
+SELECT * FROM signup_idempotency_keys
+WHERE key = $1 LIMIT 1;
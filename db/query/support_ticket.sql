@@ -0,0 +1,49 @@
+-- name: OpenSupportTicket :one
+-- Returns the customer's active ticket, creating it if needed
+INSERT INTO support_tickets (
+  support_user_id,
+  customer_id
+) VALUES (
+  $1, $2
+)
+ON CONFLICT (support_user_id, customer_id) WHERE status <> 'closed'
+DO UPDATE SET status = support_tickets.status
+RETURNING *;
+
+-- name: GetSupportTicket :one
+SELECT * FROM support_tickets
+WHERE id = $1 LIMIT 1;
+
+-- name: ListSupportTicketsByStatus :many
+SELECT * FROM support_tickets
+WHERE status = sqlc.arg(status) AND id > sqlc.arg(after_id)
+ORDER BY id
+LIMIT sqlc.arg(page_limit);
+
+-- name: ClaimSupportTicket :one
+UPDATE support_tickets
+SET agent_id = $2, status = 'claimed', claimed_at = now()
+WHERE id = $1 AND status = 'open'
+RETURNING *;
+
+-- name: AssignSupportTicket :one
+UPDATE support_tickets
+SET agent_id = $2, status = 'claimed', claimed_at = COALESCE(claimed_at, now())
+WHERE id = $1 AND status <> 'closed'
+RETURNING *;
+
+-- name: CloseSupportTicket :one
+UPDATE support_tickets
+SET status = 'closed', closed_at = now()
+WHERE id = $1 AND status <> 'closed'
+RETURNING *;
+
+-- name: ListSupportTicketTranscript :many
+SELECT m.* FROM messages m
+JOIN support_tickets t ON t.id = $1
+WHERE ((m.sender_id = t.customer_id AND m.receiver_id = t.support_user_id)
+   OR (m.sender_id = t.support_user_id AND m.receiver_id = t.customer_id))
+  AND m.created_at >= t.created_at
+  AND (t.closed_at IS NULL OR m.created_at <= t.closed_at)
+  AND m.deleted_at IS NULL
+ORDER BY m.id;
@@ -0,0 +1,79 @@
+-- name: RecordDailyUsage :one
+-- Records the usage of a UTC day so far; recording the day again updates it
+INSERT INTO usage_daily (
+  day,
+  active_users,
+  messages,
+  storage_bytes
+)
+SELECT
+  sqlc.arg(day)::date,
+  (SELECT count(*) FROM (
+    SELECT user_id FROM login_history
+    WHERE created_at >= sqlc.arg(day)::date AND created_at < sqlc.arg(day)::date + 1
+    UNION
+    SELECT sender_id FROM messages
+    WHERE created_at >= sqlc.arg(day)::date AND created_at < sqlc.arg(day)::date + 1
+    UNION
+    SELECT sender_id FROM room_messages
+    WHERE created_at >= sqlc.arg(day)::date AND created_at < sqlc.arg(day)::date + 1
+  ) active),
+  (SELECT count(*) FROM messages
+   WHERE created_at >= sqlc.arg(day)::date AND created_at < sqlc.arg(day)::date + 1)
+  + (SELECT count(*) FROM room_messages
+     WHERE created_at >= sqlc.arg(day)::date AND created_at < sqlc.arg(day)::date + 1),
+  (SELECT coalesce(sum(octet_length(content)), 0) FROM messages WHERE deleted_at IS NULL)
+  + (SELECT coalesce(sum(octet_length(content)), 0) FROM room_messages)
+ON CONFLICT (day) DO UPDATE
+SET active_users = EXCLUDED.active_users,
+    messages = EXCLUDED.messages,
+    storage_bytes = EXCLUDED.storage_bytes,
+    recorded_at = now()
+RETURNING *;
+
+-- name: RollUpMonthlyUsage :one
+-- Sums up the recorded days of a UTC month (given by its first day); active users are counted
+-- once per month
+INSERT INTO usage_monthly (
+  month,
+  active_users,
+  messages,
+  peak_storage_bytes
+)
+SELECT
+  sqlc.arg(month)::date,
+  (SELECT count(*) FROM (
+    SELECT user_id FROM login_history
+    WHERE created_at >= sqlc.arg(month)::date AND created_at < sqlc.arg(month)::date + interval '1 month'
+    UNION
+    SELECT sender_id FROM messages
+    WHERE created_at >= sqlc.arg(month)::date AND created_at < sqlc.arg(month)::date + interval '1 month'
+    UNION
+    SELECT sender_id FROM room_messages
+    WHERE created_at >= sqlc.arg(month)::date AND created_at < sqlc.arg(month)::date + interval '1 month'
+  ) active),
+  coalesce(sum(d.messages), 0)::bigint,
+  coalesce(max(d.storage_bytes), 0)::bigint
+FROM usage_daily d
+WHERE d.day >= sqlc.arg(month)::date AND d.day < sqlc.arg(month)::date + interval '1 month'
+ON CONFLICT (month) DO UPDATE
+SET active_users = EXCLUDED.active_users,
+    messages = EXCLUDED.messages,
+    peak_storage_bytes = EXCLUDED.peak_storage_bytes,
+    rolled_up_at = now()
+RETURNING *;
+
+-- name: ListMonthlyUsage :many
+SELECT * FROM usage_monthly
+ORDER BY month DESC
+LIMIT sqlc.arg(page_limit);
+
+-- name: GetMonthlyUsage :one
+SELECT * FROM usage_monthly
+WHERE month = $1;
+
+-- name: ListDailyUsage :many
+-- The recorded days of a UTC month (given by its first day)
+SELECT * FROM usage_daily
+WHERE day >= sqlc.arg(month)::date AND day < sqlc.arg(month)::date + interval '1 month'
+ORDER BY day;
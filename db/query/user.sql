@@ -3,14 +3,15 @@
 -- name: CreateUser :one
 INSERT INTO users (
   username,
-  password_plaintext
+  password_hash
 ) VALUES (
   $1, $2
 ) RETURNING *;
 
 -- name: GetUserByUsername :one
+-- Case-insensitive, like the uniqueness of usernames
 SELECT * FROM users
-WHERE username = $1 LIMIT 1;
+WHERE lower(username) = lower(sqlc.arg(username)) LIMIT 1;
 
 -- name: GetUserByID :one
 SELECT * FROM users
@@ -18,15 +19,185 @@ WHERE id = $1 LIMIT 1;
 
 -- name: UpdateUserStatus :exec
 UPDATE users
-SET status = $2
+SET status = $2,
+    last_seen_at = now()
 WHERE id = $1;
 
 -- name: ListOnlineUsers :many
-SELECT id, username FROM users
-WHERE status = 'online'
-ORDER BY username;
+-- Presence is tracked outside the database: the caller passes the IDs of the online users.
+-- Invisible users and users who turned online visibility off are left out.
+SELECT id, username, presence FROM users
+WHERE id = ANY(sqlc.arg(online_user_ids)::int[]) AND presence <> 'invisible' AND id NOT IN (SELECT user_id FROM user_settings WHERE NOT online_visibility)
+  AND username > sqlc.arg(after_username)
+ORDER BY username
+LIMIT sqlc.arg(page_limit);
 
 -- name: ListOfflineUsers :many
-SELECT id, username FROM users
-WHERE status = 'offline'
-ORDER BY username;
\ No newline at end of file
+-- Everyone but the given online users, and the invisible or hidden ones
+SELECT id, username, last_seen_at FROM users
+WHERE (NOT (id = ANY(sqlc.arg(online_user_ids)::int[])) OR presence = 'invisible'
+  OR id IN (SELECT user_id FROM user_settings WHERE NOT online_visibility)) AND username > sqlc.arg(after_username)
+ORDER BY username
+LIMIT sqlc.arg(page_limit);
+
+-- name: ListUserPresence :many
+-- Status and last-seen time of the given users; unknown IDs are skipped
+SELECT id, status, last_seen_at, presence FROM users
+WHERE id = ANY(sqlc.arg(user_ids)::int[])
+ORDER BY id;
+
+-- name: SetUsersOffline :exec
+-- Marks the given users offline at once (used on shutdown for the users still connected)
+UPDATE users
+SET status = 'offline',
+    last_seen_at = now()
+WHERE id = ANY(sqlc.arg(user_ids)::int[]);
+
+-- name: TouchUsersLastSeen :exec
+-- Refreshes the last-seen time of the given connected users, except the invisible or hidden ones
+UPDATE users
+SET last_seen_at = now()
+WHERE id = ANY(sqlc.arg(user_ids)::int[]) AND presence <> 'invisible'
+  AND id NOT IN (SELECT user_id FROM user_settings WHERE NOT online_visibility);
+
+-- name: SetUserPresence :one
+UPDATE users
+SET presence = $2
+WHERE id = $1
+RETURNING *;
+
+-- name: ListUsers :many
+SELECT * FROM users
+ORDER BY id
+LIMIT $1
+OFFSET $2;
+
+-- name: CountUsers :one
+SELECT count(*) FROM users;
+
+-- name: UpdateUsername :one
+UPDATE users
+SET username = $2
+WHERE id = $1
+RETURNING *;
+
+-- name: DeactivateUser :one
+-- Already deactivated accounts keep their original deactivation time and author
+UPDATE users
+SET deactivated_at = COALESCE(deactivated_at, now()),
+    deactivated_by = CASE WHEN deactivated_at IS NULL THEN sqlc.narg(deactivated_by) ELSE deactivated_by END,
+    status = 'offline'
+WHERE id = sqlc.arg(id)
+RETURNING *;
+
+-- name: ReactivateUser :one
+UPDATE users
+SET deactivated_at = NULL,
+    deactivated_by = NULL
+WHERE id = $1
+RETURNING *;
+
+-- name: SuspendUser :one
+UPDATE users
+SET suspended_until = sqlc.arg(suspended_until),
+    suspension_reason = sqlc.arg(suspension_reason),
+    status = 'offline'
+WHERE id = sqlc.arg(id)
+RETURNING *;
+
+-- name: UnsuspendUser :one
+UPDATE users
+SET suspended_until = NULL,
+    suspension_reason = ''
+WHERE id = $1
+RETURNING *;
+
+-- name: ListUsersForModeration :many
+-- Optionally filtered by role and by a part of the username, oldest first
+SELECT * FROM users
+WHERE id > sqlc.arg(after_id)::int
+  AND (sqlc.arg(role)::text = '' OR role = sqlc.arg(role)::text)
+  AND (sqlc.arg(username_part)::text = '' OR strpos(lower(username), lower(sqlc.arg(username_part)::text)) > 0)
+ORDER BY id
+LIMIT sqlc.arg(page_limit);
+
+-- name: CreateGuestUser :one
+INSERT INTO users (
+  username,
+  password_hash,
+  role,
+  expires_at
+) VALUES (
+  $1, $2, 'guest', $3
+) RETURNING *;
+
+-- name: CreateBotUser :one
+-- Bots authenticate with API keys only: the password hash is of a random password nobody knows
+INSERT INTO users (
+  username,
+  password_hash,
+  role
+) VALUES (
+  $1, $2, 'bot'
+) RETURNING *;
+
+-- name: DeleteExpiredGuests :many
+-- Removes expired guests together with everything that references them, in one statement.
+-- Guests on legal hold, or in a conversation on hold or with a user on hold, are kept until the hold is released.
+WITH expired AS (
+  SELECT id FROM users
+  WHERE role = 'guest' AND expires_at <= now()
+    AND NOT EXISTS (
+      SELECT 1 FROM legal_holds h
+      WHERE h.released_at IS NULL
+        AND (h.user_id = users.id OR h.partner_id = users.id
+          -- A held user's conversation with the guest
+          OR (h.partner_id IS NULL AND EXISTS (
+            SELECT 1 FROM messages m
+            WHERE (m.sender_id = users.id AND m.receiver_id = h.user_id)
+               OR (m.sender_id = h.user_id AND m.receiver_id = users.id)
+          )))
+    )
+), deleted_messages AS (
+  DELETE FROM messages
+  WHERE sender_id IN (SELECT id FROM expired) OR receiver_id IN (SELECT id FROM expired)
+), deleted_login_history AS (
+  DELETE FROM login_history
+  WHERE user_id IN (SELECT id FROM expired)
+), deleted_mutes AS (
+  DELETE FROM conversation_mutes
+  WHERE user_id IN (SELECT id FROM expired) OR partner_id IN (SELECT id FROM expired)
+), deleted_archives AS (
+  DELETE FROM conversation_archives
+  WHERE user_id IN (SELECT id FROM expired) OR partner_id IN (SELECT id FROM expired)
+), deleted_clears AS (
+  DELETE FROM conversation_clears
+  WHERE user_id IN (SELECT id FROM expired) OR partner_id IN (SELECT id FROM expired)
+), deleted_typing_opt_outs AS (
+  DELETE FROM conversation_typing_opt_outs
+  WHERE user_id IN (SELECT id FROM expired) OR partner_id IN (SELECT id FROM expired)
+), deleted_support_tickets AS (
+  DELETE FROM support_tickets
+  WHERE customer_id IN (SELECT id FROM expired)
+), deleted_announcement_receipts AS (
+  DELETE FROM announcement_receipts
+  WHERE user_id IN (SELECT id FROM expired)
+), deleted_room_members AS (
+  DELETE FROM room_members
+  WHERE user_id IN (SELECT id FROM expired)
+), deleted_room_messages AS (
+  DELETE FROM room_messages
+  WHERE sender_id IN (SELECT id FROM expired)
+), deleted_webhooks AS (
+  -- Webhooks cannot post as guests, but were created for guest recipients before that was refused
+  DELETE FROM webhooks
+  WHERE recipient_id IN (SELECT id FROM expired)
+)
+DELETE FROM users
+WHERE id IN (SELECT id FROM expired)
+RETURNING id;
+
+-- name: ListActiveUserIDsByRole :many
+SELECT id FROM users
+WHERE role = $1 AND deactivated_at IS NULL
+ORDER BY id;
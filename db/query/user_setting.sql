@@ -0,0 +1,46 @@
+-- name: GetUserSettings :one
+SELECT * FROM user_settings
+WHERE user_id = $1;
+
+-- name: UpsertUserSettings :one
+INSERT INTO user_settings (
+  user_id,
+  read_receipts,
+  typing_indicators,
+  online_visibility
+) VALUES (
+  $1, $2, $3, $4
+)
+ON CONFLICT (user_id) DO UPDATE
+SET read_receipts = EXCLUDED.read_receipts,
+    typing_indicators = EXCLUDED.typing_indicators,
+    online_visibility = EXCLUDED.online_visibility,
+    updated_at = now()
+RETURNING *;
+
+-- name: ListUsersHidingReadReceipts :many
+-- The given users who turned read receipts off
+SELECT user_id FROM user_settings
+WHERE user_id = ANY(sqlc.arg(user_ids)::int[]) AND NOT read_receipts;
+
+-- name: ListUsersHidingOnline :many
+-- The given users who turned online visibility off
+SELECT user_id FROM user_settings
+WHERE user_id = ANY(sqlc.arg(user_ids)::int[]) AND NOT online_visibility;
+
+-- name: SetUserQuietHours :one
+-- NULLs turn quiet hours off
+INSERT INTO user_settings (
+  user_id,
+  quiet_hours_start,
+  quiet_hours_end,
+  quiet_hours_timezone
+) VALUES (
+  $1, $2, $3, $4
+)
+ON CONFLICT (user_id) DO UPDATE
+SET quiet_hours_start = EXCLUDED.quiet_hours_start,
+    quiet_hours_end = EXCLUDED.quiet_hours_end,
+    quiet_hours_timezone = EXCLUDED.quiet_hours_timezone,
+    updated_at = now()
+RETURNING *;
@@ -0,0 +1,33 @@
+-- name: CreateWebhook :one
+INSERT INTO webhooks (
+  name,
+  user_id,
+  room_id,
+  recipient_id,
+  token_hash,
+  rate_limit,
+  created_by
+) VALUES (
+  $1, $2, $3, $4, $5, $6, $7
+) RETURNING *;
+
+-- name: GetActiveWebhookByHash :one
+SELECT * FROM webhooks
+WHERE token_hash = $1 AND revoked_at IS NULL
+LIMIT 1;
+
+-- name: ListWebhooks :many
+-- Newest first, revoked webhooks included
+SELECT * FROM webhooks
+ORDER BY id DESC
+LIMIT sqlc.arg(page_limit);
+
+-- name: RevokeWebhook :execrows
+UPDATE webhooks
+SET revoked_at = now()
+WHERE id = $1 AND revoked_at IS NULL;
+
+-- name: TouchWebhook :exec
+UPDATE webhooks
+SET last_used_at = now()
+WHERE id = $1;
@@ -0,0 +1,27 @@
+package db
+
+import (
+	"errors"
+
+	"github.com/lib/pq"
+)
+
+// Postgres error codes the handlers react to
+const (
+	ForeignKeyViolation = "23503"
+	UniqueViolation     = "23505"
+)
+
+// ErrorCode returns the Postgres error code of err, or "" if err is not a Postgres error
+func ErrorCode(err error) string {
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) {
+		return string(pqErr.Code)
+	}
+	return ""
+}
+
+// IsUniqueViolation reports whether err is a unique constraint violation (e.g. a taken username)
+func IsUniqueViolation(err error) bool {
+	return ErrorCode(err) == UniqueViolation
+}
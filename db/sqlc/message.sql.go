@@ -7,26 +7,88 @@ package db
 
 import (
 	"context"
+	"database/sql"
+
+	"github.com/lib/pq"
 )
 
 const createMessage = `-- name: CreateMessage :one
 INSERT INTO messages (
   sender_id,
   receiver_id,
-  content
+  content,
+  content_type,
+  reply_to_message_id
 ) VALUES (
-  $1, $2, $3
-) RETURNING id, sender_id, receiver_id, content, created_at
+  $1, $2, $3, $4, $5
+) RETURNING id, sender_id, receiver_id, content, created_at, read_at, content_type, deleted_at, reply_to_message_id, delivered_at
 `
 
 type CreateMessageParams struct {
-	SenderID   int32  `json:"sender_id"`
-	ReceiverID int32  `json:"receiver_id"`
-	Content    string `json:"content"`
+	SenderID         int32         `json:"sender_id"`
+	ReceiverID       int32         `json:"receiver_id"`
+	Content          string        `json:"content"`
+	ContentType      string        `json:"content_type"`
+	ReplyToMessageID sql.NullInt64 `json:"reply_to_message_id"`
 }
 
 func (q *Queries) CreateMessage(ctx context.Context, arg CreateMessageParams) (Message, error) {
-	row := q.db.QueryRowContext(ctx, createMessage, arg.SenderID, arg.ReceiverID, arg.Content)
+	row := q.db.QueryRowContext(ctx, createMessage,
+		arg.SenderID,
+		arg.ReceiverID,
+		arg.Content,
+		arg.ContentType,
+		arg.ReplyToMessageID,
+	)
+	var i Message
+	err := row.Scan(
+		&i.ID,
+		&i.SenderID,
+		&i.ReceiverID,
+		&i.Content,
+		&i.CreatedAt,
+		&i.ReadAt,
+		&i.ContentType,
+		&i.DeletedAt,
+		&i.ReplyToMessageID,
+		&i.DeliveredAt,
+	)
+	return i, err
+}
+
+const deleteMessage = `-- name: DeleteMessage :one
+UPDATE messages
+SET deleted_at = now()
+WHERE id = $1 AND deleted_at IS NULL
+RETURNING id, sender_id, receiver_id, content, created_at, read_at, content_type, deleted_at, reply_to_message_id, delivered_at
+`
+
+// Soft-deletes a message for both parties
+func (q *Queries) DeleteMessage(ctx context.Context, id int64) (Message, error) {
+	row := q.db.QueryRowContext(ctx, deleteMessage, id)
+	var i Message
+	err := row.Scan(
+		&i.ID,
+		&i.SenderID,
+		&i.ReceiverID,
+		&i.Content,
+		&i.CreatedAt,
+		&i.ReadAt,
+		&i.ContentType,
+		&i.DeletedAt,
+		&i.ReplyToMessageID,
+		&i.DeliveredAt,
+	)
+	return i, err
+}
+
+const getMessage = `-- name: GetMessage :one
+SELECT id, sender_id, receiver_id, content, created_at, read_at, content_type, deleted_at, reply_to_message_id, delivered_at FROM messages
+WHERE id = $1
+`
+
+func (q *Queries) GetMessage(ctx context.Context, id int64) (Message, error) {
+	row := q.db.QueryRowContext(ctx, getMessage, id)
 	var i Message
 	err := row.Scan(
 		&i.ID,
@@ -34,32 +96,44 @@ func (q *Queries) CreateMessage(ctx context.Context, arg CreateMessageParams) (M
 		&i.ReceiverID,
 		&i.Content,
 		&i.CreatedAt,
+		&i.ReadAt,
+		&i.ContentType,
+		&i.DeletedAt,
+		&i.ReplyToMessageID,
+		&i.DeliveredAt,
 	)
 	return i, err
 }
 
 const getMessagesBetweenUsers = `-- name: GetMessagesBetweenUsers :many
-SELECT id, sender_id, receiver_id, content, created_at FROM messages
-WHERE (sender_id = $1 AND receiver_id = $2)
-   OR (sender_id = $2 AND receiver_id = $1)
-ORDER BY created_at DESC -- Order by newest first for pagination
-LIMIT $3 -- Page size
-OFFSET $4
+SELECT id, sender_id, receiver_id, content, created_at, read_at, content_type, deleted_at, reply_to_message_id, delivered_at FROM messages
+WHERE ((sender_id = $1 AND receiver_id = $2)
+   OR (sender_id = $2 AND receiver_id = $1))
+  AND deleted_at IS NULL
+  -- Hide messages the requesting user cleared from their side of the conversation
+  AND id > COALESCE((
+    SELECT cleared_before_id FROM conversation_clears
+    WHERE user_id = $1 AND partner_id = $2
+  ), 0)
+  -- Keyset pagination: 0 starts from the newest message
+  AND ($3::bigint = 0 OR id < $3::bigint)
+ORDER BY id DESC -- Newest first
+LIMIT $4
 `
 
 type GetMessagesBetweenUsersParams struct {
-	SenderID   int32 `json:"sender_id"`
-	ReceiverID int32 `json:"receiver_id"`
-	Limit      int32 `json:"limit"`
-	Offset     int32 `json:"offset"`
+	UserID    int32 `json:"user_id"`
+	PartnerID int32 `json:"partner_id"`
+	BeforeID  int64 `json:"before_id"`
+	PageLimit int32 `json:"page_limit"`
 }
 
 func (q *Queries) GetMessagesBetweenUsers(ctx context.Context, arg GetMessagesBetweenUsersParams) ([]Message, error) {
 	rows, err := q.db.QueryContext(ctx, getMessagesBetweenUsers,
-		arg.SenderID,
-		arg.ReceiverID,
-		arg.Limit,
-		arg.Offset,
+		arg.UserID,
+		arg.PartnerID,
+		arg.BeforeID,
+		arg.PageLimit,
 	)
 	if err != nil {
 		return nil, err
@@ -74,6 +148,11 @@ func (q *Queries) GetMessagesBetweenUsers(ctx context.Context, arg GetMessagesBe
 			&i.ReceiverID,
 			&i.Content,
 			&i.CreatedAt,
+			&i.ReadAt,
+			&i.ContentType,
+			&i.DeletedAt,
+			&i.ReplyToMessageID,
+			&i.DeliveredAt,
 		); err != nil {
 			return nil, err
 		}
@@ -87,3 +166,159 @@ func (q *Queries) GetMessagesBetweenUsers(ctx context.Context, arg GetMessagesBe
 	}
 	return items, nil
 }
+
+const listMessagesByIDs = `-- name: ListMessagesByIDs :many
+SELECT id, sender_id, receiver_id, content, created_at, read_at, content_type, deleted_at, reply_to_message_id, delivered_at FROM messages
+WHERE id = ANY($1::bigint[])
+`
+
+// The messages replies quote, including deleted ones
+func (q *Queries) ListMessagesByIDs(ctx context.Context, ids []int64) ([]Message, error) {
+	rows, err := q.db.QueryContext(ctx, listMessagesByIDs, pq.Array(ids))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []Message{}
+	for rows.Next() {
+		var i Message
+		if err := rows.Scan(
+			&i.ID,
+			&i.SenderID,
+			&i.ReceiverID,
+			&i.Content,
+			&i.CreatedAt,
+			&i.ReadAt,
+			&i.ContentType,
+			&i.DeletedAt,
+			&i.ReplyToMessageID,
+			&i.DeliveredAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listMessagesSince = `-- name: ListMessagesSince :many
+SELECT m.id, m.sender_id, m.receiver_id, m.content, m.created_at, m.read_at, m.content_type, m.deleted_at, m.reply_to_message_id, m.delivered_at FROM messages m
+LEFT JOIN conversation_clears cc ON cc.user_id = $1
+  AND cc.partner_id = CASE WHEN m.sender_id = $1 THEN m.receiver_id ELSE m.sender_id END
+WHERE (m.sender_id = $1 OR m.receiver_id = $1)
+  AND m.id > $2::bigint
+  -- Skip what the user cleared and messages deleted for everyone
+  AND m.id > COALESCE(cc.cleared_before_id, 0)
+  AND m.deleted_at IS NULL
+ORDER BY m.id
+LIMIT $3
+`
+
+type ListMessagesSinceParams struct {
+	UserID    int32 `json:"user_id"`
+	AfterID   int64 `json:"after_id"`
+	PageLimit int32 `json:"page_limit"`
+}
+
+// Messages of all conversations of the user after a message ID, oldest first, for clients catching up on reconnect
+func (q *Queries) ListMessagesSince(ctx context.Context, arg ListMessagesSinceParams) ([]Message, error) {
+	rows, err := q.db.QueryContext(ctx, listMessagesSince, arg.UserID, arg.AfterID, arg.PageLimit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []Message{}
+	for rows.Next() {
+		var i Message
+		if err := rows.Scan(
+			&i.ID,
+			&i.SenderID,
+			&i.ReceiverID,
+			&i.Content,
+			&i.CreatedAt,
+			&i.ReadAt,
+			&i.ContentType,
+			&i.DeletedAt,
+			&i.ReplyToMessageID,
+			&i.DeliveredAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const markMessageDelivered = `-- name: MarkMessageDelivered :one
+UPDATE messages
+SET delivered_at = now()
+WHERE id = $1 AND delivered_at IS NULL
+RETURNING delivered_at
+`
+
+// Records that a connection of the receiver accepted the message. No row if it was delivered before.
+func (q *Queries) MarkMessageDelivered(ctx context.Context, id int64) (sql.NullTime, error) {
+	row := q.db.QueryRowContext(ctx, markMessageDelivered, id)
+	var delivered_at sql.NullTime
+	err := row.Scan(&delivered_at)
+	return delivered_at, err
+}
+
+const markMessagesRead = `-- name: MarkMessagesRead :many
+UPDATE messages
+SET read_at = now(), delivered_at = COALESCE(delivered_at, now())
+WHERE sender_id = $1 AND receiver_id = $2
+  AND read_at IS NULL AND deleted_at IS NULL
+  AND ($3::bigint = 0 OR id <= $3::bigint)
+  AND (coalesce(cardinality($4::bigint[]), 0) = 0 OR id = ANY($4::bigint[]))
+RETURNING id
+`
+
+type MarkMessagesReadParams struct {
+	SenderID   int32   `json:"sender_id"`
+	ReaderID   int32   `json:"reader_id"`
+	UpToID     int64   `json:"up_to_id"`
+	MessageIds []int64 `json:"message_ids"`
+}
+
+// Marks unread messages of a conversation the reader received as read and returns their IDs:
+// those up to up_to_id (0 for no limit) that are in message_ids (empty or NULL for all)
+func (q *Queries) MarkMessagesRead(ctx context.Context, arg MarkMessagesReadParams) ([]int64, error) {
+	rows, err := q.db.QueryContext(ctx, markMessagesRead,
+		arg.SenderID,
+		arg.ReaderID,
+		arg.UpToID,
+		pq.Array(arg.MessageIds),
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []int64{}
+	for rows.Next() {
+		var id int64
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		items = append(items, id)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
@@ -5,22 +5,332 @@
 package db
 
 import (
+	"database/sql"
 	"time"
+
+	"github.com/google/uuid"
 )
 
+type Announcement struct {
+	ID        int64     `json:"id"`
+	AuthorID  int32     `json:"author_id"`
+	Content   string    `json:"content"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// One row per user that acknowledged an announcement
+type AnnouncementReceipt struct {
+	AnnouncementID int64     `json:"announcement_id"`
+	UserID         int32     `json:"user_id"`
+	SeenAt         time.Time `json:"seen_at"`
+}
+
+type ApiKey struct {
+	ID int64 `json:"id"`
+	// The account the key acts as
+	UserID int32  `json:"user_id"`
+	Name   string `json:"name"`
+	// Hex SHA-256 of the key; the key itself is only shown once
+	KeyHash   string       `json:"key_hash"`
+	CreatedAt time.Time    `json:"created_at"`
+	RevokedAt sql.NullTime `json:"revoked_at"`
+	// What the key may do: send and/or read
+	Scopes []string `json:"scopes"`
+}
+
+type ConversationArchive struct {
+	UserID    int32     `json:"user_id"`
+	PartnerID int32     `json:"partner_id"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type ConversationClear struct {
+	UserID    int32 `json:"user_id"`
+	PartnerID int32 `json:"partner_id"`
+	// Messages with an id up to and including this one are hidden from user_id
+	ClearedBeforeID int64     `json:"cleared_before_id"`
+	ClearedAt       time.Time `json:"cleared_at"`
+}
+
+type ConversationLabel struct {
+	UserID    int32     `json:"user_id"`
+	PartnerID int32     `json:"partner_id"`
+	Label     string    `json:"label"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type ConversationMute struct {
+	UserID    int32 `json:"user_id"`
+	PartnerID int32 `json:"partner_id"`
+	// NULL means muted forever
+	MutedUntil sql.NullTime `json:"muted_until"`
+	CreatedAt  time.Time    `json:"created_at"`
+}
+
+type ConversationSummary struct {
+	UserID    int32 `json:"user_id"`
+	PartnerID int32 `json:"partner_id"`
+	// The newest message of the conversation user_id can see (not deleted, not cleared)
+	LastMessageID          int64     `json:"last_message_id"`
+	LastMessageSenderID    int32     `json:"last_message_sender_id"`
+	LastMessageContent     string    `json:"last_message_content"`
+	LastMessageContentType string    `json:"last_message_content_type"`
+	LastMessageAt          time.Time `json:"last_message_at"`
+	// Messages from partner_id that user_id has not read
+	UnreadCount int32 `json:"unread_count"`
+}
+
+// Conversations in which the user does not send typing indicators to the partner
+type ConversationTypingOptOut struct {
+	UserID    int32     `json:"user_id"`
+	PartnerID int32     `json:"partner_id"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type DeferredDelivery struct {
+	MessageID   int64     `json:"message_id"`
+	SenderID    int32     `json:"sender_id"`
+	RecipientID int32     `json:"recipient_id"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+// Push notification tokens of the devices users are logged in on
+type DeviceToken struct {
+	ID     int64 `json:"id"`
+	UserID int32 `json:"user_id"`
+	// Push service of the token: fcm or apns
+	Platform  string    `json:"platform"`
+	Token     string    `json:"token"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+type LegalHold struct {
+	ID     int64 `json:"id"`
+	UserID int32 `json:"user_id"`
+	// Set for a hold on the conversation of user_id and partner_id (user_id < partner_id), NULL for a hold on all of user_id's history
+	PartnerID  sql.NullInt32 `json:"partner_id"`
+	Reason     string        `json:"reason"`
+	CreatedBy  int32         `json:"created_by"`
+	CreatedAt  time.Time     `json:"created_at"`
+	ReleasedBy sql.NullInt32 `json:"released_by"`
+	// When an admin released the hold, NULL while it is active
+	ReleasedAt sql.NullTime `json:"released_at"`
+}
+
+type LoginHistory struct {
+	ID        int64     `json:"id"`
+	UserID    int32     `json:"user_id"`
+	IpAddress string    `json:"ip_address"`
+	UserAgent string    `json:"user_agent"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
 type Message struct {
 	ID         int64     `json:"id"`
 	SenderID   int32     `json:"sender_id"`
 	ReceiverID int32     `json:"receiver_id"`
 	Content    string    `json:"content"`
 	CreatedAt  time.Time `json:"created_at"`
+	// When the receiver read the message, NULL while unread
+	ReadAt sql.NullTime `json:"read_at"`
+	// How to interpret content: text, markdown, attachment, system, poll, location, encrypted or contact_card
+	ContentType string `json:"content_type"`
+	// When the sender deleted the message for everyone, NULL otherwise
+	DeletedAt sql.NullTime `json:"deleted_at"`
+	// The message of the same conversation this one replies to, NULL otherwise
+	ReplyToMessageID sql.NullInt64 `json:"reply_to_message_id"`
+	// When a connection of the receiver first accepted the message, NULL until then
+	DeliveredAt sql.NullTime `json:"delivered_at"`
+}
+
+type ModerationWord struct {
+	ID      int64  `json:"id"`
+	Pattern string `json:"pattern"`
+	// The pattern is a regular expression (RE2 syntax) instead of a word
+	IsRegex  bool   `json:"is_regex"`
+	Severity string `json:"severity"`
+	// The admin who added the entry, NULL once deleted
+	CreatedBy sql.NullInt32 `json:"created_by"`
+	CreatedAt time.Time     `json:"created_at"`
+}
+
+// Accounts created or first used from a suspicious IP: limited sends until an admin verifies them
+type QuarantinedUser struct {
+	UserID    int32     `json:"user_id"`
+	Reason    string    `json:"reason"`
+	IpAddress string    `json:"ip_address"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type RevokedToken struct {
+	ID     uuid.UUID `json:"id"`
+	UserID int32     `json:"user_id"`
+	// When the token expires anyway; the row can be deleted from then on
+	ExpiresAt time.Time `json:"expires_at"`
+	RevokedAt time.Time `json:"revoked_at"`
+}
+
+type Room struct {
+	ID        int64     `json:"id"`
+	Name      string    `json:"name"`
+	CreatedBy int32     `json:"created_by"`
+	CreatedAt time.Time `json:"created_at"`
+	// The user who administers the room, passed on when they leave or are deactivated
+	OwnerID int32 `json:"owner_id"`
+	// Anyone can join, guests included; private rooms need an invite
+	IsPublic bool `json:"is_public"`
+}
+
+// Pending invites to private rooms; joining deletes the row
+type RoomInvite struct {
+	RoomID    int64     `json:"room_id"`
+	UserID    int32     `json:"user_id"`
+	InvitedBy int32     `json:"invited_by"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// Users currently in a room; leaving deletes the row
+type RoomMember struct {
+	RoomID   int64     `json:"room_id"`
+	UserID   int32     `json:"user_id"`
+	JoinedAt time.Time `json:"joined_at"`
+}
+
+type RoomMessage struct {
+	ID        int64     `json:"id"`
+	RoomID    int64     `json:"room_id"`
+	SenderID  int32     `json:"sender_id"`
+	Content   string    `json:"content"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// One row per refresh token; the ID is the refresh token's payload ID
+type Session struct {
+	ID        uuid.UUID `json:"id"`
+	UserID    int32     `json:"user_id"`
+	UserAgent string    `json:"user_agent"`
+	ClientIp  string    `json:"client_ip"`
+	ExpiresAt time.Time `json:"expires_at"`
+	CreatedAt time.Time `json:"created_at"`
+	// Set when the refresh token was used (rotated) or revoked
+	RevokedAt sql.NullTime `json:"revoked_at"`
+}
+
+// Public read-only links to a message or a range of messages of a conversation
+type ShareLink struct {
+	ID int64 `json:"id"`
+	// The user who shared the messages; the link shows what they can see
+	UserID         int32 `json:"user_id"`
+	PartnerID      int32 `json:"partner_id"`
+	FirstMessageID int64 `json:"first_message_id"`
+	LastMessageID  int64 `json:"last_message_id"`
+	// Hex SHA-256 of the link token; the token itself is only shown once
+	TokenHash string       `json:"token_hash"`
+	CreatedAt time.Time    `json:"created_at"`
+	ExpiresAt time.Time    `json:"expires_at"`
+	RevokedAt sql.NullTime `json:"revoked_at"`
+}
+
+type SignupIdempotencyKey struct {
+	// Idempotency-Key header sent with POST /users
+	Key       string    `json:"key"`
+	UserID    int32     `json:"user_id"`
+	Username  string    `json:"username"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type SupportTicket struct {
+	ID int64 `json:"id"`
+	// The support identity the customer wrote to
+	SupportUserID int32 `json:"support_user_id"`
+	CustomerID    int32 `json:"customer_id"`
+	// NULL until an agent claims the ticket
+	AgentID sql.NullInt32 `json:"agent_id"`
+	// open, claimed or closed
+	Status    string       `json:"status"`
+	CreatedAt time.Time    `json:"created_at"`
+	ClaimedAt sql.NullTime `json:"claimed_at"`
+	ClosedAt  sql.NullTime `json:"closed_at"`
+}
+
+type UsageDaily struct {
+	Day time.Time `json:"day"`
+	// Users who logged in or sent a message that day
+	ActiveUsers int32 `json:"active_users"`
+	Messages    int64 `json:"messages"`
+	// Size of the stored message contents when the day was last recorded
+	StorageBytes int64     `json:"storage_bytes"`
+	RecordedAt   time.Time `json:"recorded_at"`
+}
+
+type UsageMonthly struct {
+	// First day of the month
+	Month time.Time `json:"month"`
+	// Distinct users who logged in or sent a message that month
+	ActiveUsers      int32     `json:"active_users"`
+	Messages         int64     `json:"messages"`
+	PeakStorageBytes int64     `json:"peak_storage_bytes"`
+	RolledUpAt       time.Time `json:"rolled_up_at"`
 }
 
 type User struct {
 	ID       int32  `json:"id"`
 	Username string `json:"username"`
-	// Practice only!
-	PasswordPlaintext string    `json:"password_plaintext"`
-	Status            string    `json:"status"`
-	CreatedAt         time.Time `json:"created_at"`
+	// bcrypt hash of the password
+	PasswordHash string    `json:"password_hash"`
+	Status       string    `json:"status"`
+	CreatedAt    time.Time `json:"created_at"`
+	// user, admin, guest, agent, support or bot
+	Role string `json:"role"`
+	// NULL while the account is active
+	DeactivatedAt sql.NullTime `json:"deactivated_at"`
+	// Guests only: the account and its data are deleted after this time
+	ExpiresAt sql.NullTime `json:"expires_at"`
+	// When the user was last connected (refreshed while online), NULL if never connected
+	LastSeenAt sql.NullTime `json:"last_seen_at"`
+	// Who deactivated the account: the user themselves, an admin, or NULL for the identity provider
+	DeactivatedBy sql.NullInt32 `json:"deactivated_by"`
+	// The account cannot log in or connect before this time, NULL if never suspended
+	SuspendedUntil sql.NullTime `json:"suspended_until"`
+	// Why an admin suspended the account, shown to the user
+	SuspensionReason string `json:"suspension_reason"`
+	// Presence chosen by the user: available, away, busy, dnd or invisible
+	Presence string `json:"presence"`
+}
+
+type UserSetting struct {
+	UserID int32 `json:"user_id"`
+	// Senders learn when the user read their messages
+	ReadReceipts bool `json:"read_receipts"`
+	// Partners and rooms see the user typing
+	TypingIndicators bool `json:"typing_indicators"`
+	// Others see the user online and their last-seen time refreshed, as with presence other than invisible
+	OnlineVisibility bool      `json:"online_visibility"`
+	UpdatedAt        time.Time `json:"updated_at"`
+	// Local time (HH:MM) quiet hours start at, NULL without quiet hours
+	QuietHoursStart sql.NullString `json:"quiet_hours_start"`
+	// Local time (HH:MM) quiet hours end at, before the start for quiet hours over midnight
+	QuietHoursEnd sql.NullString `json:"quiet_hours_end"`
+	// IANA time zone of the quiet hours, e.g. Europe/Berlin
+	QuietHoursTimezone sql.NullString `json:"quiet_hours_timezone"`
+}
+
+type Webhook struct {
+	ID   int64  `json:"id"`
+	Name string `json:"name"`
+	// The (bot) account messages are posted as
+	UserID int32 `json:"user_id"`
+	// The room messages are posted to, NULL for a conversation
+	RoomID sql.NullInt64 `json:"room_id"`
+	// The user messages are sent to, NULL for a room
+	RecipientID sql.NullInt32 `json:"recipient_id"`
+	// Hex SHA-256 of the hook token; the token itself is only shown once
+	TokenHash string `json:"token_hash"`
+	// Messages per minute
+	RateLimit  int32        `json:"rate_limit"`
+	CreatedBy  int32        `json:"created_by"`
+	CreatedAt  time.Time    `json:"created_at"`
+	LastUsedAt sql.NullTime `json:"last_used_at"`
+	RevokedAt  sql.NullTime `json:"revoked_at"`
 }
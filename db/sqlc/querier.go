@@ -6,18 +6,200 @@ package db
 
 import (
 	"context"
+	"database/sql"
+	"time"
+
+	"github.com/google/uuid"
 )
 
 type Querier interface {
+	AddRoomMember(ctx context.Context, arg AddRoomMemberParams) (int64, error)
+	ArchiveConversation(ctx context.Context, arg ArchiveConversationParams) error
+	AssignSupportTicket(ctx context.Context, arg AssignSupportTicketParams) (SupportTicket, error)
+	ClaimSupportTicket(ctx context.Context, arg ClaimSupportTicketParams) (SupportTicket, error)
+	ClearConversation(ctx context.Context, arg ClearConversationParams) (ConversationClear, error)
+	CloseSupportTicket(ctx context.Context, id int64) (SupportTicket, error)
+	CountLoginHistory(ctx context.Context, userID int32) (int64, error)
+	CountLoginHistoryForDevice(ctx context.Context, arg CountLoginHistoryForDeviceParams) (int64, error)
+	// Private and room messages the user sent after the given time
+	CountRecentSends(ctx context.Context, arg CountRecentSendsParams) (int64, error)
+	CountUsers(ctx context.Context) (int64, error)
+	CreateAPIKey(ctx context.Context, arg CreateAPIKeyParams) (ApiKey, error)
+	CreateAnnouncement(ctx context.Context, arg CreateAnnouncementParams) (Announcement, error)
+	// Bots authenticate with API keys only: the password hash is of a random password nobody knows
+	CreateBotUser(ctx context.Context, arg CreateBotUserParams) (User, error)
+	CreateDeferredDelivery(ctx context.Context, arg CreateDeferredDeliveryParams) error
+	CreateGuestUser(ctx context.Context, arg CreateGuestUserParams) (User, error)
+	// Returns no row if the user or conversation is already on hold
+	CreateLegalHold(ctx context.Context, arg CreateLegalHoldParams) (LegalHold, error)
+	CreateLoginHistory(ctx context.Context, arg CreateLoginHistoryParams) (LoginHistory, error)
 	CreateMessage(ctx context.Context, arg CreateMessageParams) (Message, error)
+	CreateModerationWord(ctx context.Context, arg CreateModerationWordParams) (ModerationWord, error)
+	CreateRoom(ctx context.Context, arg CreateRoomParams) (Room, error)
+	// Inviting someone twice keeps the first invite
+	CreateRoomInvite(ctx context.Context, arg CreateRoomInviteParams) (int64, error)
+	CreateRoomMessage(ctx context.Context, arg CreateRoomMessageParams) (RoomMessage, error)
+	CreateSession(ctx context.Context, arg CreateSessionParams) (Session, error)
+	CreateShareLink(ctx context.Context, arg CreateShareLinkParams) (ShareLink, error)
+	CreateSignupIdempotencyKey(ctx context.Context, arg CreateSignupIdempotencyKeyParams) error
 	// db/query/user.sql
 	CreateUser(ctx context.Context, arg CreateUserParams) (User, error)
+	CreateWebhook(ctx context.Context, arg CreateWebhookParams) (Webhook, error)
+	// Already deactivated accounts keep their original deactivation time and author
+	DeactivateUser(ctx context.Context, arg DeactivateUserParams) (User, error)
+	// Soft-deletes messages the sender sent to the receiver and returns their IDs: those in
+	// message_ids, or for an empty list those from from_id to to_id
+	DeleteConversationMessages(ctx context.Context, arg DeleteConversationMessagesParams) ([]int64, error)
+	DeleteConversationMute(ctx context.Context, arg DeleteConversationMuteParams) error
+	// Takes the deferred messages of a recipient who connected
+	DeleteDeferredDeliveries(ctx context.Context, recipientID int32) ([]DeferredDelivery, error)
+	DeleteDeviceToken(ctx context.Context, token string) (int64, error)
+	DeleteExpiredConversationMutes(ctx context.Context) ([]DeleteExpiredConversationMutesRow, error)
+	// Removes expired guests together with everything that references them, in one statement.
+	// Guests on legal hold, or in a conversation on hold or with a user on hold, are kept until the hold is released.
+	DeleteExpiredGuests(ctx context.Context) ([]int32, error)
+	DeleteExpiredRevokedTokens(ctx context.Context) (int64, error)
+	DeleteExpiredSessions(ctx context.Context) (int64, error)
+	// Soft-deletes a message for both parties
+	DeleteMessage(ctx context.Context, id int64) (Message, error)
+	DeleteModerationWord(ctx context.Context, id int64) (int64, error)
+	DeleteRoomInvite(ctx context.Context, arg DeleteRoomInviteParams) (int64, error)
+	DeleteUserDeviceToken(ctx context.Context, arg DeleteUserDeviceTokenParams) (int64, error)
+	GetActiveAPIKeyByHash(ctx context.Context, keyHash string) (ApiKey, error)
+	GetActiveConversationMute(ctx context.Context, arg GetActiveConversationMuteParams) (ConversationMute, error)
+	GetActiveShareLinkByHash(ctx context.Context, tokenHash string) (ShareLink, error)
+	GetActiveWebhookByHash(ctx context.Context, tokenHash string) (Webhook, error)
+	GetLegalHold(ctx context.Context, id int64) (LegalHold, error)
+	GetMessage(ctx context.Context, id int64) (Message, error)
 	GetMessagesBetweenUsers(ctx context.Context, arg GetMessagesBetweenUsersParams) ([]Message, error)
+	GetMonthlyUsage(ctx context.Context, month time.Time) (UsageMonthly, error)
+	GetQuarantine(ctx context.Context, userID int32) (QuarantinedUser, error)
+	GetRoom(ctx context.Context, id int64) (Room, error)
+	// The member who inherits the room from its owner: the longest-standing active member, guests excluded
+	GetRoomSuccessor(ctx context.Context, arg GetRoomSuccessorParams) (int32, error)
+	GetSession(ctx context.Context, id uuid.UUID) (Session, error)
+	GetSignupIdempotencyKey(ctx context.Context, key string) (SignupIdempotencyKey, error)
+	GetSupportTicket(ctx context.Context, id int64) (SupportTicket, error)
 	GetUserByID(ctx context.Context, id int32) (User, error)
+	// Case-insensitive, like the uniqueness of usernames
 	GetUserByUsername(ctx context.Context, username string) (User, error)
-	ListOfflineUsers(ctx context.Context) ([]ListOfflineUsersRow, error)
-	ListOnlineUsers(ctx context.Context) ([]ListOnlineUsersRow, error)
+	GetUserSettings(ctx context.Context, userID int32) (UserSetting, error)
+	HideTypingFromPartner(ctx context.Context, arg HideTypingFromPartnerParams) error
+	IsRoomMember(ctx context.Context, arg IsRoomMemberParams) (bool, error)
+	IsTokenRevoked(ctx context.Context, id uuid.UUID) (bool, error)
+	// Also true when the user turned typing indicators off for everyone
+	IsTypingHiddenFromPartner(ctx context.Context, arg IsTypingHiddenFromPartnerParams) (bool, error)
+	ListActiveConversationMutes(ctx context.Context, userID int32) ([]ConversationMute, error)
+	// The user's links that still work, newest first
+	ListActiveShareLinks(ctx context.Context, arg ListActiveShareLinksParams) ([]ShareLink, error)
+	ListActiveUserIDsByRole(ctx context.Context, role string) ([]int32, error)
+	// Reach of recent announcements: seen_count out of the active, non-guest accounts that existed when it was sent
+	ListAnnouncementStats(ctx context.Context, arg ListAnnouncementStatsParams) ([]ListAnnouncementStatsRow, error)
+	// Recent announcements with whether the user has acknowledged them
+	ListAnnouncementsForUser(ctx context.Context, arg ListAnnouncementsForUserParams) ([]ListAnnouncementsForUserRow, error)
+	ListArchivedConversations(ctx context.Context, userID int32) ([]ConversationArchive, error)
+	ListConversationLabelsForPartners(ctx context.Context, arg ListConversationLabelsForPartnersParams) ([]ConversationLabel, error)
+	// One row per conversation partner with the latest message the user can see, most recently active
+	// first. Reads the summaries the triggers of migration 43 maintain, not the messages.
+	ListConversations(ctx context.Context, arg ListConversationsParams) ([]ListConversationsRow, error)
+	// The recorded days of a UTC month (given by its first day)
+	ListDailyUsage(ctx context.Context, month time.Time) ([]UsageDaily, error)
+	ListDeviceTokens(ctx context.Context, userID int32) ([]DeviceToken, error)
+	// Private messages of the user, or with a partner only those of their conversation, deleted ones
+	// included, oldest first
+	ListHeldMessages(ctx context.Context, arg ListHeldMessagesParams) ([]Message, error)
+	// Room messages the user sent, oldest first
+	ListHeldRoomMessages(ctx context.Context, arg ListHeldRoomMessagesParams) ([]RoomMessage, error)
+	// Newest first, released holds included
+	ListLegalHolds(ctx context.Context, pageLimit int32) ([]LegalHold, error)
+	ListLoginHistory(ctx context.Context, arg ListLoginHistoryParams) ([]LoginHistory, error)
+	// The messages replies quote, including deleted ones
+	ListMessagesByIDs(ctx context.Context, ids []int64) ([]Message, error)
+	// Messages of all conversations of the user after a message ID, oldest first, for clients catching up on reconnect
+	ListMessagesSince(ctx context.Context, arg ListMessagesSinceParams) ([]Message, error)
+	ListModerationWords(ctx context.Context) ([]ModerationWord, error)
+	ListMonthlyUsage(ctx context.Context, pageLimit int32) ([]UsageMonthly, error)
+	// Everyone but the given online users, and the invisible or hidden ones
+	ListOfflineUsers(ctx context.Context, arg ListOfflineUsersParams) ([]ListOfflineUsersRow, error)
+	// Presence is tracked outside the database: the caller passes the IDs of the online users.
+	// Invisible users and users who turned online visibility off are left out.
+	ListOnlineUsers(ctx context.Context, arg ListOnlineUsersParams) ([]ListOnlineUsersRow, error)
+	// Newest first, with the username for the admin list
+	ListQuarantinedUsers(ctx context.Context, pageLimit int32) ([]ListQuarantinedUsersRow, error)
+	ListRoomMemberIDs(ctx context.Context, roomID int64) ([]int32, error)
+	ListRoomMessages(ctx context.Context, arg ListRoomMessagesParams) ([]RoomMessage, error)
+	// Rooms the user is a member of, oldest first
+	ListRoomsForUser(ctx context.Context, arg ListRoomsForUserParams) ([]Room, error)
+	ListRoomsOwnedBy(ctx context.Context, ownerID int32) ([]Room, error)
+	// Messages of a conversation from first_message_id to last_message_id, oldest first, as the sharing
+	// user sees them: without deleted messages, what they cleared and end-to-end encrypted contents
+	ListSharedMessages(ctx context.Context, arg ListSharedMessagesParams) ([]ListSharedMessagesRow, error)
+	ListSupportTicketTranscript(ctx context.Context, id int64) ([]Message, error)
+	ListSupportTicketsByStatus(ctx context.Context, arg ListSupportTicketsByStatusParams) ([]SupportTicket, error)
+	ListTypingOptOuts(ctx context.Context, userID int32) ([]ConversationTypingOptOut, error)
+	// The user's labels with the number of conversations carrying each
+	ListUserLabels(ctx context.Context, userID int32) ([]ListUserLabelsRow, error)
+	// Status and last-seen time of the given users; unknown IDs are skipped
+	ListUserPresence(ctx context.Context, userIds []int32) ([]ListUserPresenceRow, error)
+	ListUsers(ctx context.Context, arg ListUsersParams) ([]User, error)
+	// Optionally filtered by role and by a part of the username, oldest first
+	ListUsersForModeration(ctx context.Context, arg ListUsersForModerationParams) ([]User, error)
+	// The given users who turned online visibility off
+	ListUsersHidingOnline(ctx context.Context, userIds []int32) ([]int32, error)
+	// The given users who turned read receipts off
+	ListUsersHidingReadReceipts(ctx context.Context, userIds []int32) ([]int32, error)
+	// Newest first, revoked webhooks included
+	ListWebhooks(ctx context.Context, pageLimit int32) ([]Webhook, error)
+	MarkAnnouncementSeen(ctx context.Context, arg MarkAnnouncementSeenParams) (int64, error)
+	// Marks unread messages the reader received from the sender as read and returns their IDs: those
+	// in message_ids, or for an empty list those from from_id to to_id
+	MarkConversationMessagesRead(ctx context.Context, arg MarkConversationMessagesReadParams) ([]int64, error)
+	// Records that a connection of the receiver accepted the message. No row if it was delivered before.
+	MarkMessageDelivered(ctx context.Context, id int64) (sql.NullTime, error)
+	// Marks unread messages of a conversation the reader received as read and returns their IDs:
+	// those up to up_to_id (0 for no limit) that are in message_ids (empty or NULL for all)
+	MarkMessagesRead(ctx context.Context, arg MarkMessagesReadParams) ([]int64, error)
+	// Returns the customer's active ticket, creating it if needed
+	OpenSupportTicket(ctx context.Context, arg OpenSupportTicketParams) (SupportTicket, error)
+	QuarantineUser(ctx context.Context, arg QuarantineUserParams) error
+	ReactivateUser(ctx context.Context, id int32) (User, error)
+	// Records the usage of a UTC day so far; recording the day again updates it
+	RecordDailyUsage(ctx context.Context, day time.Time) (UsageDaily, error)
+	ReleaseLegalHold(ctx context.Context, arg ReleaseLegalHoldParams) (LegalHold, error)
+	ReleaseQuarantine(ctx context.Context, userID int32) (int64, error)
+	RemoveRoomMember(ctx context.Context, arg RemoveRoomMemberParams) (int64, error)
+	// Returns the account of the key, whose connections are closed
+	RevokeAPIKey(ctx context.Context, id int64) (int32, error)
+	// Revokes a session once; 0 rows means it was already used or revoked
+	RevokeSession(ctx context.Context, id uuid.UUID) (int64, error)
+	RevokeShareLink(ctx context.Context, arg RevokeShareLinkParams) (int64, error)
+	RevokeToken(ctx context.Context, arg RevokeTokenParams) error
+	RevokeWebhook(ctx context.Context, id int64) (int64, error)
+	// Sums up the recorded days of a UTC month (given by its first day); active users are counted
+	// once per month
+	RollUpMonthlyUsage(ctx context.Context, month time.Time) (UsageMonthly, error)
+	// Replaces the labels of the conversation with the given ones, in one statement
+	SetConversationLabels(ctx context.Context, arg SetConversationLabelsParams) error
+	SetRoomOwner(ctx context.Context, arg SetRoomOwnerParams) (Room, error)
+	SetUserPresence(ctx context.Context, arg SetUserPresenceParams) (User, error)
+	// NULLs turn quiet hours off
+	SetUserQuietHours(ctx context.Context, arg SetUserQuietHoursParams) (UserSetting, error)
+	// Marks the given users offline at once (used on shutdown for the users still connected)
+	SetUsersOffline(ctx context.Context, userIds []int32) error
+	ShowTypingToPartner(ctx context.Context, arg ShowTypingToPartnerParams) (int64, error)
+	SuspendUser(ctx context.Context, arg SuspendUserParams) (User, error)
+	// Refreshes the last-seen time of the given connected users, except the invisible or hidden ones
+	TouchUsersLastSeen(ctx context.Context, userIds []int32) error
+	TouchWebhook(ctx context.Context, id int64) error
+	UnarchiveConversation(ctx context.Context, arg UnarchiveConversationParams) (int64, error)
+	UnsuspendUser(ctx context.Context, id int32) (User, error)
+	UpdateModerationWordSeverity(ctx context.Context, arg UpdateModerationWordSeverityParams) (ModerationWord, error)
 	UpdateUserStatus(ctx context.Context, arg UpdateUserStatusParams) error
+	UpdateUsername(ctx context.Context, arg UpdateUsernameParams) (User, error)
+	UpsertConversationMute(ctx context.Context, arg UpsertConversationMuteParams) (ConversationMute, error)
+	// A token belongs to the account last logged in on the device
+	UpsertDeviceToken(ctx context.Context, arg UpsertDeviceTokenParams) (DeviceToken, error)
+	UpsertUserSettings(ctx context.Context, arg UpsertUserSettingsParams) (UserSetting, error)
 }
 
 var _ Querier = (*Queries)(nil)
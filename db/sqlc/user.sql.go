@@ -7,39 +7,252 @@ package db
 
 import (
 	"context"
+	"database/sql"
+
+	"github.com/lib/pq"
 )
 
+const countUsers = `-- name: CountUsers :one
+SELECT count(*) FROM users
+`
+
+func (q *Queries) CountUsers(ctx context.Context) (int64, error) {
+	row := q.db.QueryRowContext(ctx, countUsers)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
+const createBotUser = `-- name: CreateBotUser :one
+INSERT INTO users (
+  username,
+  password_hash,
+  role
+) VALUES (
+  $1, $2, 'bot'
+) RETURNING id, username, password_hash, status, created_at, role, deactivated_at, expires_at, last_seen_at, deactivated_by, suspended_until, suspension_reason, presence
+`
+
+type CreateBotUserParams struct {
+	Username     string `json:"username"`
+	PasswordHash string `json:"password_hash"`
+}
+
+// Bots authenticate with API keys only: the password hash is of a random password nobody knows
+func (q *Queries) CreateBotUser(ctx context.Context, arg CreateBotUserParams) (User, error) {
+	row := q.db.QueryRowContext(ctx, createBotUser, arg.Username, arg.PasswordHash)
+	var i User
+	err := row.Scan(
+		&i.ID,
+		&i.Username,
+		&i.PasswordHash,
+		&i.Status,
+		&i.CreatedAt,
+		&i.Role,
+		&i.DeactivatedAt,
+		&i.ExpiresAt,
+		&i.LastSeenAt,
+		&i.DeactivatedBy,
+		&i.SuspendedUntil,
+		&i.SuspensionReason,
+		&i.Presence,
+	)
+	return i, err
+}
+
+const createGuestUser = `-- name: CreateGuestUser :one
+INSERT INTO users (
+  username,
+  password_hash,
+  role,
+  expires_at
+) VALUES (
+  $1, $2, 'guest', $3
+) RETURNING id, username, password_hash, status, created_at, role, deactivated_at, expires_at, last_seen_at, deactivated_by, suspended_until, suspension_reason, presence
+`
+
+type CreateGuestUserParams struct {
+	Username     string       `json:"username"`
+	PasswordHash string       `json:"password_hash"`
+	ExpiresAt    sql.NullTime `json:"expires_at"`
+}
+
+func (q *Queries) CreateGuestUser(ctx context.Context, arg CreateGuestUserParams) (User, error) {
+	row := q.db.QueryRowContext(ctx, createGuestUser, arg.Username, arg.PasswordHash, arg.ExpiresAt)
+	var i User
+	err := row.Scan(
+		&i.ID,
+		&i.Username,
+		&i.PasswordHash,
+		&i.Status,
+		&i.CreatedAt,
+		&i.Role,
+		&i.DeactivatedAt,
+		&i.ExpiresAt,
+		&i.LastSeenAt,
+		&i.DeactivatedBy,
+		&i.SuspendedUntil,
+		&i.SuspensionReason,
+		&i.Presence,
+	)
+	return i, err
+}
+
 const createUser = `-- name: CreateUser :one
 
 INSERT INTO users (
   username,
-  password_plaintext
+  password_hash
 ) VALUES (
   $1, $2
-) RETURNING id, username, password_plaintext, status, created_at
+) RETURNING id, username, password_hash, status, created_at, role, deactivated_at, expires_at, last_seen_at, deactivated_by, suspended_until, suspension_reason, presence
 `
 
 type CreateUserParams struct {
-	Username          string `json:"username"`
-	PasswordPlaintext string `json:"password_plaintext"`
+	Username     string `json:"username"`
+	PasswordHash string `json:"password_hash"`
 }
 
 // db/query/user.sql
 func (q *Queries) CreateUser(ctx context.Context, arg CreateUserParams) (User, error) {
-	row := q.db.QueryRowContext(ctx, createUser, arg.Username, arg.PasswordPlaintext)
+	row := q.db.QueryRowContext(ctx, createUser, arg.Username, arg.PasswordHash)
+	var i User
+	err := row.Scan(
+		&i.ID,
+		&i.Username,
+		&i.PasswordHash,
+		&i.Status,
+		&i.CreatedAt,
+		&i.Role,
+		&i.DeactivatedAt,
+		&i.ExpiresAt,
+		&i.LastSeenAt,
+		&i.DeactivatedBy,
+		&i.SuspendedUntil,
+		&i.SuspensionReason,
+		&i.Presence,
+	)
+	return i, err
+}
+
+const deactivateUser = `-- name: DeactivateUser :one
+UPDATE users
+SET deactivated_at = COALESCE(deactivated_at, now()),
+    deactivated_by = CASE WHEN deactivated_at IS NULL THEN $1 ELSE deactivated_by END,
+    status = 'offline'
+WHERE id = $2
+RETURNING id, username, password_hash, status, created_at, role, deactivated_at, expires_at, last_seen_at, deactivated_by, suspended_until, suspension_reason, presence
+`
+
+type DeactivateUserParams struct {
+	DeactivatedBy sql.NullInt32 `json:"deactivated_by"`
+	ID            int32         `json:"id"`
+}
+
+// Already deactivated accounts keep their original deactivation time and author
+func (q *Queries) DeactivateUser(ctx context.Context, arg DeactivateUserParams) (User, error) {
+	row := q.db.QueryRowContext(ctx, deactivateUser, arg.DeactivatedBy, arg.ID)
 	var i User
 	err := row.Scan(
 		&i.ID,
 		&i.Username,
-		&i.PasswordPlaintext,
+		&i.PasswordHash,
 		&i.Status,
 		&i.CreatedAt,
+		&i.Role,
+		&i.DeactivatedAt,
+		&i.ExpiresAt,
+		&i.LastSeenAt,
+		&i.DeactivatedBy,
+		&i.SuspendedUntil,
+		&i.SuspensionReason,
+		&i.Presence,
 	)
 	return i, err
 }
 
+const deleteExpiredGuests = `-- name: DeleteExpiredGuests :many
+WITH expired AS (
+  SELECT id FROM users
+  WHERE role = 'guest' AND expires_at <= now()
+    AND NOT EXISTS (
+      SELECT 1 FROM legal_holds h
+      WHERE h.released_at IS NULL
+        AND (h.user_id = users.id OR h.partner_id = users.id
+          -- A held user's conversation with the guest
+          OR (h.partner_id IS NULL AND EXISTS (
+            SELECT 1 FROM messages m
+            WHERE (m.sender_id = users.id AND m.receiver_id = h.user_id)
+               OR (m.sender_id = h.user_id AND m.receiver_id = users.id)
+          )))
+    )
+), deleted_messages AS (
+  DELETE FROM messages
+  WHERE sender_id IN (SELECT id FROM expired) OR receiver_id IN (SELECT id FROM expired)
+), deleted_login_history AS (
+  DELETE FROM login_history
+  WHERE user_id IN (SELECT id FROM expired)
+), deleted_mutes AS (
+  DELETE FROM conversation_mutes
+  WHERE user_id IN (SELECT id FROM expired) OR partner_id IN (SELECT id FROM expired)
+), deleted_archives AS (
+  DELETE FROM conversation_archives
+  WHERE user_id IN (SELECT id FROM expired) OR partner_id IN (SELECT id FROM expired)
+), deleted_clears AS (
+  DELETE FROM conversation_clears
+  WHERE user_id IN (SELECT id FROM expired) OR partner_id IN (SELECT id FROM expired)
+), deleted_typing_opt_outs AS (
+  DELETE FROM conversation_typing_opt_outs
+  WHERE user_id IN (SELECT id FROM expired) OR partner_id IN (SELECT id FROM expired)
+), deleted_support_tickets AS (
+  DELETE FROM support_tickets
+  WHERE customer_id IN (SELECT id FROM expired)
+), deleted_announcement_receipts AS (
+  DELETE FROM announcement_receipts
+  WHERE user_id IN (SELECT id FROM expired)
+), deleted_room_members AS (
+  DELETE FROM room_members
+  WHERE user_id IN (SELECT id FROM expired)
+), deleted_room_messages AS (
+  DELETE FROM room_messages
+  WHERE sender_id IN (SELECT id FROM expired)
+), deleted_webhooks AS (
+  -- Webhooks cannot post as guests, but were created for guest recipients before that was refused
+  DELETE FROM webhooks
+  WHERE recipient_id IN (SELECT id FROM expired)
+)
+DELETE FROM users
+WHERE id IN (SELECT id FROM expired)
+RETURNING id
+`
+
+// Removes expired guests together with everything that references them, in one statement.
+// Guests on legal hold, or in a conversation on hold or with a user on hold, are kept until the hold is released.
+func (q *Queries) DeleteExpiredGuests(ctx context.Context) ([]int32, error) {
+	rows, err := q.db.QueryContext(ctx, deleteExpiredGuests)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []int32{}
+	for rows.Next() {
+		var id int32
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		items = append(items, id)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const getUserByID = `-- name: GetUserByID :one
-SELECT id, username, password_plaintext, status, created_at FROM users
+SELECT id, username, password_hash, status, created_at, role, deactivated_at, expires_at, last_seen_at, deactivated_by, suspended_until, suspension_reason, presence FROM users
 WHERE id = $1 LIMIT 1
 `
 
@@ -49,44 +262,100 @@ func (q *Queries) GetUserByID(ctx context.Context, id int32) (User, error) {
 	err := row.Scan(
 		&i.ID,
 		&i.Username,
-		&i.PasswordPlaintext,
+		&i.PasswordHash,
 		&i.Status,
 		&i.CreatedAt,
+		&i.Role,
+		&i.DeactivatedAt,
+		&i.ExpiresAt,
+		&i.LastSeenAt,
+		&i.DeactivatedBy,
+		&i.SuspendedUntil,
+		&i.SuspensionReason,
+		&i.Presence,
 	)
 	return i, err
 }
 
 const getUserByUsername = `-- name: GetUserByUsername :one
-SELECT id, username, password_plaintext, status, created_at FROM users
-WHERE username = $1 LIMIT 1
+SELECT id, username, password_hash, status, created_at, role, deactivated_at, expires_at, last_seen_at, deactivated_by, suspended_until, suspension_reason, presence FROM users
+WHERE lower(username) = lower($1) LIMIT 1
 `
 
+// Case-insensitive, like the uniqueness of usernames
 func (q *Queries) GetUserByUsername(ctx context.Context, username string) (User, error) {
 	row := q.db.QueryRowContext(ctx, getUserByUsername, username)
 	var i User
 	err := row.Scan(
 		&i.ID,
 		&i.Username,
-		&i.PasswordPlaintext,
+		&i.PasswordHash,
 		&i.Status,
 		&i.CreatedAt,
+		&i.Role,
+		&i.DeactivatedAt,
+		&i.ExpiresAt,
+		&i.LastSeenAt,
+		&i.DeactivatedBy,
+		&i.SuspendedUntil,
+		&i.SuspensionReason,
+		&i.Presence,
 	)
 	return i, err
 }
 
+const listActiveUserIDsByRole = `-- name: ListActiveUserIDsByRole :many
+SELECT id FROM users
+WHERE role = $1 AND deactivated_at IS NULL
+ORDER BY id
+`
+
+func (q *Queries) ListActiveUserIDsByRole(ctx context.Context, role string) ([]int32, error) {
+	rows, err := q.db.QueryContext(ctx, listActiveUserIDsByRole, role)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []int32{}
+	for rows.Next() {
+		var id int32
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		items = append(items, id)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const listOfflineUsers = `-- name: ListOfflineUsers :many
-SELECT id, username FROM users
-WHERE status = 'offline'
+SELECT id, username, last_seen_at FROM users
+WHERE (NOT (id = ANY($1::int[])) OR presence = 'invisible'
+  OR id IN (SELECT user_id FROM user_settings WHERE NOT online_visibility)) AND username > $2
 ORDER BY username
+LIMIT $3
 `
 
+type ListOfflineUsersParams struct {
+	OnlineUserIds []int32 `json:"online_user_ids"`
+	AfterUsername string  `json:"after_username"`
+	PageLimit     int32   `json:"page_limit"`
+}
+
 type ListOfflineUsersRow struct {
-	ID       int32  `json:"id"`
-	Username string `json:"username"`
+	ID         int32        `json:"id"`
+	Username   string       `json:"username"`
+	LastSeenAt sql.NullTime `json:"last_seen_at"`
 }
 
-func (q *Queries) ListOfflineUsers(ctx context.Context) ([]ListOfflineUsersRow, error) {
-	rows, err := q.db.QueryContext(ctx, listOfflineUsers)
+// Everyone but the given online users, and the invisible or hidden ones
+func (q *Queries) ListOfflineUsers(ctx context.Context, arg ListOfflineUsersParams) ([]ListOfflineUsersRow, error) {
+	rows, err := q.db.QueryContext(ctx, listOfflineUsers, pq.Array(arg.OnlineUserIds), arg.AfterUsername, arg.PageLimit)
 	if err != nil {
 		return nil, err
 	}
@@ -94,7 +363,7 @@ func (q *Queries) ListOfflineUsers(ctx context.Context) ([]ListOfflineUsersRow,
 	items := []ListOfflineUsersRow{}
 	for rows.Next() {
 		var i ListOfflineUsersRow
-		if err := rows.Scan(&i.ID, &i.Username); err != nil {
+		if err := rows.Scan(&i.ID, &i.Username, &i.LastSeenAt); err != nil {
 			return nil, err
 		}
 		items = append(items, i)
@@ -109,18 +378,29 @@ func (q *Queries) ListOfflineUsers(ctx context.Context) ([]ListOfflineUsersRow,
 }
 
 const listOnlineUsers = `-- name: ListOnlineUsers :many
-SELECT id, username FROM users
-WHERE status = 'online'
+SELECT id, username, presence FROM users
+WHERE id = ANY($1::int[]) AND presence <> 'invisible' AND id NOT IN (SELECT user_id FROM user_settings WHERE NOT online_visibility)
+  AND username > $2
 ORDER BY username
+LIMIT $3
 `
 
+type ListOnlineUsersParams struct {
+	OnlineUserIds []int32 `json:"online_user_ids"`
+	AfterUsername string  `json:"after_username"`
+	PageLimit     int32   `json:"page_limit"`
+}
+
 type ListOnlineUsersRow struct {
 	ID       int32  `json:"id"`
 	Username string `json:"username"`
+	Presence string `json:"presence"`
 }
 
-func (q *Queries) ListOnlineUsers(ctx context.Context) ([]ListOnlineUsersRow, error) {
-	rows, err := q.db.QueryContext(ctx, listOnlineUsers)
+// Presence is tracked outside the database: the caller passes the IDs of the online users.
+// Invisible users and users who turned online visibility off are left out.
+func (q *Queries) ListOnlineUsers(ctx context.Context, arg ListOnlineUsersParams) ([]ListOnlineUsersRow, error) {
+	rows, err := q.db.QueryContext(ctx, listOnlineUsers, pq.Array(arg.OnlineUserIds), arg.AfterUsername, arg.PageLimit)
 	if err != nil {
 		return nil, err
 	}
@@ -128,7 +408,157 @@ func (q *Queries) ListOnlineUsers(ctx context.Context) ([]ListOnlineUsersRow, er
 	items := []ListOnlineUsersRow{}
 	for rows.Next() {
 		var i ListOnlineUsersRow
-		if err := rows.Scan(&i.ID, &i.Username); err != nil {
+		if err := rows.Scan(&i.ID, &i.Username, &i.Presence); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listUserPresence = `-- name: ListUserPresence :many
+SELECT id, status, last_seen_at, presence FROM users
+WHERE id = ANY($1::int[])
+ORDER BY id
+`
+
+type ListUserPresenceRow struct {
+	ID         int32        `json:"id"`
+	Status     string       `json:"status"`
+	LastSeenAt sql.NullTime `json:"last_seen_at"`
+	Presence   string       `json:"presence"`
+}
+
+// Status and last-seen time of the given users; unknown IDs are skipped
+func (q *Queries) ListUserPresence(ctx context.Context, userIds []int32) ([]ListUserPresenceRow, error) {
+	rows, err := q.db.QueryContext(ctx, listUserPresence, pq.Array(userIds))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []ListUserPresenceRow{}
+	for rows.Next() {
+		var i ListUserPresenceRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.Status,
+			&i.LastSeenAt,
+			&i.Presence,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listUsers = `-- name: ListUsers :many
+SELECT id, username, password_hash, status, created_at, role, deactivated_at, expires_at, last_seen_at, deactivated_by, suspended_until, suspension_reason, presence FROM users
+ORDER BY id
+LIMIT $1
+OFFSET $2
+`
+
+type ListUsersParams struct {
+	Limit  int32 `json:"limit"`
+	Offset int32 `json:"offset"`
+}
+
+func (q *Queries) ListUsers(ctx context.Context, arg ListUsersParams) ([]User, error) {
+	rows, err := q.db.QueryContext(ctx, listUsers, arg.Limit, arg.Offset)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []User{}
+	for rows.Next() {
+		var i User
+		if err := rows.Scan(
+			&i.ID,
+			&i.Username,
+			&i.PasswordHash,
+			&i.Status,
+			&i.CreatedAt,
+			&i.Role,
+			&i.DeactivatedAt,
+			&i.ExpiresAt,
+			&i.LastSeenAt,
+			&i.DeactivatedBy,
+			&i.SuspendedUntil,
+			&i.SuspensionReason,
+			&i.Presence,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listUsersForModeration = `-- name: ListUsersForModeration :many
+SELECT id, username, password_hash, status, created_at, role, deactivated_at, expires_at, last_seen_at, deactivated_by, suspended_until, suspension_reason, presence FROM users
+WHERE id > $1::int
+  AND ($2::text = '' OR role = $2::text)
+  AND ($3::text = '' OR strpos(lower(username), lower($3::text)) > 0)
+ORDER BY id
+LIMIT $4
+`
+
+type ListUsersForModerationParams struct {
+	AfterID      int32  `json:"after_id"`
+	Role         string `json:"role"`
+	UsernamePart string `json:"username_part"`
+	PageLimit    int32  `json:"page_limit"`
+}
+
+// Optionally filtered by role and by a part of the username, oldest first
+func (q *Queries) ListUsersForModeration(ctx context.Context, arg ListUsersForModerationParams) ([]User, error) {
+	rows, err := q.db.QueryContext(ctx, listUsersForModeration,
+		arg.AfterID,
+		arg.Role,
+		arg.UsernamePart,
+		arg.PageLimit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []User{}
+	for rows.Next() {
+		var i User
+		if err := rows.Scan(
+			&i.ID,
+			&i.Username,
+			&i.PasswordHash,
+			&i.Status,
+			&i.CreatedAt,
+			&i.Role,
+			&i.DeactivatedAt,
+			&i.ExpiresAt,
+			&i.LastSeenAt,
+			&i.DeactivatedBy,
+			&i.SuspendedUntil,
+			&i.SuspensionReason,
+			&i.Presence,
+		); err != nil {
 			return nil, err
 		}
 		items = append(items, i)
@@ -142,9 +572,163 @@ func (q *Queries) ListOnlineUsers(ctx context.Context) ([]ListOnlineUsersRow, er
 	return items, nil
 }
 
+const reactivateUser = `-- name: ReactivateUser :one
+UPDATE users
+SET deactivated_at = NULL,
+    deactivated_by = NULL
+WHERE id = $1
+RETURNING id, username, password_hash, status, created_at, role, deactivated_at, expires_at, last_seen_at, deactivated_by, suspended_until, suspension_reason, presence
+`
+
+func (q *Queries) ReactivateUser(ctx context.Context, id int32) (User, error) {
+	row := q.db.QueryRowContext(ctx, reactivateUser, id)
+	var i User
+	err := row.Scan(
+		&i.ID,
+		&i.Username,
+		&i.PasswordHash,
+		&i.Status,
+		&i.CreatedAt,
+		&i.Role,
+		&i.DeactivatedAt,
+		&i.ExpiresAt,
+		&i.LastSeenAt,
+		&i.DeactivatedBy,
+		&i.SuspendedUntil,
+		&i.SuspensionReason,
+		&i.Presence,
+	)
+	return i, err
+}
+
+const setUserPresence = `-- name: SetUserPresence :one
+UPDATE users
+SET presence = $2
+WHERE id = $1
+RETURNING id, username, password_hash, status, created_at, role, deactivated_at, expires_at, last_seen_at, deactivated_by, suspended_until, suspension_reason, presence
+`
+
+type SetUserPresenceParams struct {
+	ID       int32  `json:"id"`
+	Presence string `json:"presence"`
+}
+
+func (q *Queries) SetUserPresence(ctx context.Context, arg SetUserPresenceParams) (User, error) {
+	row := q.db.QueryRowContext(ctx, setUserPresence, arg.ID, arg.Presence)
+	var i User
+	err := row.Scan(
+		&i.ID,
+		&i.Username,
+		&i.PasswordHash,
+		&i.Status,
+		&i.CreatedAt,
+		&i.Role,
+		&i.DeactivatedAt,
+		&i.ExpiresAt,
+		&i.LastSeenAt,
+		&i.DeactivatedBy,
+		&i.SuspendedUntil,
+		&i.SuspensionReason,
+		&i.Presence,
+	)
+	return i, err
+}
+
+const setUsersOffline = `-- name: SetUsersOffline :exec
+UPDATE users
+SET status = 'offline',
+    last_seen_at = now()
+WHERE id = ANY($1::int[])
+`
+
+// Marks the given users offline at once (used on shutdown for the users still connected)
+func (q *Queries) SetUsersOffline(ctx context.Context, userIds []int32) error {
+	_, err := q.db.ExecContext(ctx, setUsersOffline, pq.Array(userIds))
+	return err
+}
+
+const suspendUser = `-- name: SuspendUser :one
+UPDATE users
+SET suspended_until = $1,
+    suspension_reason = $2,
+    status = 'offline'
+WHERE id = $3
+RETURNING id, username, password_hash, status, created_at, role, deactivated_at, expires_at, last_seen_at, deactivated_by, suspended_until, suspension_reason, presence
+`
+
+type SuspendUserParams struct {
+	SuspendedUntil   sql.NullTime `json:"suspended_until"`
+	SuspensionReason string       `json:"suspension_reason"`
+	ID               int32        `json:"id"`
+}
+
+func (q *Queries) SuspendUser(ctx context.Context, arg SuspendUserParams) (User, error) {
+	row := q.db.QueryRowContext(ctx, suspendUser, arg.SuspendedUntil, arg.SuspensionReason, arg.ID)
+	var i User
+	err := row.Scan(
+		&i.ID,
+		&i.Username,
+		&i.PasswordHash,
+		&i.Status,
+		&i.CreatedAt,
+		&i.Role,
+		&i.DeactivatedAt,
+		&i.ExpiresAt,
+		&i.LastSeenAt,
+		&i.DeactivatedBy,
+		&i.SuspendedUntil,
+		&i.SuspensionReason,
+		&i.Presence,
+	)
+	return i, err
+}
+
+const touchUsersLastSeen = `-- name: TouchUsersLastSeen :exec
+UPDATE users
+SET last_seen_at = now()
+WHERE id = ANY($1::int[]) AND presence <> 'invisible'
+  AND id NOT IN (SELECT user_id FROM user_settings WHERE NOT online_visibility)
+`
+
+// Refreshes the last-seen time of the given connected users, except the invisible or hidden ones
+func (q *Queries) TouchUsersLastSeen(ctx context.Context, userIds []int32) error {
+	_, err := q.db.ExecContext(ctx, touchUsersLastSeen, pq.Array(userIds))
+	return err
+}
+
+const unsuspendUser = `-- name: UnsuspendUser :one
+UPDATE users
+SET suspended_until = NULL,
+    suspension_reason = ''
+WHERE id = $1
+RETURNING id, username, password_hash, status, created_at, role, deactivated_at, expires_at, last_seen_at, deactivated_by, suspended_until, suspension_reason, presence
+`
+
+func (q *Queries) UnsuspendUser(ctx context.Context, id int32) (User, error) {
+	row := q.db.QueryRowContext(ctx, unsuspendUser, id)
+	var i User
+	err := row.Scan(
+		&i.ID,
+		&i.Username,
+		&i.PasswordHash,
+		&i.Status,
+		&i.CreatedAt,
+		&i.Role,
+		&i.DeactivatedAt,
+		&i.ExpiresAt,
+		&i.LastSeenAt,
+		&i.DeactivatedBy,
+		&i.SuspendedUntil,
+		&i.SuspensionReason,
+		&i.Presence,
+	)
+	return i, err
+}
+
 const updateUserStatus = `-- name: UpdateUserStatus :exec
 UPDATE users
-SET status = $2
+SET status = $2,
+    last_seen_at = now()
 WHERE id = $1
 `
 
@@ -157,3 +741,36 @@ func (q *Queries) UpdateUserStatus(ctx context.Context, arg UpdateUserStatusPara
 	_, err := q.db.ExecContext(ctx, updateUserStatus, arg.ID, arg.Status)
 	return err
 }
+
+const updateUsername = `-- name: UpdateUsername :one
+UPDATE users
+SET username = $2
+WHERE id = $1
+RETURNING id, username, password_hash, status, created_at, role, deactivated_at, expires_at, last_seen_at, deactivated_by, suspended_until, suspension_reason, presence
+`
+
+type UpdateUsernameParams struct {
+	ID       int32  `json:"id"`
+	Username string `json:"username"`
+}
+
+func (q *Queries) UpdateUsername(ctx context.Context, arg UpdateUsernameParams) (User, error) {
+	row := q.db.QueryRowContext(ctx, updateUsername, arg.ID, arg.Username)
+	var i User
+	err := row.Scan(
+		&i.ID,
+		&i.Username,
+		&i.PasswordHash,
+		&i.Status,
+		&i.CreatedAt,
+		&i.Role,
+		&i.DeactivatedAt,
+		&i.ExpiresAt,
+		&i.LastSeenAt,
+		&i.DeactivatedBy,
+		&i.SuspendedUntil,
+		&i.SuspensionReason,
+		&i.Presence,
+	)
+	return i, err
+}
@@ -0,0 +1,38 @@
+package db_test
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+
+	db "websocket-simple-chat-app/db/sqlc"
+	"websocket-simple-chat-app/internal/testutil"
+)
+
+// Reactivated accounts must not keep the author of their deactivation, which decides who may
+// reactivate them next time (see deactivation.go)
+func TestReactivateUserClearsDeactivatedBy(t *testing.T) {
+	database := testutil.NewPostgres(t)
+	ctx := context.Background()
+	admin := testutil.CreateUser(t, database.Store, "admin")
+	user := testutil.CreateUser(t, database.Store, "user")
+
+	deactivated, err := database.Store.DeactivateUser(ctx, db.DeactivateUserParams{
+		DeactivatedBy: sql.NullInt32{Int32: admin.ID, Valid: true},
+		ID:            user.ID,
+	})
+	if err != nil {
+		t.Fatalf("DeactivateUser: %v", err)
+	}
+	if !deactivated.DeactivatedAt.Valid || deactivated.DeactivatedBy.Int32 != admin.ID {
+		t.Fatalf("deactivated user has deactivated_at %v, deactivated_by %v", deactivated.DeactivatedAt, deactivated.DeactivatedBy)
+	}
+
+	reactivated, err := database.Store.ReactivateUser(ctx, user.ID)
+	if err != nil {
+		t.Fatalf("ReactivateUser: %v", err)
+	}
+	if reactivated.DeactivatedAt.Valid || reactivated.DeactivatedBy.Valid {
+		t.Fatalf("reactivated user has deactivated_at %v, deactivated_by %v", reactivated.DeactivatedAt, reactivated.DeactivatedBy)
+	}
+}
@@ -0,0 +1,216 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"log"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	db "websocket-simple-chat-app/db/sqlc"
+	"websocket-simple-chat-app/hub"
+	"websocket-simple-chat-app/token"
+	"websocket-simple-chat-app/util/password"
+)
+
+// Deactivation is a reversible disable: the account keeps its messages, rooms and settings, but it
+// cannot log in, refresh tokens or connect. Its open connections are closed with wsCloseBanned, which
+// takes the user offline. Users who deactivated themselves can reactivate with their password;
+// accounts deactivated by an admin or the identity provider (SCIM) only by them.
+
+// deactivateAccount deactivates a user and closes their connections. deactivatedBy is the acting
+// user, or NULL for the identity provider.
+func deactivateAccount(store *db.Queries, connectionHub *hub.Hub, userID int32, deactivatedBy sql.NullInt32) (db.User, error) {
+	user, err := store.DeactivateUser(context.Background(), db.DeactivateUserParams{
+		ID:            userID,
+		DeactivatedBy: deactivatedBy,
+	})
+	if err != nil {
+		return db.User{}, err
+	}
+
+	connectionHub.DisconnectUser(userID, wsCloseBanned, "account deactivated")
+	passOnOwnedRooms(store, connectionHub, userID)
+	return user, nil
+}
+
+// selfDeactivated reports whether the user deactivated their own account (and may reactivate it)
+func selfDeactivated(user db.User) bool {
+	return user.DeactivatedAt.Valid && user.DeactivatedBy.Valid && user.DeactivatedBy.Int32 == user.ID
+}
+
+// deactivationResponse is returned by the deactivation endpoints
+func deactivationResponse(user db.User) gin.H {
+	response := gin.H{"user_id": user.ID, "active": !user.DeactivatedAt.Valid}
+	if user.DeactivatedAt.Valid {
+		response["deactivated_at"] = user.DeactivatedAt.Time
+	}
+	return response
+}
+
+// --- Self-Service ---
+
+// deactivateSelfHandler deactivates the authenticated user's account. The password is asked again
+// so a stolen token alone cannot lock the owner out.
+func deactivateSelfHandler(store *db.Queries, connectionHub *hub.Hub) gin.HandlerFunc {
+	type deactivateSelfRequest struct {
+		Password string `json:"password" binding:"required"`
+	}
+	return func(c *gin.Context) {
+		payload := c.MustGet(authorizationPayloadKey).(*token.Payload)
+
+		var req deactivateSelfRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		user, err := store.GetUserByID(context.Background(), payload.UserID)
+		if err != nil {
+			log.Printf("Error fetching user %d for deactivation: %v", payload.UserID, err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to deactivate account"})
+			return
+		}
+		if user.Role == roleGuest {
+			c.JSON(http.StatusForbidden, gin.H{"error": "Guest accounts cannot be deactivated"})
+			return
+		}
+		if err := password.Check(req.Password, user.PasswordHash); err != nil {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid credentials"})
+			return
+		}
+
+		user, err = deactivateAccount(store, connectionHub, user.ID, sql.NullInt32{Int32: user.ID, Valid: true})
+		if err != nil {
+			log.Printf("Error deactivating user %d: %v", payload.UserID, err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to deactivate account"})
+			return
+		}
+
+		log.Printf("User %s (ID: %d) deactivated their account", user.Username, user.ID)
+		c.JSON(http.StatusOK, deactivationResponse(user))
+	}
+}
+
+// reactivateSelfHandler reactivates an account its owner deactivated. It is public, as deactivated
+// users cannot log in: the credentials are checked like on login.
+func reactivateSelfHandler(store *db.Queries) gin.HandlerFunc {
+	type reactivateSelfRequest struct {
+		Username string `json:"username" binding:"required"`
+		Password string `json:"password" binding:"required"`
+	}
+	return func(c *gin.Context) {
+		var req reactivateSelfRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		user, err := store.GetUserByUsername(context.Background(), req.Username)
+		if err != nil {
+			if err == sql.ErrNoRows {
+				c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid credentials"})
+				return
+			}
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to reactivate account"})
+			return
+		}
+		if err := password.Check(req.Password, user.PasswordHash); err != nil {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid credentials"})
+			return
+		}
+
+		if !user.DeactivatedAt.Valid {
+			c.JSON(http.StatusOK, deactivationResponse(user)) // Already active
+			return
+		}
+		if !selfDeactivated(user) {
+			c.JSON(http.StatusForbidden, gin.H{"error": "Account was deactivated by an administrator"})
+			return
+		}
+
+		reactivated, err := store.ReactivateUser(context.Background(), user.ID)
+		if err != nil {
+			log.Printf("Error reactivating user %d: %v", user.ID, err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to reactivate account"})
+			return
+		}
+
+		log.Printf("User %s (ID: %d) reactivated their account", reactivated.Username, reactivated.ID)
+		c.JSON(http.StatusOK, deactivationResponse(reactivated))
+	}
+}
+
+// --- Admin ---
+
+// parseUserIDParam loads the user referenced by the :user_id path parameter, writing the error response if needed
+func parseUserIDParam(c *gin.Context, store *db.Queries) (db.User, bool) {
+	userID, err := strconv.ParseInt(c.Param("user_id"), 10, 32)
+	if err != nil || userID <= 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid 'user_id' format"})
+		return db.User{}, false
+	}
+
+	user, err := store.GetUserByID(context.Background(), int32(userID))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+			return db.User{}, false
+		}
+		log.Printf("Error fetching user %d: %v", userID, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch user"})
+		return db.User{}, false
+	}
+
+	return user, true
+}
+
+// adminDeactivateUserHandler deactivates any other account. The owner cannot reactivate it themselves.
+func adminDeactivateUserHandler(store *db.Queries, connectionHub *hub.Hub) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		payload := c.MustGet(authorizationPayloadKey).(*token.Payload)
+
+		target, ok := parseUserIDParam(c, store)
+		if !ok {
+			return
+		}
+		if target.ID == payload.UserID {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Admins cannot deactivate their own account here"})
+			return
+		}
+
+		user, err := deactivateAccount(store, connectionHub, target.ID, sql.NullInt32{Int32: payload.UserID, Valid: true})
+		if err != nil {
+			log.Printf("Error deactivating user %d: %v", target.ID, err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to deactivate user"})
+			return
+		}
+
+		log.Printf("Admin %d deactivated user %s (ID: %d)", payload.UserID, user.Username, user.ID)
+		c.JSON(http.StatusOK, deactivationResponse(user))
+	}
+}
+
+// adminReactivateUserHandler reactivates an account, whoever deactivated it
+func adminReactivateUserHandler(store *db.Queries) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		payload := c.MustGet(authorizationPayloadKey).(*token.Payload)
+
+		target, ok := parseUserIDParam(c, store)
+		if !ok {
+			return
+		}
+
+		user, err := store.ReactivateUser(context.Background(), target.ID)
+		if err != nil {
+			log.Printf("Error reactivating user %d: %v", target.ID, err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to reactivate user"})
+			return
+		}
+
+		log.Printf("Admin %d reactivated user %s (ID: %d)", payload.UserID, user.Username, user.ID)
+		c.JSON(http.StatusOK, deactivationResponse(user))
+	}
+}
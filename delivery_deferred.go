@@ -0,0 +1,73 @@
+package main
+
+import (
+	"context"
+	"log"
+	"sort"
+	"time"
+
+	db "websocket-simple-chat-app/db/sqlc"
+	"websocket-simple-chat-app/hub"
+	"websocket-simple-chat-app/presence"
+)
+
+// A private message that reached neither a connection nor a device of its recipient (offline
+// without push notifications, or every push failed) is deferred: its sender gets a
+// delivery_deferred event, and a delivered event once the recipient connects and can fetch it.
+
+// DeliveryStatusMessage tells the sender of a private message how its delivery went on after the ack
+//
+//wsschema:server
+type DeliveryStatusMessage struct {
+	Type        string    `json:"type"` // "delivery_deferred" or "delivered"
+	MessageID   int64     `json:"message_id"`
+	RecipientID int32     `json:"recipient_id"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+// deferDelivery records the message as deferred and tells its sender, unless the recipient is
+// connected to another instance by now (the relay delivers it there)
+func deferDelivery(store *db.Queries, connectionHub *hub.Hub, presenceTracker presence.Tracker, message db.Message) {
+	online, err := presenceTracker.AreOnline(context.Background(), []int32{message.ReceiverID})
+	if err == nil && online[message.ReceiverID] {
+		return
+	}
+
+	err = store.CreateDeferredDelivery(context.Background(), db.CreateDeferredDeliveryParams{
+		MessageID:   message.ID,
+		SenderID:    message.SenderID,
+		RecipientID: message.ReceiverID,
+	})
+	if err != nil {
+		log.Printf("Error recording deferred delivery of message %d: %v", message.ID, err)
+		return
+	}
+	log.Printf("Delivery of message %d to user %d deferred", message.ID, message.ReceiverID)
+	sendJSONToUser(connectionHub, message.SenderID, DeliveryStatusMessage{
+		Type:        "delivery_deferred",
+		MessageID:   message.ID,
+		RecipientID: message.ReceiverID,
+		CreatedAt:   time.Now().UTC(),
+	})
+}
+
+// reportDeferredDeliveries tells the senders of the messages deferred for a user who just
+// connected that they are delivered now
+func reportDeferredDeliveries(store *db.Queries, connectionHub *hub.Hub, recipientID int32) {
+	deliveries, err := store.DeleteDeferredDeliveries(context.Background(), recipientID)
+	if err != nil {
+		log.Printf("Error taking the deferred deliveries of user %d: %v", recipientID, err)
+		return
+	}
+	sort.Slice(deliveries, func(i, j int) bool { return deliveries[i].MessageID < deliveries[j].MessageID })
+
+	now := time.Now().UTC()
+	for _, delivery := range deliveries {
+		sendJSONToUser(connectionHub, delivery.SenderID, DeliveryStatusMessage{
+			Type:        "delivered",
+			MessageID:   delivery.MessageID,
+			RecipientID: recipientID,
+			CreatedAt:   now,
+		})
+	}
+}
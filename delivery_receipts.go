@@ -0,0 +1,74 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"log"
+	"time"
+
+	db "websocket-simple-chat-app/db/sqlc"
+	"websocket-simple-chat-app/hub"
+)
+
+// Private messages go through three states: stored, delivered once a connection of the recipient
+// accepted the frame, and read. The sender gets a delivery_receipt when a message is delivered in
+// real time on this instance; reading a message marks it delivered too.
+const (
+	messageStateStored    = "stored"
+	messageStateDelivered = "delivered"
+	messageStateRead      = "read"
+)
+
+// DeliveryReceiptMessage is sent to the sender of a private message once a connection of the
+// recipient accepted it
+//
+//wsschema:server
+type DeliveryReceiptMessage struct {
+	Type        string    `json:"type"` // "delivery_receipt"
+	MessageID   int64     `json:"message_id"`
+	RecipientID int32     `json:"recipient_id"`
+	DeliveredAt time.Time `json:"delivered_at"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+// messageState returns the delivery state of a message
+func messageState(message db.Message) string {
+	switch {
+	case message.ReadAt.Valid:
+		return messageStateRead
+	case message.DeliveredAt.Valid:
+		return messageStateDelivered
+	default:
+		return messageStateStored
+	}
+}
+
+// markMessageDelivered records the delivery of a message and sends the receipt to its sender.
+// Only the first delivery counts: the recipient's other connections do not send another receipt.
+func markMessageDelivered(store *db.Queries, connectionHub *hub.Hub, message db.Message) {
+	deliveredAt, err := store.MarkMessageDelivered(context.Background(), message.ID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return
+	}
+	if err != nil {
+		log.Printf("Error marking message %d delivered: %v", message.ID, err)
+		return
+	}
+
+	receipt := DeliveryReceiptMessage{
+		Type:        "delivery_receipt",
+		MessageID:   message.ID,
+		RecipientID: message.ReceiverID,
+		DeliveredAt: deliveredAt.Time.UTC(),
+		CreatedAt:   time.Now().UTC(),
+	}
+	jsonMsg, err := json.Marshal(receipt)
+	if err != nil {
+		log.Printf("WS Error: Failed to marshal delivery_receipt: %v", err)
+		return
+	}
+	// Queued like read receipts if the sender just disconnected
+	connectionHub.SendOrQueue(message.SenderID, jsonMsg)
+}
@@ -0,0 +1,192 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gorilla/websocket"
+
+	"websocket-simple-chat-app/hub"
+	"websocket-simple-chat-app/token"
+)
+
+// GET /events streams events as Server-Sent Events to clients that cannot hold a WebSocket, e.g.
+// behind corporate proxies that refuse upgrades. The stream is receive-only: it carries new private
+// messages, presence changes and read receipts, and the client sends with the REST API. Like a gRPC
+// ChatStream, it is a hub client like any /ws connection (see connections.go), so the user shows
+// online while a stream is open.
+//
+// Each event has the WebSocket event type as its SSE event name and the JSON of the event as its
+// data. When the server ends the stream, a final "close" event carries the WebSocket close code.
+
+// sseEventTypes are the events streamed by /events, the others are left out
+var sseEventTypes = map[string]bool{
+	"incoming_message":    true,
+	"user_online":         true,
+	"user_offline":        true,
+	"presence_changed":    true,
+	"read_receipt_update": true,
+}
+
+// sseRetry is how long browsers wait before reconnecting a dropped stream
+const sseRetry = 3 * time.Second
+
+// SSECloseEvent is the last event of a stream the server ends
+type SSECloseEvent struct {
+	Code   int    `json:"code"` // WebSocket close code, e.g. 4001 when the token expired
+	Reason string `json:"reason"`
+}
+
+// eventsHandler serves GET /events. Streams authenticated with a token end when it expires, with
+// close code 4001; the others last until the client disconnects.
+func eventsHandler(chat *chatServer) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		payload := c.MustGet(authorizationPayloadKey).(*token.Payload)
+
+		// Tokens issued before a deactivation or suspension stay valid until they expire, so check the account
+		account, err := chat.store.GetUserByID(context.Background(), payload.UserID)
+		if err != nil {
+			if err != sql.ErrNoRows {
+				log.Printf("Error fetching user %d for event stream: %v", payload.UserID, err)
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch account"})
+				return
+			}
+			c.JSON(http.StatusForbidden, gin.H{"error": "account deactivated"})
+			return
+		}
+		if account.DeactivatedAt.Valid {
+			c.JSON(http.StatusForbidden, gin.H{"error": "account deactivated"})
+			return
+		}
+		if accountSuspended(account) {
+			c.JSON(http.StatusForbidden, gin.H{"error": "account suspended"})
+			return
+		}
+
+		c.Header("Content-Type", "text/event-stream")
+		c.Header("Cache-Control", "no-cache")
+		c.Header("X-Accel-Buffering", "no") // Keeps nginx from buffering the stream
+		c.Status(http.StatusOK)
+		fmt.Fprintf(c.Writer, "retry: %d\n\n", sseRetry.Milliseconds())
+		c.Writer.Flush()
+
+		// No features: contact cards and structured content arrive as incoming_message text
+		capabilities, _ := parseCapabilities("", "", true)
+
+		// --- Register Connection (see connections.go) ---
+		conn := newSSEConn(c.Writer)
+		client := hub.NewStreamClient(payload.UserID, conn, capabilities)
+		go client.WritePump()
+		defer conn.release()
+		defer client.Disconnect()
+		disconnect := chat.connect(client, account, 0, false)
+		defer disconnect()
+
+		method := c.GetString(authorizationMethodKey)
+		if method == authMethodBearer || method == authMethodCookie {
+			sessionTimer := startSessionTimer(client, payload.ExpiredAt)
+			defer sessionTimer.Stop()
+		}
+		log.Printf("SSE: User %s (ID: %d) opened an event stream from %s", payload.Username, payload.UserID, c.ClientIP())
+
+		select {
+		case <-c.Request.Context().Done():
+			log.Printf("SSE: Event stream of user %s (ID: %d) closed by the client", payload.Username, payload.UserID)
+		case <-conn.closed:
+			log.Printf("SSE: Event stream of user %s (ID: %d) closed by the server", payload.Username, payload.UserID)
+		}
+	}
+}
+
+// sseConn is the hub.Conn of an event stream. Text frames of the streamed types are written as
+// events, pings as comments (which keep proxies from timing out idle streams), and a close frame
+// as the close event.
+type sseConn struct {
+	w          gin.ResponseWriter
+	controller *http.ResponseController
+
+	closed    chan struct{} // Closed by Close, which ends the handler
+	closeOnce sync.Once
+
+	mu       sync.Mutex // Held while writing
+	released bool       // The handler returned: the writer must not be used anymore
+}
+
+func newSSEConn(w gin.ResponseWriter) *sseConn {
+	return &sseConn{w: w, controller: http.NewResponseController(w), closed: make(chan struct{})}
+}
+
+func (c *sseConn) WriteMessage(messageType int, data []byte) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.released {
+		return net.ErrClosed
+	}
+
+	switch messageType {
+	case websocket.TextMessage:
+		var event struct {
+			Type string `json:"type"`
+		}
+		if err := json.Unmarshal(data, &event); err != nil || !sseEventTypes[event.Type] {
+			return nil
+		}
+		// Events are marshaled JSON, so their data is one line
+		if _, err := fmt.Fprintf(c.w, "event: %s\ndata: %s\n\n", event.Type, data); err != nil {
+			return err
+		}
+	case websocket.PingMessage:
+		if _, err := c.w.WriteString(": ping\n\n"); err != nil {
+			return err
+		}
+	case websocket.CloseMessage:
+		if len(data) < 2 {
+			return nil
+		}
+		closeJSON, err := json.Marshal(SSECloseEvent{Code: int(binary.BigEndian.Uint16(data)), Reason: string(data[2:])})
+		if err != nil {
+			return err
+		}
+		if _, err := fmt.Fprintf(c.w, "event: close\ndata: %s\n\n", closeJSON); err != nil {
+			return err
+		}
+	default:
+		return nil
+	}
+	return c.controller.Flush()
+}
+
+func (c *sseConn) SetWriteDeadline(t time.Time) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.released {
+		return nil
+	}
+	if err := c.controller.SetWriteDeadline(t); err != nil && !errors.Is(err, http.ErrNotSupported) {
+		return err
+	}
+	return nil
+}
+
+func (c *sseConn) Close() error {
+	c.closeOnce.Do(func() { close(c.closed) })
+	return nil
+}
+
+// release waits for the write in progress, if any, and stops writes before the handler returns
+func (c *sseConn) release() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.released = true
+	c.controller.SetWriteDeadline(time.Time{}) // For the end of the response
+}
@@ -0,0 +1,93 @@
+package gif
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+const giphySearchURL = "https://api.giphy.com/v1/gifs/search"
+
+// GiphyProvider is a GIPHY search provider
+type GiphyProvider struct {
+	apiKey string
+	client *http.Client
+}
+
+// NewGiphyProvider creates a new GiphyProvider
+func NewGiphyProvider(apiKey string) *GiphyProvider {
+	return &GiphyProvider{
+		apiKey: apiKey,
+		client: &http.Client{Timeout: 5 * time.Second},
+	}
+}
+
+// giphyImage is a single rendition of a GIPHY result
+type giphyImage struct {
+	URL    string `json:"url"`
+	Width  string `json:"width"`
+	Height string `json:"height"`
+}
+
+type giphySearchResponse struct {
+	Data []struct {
+		ID     string `json:"id"`
+		Title  string `json:"title"`
+		Images struct {
+			Original       giphyImage `json:"original"`
+			FixedWidthDown giphyImage `json:"fixed_width_downsampled"`
+		} `json:"images"`
+	} `json:"data"`
+}
+
+// Search queries GIPHY and normalizes the results
+func (provider *GiphyProvider) Search(ctx context.Context, query string, limit int) ([]Result, error) {
+	params := url.Values{}
+	params.Set("api_key", provider.apiKey)
+	params.Set("q", query)
+	params.Set("limit", strconv.Itoa(limit))
+	params.Set("rating", "g")
+
+	var response giphySearchResponse
+	if err := getJSON(ctx, provider.client, giphySearchURL+"?"+params.Encode(), &response); err != nil {
+		return nil, fmt.Errorf("giphy search failed: %w", err)
+	}
+
+	results := make([]Result, 0, len(response.Data))
+	for _, item := range response.Data {
+		width, _ := strconv.Atoi(item.Images.Original.Width)
+		height, _ := strconv.Atoi(item.Images.Original.Height)
+		results = append(results, Result{
+			ID:         item.ID,
+			Title:      item.Title,
+			URL:        item.Images.Original.URL,
+			PreviewURL: item.Images.FixedWidthDown.URL,
+			Width:      width,
+			Height:     height,
+		})
+	}
+	return results, nil
+}
+
+// getJSON performs a GET request and decodes the JSON response into v
+func getJSON(ctx context.Context, client *http.Client, requestURL string, v any) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, requestURL, nil)
+	if err != nil {
+		return err
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("unexpected status %d", resp.StatusCode)
+	}
+	return json.NewDecoder(resp.Body).Decode(v)
+}
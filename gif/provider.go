@@ -0,0 +1,41 @@
+package gif
+
+import (
+	"context"
+	"errors"
+)
+
+// ErrNotConfigured is returned when no GIF provider API key was configured
+var ErrNotConfigured = errors.New("gif search is not configured")
+
+// Result is a provider-independent GIF search result
+type Result struct {
+	ID         string `json:"id"`
+	Title      string `json:"title"`
+	URL        string `json:"url"`         // Full size animated GIF
+	PreviewURL string `json:"preview_url"` // Small animated preview for pickers
+	Width      int    `json:"width"`
+	Height     int    `json:"height"`
+}
+
+// Provider searches an external GIF service
+type Provider interface {
+	Search(ctx context.Context, query string, limit int) ([]Result, error)
+}
+
+// NewProvider creates the provider with the given name ("giphy" or "tenor").
+// It returns ErrNotConfigured if apiKey is empty.
+func NewProvider(name string, apiKey string) (Provider, error) {
+	if apiKey == "" {
+		return nil, ErrNotConfigured
+	}
+
+	switch name {
+	case "giphy":
+		return NewGiphyProvider(apiKey), nil
+	case "tenor":
+		return NewTenorProvider(apiKey), nil
+	default:
+		return nil, errors.New("unknown gif provider: " + name)
+	}
+}
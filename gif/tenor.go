@@ -0,0 +1,71 @@
+package gif
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+const tenorSearchURL = "https://tenor.googleapis.com/v2/search"
+
+// TenorProvider is a Tenor (v2 API) search provider
+type TenorProvider struct {
+	apiKey string
+	client *http.Client
+}
+
+// NewTenorProvider creates a new TenorProvider
+func NewTenorProvider(apiKey string) *TenorProvider {
+	return &TenorProvider{
+		apiKey: apiKey,
+		client: &http.Client{Timeout: 5 * time.Second},
+	}
+}
+
+// tenorMedia is a single rendition of a Tenor result
+type tenorMedia struct {
+	URL  string `json:"url"`
+	Dims []int  `json:"dims"`
+}
+
+type tenorSearchResponse struct {
+	Results []struct {
+		ID                 string                `json:"id"`
+		ContentDescription string                `json:"content_description"`
+		MediaFormats       map[string]tenorMedia `json:"media_formats"`
+	} `json:"results"`
+}
+
+// Search queries Tenor and normalizes the results
+func (provider *TenorProvider) Search(ctx context.Context, query string, limit int) ([]Result, error) {
+	params := url.Values{}
+	params.Set("key", provider.apiKey)
+	params.Set("q", query)
+	params.Set("limit", strconv.Itoa(limit))
+	params.Set("media_filter", "gif,tinygif")
+	params.Set("contentfilter", "medium")
+
+	var response tenorSearchResponse
+	if err := getJSON(ctx, provider.client, tenorSearchURL+"?"+params.Encode(), &response); err != nil {
+		return nil, fmt.Errorf("tenor search failed: %w", err)
+	}
+
+	results := make([]Result, 0, len(response.Results))
+	for _, item := range response.Results {
+		original := item.MediaFormats["gif"]
+		result := Result{
+			ID:         item.ID,
+			Title:      item.ContentDescription,
+			URL:        original.URL,
+			PreviewURL: item.MediaFormats["tinygif"].URL,
+		}
+		if len(original.Dims) == 2 {
+			result.Width, result.Height = original.Dims[0], original.Dims[1]
+		}
+		results = append(results, result)
+	}
+	return results, nil
+}
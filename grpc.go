@@ -0,0 +1,309 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"encoding/binary"
+	"errors"
+	"io"
+	"log"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/keepalive"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+
+	chatv1 "websocket-simple-chat-app/api/chat/v1"
+	"websocket-simple-chat-app/bruteforce"
+	"websocket-simple-chat-app/hub"
+	"websocket-simple-chat-app/token"
+)
+
+// The gRPC API serves clients that would rather not hold a WebSocket, such as backend services and
+// apps with a gRPC stack, on GRPC_LISTEN_ADDR. Its ChatStream RPC (see api/chat/v1/chat.proto) is a
+// typed version of the WebSocket protocol: requests and events are protobuf messages, mapped to and
+// from the WebSocket messages (see grpc_messages.go), and the connection is a hub client like any
+// other (see connections.go).
+//
+// The stream is set up with request metadata instead of the /ws query parameters and headers:
+// authorization ("Bearer <token>") or x-api-key, protocol-version, capabilities and since. When the
+// server closes the connection, the RPC ends with the status of the WebSocket close code (see
+// grpcCloseStatus) and the code itself in the ws-close-code trailer.
+
+//go:generate protoc --go_out=. --go_opt=paths=source_relative --go-grpc_out=. --go-grpc_opt=paths=source_relative api/chat/v1/chat.proto
+
+// HTTP/2 keepalives replace the WebSocket pings: connections that miss them are dropped
+const (
+	grpcKeepaliveTime    = 30 * time.Second
+	grpcKeepaliveTimeout = 20 * time.Second
+	grpcKeepaliveMinTime = 10 * time.Second // Clients pinging more often are disconnected
+)
+
+// Request metadata of ChatStream (gRPC lowercases the keys)
+const (
+	grpcMetadataAuthorization   = "authorization"
+	grpcMetadataAPIKey          = "x-api-key"
+	grpcMetadataProtocolVersion = "protocol-version"
+	grpcMetadataCapabilities    = "capabilities"
+	grpcMetadataSince           = "since"
+	grpcTrailerCloseCode        = "ws-close-code"
+)
+
+// newGRPCServer creates the gRPC server of the ChatStream API
+func newGRPCServer(chat *chatServer, authGuard *bruteforce.Guard) *grpc.Server {
+	server := grpc.NewServer(
+		grpc.MaxRecvMsgSize(int(wsReadLimit())),
+		grpc.KeepaliveParams(keepalive.ServerParameters{Time: grpcKeepaliveTime, Timeout: grpcKeepaliveTimeout}),
+		grpc.KeepaliveEnforcementPolicy(keepalive.EnforcementPolicy{MinTime: grpcKeepaliveMinTime, PermitWithoutStream: true}),
+	)
+	chatv1.RegisterChatServer(server, &grpcChatHandler{chat: chat, authGuard: authGuard})
+	return server
+}
+
+// grpcChatHandler serves ChatStream
+type grpcChatHandler struct {
+	chatv1.UnimplementedChatServer
+
+	chat      *chatServer
+	authGuard *bruteforce.Guard // Shared with /ws
+}
+
+func (h *grpcChatHandler) ChatStream(stream chatv1.Chat_ChatStreamServer) error {
+	ctx := stream.Context()
+	md, _ := metadata.FromIncomingContext(ctx)
+
+	// --- Brute-Force Protection ---
+	clientIP := grpcPeerIP(ctx)
+	if blocked, _ := h.authGuard.Blocked(clientIP); blocked {
+		log.Printf("gRPC Warning: Rejected stream from blocked IP %s", clientIP)
+		return status.Error(codes.ResourceExhausted, "Too many failed authentication attempts")
+	}
+
+	features, declared := grpcMetadataValue(md, grpcMetadataCapabilities)
+	capabilities, err := parseCapabilities(grpcMetadataString(md, grpcMetadataProtocolVersion), features, declared)
+	if err != nil {
+		return status.Error(codes.InvalidArgument, err.Error())
+	}
+	syncSince, syncRequested, err := parseSinceValue(grpcMetadataValue(md, grpcMetadataSince))
+	if err != nil {
+		return status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	// --- Authentication: an API key, else a PASETO access token ---
+	var payload *token.Payload
+	var apiKeyScopes []string
+	apiKey := grpcMetadataString(md, grpcMetadataAPIKey)
+	apiKeySession := apiKey != ""
+	if apiKeySession {
+		payload, apiKeyScopes, err = authenticateWsAPIKey(h.chat.store, apiKey)
+		if err != nil {
+			log.Printf("gRPC Error: API key from %s refused: %v", clientIP, err)
+			if err == errInvalidAPIKey {
+				recordWsAuthFailure(h.authGuard, clientIP)
+			}
+			return status.Error(codes.Unauthenticated, err.Error())
+		}
+	} else {
+		payload, err = h.verifyBearer(grpcMetadataString(md, grpcMetadataAuthorization))
+		if err != nil {
+			log.Printf("gRPC Error: Token from %s refused: %v", clientIP, err)
+			recordWsAuthFailure(h.authGuard, clientIP)
+			return status.Error(codes.Unauthenticated, err.Error())
+		}
+	}
+	h.authGuard.RecordSuccess(clientIP)
+
+	// Tokens issued before a deactivation or suspension stay valid until they expire, so check the account
+	account, err := h.chat.store.GetUserByID(context.Background(), payload.UserID)
+	if err != nil {
+		if err != sql.ErrNoRows {
+			log.Printf("gRPC Error: Failed to fetch user %d: %v", payload.UserID, err)
+			return status.Error(codes.Internal, "Failed to fetch account")
+		}
+		return status.Error(codes.PermissionDenied, "account deactivated")
+	}
+	if account.DeactivatedAt.Valid {
+		return status.Error(codes.PermissionDenied, "account deactivated")
+	}
+	if accountSuspended(account) {
+		return status.Error(codes.PermissionDenied, "account suspended")
+	}
+
+	// --- Register Connection (see connections.go) ---
+	conn := newGRPCStreamConn(stream)
+	client := hub.NewStreamClient(payload.UserID, conn, capabilities)
+	go client.WritePump()
+	defer client.Disconnect()
+	disconnect := h.chat.connect(client, account, syncSince, syncRequested)
+	defer disconnect()
+
+	connection, stopSession := h.chat.newConnection(client, payload, account, apiKeySession, apiKeyScopes)
+	defer stopSession()
+	log.Printf("gRPC: User %s (ID: %d) opened a ChatStream from %s", payload.Username, payload.UserID, clientIP)
+
+	// --- Message Read Loop ---
+	// RecvMsg cannot be interrupted, so it runs in its own goroutine: the stream ends when the
+	// client closes its side or when the hub closes the client, whichever comes first
+	received := make(chan error, 1)
+	go func() {
+		for {
+			req, err := stream.Recv()
+			if err != nil {
+				received <- err
+				return
+			}
+			receivedAt := time.Now().UTC()
+			frame, err := wsRequestFrame(req)
+			if err != nil {
+				log.Printf("gRPC Warning: Invalid request from %s (ID: %d): %v", payload.Username, payload.UserID, err)
+				sendWsError(client, wsErrorInvalidJSON, "", "", err.Error())
+				continue
+			}
+			connection.handleMessage(frame, receivedAt)
+		}
+	}()
+
+	select {
+	case err := <-received:
+		if err == io.EOF {
+			log.Printf("gRPC: ChatStream of user %s (ID: %d) closed by the client", payload.Username, payload.UserID)
+			return nil
+		}
+		log.Printf("gRPC: ChatStream of user %s (ID: %d) failed: %v", payload.Username, payload.UserID, err)
+		return err
+	case <-conn.closed:
+		code, reason := conn.closeFrame()
+		if code != 0 {
+			stream.SetTrailer(metadata.Pairs(grpcTrailerCloseCode, strconv.Itoa(code)))
+		}
+		return grpcCloseStatus(code, reason)
+	}
+}
+
+// verifyBearer checks the access token of the authorization metadata, like the /ws handshake
+func (h *grpcChatHandler) verifyBearer(authorization string) (*token.Payload, error) {
+	fields := strings.Fields(authorization)
+	if len(fields) != 2 || strings.ToLower(fields[0]) != authorizationTypeBearer {
+		return nil, errors.New("token required")
+	}
+	payload, err := h.chat.tokenMaker.VerifyToken(fields[1])
+	if err != nil {
+		return nil, errors.New("invalid token")
+	}
+	if err := checkTokenRevoked(h.chat.store, payload); err != nil {
+		return nil, errors.New("token revoked")
+	}
+	return payload, nil
+}
+
+// grpcCloseStatus is the status a ChatStream ends with when the server closes it with a WebSocket
+// close code. Code 0 means it was dropped without one, e.g. for falling behind.
+func grpcCloseStatus(code int, reason string) error {
+	switch code {
+	case 0:
+		return status.Error(codes.Unavailable, "connection dropped")
+	case wsCloseAuthFailed, wsCloseTokenExpired:
+		return status.Error(codes.Unauthenticated, reason)
+	case wsCloseBanned:
+		return status.Error(codes.PermissionDenied, reason)
+	case wsCloseRateLimited:
+		return status.Error(codes.ResourceExhausted, reason)
+	case wsCloseServerShutdown:
+		return status.Error(codes.Unavailable, reason)
+	default: // wsCloseKicked, wsCloseProtocolError
+		return status.Error(codes.Aborted, reason)
+	}
+}
+
+// grpcStreamConn is the hub.Conn of a ChatStream. Text frames are sent on the stream as events,
+// pings are left to the HTTP/2 keepalives, and a close frame is kept for the status the RPC ends
+// with.
+type grpcStreamConn struct {
+	stream chatv1.Chat_ChatStreamServer
+
+	closed    chan struct{} // Closed by Close, which ends the RPC
+	closeOnce sync.Once
+
+	mu          sync.Mutex
+	closeCode   int
+	closeReason string
+}
+
+func newGRPCStreamConn(stream chatv1.Chat_ChatStreamServer) *grpcStreamConn {
+	return &grpcStreamConn{stream: stream, closed: make(chan struct{})}
+}
+
+func (c *grpcStreamConn) WriteMessage(messageType int, data []byte) error {
+	switch messageType {
+	case websocket.TextMessage:
+		event, err := chatEvent(data)
+		if err != nil {
+			log.Printf("gRPC Error: Dropping event that has no ChatEvent form: %v", err)
+			return nil
+		}
+		return c.stream.Send(event)
+	case websocket.CloseMessage:
+		c.mu.Lock()
+		defer c.mu.Unlock()
+		if len(data) >= 2 {
+			c.closeCode = int(binary.BigEndian.Uint16(data))
+			c.closeReason = string(data[2:])
+		}
+		return nil
+	default:
+		return nil
+	}
+}
+
+// SetWriteDeadline does nothing: sends are bounded by HTTP/2 flow control and the keepalives
+func (c *grpcStreamConn) SetWriteDeadline(time.Time) error {
+	return nil
+}
+
+func (c *grpcStreamConn) Close() error {
+	c.closeOnce.Do(func() { close(c.closed) })
+	return nil
+}
+
+// closeFrame returns the close code and reason the hub closed the connection with, if any
+func (c *grpcStreamConn) closeFrame() (int, string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.closeCode, c.closeReason
+}
+
+// grpcPeerIP returns the IP of the client of a stream
+func grpcPeerIP(ctx context.Context) string {
+	p, ok := peer.FromContext(ctx)
+	if !ok {
+		return ""
+	}
+	host, _, err := net.SplitHostPort(p.Addr.String())
+	if err != nil {
+		return p.Addr.String()
+	}
+	return host
+}
+
+// grpcMetadataValue returns the first value of a metadata key and whether it is set
+func grpcMetadataValue(md metadata.MD, key string) (string, bool) {
+	values := md.Get(key)
+	if len(values) == 0 {
+		return "", false
+	}
+	return values[0], true
+}
+
+// grpcMetadataString returns the first value of a metadata key, or "" if unset
+func grpcMetadataString(md metadata.MD, key string) string {
+	value, _ := grpcMetadataValue(md, key)
+	return value
+}
@@ -0,0 +1,202 @@
+package main
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/known/structpb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	chatv1 "websocket-simple-chat-app/api/chat/v1"
+	"websocket-simple-chat-app/hub"
+)
+
+// The ChatStream messages (api/chat/v1/chat.proto) are mapped to and from the WebSocket protocol,
+// so that gRPC and WebSocket clients share one read loop and the hub (see connections.go):
+//   - requests are encoded as the WebSocket message they stand for and handled by the read loop;
+//   - events are decoded from the JSON the hub sends to every connection, into the oneof field
+//     named after their type.
+
+// wsRequestFrame encodes a ChatStream request as a WebSocket message
+func wsRequestFrame(req *chatv1.ChatRequest) ([]byte, error) {
+	var message any
+	switch r := req.Request.(type) {
+	case *chatv1.ChatRequest_PrivateMessage:
+		m := r.PrivateMessage
+		message = IncomingWsMessage{Type: "private_message", RecipientID: m.RecipientId, Content: m.Content, ContentType: m.ContentType, ClientMsgID: m.ClientMsgId, ReplyToMessageID: m.ReplyToMessageId}
+	case *chatv1.ChatRequest_TypingStart:
+		message = TypingIndicatorMessage{Type: "typing_start", RecipientID: r.TypingStart.RecipientId}
+	case *chatv1.ChatRequest_TypingStop:
+		message = TypingIndicatorMessage{Type: "typing_stop", RecipientID: r.TypingStop.RecipientId}
+	case *chatv1.ChatRequest_MessageRead:
+		m := r.MessageRead
+		message = MessageReadMessage{Type: "message_read", SenderID: m.SenderId, MessageIDs: m.MessageIds, UpToID: m.UpToId}
+	case *chatv1.ChatRequest_ContactCard:
+		message = ContactCardRequest{Type: "contact_card", RecipientID: r.ContactCard.RecipientId, UserID: r.ContactCard.UserId}
+	case *chatv1.ChatRequest_DeleteMessage:
+		message = DeleteMessageRequest{Type: "delete_message", MessageID: r.DeleteMessage.MessageId}
+	case *chatv1.ChatRequest_Ping:
+		clientTime, err := rawJSONValue(r.Ping.ClientTime)
+		if err != nil {
+			return nil, err
+		}
+		message = PingMessage{Type: "ping", ClientTime: clientTime, LastRttMs: r.Ping.LastRttMs}
+	case *chatv1.ChatRequest_Reauth:
+		message = ReauthMessage{Type: "reauth", Token: r.Reauth.Token}
+	case *chatv1.ChatRequest_SetPresence:
+		message = SetPresenceMessage{Type: "set_presence", Presence: r.SetPresence.Presence}
+	case *chatv1.ChatRequest_RoomMessage:
+		message = RoomMessageRequest{Type: "room_message", RoomID: r.RoomMessage.RoomId, Content: r.RoomMessage.Content}
+	case *chatv1.ChatRequest_RoomTypingStart:
+		message = RoomTypingRequest{Type: "room_typing_start", RoomID: r.RoomTypingStart.RoomId}
+	case *chatv1.ChatRequest_RoomTypingStop:
+		message = RoomTypingRequest{Type: "room_typing_stop", RoomID: r.RoomTypingStop.RoomId}
+	case *chatv1.ChatRequest_SupportReply:
+		message = SupportReplyMessage{Type: "support_reply", TicketID: r.SupportReply.TicketId, Content: r.SupportReply.Content}
+	case *chatv1.ChatRequest_AnnouncementSeen:
+		message = AnnouncementSeenMessage{Type: "announcement_seen", AnnouncementID: r.AnnouncementSeen.AnnouncementId}
+	case *chatv1.ChatRequest_Offer:
+		offer, err := rawJSONValue(r.Offer.Offer)
+		if err != nil {
+			return nil, err
+		}
+		message = OfferMessage{Type: "offer", Offer: offer, ReceiverID: r.Offer.ReceiverId}
+	case *chatv1.ChatRequest_Answer:
+		answer, err := rawJSONValue(r.Answer.Answer)
+		if err != nil {
+			return nil, err
+		}
+		message = AnswerMessage{Type: "answer", Answer: answer, ReceiverID: r.Answer.ReceiverId}
+	case *chatv1.ChatRequest_IceCandidate:
+		candidate, err := rawJSONValue(r.IceCandidate.Candidate)
+		if err != nil {
+			return nil, err
+		}
+		message = IceCandidateMessage{Type: "ice-candidate", Candidate: candidate, ReceiverID: r.IceCandidate.ReceiverId}
+	case *chatv1.ChatRequest_Hangup:
+		message = HangupMessage{Type: "hangup", ReceiverID: r.Hangup.ReceiverId}
+	default:
+		return nil, errors.New("request has no message set")
+	}
+	return json.Marshal(message)
+}
+
+// rawJSONValue returns the JSON of an opaque value, null if unset
+func rawJSONValue(value *structpb.Value) (json.RawMessage, error) {
+	if value == nil {
+		return json.RawMessage("null"), nil
+	}
+	return protojson.Marshal(value)
+}
+
+// chatEventFields are the oneof fields of ChatEvent, one per event type
+var chatEventFields = (&chatv1.ChatEvent{}).ProtoReflect().Descriptor().Oneofs().ByName("event").Fields()
+
+// eventUnmarshal reads event JSON into the message of its oneof field. The field names match the
+// keys (snake_case, or the lowerCamelCase JSON names for the camelCase ones); "type" and "seq"
+// are left to the ChatEvent itself.
+var eventUnmarshal = protojson.UnmarshalOptions{DiscardUnknown: true}
+
+// chatEvent decodes an event the hub sends to a connection
+func chatEvent(message []byte) (*chatv1.ChatEvent, error) {
+	var header struct {
+		Type string `json:"type"`
+		Seq  int64  `json:"seq"` // Set on queued events, see hub.SendOrQueue
+	}
+	if err := json.Unmarshal(message, &header); err != nil {
+		return nil, err
+	}
+	event := &chatv1.ChatEvent{Seq: header.Seq}
+
+	// Events whose JSON does not follow the proto
+	switch header.Type {
+	case "message_sync":
+		var sync MessageSyncMessage
+		if err := json.Unmarshal(message, &sync); err != nil {
+			return nil, err
+		}
+		event.Event = &chatv1.ChatEvent_MessageSync{MessageSync: messageSyncEvent(sync)}
+		return event, nil
+	case "batch":
+		var batch hub.BatchMessage
+		if err := json.Unmarshal(message, &batch); err != nil {
+			return nil, err
+		}
+		events := make([]*chatv1.ChatEvent, 0, len(batch.Events))
+		for _, raw := range batch.Events {
+			batched, err := chatEvent(raw)
+			if err != nil {
+				return nil, err
+			}
+			events = append(events, batched)
+		}
+		event.Event = &chatv1.ChatEvent_Batch{Batch: &chatv1.Batch{Events: events, CreatedAt: timestamppb.New(batch.CreatedAt)}}
+		return event, nil
+	}
+
+	field := chatEventFields.ByName(protoreflect.Name(strings.ReplaceAll(header.Type, "-", "_")))
+	if field == nil {
+		return nil, fmt.Errorf("no ChatEvent field for event type %q", header.Type)
+	}
+	reflected := event.ProtoReflect()
+	value := reflected.NewField(field)
+	if err := eventUnmarshal.Unmarshal(message, value.Message().Interface()); err != nil {
+		return nil, fmt.Errorf("decoding %s: %w", header.Type, err)
+	}
+	reflected.Set(field, value)
+	return event, nil
+}
+
+// messageSyncEvent converts a message_sync, whose messages have nullable columns
+func messageSyncEvent(sync MessageSyncMessage) *chatv1.MessageSync {
+	messages := make([]*chatv1.SyncedMessage, len(sync.Messages))
+	for i, m := range sync.Messages {
+		messages[i] = &chatv1.SyncedMessage{
+			Id:               m.ID,
+			SenderId:         m.SenderID,
+			ReceiverId:       m.ReceiverID,
+			Content:          m.Content,
+			ContentType:      m.ContentType,
+			CreatedAt:        timestamppb.New(m.CreatedAt),
+			ReadAt:           nullTimestamp(m.ReadAt),
+			DeletedAt:        nullTimestamp(m.DeletedAt),
+			DeliveredAt:      nullTimestamp(m.DeliveredAt),
+			ReplyToMessageId: m.ReplyToMessageID.Int64,
+			State:            m.State,
+			ReplyTo:          quotedMessageEvent(m.ReplyTo),
+		}
+	}
+	return &chatv1.MessageSync{
+		Messages:  messages,
+		LastId:    sync.LastID,
+		Complete:  sync.Complete,
+		CreatedAt: timestamppb.New(sync.CreatedAt),
+	}
+}
+
+func quotedMessageEvent(quoted *QuotedMessage) *chatv1.QuotedMessage {
+	if quoted == nil {
+		return nil
+	}
+	return &chatv1.QuotedMessage{
+		Id:          quoted.ID,
+		SenderId:    quoted.SenderID,
+		ContentType: quoted.ContentType,
+		Preview:     quoted.Preview,
+		CreatedAt:   timestamppb.New(quoted.CreatedAt),
+		Deleted:     quoted.Deleted,
+	}
+}
+
+// nullTimestamp returns nil for NULL
+func nullTimestamp(t sql.NullTime) *timestamppb.Timestamp {
+	if !t.Valid {
+		return nil
+	}
+	return timestamppb.New(t.Time)
+}
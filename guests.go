@@ -0,0 +1,115 @@
+package main
+
+import (
+	"context"
+	"crypto/rand"
+	"database/sql"
+	"encoding/hex"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	db "websocket-simple-chat-app/db/sqlc"
+	"websocket-simple-chat-app/hub"
+	"websocket-simple-chat-app/token"
+	"websocket-simple-chat-app/util/password"
+)
+
+// roleGuest is the role of ephemeral guest accounts
+const roleGuest = "guest"
+
+// Guest account settings. Guests get a random username, no usable password and a token that
+// expires together with the account; the account and its data are deleted after guestTTL.
+const (
+	guestTTL            = 2 * time.Hour
+	guestSweepInterval  = time.Minute
+	guestUsernamePrefix = "guest-"
+)
+
+// guestAllowedMessageTypes lists the WebSocket message types guests may send.
+// Guests are meant for public rooms and support chats only, so calls and the like are refused.
+var guestAllowedMessageTypes = map[string]bool{
+	"private_message":   true, // Only to support identities, checked by the handler
+	"room_message":      true, // Guests can join public rooms but not create them
+	"room_typing_start": true,
+	"room_typing_stop":  true,
+	"ping":              true,
+}
+
+// createGuestHandler issues a new guest identity and its token. Disabled unless guest mode is on.
+func createGuestHandler(store *db.Queries, tokenMaker token.Maker, enabled bool) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !enabled {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Guest accounts are disabled"})
+			return
+		}
+
+		// 1. Random identity: nobody can log in with the password, the token is the only credential
+		suffix := make([]byte, 4)
+		secret := make([]byte, 32)
+		if _, err := rand.Read(suffix); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create guest"})
+			return
+		}
+		if _, err := rand.Read(secret); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create guest"})
+			return
+		}
+
+		hash, err := password.Hash(hex.EncodeToString(secret))
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create guest"})
+			return
+		}
+
+		expiresAt := time.Now().UTC().Add(guestTTL)
+		user, err := store.CreateGuestUser(context.Background(), db.CreateGuestUserParams{
+			Username:     guestUsernamePrefix + hex.EncodeToString(suffix),
+			PasswordHash: hash,
+			ExpiresAt:    sql.NullTime{Time: expiresAt, Valid: true},
+		})
+		if err != nil {
+			log.Printf("Error creating guest user: %v", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create guest"})
+			return
+		}
+
+		// 2. Token valid for the lifetime of the account
+		tokenStr, payload, err := tokenMaker.CreateToken(user.ID, user.Username, guestTTL)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate token"})
+			return
+		}
+
+		log.Printf("Guest %s (ID: %d) created, expires at %s", user.Username, user.ID, expiresAt.Format(time.RFC3339))
+		c.JSON(http.StatusCreated, gin.H{
+			"message":    "Guest created",
+			"user_id":    user.ID,
+			"username":   user.Username,
+			"expires_at": expiresAt,
+			"token":      tokenStr,
+			"payload":    payload,
+		})
+	}
+}
+
+// runGuestSweeper periodically deletes expired guests with their messages and disconnects them.
+// Guests covered by a legal hold are kept (see legal_holds.go).
+func runGuestSweeper(store *db.Queries, connectionHub *hub.Hub) {
+	for range time.Tick(guestSweepInterval) {
+		deleted, err := store.DeleteExpiredGuests(context.Background())
+		if err != nil {
+			log.Printf("Error sweeping expired guests: %v", err)
+			continue
+		}
+
+		for _, userID := range deleted {
+			connectionHub.DisconnectUser(userID, wsCloseTokenExpired, "guest session expired")
+		}
+		if len(deleted) > 0 {
+			log.Printf("Deleted %d expired guests", len(deleted))
+		}
+	}
+}
@@ -0,0 +1,65 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"net/http"
+	"sync/atomic"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"websocket-simple-chat-app/hub"
+	"websocket-simple-chat-app/presence"
+)
+
+// Probes for load balancers and orchestrators. /healthz only tells that the process serves HTTP,
+// for liveness: restarting the process would not fix a failing dependency. /readyz checks what
+// requests need, for readiness: instances that fail it, or that are shutting down, should get no
+// traffic.
+
+// readinessTimeout bounds the checks of one readiness probe together
+const readinessTimeout = 2 * time.Second
+
+// healthzHandler answers as long as the process serves HTTP
+func healthzHandler() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.JSON(http.StatusOK, gin.H{"status": "ok"})
+	}
+}
+
+// readyzHandler checks the database, the hub and, with REDIS_URL, Redis. It answers 503 with the
+// failed checks, and once the server is shutting down.
+func readyzHandler(dbConn *sql.DB, connectionHub *hub.Hub, presenceTracker presence.Tracker, redisEnabled bool, shuttingDown *atomic.Bool) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if shuttingDown.Load() {
+			c.JSON(http.StatusServiceUnavailable, gin.H{"status": "shutting down"})
+			return
+		}
+
+		ctx, cancel := context.WithTimeout(c.Request.Context(), readinessTimeout)
+		defer cancel()
+
+		checks := map[string]string{}
+		ready := true
+		record := func(name string, err error) {
+			if err != nil {
+				checks[name] = err.Error()
+				ready = false
+				return
+			}
+			checks[name] = "ok"
+		}
+		record("database", dbConn.PingContext(ctx))
+		record("hub", connectionHub.Ready(ctx)) // Also pings the Redis broker
+		if redisEnabled {
+			record("redis", presenceTracker.Ping(ctx))
+		}
+
+		if !ready {
+			c.JSON(http.StatusServiceUnavailable, gin.H{"status": "unavailable", "checks": checks})
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"status": "ok", "checks": checks})
+	}
+}
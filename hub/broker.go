@@ -0,0 +1,135 @@
+package hub
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"time"
+
+	"websocket-simple-chat-app/metrics"
+)
+
+// Relay settings
+const (
+	relayBufferSize     = 1024            // Envelopes waiting to be published before new ones are dropped
+	relayPublishTimeout = 5 * time.Second // Per envelope
+	relayRetryDelay     = time.Second     // Before resubscribing after the subscription failed
+)
+
+// Broker connects the hubs of several server instances, so users connected to another instance
+// receive events too. Implementations must deliver an envelope to every instance, including the
+// publisher (which ignores its own envelopes).
+type Broker interface {
+	// Publish sends an envelope to all instances
+	Publish(ctx context.Context, envelope Envelope) error
+	// Subscribe calls handle for every published envelope until ctx is done or the subscription fails
+	Subscribe(ctx context.Context, handle func(Envelope)) error
+	// Ping reports whether the broker is reachable
+	Ping(ctx context.Context) error
+}
+
+// Envelope is a hub event relayed between instances
+type Envelope struct {
+	Origin        string          `json:"origin"`                    // Instance that published it
+	Broadcast     bool            `json:"broadcast,omitempty"`       // Send to every connected user
+	ExcludeUserID int32           `json:"exclude_user_id,omitempty"` // Broadcasts only
+	UserIDs       []int32         `json:"user_ids,omitempty"`        // Recipients of non-broadcasts
+	Queue         bool            `json:"queue,omitempty"`           // Sequence and queue it like SendOrQueue, instead of live-only
+	CloseCode     int             `json:"close_code,omitempty"`      // Close the recipients' connections instead of sending Message
+	CloseReason   string          `json:"close_reason,omitempty"`
+	Handoff       []HandoffState  `json:"handoff,omitempty"`    // Replay state of a draining instance, instead of Message
+	ReceivedAt    time.Time       `json:"received_at,omitzero"` // When the origin received the message, if its delivery latency is measured
+	Message       json.RawMessage `json:"message"`
+
+	published chan struct{} // Closed once the envelope was published, if not nil
+}
+
+// UseBroker relays the hub's events through broker to the other instances and delivers theirs to
+// the local connections. It must be called before Run.
+//
+// Every instance numbers the events of its own connections (see Checkpoint), so clients using the
+// sync capability should be routed to the same instance by user. A draining instance hands the
+// numbering of its users off to the others (see handoff.go).
+func (h *Hub) UseBroker(broker Broker) {
+	h.broker = broker
+	h.relay = make(chan Envelope, relayBufferSize)
+}
+
+// Relay sends a live-only message to the user's connections on the other instances.
+// Local connections are not affected. It does nothing without a broker.
+func (h *Hub) Relay(userID int32, message []byte) {
+	h.publish(Envelope{UserIDs: []int32{userID}, Message: message})
+}
+
+// publish queues an envelope for the publish loop without blocking the caller
+func (h *Hub) publish(envelope Envelope) {
+	if h.broker == nil {
+		return
+	}
+	envelope.Origin = h.epoch
+	select {
+	case h.relay <- envelope:
+	default:
+		log.Printf("Hub Warning: Relay buffer full, dropping envelope for %d users (broadcast: %t)", len(envelope.UserIDs), envelope.Broadcast)
+	}
+}
+
+// runRelay publishes local envelopes and applies the envelopes of other instances until the process exits
+func (h *Hub) runRelay() {
+	go func() {
+		for envelope := range h.relay {
+			ctx, cancel := context.WithTimeout(context.Background(), relayPublishTimeout)
+			if err := h.broker.Publish(ctx, envelope); err != nil {
+				log.Printf("Hub Error: Failed to publish envelope: %v", err)
+			} else if envelope.published != nil {
+				close(envelope.published)
+			}
+			cancel()
+		}
+	}()
+
+	for {
+		err := h.broker.Subscribe(context.Background(), func(envelope Envelope) {
+			if envelope.Origin == h.epoch {
+				return
+			}
+			h.do(func() { h.applyEnvelope(envelope) })
+		})
+		log.Printf("Hub Error: Broker subscription ended: %v. Resubscribing in %s", err, relayRetryDelay)
+		time.Sleep(relayRetryDelay)
+	}
+}
+
+// applyEnvelope delivers an envelope of another instance to the local connections (only called from Run)
+func (h *Hub) applyEnvelope(envelope Envelope) {
+	if len(envelope.Handoff) > 0 {
+		h.adoptReplayState(envelope.Origin, envelope.Handoff)
+		return
+	}
+	if envelope.CloseCode != 0 {
+		for _, userID := range envelope.UserIDs {
+			h.closeUser(userID, envelope.CloseCode, envelope.CloseReason)
+		}
+		return
+	}
+
+	message := []byte(envelope.Message)
+	if envelope.Broadcast {
+		h.sendToAll(message, envelope.ExcludeUserID)
+		return
+	}
+
+	var onWritten func()
+	if !envelope.ReceivedAt.IsZero() {
+		onWritten = func() { metrics.ObserveDelivery(metrics.RouteRelay, time.Since(envelope.ReceivedAt)) }
+	}
+	for _, userID := range envelope.UserIDs {
+		if envelope.Queue {
+			h.deliverAndNotify(userID, func(Capabilities) []byte { return message }, onWritten)
+			continue
+		}
+		for client := range h.clients[userID] {
+			client.SendAndNotify(message, onWritten)
+		}
+	}
+}
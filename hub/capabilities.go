@@ -0,0 +1,40 @@
+package hub
+
+import "time"
+
+// Capabilities are the optional features and protocol version a connection declared at connect time.
+// The zero value supports no optional feature.
+type Capabilities struct {
+	ProtocolVersion int
+	Features        map[string]bool
+}
+
+// Supports reports whether the connection declared the given feature
+func (c Capabilities) Supports(feature string) bool {
+	return c.Features[feature]
+}
+
+// SendOrQueueRendered is like SendOrQueue for events whose format depends on the capabilities of
+// the receiving connection: render is called once per connection. Events buffered for replay are
+// rendered with the zero Capabilities, as the connection they will reach is unknown. So are the
+// events relayed to other instances.
+func (h *Hub) SendOrQueueRendered(userID int32, render func(Capabilities) []byte) bool {
+	return h.SendOrQueueReceived(userID, render, time.Time{})
+}
+
+// SendOrQueueReceived is like SendOrQueueRendered for a message received at receivedAt. The other
+// instances that write it to a connection record its delivery latency (route metrics.RouteRelay).
+func (h *Hub) SendOrQueueReceived(userID int32, render func(Capabilities) []byte, receivedAt time.Time) bool {
+	return h.SendOrQueueAndNotify(userID, render, receivedAt, nil)
+}
+
+// SendOrQueueAndNotify is like SendOrQueueReceived, and calls onWritten (if not nil) each time a
+// connection of this instance wrote the message. Queued messages replayed later do not call it.
+func (h *Hub) SendOrQueueAndNotify(userID int32, render func(Capabilities) []byte, receivedAt time.Time, onWritten func()) bool {
+	var accepted bool
+	h.do(func() {
+		accepted = h.deliverAndNotify(userID, render, onWritten)
+	})
+	h.publish(Envelope{UserIDs: []int32{userID}, Queue: true, ReceivedAt: receivedAt, Message: render(Capabilities{})})
+	return accepted
+}
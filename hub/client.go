@@ -0,0 +1,225 @@
+package hub
+
+import (
+	"log"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/gorilla/websocket"
+
+	"websocket-simple-chat-app/metrics"
+	"websocket-simple-chat-app/ratelimit"
+)
+
+// Write pump limits and heartbeat
+const (
+	// sendBufferSize is the number of outbound messages a client can have pending.
+	// It is larger than replayMaxEvents so a full replay buffer can be flushed at once.
+	sendBufferSize = 256
+
+	// writeWait is the time allowed to write a message to the connection
+	writeWait = 10 * time.Second
+
+	// pongWait is how long a connection may stay silent: every pong extends the read deadline by it.
+	// Connections that miss it fail their next read and are unregistered by their read loop.
+	pongWait = 60 * time.Second
+
+	// pingPeriod is the interval of server pings. It must be shorter than pongWait.
+	pingPeriod = pongWait * 9 / 10
+)
+
+// outboundFrame is a message waiting in a client's send buffer
+type outboundFrame struct {
+	messageType int
+	data        []byte
+	onWritten   func() // Optional, called by the write pump once the frame was written
+}
+
+// Conn is the transport of a client, written by its write pump only. *websocket.Conn implements
+// it; other transports (such as gRPC streams) map the WebSocket message types to their own frames.
+type Conn interface {
+	WriteMessage(messageType int, data []byte) error
+	SetWriteDeadline(t time.Time) error
+	Close() error
+}
+
+// Client is one connection of a user, usually a WebSocket connection.
+// gorilla/websocket supports a single concurrent writer, so nothing writes to the connection
+// directly once the client is created: outbound messages go through the buffered send channel
+// and are written by the client's own WritePump goroutine. Reads stay with the caller.
+type Client struct {
+	UserID       int32
+	Capabilities Capabilities // Declared at connect time, read-only afterwards
+
+	conn Conn
+	send chan outboundFrame
+
+	done      chan struct{} // Closed when the client shuts down
+	closeOnce sync.Once
+
+	journal atomic.Pointer[journal]                  // Set on registration when the hub journal is enabled
+	faults  atomic.Pointer[FaultInjection]           // Set on registration when fault injection is enabled
+	limiter atomic.Pointer[ratelimit.Limiter[int32]] // Set on registration when inbound messages are rate limited
+
+	lowBandwidth atomic.Pointer[lowBandwidthState]     // Set on registration for low-bandwidth connections
+	eventClasses atomic.Pointer[map[string]EventClass] // Set on registration when events are classified
+}
+
+// NewClient wraps an authenticated connection and arms its heartbeat: the connection must answer
+// the pings of WritePump, which must be started in its own goroutine.
+func NewClient(userID int32, conn *websocket.Conn, capabilities Capabilities) *Client {
+	conn.SetReadDeadline(time.Now().Add(pongWait))
+	conn.SetPongHandler(func(string) error {
+		return conn.SetReadDeadline(time.Now().Add(pongWait))
+	})
+
+	return NewStreamClient(userID, conn, capabilities)
+}
+
+// NewStreamClient wraps an authenticated connection of another transport, which detects dead peers
+// by itself: the pings of WritePump are written to it like any frame, and it may ignore them.
+func NewStreamClient(userID int32, conn Conn, capabilities Capabilities) *Client {
+	return &Client{
+		UserID:       userID,
+		Capabilities: capabilities,
+		conn:         conn,
+		send:         make(chan outboundFrame, sendBufferSize),
+		done:         make(chan struct{}),
+	}
+}
+
+// Conn returns the underlying connection, to be used for reading only
+func (c *Client) Conn() Conn {
+	return c.conn
+}
+
+// Send queues a text message for the connection. A client whose buffer is full is too slow to
+// keep up and is disconnected. It returns false if the message was not queued.
+func (c *Client) Send(message []byte) bool {
+	return c.enqueue(outboundFrame{messageType: websocket.TextMessage, data: message})
+}
+
+// SendAndNotify is like Send, and calls onWritten from the write pump once the message was written
+func (c *Client) SendAndNotify(message []byte, onWritten func()) bool {
+	return c.enqueue(outboundFrame{messageType: websocket.TextMessage, data: message, onWritten: onWritten})
+}
+
+// Close sends a close frame with the given code and reason after the pending messages, then
+// closes the connection
+func (c *Client) Close(code int, reason string) {
+	c.record(JournalClose, nil, strconv.Itoa(code)+" "+reason)
+	frame := outboundFrame{messageType: websocket.CloseMessage, data: websocket.FormatCloseMessage(code, reason)}
+	if !c.enqueue(frame) {
+		c.Disconnect()
+	}
+}
+
+// Disconnect closes the connection immediately and stops the write pump. Pending messages are dropped.
+func (c *Client) Disconnect() {
+	c.closeOnce.Do(func() {
+		close(c.done)
+		c.conn.Close()
+	})
+}
+
+// WritePump writes queued messages and periodic pings to the connection until the client is
+// closed or a write fails
+func (c *Client) WritePump() {
+	pingTicker := time.NewTicker(pingPeriod)
+	defer func() {
+		pingTicker.Stop()
+		c.Disconnect()
+	}()
+
+	for {
+		select {
+		case frame := <-c.send:
+			if frame.messageType == websocket.TextMessage {
+				c.faults.Load().delay()
+			}
+			c.conn.SetWriteDeadline(time.Now().Add(writeWait))
+			if err := c.conn.WriteMessage(frame.messageType, frame.data); err != nil {
+				log.Printf("Hub Error: Failed to write message to user %d connection %p: %v", c.UserID, c.conn, err)
+				c.record(JournalWriteError, nil, err.Error())
+				return
+			}
+			if frame.onWritten != nil {
+				frame.onWritten()
+			}
+			if frame.messageType == websocket.CloseMessage {
+				return
+			}
+		case <-pingTicker.C:
+			c.conn.SetWriteDeadline(time.Now().Add(writeWait))
+			if err := c.conn.WriteMessage(websocket.PingMessage, nil); err != nil {
+				log.Printf("Hub Error: Failed to ping user %d connection %p: %v", c.UserID, c.conn, err)
+				return
+			}
+		case <-c.done:
+			return
+		}
+	}
+}
+
+// enqueue adds a frame to the send buffer without blocking
+func (c *Client) enqueue(frame outboundFrame) bool {
+	if lowBandwidth := c.lowBandwidth.Load(); lowBandwidth != nil && frame.messageType == websocket.TextMessage {
+		var send bool
+		if frame, send = lowBandwidth.thinOut(c, frame); !send {
+			return true // Suppressed, or sent later in a batch
+		}
+	}
+	return c.push(frame)
+}
+
+// push adds a frame to the send buffer without blocking, after low-bandwidth mode was applied
+func (c *Client) push(frame outboundFrame) bool {
+	select {
+	case <-c.done:
+		c.recordMessage(JournalDrop, frame, "connection closed")
+		return false
+	default:
+	}
+
+	if frame.messageType == websocket.TextMessage && c.faults.Load().shouldDrop() {
+		c.recordMessage(JournalDrop, frame, "fault injection")
+		return true // Lost on the way, as far as the caller can tell
+	}
+	if c.shed(frame) {
+		return true
+	}
+
+	select {
+	case c.send <- frame:
+		c.recordMessage(JournalSend, frame, "")
+		return true
+	default:
+		log.Printf("Hub Warning: Send buffer of user %d connection %p is full, disconnecting", c.UserID, c.conn)
+		c.recordMessage(JournalDrop, frame, "send buffer full, disconnecting")
+		metrics.ObserveDroppedEvent(ClassMessage.String())
+		c.Disconnect()
+		return false
+	}
+}
+
+// record adds an event of the connection to the hub journal, if enabled
+func (c *Client) record(event string, message []byte, detail string) {
+	journal := c.journal.Load()
+	if journal == nil {
+		return
+	}
+	entry := JournalEntry{Event: event, Connection: connectionID(c), Detail: detail}
+	if message != nil {
+		entry.Type = messageType(message)
+	}
+	journal.record(c.UserID, entry)
+}
+
+// recordMessage journals a text frame. Close frames are journaled by Close.
+func (c *Client) recordMessage(event string, frame outboundFrame, detail string) {
+	if frame.messageType == websocket.TextMessage {
+		c.record(event, frame.data, detail)
+	}
+}
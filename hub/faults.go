@@ -0,0 +1,68 @@
+package hub
+
+import (
+	"log"
+	"math/rand/v2"
+	"time"
+)
+
+// FaultInjection makes the hub misbehave on purpose, so the reconnection, replay and deduplication
+// logic of clients can be exercised in integration tests and staging. Never enable it in production.
+type FaultInjection struct {
+	DropPercent  int           // Share of outbound messages that are silently discarded, 0-100
+	MaxDelay     time.Duration // Outbound messages are written after a random delay up to it
+	KillInterval time.Duration // A random connection is dropped (without close frame) at this interval
+}
+
+// enabled reports whether any fault is configured
+func (f FaultInjection) enabled() bool {
+	return f.DropPercent > 0 || f.MaxDelay > 0 || f.KillInterval > 0
+}
+
+// InjectFaults enables fault injection for every connection. It must be called before Run.
+func (h *Hub) InjectFaults(faults FaultInjection) {
+	if !faults.enabled() {
+		return
+	}
+	faults.DropPercent = min(faults.DropPercent, 100)
+	h.faults = &faults
+}
+
+// shouldDrop decides whether an outbound message is discarded
+func (f *FaultInjection) shouldDrop() bool {
+	return f != nil && f.DropPercent > 0 && rand.IntN(100) < f.DropPercent
+}
+
+// delay waits a random part of MaxDelay before a write
+func (f *FaultInjection) delay() {
+	if f != nil && f.MaxDelay > 0 {
+		time.Sleep(rand.N(f.MaxDelay))
+	}
+}
+
+// killTicks returns the ticker channel of connection kills, or nil when disabled
+func (h *Hub) killTicks() (<-chan time.Time, func()) {
+	if h.faults == nil || h.faults.KillInterval <= 0 {
+		return nil, func() {}
+	}
+	ticker := time.NewTicker(h.faults.KillInterval)
+	return ticker.C, ticker.Stop
+}
+
+// killRandomConnection drops a random connection (only called from Run)
+func (h *Hub) killRandomConnection() {
+	var connections []*Client
+	for _, userClients := range h.clients {
+		for client := range userClients {
+			connections = append(connections, client)
+		}
+	}
+	if len(connections) == 0 {
+		return
+	}
+
+	victim := connections[rand.IntN(len(connections))]
+	log.Printf("Hub Chaos: Dropping connection %p of user %d", victim.conn, victim.UserID)
+	victim.record(JournalDrop, nil, "fault injection: connection killed")
+	victim.Disconnect()
+}
@@ -0,0 +1,123 @@
+package hub
+
+import (
+	"encoding/json"
+	"log"
+	"time"
+)
+
+// Warm handoff for blue/green deploys. When a draining instance closes its connections (CloseAll)
+// with a Broker, it first publishes the replay state of its users: their sequence numbers and the
+// events kept for replay. The other instances adopt the state of the users not connected to them.
+// A client reconnecting there keeps its sequence numbers and epoch, and gets exactly the events it
+// missed, including those sent while it was reconnecting (relayed to the new instance). Events
+// sent in the moment between the handoff and the close frame can arrive twice, with the same seq.
+
+// handoffPublishTimeout bounds how long CloseAll waits for the handoff to be published
+const handoffPublishTimeout = relayPublishTimeout
+
+// HandoffState is the replay state of a user handed off by a draining instance
+type HandoffState struct {
+	UserID          int32          `json:"user_id"`
+	Epoch           string         `json:"epoch"` // Of the user's sequence numbers
+	LastSeq         int64          `json:"last_seq"`
+	AckedSeq        int64          `json:"acked_seq"`
+	DroppedSeq      int64          `json:"dropped_seq"`
+	RetainDelivered bool           `json:"retain_delivered,omitempty"`
+	DisconnectedAt  time.Time      `json:"disconnected_at"` // When the user lost their last connection to the draining instance
+	Events          []HandoffEvent `json:"events,omitempty"`
+}
+
+// HandoffEvent is a buffered event in a HandoffState
+type HandoffEvent struct {
+	Seq      int64           `json:"seq"`
+	Message  json.RawMessage `json:"message"` // With its seq
+	QueuedAt time.Time       `json:"queued_at"`
+}
+
+// handOff publishes the replay state of every user with a live buffer and waits until it was
+// published or the timeout passed. The connections of the handed off users must be closed right
+// after, so that the events sent from now on are only numbered by the adopting instances.
+func (h *Hub) handOff() {
+	var states []HandoffState
+	published := make(chan struct{})
+	h.do(func() {
+		states = h.exportReplayState()
+		if len(states) > 0 {
+			h.publish(Envelope{Handoff: states, published: published})
+		}
+	})
+	if len(states) == 0 {
+		return
+	}
+
+	select {
+	case <-published:
+		log.Printf("Hub: Handed off the replay state of %d users", len(states))
+	case <-time.After(handoffPublishTimeout):
+		log.Printf("Hub Warning: Handoff of %d users not published within %s", len(states), handoffPublishTimeout)
+	}
+}
+
+// --- Run loop internals (only called from Run) ---
+
+// exportReplayState returns the state of the users who are connected or still within the replay TTL
+func (h *Hub) exportReplayState() []HandoffState {
+	now := time.Now()
+	states := make([]HandoffState, 0, len(h.replay))
+	for userID, buffer := range h.replay {
+		if buffer.expired() || buffer.lastSeq == 0 {
+			continue
+		}
+
+		state := HandoffState{
+			UserID:          userID,
+			Epoch:           h.epoch,
+			LastSeq:         buffer.lastSeq,
+			AckedSeq:        buffer.ackedSeq,
+			DroppedSeq:      buffer.droppedSeq,
+			RetainDelivered: buffer.retainDelivered,
+			DisconnectedAt:  buffer.disconnectedAt,
+			Events:          make([]HandoffEvent, len(buffer.events)),
+		}
+		if buffer.epoch != "" {
+			state.Epoch = buffer.epoch
+		}
+		if state.DisconnectedAt.IsZero() {
+			state.DisconnectedAt = now
+		}
+		for i, event := range buffer.events {
+			state.Events[i] = HandoffEvent{Seq: event.seq, Message: event.message, QueuedAt: event.queuedAt}
+		}
+		states = append(states, state)
+	}
+	return states
+}
+
+// adoptReplayState takes over the replay state handed off by another instance for the users not
+// connected to this one, replacing what this hub had for them
+func (h *Hub) adoptReplayState(origin string, states []HandoffState) {
+	adopted := 0
+	for _, state := range states {
+		if len(h.clients[state.UserID]) > 0 {
+			continue
+		}
+
+		buffer := &replayBuffer{
+			epoch:           state.Epoch,
+			lastSeq:         state.LastSeq,
+			ackedSeq:        state.AckedSeq,
+			droppedSeq:      state.DroppedSeq,
+			retainDelivered: state.RetainDelivered,
+			disconnectedAt:  state.DisconnectedAt,
+			events:          make([]bufferedEvent, len(state.Events)),
+		}
+		for i, event := range state.Events {
+			buffer.events[i] = bufferedEvent{seq: event.Seq, message: event.Message, queuedAt: event.QueuedAt}
+			buffer.size += len(event.Message)
+		}
+		h.replay[state.UserID] = buffer
+		adopted++
+	}
+	log.Printf("Hub: Adopted the replay state of %d of %d users handed off by instance %s", adopted, len(states), origin)
+}
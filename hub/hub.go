@@ -1,107 +1,367 @@
 package hub
 
 import (
-	"log" // Added for logging in Broadcast
-	"sync"
+	"context"
+	"errors"
+	"sort"
+	"strconv"
+	"time"
 
-	"github.com/gorilla/websocket"
+	"websocket-simple-chat-app/ratelimit"
 )
 
+// broadcastBufferSize is the number of broadcasts that can be queued before Broadcast blocks
+const broadcastBufferSize = 256
+
+// Hub keeps track of the active connections (clients) of every user.
+// All of its state is owned by the Run goroutine: the exported methods send requests over
+// channels instead of locking, so no two operations ever touch the maps concurrently.
 type Hub struct {
-	clients map[int32]map[*websocket.Conn]bool
+	clients map[int32]map[*Client]bool
+
+	// latencies holds the last round-trip time reported by each client
+	latencies map[*Client]time.Duration
+
+	// replay holds the event sequence state and replayable events of each user
+	replay map[int32]*replayBuffer
+	epoch  string // Identifies this hub's sequence numbering, and the instance to the Broker
+
+	// journal records recent hub events per user for debugging (nil unless enabled)
+	journal *journal
+
+	// faults makes connections misbehave for testing (nil unless enabled)
+	faults *FaultInjection
+
+	// messageLimiter rate limits inbound messages per user (nil unless enabled)
+	messageLimiter *ratelimit.Limiter[int32]
 
-	mu sync.RWMutex
+	// lowBandwidth thins out the events of low-bandwidth connections (nil unless enabled)
+	lowBandwidth *LowBandwidthMode
+
+	// eventClasses ranks event types for dropping under buffer pressure (nil unless set)
+	eventClasses map[string]EventClass
+
+	// broker relays events to the hubs of other instances (nil on a single instance)
+	broker Broker
+	relay  chan Envelope
+
+	register   chan registration
+	unregister chan registration
+	broadcast  chan broadcastMessage
+	requests   chan func() // Read and misc operations executed inside the run loop
 }
 
+// registration is a register/unregister request; result receives the first/last connection flag
+type registration struct {
+	client *Client
+	result chan bool
+}
+
+// broadcastMessage is a queued Broadcast call
+type broadcastMessage struct {
+	message       []byte
+	excludeUserID int32
+}
+
+// LatencyStats summarizes the round-trip times reported by connected clients
+type LatencyStats struct {
+	Connections int     `json:"connections"` // Number of connections that reported a latency
+	AvgMs       float64 `json:"avg_ms"`
+	P50Ms       float64 `json:"p50_ms"`
+	P95Ms       float64 `json:"p95_ms"`
+	MaxMs       float64 `json:"max_ms"`
+}
+
+// NewHub creates a Hub. Run must be started in its own goroutine before the hub is used.
 func NewHub() *Hub {
 	return &Hub{
-		clients: make(map[int32]map[*websocket.Conn]bool),
+		clients:    make(map[int32]map[*Client]bool),
+		latencies:  make(map[*Client]time.Duration),
+		replay:     make(map[int32]*replayBuffer),
+		epoch:      strconv.FormatInt(time.Now().UnixNano(), 36),
+		register:   make(chan registration),
+		unregister: make(chan registration),
+		broadcast:  make(chan broadcastMessage, broadcastBufferSize),
+		requests:   make(chan func()),
 	}
 }
 
-// Register adds a new connection for a given user.
-// It returns true if this was the user's first connection (meaning they just came online).
-func (h *Hub) Register(userID int32, conn *websocket.Conn) bool {
-	h.mu.Lock()
-	defer h.mu.Unlock()
+// Run processes hub requests until the process exits
+func (h *Hub) Run() {
+	sweepTicker := time.NewTicker(replaySweepInterval)
+	defer sweepTicker.Stop()
+	killTicks, stopKills := h.killTicks()
+	defer stopKills()
 
-	userConnections, ok := h.clients[userID]
-	isFirstConnection := !ok || len(userConnections) == 0
+	if h.broker != nil {
+		go h.runRelay()
+	}
 
-	if !ok {
-		userConnections = make(map[*websocket.Conn]bool)
-		h.clients[userID] = userConnections
+	for {
+		select {
+		case reg := <-h.register:
+			reg.result <- h.addConnection(reg.client)
+		case reg := <-h.unregister:
+			reg.result <- h.removeConnection(reg.client)
+		case msg := <-h.broadcast:
+			h.sendToAll(msg.message, msg.excludeUserID)
+		case fn := <-h.requests:
+			fn()
+		case <-sweepTicker.C:
+			h.sweepReplayBuffers()
+			h.journal.sweep()
+			if h.messageLimiter != nil {
+				h.messageLimiter.Sweep()
+			}
+		case <-killTicks:
+			h.killRandomConnection()
+		}
 	}
-	userConnections[conn] = true
+}
 
-	return isFirstConnection
+// do runs fn inside the run loop and waits for it to complete
+func (h *Hub) do(fn func()) {
+	done := make(chan struct{})
+	h.requests <- func() {
+		fn()
+		close(done)
+	}
+	<-done
 }
 
-// Unregister removes a connection for a given user.
+// Ready reports whether the run loop is processing requests and the broker, if any, is reachable
+func (h *Hub) Ready(ctx context.Context) error {
+	done := make(chan struct{})
+	select {
+	case h.requests <- func() { close(done) }:
+	case <-ctx.Done():
+		return errors.New("run loop is not responding")
+	}
+	<-done
+	if h.broker != nil {
+		return h.broker.Ping(ctx)
+	}
+	return nil
+}
+
+// Register adds a new connection of the client's user.
+// It returns true if this was the user's first connection (meaning they just came online).
+func (h *Hub) Register(client *Client) bool {
+	result := make(chan bool, 1)
+	h.register <- registration{client: client, result: result}
+	return <-result
+}
+
+// Unregister removes a connection of the client's user.
 // It returns true if this was the user's last connection (meaning they just went offline).
-func (h *Hub) Unregister(userID int32, conn *websocket.Conn) bool {
-	h.mu.Lock()
-	defer h.mu.Unlock()
+func (h *Hub) Unregister(client *Client) bool {
+	result := make(chan bool, 1)
+	h.unregister <- registration{client: client, result: result}
+	return <-result
+}
 
-	userConnections, ok := h.clients[userID]
-	if !ok {
-		return false
+// GetUserClients returns the active connections of a given user.
+// It returns an empty slice if the user is not connected or not found.
+func (h *Hub) GetUserClients(userID int32) []*Client {
+	var clients []*Client
+	h.do(func() {
+		userClients := h.clients[userID]
+
+		clients = make([]*Client, 0, len(userClients))
+		for client := range userClients {
+			clients = append(clients, client)
+		}
+	})
+	return clients
+}
+
+// Broadcast sends a message to all connected clients, optionally excluding one user.
+// If excludeUserID is 0 or a non-existent ID, the message is sent to everyone.
+// The message is queued and sent asynchronously by the run loop (and relayed to other instances).
+func (h *Hub) Broadcast(message []byte, excludeUserID int32) {
+	h.broadcast <- broadcastMessage{message: message, excludeUserID: excludeUserID}
+	h.publish(Envelope{Broadcast: true, ExcludeUserID: excludeUserID, Message: message})
+}
+
+// SendToUsers sends a live-only message to every connection of the given users, on this and the
+// other instances. Users without a connection miss it (nothing is queued).
+func (h *Hub) SendToUsers(userIDs []int32, message []byte) {
+	h.do(func() {
+		for _, userID := range userIDs {
+			for client := range h.clients[userID] {
+				client.Send(message)
+			}
+		}
+	})
+	h.publish(Envelope{UserIDs: userIDs, Message: message})
+}
+
+// CloseAll sends a close frame with the given code and reason to every connection, after their
+// pending messages, and returns the users that were connected. The connections unregister
+// themselves once their read loops see the close. With a Broker, the replay state of the users is
+// handed off to the other instances first.
+func (h *Hub) CloseAll(code int, reason string) []int32 {
+	if h.broker != nil {
+		h.handOff()
 	}
 
-	delete(userConnections, conn)
+	var userIDs []int32
+	h.do(func() {
+		userIDs = make([]int32, 0, len(h.clients))
+		for userID, userClients := range h.clients {
+			userIDs = append(userIDs, userID)
+			for client := range userClients {
+				client.Close(code, reason)
+			}
+		}
+	})
+	return userIDs
+}
 
-	isLastConnection := len(userConnections) == 0
-	if isLastConnection {
-		delete(h.clients, userID)
+// DisconnectUser closes every connection of the user, on this and the other instances, with a close
+// frame with the given code and reason after their pending messages. It returns the number of
+// connections closed on this instance. The connections unregister themselves as usual, which takes
+// the user offline.
+func (h *Hub) DisconnectUser(userID int32, code int, reason string) int {
+	var closed int
+	h.do(func() { closed = h.closeUser(userID, code, reason) })
+	h.publish(Envelope{UserIDs: []int32{userID}, CloseCode: code, CloseReason: reason})
+	return closed
+}
+
+// closeUser closes the local connections of a user (only called from Run)
+func (h *Hub) closeUser(userID int32, code int, reason string) int {
+	for client := range h.clients[userID] {
+		client.Close(code, reason)
 	}
+	return len(h.clients[userID])
+}
 
-	return isLastConnection
+// ConnectionCount returns the number of registered connections
+func (h *Hub) ConnectionCount() int {
+	var count int
+	h.do(func() {
+		for _, userClients := range h.clients {
+			count += len(userClients)
+		}
+	})
+	return count
 }
 
-// GetUserConnections returns a slice of active connections for a given user.
-// It returns an empty slice if the user is not connected or not found.
-func (h *Hub) GetUserConnections(userID int32) []*websocket.Conn {
-	h.mu.RLock() // Use Read Lock for reading
-	defer h.mu.RUnlock()
+// ConnectedUserIDs returns the users with at least one registered connection
+func (h *Hub) ConnectedUserIDs() []int32 {
+	var userIDs []int32
+	h.do(func() {
+		userIDs = make([]int32, 0, len(h.clients))
+		for userID := range h.clients {
+			userIDs = append(userIDs, userID)
+		}
+	})
+	return userIDs
+}
 
-	userConnectionsMap, ok := h.clients[userID]
-	if !ok {
-		return []*websocket.Conn{} // Return empty slice if user not found
+// SetLatency records the last round-trip time measured for a connection.
+// Latencies of clients that are not registered are ignored.
+func (h *Hub) SetLatency(client *Client, rtt time.Duration) {
+	h.do(func() {
+		if _, ok := h.clients[client.UserID][client]; !ok {
+			return
+		}
+		h.latencies[client] = rtt
+	})
+}
+
+// LatencyStats returns aggregated round-trip times over all connections that reported one
+func (h *Hub) LatencyStats() LatencyStats {
+	var samples []time.Duration
+	h.do(func() {
+		samples = make([]time.Duration, 0, len(h.latencies))
+		for _, rtt := range h.latencies {
+			samples = append(samples, rtt)
+		}
+	})
+
+	stats := LatencyStats{Connections: len(samples)}
+	if len(samples) == 0 {
+		return stats
 	}
 
-	// Create a slice to hold the connections
-	connections := make([]*websocket.Conn, 0, len(userConnectionsMap))
-	for conn := range userConnectionsMap {
-		connections = append(connections, conn)
+	sort.Slice(samples, func(i, j int) bool { return samples[i] < samples[j] })
+	var total time.Duration
+	for _, rtt := range samples {
+		total += rtt
 	}
-	return connections
+	toMs := func(d time.Duration) float64 { return float64(d) / float64(time.Millisecond) }
+
+	stats.AvgMs = toMs(total / time.Duration(len(samples)))
+	stats.P50Ms = toMs(samples[len(samples)*50/100])
+	stats.P95Ms = toMs(samples[len(samples)*95/100])
+	stats.MaxMs = toMs(samples[len(samples)-1])
+	return stats
 }
 
-// Broadcast sends a message to all connected clients, optionally excluding one user.
-// If excludeUserID is 0 or a non-existent ID, the message is sent to everyone.
-func (h *Hub) Broadcast(message []byte, excludeUserID int32) {
-	h.mu.RLock() // Use Read Lock as we are only reading the client list
-	defer h.mu.RUnlock()
+// --- Run loop internals (only called from Run) ---
+
+func (h *Hub) addConnection(client *Client) bool {
+	userClients, ok := h.clients[client.UserID]
+	isFirstConnection := !ok || len(userClients) == 0
+
+	if !ok {
+		userClients = make(map[*Client]bool)
+		h.clients[client.UserID] = userClients
+	}
+	userClients[client] = true
+	if h.faults != nil {
+		client.faults.Store(h.faults)
+	}
+	if h.messageLimiter != nil {
+		client.limiter.Store(h.messageLimiter)
+	}
+	if h.lowBandwidth != nil && client.Capabilities.Supports(CapabilityLowBandwidth) {
+		client.lowBandwidth.Store(&lowBandwidthState{mode: h.lowBandwidth})
+	}
+	if h.eventClasses != nil {
+		client.eventClasses.Store(&h.eventClasses)
+	}
+	if h.journal != nil {
+		client.journal.Store(h.journal)
+		client.record(JournalRegister, nil, "connections: "+strconv.Itoa(len(userClients)))
+	}
+
+	// Deliver what the user missed during a short disconnect
+	h.replayToConnection(client)
+
+	return isFirstConnection
+}
+
+func (h *Hub) removeConnection(client *Client) bool {
+	userClients, ok := h.clients[client.UserID]
+	if !ok {
+		return false
+	}
+
+	delete(userClients, client)
+	delete(h.latencies, client)
+	client.record(JournalUnregister, nil, "connections: "+strconv.Itoa(len(userClients)))
+
+	isLastConnection := len(userClients) == 0
+	if isLastConnection {
+		delete(h.clients, client.UserID)
+		h.startReplayTTL(client.UserID)
+	}
+
+	return isLastConnection
+}
 
-	for userID, userConnections := range h.clients {
+func (h *Hub) sendToAll(message []byte, excludeUserID int32) {
+	for userID, userClients := range h.clients {
 		if userID == excludeUserID {
 			continue // Skip the excluded user
 		}
 
-		for conn := range userConnections {
-			// Use a separate goroutine for each write to avoid blocking the broadcast loop
-			// if one connection is slow or unresponsive.
-			go func(c *websocket.Conn) {
-				// It's generally safer to use WriteMessage within its own lock if the connection
-				// object itself isn't inherently thread-safe for concurrent writes,
-				// although Gorilla WebSocket's default implementation usually handles this.
-				// However, for simplicity here, we assume concurrent writes are safe or handled by the library.
-				if err := c.WriteMessage(websocket.TextMessage, message); err != nil {
-					// Log the error, but don't stop broadcasting to others.
-					// The connection's own read loop should handle the disconnection.
-					log.Printf("Broadcast Error: Failed to write message to user %d connection %p: %v", userID, c, err)
-				}
-			}(conn)
+		// Send never blocks: a slow connection is disconnected instead of stalling the run loop
+		for client := range userClients {
+			client.Send(message)
 		}
 	}
 }
@@ -0,0 +1,108 @@
+package hub
+
+import (
+	"strconv"
+	"testing"
+	"time"
+)
+
+// The run loop serializes every hub operation, so its cost per operation has to stay flat as the
+// number of connections grows. Run with: go test ./hub -run '^$' -bench . -benchmem
+
+// benchmarkConnections are the hub sizes benchmarked
+var benchmarkConnections = []int{10_000, 100_000}
+
+// nopConn is a Conn that discards everything
+type nopConn struct{}
+
+func (nopConn) WriteMessage(int, []byte) error   { return nil }
+func (nopConn) SetWriteDeadline(time.Time) error { return nil }
+func (nopConn) Close() error                     { return nil }
+
+var benchmarkMessage = []byte(`{"type":"incoming_message","sender_id":1,"sender_username":"alice","content":"hello","preview":"hello","created_at":"2026-01-01T00:00:00Z"}`)
+
+// newBenchmarkHub runs a hub with one connection for each of n users, whose write pumps drain
+// them over a nopConn. The connections are closed and unregistered when the benchmark ends, as Run
+// never returns.
+func newBenchmarkHub(b *testing.B, n int) (*Hub, []*Client) {
+	b.Helper()
+	h := NewHub()
+	go h.Run()
+
+	clients := make([]*Client, n)
+	for i := range clients {
+		clients[i] = NewStreamClient(int32(i+1), nopConn{}, Capabilities{})
+		go clients[i].WritePump()
+		h.Register(clients[i])
+	}
+	b.Cleanup(func() {
+		for _, client := range clients {
+			client.Disconnect()
+			h.Unregister(client)
+		}
+	})
+	return h, clients
+}
+
+// checkNoneDropped fails the benchmark if a connection fell behind and was disconnected, as the
+// remaining operations would then have been cheaper than in a healthy hub
+func checkNoneDropped(b *testing.B, clients []*Client) {
+	b.Helper()
+	for _, client := range clients {
+		select {
+		case <-client.done:
+			b.Fatalf("connection of user %d was disconnected", client.UserID)
+		default:
+		}
+	}
+}
+
+func BenchmarkRegisterUnregister(b *testing.B) {
+	for _, n := range benchmarkConnections {
+		b.Run(strconv.Itoa(n), func(b *testing.B) {
+			h, _ := newBenchmarkHub(b, n)
+			client := NewStreamClient(int32(n+1), nopConn{}, Capabilities{})
+
+			b.ResetTimer()
+			for range b.N {
+				h.Register(client)
+				h.Unregister(client)
+			}
+		})
+	}
+}
+
+func BenchmarkSendOrQueue(b *testing.B) {
+	for _, n := range benchmarkConnections {
+		b.Run(strconv.Itoa(n), func(b *testing.B) {
+			h, clients := newBenchmarkHub(b, n)
+
+			b.ResetTimer()
+			for i := range b.N {
+				h.SendOrQueue(int32(i%n+1), benchmarkMessage)
+			}
+			b.StopTimer()
+			checkNoneDropped(b, clients)
+		})
+	}
+}
+
+func BenchmarkBroadcast(b *testing.B) {
+	for _, n := range benchmarkConnections {
+		b.Run(strconv.Itoa(n), func(b *testing.B) {
+			h, clients := newBenchmarkHub(b, n)
+
+			b.ResetTimer()
+			for range b.N {
+				h.Broadcast(benchmarkMessage, 0)
+			}
+			// Broadcasts are queued: wait until the run loop took the last one, then until it was sent
+			for len(h.broadcast) > 0 {
+				time.Sleep(time.Millisecond)
+			}
+			h.do(func() {})
+			b.StopTimer()
+			checkNoneDropped(b, clients)
+		})
+	}
+}
@@ -0,0 +1,132 @@
+package hub
+
+import (
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// journalRetention is how long the journal of a user who had no hub event is kept
+const journalRetention = time.Hour
+
+// Journal event kinds
+const (
+	JournalRegister   = "register"    // A connection registered
+	JournalUnregister = "unregister"  // A connection unregistered
+	JournalReplay     = "replay"      // Buffered events were flushed to a new connection
+	JournalSend       = "send"        // A message was queued on a connection's send buffer
+	JournalDrop       = "drop"        // A message was not queued: the connection is closed or too slow
+	JournalQueue      = "queue"       // A message was buffered while the user has no connection
+	JournalMiss       = "miss"        // A message was not delivered: the user is offline for longer than the replay TTL
+	JournalClose      = "close"       // A close frame was queued on a connection
+	JournalWriteError = "write_error" // Writing to a connection failed, which ends it
+)
+
+// JournalEntry is a hub event that concerned a user
+type JournalEntry struct {
+	At         time.Time `json:"at"`
+	Event      string    `json:"event"`
+	Connection string    `json:"connection,omitempty"` // Connection address, as in the server logs
+	Type       string    `json:"type,omitempty"`       // Type of the message, for message events
+	Detail     string    `json:"detail,omitempty"`
+}
+
+// journal keeps the recent hub events of every user in fixed-size rings. Connections record their
+// events from their own goroutines, so unlike the rest of the hub it has a lock.
+type journal struct {
+	mu    sync.Mutex
+	size  int
+	rings map[int32]*journalRing
+}
+
+type journalRing struct {
+	entries []JournalEntry
+	next    int // Index the next entry is written to once the ring is full
+	lastAt  time.Time
+}
+
+// EnableJournal keeps the last size hub events of every user (registrations, sends, drops, ...)
+// for debugging, see Journal. It must be called before Run.
+func (h *Hub) EnableJournal(size int) {
+	h.journal = &journal{size: size, rings: make(map[int32]*journalRing)}
+}
+
+// Journal returns the recorded hub events of a user, oldest first. It returns false if the journal
+// is not enabled.
+func (h *Hub) Journal(userID int32) ([]JournalEntry, bool) {
+	if h.journal == nil {
+		return nil, false
+	}
+	return h.journal.entries(userID), true
+}
+
+func (j *journal) record(userID int32, entry JournalEntry) {
+	if j == nil {
+		return
+	}
+	entry.At = time.Now().UTC()
+
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	ring, ok := j.rings[userID]
+	if !ok {
+		ring = &journalRing{entries: make([]JournalEntry, 0, j.size)}
+		j.rings[userID] = ring
+	}
+	if len(ring.entries) < j.size {
+		ring.entries = append(ring.entries, entry)
+	} else {
+		ring.entries[ring.next] = entry
+		ring.next = (ring.next + 1) % j.size
+	}
+	ring.lastAt = entry.At
+}
+
+// recordMessage records an event about a message that did not reach a connection
+func (j *journal) recordMessage(userID int32, event string, message []byte, detail string) {
+	if j == nil {
+		return
+	}
+	j.record(userID, JournalEntry{Event: event, Type: messageType(message), Detail: detail})
+}
+
+func (j *journal) entries(userID int32) []JournalEntry {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	ring, ok := j.rings[userID]
+	if !ok {
+		return []JournalEntry{}
+	}
+	entries := make([]JournalEntry, 0, len(ring.entries))
+	entries = append(entries, ring.entries[ring.next:]...)
+	return append(entries, ring.entries[:ring.next]...)
+}
+
+// sweep frees the journals of users without events for journalRetention
+func (j *journal) sweep() {
+	if j == nil {
+		return
+	}
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	for userID, ring := range j.rings {
+		if time.Since(ring.lastAt) > journalRetention {
+			delete(j.rings, userID)
+		}
+	}
+}
+
+// connectionID identifies a connection in journal entries, matching the %p of the connection in the logs
+func connectionID(client *Client) string {
+	return fmt.Sprintf("%p", client.conn)
+}
+
+// messageType extracts the type of a JSON event for journal entries
+func messageType(message []byte) string {
+	var event struct {
+		Type string `json:"type"`
+	}
+	json.Unmarshal(message, &event)
+	return event.Type
+}
@@ -0,0 +1,135 @@
+package hub
+
+import (
+	"bytes"
+	"encoding/json"
+	"sync"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+// CapabilityLowBandwidth is declared by clients on metered or slow connections. Their events are
+// thinned out as configured with UseLowBandwidthMode.
+const CapabilityLowBandwidth = "low_bandwidth"
+
+// LowBandwidthMode says how the events of low-bandwidth connections are thinned out
+type LowBandwidthMode struct {
+	Suppress      map[string]bool // Event types that are not sent at all
+	Batch         map[string]bool // Event types held back and sent together in one "batch" event
+	BatchInterval time.Duration   // How long batched events are held back
+	StripFields   []string        // Optional top-level fields removed from every event
+
+	// CoalesceKey optionally groups batched events that supersede each other: of the events of a
+	// batch with the same non-empty key, only the last one is sent
+	CoalesceKey func(eventType string, message []byte) string
+}
+
+// BatchMessage carries the events held back for a low-bandwidth connection, oldest first
+//
+//wsschema:server
+type BatchMessage struct {
+	Type      string            `json:"type"` // "batch"
+	Events    []json.RawMessage `json:"events"`
+	CreatedAt time.Time         `json:"created_at"`
+}
+
+// lowBandwidthState is the batch of one low-bandwidth connection
+type lowBandwidthState struct {
+	mode *LowBandwidthMode
+
+	mu      sync.Mutex
+	pending [][]byte
+	keys    []string
+	timer   *time.Timer // Armed while events are pending
+}
+
+// UseLowBandwidthMode enables low-bandwidth mode for connections declaring CapabilityLowBandwidth.
+// It must be called before Run.
+func (h *Hub) UseLowBandwidthMode(mode LowBandwidthMode) {
+	h.lowBandwidth = &mode
+}
+
+// thinOut applies low-bandwidth mode to a text frame. It returns false if the frame is not to be
+// sent now, because it is suppressed or held back for the next batch.
+func (s *lowBandwidthState) thinOut(c *Client, frame outboundFrame) (outboundFrame, bool) {
+	eventType := messageType(frame.data)
+	if frame.onWritten == nil { // Frames someone waits for are never held back
+		if s.mode.Suppress[eventType] {
+			c.record(JournalDrop, frame.data, "low bandwidth: suppressed")
+			return frame, false
+		}
+		if s.mode.Batch[eventType] {
+			s.hold(c, eventType, s.strip(frame.data))
+			return frame, false
+		}
+	}
+	frame.data = s.strip(frame.data)
+	return frame, true
+}
+
+// strip removes the optional fields from an event
+func (s *lowBandwidthState) strip(message []byte) []byte {
+	var event map[string]json.RawMessage
+	for _, field := range s.mode.StripFields {
+		if !bytes.Contains(message, []byte(`"`+field+`"`)) {
+			continue
+		}
+		if event == nil && json.Unmarshal(message, &event) != nil {
+			return message
+		}
+		delete(event, field)
+	}
+	if event == nil {
+		return message
+	}
+	stripped, err := json.Marshal(event)
+	if err != nil {
+		return message
+	}
+	return stripped
+}
+
+// hold adds an event to the pending batch and arms the flush timer
+func (s *lowBandwidthState) hold(c *Client, eventType string, message []byte) {
+	key := ""
+	if s.mode.CoalesceKey != nil {
+		key = s.mode.CoalesceKey(eventType, message)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.pending = append(s.pending, message)
+	s.keys = append(s.keys, key)
+	if s.timer == nil {
+		s.timer = time.AfterFunc(s.mode.BatchInterval, func() { s.flush(c) })
+	}
+}
+
+// flush sends the pending events as one batch event
+func (s *lowBandwidthState) flush(c *Client) {
+	s.mu.Lock()
+	pending, keys := s.pending, s.keys
+	s.pending, s.keys, s.timer = nil, nil, nil
+	s.mu.Unlock()
+
+	// Keep the last event of each key, in the order they were held back
+	last := make(map[string]int, len(keys))
+	for i, key := range keys {
+		if key != "" {
+			last[key] = i
+		}
+	}
+	events := make([]json.RawMessage, 0, len(pending))
+	for i, message := range pending {
+		if keys[i] == "" || last[keys[i]] == i {
+			events = append(events, message)
+		}
+	}
+
+	batch, err := json.Marshal(BatchMessage{Type: "batch", Events: events, CreatedAt: time.Now().UTC()})
+	if err != nil {
+		return
+	}
+	c.push(outboundFrame{messageType: websocket.TextMessage, data: batch})
+}
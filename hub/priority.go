@@ -0,0 +1,68 @@
+package hub
+
+import (
+	"github.com/gorilla/websocket"
+
+	"websocket-simple-chat-app/metrics"
+)
+
+// EventClass ranks events by how much a user loses when one is not delivered. When a connection's
+// send buffer fills up, events of the lower classes are dropped first, each class from its own
+// fill level on, so a slow connection keeps getting messages as long as possible.
+type EventClass int
+
+const (
+	ClassMessage  EventClass = iota // Messages and unclassified events: never dropped, a full buffer disconnects
+	ClassReceipt                    // Read receipts: dropped from 3/4 of the buffer on
+	ClassTyping                     // Typing indicators: dropped from 5/8 of the buffer on
+	ClassPresence                   // Presence changes: dropped from half of the buffer on
+)
+
+// classDropLevels is the number of pending frames from which events of a class are dropped
+var classDropLevels = map[EventClass]int{
+	ClassReceipt:  sendBufferSize * 3 / 4,
+	ClassTyping:   sendBufferSize * 5 / 8,
+	ClassPresence: sendBufferSize / 2,
+}
+
+// minClassDropLevel is the lowest of classDropLevels: below it, events are not even classified
+const minClassDropLevel = sendBufferSize / 2
+
+func (c EventClass) String() string {
+	switch c {
+	case ClassReceipt:
+		return "receipt"
+	case ClassTyping:
+		return "typing"
+	case ClassPresence:
+		return "presence"
+	default:
+		return "message"
+	}
+}
+
+// ClassifyEvents sets the class of event types. Types that are not listed are ClassMessage.
+// It must be called before Run.
+func (h *Hub) ClassifyEvents(classes map[string]EventClass) {
+	h.eventClasses = classes
+}
+
+// shed reports whether a frame should be dropped because the send buffer is under pressure
+func (c *Client) shed(frame outboundFrame) bool {
+	pending := len(c.send)
+	if frame.messageType != websocket.TextMessage || pending < minClassDropLevel {
+		return false
+	}
+	classes := c.eventClasses.Load()
+	if classes == nil {
+		return false
+	}
+	class := (*classes)[messageType(frame.data)]
+	level, droppable := classDropLevels[class]
+	if !droppable || pending < level {
+		return false
+	}
+	c.recordMessage(JournalDrop, frame, "send buffer under pressure: "+class.String())
+	metrics.ObserveDroppedEvent(class.String())
+	return true
+}
@@ -0,0 +1,36 @@
+package hub
+
+import (
+	"websocket-simple-chat-app/ratelimit"
+)
+
+// MessageRateLimit bounds the messages a user sends over all their connections to this instance:
+// Rate messages per second on average, in bursts of up to Burst messages
+type MessageRateLimit struct {
+	Rate  float64
+	Burst int
+}
+
+// LimitMessages enables the per-user rate limit of inbound messages, see Client.AllowMessage.
+// It must be called before Run.
+func (h *Hub) LimitMessages(limit MessageRateLimit) {
+	if limit.Rate <= 0 || limit.Burst <= 0 {
+		return
+	}
+	h.messageLimiter = ratelimit.New[int32](limit.Rate, limit.Burst)
+}
+
+// AllowMessage takes a token from the user's bucket for a message the connection received. The
+// result tells whether the message is allowed and how close the user is to the limit. It always
+// allows messages when no limit is configured, with a zero Burst.
+func (c *Client) AllowMessage() ratelimit.Result {
+	limiter := c.limiter.Load()
+	if limiter == nil {
+		return ratelimit.Result{Allowed: true}
+	}
+	result := limiter.Take(c.UserID)
+	if !result.Allowed {
+		c.record(JournalDrop, nil, "inbound message rate limited")
+	}
+	return result
+}
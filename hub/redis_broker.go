@@ -0,0 +1,75 @@
+package hub
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// RedisBroker is a Broker using Redis pub/sub on a single channel
+type RedisBroker struct {
+	client  *redis.Client
+	channel string
+}
+
+// NewRedisBroker connects to the Redis server at url (e.g. "redis://localhost:6379/0")
+func NewRedisBroker(url string, channel string) (*RedisBroker, error) {
+	options, err := redis.ParseURL(url)
+	if err != nil {
+		return nil, err
+	}
+
+	client := redis.NewClient(options)
+	if err := client.Ping(context.Background()).Err(); err != nil {
+		client.Close()
+		return nil, err
+	}
+	return &RedisBroker{client: client, channel: channel}, nil
+}
+
+// Publish sends the envelope to every subscribed instance
+func (b *RedisBroker) Publish(ctx context.Context, envelope Envelope) error {
+	data, err := json.Marshal(envelope)
+	if err != nil {
+		return err
+	}
+	return b.client.Publish(ctx, b.channel, data).Err()
+}
+
+// Ping checks the connection to the Redis server
+func (b *RedisBroker) Ping(ctx context.Context) error {
+	return b.client.Ping(ctx).Err()
+}
+
+// Subscribe calls handle for every envelope on the channel. go-redis reconnects dropped
+// subscriptions by itself; envelopes published in the meantime are lost.
+func (b *RedisBroker) Subscribe(ctx context.Context, handle func(Envelope)) error {
+	subscription := b.client.Subscribe(ctx, b.channel)
+	defer subscription.Close()
+
+	// Wait for the subscription to be confirmed
+	if _, err := subscription.Receive(ctx); err != nil {
+		return err
+	}
+
+	messages := subscription.Channel()
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case message, ok := <-messages:
+			if !ok {
+				return errors.New("subscription closed")
+			}
+			var envelope Envelope
+			if err := json.Unmarshal([]byte(message.Payload), &envelope); err != nil {
+				log.Printf("Hub Error: Invalid envelope on %s: %v", b.channel, err)
+				continue
+			}
+			handle(envelope)
+		}
+	}
+}
@@ -0,0 +1,259 @@
+package hub
+
+import (
+	"log"
+	"strconv"
+	"time"
+)
+
+// Replay buffer limits. Every event sent with SendOrQueue gets a per-user sequence number ("seq").
+// Events for a user who disconnected less than replayTTL ago are kept in memory (at most
+// replayMaxEvents / replayMaxBytes per user, oldest dropped first) and flushed to their next
+// connection. Once a connection with the sync capability registered, delivered events are kept
+// as well, until the user acks them.
+const (
+	replayTTL           = 2 * time.Minute
+	replayMaxEvents     = 100
+	replayMaxBytes      = 256 * 1024
+	replaySweepInterval = 30 * time.Second
+)
+
+// CapabilitySync is declared by clients that store events durably and ack them (POST /sync/ack).
+// Such connections get every unacked event replayed when they connect.
+const CapabilitySync = "sync"
+
+// bufferedEvent is a sequenced message kept for replay
+type bufferedEvent struct {
+	seq      int64
+	message  []byte
+	queuedAt time.Time
+}
+
+// replayBuffer holds the sequence state and the replayable events of one user
+type replayBuffer struct {
+	epoch           string // Of a numbering handed off by another instance, empty for the hub's own
+	lastSeq         int64
+	ackedSeq        int64
+	droppedSeq      int64     // Highest seq that is gone without being acked
+	retainDelivered bool      // Set once a sync connection registered
+	disconnectedAt  time.Time // Zero while the user is connected
+	events          []bufferedEvent
+	size            int // Total bytes of buffered messages
+}
+
+// Checkpoint is the sync state of a user, as returned by GET /sync/checkpoint
+type Checkpoint struct {
+	Epoch          string `json:"epoch"`           // Changes when the server restarts: sequence numbers start over, unless handed off
+	AckedSeq       int64  `json:"acked_seq"`       // Last event the user acknowledged
+	LatestSeq      int64  `json:"latest_seq"`      // Last event sent to the user
+	ReplayComplete bool   `json:"replay_complete"` // Whether every event after acked_seq can still be replayed
+}
+
+// expired reports whether the user has been gone for longer than the TTL
+func (b *replayBuffer) expired() bool {
+	return !b.disconnectedAt.IsZero() && time.Since(b.disconnectedAt) > replayTTL
+}
+
+// push appends an event, dropping the oldest ones to stay within the limits
+func (b *replayBuffer) push(seq int64, message []byte) {
+	b.events = append(b.events, bufferedEvent{seq: seq, message: message, queuedAt: time.Now()})
+	b.size += len(message)
+
+	for len(b.events) > replayMaxEvents || (b.size > replayMaxBytes && len(b.events) > 1) {
+		b.droppedSeq = b.events[0].seq
+		b.size -= len(b.events[0].message)
+		b.events = b.events[1:]
+	}
+}
+
+// dropEvents frees all buffered events
+func (b *replayBuffer) dropEvents() {
+	if len(b.events) > 0 {
+		b.droppedSeq = b.events[len(b.events)-1].seq
+	}
+	b.events = nil
+	b.size = 0
+}
+
+// ack records that the user stored every event up to seq and frees them
+func (b *replayBuffer) ack(seq int64) {
+	seq = min(seq, b.lastSeq)
+	if seq <= b.ackedSeq {
+		return
+	}
+	b.ackedSeq = seq
+
+	acked := 0
+	for acked < len(b.events) && b.events[acked].seq <= seq {
+		b.size -= len(b.events[acked].message)
+		acked++
+	}
+	b.events = b.events[acked:]
+}
+
+// pending returns the events a new connection should receive: every unacked event for sync
+// connections, only the events queued while the user was offline for the others
+func (b *replayBuffer) pending(sync bool) [][]byte {
+	messages := make([][]byte, 0, len(b.events))
+	for _, event := range b.events {
+		if sync || (!b.disconnectedAt.IsZero() && !event.queuedAt.Before(b.disconnectedAt)) {
+			messages = append(messages, event.message)
+		}
+	}
+	return messages
+}
+
+// withSeq adds the sequence number as the first field of a JSON object message
+func withSeq(message []byte, seq int64) []byte {
+	if len(message) < 2 || message[0] != '{' {
+		return message
+	}
+	stamped := []byte(`{"seq":` + strconv.FormatInt(seq, 10))
+	if message[1] != '}' {
+		stamped = append(stamped, ',')
+	}
+	return append(stamped, message[1:]...)
+}
+
+// SendOrQueue sends the message to all connections of the user. If the user has no connection
+// but disconnected recently, the message is queued and delivered when they reconnect.
+// It returns false if the user is offline for longer than the queue TTL (the message is dropped).
+// With a Broker, the other instances do the same for the user's connections there; the result
+// only covers this instance.
+func (h *Hub) SendOrQueue(userID int32, message []byte) bool {
+	var accepted bool
+	h.do(func() {
+		accepted = h.deliver(userID, func(Capabilities) []byte { return message })
+	})
+	h.publish(Envelope{UserIDs: []int32{userID}, Queue: true, Message: message})
+	return accepted
+}
+
+// SendOrQueueToUsers is SendOrQueue for a group of users (e.g. the members of a room), in a single run loop request
+func (h *Hub) SendOrQueueToUsers(userIDs []int32, message []byte) {
+	h.do(func() {
+		for _, userID := range userIDs {
+			h.deliver(userID, func(Capabilities) []byte { return message })
+		}
+	})
+	h.publish(Envelope{UserIDs: userIDs, Queue: true, Message: message})
+}
+
+// Checkpoint returns the sync state of a user
+func (h *Hub) Checkpoint(userID int32) Checkpoint {
+	var checkpoint Checkpoint
+	h.do(func() {
+		checkpoint = h.checkpoint(userID)
+	})
+	return checkpoint
+}
+
+// Ack records that the user durably stored every event up to seq. Acked events are freed and
+// no longer replayed. Sequence numbers beyond the latest event are clamped.
+func (h *Hub) Ack(userID int32, seq int64) Checkpoint {
+	var checkpoint Checkpoint
+	h.do(func() {
+		if buffer, ok := h.replay[userID]; ok {
+			buffer.ack(seq)
+		}
+		checkpoint = h.checkpoint(userID)
+	})
+	return checkpoint
+}
+
+// --- Run loop internals (only called from Run) ---
+
+// deliver stamps the next sequence number of the user on the event, sends it to their connections
+// and buffers it for replay. Buffered events are rendered with the zero Capabilities.
+func (h *Hub) deliver(userID int32, render func(Capabilities) []byte) bool {
+	return h.deliverAndNotify(userID, render, nil)
+}
+
+// deliverAndNotify is like deliver, and calls onWritten (if not nil) each time a connection wrote the event
+func (h *Hub) deliverAndNotify(userID int32, render func(Capabilities) []byte, onWritten func()) bool {
+	userClients := h.clients[userID]
+	buffer, ok := h.replay[userID]
+	if len(userClients) == 0 && (!ok || buffer.expired()) {
+		h.journal.recordMessage(userID, JournalMiss, render(Capabilities{}), "")
+		return false
+	}
+
+	buffer.lastSeq++
+	seq := buffer.lastSeq
+	if len(userClients) == 0 || buffer.retainDelivered {
+		message := render(Capabilities{})
+		buffer.push(seq, withSeq(message, seq))
+		if len(userClients) == 0 {
+			h.journal.recordMessage(userID, JournalQueue, message, "seq "+strconv.FormatInt(seq, 10))
+		}
+	} else {
+		buffer.droppedSeq = seq
+	}
+
+	for client := range userClients {
+		client.SendAndNotify(withSeq(render(client.Capabilities), seq), onWritten)
+	}
+	return true
+}
+
+func (h *Hub) checkpoint(userID int32) Checkpoint {
+	checkpoint := Checkpoint{Epoch: h.epoch, ReplayComplete: true}
+	if buffer, ok := h.replay[userID]; ok {
+		checkpoint.AckedSeq = buffer.ackedSeq
+		checkpoint.LatestSeq = buffer.lastSeq
+		checkpoint.ReplayComplete = buffer.droppedSeq <= buffer.ackedSeq
+		if buffer.epoch != "" {
+			checkpoint.Epoch = buffer.epoch
+		}
+	}
+	return checkpoint
+}
+
+// replayToConnection sends the pending events of a user to their new connection
+func (h *Hub) replayToConnection(client *Client) {
+	buffer, ok := h.replay[client.UserID]
+	if !ok {
+		buffer = &replayBuffer{}
+		h.replay[client.UserID] = buffer
+	}
+	if buffer.expired() {
+		buffer.dropEvents()
+	}
+
+	sync := client.Capabilities.Supports(CapabilitySync)
+	if sync {
+		buffer.retainDelivered = true
+	}
+
+	messages := buffer.pending(sync)
+	if len(messages) > 0 {
+		log.Printf("Hub: Replaying %d buffered events to user %d", len(messages), client.UserID)
+		client.record(JournalReplay, nil, strconv.Itoa(len(messages))+" events")
+		for _, message := range messages {
+			client.Send(message)
+		}
+	}
+
+	// Without sync connections, events are only kept until they reached a connection
+	if !buffer.retainDelivered {
+		buffer.dropEvents()
+	}
+	buffer.disconnectedAt = time.Time{}
+}
+
+// startReplayTTL starts the TTL of the buffer of a user who just lost their last connection
+func (h *Hub) startReplayTTL(userID int32) {
+	if buffer, ok := h.replay[userID]; ok {
+		buffer.disconnectedAt = time.Now()
+	}
+}
+
+// sweepReplayBuffers frees the events of users who did not come back within the TTL.
+// The sequence state is kept so numbering continues when they return.
+func (h *Hub) sweepReplayBuffers() {
+	for _, buffer := range h.replay {
+		if buffer.expired() && len(buffer.events) > 0 {
+			buffer.dropEvents()
+		}
+	}
+}
@@ -0,0 +1,58 @@
+package hub
+
+import (
+	"testing"
+	"time"
+)
+
+// syncCapabilities are those of a client that stores events and acks them
+var syncCapabilities = Capabilities{Features: map[string]bool{CapabilitySync: true}}
+
+// A message written to a live sync connection is kept until acked, so a client that drops the
+// connection before acking gets it replayed on the next one
+func TestLiveMessageReplayedToSyncReconnect(t *testing.T) {
+	h := NewHub()
+	go h.Run()
+
+	first := NewStreamClient(1, nopConn{}, syncCapabilities)
+	go first.WritePump()
+	h.Register(first)
+
+	written := make(chan struct{}, 1)
+	message := []byte(`{"type":"incoming_message","content":"hello"}`)
+	render := func(Capabilities) []byte { return message }
+	if !h.SendOrQueueAndNotify(1, render, time.Now(), func() { written <- struct{}{} }) {
+		t.Fatal("message to a connected user was not accepted")
+	}
+	select {
+	case <-written:
+	case <-time.After(time.Second):
+		t.Fatal("message was not written to the live connection")
+	}
+
+	// The connection drops before the client acked the message
+	first.Disconnect()
+	h.Unregister(first)
+
+	second := NewStreamClient(1, nopConn{}, syncCapabilities)
+	h.Register(second)
+	want := `{"seq":1,"type":"incoming_message","content":"hello"}`
+	select {
+	case frame := <-second.send:
+		if string(frame.data) != want {
+			t.Fatalf("replayed %s, want %s", frame.data, want)
+		}
+	default:
+		t.Fatal("message was not replayed to the new connection")
+	}
+
+	// Acked events are not replayed anymore
+	h.Ack(1, 1)
+	third := NewStreamClient(1, nopConn{}, syncCapabilities)
+	h.Register(third)
+	select {
+	case frame := <-third.send:
+		t.Fatalf("acked message replayed: %s", frame.data)
+	default:
+	}
+}
@@ -0,0 +1,34 @@
+package main
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	db "websocket-simple-chat-app/db/sqlc"
+	"websocket-simple-chat-app/hub"
+)
+
+// hubJournalHandler returns the recent hub events of a user on this instance, to debug missed
+// deliveries without verbose logging. The journal is enabled with HUB_JOURNAL_SIZE.
+func hubJournalHandler(store *db.Queries, connectionHub *hub.Hub) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		user, ok := parseUserIDParam(c, store)
+		if !ok {
+			return
+		}
+
+		entries, enabled := connectionHub.Journal(user.ID)
+		if !enabled {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Hub journal is disabled, set HUB_JOURNAL_SIZE to enable it"})
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{
+			"user_id":     user.ID,
+			"username":    user.Username,
+			"connections": len(connectionHub.GetUserClients(user.ID)),
+			"entries":     entries,
+		})
+	}
+}
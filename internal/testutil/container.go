@@ -0,0 +1,87 @@
+// Package testutil provides the pieces of end-to-end tests: throwaway Postgres and Redis containers
+// with the schema migrated, the server binary running against them, and helpers to create users,
+// tokens and WebSocket test clients. A typical test:
+//
+//	database := testutil.NewPostgres(t)
+//	server := testutil.StartServer(t, database, nil)
+//	alice := testutil.CreateUser(t, database.Store, "alice")
+//	ws := testutil.DialWS(t, server.URL, testutil.AccessToken(t, testutil.NewTokenMaker(t), alice), nil)
+//	ws.WaitFor("capabilities", testutil.DefaultEventTimeout)
+//
+// Containers are started with the docker CLI and removed when the test ends. Tests are skipped when
+// docker is not available, unless TEST_DATABASE_URL / TEST_REDIS_URL point at existing servers.
+package testutil
+
+import (
+	"fmt"
+	"os/exec"
+	"strings"
+	"testing"
+	"time"
+)
+
+// containerStartTimeout bounds how long a container may take to accept connections
+const containerStartTimeout = 60 * time.Second
+
+// container is a running docker container
+type container struct {
+	id   string
+	addr string // host:port the exposed port is published on
+}
+
+// startContainer runs an image in the background, publishing port (e.g. "5432/tcp") on a random
+// host port. The container is removed when the test ends.
+func startContainer(t testing.TB, image string, port string, env map[string]string, args ...string) container {
+	t.Helper()
+	if _, err := exec.LookPath("docker"); err != nil {
+		t.Skip("testutil: docker is not available")
+	}
+
+	runArgs := []string{"run", "--detach", "--rm", "--publish", "127.0.0.1::" + port}
+	for name, value := range env {
+		runArgs = append(runArgs, "--env", name+"="+value)
+	}
+	runArgs = append(runArgs, image)
+	runArgs = append(runArgs, args...)
+
+	out, err := exec.Command("docker", runArgs...).Output()
+	if err != nil {
+		t.Fatalf("testutil: failed to start %s: %v", image, commandError(err))
+	}
+	id := strings.TrimSpace(string(out))
+	t.Cleanup(func() {
+		exec.Command("docker", "rm", "--force", id).Run()
+	})
+
+	out, err = exec.Command("docker", "port", id, port).Output()
+	if err != nil {
+		t.Fatalf("testutil: failed to read the published port of %s: %v", image, commandError(err))
+	}
+	// One line per address family, e.g. "127.0.0.1:49154"
+	addr := strings.TrimSpace(strings.SplitN(string(out), "\n", 2)[0])
+	return container{id: id, addr: addr}
+}
+
+// waitFor retries check until it succeeds or containerStartTimeout passes
+func waitFor(t testing.TB, what string, check func() error) {
+	t.Helper()
+	deadline := time.Now().Add(containerStartTimeout)
+	for {
+		err := check()
+		if err == nil {
+			return
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("testutil: %s not ready after %s: %v", what, containerStartTimeout, err)
+		}
+		time.Sleep(250 * time.Millisecond)
+	}
+}
+
+// commandError includes the stderr of a failed command in its error
+func commandError(err error) error {
+	if exitErr, ok := err.(*exec.ExitError); ok {
+		return fmt.Errorf("%w: %s", err, strings.TrimSpace(string(exitErr.Stderr)))
+	}
+	return err
+}
@@ -0,0 +1,61 @@
+package testutil
+
+import (
+	"context"
+	"fmt"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	db "websocket-simple-chat-app/db/sqlc"
+	"websocket-simple-chat-app/token"
+	"websocket-simple-chat-app/util/password"
+)
+
+// Fixture defaults
+const (
+	// TokenSymmetricKey signs the tokens of NewTokenMaker, as TOKEN_SYMMETRIC_KEY of the server under test
+	TokenSymmetricKey = "testutil-symmetric-key-32-bytes!"
+	// Password is the password of every user created by CreateUser
+	Password = "password123"
+)
+
+var userCounter atomic.Int64
+
+// NewTokenMaker returns a token maker using TokenSymmetricKey
+func NewTokenMaker(t testing.TB) token.Maker {
+	t.Helper()
+	maker, err := token.NewPasetoMaker([]byte(TokenSymmetricKey))
+	if err != nil {
+		t.Fatalf("testutil: cannot create token maker: %v", err)
+	}
+	return maker
+}
+
+// CreateUser creates a user with a unique name starting with prefix and the password Password
+func CreateUser(t testing.TB, store *db.Queries, prefix string) db.User {
+	t.Helper()
+	hash, err := password.Hash(Password)
+	if err != nil {
+		t.Fatalf("testutil: cannot hash password: %v", err)
+	}
+
+	user, err := store.CreateUser(context.Background(), db.CreateUserParams{
+		Username:     fmt.Sprintf("%s%d", prefix, userCounter.Add(1)),
+		PasswordHash: hash,
+	})
+	if err != nil {
+		t.Fatalf("testutil: cannot create user: %v", err)
+	}
+	return user
+}
+
+// AccessToken issues a token of the user valid for an hour
+func AccessToken(t testing.TB, maker token.Maker, user db.User) string {
+	t.Helper()
+	accessToken, _, err := maker.CreateToken(user.ID, user.Username, time.Hour)
+	if err != nil {
+		t.Fatalf("testutil: cannot create token for user %d: %v", user.ID, err)
+	}
+	return accessToken
+}
@@ -0,0 +1,78 @@
+package testutil
+
+import (
+	"database/sql"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	_ "github.com/lib/pq"
+
+	"websocket-simple-chat-app/db/migrations"
+	db "websocket-simple-chat-app/db/sqlc"
+)
+
+// postgresImage is the server version the schema is developed against
+const postgresImage = "postgres:16-alpine"
+
+// Database is a migrated, empty database for one test
+type Database struct {
+	Source string // Connection URL, as DB_SOURCE
+	Conn   *sql.DB
+	Store  *db.Queries
+}
+
+// NewPostgres starts a Postgres container and applies every migration. With TEST_DATABASE_URL set,
+// that database is migrated and used instead: it must be empty, as tests do not clean up after themselves.
+func NewPostgres(t testing.TB) *Database {
+	t.Helper()
+
+	source := os.Getenv("TEST_DATABASE_URL")
+	if source == "" {
+		c := startContainer(t, postgresImage, "5432/tcp", map[string]string{
+			"POSTGRES_USER":     "postgres",
+			"POSTGRES_PASSWORD": "postgres",
+			"POSTGRES_DB":       "chat_app_test",
+		})
+		source = fmt.Sprintf("postgres://postgres:postgres@%s/chat_app_test?sslmode=disable&timezone=UTC", c.addr)
+	}
+
+	conn, err := sql.Open("postgres", source)
+	if err != nil {
+		t.Fatalf("testutil: cannot open database: %v", err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	waitFor(t, "postgres", conn.Ping)
+
+	Migrate(t, source)
+	return &Database{Source: source, Conn: conn, Store: db.New(conn)}
+}
+
+// Migrate applies the migrations of db/migrations as the server does at startup, so the applied
+// version is recorded in schema_migrations and the server under test finds nothing left to apply
+func Migrate(t testing.TB, source string) {
+	t.Helper()
+	if _, err := migrations.Up(source); err != nil {
+		t.Fatalf("testutil: migrations failed: %v", err)
+	}
+}
+
+// ModuleRoot returns the directory of go.mod, found from the working directory of the test
+func ModuleRoot(t testing.TB) string {
+	t.Helper()
+	dir, err := os.Getwd()
+	if err != nil {
+		t.Fatalf("testutil: %v", err)
+	}
+	for {
+		if _, err := os.Stat(filepath.Join(dir, "go.mod")); err == nil {
+			return dir
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			t.Fatalf("testutil: go.mod not found above the working directory")
+		}
+		dir = parent
+	}
+}
@@ -0,0 +1,33 @@
+package testutil
+
+import (
+	"context"
+	"os"
+	"testing"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// redisImage is used for multi-instance tests
+const redisImage = "redis:7-alpine"
+
+// NewRedis starts a Redis container and returns its URL, as REDIS_URL. With TEST_REDIS_URL set,
+// that server is used instead.
+func NewRedis(t testing.TB) string {
+	t.Helper()
+
+	url := os.Getenv("TEST_REDIS_URL")
+	if url == "" {
+		c := startContainer(t, redisImage, "6379/tcp", nil)
+		url = "redis://" + c.addr + "/0"
+	}
+
+	options, err := redis.ParseURL(url)
+	if err != nil {
+		t.Fatalf("testutil: invalid redis URL %q: %v", url, err)
+	}
+	client := redis.NewClient(options)
+	defer client.Close()
+	waitFor(t, "redis", func() error { return client.Ping(context.Background()).Err() })
+	return url
+}
@@ -0,0 +1,81 @@
+package testutil
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"syscall"
+	"testing"
+)
+
+// Server is the chat server binary running against a test database
+type Server struct {
+	URL string // e.g. "http://127.0.0.1:49321"
+}
+
+// StartServer builds the server and runs it on a free port with the database, TokenSymmetricKey
+// and the extra environment variables (e.g. REDIS_URL, GUEST_ACCOUNTS_ENABLED). Its output goes to
+// the test log. The server is stopped when the test ends.
+func StartServer(t testing.TB, database *Database, env map[string]string) *Server {
+	t.Helper()
+
+	binary := filepath.Join(t.TempDir(), "chat-server")
+	build := exec.Command("go", "build", "-o", binary, ".")
+	build.Dir = ModuleRoot(t)
+	if out, err := build.CombinedOutput(); err != nil {
+		t.Fatalf("testutil: cannot build the server: %v\n%s", err, out)
+	}
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("testutil: cannot find a free port: %v", err)
+	}
+	addr := listener.Addr().String()
+	listener.Close()
+
+	cmd := exec.Command(binary)
+	cmd.Env = append(os.Environ(),
+		"LISTEN_ADDR="+addr,
+		"DB_SOURCE="+database.Source,
+		"TOKEN_SYMMETRIC_KEY="+TokenSymmetricKey,
+	)
+	for name, value := range env {
+		cmd.Env = append(cmd.Env, name+"="+value)
+	}
+	cmd.Stdout = testLogWriter{t}
+	cmd.Stderr = testLogWriter{t}
+	if err := cmd.Start(); err != nil {
+		t.Fatalf("testutil: cannot start the server: %v", err)
+	}
+	t.Cleanup(func() {
+		cmd.Process.Signal(syscall.SIGTERM) // Graceful shutdown, like in production
+		cmd.Wait()
+	})
+
+	server := &Server{URL: "http://" + addr}
+	waitFor(t, "server", func() error {
+		resp, err := http.Get(server.URL + "/config")
+		if err != nil {
+			return err
+		}
+		resp.Body.Close()
+		if resp.StatusCode != http.StatusOK {
+			return fmt.Errorf("GET /config returned %d", resp.StatusCode)
+		}
+		return nil
+	})
+	return server
+}
+
+// testLogWriter forwards process output to the test log
+type testLogWriter struct {
+	t testing.TB
+}
+
+func (w testLogWriter) Write(p []byte) (int, error) {
+	w.t.Logf("%s", p)
+	return len(p), nil
+}
@@ -0,0 +1,83 @@
+package testutil_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"websocket-simple-chat-app/internal/testutil"
+)
+
+// TestSmoke runs the server against a fresh database and goes through signup, login and a private
+// message over /ws. It is skipped when docker is not available and TEST_DATABASE_URL is not set.
+func TestSmoke(t *testing.T) {
+	if testing.Short() {
+		t.Skip("end-to-end test: builds and runs the server")
+	}
+	database := testutil.NewPostgres(t)
+	server := testutil.StartServer(t, database, nil)
+
+	alice := signupAndLogin(t, server, "smoke_alice")
+	bob := signupAndLogin(t, server, "smoke_bob")
+
+	aliceWS := testutil.DialWS(t, server.URL, alice.Token, nil)
+	aliceWS.WaitFor("capabilities", testutil.DefaultEventTimeout)
+	bobWS := testutil.DialWS(t, server.URL, bob.Token, nil)
+	bobWS.WaitFor("capabilities", testutil.DefaultEventTimeout)
+
+	aliceWS.Send(map[string]any{"type": "private_message", "recipient_id": bob.UserID, "content": "hello", "client_msg_id": "smoke-1"})
+	ack := aliceWS.WaitFor("ack", testutil.DefaultEventTimeout)
+	if ack["status"] != "delivered" || ack["client_msg_id"] != "smoke-1" {
+		t.Fatalf("unexpected ack %v", ack)
+	}
+	incoming := bobWS.WaitFor("incoming_message", testutil.DefaultEventTimeout)
+	if incoming["content"] != "hello" || incoming["sender_username"] != "smoke_alice" {
+		t.Fatalf("unexpected incoming_message %v", incoming)
+	}
+}
+
+// session is a user signed up and logged in over the REST API
+type session struct {
+	UserID int32
+	Token  string
+}
+
+func signupAndLogin(t *testing.T, server *testutil.Server, username string) session {
+	t.Helper()
+	credentials := map[string]string{"username": username, "password": testutil.Password}
+
+	var created struct {
+		UserID int32 `json:"user_id"`
+	}
+	postJSON(t, server.URL+"/users", credentials, &created)
+
+	var login struct {
+		Token string `json:"token"`
+	}
+	postJSON(t, server.URL+"/login", credentials, &login)
+	if created.UserID == 0 || login.Token == "" {
+		t.Fatalf("signup or login of %s returned no user or token", username)
+	}
+	return session{UserID: created.UserID, Token: login.Token}
+}
+
+// postJSON sends body and decodes the response into out, failing the test unless it is 200 OK
+func postJSON(t *testing.T, url string, body any, out any) {
+	t.Helper()
+	data, err := json.Marshal(body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp, err := http.Post(url, "application/json", bytes.NewReader(data))
+	if err != nil {
+		t.Fatalf("POST %s: %v", url, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("POST %s returned %d", url, resp.StatusCode)
+	}
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		t.Fatalf("POST %s: invalid response: %v", url, err)
+	}
+}
@@ -0,0 +1,99 @@
+package testutil
+
+import (
+	"encoding/json"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+// DefaultEventTimeout is how long ReadEvent and WaitFor wait by default
+const DefaultEventTimeout = 5 * time.Second
+
+// Event is a decoded server event
+type Event map[string]any
+
+// Type returns the event type
+func (e Event) Type() string {
+	eventType, _ := e["type"].(string)
+	return eventType
+}
+
+// WSClient is a WebSocket connection of a test user. It is not safe for concurrent use.
+type WSClient struct {
+	t    testing.TB
+	Conn *websocket.Conn
+}
+
+// DialWS connects to the /ws endpoint of serverURL (e.g. an httptest.Server URL) with the token and
+// extra query parameters (capabilities, since, ...). The connection is closed when the test ends.
+func DialWS(t testing.TB, serverURL string, accessToken string, query url.Values) *WSClient {
+	t.Helper()
+	if query == nil {
+		query = url.Values{}
+	}
+	query.Set("token", accessToken)
+	wsURL := strings.Replace(serverURL, "http", "ws", 1) + "/ws?" + query.Encode()
+
+	conn, _, err := websocket.DefaultDialer.Dial(wsURL, nil)
+	if err != nil {
+		t.Fatalf("testutil: cannot connect to %s: %v", serverURL, err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	return &WSClient{t: t, Conn: conn}
+}
+
+// Send writes a JSON message
+func (c *WSClient) Send(msg any) {
+	c.t.Helper()
+	if err := c.Conn.WriteJSON(msg); err != nil {
+		c.t.Fatalf("testutil: cannot send %v: %v", msg, err)
+	}
+}
+
+// ReadEvent returns the next event, failing the test if none arrives within the timeout
+func (c *WSClient) ReadEvent(timeout time.Duration) Event {
+	c.t.Helper()
+	c.Conn.SetReadDeadline(time.Now().Add(timeout))
+	_, data, err := c.Conn.ReadMessage()
+	if err != nil {
+		c.t.Fatalf("testutil: no event within %s: %v", timeout, err)
+	}
+	var event Event
+	if err := json.Unmarshal(data, &event); err != nil {
+		c.t.Fatalf("testutil: invalid event %s: %v", data, err)
+	}
+	return event
+}
+
+// WaitFor skips events until one of the given type arrives, failing the test after the timeout
+func (c *WSClient) WaitFor(eventType string, timeout time.Duration) Event {
+	c.t.Helper()
+	deadline := time.Now().Add(timeout)
+	for {
+		remaining := time.Until(deadline)
+		if remaining <= 0 {
+			c.t.Fatalf("testutil: no %s event within %s", eventType, timeout)
+		}
+		if event := c.ReadEvent(remaining); event.Type() == eventType {
+			return event
+		}
+	}
+}
+
+// ExpectClose reads until the server closes the connection and returns the close code
+func (c *WSClient) ExpectClose(timeout time.Duration) int {
+	c.t.Helper()
+	c.Conn.SetReadDeadline(time.Now().Add(timeout))
+	for {
+		if _, _, err := c.Conn.ReadMessage(); err != nil {
+			if closeErr, ok := err.(*websocket.CloseError); ok {
+				return closeErr.Code
+			}
+			c.t.Fatalf("testutil: connection ended without close frame: %v", err)
+		}
+	}
+}
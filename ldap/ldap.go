@@ -0,0 +1,279 @@
+// Package ldap checks passwords against an LDAP directory with a simple bind (RFC 4511). It
+// implements only the bind and unbind operations, which is all password checks need.
+package ldap
+
+import (
+	"bufio"
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// dialTimeout bounds connecting to the server when the context has no earlier deadline
+const dialTimeout = 5 * time.Second
+
+// maxResponseSize bounds the size of a response read from the server
+const maxResponseSize = 64 * 1024
+
+// Result codes of a bind response
+const (
+	resultSuccess            = 0
+	resultInvalidCredentials = 49
+)
+
+// ErrInvalidCredentials is returned for an unknown user or a wrong password
+var ErrInvalidCredentials = errors.New("ldap: invalid credentials")
+
+// Client binds to one LDAP server as users, to check their passwords
+type Client struct {
+	addr   string
+	tls    *tls.Config // nil for ldap:// URLs
+	userDN string      // With %s for the escaped username
+	dialer net.Dialer
+}
+
+// New creates a client for an ldap:// or ldaps:// URL. userDN is the DN users bind as, with %s
+// where the username goes, e.g. "uid=%s,ou=people,dc=example,dc=org".
+func New(rawURL string, userDN string) (*Client, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, fmt.Errorf("ldap: invalid URL: %w", err)
+	}
+	if strings.Count(userDN, "%s") != 1 {
+		return nil, errors.New("ldap: the user DN must contain %s exactly once")
+	}
+
+	client := &Client{userDN: userDN, dialer: net.Dialer{Timeout: dialTimeout}}
+	switch u.Scheme {
+	case "ldap":
+		client.addr = hostPort(u, "389")
+	case "ldaps":
+		client.addr = hostPort(u, "636")
+		client.tls = &tls.Config{ServerName: u.Hostname(), MinVersion: tls.VersionTLS12}
+	default:
+		return nil, fmt.Errorf("ldap: unsupported scheme %q, use ldap:// or ldaps://", u.Scheme)
+	}
+	return client, nil
+}
+
+func hostPort(u *url.URL, defaultPort string) string {
+	if u.Port() != "" {
+		return u.Host
+	}
+	return net.JoinHostPort(u.Hostname(), defaultPort)
+}
+
+// Authenticate binds as the user with the password. It returns ErrInvalidCredentials if the
+// directory refuses them; other errors mean the check could not be made.
+func (c *Client) Authenticate(ctx context.Context, username string, password string) error {
+	// A bind with an empty password is an unauthenticated bind, which servers accept for any DN
+	if username == "" || password == "" {
+		return ErrInvalidCredentials
+	}
+
+	conn, err := c.dial(ctx)
+	if err != nil {
+		return fmt.Errorf("ldap: connecting: %w", err)
+	}
+	defer conn.Close()
+	if deadline, ok := ctx.Deadline(); ok {
+		conn.SetDeadline(deadline)
+	} else {
+		conn.SetDeadline(time.Now().Add(dialTimeout))
+	}
+
+	dn := fmt.Sprintf(c.userDN, EscapeDN(username))
+	if _, err := conn.Write(bindRequest(1, dn, password)); err != nil {
+		return fmt.Errorf("ldap: sending bind: %w", err)
+	}
+	code, message, err := readBindResponse(bufio.NewReader(conn), 1)
+	if err != nil {
+		return fmt.Errorf("ldap: reading bind response: %w", err)
+	}
+	conn.Write(unbindRequest(2)) // Polite close; the result does not matter
+
+	switch code {
+	case resultSuccess:
+		return nil
+	case resultInvalidCredentials:
+		return ErrInvalidCredentials
+	default:
+		return fmt.Errorf("ldap: bind failed with result code %d: %s", code, message)
+	}
+}
+
+func (c *Client) dial(ctx context.Context) (net.Conn, error) {
+	if c.tls == nil {
+		return c.dialer.DialContext(ctx, "tcp", c.addr)
+	}
+	dialer := tls.Dialer{NetDialer: &c.dialer, Config: c.tls}
+	return dialer.DialContext(ctx, "tcp", c.addr)
+}
+
+// EscapeDN escapes a value for use in a distinguished name (RFC 4514)
+func EscapeDN(value string) string {
+	var b strings.Builder
+	for i := 0; i < len(value); i++ {
+		ch := value[i]
+		switch {
+		case ch == 0:
+			b.WriteString(`\00`)
+		case strings.IndexByte(`,+"\<>;=`, ch) >= 0,
+			(ch == ' ' || ch == '#') && i == 0,
+			ch == ' ' && i == len(value)-1:
+			b.WriteByte('\\')
+			b.WriteByte(ch)
+		default:
+			b.WriteByte(ch)
+		}
+	}
+	return b.String()
+}
+
+// --- BER encoding of the messages ---
+
+// BER tags of the LDAP messages used
+const (
+	tagInteger       = 0x02
+	tagOctetString   = 0x04
+	tagEnumerated    = 0x0a
+	tagSequence      = 0x30
+	tagBindRequest   = 0x60 // [APPLICATION 0], constructed
+	tagBindResponse  = 0x61 // [APPLICATION 1], constructed
+	tagUnbindRequest = 0x42 // [APPLICATION 2], primitive
+	tagSimpleAuth    = 0x80 // [0], primitive
+	ldapVersion      = 3
+)
+
+func encodeLength(n int) []byte {
+	switch {
+	case n < 0x80:
+		return []byte{byte(n)}
+	case n <= 0xff:
+		return []byte{0x81, byte(n)}
+	case n <= 0xffff:
+		return []byte{0x82, byte(n >> 8), byte(n)}
+	default:
+		return []byte{0x84, byte(n >> 24), byte(n >> 16), byte(n >> 8), byte(n)}
+	}
+}
+
+func tlv(tag byte, content ...[]byte) []byte {
+	size := 0
+	for _, part := range content {
+		size += len(part)
+	}
+	out := append([]byte{tag}, encodeLength(size)...)
+	for _, part := range content {
+		out = append(out, part...)
+	}
+	return out
+}
+
+func encodeInteger(tag byte, n int) []byte {
+	// Minimal two's complement encoding of a non-negative number
+	content := []byte{byte(n)}
+	for n >>= 8; n > 0; n >>= 8 {
+		content = append([]byte{byte(n)}, content...)
+	}
+	if content[0]&0x80 != 0 {
+		content = append([]byte{0}, content...)
+	}
+	return tlv(tag, content)
+}
+
+func bindRequest(messageID int, dn string, password string) []byte {
+	return tlv(tagSequence,
+		encodeInteger(tagInteger, messageID),
+		tlv(tagBindRequest,
+			encodeInteger(tagInteger, ldapVersion),
+			tlv(tagOctetString, []byte(dn)),
+			tlv(tagSimpleAuth, []byte(password)),
+		),
+	)
+}
+
+func unbindRequest(messageID int) []byte {
+	return tlv(tagSequence, encodeInteger(tagInteger, messageID), []byte{tagUnbindRequest, 0})
+}
+
+// readElement reads one element and returns its tag and content
+func readElement(r io.Reader) (byte, []byte, error) {
+	var header [2]byte
+	if _, err := io.ReadFull(r, header[:]); err != nil {
+		return 0, nil, err
+	}
+	size := int(header[1])
+	if size&0x80 != 0 {
+		octets := size & 0x7f
+		if octets == 0 || octets > 4 {
+			return 0, nil, errors.New("unsupported length encoding")
+		}
+		lengthBytes := make([]byte, octets)
+		if _, err := io.ReadFull(r, lengthBytes); err != nil {
+			return 0, nil, err
+		}
+		size = 0
+		for _, b := range lengthBytes {
+			size = size<<8 | int(b)
+		}
+	}
+	if size > maxResponseSize {
+		return 0, nil, errors.New("response too large")
+	}
+	content := make([]byte, size)
+	if _, err := io.ReadFull(r, content); err != nil {
+		return 0, nil, err
+	}
+	return header[0], content, nil
+}
+
+func decodeInteger(content []byte) int {
+	n := 0
+	for _, b := range content {
+		n = n<<8 | int(b)
+	}
+	return n
+}
+
+// readBindResponse reads the bind response to messageID and returns its result code and
+// diagnostic message
+func readBindResponse(r io.Reader, messageID int) (int, string, error) {
+	tag, message, err := readElement(r)
+	if err != nil {
+		return 0, "", err
+	}
+	if tag != tagSequence {
+		return 0, "", fmt.Errorf("unexpected tag 0x%02x", tag)
+	}
+
+	parts := strings.NewReader(string(message))
+	tag, id, err := readElement(parts)
+	if err != nil || tag != tagInteger || decodeInteger(id) != messageID {
+		return 0, "", errors.New("unexpected message ID")
+	}
+	tag, response, err := readElement(parts)
+	if err != nil || tag != tagBindResponse {
+		return 0, "", errors.New("not a bind response")
+	}
+
+	fields := strings.NewReader(string(response))
+	tag, code, err := readElement(fields)
+	if err != nil || tag != tagEnumerated {
+		return 0, "", errors.New("missing result code")
+	}
+	if _, _, err := readElement(fields); err != nil { // matchedDN
+		return 0, "", err
+	}
+	_, diagnostic, err := readElement(fields)
+	if err != nil {
+		return 0, "", err
+	}
+	return decodeInteger(code), string(diagnostic), nil
+}
@@ -0,0 +1,407 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	db "websocket-simple-chat-app/db/sqlc"
+	"websocket-simple-chat-app/token"
+)
+
+// A legal hold preserves the history of a user, or of one conversation, for workplace deployments
+// that must produce it later. Messages are only ever soft-deleted, so what users delete stays
+// stored; the hold exempts the held users from the one hard deletion, the sweep of expired guests.
+// Admins export the history of a hold as JSON lines protected by a hash chain (see
+// exportLegalHoldHandler).
+
+const (
+	legalHoldListLimit       = 500
+	legalHoldMaxReasonLength = 500
+	legalHoldExportPageSize  = 1000
+)
+
+// LegalHold is a hold as returned to admins
+type LegalHold struct {
+	ID         int64      `json:"id"`
+	UserID     int32      `json:"user_id"`
+	PartnerID  *int32     `json:"partner_id,omitempty"` // Only for a hold on a conversation
+	Reason     string     `json:"reason"`
+	CreatedBy  int32      `json:"created_by"`
+	CreatedAt  time.Time  `json:"created_at"`
+	Active     bool       `json:"active"`
+	ReleasedBy *int32     `json:"released_by,omitempty"`
+	ReleasedAt *time.Time `json:"released_at,omitempty"`
+}
+
+func newLegalHold(hold db.LegalHold) LegalHold {
+	response := LegalHold{
+		ID:        hold.ID,
+		UserID:    hold.UserID,
+		Reason:    hold.Reason,
+		CreatedBy: hold.CreatedBy,
+		CreatedAt: hold.CreatedAt,
+		Active:    !hold.ReleasedAt.Valid,
+	}
+	if hold.PartnerID.Valid {
+		response.PartnerID = &hold.PartnerID.Int32
+	}
+	if hold.ReleasedBy.Valid {
+		response.ReleasedBy = &hold.ReleasedBy.Int32
+	}
+	if hold.ReleasedAt.Valid {
+		response.ReleasedAt = &hold.ReleasedAt.Time
+	}
+	return response
+}
+
+// parseLegalHoldIDParam fetches the hold of the hold_id path parameter. It answers the request
+// itself when the ID is invalid or unknown.
+func parseLegalHoldIDParam(c *gin.Context, store *db.Queries) (db.LegalHold, bool) {
+	holdID, err := strconv.ParseInt(c.Param("hold_id"), 10, 64)
+	if err != nil || holdID <= 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid 'hold_id' format"})
+		return db.LegalHold{}, false
+	}
+
+	hold, err := store.GetLegalHold(context.Background(), holdID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Legal hold not found"})
+			return db.LegalHold{}, false
+		}
+		log.Printf("Error fetching legal hold %d: %v", holdID, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch legal hold"})
+		return db.LegalHold{}, false
+	}
+	return hold, true
+}
+
+// createLegalHoldHandler places a user, or the conversation of two users, on legal hold
+func createLegalHoldHandler(store *db.Queries) gin.HandlerFunc {
+	type createLegalHoldRequest struct {
+		UserID    int32  `json:"user_id" binding:"required,gt=0"`
+		PartnerID int32  `json:"partner_id" binding:"omitempty,gt=0"`
+		Reason    string `json:"reason" binding:"required"`
+	}
+
+	return func(c *gin.Context) {
+		payload := c.MustGet(authorizationPayloadKey).(*token.Payload)
+
+		var req createLegalHoldRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		if len([]rune(req.Reason)) > legalHoldMaxReasonLength {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "'reason' must be at most 500 characters"})
+			return
+		}
+		if req.PartnerID == req.UserID {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "'partner_id' must differ from 'user_id'"})
+			return
+		}
+		for _, userID := range []int32{req.UserID, req.PartnerID} {
+			if userID == 0 {
+				continue
+			}
+			if _, err := store.GetUserByID(context.Background(), userID); err != nil {
+				if err == sql.ErrNoRows {
+					c.JSON(http.StatusNotFound, gin.H{"error": fmt.Sprintf("User %d not found", userID)})
+					return
+				}
+				log.Printf("Error fetching user %d: %v", userID, err)
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch user"})
+				return
+			}
+		}
+
+		// A conversation is stored with the lower user ID first
+		params := db.CreateLegalHoldParams{UserID: req.UserID, Reason: req.Reason, CreatedBy: payload.UserID}
+		if req.PartnerID != 0 {
+			params.UserID = min(req.UserID, req.PartnerID)
+			params.PartnerID = sql.NullInt32{Int32: max(req.UserID, req.PartnerID), Valid: true}
+		}
+		hold, err := store.CreateLegalHold(context.Background(), params)
+		if err != nil {
+			if err == sql.ErrNoRows {
+				c.JSON(http.StatusConflict, gin.H{"error": "Already on legal hold"})
+				return
+			}
+			log.Printf("Error creating legal hold on user %d: %v", req.UserID, err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create legal hold"})
+			return
+		}
+
+		log.Printf("Compliance: Admin %d placed legal hold %d (user %d, partner %d)", payload.UserID, hold.ID, hold.UserID, hold.PartnerID.Int32)
+		c.JSON(http.StatusCreated, newLegalHold(hold))
+	}
+}
+
+// listLegalHoldsHandler lists the newest holds, released ones included
+func listLegalHoldsHandler(store *db.Queries) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		holds, err := store.ListLegalHolds(context.Background(), legalHoldListLimit)
+		if err != nil {
+			log.Printf("Error listing legal holds: %v", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to list legal holds"})
+			return
+		}
+
+		response := make([]LegalHold, len(holds))
+		for i, hold := range holds {
+			response[i] = newLegalHold(hold)
+		}
+		c.JSON(http.StatusOK, gin.H{"legal_holds": response})
+	}
+}
+
+// releaseLegalHoldHandler releases an active hold. The hold is kept as a record.
+func releaseLegalHoldHandler(store *db.Queries) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		payload := c.MustGet(authorizationPayloadKey).(*token.Payload)
+
+		hold, ok := parseLegalHoldIDParam(c, store)
+		if !ok {
+			return
+		}
+
+		released, err := store.ReleaseLegalHold(context.Background(), db.ReleaseLegalHoldParams{
+			ID:         hold.ID,
+			ReleasedBy: sql.NullInt32{Int32: payload.UserID, Valid: true},
+		})
+		if err != nil {
+			if err == sql.ErrNoRows {
+				c.JSON(http.StatusConflict, gin.H{"error": "Legal hold already released"})
+				return
+			}
+			log.Printf("Error releasing legal hold %d: %v", hold.ID, err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to release legal hold"})
+			return
+		}
+
+		log.Printf("Compliance: Admin %d released legal hold %d", payload.UserID, hold.ID)
+		c.JSON(http.StatusOK, newLegalHold(released))
+	}
+}
+
+// --- Export ---
+
+// LegalHoldMessage is a private message as exported
+type LegalHoldMessage struct {
+	ID               int64      `json:"id"`
+	SenderID         int32      `json:"sender_id"`
+	ReceiverID       int32      `json:"receiver_id"`
+	Content          string     `json:"content"`
+	ContentType      string     `json:"content_type"`
+	ReplyToMessageID *int64     `json:"reply_to_message_id,omitempty"`
+	CreatedAt        time.Time  `json:"created_at"`
+	DeliveredAt      *time.Time `json:"delivered_at,omitempty"`
+	ReadAt           *time.Time `json:"read_at,omitempty"`
+	DeletedAt        *time.Time `json:"deleted_at,omitempty"` // Deleted for everyone, still preserved
+}
+
+func newLegalHoldMessage(message db.Message) LegalHoldMessage {
+	exported := LegalHoldMessage{
+		ID:          message.ID,
+		SenderID:    message.SenderID,
+		ReceiverID:  message.ReceiverID,
+		Content:     message.Content,
+		ContentType: message.ContentType,
+		CreatedAt:   message.CreatedAt,
+	}
+	if message.ReplyToMessageID.Valid {
+		exported.ReplyToMessageID = &message.ReplyToMessageID.Int64
+	}
+	if message.DeliveredAt.Valid {
+		exported.DeliveredAt = &message.DeliveredAt.Time
+	}
+	if message.ReadAt.Valid {
+		exported.ReadAt = &message.ReadAt.Time
+	}
+	if message.DeletedAt.Valid {
+		exported.DeletedAt = &message.DeletedAt.Time
+	}
+	return exported
+}
+
+// legalHoldExportLine is a line of an export. Hash is the SHA-256 of the exact bytes of Record, and
+// Chain the SHA-256 of the previous line's chain followed by Hash (both as bytes, 32 zero bytes
+// before the first line): changing, dropping or reordering lines breaks every later chain value.
+type legalHoldExportLine struct {
+	Type   string          `json:"type"` // hold, user, message or room_message
+	Record json.RawMessage `json:"record"`
+	Hash   string          `json:"hash"`
+	Chain  string          `json:"chain"`
+}
+
+// legalHoldExportEnd is the last line of a complete export
+type legalHoldExportEnd struct {
+	Type    string `json:"type"` // "end"
+	Records int    `json:"records"`
+	Chain   string `json:"chain"` // Chain of the last record line
+}
+
+// legalHoldExporter writes the lines of an export and keeps the hash chain
+type legalHoldExporter struct {
+	w       *bufio.Writer
+	chain   [sha256.Size]byte
+	records int
+}
+
+func (e *legalHoldExporter) write(recordType string, record any) error {
+	data, err := json.Marshal(record)
+	if err != nil {
+		return err
+	}
+	hash := sha256.Sum256(data)
+	e.chain = sha256.Sum256(append(e.chain[:], hash[:]...))
+	e.records++
+
+	line, err := json.Marshal(legalHoldExportLine{
+		Type:   recordType,
+		Record: data,
+		Hash:   hex.EncodeToString(hash[:]),
+		Chain:  hex.EncodeToString(e.chain[:]),
+	})
+	if err != nil {
+		return err
+	}
+	_, err = e.w.Write(append(line, '\n'))
+	return err
+}
+
+func (e *legalHoldExporter) end() error {
+	line, err := json.Marshal(legalHoldExportEnd{Type: "end", Records: e.records, Chain: hex.EncodeToString(e.chain[:])})
+	if err != nil {
+		return err
+	}
+	if _, err := e.w.Write(append(line, '\n')); err != nil {
+		return err
+	}
+	return e.w.Flush()
+}
+
+// exportLegalHold writes the history of a hold from one snapshot: the hold, the held users, their
+// private messages (of the conversation only for a conversation hold) and, for a user hold, the
+// room messages they sent
+func exportLegalHold(queries *db.Queries, exporter *legalHoldExporter, hold db.LegalHold, exportedBy int32) error {
+	ctx := context.Background()
+	err := exporter.write("hold", struct {
+		LegalHold
+		ExportedBy int32     `json:"exported_by"`
+		ExportedAt time.Time `json:"exported_at"`
+	}{newLegalHold(hold), exportedBy, time.Now().UTC()})
+	if err != nil {
+		return err
+	}
+
+	userIDs := []int32{hold.UserID}
+	if hold.PartnerID.Valid {
+		userIDs = append(userIDs, hold.PartnerID.Int32)
+	}
+	for _, userID := range userIDs {
+		user, err := queries.GetUserByID(ctx, userID)
+		if err != nil {
+			return fmt.Errorf("fetching user %d: %w", userID, err)
+		}
+		if err := exporter.write("user", newModeratedUser(user)); err != nil {
+			return err
+		}
+	}
+
+	for afterID := int64(0); ; {
+		messages, err := queries.ListHeldMessages(ctx, db.ListHeldMessagesParams{
+			UserID:    hold.UserID,
+			PartnerID: hold.PartnerID.Int32,
+			AfterID:   afterID,
+			PageLimit: legalHoldExportPageSize,
+		})
+		if err != nil {
+			return fmt.Errorf("listing messages: %w", err)
+		}
+		for _, message := range messages {
+			if err := exporter.write("message", newLegalHoldMessage(message)); err != nil {
+				return err
+			}
+			afterID = message.ID
+		}
+		if len(messages) < legalHoldExportPageSize {
+			break
+		}
+	}
+
+	if hold.PartnerID.Valid {
+		return nil
+	}
+	for afterID := int64(0); ; {
+		messages, err := queries.ListHeldRoomMessages(ctx, db.ListHeldRoomMessagesParams{
+			UserID:    hold.UserID,
+			AfterID:   afterID,
+			PageLimit: legalHoldExportPageSize,
+		})
+		if err != nil {
+			return fmt.Errorf("listing room messages: %w", err)
+		}
+		for _, message := range messages {
+			if err := exporter.write("room_message", message); err != nil {
+				return err
+			}
+			afterID = message.ID
+		}
+		if len(messages) < legalHoldExportPageSize {
+			break
+		}
+	}
+	return nil
+}
+
+// exportLegalHoldHandler streams the complete history of a hold as JSON lines (see
+// legalHoldExportLine). The export ends with an "end" line; a response without one was cut off.
+// The final chain value is logged, so a copy can later be checked against the server log.
+func exportLegalHoldHandler(dbConn *sql.DB, store *db.Queries) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		payload := c.MustGet(authorizationPayloadKey).(*token.Payload)
+
+		hold, ok := parseLegalHoldIDParam(c, store)
+		if !ok {
+			return
+		}
+
+		// One snapshot, so messages deleted or read during the export appear in one state
+		tx, err := dbConn.BeginTx(context.Background(), &sql.TxOptions{Isolation: sql.LevelRepeatableRead, ReadOnly: true})
+		if err != nil {
+			log.Printf("Error starting export of legal hold %d: %v", hold.ID, err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to export legal hold"})
+			return
+		}
+		defer tx.Rollback()
+
+		c.Header("Content-Type", "application/x-ndjson")
+		c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="legal-hold-%d.jsonl"`, hold.ID))
+		c.Status(http.StatusOK)
+
+		exporter := &legalHoldExporter{w: bufio.NewWriter(c.Writer)}
+		if err := exportLegalHold(db.New(tx), exporter, hold, payload.UserID); err != nil {
+			// The status is sent already: the missing end line tells the client
+			log.Printf("Error exporting legal hold %d: %v", hold.ID, err)
+			exporter.w.Flush()
+			return
+		}
+		if err := exporter.end(); err != nil {
+			log.Printf("Error exporting legal hold %d: %v", hold.ID, err)
+			return
+		}
+		log.Printf("Compliance: Admin %d exported legal hold %d: %d records, chain %x", payload.UserID, hold.ID, exporter.records, exporter.chain)
+	}
+}
@@ -0,0 +1,112 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"log"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	db "websocket-simple-chat-app/db/sqlc"
+	"websocket-simple-chat-app/hub"
+	"websocket-simple-chat-app/token"
+)
+
+// Logging out revokes the access token before it expires: its payload ID is stored in
+// revoked_tokens, which the bearer and cookie authenticators, the /ws handshake and reauth check.
+// Rows are deleted by the session sweeper once the token would have expired anyway. Open WebSocket
+// connections of the user are closed with wsCloseKicked; connections of other devices reconnect
+// with their own tokens, while the revoked token is refused. Requests authenticated without a
+// token (API key, LDAP) have nothing to log out from.
+
+// errTokenRevoked is returned for tokens revoked by logging out
+var errTokenRevoked = errors.New("token has been revoked")
+
+// checkTokenRevoked returns errTokenRevoked if the token was revoked. Lookup errors are returned as
+// they are, so callers fail closed.
+func checkTokenRevoked(store *db.Queries, payload *token.Payload) error {
+	revoked, err := store.IsTokenRevoked(context.Background(), payload.ID)
+	if err != nil {
+		return err
+	}
+	if revoked {
+		return errTokenRevoked
+	}
+	return nil
+}
+
+// logoutHandler revokes the access token of the request and, if given, the refresh token of the
+// same login. The auth cookie, if any, is cleared.
+func logoutHandler(store *db.Queries, refreshMaker token.Maker, connectionHub *hub.Hub, sessions sessionConfig) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		type logoutRequest struct {
+			RefreshToken string `json:"refresh_token"` // Optional
+		}
+		payload := c.MustGet(authorizationPayloadKey).(*token.Payload)
+		if method := c.GetString(authorizationMethodKey); method != authMethodBearer && method != authMethodCookie {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Only sessions authenticated with a token can log out"})
+			return
+		}
+
+		var req logoutRequest
+		if c.Request.ContentLength != 0 {
+			if err := c.ShouldBindJSON(&req); err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+				return
+			}
+		}
+
+		var refreshPayload *token.Payload
+		if req.RefreshToken != "" {
+			var err error
+			refreshPayload, err = refreshMaker.VerifyToken(req.RefreshToken)
+			if err != nil && !errors.Is(err, token.ErrExpiredToken) {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid 'refresh_token'"})
+				return
+			}
+			if refreshPayload != nil && refreshPayload.UserID != payload.UserID {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "'refresh_token' belongs to another user"})
+				return
+			}
+		}
+
+		err := store.RevokeToken(context.Background(), db.RevokeTokenParams{
+			ID:        payload.ID,
+			UserID:    payload.UserID,
+			ExpiresAt: payload.ExpiredAt,
+		})
+		if err != nil {
+			log.Printf("Error revoking token %s of user %d: %v", payload.ID, payload.UserID, err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to log out"})
+			return
+		}
+		if refreshPayload != nil {
+			// An already used or revoked session needs no revoking
+			if _, err := store.RevokeSession(context.Background(), refreshPayload.ID); err != nil {
+				log.Printf("Error revoking session %s of user %d: %v", refreshPayload.ID, payload.UserID, err)
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to log out"})
+				return
+			}
+		}
+
+		if sessions.AuthCookieName != "" {
+			clearAuthCookie(c, sessions.AuthCookieName)
+		}
+		connectionHub.DisconnectUser(payload.UserID, wsCloseKicked, "logged out")
+		log.Printf("User %d logged out (token %s)", payload.UserID, payload.ID)
+		c.JSON(http.StatusOK, gin.H{"message": "Logged out"})
+	}
+}
+
+// sweepRevokedTokens deletes the revocations of tokens that have expired, on each session sweep
+func sweepRevokedTokens(store *db.Queries) {
+	deleted, err := store.DeleteExpiredRevokedTokens(context.Background())
+	if err != nil {
+		log.Printf("Error sweeping expired revoked tokens: %v", err)
+		return
+	}
+	if deleted > 0 {
+		log.Printf("Deleted %d expired revoked tokens", deleted)
+	}
+}
@@ -0,0 +1,206 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"log"
+	"net/http"
+	"slices"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	db "websocket-simple-chat-app/db/sqlc"
+	"websocket-simple-chat-app/hub"
+	"websocket-simple-chat-app/token"
+)
+
+// Bulk operations act on many messages of one conversation at once, for clients with multi-select.
+// A request selects messages either by ID list or by ID range (from_id to to_id, both included).
+// Each operation runs in one transaction and produces one batched event: messages_deleted for
+// deletes, read_receipt_update for reads.
+
+// maxBulkMessages bounds the messages a bulk operation may select
+const maxBulkMessages = 500
+
+var (
+	errBulkSelection  = errors.New("give either 'message_ids' or 'from_id' and 'to_id'")
+	errBulkTooMany    = errors.New("at most 500 messages can be selected at once")
+	errBulkNotDeleted = errors.New("some messages are not yours, not in this conversation or already deleted")
+)
+
+// MessagesDeletedMessage is sent to both parties of a conversation after several messages were
+// deleted at once
+//
+//wsschema:server
+type MessagesDeletedMessage struct {
+	Type       string    `json:"type"` // "messages_deleted"
+	SenderID   int32     `json:"sender_id"`
+	ReceiverID int32     `json:"receiver_id"`
+	MessageIDs []int64   `json:"message_ids"` // Sorted
+	CreatedAt  time.Time `json:"created_at"`  // When the messages were deleted
+}
+
+// bulkMessagesRequest selects the messages of a bulk operation
+type bulkMessagesRequest struct {
+	MessageIDs []int64 `json:"message_ids"`
+	FromID     int64   `json:"from_id"`
+	ToID       int64   `json:"to_id"`
+}
+
+// validate checks that the request selects messages in exactly one way
+func (r bulkMessagesRequest) validate() error {
+	byIDs := len(r.MessageIDs) > 0
+	byRange := r.FromID != 0 || r.ToID != 0
+	if byIDs == byRange {
+		return errBulkSelection
+	}
+	if byIDs {
+		if len(r.MessageIDs) > maxBulkMessages {
+			return errBulkTooMany
+		}
+		if slices.ContainsFunc(r.MessageIDs, func(id int64) bool { return id <= 0 }) {
+			return errors.New("message IDs must be positive")
+		}
+		return nil
+	}
+	if r.FromID <= 0 || r.ToID < r.FromID {
+		return errors.New("'from_id' and 'to_id' must be positive, with 'from_id' <= 'to_id'")
+	}
+	return nil
+}
+
+// bindBulkMessagesRequest parses the conversation partner and the selection of a bulk request.
+// It answers the request itself when they are invalid.
+func bindBulkMessagesRequest(c *gin.Context, store *db.Queries) (int32, bulkMessagesRequest, bool) {
+	partnerID, ok := parsePartnerIDParam(c, store)
+	if !ok {
+		return 0, bulkMessagesRequest{}, false
+	}
+	var req bulkMessagesRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return 0, bulkMessagesRequest{}, false
+	}
+	if err := req.validate(); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return 0, bulkMessagesRequest{}, false
+	}
+	req.MessageIDs = slices.Compact(slices.Sorted(slices.Values(req.MessageIDs)))
+	return partnerID, req, true
+}
+
+// deleteConversationMessages deletes the selected messages userID sent to partnerID for everyone,
+// all or none. An ID list must only contain deletable messages, and a range at most
+// maxBulkMessages of them.
+func deleteConversationMessages(dbConn *sql.DB, userID int32, partnerID int32, req bulkMessagesRequest) ([]int64, error) {
+	tx, err := dbConn.BeginTx(context.Background(), nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	deletedIDs, err := db.New(tx).DeleteConversationMessages(context.Background(), db.DeleteConversationMessagesParams{
+		SenderID:   userID,
+		ReceiverID: partnerID,
+		MessageIds: req.MessageIDs,
+		FromID:     req.FromID,
+		ToID:       req.ToID,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if len(req.MessageIDs) > 0 && len(deletedIDs) != len(req.MessageIDs) {
+		return nil, errBulkNotDeleted
+	}
+	if len(deletedIDs) > maxBulkMessages {
+		return nil, errBulkTooMany
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	slices.Sort(deletedIDs)
+	return deletedIDs, nil
+}
+
+// bulkDeleteMessagesHandler deletes several of the authenticated user's messages in a conversation
+// for everyone
+func bulkDeleteMessagesHandler(dbConn *sql.DB, store *db.Queries, connectionHub *hub.Hub) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		payload := c.MustGet(authorizationPayloadKey).(*token.Payload)
+
+		partnerID, req, ok := bindBulkMessagesRequest(c, store)
+		if !ok {
+			return
+		}
+
+		deletedIDs, err := deleteConversationMessages(dbConn, payload.UserID, partnerID, req)
+		switch {
+		case errors.Is(err, errBulkNotDeleted):
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+			return
+		case errors.Is(err, errBulkTooMany):
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		case err != nil:
+			log.Printf("Error bulk deleting messages of user %d to %d: %v", payload.UserID, partnerID, err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete messages"})
+			return
+		}
+
+		if len(deletedIDs) > 0 {
+			event := MessagesDeletedMessage{
+				Type:       "messages_deleted",
+				SenderID:   payload.UserID,
+				ReceiverID: partnerID,
+				MessageIDs: deletedIDs,
+				CreatedAt:  time.Now().UTC(),
+			}
+			sendJSONToUser(connectionHub, payload.UserID, event) // The sender's other devices
+			sendJSONToUser(connectionHub, partnerID, event)
+			log.Printf("User %d deleted %d messages to %d", payload.UserID, len(deletedIDs), partnerID)
+		}
+		c.JSON(http.StatusOK, gin.H{"message_ids": deletedIDs})
+	}
+}
+
+// bulkMarkReadHandler marks several messages the authenticated user received in a conversation as
+// read. Messages already read are skipped.
+func bulkMarkReadHandler(store *db.Queries, connectionHub *hub.Hub) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		payload := c.MustGet(authorizationPayloadKey).(*token.Payload)
+
+		partnerID, req, ok := bindBulkMessagesRequest(c, store)
+		if !ok {
+			return
+		}
+
+		// A single statement, so the messages are marked all or none
+		readIDs, err := store.MarkConversationMessagesRead(context.Background(), db.MarkConversationMessagesReadParams{
+			SenderID:   partnerID,
+			ReaderID:   payload.UserID,
+			MessageIds: req.MessageIDs,
+			FromID:     req.FromID,
+			ToID:       req.ToID,
+		})
+		if err != nil {
+			log.Printf("Error bulk marking messages from %d to %d as read: %v", partnerID, payload.UserID, err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to mark messages as read"})
+			return
+		}
+		slices.Sort(readIDs)
+
+		// As for message_read, readers who turned read receipts off send no receipt
+		if len(readIDs) > 0 && loadUserSettings(store, payload.UserID).ReadReceipts {
+			sendJSONToUser(connectionHub, partnerID, ReadReceiptUpdateMessage{
+				Type:       "read_receipt_update",
+				ReaderID:   payload.UserID,
+				SenderID:   partnerID,
+				MessageIDs: readIDs,
+				CreatedAt:  time.Now().UTC(),
+			})
+		}
+		c.JSON(http.StatusOK, gin.H{"message_ids": readIDs})
+	}
+}
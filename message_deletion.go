@@ -0,0 +1,134 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	db "websocket-simple-chat-app/db/sqlc"
+	"websocket-simple-chat-app/hub"
+	"websocket-simple-chat-app/token"
+)
+
+// Deleting a message is "delete for everyone": only its sender (or an admin moderating it) can do it,
+// and it disappears for both parties. The row is kept with deleted_at set and never returned by the
+// API again.
+
+var (
+	errMessageNotFound  = errors.New("message not found")
+	errNotMessageSender = errors.New("only the sender can delete a message")
+)
+
+// DeleteMessageRequest is the delete_message WebSocket message
+//
+//wsschema:client
+type DeleteMessageRequest struct {
+	Type      string `json:"type"` // "delete_message"
+	MessageID int64  `json:"message_id"`
+}
+
+// MessageDeletedMessage is sent to both parties of a conversation after a message was deleted
+//
+//wsschema:server
+type MessageDeletedMessage struct {
+	Type       string    `json:"type"` // "message_deleted"
+	MessageID  int64     `json:"message_id"`
+	SenderID   int32     `json:"sender_id"`
+	ReceiverID int32     `json:"receiver_id"`
+	Moderated  bool      `json:"moderated,omitempty"` // Deleted by an admin, not by the sender
+	CreatedAt  time.Time `json:"created_at"`          // When the message was deleted
+}
+
+// deleteMessage deletes a message of userID for everyone and tells both parties' sessions.
+// Messages that do not exist or are already deleted return errMessageNotFound.
+func deleteMessage(store *db.Queries, connectionHub *hub.Hub, userID int32, messageID int64) (db.Message, error) {
+	message, err := store.GetMessage(context.Background(), messageID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return db.Message{}, errMessageNotFound
+		}
+		return db.Message{}, err
+	}
+	if message.DeletedAt.Valid {
+		return db.Message{}, errMessageNotFound
+	}
+	if message.SenderID != userID {
+		return db.Message{}, errNotMessageSender
+	}
+	return deleteMessageForEveryone(store, connectionHub, messageID, false)
+}
+
+// deleteMessageForEveryone deletes a message without checking who asks and tells both parties'
+// sessions. Messages that do not exist or are already deleted return errMessageNotFound.
+func deleteMessageForEveryone(store *db.Queries, connectionHub *hub.Hub, messageID int64, moderated bool) (db.Message, error) {
+	deleted, err := store.DeleteMessage(context.Background(), messageID)
+	if err != nil {
+		if err == sql.ErrNoRows { // Unknown, or deleted concurrently
+			return db.Message{}, errMessageNotFound
+		}
+		return db.Message{}, err
+	}
+
+	event := MessageDeletedMessage{
+		Type:       "message_deleted",
+		MessageID:  deleted.ID,
+		SenderID:   deleted.SenderID,
+		ReceiverID: deleted.ReceiverID,
+		Moderated:  moderated,
+		CreatedAt:  deleted.DeletedAt.Time,
+	}
+	sendJSONToUser(connectionHub, deleted.SenderID, event) // The sender's other devices
+	sendJSONToUser(connectionHub, deleted.ReceiverID, event)
+	return deleted, nil
+}
+
+// deleteMessageHandler deletes one of the authenticated user's messages for everyone
+func deleteMessageHandler(store *db.Queries, connectionHub *hub.Hub) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		payload := c.MustGet(authorizationPayloadKey).(*token.Payload)
+
+		messageID, err := strconv.ParseInt(c.Param("message_id"), 10, 64)
+		if err != nil || messageID <= 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid 'message_id' format"})
+			return
+		}
+
+		deleted, err := deleteMessage(store, connectionHub, payload.UserID, messageID)
+		switch {
+		case errors.Is(err, errMessageNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": "Message not found"})
+			return
+		case errors.Is(err, errNotMessageSender):
+			c.JSON(http.StatusForbidden, gin.H{"error": "Only the sender can delete a message"})
+			return
+		case err != nil:
+			log.Printf("Error deleting message %d for user %d: %v", messageID, payload.UserID, err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete message"})
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{"message_id": deleted.ID, "deleted_at": deleted.DeletedAt.Time})
+	}
+}
+
+// handleDeleteMessage processes a delete_message WebSocket message. Failures are logged and dropped.
+func handleDeleteMessage(store *db.Queries, connectionHub *hub.Hub, userID int32, payload []byte) {
+	var msg DeleteMessageRequest
+	if err := json.Unmarshal(payload, &msg); err != nil || msg.MessageID <= 0 {
+		log.Printf("WS Warning: Invalid delete_message from user %d. Payload: %s", userID, string(payload))
+		return
+	}
+
+	if _, err := deleteMessage(store, connectionHub, userID, msg.MessageID); err != nil {
+		log.Printf("WS Warning: User %d could not delete message %d: %v", userID, msg.MessageID, err)
+		return
+	}
+	log.Printf("User %d deleted message %d", userID, msg.MessageID)
+}
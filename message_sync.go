@@ -0,0 +1,106 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	db "websocket-simple-chat-app/db/sqlc"
+	"websocket-simple-chat-app/hub"
+)
+
+// Message sync limits. A reconnecting client gets at most messageSyncMaxPages frames; if more
+// messages were missed, the last frame is marked incomplete and the client pages through the rest
+// with GET /messages.
+const (
+	messageSyncPageSize = 100
+	messageSyncMaxPages = 10
+)
+
+var errInvalidSince = errors.New("invalid since")
+
+// MessageSyncMessage carries private messages a reconnecting client missed, oldest first
+//
+//wsschema:server
+type MessageSyncMessage struct {
+	Type      string            `json:"type"` // "message_sync"
+	Messages  []messageResponse `json:"messages"`
+	LastID    int64             `json:"last_id"`  // ID of the newest message so far, to resume from
+	Complete  bool              `json:"complete"` // False while more frames follow, and when the sync was truncated
+	CreatedAt time.Time         `json:"created_at"`
+}
+
+// parseSince reads the since query parameter of a /ws request: the ID of the newest message the
+// client has. ok is false if the parameter is not set.
+func parseSince(c *gin.Context) (since int64, ok bool, err error) {
+	value, ok := c.GetQuery("since")
+	return parseSinceValue(value, ok)
+}
+
+// parseSinceValue parses a since value, if set
+func parseSinceValue(value string, set bool) (since int64, ok bool, err error) {
+	if !set {
+		return 0, false, nil
+	}
+	since, err = strconv.ParseInt(value, 10, 64)
+	if err != nil || since < 0 {
+		return 0, false, errInvalidSince
+	}
+	return since, true, nil
+}
+
+// sendMissedMessages sends the messages of all the user's conversations created after since to a
+// new connection, as message_sync frames. It runs after registration, so messages stored meanwhile
+// may arrive both live and in a frame: clients dedupe by message ID.
+func sendMissedMessages(store *db.Queries, client *hub.Client, since int64) {
+	afterID := since
+	for page := 1; page <= messageSyncMaxPages; page++ {
+		messages, err := store.ListMessagesSince(context.Background(), db.ListMessagesSinceParams{
+			UserID:    client.UserID,
+			AfterID:   afterID,
+			PageLimit: messageSyncPageSize + 1, // One extra to know whether more follow
+		})
+		if err != nil {
+			log.Printf("WS Error: Failed to list messages since %d for user %d: %v", afterID, client.UserID, err)
+			return
+		}
+
+		more := len(messages) > messageSyncPageSize
+		if more {
+			messages = messages[:messageSyncPageSize]
+		}
+		if len(messages) > 0 {
+			afterID = messages[len(messages)-1].ID
+		}
+
+		response, err := withQuotedParents(store, messages)
+		if err != nil {
+			log.Printf("WS Error: Failed to fetch quoted messages for user %d: %v", client.UserID, err)
+			return
+		}
+		if err := hideUnsharedReadReceipts(store, client.UserID, response); err != nil {
+			log.Printf("WS Error: Failed to check read receipts for user %d: %v", client.UserID, err)
+			return
+		}
+		jsonMsg, err := json.Marshal(MessageSyncMessage{
+			Type:      "message_sync",
+			Messages:  response,
+			LastID:    afterID,
+			Complete:  !more,
+			CreatedAt: time.Now().UTC(),
+		})
+		if err != nil {
+			log.Printf("WS Error: Failed to marshal message_sync for user %d: %v", client.UserID, err)
+			return
+		}
+		if !client.Send(jsonMsg) || !more {
+			return
+		}
+	}
+	log.Printf("WS Warning: User %d missed more than %d messages since %d, sync truncated", client.UserID, messageSyncPageSize*messageSyncMaxPages, since)
+}
@@ -0,0 +1,44 @@
+package metrics
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+
+	"websocket-simple-chat-app/bruteforce"
+)
+
+var (
+	guardFailures = prometheus.NewDesc("chat_bruteforce_failures_total",
+		"Failed authentications counted by a brute-force guard.", nil, nil)
+	guardBlocks = prometheus.NewDesc("chat_bruteforce_blocks_total",
+		"IPs blocked by a brute-force guard after too many failed authentications.", nil, nil)
+	guardRejected = prometheus.NewDesc("chat_bruteforce_rejected_total",
+		"Authentication attempts refused because their IP was blocked.", nil, nil)
+	guardBlockedIPs = prometheus.NewDesc("chat_bruteforce_blocked_ips",
+		"IPs currently blocked by a brute-force guard.", nil, nil)
+)
+
+// guardCollector reads the counters of a guard when scraped
+type guardCollector struct {
+	guard *bruteforce.Guard
+}
+
+func (c guardCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- guardFailures
+	ch <- guardBlocks
+	ch <- guardRejected
+	ch <- guardBlockedIPs
+}
+
+func (c guardCollector) Collect(ch chan<- prometheus.Metric) {
+	stats := c.guard.Stats()
+	ch <- prometheus.MustNewConstMetric(guardFailures, prometheus.CounterValue, float64(stats.TotalFailures))
+	ch <- prometheus.MustNewConstMetric(guardBlocks, prometheus.CounterValue, float64(stats.TotalBlocks))
+	ch <- prometheus.MustNewConstMetric(guardRejected, prometheus.CounterValue, float64(stats.Rejected))
+	ch <- prometheus.MustNewConstMetric(guardBlockedIPs, prometheus.GaugeValue, float64(stats.CurrentBlocks))
+}
+
+// RegisterBruteForceGuard exports the counters of a guard, labeled with its name (e.g. guard="ws_auth")
+func RegisterBruteForceGuard(name string, guard *bruteforce.Guard) {
+	prometheus.WrapRegistererWith(prometheus.Labels{"guard": name}, prometheus.DefaultRegisterer).
+		MustRegister(guardCollector{guard: guard})
+}
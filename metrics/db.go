@@ -0,0 +1,213 @@
+package metrics
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"io"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+// Queries are measured at the driver level, below the generated store, so every query of the store
+// (inside transactions too) is recorded under its sqlc name without wrapping each method. Statements
+// without a "-- name:" comment are recorded as "other".
+
+const otherQuery = "other"
+
+var (
+	queryDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "chat_db_query_duration_seconds",
+		Help:    "Time from sending a database query until its rows were read, by sqlc query name.",
+		Buckets: []float64{.0005, .001, .0025, .005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5},
+	}, []string{"query"})
+
+	queryErrors = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "chat_db_query_errors_total",
+		Help: "Database queries that failed, by sqlc query name.",
+	}, []string{"query"})
+
+	queryRows = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "chat_db_query_rows_total",
+		Help: "Rows returned by database queries, by sqlc query name.",
+	}, []string{"query"})
+)
+
+// OpenDB opens a database like sql.Open, recording the duration, errors and rows of its queries and
+// exporting its connection pool statistics (go_sql_* metrics with db_name="chat").
+func OpenDB(driverName string, dataSourceName string) (*sql.DB, error) {
+	probe, err := sql.Open(driverName, dataSourceName) // Only to look up the driver, it does not connect
+	if err != nil {
+		return nil, err
+	}
+	base := probe.Driver()
+	probe.Close()
+
+	var connector driver.Connector = dsnConnector{dsn: dataSourceName, driver: base}
+	if withConnector, ok := base.(driver.DriverContext); ok {
+		if connector, err = withConnector.OpenConnector(dataSourceName); err != nil {
+			return nil, err
+		}
+	}
+
+	db := sql.OpenDB(instrumentedConnector{base: connector})
+	prometheus.MustRegister(collectors.NewDBStatsCollector(db, "chat"))
+	return db, nil
+}
+
+// queryName returns the sqlc name of a query ("-- name: GetUserByID :one ...")
+func queryName(query string) string {
+	rest, ok := strings.CutPrefix(query, "-- name: ")
+	if !ok {
+		return otherQuery
+	}
+	name, _, ok := strings.Cut(rest, " ")
+	if !ok || name == "" {
+		return otherQuery
+	}
+	return name
+}
+
+func observeQuery(name string, start time.Time, rows int, err error) {
+	queryDuration.WithLabelValues(name).Observe(time.Since(start).Seconds())
+	if err != nil {
+		queryErrors.WithLabelValues(name).Inc()
+	}
+	if rows > 0 {
+		queryRows.WithLabelValues(name).Add(float64(rows))
+	}
+}
+
+// dsnConnector connects through drivers that do not provide a connector themselves
+type dsnConnector struct {
+	dsn    string
+	driver driver.Driver
+}
+
+func (c dsnConnector) Connect(context.Context) (driver.Conn, error) {
+	return c.driver.Open(c.dsn)
+}
+
+func (c dsnConnector) Driver() driver.Driver {
+	return c.driver
+}
+
+type instrumentedConnector struct {
+	base driver.Connector
+}
+
+func (c instrumentedConnector) Connect(ctx context.Context) (driver.Conn, error) {
+	conn, err := c.base.Connect(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return instrumentedConn{Conn: conn}, nil
+}
+
+func (c instrumentedConnector) Driver() driver.Driver {
+	return c.base.Driver()
+}
+
+// instrumentedConn measures the queries of a connection and passes everything else through
+type instrumentedConn struct {
+	driver.Conn
+}
+
+func (c instrumentedConn) QueryContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	queryer, ok := c.Conn.(driver.QueryerContext)
+	if !ok {
+		return nil, driver.ErrSkip
+	}
+	name, start := queryName(query), time.Now()
+	rows, err := queryer.QueryContext(ctx, query, args)
+	if err != nil {
+		observeQuery(name, start, 0, err)
+		return nil, err
+	}
+	return &instrumentedRows{Rows: rows, name: name, start: start}, nil
+}
+
+func (c instrumentedConn) ExecContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	execer, ok := c.Conn.(driver.ExecerContext)
+	if !ok {
+		return nil, driver.ErrSkip
+	}
+	name, start := queryName(query), time.Now()
+	result, err := execer.ExecContext(ctx, query, args)
+	observeQuery(name, start, 0, err)
+	return result, err
+}
+
+func (c instrumentedConn) PrepareContext(ctx context.Context, query string) (driver.Stmt, error) {
+	if preparer, ok := c.Conn.(driver.ConnPrepareContext); ok {
+		return preparer.PrepareContext(ctx, query)
+	}
+	return c.Conn.Prepare(query)
+}
+
+func (c instrumentedConn) BeginTx(ctx context.Context, opts driver.TxOptions) (driver.Tx, error) {
+	if beginner, ok := c.Conn.(driver.ConnBeginTx); ok {
+		return beginner.BeginTx(ctx, opts)
+	}
+	return c.Conn.Begin()
+}
+
+func (c instrumentedConn) Ping(ctx context.Context) error {
+	if pinger, ok := c.Conn.(driver.Pinger); ok {
+		return pinger.Ping(ctx)
+	}
+	return nil
+}
+
+func (c instrumentedConn) ResetSession(ctx context.Context) error {
+	if resetter, ok := c.Conn.(driver.SessionResetter); ok {
+		return resetter.ResetSession(ctx)
+	}
+	return nil
+}
+
+func (c instrumentedConn) IsValid() bool {
+	if validator, ok := c.Conn.(driver.Validator); ok {
+		return validator.IsValid()
+	}
+	return true
+}
+
+func (c instrumentedConn) CheckNamedValue(value *driver.NamedValue) error {
+	if checker, ok := c.Conn.(driver.NamedValueChecker); ok {
+		return checker.CheckNamedValue(value)
+	}
+	return driver.ErrSkip
+}
+
+// instrumentedRows counts the rows read and records the query when they are closed
+type instrumentedRows struct {
+	driver.Rows
+	name  string
+	start time.Time
+	count int
+	err   error
+	once  sync.Once
+}
+
+func (r *instrumentedRows) Next(dest []driver.Value) error {
+	err := r.Rows.Next(dest)
+	switch {
+	case err == nil:
+		r.count++
+	case err != io.EOF:
+		r.err = err
+	}
+	return err
+}
+
+func (r *instrumentedRows) Close() error {
+	err := r.Rows.Close()
+	r.once.Do(func() { observeQuery(r.name, r.start, r.count, r.err) })
+	return err
+}
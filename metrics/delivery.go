@@ -0,0 +1,57 @@
+package metrics
+
+import (
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+// Delivery routes
+const (
+	RouteLocal = "local" // Sender and recipient are connected to the same instance
+	RouteRelay = "relay" // Relayed through the broker to the instance the recipient is connected to
+)
+
+// Delivery latency SLO: DeliverySLOObjective of all deliveries should complete within DeliverySLOTarget
+const (
+	DeliverySLOTarget    = 250 * time.Millisecond
+	DeliverySLOObjective = 0.99
+)
+
+var (
+	deliveryLatency = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "chat_message_delivery_seconds",
+		Help:    "Time from receiving a message to writing it to a recipient connection.",
+		Buckets: []float64{.001, .0025, .005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5},
+	}, []string{"route"})
+
+	deliveries = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "chat_message_deliveries_total",
+		Help: "Message deliveries by route and whether they met the latency SLO target (slo=\"met\" or \"missed\").",
+	}, []string{"route", "slo"})
+
+	// The SLO parameters are exported so burn rates can be computed in PromQL:
+	//   rate(chat_message_deliveries_total{slo="missed"}[1h]) / rate(chat_message_deliveries_total[1h])
+	//     / (1 - chat_message_delivery_slo_objective)
+	_ = promauto.NewGaugeFunc(prometheus.GaugeOpts{
+		Name: "chat_message_delivery_slo_target_seconds",
+		Help: "Latency target of the message delivery SLO.",
+	}, func() float64 { return DeliverySLOTarget.Seconds() })
+
+	_ = promauto.NewGaugeFunc(prometheus.GaugeOpts{
+		Name: "chat_message_delivery_slo_objective",
+		Help: "Fraction of deliveries that should meet the latency target.",
+	}, func() float64 { return DeliverySLOObjective })
+)
+
+// ObserveDelivery records a successful delivery that took the given time on the given route
+func ObserveDelivery(route string, elapsed time.Duration) {
+	deliveryLatency.WithLabelValues(route).Observe(elapsed.Seconds())
+
+	slo := "met"
+	if elapsed > DeliverySLOTarget {
+		slo = "missed"
+	}
+	deliveries.WithLabelValues(route, slo).Inc()
+}
@@ -0,0 +1,16 @@
+package metrics
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+var droppedEvents = promauto.NewCounterVec(prometheus.CounterOpts{
+	Name: "chat_hub_events_dropped_total",
+	Help: "Events not sent to a connection because its send buffer was under pressure, by event class.",
+}, []string{"class"})
+
+// ObserveDroppedEvent records an event of the given class dropped for a slow connection
+func ObserveDroppedEvent(class string) {
+	droppedEvents.WithLabelValues(class).Inc()
+}
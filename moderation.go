@@ -0,0 +1,221 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	db "websocket-simple-chat-app/db/sqlc"
+	"websocket-simple-chat-app/hub"
+	"websocket-simple-chat-app/pagination"
+	"websocket-simple-chat-app/token"
+)
+
+// Admins moderate accounts by suspending them for a while, banning them (deactivation, see
+// deactivation.go), ending their sessions and deleting their messages. A suspended account keeps
+// everything but cannot log in, refresh tokens or connect until the suspension ends by itself or is
+// lifted.
+
+// Moderation limits
+const (
+	maxSuspension               = 365 * 24 * time.Hour
+	moderationUsersDefaultLimit = 50
+	moderationUsersMaxLimit     = 200
+)
+
+// accountSuspended reports whether the user is currently suspended
+func accountSuspended(user db.User) bool {
+	return user.SuspendedUntil.Valid && user.SuspendedUntil.Time.After(time.Now())
+}
+
+// suspensionResponse is returned to suspended users and by the suspension endpoints
+func suspensionResponse(user db.User) gin.H {
+	response := gin.H{"user_id": user.ID, "suspended": accountSuspended(user)}
+	if accountSuspended(user) {
+		response["suspended_until"] = user.SuspendedUntil.Time
+		response["reason"] = user.SuspensionReason
+	}
+	return response
+}
+
+// ModeratedUser is an account as listed for admins
+type ModeratedUser struct {
+	ID               int32      `json:"id"`
+	Username         string     `json:"username"`
+	Role             string     `json:"role"`
+	Status           string     `json:"status"`
+	CreatedAt        time.Time  `json:"created_at"`
+	LastSeenAt       *time.Time `json:"last_seen_at,omitempty"`
+	DeactivatedAt    *time.Time `json:"deactivated_at,omitempty"`
+	SuspendedUntil   *time.Time `json:"suspended_until,omitempty"` // Only while suspended
+	SuspensionReason string     `json:"suspension_reason,omitempty"`
+}
+
+func newModeratedUser(user db.User) ModeratedUser {
+	moderated := ModeratedUser{
+		ID:        user.ID,
+		Username:  user.Username,
+		Role:      user.Role,
+		Status:    user.Status,
+		CreatedAt: user.CreatedAt,
+	}
+	if user.LastSeenAt.Valid {
+		moderated.LastSeenAt = &user.LastSeenAt.Time
+	}
+	if user.DeactivatedAt.Valid {
+		moderated.DeactivatedAt = &user.DeactivatedAt.Time
+	}
+	if accountSuspended(user) {
+		moderated.SuspendedUntil = &user.SuspendedUntil.Time
+		moderated.SuspensionReason = user.SuspensionReason
+	}
+	return moderated
+}
+
+// listUsersForModerationHandler lists all accounts, optionally filtered by role and by a part of the username
+func listUsersForModerationHandler(store *db.Queries) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		page, afterID, ok := parseIDPage(c, moderationUsersDefaultLimit, moderationUsersMaxLimit)
+		if !ok {
+			return
+		}
+
+		users, err := store.ListUsersForModeration(context.Background(), db.ListUsersForModerationParams{
+			AfterID:      int32(afterID),
+			Role:         c.Query("role"),
+			UsernamePart: strings.TrimSpace(c.Query("q")),
+			PageLimit:    page.FetchLimit(),
+		})
+		if err != nil {
+			log.Printf("Error listing users for moderation: %v", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to list users"})
+			return
+		}
+
+		users, nextCursor := pagination.Trim(users, page, func(u db.User) string { return pagination.IDKey(int64(u.ID)) })
+		response := make([]ModeratedUser, len(users))
+		for i, user := range users {
+			response[i] = newModeratedUser(user)
+		}
+		c.JSON(http.StatusOK, gin.H{"users": response, "next_cursor": nextCursor})
+	}
+}
+
+// suspendUserHandler suspends another account for the given duration and closes its connections.
+// Suspending a suspended account replaces the end and reason of the suspension.
+func suspendUserHandler(store *db.Queries, connectionHub *hub.Hub) gin.HandlerFunc {
+	type suspendUserRequest struct {
+		Duration string `json:"duration" binding:"required"` // Go duration, e.g. "24h"
+		Reason   string `json:"reason" binding:"max=500"`
+	}
+	return func(c *gin.Context) {
+		payload := c.MustGet(authorizationPayloadKey).(*token.Payload)
+
+		target, ok := parseUserIDParam(c, store)
+		if !ok {
+			return
+		}
+		var req suspendUserRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		duration, err := time.ParseDuration(req.Duration)
+		if err != nil || duration <= 0 || duration > maxSuspension {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid 'duration', must be a positive duration of at most 8760h"})
+			return
+		}
+		if target.ID == payload.UserID {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Admins cannot suspend their own account"})
+			return
+		}
+
+		user, err := store.SuspendUser(context.Background(), db.SuspendUserParams{
+			SuspendedUntil:   sql.NullTime{Time: time.Now().Add(duration), Valid: true},
+			SuspensionReason: strings.TrimSpace(req.Reason),
+			ID:               target.ID,
+		})
+		if err != nil {
+			log.Printf("Error suspending user %d: %v", target.ID, err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to suspend user"})
+			return
+		}
+		connectionHub.DisconnectUser(user.ID, wsCloseBanned, "account suspended")
+
+		log.Printf("Admin %d suspended user %s (ID: %d) until %s", payload.UserID, user.Username, user.ID, user.SuspendedUntil.Time.Format(time.RFC3339))
+		c.JSON(http.StatusOK, suspensionResponse(user))
+	}
+}
+
+// unsuspendUserHandler lifts the suspension of an account before it ends
+func unsuspendUserHandler(store *db.Queries) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		payload := c.MustGet(authorizationPayloadKey).(*token.Payload)
+
+		target, ok := parseUserIDParam(c, store)
+		if !ok {
+			return
+		}
+
+		user, err := store.UnsuspendUser(context.Background(), target.ID)
+		if err != nil {
+			log.Printf("Error lifting the suspension of user %d: %v", target.ID, err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to unsuspend user"})
+			return
+		}
+
+		log.Printf("Admin %d lifted the suspension of user %s (ID: %d)", payload.UserID, user.Username, user.ID)
+		c.JSON(http.StatusOK, suspensionResponse(user))
+	}
+}
+
+// disconnectUserHandler ends all WebSocket sessions of a user. The user may reconnect; suspend or
+// deactivate the account to keep them out.
+func disconnectUserHandler(store *db.Queries, connectionHub *hub.Hub) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		payload := c.MustGet(authorizationPayloadKey).(*token.Payload)
+
+		target, ok := parseUserIDParam(c, store)
+		if !ok {
+			return
+		}
+
+		closed := connectionHub.DisconnectUser(target.ID, wsCloseKicked, "disconnected by an admin")
+		log.Printf("Admin %d disconnected user %s (ID: %d), %d connections on this instance", payload.UserID, target.Username, target.ID, closed)
+		c.JSON(http.StatusOK, gin.H{"user_id": target.ID, "connections_closed": closed})
+	}
+}
+
+// moderateMessageHandler deletes any private message for everyone, e.g. an abusive one
+func moderateMessageHandler(store *db.Queries, connectionHub *hub.Hub) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		payload := c.MustGet(authorizationPayloadKey).(*token.Payload)
+
+		messageID, err := strconv.ParseInt(c.Param("message_id"), 10, 64)
+		if err != nil || messageID <= 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid 'message_id' format"})
+			return
+		}
+
+		deleted, err := deleteMessageForEveryone(store, connectionHub, messageID, true)
+		if errors.Is(err, errMessageNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Message not found"})
+			return
+		}
+		if err != nil {
+			log.Printf("Error deleting message %d for admin %d: %v", messageID, payload.UserID, err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete message"})
+			return
+		}
+
+		log.Printf("Admin %d deleted message %d of user %d", payload.UserID, deleted.ID, deleted.SenderID)
+		c.JSON(http.StatusOK, gin.H{"message_id": deleted.ID, "sender_id": deleted.SenderID, "deleted_at": deleted.DeletedAt.Time})
+	}
+}
@@ -0,0 +1,215 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	db "websocket-simple-chat-app/db/sqlc"
+	"websocket-simple-chat-app/token"
+	"websocket-simple-chat-app/wordfilter"
+)
+
+// The moderation word list is kept in the moderation_words table and managed by admins at runtime.
+// Low severity entries are masked in messages, high severity entries get the message rejected. The
+// instance handling a change reloads its filter at once; the other instances pick the change up
+// within wordFilterReloadInterval.
+
+const wordFilterReloadInterval = 30 * time.Second
+
+// errBlockedWords is returned for messages matching a high severity entry
+var errBlockedWords = errors.New("message contains blocked words")
+
+// ModerationWordResponse is an entry of the moderation word list
+type ModerationWordResponse struct {
+	ID        int64     `json:"id"`
+	Pattern   string    `json:"pattern"`
+	Regex     bool      `json:"regex"`
+	Severity  string    `json:"severity"` // "low" (masked) or "high" (rejected)
+	CreatedBy *int32    `json:"created_by"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+func newModerationWordResponse(word db.ModerationWord) ModerationWordResponse {
+	response := ModerationWordResponse{
+		ID:        word.ID,
+		Pattern:   word.Pattern,
+		Regex:     word.IsRegex,
+		Severity:  word.Severity,
+		CreatedAt: word.CreatedAt,
+	}
+	if word.CreatedBy.Valid {
+		response.CreatedBy = &word.CreatedBy.Int32
+	}
+	return response
+}
+
+// filterMessageContent returns the content with low severity matches masked, or errBlockedWords
+func filterMessageContent(filter *wordfilter.Filter, content string) (string, error) {
+	result := filter.Check(content)
+	if result.Rejected {
+		return "", errBlockedWords
+	}
+	return result.Text, nil
+}
+
+// loadWordFilter replaces the filter's list with the stored one
+func loadWordFilter(store *db.Queries, filter *wordfilter.Filter) error {
+	words, err := store.ListModerationWords(context.Background())
+	if err != nil {
+		return err
+	}
+	entries := make([]wordfilter.Entry, len(words))
+	for i, word := range words {
+		entries[i] = wordfilter.Entry{Pattern: word.Pattern, Regex: word.IsRegex, Severity: word.Severity}
+	}
+	if err := filter.Load(entries); err != nil {
+		log.Printf("Warning: Skipped invalid moderation words: %v", err)
+	}
+	return nil
+}
+
+// runWordFilterReloader periodically reloads the filter, for changes made through other instances
+func runWordFilterReloader(store *db.Queries, filter *wordfilter.Filter) {
+	for range time.Tick(wordFilterReloadInterval) {
+		if err := loadWordFilter(store, filter); err != nil {
+			log.Printf("Error reloading the moderation word list: %v", err)
+		}
+	}
+}
+
+// reloadWordFilter reloads the filter after a change made by this instance
+func reloadWordFilter(store *db.Queries, filter *wordfilter.Filter) {
+	if err := loadWordFilter(store, filter); err != nil {
+		log.Printf("Error reloading the moderation word list: %v", err)
+	}
+}
+
+// listModerationWordsHandler lists the moderation word list
+func listModerationWordsHandler(store *db.Queries) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		words, err := store.ListModerationWords(context.Background())
+		if err != nil {
+			log.Printf("Error listing moderation words: %v", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to list moderation words"})
+			return
+		}
+
+		response := make([]ModerationWordResponse, len(words))
+		for i, word := range words {
+			response[i] = newModerationWordResponse(word)
+		}
+		c.JSON(http.StatusOK, gin.H{"words": response})
+	}
+}
+
+// createModerationWordHandler adds a word or regular expression to the list
+func createModerationWordHandler(store *db.Queries, filter *wordfilter.Filter) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		type createModerationWordRequest struct {
+			Pattern  string `json:"pattern" binding:"required,max=200"`
+			Regex    bool   `json:"regex"`
+			Severity string `json:"severity" binding:"required,oneof=low high"`
+		}
+		var req createModerationWordRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		entry := wordfilter.Entry{Pattern: req.Pattern, Regex: req.Regex, Severity: req.Severity}
+		if _, err := wordfilter.Compile(entry); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid pattern: " + err.Error()})
+			return
+		}
+
+		payload := c.MustGet(authorizationPayloadKey).(*token.Payload)
+		word, err := store.CreateModerationWord(context.Background(), db.CreateModerationWordParams{
+			Pattern:   req.Pattern,
+			IsRegex:   req.Regex,
+			Severity:  req.Severity,
+			CreatedBy: sql.NullInt32{Int32: payload.UserID, Valid: true},
+		})
+		if db.IsUniqueViolation(err) {
+			c.JSON(http.StatusConflict, gin.H{"error": "Pattern is already in the list"})
+			return
+		}
+		if err != nil {
+			log.Printf("Error adding moderation word for admin %d: %v", payload.UserID, err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to add moderation word"})
+			return
+		}
+		reloadWordFilter(store, filter)
+
+		log.Printf("Moderation word %d (%s) added by admin %d", word.ID, word.Severity, payload.UserID)
+		c.JSON(http.StatusCreated, newModerationWordResponse(word))
+	}
+}
+
+// updateModerationWordHandler changes the severity of an entry
+func updateModerationWordHandler(store *db.Queries, filter *wordfilter.Filter) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		wordID, err := strconv.ParseInt(c.Param("word_id"), 10, 64)
+		if err != nil || wordID <= 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid moderation word ID"})
+			return
+		}
+		type updateModerationWordRequest struct {
+			Severity string `json:"severity" binding:"required,oneof=low high"`
+		}
+		var req updateModerationWordRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		word, err := store.UpdateModerationWordSeverity(context.Background(), db.UpdateModerationWordSeverityParams{
+			ID:       wordID,
+			Severity: req.Severity,
+		})
+		if errors.Is(err, sql.ErrNoRows) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Moderation word not found"})
+			return
+		}
+		if err != nil {
+			log.Printf("Error updating moderation word %d: %v", wordID, err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update moderation word"})
+			return
+		}
+		reloadWordFilter(store, filter)
+
+		log.Printf("Moderation word %d set to %s", word.ID, word.Severity)
+		c.JSON(http.StatusOK, newModerationWordResponse(word))
+	}
+}
+
+// deleteModerationWordHandler removes an entry from the list
+func deleteModerationWordHandler(store *db.Queries, filter *wordfilter.Filter) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		wordID, err := strconv.ParseInt(c.Param("word_id"), 10, 64)
+		if err != nil || wordID <= 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid moderation word ID"})
+			return
+		}
+
+		deleted, err := store.DeleteModerationWord(context.Background(), wordID)
+		if err != nil {
+			log.Printf("Error deleting moderation word %d: %v", wordID, err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete moderation word"})
+			return
+		}
+		if deleted == 0 {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Moderation word not found"})
+			return
+		}
+		reloadWordFilter(store, filter)
+
+		log.Printf("Moderation word %d deleted", wordID)
+		c.Status(http.StatusNoContent)
+	}
+}
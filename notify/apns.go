@@ -0,0 +1,178 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"crypto/ecdsa"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+)
+
+const (
+	apnsProductionURL = "https://api.push.apple.com/3/device/"
+	apnsSandboxURL    = "https://api.sandbox.push.apple.com/3/device/"
+
+	// apnsTokenLife is how long a provider token is reused. APNs rejects tokens older than an
+	// hour and refreshing more than every 20 minutes.
+	apnsTokenLife = 50 * time.Minute
+)
+
+// APNsConfig identifies the app and the signing key for token-based APNs authentication
+type APNsConfig struct {
+	KeyFile string // The .p8 key downloaded from the Apple developer account
+	KeyID   string
+	TeamID  string
+	Topic   string // The app's bundle ID
+	Sandbox bool   // Use the development environment
+}
+
+// APNsSender sends through the Apple Push Notification service over HTTP/2
+type APNsSender struct {
+	config  APNsConfig
+	key     *ecdsa.PrivateKey
+	baseURL string
+	client  *http.Client
+
+	mu       sync.Mutex
+	token    string
+	issuedAt time.Time
+}
+
+// NewAPNsSender creates a sender authenticating with the configured signing key
+func NewAPNsSender(config APNsConfig) (*APNsSender, error) {
+	if config.KeyID == "" || config.TeamID == "" || config.Topic == "" {
+		return nil, errors.New("apns: key ID, team ID and topic are required")
+	}
+	data, err := os.ReadFile(config.KeyFile)
+	if err != nil {
+		return nil, err
+	}
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, errors.New("apns: key file is not PEM encoded")
+	}
+	parsed, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("apns: invalid key: %w", err)
+	}
+	key, ok := parsed.(*ecdsa.PrivateKey)
+	if !ok || key.Curve.Params().BitSize != 256 {
+		return nil, errors.New("apns: key is not a P-256 ECDSA key")
+	}
+
+	baseURL := apnsProductionURL
+	if config.Sandbox {
+		baseURL = apnsSandboxURL
+	}
+	return &APNsSender{
+		config:  config,
+		key:     key,
+		baseURL: baseURL,
+		client:  &http.Client{Timeout: 10 * time.Second}, // HTTP/2 is negotiated over TLS
+	}, nil
+}
+
+type apnsAlert struct {
+	Title string `json:"title"`
+	Body  string `json:"body"`
+}
+
+type apnsAPS struct {
+	Alert    apnsAlert `json:"alert"`
+	Sound    string    `json:"sound"`
+	ThreadID string    `json:"thread-id,omitempty"`
+}
+
+// Send delivers a notification to one device
+func (s *APNsSender) Send(ctx context.Context, deviceToken string, notification Notification) error {
+	token, err := s.providerToken()
+	if err != nil {
+		return err
+	}
+
+	// Custom data goes next to the aps dictionary
+	payload := map[string]any{
+		"aps": apnsAPS{
+			Alert:    apnsAlert{Title: notification.Title, Body: notification.Body},
+			Sound:    "default",
+			ThreadID: notification.ThreadID,
+		},
+	}
+	for key, value := range notification.Data {
+		if key != "aps" {
+			payload[key] = value
+		}
+	}
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.baseURL+deviceToken, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "bearer "+token)
+	req.Header.Set("apns-topic", s.config.Topic)
+	req.Header.Set("apns-push-type", "alert")
+	if notification.ThreadID != "" {
+		req.Header.Set("apns-collapse-id", notification.ThreadID)
+	}
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("apns: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode == http.StatusOK {
+		return nil
+	}
+
+	var response struct {
+		Reason string `json:"reason"`
+	}
+	json.NewDecoder(resp.Body).Decode(&response)
+	if resp.StatusCode == http.StatusGone || response.Reason == "BadDeviceToken" || response.Reason == "Unregistered" {
+		return ErrInvalidToken
+	}
+	return fmt.Errorf("apns: send returned %d: %s", resp.StatusCode, response.Reason)
+}
+
+// providerToken returns the signed provider authentication token, renewing it when it gets old
+func (s *APNsSender) providerToken() (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.token != "" && time.Since(s.issuedAt) < apnsTokenLife {
+		return s.token, nil
+	}
+
+	now := time.Now()
+	token, err := signJWT(map[string]string{"alg": "ES256", "kid": s.config.KeyID}, map[string]any{
+		"iss": s.config.TeamID,
+		"iat": now.Unix(),
+	}, func(signingInput []byte) ([]byte, error) {
+		digest := sha256.Sum256(signingInput)
+		r, sig, err := ecdsa.Sign(rand.Reader, s.key, digest[:])
+		if err != nil {
+			return nil, err
+		}
+		// JWS wants the fixed-size concatenation r || s, not ASN.1
+		signature := make([]byte, 64)
+		r.FillBytes(signature[:32])
+		sig.FillBytes(signature[32:])
+		return signature, nil
+	})
+	if err != nil {
+		return "", err
+	}
+	s.token, s.issuedAt = token, now
+	return token, nil
+}
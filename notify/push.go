@@ -0,0 +1,145 @@
+// Package notify delivers push notifications to the mobile devices of users who are not connected.
+// Senders talk to the push services (Firebase Cloud Messaging, Apple Push Notification service);
+// the Dispatcher looks up a user's devices and sends in the background, so callers never wait
+// for a push service.
+package notify
+
+import (
+	"context"
+	"errors"
+	"log"
+	"time"
+)
+
+// Push platforms, as stored with a device token
+const (
+	PlatformFCM  = "fcm"  // Android and web, through Firebase Cloud Messaging
+	PlatformAPNs = "apns" // iOS and macOS, through the Apple Push Notification service
+)
+
+// ErrInvalidToken is returned by a Sender when the push service no longer accepts the device
+// token (app uninstalled, token rotated). The token should be forgotten.
+var ErrInvalidToken = errors.New("device token is no longer valid")
+
+// Notification is a provider-independent push notification
+type Notification struct {
+	Title    string
+	Body     string
+	ThreadID string            // Groups notifications on the device, and lets a newer one replace an older one
+	Data     map[string]string // Passed to the app, e.g. the conversation to open
+}
+
+// Sender delivers notifications through one push service
+type Sender interface {
+	Send(ctx context.Context, deviceToken string, notification Notification) error
+}
+
+// Device is a registered device of a user
+type Device struct {
+	Platform string
+	Token    string
+}
+
+// DeviceStore looks up and forgets device tokens
+type DeviceStore interface {
+	Devices(ctx context.Context, userID int32) ([]Device, error)
+	Forget(ctx context.Context, token string) error
+}
+
+// Dispatcher limits
+const (
+	dispatchQueueSize = 1024
+	dispatchWorkers   = 4
+	sendTimeout       = 10 * time.Second
+)
+
+// push is a queued notification for all devices of a user
+type push struct {
+	userID       int32
+	notification Notification
+	done         func(sent bool)
+}
+
+// Dispatcher sends notifications to the devices of users in the background
+type Dispatcher struct {
+	senders map[string]Sender // By platform
+	devices DeviceStore
+	queue   chan push
+}
+
+// NewDispatcher creates a dispatcher sending through the given senders (by platform) and starts
+// its workers. Devices of platforms without a sender are skipped.
+func NewDispatcher(senders map[string]Sender, devices DeviceStore) *Dispatcher {
+	d := &Dispatcher{
+		senders: senders,
+		devices: devices,
+		queue:   make(chan push, dispatchQueueSize),
+	}
+	for i := 0; i < dispatchWorkers; i++ {
+		go d.run()
+	}
+	return d
+}
+
+// Supports reports whether notifications can be sent to devices of the platform
+func (d *Dispatcher) Supports(platform string) bool {
+	return d != nil && d.senders[platform] != nil
+}
+
+// Notify queues a notification for every device of the user without blocking. It returns false
+// if the queue is full and the notification was dropped. Otherwise done (if not nil) is called
+// once the notification was sent, with whether at least one device accepted it.
+func (d *Dispatcher) Notify(userID int32, notification Notification, done func(sent bool)) bool {
+	if d == nil {
+		return false
+	}
+	select {
+	case d.queue <- push{userID: userID, notification: notification, done: done}:
+		return true
+	default:
+		log.Printf("Push Warning: Queue full, dropping notification for user %d", userID)
+		return false
+	}
+}
+
+func (d *Dispatcher) run() {
+	for p := range d.queue {
+		d.send(p)
+	}
+}
+
+// send delivers a notification to every device of its user, forgetting rejected tokens
+func (d *Dispatcher) send(p push) {
+	ctx, cancel := context.WithTimeout(context.Background(), sendTimeout)
+	defer cancel()
+
+	sent := false
+	if p.done != nil {
+		defer func() { p.done(sent) }()
+	}
+
+	devices, err := d.devices.Devices(ctx, p.userID)
+	if err != nil {
+		log.Printf("Push Error: Failed to list devices of user %d: %v", p.userID, err)
+		return
+	}
+	for _, device := range devices {
+		sender := d.senders[device.Platform]
+		if sender == nil {
+			continue
+		}
+		err := sender.Send(ctx, device.Token, p.notification)
+		if errors.Is(err, ErrInvalidToken) {
+			log.Printf("Push: Forgetting rejected %s device of user %d", device.Platform, p.userID)
+			if err := d.devices.Forget(ctx, device.Token); err != nil {
+				log.Printf("Push Error: Failed to forget device of user %d: %v", p.userID, err)
+			}
+			continue
+		}
+		if err != nil {
+			log.Printf("Push Error: Failed to notify %s device of user %d: %v", device.Platform, p.userID, err)
+			continue
+		}
+		sent = true
+	}
+}
@@ -0,0 +1,91 @@
+package pagination
+
+import (
+	"encoding/base64"
+	"errors"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Query parameter names shared by all paginated endpoints
+const (
+	LimitParam  = "limit"
+	CursorParam = "cursor"
+)
+
+var (
+	ErrInvalidLimit  = errors.New("'limit' must be a positive integer")
+	ErrInvalidCursor = errors.New("invalid 'cursor'")
+)
+
+// Page is the parsed pagination of a list request.
+// Lists are keyset paginated: the cursor holds the sort key of the last item of the previous page.
+type Page struct {
+	Limit  int32
+	Cursor string // Decoded cursor, empty for the first page
+}
+
+// Parse reads the limit and cursor query parameters. A missing limit defaults to defaultLimit
+// and limits above maxLimit are clamped instead of rejected.
+func Parse(c *gin.Context, defaultLimit int32, maxLimit int32) (Page, error) {
+	page := Page{Limit: defaultLimit}
+
+	if limitStr := c.Query(LimitParam); limitStr != "" {
+		limit, err := strconv.ParseInt(limitStr, 10, 32)
+		if err != nil || limit < 1 {
+			return Page{}, ErrInvalidLimit
+		}
+		page.Limit = int32(limit)
+	}
+	if page.Limit > maxLimit {
+		page.Limit = maxLimit
+	}
+
+	if cursor := c.Query(CursorParam); cursor != "" {
+		decoded, err := base64.RawURLEncoding.DecodeString(cursor)
+		if err != nil || len(decoded) == 0 {
+			return Page{}, ErrInvalidCursor
+		}
+		page.Cursor = string(decoded)
+	}
+
+	return page, nil
+}
+
+// CursorID returns the cursor of an ID-keyed list, or 0 for the first page
+func (p Page) CursorID() (int64, error) {
+	if p.Cursor == "" {
+		return 0, nil
+	}
+	id, err := strconv.ParseInt(p.Cursor, 10, 64)
+	if err != nil || id <= 0 {
+		return 0, ErrInvalidCursor
+	}
+	return id, nil
+}
+
+// FetchLimit is the number of rows to query: one more than the page size, to know whether a next page exists
+func (p Page) FetchLimit() int32 {
+	return p.Limit + 1
+}
+
+// EncodeCursor turns a sort key into an opaque cursor
+func EncodeCursor(key string) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(key))
+}
+
+// IDKey is the sort key of ID-keyed lists, for use with Trim
+func IDKey(id int64) string {
+	return strconv.FormatInt(id, 10)
+}
+
+// Trim cuts the extra row fetched with FetchLimit and returns the page items with the cursor of the
+// next page, which is empty on the last page
+func Trim[T any](items []T, page Page, key func(T) string) ([]T, string) {
+	if len(items) <= int(page.Limit) {
+		return items, ""
+	}
+	items = items[:page.Limit]
+	return items, EncodeCursor(key(items[len(items)-1]))
+}
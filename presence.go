@@ -0,0 +1,159 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	db "websocket-simple-chat-app/db/sqlc"
+	"websocket-simple-chat-app/hub"
+	"websocket-simple-chat-app/presence"
+	"websocket-simple-chat-app/token"
+)
+
+// userPresenceResponse is the API representation of a user's presence (last_seen_at is null if they never connected)
+type userPresenceResponse struct {
+	UserID     int32      `json:"user_id"`
+	Status     string     `json:"status"`
+	Presence   string     `json:"presence,omitempty"` // Only while online, see presence_status.go
+	LastSeenAt *time.Time `json:"last_seen_at"`
+}
+
+// newUserPresenceResponse combines the last-seen time from the database with the live presence.
+// Invisible users are shown offline.
+func newUserPresenceResponse(row db.ListUserPresenceRow, online bool) userPresenceResponse {
+	response := userPresenceResponse{UserID: row.ID, Status: "offline"}
+	if online && row.Presence != presenceInvisible {
+		response.Status = "online"
+		response.Presence = row.Presence
+	}
+	if row.LastSeenAt.Valid {
+		response.LastSeenAt = &row.LastSeenAt.Time
+	}
+	return response
+}
+
+// userProfileResponse is a user as shown to other users
+type userProfileResponse struct {
+	ID         int32      `json:"id"`
+	Username   string     `json:"username"`
+	Status     string     `json:"status"`             // "online" or "offline"
+	Presence   string     `json:"presence,omitempty"` // Only while online, or always for the user themselves
+	LastSeenAt *time.Time `json:"last_seen_at"`       // null if never connected
+}
+
+// getUserHandler returns a user's profile with their presence, e.g. to show "last seen 5 minutes
+// ago". Invisible users and users who turned online visibility off are shown offline, except to
+// themselves.
+func getUserHandler(store *db.Queries, presenceTracker presence.Tracker) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		payload := c.MustGet(authorizationPayloadKey).(*token.Payload)
+
+		user, ok := parseUserIDParam(c, store)
+		if !ok {
+			return
+		}
+
+		online, err := presenceTracker.AreOnline(context.Background(), []int32{user.ID})
+		if err != nil {
+			log.Printf("Error querying presence of user %d: %v", user.ID, err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch user"})
+			return
+		}
+
+		response := userProfileResponse{ID: user.ID, Username: user.Username, Status: "offline"}
+		shown := online[user.ID] && loadUserSettings(store, user.ID).OnlineVisibility
+		if presence := visiblePresence(user, shown); presence != "" {
+			response.Status = "online"
+			response.Presence = presence
+		}
+		if user.ID == payload.UserID {
+			response.Presence = user.Presence
+		}
+		if user.LastSeenAt.Valid {
+			response.LastSeenAt = &user.LastSeenAt.Time
+		}
+		c.JSON(http.StatusOK, response)
+	}
+}
+
+// queryPresenceHandler returns the status and last-seen time of many users at once,
+// so clients can sync a contact list in one request
+func queryPresenceHandler(store *db.Queries, presenceTracker presence.Tracker) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		type queryPresenceRequest struct {
+			UserIDs []int32 `json:"user_ids" binding:"required,min=1,max=500,dive,min=1"` // At most 500 IDs per request
+		}
+		var req queryPresenceRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		rows, err := store.ListUserPresence(context.Background(), req.UserIDs)
+		if err != nil {
+			log.Printf("Error querying presence of %d users: %v", len(req.UserIDs), err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to query presence"})
+			return
+		}
+
+		online, err := presenceTracker.AreOnline(context.Background(), req.UserIDs)
+		if err != nil {
+			log.Printf("Error querying presence of %d users: %v", len(req.UserIDs), err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to query presence"})
+			return
+		}
+
+		hidden, err := usersHidingOnline(store, req.UserIDs)
+		if err != nil {
+			log.Printf("Error querying online visibility of %d users: %v", len(req.UserIDs), err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to query presence"})
+			return
+		}
+
+		response := make([]userPresenceResponse, 0, len(rows))
+		for _, row := range rows {
+			response = append(response, newUserPresenceResponse(row, online[row.ID] && !hidden[row.ID]))
+		}
+
+		c.JSON(http.StatusOK, gin.H{"presence": response})
+	}
+}
+
+// runPresenceHeartbeat keeps the presence and last-seen time of this instance's users fresh and
+// takes the users of instances that stopped (crashed) offline, as if they disconnected
+func runPresenceHeartbeat(presenceTracker presence.Tracker, connectionHub *hub.Hub, store *db.Queries) {
+	for range time.Tick(presence.HeartbeatInterval) {
+		connected := connectionHub.ConnectedUserIDs()
+		if len(connected) > 0 {
+			if err := store.TouchUsersLastSeen(context.Background(), connected); err != nil {
+				log.Printf("Error refreshing the last-seen time of %d users: %v", len(connected), err)
+			}
+		}
+
+		expired, err := presenceTracker.Heartbeat(context.Background(), connected)
+		if err != nil {
+			log.Printf("Error refreshing presence: %v", err)
+		}
+		if len(expired) == 0 {
+			continue
+		}
+
+		log.Printf("Presence of %d users expired", len(expired))
+		if err := store.SetUsersOffline(context.Background(), expired); err != nil {
+			log.Printf("Error setting %d users with expired presence offline: %v", len(expired), err)
+		}
+		for _, userID := range expired {
+			offlineMsg, err := json.Marshal(newUserOfflineBroadcast(userID))
+			if err != nil {
+				log.Printf("Error marshalling user_offline for user %d: %v", userID, err)
+				continue
+			}
+			connectionHub.Broadcast(offlineMsg, 0)
+		}
+	}
+}
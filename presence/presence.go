@@ -0,0 +1,95 @@
+// Package presence tracks which users are online. With several server instances the users'
+// connections are spread over them, so presence is kept in Redis, where every instance announces
+// its connected users with keys that expire unless the instance keeps refreshing them: users of a
+// crashed instance go offline by themselves.
+package presence
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// Presence timing: an instance refreshes its users every HeartbeatInterval, and a user counts as
+// online until TTL after the last refresh
+const (
+	HeartbeatInterval = 30 * time.Second
+	TTL               = 3 * HeartbeatInterval
+)
+
+// Tracker records the users connected to this instance and answers who is online on any instance.
+// Callers report a user's first and last connection on this instance.
+type Tracker interface {
+	// Connect marks the user online on this instance. It reports whether the user was offline
+	// everywhere before.
+	Connect(ctx context.Context, userID int32) (bool, error)
+	// Disconnect marks the user offline on this instance. It reports whether the user is now
+	// offline everywhere.
+	Disconnect(ctx context.Context, userID int32) (bool, error)
+	// Heartbeat refreshes the presence of the users connected to this instance. It returns the
+	// users that went offline because their instance stopped refreshing them; each such user is
+	// returned to only one instance.
+	Heartbeat(ctx context.Context, userIDs []int32) ([]int32, error)
+	// Online returns the IDs of all online users, in no particular order
+	Online(ctx context.Context) ([]int32, error)
+	// AreOnline reports which of the given users are online
+	AreOnline(ctx context.Context, userIDs []int32) (map[int32]bool, error)
+	// Ping reports whether the presence store is reachable
+	Ping(ctx context.Context) error
+}
+
+// LocalTracker keeps presence in memory, for a single instance
+type LocalTracker struct {
+	mu     sync.Mutex
+	online map[int32]bool
+}
+
+// NewLocalTracker creates an in-memory tracker
+func NewLocalTracker() *LocalTracker {
+	return &LocalTracker{online: make(map[int32]bool)}
+}
+
+func (t *LocalTracker) Connect(_ context.Context, userID int32) (bool, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	first := !t.online[userID]
+	t.online[userID] = true
+	return first, nil
+}
+
+func (t *LocalTracker) Disconnect(_ context.Context, userID int32) (bool, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	last := t.online[userID]
+	delete(t.online, userID)
+	return last, nil
+}
+
+// Heartbeat does nothing: local presence cannot outlive its process
+func (t *LocalTracker) Heartbeat(context.Context, []int32) ([]int32, error) {
+	return nil, nil
+}
+
+func (t *LocalTracker) Online(context.Context) ([]int32, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	userIDs := make([]int32, 0, len(t.online))
+	for userID := range t.online {
+		userIDs = append(userIDs, userID)
+	}
+	return userIDs, nil
+}
+
+func (t *LocalTracker) AreOnline(_ context.Context, userIDs []int32) (map[int32]bool, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	online := make(map[int32]bool, len(userIDs))
+	for _, userID := range userIDs {
+		online[userID] = t.online[userID]
+	}
+	return online, nil
+}
+
+func (t *LocalTracker) Ping(context.Context) error {
+	return nil
+}
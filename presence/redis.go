@@ -0,0 +1,179 @@
+package presence
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"strconv"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// Redis keys. chat:online is a sorted set of the online user IDs, scored by when their presence
+// expires (Unix milliseconds). chat:presence:<user ID> is a sorted set of the instances the user is
+// connected to, scored the same way, and expires itself when no instance refreshes it.
+const (
+	onlineKey             = "chat:online"
+	userPresenceKeyPrefix = "chat:presence:"
+)
+
+// joinScript adds the instance to the user's presence and reports whether the user was offline
+// everywhere before. KEYS: user presence, online. ARGV: instance, expiry, now, user ID, TTL (ms).
+var joinScript = redis.NewScript(`
+local previous = redis.call('ZSCORE', KEYS[2], ARGV[4])
+redis.call('ZADD', KEYS[1], ARGV[2], ARGV[1])
+redis.call('PEXPIRE', KEYS[1], ARGV[5])
+redis.call('ZADD', KEYS[2], 'GT', ARGV[2], ARGV[4])
+if previous and tonumber(previous) > tonumber(ARGV[3]) then
+  return 0
+end
+return 1
+`)
+
+// leaveScript removes the instance (which may be empty) and the expired instances from the user's
+// presence, and takes the user offline if no instance is left. It returns 1 only to the caller that
+// took the user offline. KEYS: user presence, online. ARGV: instance, now, user ID.
+var leaveScript = redis.NewScript(`
+redis.call('ZREM', KEYS[1], ARGV[1])
+redis.call('ZREMRANGEBYSCORE', KEYS[1], '-inf', ARGV[2])
+if redis.call('ZCARD', KEYS[1]) > 0 then
+  return 0
+end
+redis.call('DEL', KEYS[1])
+return redis.call('ZREM', KEYS[2], ARGV[3])
+`)
+
+// RedisTracker keeps presence in Redis, shared by all instances
+type RedisTracker struct {
+	client   *redis.Client
+	instance string
+}
+
+// NewRedisTracker connects to the Redis server at url (e.g. "redis://localhost:6379/0"). Sorted
+// set updates with GT need Redis 6.2 or later.
+func NewRedisTracker(url string) (*RedisTracker, error) {
+	options, err := redis.ParseURL(url)
+	if err != nil {
+		return nil, err
+	}
+
+	client := redis.NewClient(options)
+	if err := client.Ping(context.Background()).Err(); err != nil {
+		client.Close()
+		return nil, err
+	}
+
+	instance := make([]byte, 8)
+	if _, err := rand.Read(instance); err != nil {
+		client.Close()
+		return nil, err
+	}
+	return &RedisTracker{client: client, instance: hex.EncodeToString(instance)}, nil
+}
+
+func userPresenceKey(userID int32) string {
+	return userPresenceKeyPrefix + strconv.Itoa(int(userID))
+}
+
+func unixMilli(t time.Time) string {
+	return strconv.FormatInt(t.UnixMilli(), 10)
+}
+
+func (t *RedisTracker) Connect(ctx context.Context, userID int32) (bool, error) {
+	now := time.Now()
+	first, err := joinScript.Run(ctx, t.client, []string{userPresenceKey(userID), onlineKey},
+		t.instance, unixMilli(now.Add(TTL)), unixMilli(now), userID, TTL.Milliseconds()).Int()
+	return first == 1, err
+}
+
+func (t *RedisTracker) Disconnect(ctx context.Context, userID int32) (bool, error) {
+	return t.leave(ctx, userID, t.instance)
+}
+
+func (t *RedisTracker) leave(ctx context.Context, userID int32, instance string) (bool, error) {
+	last, err := leaveScript.Run(ctx, t.client, []string{userPresenceKey(userID), onlineKey},
+		instance, unixMilli(time.Now()), userID).Int()
+	return last == 1, err
+}
+
+func (t *RedisTracker) Heartbeat(ctx context.Context, userIDs []int32) ([]int32, error) {
+	now := time.Now()
+	expiry := float64(now.Add(TTL).UnixMilli())
+	if len(userIDs) > 0 {
+		_, err := t.client.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+			for _, userID := range userIDs {
+				key := userPresenceKey(userID)
+				pipe.ZAdd(ctx, key, redis.Z{Score: expiry, Member: t.instance})
+				pipe.PExpire(ctx, key, TTL)
+				pipe.ZAddGT(ctx, onlineKey, redis.Z{Score: expiry, Member: userID})
+			}
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	// Users whose instances all stopped refreshing them
+	stale, err := t.client.ZRangeByScore(ctx, onlineKey, &redis.ZRangeBy{Min: "-inf", Max: unixMilli(now)}).Result()
+	if err != nil {
+		return nil, err
+	}
+	var expired []int32
+	for _, member := range stale {
+		userID, err := strconv.ParseInt(member, 10, 32)
+		if err != nil {
+			t.client.ZRem(ctx, onlineKey, member)
+			continue
+		}
+		last, err := t.leave(ctx, int32(userID), "")
+		if err != nil {
+			return expired, err
+		}
+		if last {
+			expired = append(expired, int32(userID))
+		}
+	}
+	return expired, nil
+}
+
+func (t *RedisTracker) Online(ctx context.Context) ([]int32, error) {
+	members, err := t.client.ZRangeByScore(ctx, onlineKey, &redis.ZRangeBy{Min: "(" + unixMilli(time.Now()), Max: "+inf"}).Result()
+	if err != nil {
+		return nil, err
+	}
+	userIDs := make([]int32, 0, len(members))
+	for _, member := range members {
+		if userID, err := strconv.ParseInt(member, 10, 32); err == nil {
+			userIDs = append(userIDs, int32(userID))
+		}
+	}
+	return userIDs, nil
+}
+
+func (t *RedisTracker) AreOnline(ctx context.Context, userIDs []int32) (map[int32]bool, error) {
+	scores := make([]*redis.FloatCmd, len(userIDs))
+	_, err := t.client.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+		for i, userID := range userIDs {
+			scores[i] = pipe.ZScore(ctx, onlineKey, strconv.Itoa(int(userID)))
+		}
+		return nil
+	})
+	if err != nil && !errors.Is(err, redis.Nil) {
+		return nil, err
+	}
+
+	now := float64(time.Now().UnixMilli())
+	online := make(map[int32]bool, len(userIDs))
+	for i, userID := range userIDs {
+		score, err := scores[i].Result()
+		online[userID] = err == nil && score > now
+	}
+	return online, nil
+}
+
+func (t *RedisTracker) Ping(ctx context.Context) error {
+	return t.client.Ping(ctx).Err()
+}
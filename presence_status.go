@@ -0,0 +1,105 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"time"
+
+	db "websocket-simple-chat-app/db/sqlc"
+	"websocket-simple-chat-app/hub"
+)
+
+// Besides being online or offline, users choose how they appear to others with set_presence. The
+// choice is kept across connections and sessions. dnd (do not disturb) marks incoming messages as
+// muted and suppresses their push notifications; invisible users are shown offline to others while
+// connected, and their last-seen time is not refreshed.
+const (
+	presenceAvailable = "available"
+	presenceAway      = "away"
+	presenceBusy      = "busy"
+	presenceDND       = "dnd"
+	presenceInvisible = "invisible"
+)
+
+var validPresences = map[string]bool{
+	presenceAvailable: true,
+	presenceAway:      true,
+	presenceBusy:      true,
+	presenceDND:       true,
+	presenceInvisible: true,
+}
+
+// SetPresenceMessage is sent by a client to change the user's presence
+//
+//wsschema:client
+type SetPresenceMessage struct {
+	Type     string `json:"type"`     // "set_presence"
+	Presence string `json:"presence"` // available, away, busy, dnd or invisible
+}
+
+// visiblePresence returns the presence others see, "" for users shown offline
+func visiblePresence(user db.User, online bool) string {
+	if !online || user.Presence == presenceInvisible {
+		return ""
+	}
+	return user.Presence
+}
+
+// broadcastPresenceEvent sends a user_online, user_offline or presence_changed event to every
+// connected user but excludeUserID (0 for everyone)
+func broadcastPresenceEvent(connectionHub *hub.Hub, event UserStatusBroadcast, excludeUserID int32) {
+	jsonMsg, err := json.Marshal(event)
+	if err != nil {
+		log.Printf("WS Error: Failed to marshal %s message for user %d: %v", event.Type, event.UserID, err)
+		return
+	}
+	connectionHub.Broadcast(jsonMsg, excludeUserID)
+}
+
+// handleSetPresence stores the new presence and tells the other users. Turning invisible looks
+// like going offline to them, and turning visible again like coming online. Users who turned
+// online visibility off look offline either way. The user's own connections always get
+// presence_changed, so all their devices show the same choice.
+func handleSetPresence(store *db.Queries, connectionHub *hub.Hub, userID int32, payload []byte) {
+	var msg SetPresenceMessage
+	if err := json.Unmarshal(payload, &msg); err != nil {
+		log.Printf("WS Error: Failed to unmarshal set_presence: %v. Payload: %s", err, string(payload))
+		return
+	}
+	if !validPresences[msg.Presence] {
+		log.Printf("WS Warning: Invalid set_presence from user %d: %q", userID, msg.Presence)
+		return
+	}
+
+	previous, err := store.GetUserByID(context.Background(), userID)
+	if err != nil {
+		log.Printf("WS Error: Failed to fetch user %d to set presence: %v", userID, err)
+		return
+	}
+	user, err := store.SetUserPresence(context.Background(), db.SetUserPresenceParams{ID: userID, Presence: msg.Presence})
+	if err != nil {
+		log.Printf("WS Error: Failed to set presence of user %d to %s: %v", userID, msg.Presence, err)
+		return
+	}
+	if previous.Presence == user.Presence {
+		return
+	}
+
+	changed := UserStatusBroadcast{Type: "presence_changed", UserID: userID, CreatedAt: time.Now().UTC(), Presence: user.Presence}
+	switch {
+	case !loadUserSettings(store, userID).OnlineVisibility:
+		// Others see the user offline whatever the presence
+		sendJSONToUser(connectionHub, userID, changed)
+	case user.Presence == presenceInvisible:
+		broadcastPresenceEvent(connectionHub, newUserOfflineBroadcast(userID), userID)
+		sendJSONToUser(connectionHub, userID, changed)
+	case previous.Presence == presenceInvisible:
+		online := UserStatusBroadcast{Type: "user_online", UserID: userID, CreatedAt: time.Now().UTC(), Presence: user.Presence}
+		broadcastPresenceEvent(connectionHub, online, userID)
+		sendJSONToUser(connectionHub, userID, changed)
+	default:
+		broadcastPresenceEvent(connectionHub, changed, 0)
+	}
+	log.Printf("User %d changed presence from %s to %s", userID, previous.Presence, user.Presence)
+}
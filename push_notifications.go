@@ -0,0 +1,163 @@
+package main
+
+import (
+	"context"
+	"log"
+	"net/http"
+	"strconv"
+
+	"websocket-simple-chat-app/config"
+	db "websocket-simple-chat-app/db/sqlc"
+	"websocket-simple-chat-app/notify"
+	"websocket-simple-chat-app/token"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Recipients without a connection get a push notification on the devices they registered. Each
+// push service is enabled by its settings; without any, device registration is unavailable.
+
+// deviceStore adapts the device_tokens table to the dispatcher
+type deviceStore struct {
+	store *db.Queries
+}
+
+func (s deviceStore) Devices(ctx context.Context, userID int32) ([]notify.Device, error) {
+	tokens, err := s.store.ListDeviceTokens(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	devices := make([]notify.Device, len(tokens))
+	for i, deviceToken := range tokens {
+		devices[i] = notify.Device{Platform: deviceToken.Platform, Token: deviceToken.Token}
+	}
+	return devices, nil
+}
+
+func (s deviceStore) Forget(ctx context.Context, token string) error {
+	_, err := s.store.DeleteDeviceToken(ctx, token)
+	return err
+}
+
+// newPushDispatcher creates the dispatcher for the configured push services. It returns nil when
+// none is configured, which disables push notifications.
+func newPushDispatcher(cfg config.Config, store *db.Queries) (*notify.Dispatcher, error) {
+	senders := make(map[string]notify.Sender)
+	if cfg.PushFCMCredentialsFile != "" {
+		sender, err := notify.NewFCMSender(cfg.PushFCMCredentialsFile)
+		if err != nil {
+			return nil, err
+		}
+		senders[notify.PlatformFCM] = sender
+	}
+	if cfg.PushAPNsKeyFile != "" {
+		sender, err := notify.NewAPNsSender(notify.APNsConfig{
+			KeyFile: cfg.PushAPNsKeyFile,
+			KeyID:   cfg.PushAPNsKeyID,
+			TeamID:  cfg.PushAPNsTeamID,
+			Topic:   cfg.PushAPNsTopic,
+			Sandbox: cfg.PushAPNsSandbox,
+		})
+		if err != nil {
+			return nil, err
+		}
+		senders[notify.PlatformAPNs] = sender
+	}
+	if len(senders) == 0 {
+		return nil, nil
+	}
+	return notify.NewDispatcher(senders, deviceStore{store: store}), nil
+}
+
+// notifyOfflineRecipient sends a push notification for a private message its recipient is not
+// connected to receive. Silent messages (muted conversation, do not disturb or quiet hours) and
+// deactivated recipients get none. undelivered is called when no device got the notification.
+func notifyOfflineRecipient(dispatcher *notify.Dispatcher, recipient db.User, senderUsername string, message db.Message, silent bool, undelivered func()) {
+	if silent || recipient.DeactivatedAt.Valid {
+		undelivered()
+		return
+	}
+	senderID := strconv.Itoa(int(message.SenderID))
+	queued := dispatcher.Notify(recipient.ID, notify.Notification{
+		Title:    senderUsername,
+		Body:     messagePreview(message.ContentType, message.Content),
+		ThreadID: "conversation-" + senderID,
+		Data: map[string]string{
+			"type":       "incoming_message",
+			"sender_id":  senderID,
+			"message_id": strconv.FormatInt(message.ID, 10),
+		},
+	}, func(sent bool) {
+		if !sent {
+			undelivered()
+		}
+	})
+	if !queued {
+		undelivered()
+	}
+}
+
+// registerDeviceHandler registers the push token of the caller's device. A token already registered
+// moves to the caller, since a device only notifies the account last logged in on it.
+func registerDeviceHandler(store *db.Queries, dispatcher *notify.Dispatcher) gin.HandlerFunc {
+	type registerDeviceRequest struct {
+		Platform string `json:"platform" binding:"required,oneof=fcm apns"`
+		Token    string `json:"token" binding:"required,max=512"`
+	}
+	return func(c *gin.Context) {
+		authPayload := c.MustGet(authorizationPayloadKey).(*token.Payload)
+
+		var req registerDeviceRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		if !dispatcher.Supports(req.Platform) {
+			c.JSON(http.StatusServiceUnavailable, gin.H{"error": "Push notifications via " + req.Platform + " are not configured"})
+			return
+		}
+
+		device, err := store.UpsertDeviceToken(context.Background(), db.UpsertDeviceTokenParams{
+			UserID:   authPayload.UserID,
+			Platform: req.Platform,
+			Token:    req.Token,
+		})
+		if err != nil {
+			log.Printf("Error registering %s device of user %d: %v", req.Platform, authPayload.UserID, err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to register device"})
+			return
+		}
+		c.JSON(http.StatusOK, device)
+	}
+}
+
+// unregisterDeviceHandler stops push notifications to one of the caller's devices, e.g. on logout
+func unregisterDeviceHandler(store *db.Queries) gin.HandlerFunc {
+	type unregisterDeviceRequest struct {
+		Token string `json:"token" binding:"required"`
+	}
+	return func(c *gin.Context) {
+		authPayload := c.MustGet(authorizationPayloadKey).(*token.Payload)
+
+		var req unregisterDeviceRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		deleted, err := store.DeleteUserDeviceToken(context.Background(), db.DeleteUserDeviceTokenParams{
+			UserID: authPayload.UserID,
+			Token:  req.Token,
+		})
+		if err != nil {
+			log.Printf("Error unregistering device of user %d: %v", authPayload.UserID, err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to unregister device"})
+			return
+		}
+		if deleted == 0 {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Device not registered"})
+			return
+		}
+		c.Status(http.StatusNoContent)
+	}
+}
@@ -0,0 +1,157 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"websocket-simple-chat-app/config"
+	db "websocket-simple-chat-app/db/sqlc"
+	"websocket-simple-chat-app/reputation"
+)
+
+// Accounts signed up (or logged in for the first time) from a suspicious IP are quarantined: they
+// can send a few messages per hour until an admin verifies them
+const (
+	quarantineSendLimit    = 20
+	quarantineSendWindow   = time.Hour
+	quarantineListLimit    = 500
+	reputationCheckTimeout = 3 * time.Second
+	signupVelocityWindow   = time.Hour
+	reputationSweepPeriod  = 10 * time.Minute
+)
+
+// errQuarantineLimit is returned when a quarantined account reached its send limit
+var errQuarantineLimit = errors.New("send limit reached: the account is limited until it is verified")
+
+// newReputationChecker combines the local heuristics with the external service, if configured
+func newReputationChecker(cfg config.Config) (reputation.Checker, error) {
+	heuristics, err := reputation.NewHeuristics(cfg.ReputationDenylist, cfg.SignupIPLimit, signupVelocityWindow)
+	if err != nil {
+		return nil, err
+	}
+	go func() {
+		for range time.Tick(reputationSweepPeriod) {
+			heuristics.Sweep()
+		}
+	}()
+
+	if cfg.ReputationServiceURL == "" {
+		return heuristics, nil
+	}
+	return reputation.Chain(heuristics, reputation.NewService(cfg.ReputationServiceURL)), nil
+}
+
+// checkReputation quarantines the user if the IP looks suspicious and reports whether the user is
+// quarantined. The check fails open: errors are logged and the user is let through.
+func checkReputation(store *db.Queries, checker reputation.Checker, userID int32, ip string, action reputation.Action) bool {
+	ctx, cancel := context.WithTimeout(context.Background(), reputationCheckTimeout)
+	defer cancel()
+
+	verdict, err := checker.Check(ctx, ip, action)
+	if err != nil {
+		log.Printf("Warning: IP reputation check of %s failed: %v", ip, err)
+	}
+	if !verdict.Suspicious {
+		return isQuarantined(store, userID)
+	}
+
+	err = store.QuarantineUser(context.Background(), db.QuarantineUserParams{
+		UserID:    userID,
+		Reason:    verdict.Reason,
+		IpAddress: ip,
+	})
+	if err != nil {
+		log.Printf("Error quarantining user %d: %v", userID, err)
+		return false
+	}
+	log.Printf("Security: User %d quarantined on %s from %s: %s", userID, action, ip, verdict.Reason)
+	return true
+}
+
+// checkFirstLoginReputation runs the reputation check on the first login of an account, which
+// covers accounts that were not created through signup (imports, SCIM)
+func checkFirstLoginReputation(store *db.Queries, checker reputation.Checker, userID int32, ip string) bool {
+	logins, err := store.CountLoginHistory(context.Background(), userID)
+	if err != nil {
+		log.Printf("Error counting login history for user %d: %v", userID, err)
+		return isQuarantined(store, userID)
+	}
+	if logins > 0 {
+		return isQuarantined(store, userID)
+	}
+	return checkReputation(store, checker, userID, ip, reputation.ActionFirstLogin)
+}
+
+// isQuarantined reports whether the user is quarantined
+func isQuarantined(store *db.Queries, userID int32) bool {
+	_, err := store.GetQuarantine(context.Background(), userID)
+	if err != nil && err != sql.ErrNoRows {
+		log.Printf("Error fetching quarantine of user %d: %v", userID, err)
+	}
+	return err == nil
+}
+
+// checkQuarantineSend returns errQuarantineLimit if the user is quarantined and already sent
+// quarantineSendLimit messages within quarantineSendWindow. Database errors let the message through.
+func checkQuarantineSend(store *db.Queries, userID int32) error {
+	if !isQuarantined(store, userID) {
+		return nil
+	}
+	sends, err := store.CountRecentSends(context.Background(), db.CountRecentSendsParams{
+		UserID: userID,
+		Since:  time.Now().Add(-quarantineSendWindow),
+	})
+	if err != nil {
+		log.Printf("Error counting recent sends of user %d: %v", userID, err)
+		return nil
+	}
+	if sends >= quarantineSendLimit {
+		return errQuarantineLimit
+	}
+	return nil
+}
+
+// --- Admin Endpoints ---
+
+// listQuarantinedUsersHandler lists the quarantined accounts, newest first
+func listQuarantinedUsersHandler(store *db.Queries) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		users, err := store.ListQuarantinedUsers(context.Background(), quarantineListLimit)
+		if err != nil {
+			log.Printf("Error listing quarantined users: %v", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to list quarantined users"})
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"users": users})
+	}
+}
+
+// verifyUserHandler releases an account from quarantine
+func verifyUserHandler(store *db.Queries) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		user, ok := parseUserIDParam(c, store)
+		if !ok {
+			return
+		}
+
+		released, err := store.ReleaseQuarantine(context.Background(), user.ID)
+		if err != nil {
+			log.Printf("Error releasing user %d from quarantine: %v", user.ID, err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to verify user"})
+			return
+		}
+		if released == 0 {
+			c.JSON(http.StatusNotFound, gin.H{"error": "User is not quarantined"})
+			return
+		}
+
+		log.Printf("User %d verified and released from quarantine", user.ID)
+		c.JSON(http.StatusOK, gin.H{"message": "User verified", "user_id": user.ID})
+	}
+}
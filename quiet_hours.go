@@ -0,0 +1,129 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	db "websocket-simple-chat-app/db/sqlc"
+	"websocket-simple-chat-app/token"
+)
+
+// During a user's quiet hours, private messages to them send no push notification. Messages are
+// still delivered to open connections, flagged quiet, so clients can skip sounds and alerts. The
+// hours are local times in the user's time zone and may span midnight (e.g. 22:00 to 07:00).
+
+const quietHoursLayout = "15:04"
+
+// quietHoursResponse is the API representation of a user's quiet hours
+type quietHoursResponse struct {
+	Start    string `json:"start"`    // Local time, e.g. "22:00"
+	End      string `json:"end"`      // Local time, e.g. "07:00"
+	Timezone string `json:"timezone"` // IANA time zone, e.g. "Europe/Berlin"
+	Active   bool   `json:"active"`   // True if quiet hours are on right now
+}
+
+// newQuietHoursResponse returns nil for users without quiet hours
+func newQuietHoursResponse(settings db.UserSetting) *quietHoursResponse {
+	if !settings.QuietHoursStart.Valid {
+		return nil
+	}
+	return &quietHoursResponse{
+		Start:    settings.QuietHoursStart.String,
+		End:      settings.QuietHoursEnd.String,
+		Timezone: settings.QuietHoursTimezone.String,
+		Active:   inQuietHours(settings, time.Now()),
+	}
+}
+
+// inQuietHours reports whether now falls in the user's quiet hours. Settings that no longer parse
+// (e.g. a time zone removed from the tz database) count as no quiet hours.
+func inQuietHours(settings db.UserSetting, now time.Time) bool {
+	if !settings.QuietHoursStart.Valid {
+		return false
+	}
+	location, err := time.LoadLocation(settings.QuietHoursTimezone.String)
+	if err != nil {
+		log.Printf("Warning: Invalid quiet hours time zone of user %d: %v", settings.UserID, err)
+		return false
+	}
+	start, startErr := time.Parse(quietHoursLayout, settings.QuietHoursStart.String)
+	end, endErr := time.Parse(quietHoursLayout, settings.QuietHoursEnd.String)
+	if startErr != nil || endErr != nil {
+		log.Printf("Warning: Invalid quiet hours of user %d: %s to %s", settings.UserID, settings.QuietHoursStart.String, settings.QuietHoursEnd.String)
+		return false
+	}
+
+	local := now.In(location)
+	minute := local.Hour()*60 + local.Minute()
+	startMinute := start.Hour()*60 + start.Minute()
+	endMinute := end.Hour()*60 + end.Minute()
+	if startMinute < endMinute {
+		return minute >= startMinute && minute < endMinute
+	}
+	return minute >= startMinute || minute < endMinute
+}
+
+// setQuietHoursHandler sets the authenticated user's quiet hours, replacing previous ones
+func setQuietHoursHandler(store *db.Queries) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		type setQuietHoursRequest struct {
+			Start    string `json:"start" binding:"required"`
+			End      string `json:"end" binding:"required"`
+			Timezone string `json:"timezone" binding:"required,max=64"`
+		}
+		payload := c.MustGet(authorizationPayloadKey).(*token.Payload)
+
+		var req setQuietHoursRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		start, startErr := time.Parse(quietHoursLayout, req.Start)
+		end, endErr := time.Parse(quietHoursLayout, req.End)
+		if startErr != nil || endErr != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid 'start' or 'end', expected HH:MM"})
+			return
+		}
+		if start.Equal(end) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "'start' and 'end' must differ"})
+			return
+		}
+		if _, err := time.LoadLocation(req.Timezone); err != nil || req.Timezone == "Local" {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid 'timezone', expected an IANA time zone such as Europe/Berlin"})
+			return
+		}
+
+		settings, err := store.SetUserQuietHours(context.Background(), db.SetUserQuietHoursParams{
+			UserID:             payload.UserID,
+			QuietHoursStart:    sql.NullString{String: start.Format(quietHoursLayout), Valid: true},
+			QuietHoursEnd:      sql.NullString{String: end.Format(quietHoursLayout), Valid: true},
+			QuietHoursTimezone: sql.NullString{String: req.Timezone, Valid: true},
+		})
+		if err != nil {
+			log.Printf("Error setting the quiet hours of user %d: %v", payload.UserID, err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to set quiet hours"})
+			return
+		}
+		c.JSON(http.StatusOK, newQuietHoursResponse(settings))
+	}
+}
+
+// clearQuietHoursHandler turns the authenticated user's quiet hours off
+func clearQuietHoursHandler(store *db.Queries) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		payload := c.MustGet(authorizationPayloadKey).(*token.Payload)
+
+		_, err := store.SetUserQuietHours(context.Background(), db.SetUserQuietHoursParams{UserID: payload.UserID})
+		if err != nil {
+			log.Printf("Error clearing the quiet hours of user %d: %v", payload.UserID, err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to clear quiet hours"})
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"message": "Quiet hours turned off"})
+	}
+}
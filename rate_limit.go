@@ -0,0 +1,128 @@
+package main
+
+import (
+	"encoding/json"
+	"log"
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"websocket-simple-chat-app/hub"
+	"websocket-simple-chat-app/ratelimit"
+)
+
+// rateLimitSweepInterval is how often full buckets are forgotten
+const rateLimitSweepInterval = time.Minute
+
+// wsRateLimitMaxViolations is the number of rate limited messages in a row after which a connection
+// is closed with wsCloseRateLimited: the client ignores the rate_limited frames
+const wsRateLimitMaxViolations = 50
+
+// wsRateWarningPercent is the share of the burst left below which a connection gets a rate_warning,
+// so that the client can slow down before its messages are refused
+const wsRateWarningPercent = 20
+
+// RateLimitedMessage is sent on the connection instead of handling a message that exceeded the
+// user's rate limit
+//
+//wsschema:server
+type RateLimitedMessage struct {
+	Type         string    `json:"type"`                    // "rate_limited"
+	MessageType  string    `json:"message_type"`            // Type of the message that was not handled
+	ClientMsgID  string    `json:"client_msg_id,omitempty"` // Echoed from the message, if it had one
+	RetryAfterMs int64     `json:"retry_after_ms"`          // When the next message will be accepted
+	CreatedAt    time.Time `json:"created_at"`
+}
+
+// RateWarningMessage is sent on a connection whose user is close to the rate limit. It is sent once
+// until the user's bucket refills above the threshold again.
+//
+//wsschema:server
+type RateWarningMessage struct {
+	Type          string    `json:"type"`            // "rate_warning"
+	Used          int       `json:"used"`            // Messages counted against the burst
+	Limit         int       `json:"limit"`           // Messages allowed at once (WS_MESSAGE_BURST)
+	Remaining     int       `json:"remaining"`       // Messages accepted right away before rate_limited
+	RatePerSecond float64   `json:"rate_per_second"` // Messages allowed per second on average (WS_MESSAGE_RATE)
+	ResetAfterMs  int64     `json:"reset_after_ms"`  // Until the whole burst is available again
+	CreatedAt     time.Time `json:"created_at"`
+}
+
+// newEngine creates the router. Client IPs, which key the per-IP limiters, the brute-force guards
+// and the reputation checks, come from X-Forwarded-For only behind the trusted proxies: otherwise
+// every client could pick a new key for each request.
+func newEngine(trustedProxies []string) (*gin.Engine, error) {
+	r := gin.Default()
+	if err := r.SetTrustedProxies(trustedProxies); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// newIPRateLimiter creates a per-IP limiter of perMinute requests and sweeps it in the background
+func newIPRateLimiter(perMinute int) *ratelimit.Limiter[string] {
+	limiter := ratelimit.PerMinute[string](perMinute)
+	go func() {
+		for range time.Tick(rateLimitSweepInterval) {
+			limiter.Sweep()
+		}
+	}()
+	return limiter
+}
+
+// rateLimitMiddleware rejects requests of client IPs that exceed the limiter with 429 and a
+// Retry-After header (seconds). The router must come from newEngine.
+func rateLimitMiddleware(limiter *ratelimit.Limiter[string]) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		allowed, retryAfter := limiter.Allow(c.ClientIP())
+		if !allowed {
+			log.Printf("Rate limit: Rejected %s %s from %s", c.Request.Method, c.FullPath(), c.ClientIP())
+			c.Header("Retry-After", strconv.Itoa(int(math.Ceil(retryAfter.Seconds()))))
+			c.AbortWithStatusJSON(http.StatusTooManyRequests, gin.H{"error": "Too many requests, try again later", "code": "rate_limited"})
+			return
+		}
+		c.Next()
+	}
+}
+
+// sendRateLimited tells the connection that a message was not handled because of the rate limit
+func sendRateLimited(client *hub.Client, messageType string, clientMsgID string, retryAfter time.Duration) {
+	jsonMsg, err := json.Marshal(RateLimitedMessage{
+		Type:         "rate_limited",
+		MessageType:  messageType,
+		ClientMsgID:  clientMsgID,
+		RetryAfterMs: (retryAfter + time.Millisecond - 1).Milliseconds(), // Rounded up
+		CreatedAt:    time.Now().UTC(),
+	})
+	if err != nil {
+		log.Printf("WS Error: Failed to marshal rate_limited for user %d: %v", client.UserID, err)
+		return
+	}
+	client.Send(jsonMsg)
+}
+
+// nearRateLimit reports whether less than wsRateWarningPercent of the user's burst is left
+func nearRateLimit(usage ratelimit.Result) bool {
+	return usage.Burst > 0 && usage.Remaining*100 < usage.Burst*wsRateWarningPercent
+}
+
+// sendRateWarning tells the connection how close its user is to the rate limit
+func sendRateWarning(client *hub.Client, usage ratelimit.Result) {
+	jsonMsg, err := json.Marshal(RateWarningMessage{
+		Type:          "rate_warning",
+		Used:          usage.Burst - usage.Remaining,
+		Limit:         usage.Burst,
+		Remaining:     usage.Remaining,
+		RatePerSecond: usage.Rate,
+		ResetAfterMs:  (usage.ResetAfter + time.Millisecond - 1).Milliseconds(), // Rounded up
+		CreatedAt:     time.Now().UTC(),
+	})
+	if err != nil {
+		log.Printf("WS Error: Failed to marshal rate_warning for user %d: %v", client.UserID, err)
+		return
+	}
+	client.Send(jsonMsg)
+}
@@ -0,0 +1,55 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+// limitedRequest sends GET / from remoteAddr with the X-Forwarded-For header and returns the status
+func limitedRequest(r *gin.Engine, remoteAddr string, forwardedFor string) int {
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.RemoteAddr = remoteAddr
+	req.Header.Set("X-Forwarded-For", forwardedFor)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	return w.Code
+}
+
+func newLimitedEngine(t *testing.T, trustedProxies []string) *gin.Engine {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+	r, err := newEngine(trustedProxies)
+	if err != nil {
+		t.Fatal(err)
+	}
+	r.GET("/", rateLimitMiddleware(newIPRateLimiter(1)), func(c *gin.Context) { c.Status(http.StatusOK) })
+	return r
+}
+
+// Without trusted proxies, a client cannot reset its limit with a new X-Forwarded-For each request
+func TestRateLimitIgnoresForwardedForOfClients(t *testing.T) {
+	r := newLimitedEngine(t, nil)
+	if code := limitedRequest(r, "203.0.113.7:1234", "198.51.100.1"); code != http.StatusOK {
+		t.Fatalf("first request: %d", code)
+	}
+	if code := limitedRequest(r, "203.0.113.7:1234", "198.51.100.2"); code != http.StatusTooManyRequests {
+		t.Fatalf("second request with another X-Forwarded-For: %d, want 429", code)
+	}
+}
+
+// Behind a trusted proxy, the clients it forwards for are limited separately
+func TestRateLimitUsesForwardedForOfTrustedProxies(t *testing.T) {
+	r := newLimitedEngine(t, []string{"192.0.2.0/24"})
+	if code := limitedRequest(r, "192.0.2.10:1234", "198.51.100.1"); code != http.StatusOK {
+		t.Fatalf("first client: %d", code)
+	}
+	if code := limitedRequest(r, "192.0.2.10:1234", "198.51.100.2"); code != http.StatusOK {
+		t.Fatalf("second client: %d", code)
+	}
+	if code := limitedRequest(r, "192.0.2.10:1234", "198.51.100.1"); code != http.StatusTooManyRequests {
+		t.Fatalf("first client again: %d, want 429", code)
+	}
+}
@@ -0,0 +1,97 @@
+package ratelimit
+
+import (
+	"math"
+	"sync"
+	"time"
+)
+
+// bucket is the token bucket of one key
+type bucket struct {
+	tokens  float64
+	updated time.Time
+}
+
+// Limiter is a set of token buckets, one per key (an IP, a user ID, ...). Each bucket holds up to
+// burst tokens and refills at rate tokens per second; every allowed event takes one token.
+// It is safe for concurrent use.
+type Limiter[K comparable] struct {
+	rate  float64
+	burst float64
+
+	buckets map[K]*bucket
+
+	mu sync.Mutex
+}
+
+// New creates a limiter allowing rate events per second on average, in bursts of up to burst events
+func New[K comparable](rate float64, burst int) *Limiter[K] {
+	return &Limiter[K]{
+		rate:    rate,
+		burst:   float64(burst),
+		buckets: make(map[K]*bucket),
+	}
+}
+
+// PerMinute creates a limiter allowing count events per minute per key, all at once if they come in a burst
+func PerMinute[K comparable](count int) *Limiter[K] {
+	return New[K](float64(count)/60, count)
+}
+
+// Result is the outcome of Take and the state of the key's bucket after it
+type Result struct {
+	Allowed    bool
+	Remaining  int           // Whole tokens left, events allowed right away
+	Burst      int           // Capacity of the bucket
+	Rate       float64       // Tokens refilled per second
+	RetryAfter time.Duration // Until the next token is available, when not allowed
+	ResetAfter time.Duration // Until the bucket is full again
+}
+
+// Allow takes a token from the key's bucket. If the bucket is empty it returns false and how long
+// until the next token is available.
+func (l *Limiter[K]) Allow(key K) (bool, time.Duration) {
+	result := l.Take(key)
+	return result.Allowed, result.RetryAfter
+}
+
+// Take is Allow, also returning how full the key's bucket is, e.g. to warn before refusing events
+func (l *Limiter[K]) Take(key K) Result {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := time.Now()
+	b, ok := l.buckets[key]
+	if !ok {
+		b = &bucket{tokens: l.burst, updated: now}
+		l.buckets[key] = b
+	} else {
+		b.tokens = math.Min(l.burst, b.tokens+now.Sub(b.updated).Seconds()*l.rate)
+		b.updated = now
+	}
+
+	result := Result{Burst: int(l.burst), Rate: l.rate}
+	if b.tokens >= 1 {
+		b.tokens--
+		result.Allowed = true
+	} else {
+		result.RetryAfter = time.Duration((1 - b.tokens) / l.rate * float64(time.Second))
+	}
+	result.Remaining = int(b.tokens)
+	result.ResetAfter = time.Duration((l.burst - b.tokens) / l.rate * float64(time.Second))
+	return result
+}
+
+// Sweep forgets the buckets that refilled completely, as they behave like new ones. It should be
+// called periodically.
+func (l *Limiter[K]) Sweep() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := time.Now()
+	for key, b := range l.buckets {
+		if b.tokens+now.Sub(b.updated).Seconds()*l.rate >= l.burst {
+			delete(l.buckets, key)
+		}
+	}
+}
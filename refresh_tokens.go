@@ -0,0 +1,148 @@
+package main
+
+import (
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	db "websocket-simple-chat-app/db/sqlc"
+	"websocket-simple-chat-app/token"
+)
+
+// sessionSweepInterval is how often expired refresh sessions are deleted
+const sessionSweepInterval = time.Hour
+
+// newRefreshTokenMaker returns the maker of refresh tokens. Its key is derived from the access token key,
+// so a refresh token is never accepted as an access token and vice versa.
+func newRefreshTokenMaker(accessSymmetricKey string) (token.Maker, error) {
+	key := sha256.Sum256([]byte("refresh:" + accessSymmetricKey))
+	return token.NewPasetoMaker(key[:])
+}
+
+// issueRefreshToken creates a refresh token for the user and stores its session
+func issueRefreshToken(store *db.Queries, refreshMaker token.Maker, user db.User, duration time.Duration, clientIP string, userAgent string) (string, *token.Payload, error) {
+	refreshToken, payload, err := refreshMaker.CreateToken(user.ID, user.Username, duration)
+	if err != nil {
+		return "", nil, err
+	}
+
+	_, err = store.CreateSession(context.Background(), db.CreateSessionParams{
+		ID:        payload.ID,
+		UserID:    user.ID,
+		UserAgent: userAgent,
+		ClientIp:  clientIP,
+		ExpiresAt: payload.ExpiredAt,
+	})
+	if err != nil {
+		return "", nil, err
+	}
+	return refreshToken, payload, nil
+}
+
+// refreshTokenHandler exchanges a refresh token for a new access token and a new refresh token.
+// Refresh tokens are single use: the presented one is revoked (rotated).
+func refreshTokenHandler(store *db.Queries, tokenMaker token.Maker, refreshMaker token.Maker, sessions sessionConfig) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		type refreshTokenRequest struct {
+			RefreshToken string `json:"refresh_token" binding:"required"`
+		}
+		var req refreshTokenRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		// 1. Verify the token and load its session
+		refreshPayload, err := refreshMaker.VerifyToken(req.RefreshToken)
+		if err != nil {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+			return
+		}
+
+		session, err := store.GetSession(context.Background(), refreshPayload.ID)
+		if err != nil {
+			if err != sql.ErrNoRows {
+				log.Printf("Error fetching session %s: %v", refreshPayload.ID, err)
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to refresh token"})
+				return
+			}
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "unknown refresh token"})
+			return
+		}
+		if session.RevokedAt.Valid || session.UserID != refreshPayload.UserID {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "refresh token was already used or revoked"})
+			return
+		}
+
+		// 2. The account must still be active
+		user, err := store.GetUserByID(context.Background(), session.UserID)
+		if err != nil {
+			log.Printf("Error fetching user %d for session %s: %v", session.UserID, session.ID, err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to refresh token"})
+			return
+		}
+		if user.DeactivatedAt.Valid {
+			c.JSON(http.StatusForbidden, gin.H{"error": "Account is deactivated"})
+			return
+		}
+		if accountSuspended(user) {
+			c.JSON(http.StatusForbidden, gin.H{"error": "Account is suspended"})
+			return
+		}
+
+		// 3. Rotate: only one request can revoke the session, a concurrent reuse gets 401
+		revoked, err := store.RevokeSession(context.Background(), session.ID)
+		if err != nil {
+			log.Printf("Error revoking session %s: %v", session.ID, err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to refresh token"})
+			return
+		}
+		if revoked == 0 {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "refresh token was already used or revoked"})
+			return
+		}
+
+		// 4. Issue the new pair (the username is read again in case it changed)
+		accessToken, accessPayload, err := tokenMaker.CreateToken(user.ID, user.Username, sessions.AccessTokenDuration)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate token"})
+			return
+		}
+		refreshToken, newRefreshPayload, err := issueRefreshToken(store, refreshMaker, user, sessions.RefreshTokenDuration, c.ClientIP(), c.Request.UserAgent())
+		if err != nil {
+			log.Printf("Error issuing refresh token for user %d: %v", user.ID, err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate token"})
+			return
+		}
+
+		if sessions.AuthCookieName != "" {
+			setAuthCookie(c, sessions.AuthCookieName, accessToken, accessPayload.ExpiredAt)
+		}
+		c.JSON(http.StatusOK, gin.H{
+			"token":                    accessToken,
+			"payload":                  accessPayload,
+			"refresh_token":            refreshToken,
+			"refresh_token_expires_at": newRefreshPayload.ExpiredAt,
+		})
+	}
+}
+
+// runSessionSweeper periodically deletes expired refresh sessions and token revocations
+func runSessionSweeper(store *db.Queries) {
+	for range time.Tick(sessionSweepInterval) {
+		deleted, err := store.DeleteExpiredSessions(context.Background())
+		if err != nil {
+			log.Printf("Error sweeping expired sessions: %v", err)
+			continue
+		}
+		if deleted > 0 {
+			log.Printf("Deleted %d expired sessions", deleted)
+		}
+		sweepRevokedTokens(store)
+	}
+}
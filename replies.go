@@ -0,0 +1,97 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	db "websocket-simple-chat-app/db/sqlc"
+)
+
+// A private message can reply to an earlier message of the same conversation. The reply carries a
+// quote of its parent, so clients can show it without loading the parent first.
+
+var errInvalidReply = errors.New("reply_to_message_id is not a message of this conversation")
+
+// QuotedMessage is the parent of a reply, as shown above it
+type QuotedMessage struct {
+	ID          int64     `json:"id"`
+	SenderID    int32     `json:"sender_id"`
+	ContentType string    `json:"content_type"`
+	Preview     string    `json:"preview"` // Short single-line text of the parent, empty once it was deleted
+	CreatedAt   time.Time `json:"created_at"`
+	Deleted     bool      `json:"deleted"` // The parent was deleted for everyone after the reply was sent
+}
+
+func newQuotedMessage(parent db.Message) *QuotedMessage {
+	quoted := &QuotedMessage{
+		ID:          parent.ID,
+		SenderID:    parent.SenderID,
+		ContentType: parent.ContentType,
+		CreatedAt:   parent.CreatedAt,
+		Deleted:     parent.DeletedAt.Valid,
+	}
+	if !quoted.Deleted {
+		quoted.Preview = messagePreview(parent.ContentType, parent.Content)
+	}
+	return quoted
+}
+
+// getReplyParent returns the message a new message from senderID to recipientID replies to. It
+// returns errInvalidReply unless the parent belongs to their conversation and was not deleted.
+func getReplyParent(store *db.Queries, senderID int32, recipientID int32, parentID int64) (db.Message, error) {
+	parent, err := store.GetMessage(context.Background(), parentID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return db.Message{}, errInvalidReply
+		}
+		return db.Message{}, err
+	}
+	inConversation := (parent.SenderID == senderID && parent.ReceiverID == recipientID) ||
+		(parent.SenderID == recipientID && parent.ReceiverID == senderID)
+	if !inConversation || parent.DeletedAt.Valid {
+		return db.Message{}, errInvalidReply
+	}
+	return parent, nil
+}
+
+// messageResponse is a private message as returned by the API, with its delivery state and the
+// quote of its parent
+type messageResponse struct {
+	db.Message
+	State   string         `json:"state"`              // stored, delivered or read, see delivery_receipts.go
+	ReplyTo *QuotedMessage `json:"reply_to,omitempty"` // Only set on replies
+}
+
+// withQuotedParents returns messages as returned by the API, adding the quotes of their parents to
+// the replies
+func withQuotedParents(store *db.Queries, messages []db.Message) ([]messageResponse, error) {
+	response := make([]messageResponse, len(messages))
+	var parentIDs []int64
+	for i, message := range messages {
+		response[i].Message = message
+		response[i].State = messageState(message)
+		if message.ReplyToMessageID.Valid {
+			parentIDs = append(parentIDs, message.ReplyToMessageID.Int64)
+		}
+	}
+	if len(parentIDs) == 0 {
+		return response, nil
+	}
+
+	parents, err := store.ListMessagesByIDs(context.Background(), parentIDs)
+	if err != nil {
+		return nil, err
+	}
+	quotes := make(map[int64]*QuotedMessage, len(parents))
+	for _, parent := range parents {
+		quotes[parent.ID] = newQuotedMessage(parent)
+	}
+	for i, message := range messages {
+		if message.ReplyToMessageID.Valid {
+			response[i].ReplyTo = quotes[message.ReplyToMessageID.Int64]
+		}
+	}
+	return response, nil
+}
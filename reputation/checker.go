@@ -0,0 +1,48 @@
+package reputation
+
+import (
+	"context"
+	"errors"
+)
+
+// Action is what a client IP is checked for
+type Action string
+
+const (
+	ActionSignup     Action = "signup"
+	ActionFirstLogin Action = "first_login" // The first login of an account
+)
+
+// Verdict is the outcome of a reputation check
+type Verdict struct {
+	Suspicious bool
+	Reason     string // Why the IP is suspicious, shown to admins
+}
+
+// Checker rates client IPs
+type Checker interface {
+	Check(ctx context.Context, ip string, action Action) (Verdict, error)
+}
+
+// Chain runs checkers in order and returns the first suspicious verdict. Checkers that fail are
+// skipped, so an unreachable service does not block signups; their errors are returned joined.
+func Chain(checkers ...Checker) Checker {
+	return chain(checkers)
+}
+
+type chain []Checker
+
+func (c chain) Check(ctx context.Context, ip string, action Action) (Verdict, error) {
+	var errs []error
+	for _, checker := range c {
+		verdict, err := checker.Check(ctx, ip, action)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		if verdict.Suspicious {
+			return verdict, errors.Join(errs...)
+		}
+	}
+	return Verdict{}, errors.Join(errs...)
+}
@@ -0,0 +1,87 @@
+package reputation
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"sync"
+	"time"
+)
+
+// Heuristics rates IPs locally: IPs in a denylist are suspicious, and so is an IP that signs up
+// more than a number of accounts within a window
+type Heuristics struct {
+	denylist     []*net.IPNet
+	signupLimit  int // 0 disables the signup velocity check
+	signupWindow time.Duration
+
+	signups map[string][]time.Time // Recent signups per IP
+
+	mu sync.Mutex
+}
+
+// NewHeuristics creates local heuristics. denylist holds CIDRs such as "203.0.113.0/24"; an IP
+// that signs up more than signupLimit accounts within signupWindow gets suspicious.
+func NewHeuristics(denylist []string, signupLimit int, signupWindow time.Duration) (*Heuristics, error) {
+	h := &Heuristics{
+		signupLimit:  signupLimit,
+		signupWindow: signupWindow,
+		signups:      make(map[string][]time.Time),
+	}
+	for _, cidr := range denylist {
+		_, network, err := net.ParseCIDR(cidr)
+		if err != nil {
+			return nil, fmt.Errorf("invalid denylist entry %q: %w", cidr, err)
+		}
+		h.denylist = append(h.denylist, network)
+	}
+	return h, nil
+}
+
+// Check rates the IP. Every signup checked counts towards the IP's signup velocity.
+func (h *Heuristics) Check(_ context.Context, ip string, action Action) (Verdict, error) {
+	if parsed := net.ParseIP(ip); parsed != nil {
+		for _, network := range h.denylist {
+			if network.Contains(parsed) {
+				return Verdict{Suspicious: true, Reason: "ip in denylist " + network.String()}, nil
+			}
+		}
+	}
+
+	if action != ActionSignup || h.signupLimit <= 0 {
+		return Verdict{}, nil
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	recent := h.recentSignups(ip, time.Now())
+	h.signups[ip] = append(recent, time.Now())
+	if len(recent) >= h.signupLimit {
+		return Verdict{Suspicious: true, Reason: fmt.Sprintf("more than %d signups from this ip within %s", h.signupLimit, h.signupWindow)}, nil
+	}
+	return Verdict{}, nil
+}
+
+// recentSignups returns the signups of the IP within the window. The caller must hold the lock.
+func (h *Heuristics) recentSignups(ip string, now time.Time) []time.Time {
+	signups := h.signups[ip]
+	for len(signups) > 0 && now.Sub(signups[0]) > h.signupWindow {
+		signups = signups[1:]
+	}
+	return signups
+}
+
+// Sweep forgets the signups that left the window. It should be called periodically.
+func (h *Heuristics) Sweep() {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	now := time.Now()
+	for ip := range h.signups {
+		if recent := h.recentSignups(ip, now); len(recent) > 0 {
+			h.signups[ip] = recent
+		} else {
+			delete(h.signups, ip)
+		}
+	}
+}
@@ -0,0 +1,59 @@
+package reputation
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+// Service asks an external reputation service. It sends
+//
+//	GET <url>?ip=<ip>&action=<action>
+//
+// and expects a 200 response with {"suspicious": bool, "reason": "string"}.
+type Service struct {
+	url    string
+	client *http.Client
+}
+
+// NewService creates a checker for the service at serviceURL
+func NewService(serviceURL string) *Service {
+	return &Service{
+		url:    serviceURL,
+		client: &http.Client{Timeout: 2 * time.Second},
+	}
+}
+
+type serviceResponse struct {
+	Suspicious bool   `json:"suspicious"`
+	Reason     string `json:"reason"`
+}
+
+// Check asks the service about the IP
+func (s *Service) Check(ctx context.Context, ip string, action Action) (Verdict, error) {
+	params := url.Values{}
+	params.Set("ip", ip)
+	params.Set("action", string(action))
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, s.url+"?"+params.Encode(), nil)
+	if err != nil {
+		return Verdict{}, err
+	}
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return Verdict{}, fmt.Errorf("reputation service: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return Verdict{}, fmt.Errorf("reputation service returned %d", resp.StatusCode)
+	}
+
+	var response serviceResponse
+	if err := json.NewDecoder(resp.Body).Decode(&response); err != nil {
+		return Verdict{}, fmt.Errorf("reputation service: invalid response: %w", err)
+	}
+	return Verdict{Suspicious: response.Suspicious, Reason: response.Reason}, nil
+}
@@ -0,0 +1,148 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	db "websocket-simple-chat-app/db/sqlc"
+	"websocket-simple-chat-app/hub"
+	"websocket-simple-chat-app/token"
+)
+
+// Why a room changed owner
+const (
+	roomOwnerTransferred = "transferred"       // The owner (or an admin) handed the room over
+	roomOwnerLeft        = "owner_left"        // The owner left the room
+	roomOwnerDeactivated = "owner_deactivated" // The owner's account was deactivated
+)
+
+// RoomOwnerChangedEvent tells the members of a room who administers it now
+//
+//wsschema:server
+type RoomOwnerChangedEvent struct {
+	Type            string    `json:"type"` // "room_owner_changed"
+	RoomID          int64     `json:"room_id"`
+	OwnerID         int32     `json:"owner_id"`
+	PreviousOwnerID int32     `json:"previous_owner_id"`
+	Reason          string    `json:"reason"`
+	CreatedAt       time.Time `json:"created_at"`
+}
+
+// setRoomOwner makes newOwnerID the owner of the room and tells the members
+func setRoomOwner(store *db.Queries, connectionHub *hub.Hub, room db.Room, newOwnerID int32, reason string) (db.Room, error) {
+	updated, err := store.SetRoomOwner(context.Background(), db.SetRoomOwnerParams{OwnerID: newOwnerID, RoomID: room.ID})
+	if err != nil {
+		return db.Room{}, err
+	}
+
+	log.Printf("Room %d: owner %d -> %d (%s)", room.ID, room.OwnerID, newOwnerID, reason)
+	sendJSONToRoom(store, connectionHub, room.ID, 0, RoomOwnerChangedEvent{
+		Type:            "room_owner_changed",
+		RoomID:          room.ID,
+		OwnerID:         newOwnerID,
+		PreviousOwnerID: room.OwnerID,
+		Reason:          reason,
+		CreatedAt:       time.Now().UTC(),
+	})
+	return updated, nil
+}
+
+// passOnRoom promotes the longest-standing active member when the owner can no longer administer
+// the room. A room without other members keeps its owner, who can come back to it.
+func passOnRoom(store *db.Queries, connectionHub *hub.Hub, room db.Room, reason string) {
+	successorID, err := store.GetRoomSuccessor(context.Background(), db.GetRoomSuccessorParams{RoomID: room.ID, OwnerID: room.OwnerID})
+	if err != nil {
+		if err != sql.ErrNoRows {
+			log.Printf("Error finding a successor for room %d: %v", room.ID, err)
+		}
+		return
+	}
+	if _, err := setRoomOwner(store, connectionHub, room, successorID, reason); err != nil {
+		log.Printf("Error passing room %d on to user %d: %v", room.ID, successorID, err)
+	}
+}
+
+// passOnOwnedRooms passes on every room of a user whose account was deactivated
+func passOnOwnedRooms(store *db.Queries, connectionHub *hub.Hub, userID int32) {
+	rooms, err := store.ListRoomsOwnedBy(context.Background(), userID)
+	if err != nil {
+		log.Printf("Error listing the rooms owned by user %d: %v", userID, err)
+		return
+	}
+	for _, room := range rooms {
+		passOnRoom(store, connectionHub, room, roomOwnerDeactivated)
+	}
+}
+
+// transferRoomHandler hands a room over to another member. Only the owner and admins may do it.
+func transferRoomHandler(store *db.Queries, connectionHub *hub.Hub) gin.HandlerFunc {
+	type transferRoomRequest struct {
+		UserID int32 `json:"user_id" binding:"required,min=1"`
+	}
+	return func(c *gin.Context) {
+		payload := c.MustGet(authorizationPayloadKey).(*token.Payload)
+
+		room, ok := parseRoomIDParam(c, store)
+		if !ok {
+			return
+		}
+
+		var req transferRoomRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		if room.OwnerID != payload.UserID {
+			caller, err := store.GetUserByID(context.Background(), payload.UserID)
+			if err != nil {
+				log.Printf("Error fetching user %d: %v", payload.UserID, err)
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to transfer room"})
+				return
+			}
+			if caller.Role != roleAdmin {
+				c.JSON(http.StatusForbidden, gin.H{"error": "Only the room owner can transfer it"})
+				return
+			}
+		}
+		if req.UserID == room.OwnerID {
+			c.JSON(http.StatusOK, room)
+			return
+		}
+
+		// The new owner must be an active member who can administer the room
+		isMember, err := store.IsRoomMember(context.Background(), db.IsRoomMemberParams{RoomID: room.ID, UserID: req.UserID})
+		if err != nil {
+			log.Printf("Error checking membership of user %d in room %d: %v", req.UserID, room.ID, err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to transfer room"})
+			return
+		}
+		if !isMember {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "The new owner must be a member of the room"})
+			return
+		}
+		newOwner, err := store.GetUserByID(context.Background(), req.UserID)
+		if err != nil {
+			log.Printf("Error fetching user %d: %v", req.UserID, err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to transfer room"})
+			return
+		}
+		if newOwner.DeactivatedAt.Valid || newOwner.Role == roleGuest {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Deactivated accounts and guests cannot own rooms"})
+			return
+		}
+
+		updated, err := setRoomOwner(store, connectionHub, room, req.UserID, roomOwnerTransferred)
+		if err != nil {
+			log.Printf("Error transferring room %d to user %d: %v", room.ID, req.UserID, err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to transfer room"})
+			return
+		}
+		c.JSON(http.StatusOK, updated)
+	}
+}
@@ -0,0 +1,198 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"sort"
+	"sync"
+	"time"
+
+	db "websocket-simple-chat-app/db/sqlc"
+	"websocket-simple-chat-app/hub"
+)
+
+// Room typing indicators. Members send room_typing_start/room_typing_stop; the server collects them
+// and sends each room's typing state at most once per flush interval, only when it changed. Beyond
+// roomTypingMaxNames typists the state is aggregated to a count, so a busy room costs one event per
+// member per interval instead of one per typist per member.
+const (
+	roomTypingMaxNames      = 3
+	roomTypingTTL           = 6 * time.Second // A typist who sent no start within it is dropped (clients repeat the start while typing)
+	roomTypingFlushInterval = time.Second
+)
+
+// RoomTypingRequest is sent by members of a room when they start or stop typing in it
+//
+//wsschema:client
+type RoomTypingRequest struct {
+	Type   string `json:"type"` // "room_typing_start" or "room_typing_stop"
+	RoomID int64  `json:"room_id"`
+}
+
+// RoomTypist is one named typist of a RoomTypingEvent
+type RoomTypist struct {
+	UserID   int32  `json:"user_id"`
+	Username string `json:"username"`
+}
+
+// RoomTypingEvent is the typing state of a room, sent to its members whenever it changed
+//
+//wsschema:server
+type RoomTypingEvent struct {
+	Type      string       `json:"type"` // "room_typing"
+	RoomID    int64        `json:"room_id"`
+	Count     int          `json:"count"`             // Number of members typing, 0 once everyone stopped
+	Typists   []RoomTypist `json:"typists,omitempty"` // Omitted when Count is above roomTypingMaxNames
+	CreatedAt time.Time    `json:"created_at"`
+}
+
+// roomTypist is a member currently typing in a room
+type roomTypist struct {
+	username  string
+	expiresAt time.Time
+}
+
+// roomTypingTracker holds who is typing in which room on this instance
+type roomTypingTracker struct {
+	rooms map[int64]map[int32]roomTypist
+	dirty map[int64]bool // Rooms whose state changed since the last flush
+
+	mu sync.Mutex
+}
+
+func newRoomTypingTracker() *roomTypingTracker {
+	return &roomTypingTracker{
+		rooms: make(map[int64]map[int32]roomTypist),
+		dirty: make(map[int64]bool),
+	}
+}
+
+// Start records that the user is typing in the room, or extends their TTL
+func (t *roomTypingTracker) Start(roomID int64, userID int32, username string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	typists, ok := t.rooms[roomID]
+	if !ok {
+		typists = make(map[int32]roomTypist)
+		t.rooms[roomID] = typists
+	}
+	if _, typing := typists[userID]; !typing {
+		t.dirty[roomID] = true
+	}
+	typists[userID] = roomTypist{username: username, expiresAt: time.Now().Add(roomTypingTTL)}
+}
+
+// Stop records that the user stopped typing in the room
+func (t *roomTypingTracker) Stop(roomID int64, userID int32) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.remove(roomID, userID)
+}
+
+// RemoveUser stops the user's typing in every room (e.g. when their last connection closed)
+func (t *roomTypingTracker) RemoveUser(userID int32) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	for roomID := range t.rooms {
+		t.remove(roomID, userID)
+	}
+}
+
+// flush drops expired typists and returns the state of every room that changed since the last flush
+func (t *roomTypingTracker) flush() []RoomTypingEvent {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	now := time.Now()
+	for roomID, typists := range t.rooms {
+		for userID, typist := range typists {
+			if now.After(typist.expiresAt) {
+				t.remove(roomID, userID)
+			}
+		}
+	}
+
+	events := make([]RoomTypingEvent, 0, len(t.dirty))
+	for roomID := range t.dirty {
+		typists := t.rooms[roomID]
+		event := RoomTypingEvent{Type: "room_typing", RoomID: roomID, Count: len(typists), CreatedAt: now.UTC()}
+		if len(typists) <= roomTypingMaxNames {
+			for userID, typist := range typists {
+				event.Typists = append(event.Typists, RoomTypist{UserID: userID, Username: typist.username})
+			}
+			sort.Slice(event.Typists, func(i, j int) bool { return event.Typists[i].UserID < event.Typists[j].UserID })
+		}
+		events = append(events, event)
+		delete(t.dirty, roomID)
+	}
+	return events
+}
+
+// remove must be called with the lock held
+func (t *roomTypingTracker) remove(roomID int64, userID int32) {
+	typists, ok := t.rooms[roomID]
+	if !ok {
+		return
+	}
+	if _, typing := typists[userID]; !typing {
+		return
+	}
+	delete(typists, userID)
+	if len(typists) == 0 {
+		delete(t.rooms, roomID)
+	}
+	t.dirty[roomID] = true
+}
+
+// handleRoomTyping records a room typing indicator received over WebSocket
+func handleRoomTyping(store *db.Queries, tracker *roomTypingTracker, userID int32, username string, payload []byte) {
+	var msg RoomTypingRequest
+	if err := json.Unmarshal(payload, &msg); err != nil {
+		log.Printf("WS Error: Failed to unmarshal room typing indicator: %v. Payload: %s", err, string(payload))
+		return
+	}
+	if msg.RoomID <= 0 {
+		log.Printf("WS Warning: Invalid room typing indicator from %s (ID: %d): RoomID=%d", username, userID, msg.RoomID)
+		return
+	}
+
+	// Users who turned typing indicators off never show up as typing
+	if msg.Type == "room_typing_stop" || !loadUserSettings(store, userID).TypingIndicators {
+		tracker.Stop(msg.RoomID, userID)
+		return
+	}
+
+	// Only members may show up as typing
+	isMember, err := store.IsRoomMember(context.Background(), db.IsRoomMemberParams{RoomID: msg.RoomID, UserID: userID})
+	if err != nil {
+		log.Printf("WS Error: Failed to check membership of user %d in room %d: %v", userID, msg.RoomID, err)
+		return
+	}
+	if !isMember {
+		log.Printf("WS Warning: User %d sent a typing indicator to room %d without being a member", userID, msg.RoomID)
+		return
+	}
+	tracker.Start(msg.RoomID, userID, username)
+}
+
+// runRoomTypingFlusher sends the changed room typing states to the rooms' members.
+// Typing indicators are live-only: members without a connection miss them.
+func runRoomTypingFlusher(store *db.Queries, connectionHub *hub.Hub, tracker *roomTypingTracker) {
+	for range time.Tick(roomTypingFlushInterval) {
+		for _, event := range tracker.flush() {
+			memberIDs, err := store.ListRoomMemberIDs(context.Background(), event.RoomID)
+			if err != nil {
+				log.Printf("Error listing members of room %d: %v", event.RoomID, err)
+				continue
+			}
+			jsonMsg, err := json.Marshal(event)
+			if err != nil {
+				log.Printf("Error marshalling typing state of room %d: %v", event.RoomID, err)
+				continue
+			}
+			connectionHub.SendToUsers(memberIDs, jsonMsg)
+		}
+	}
+}
@@ -0,0 +1,513 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/gin-gonic/gin"
+
+	db "websocket-simple-chat-app/db/sqlc"
+	"websocket-simple-chat-app/hub"
+	"websocket-simple-chat-app/pagination"
+	"websocket-simple-chat-app/token"
+	"websocket-simple-chat-app/wordfilter"
+)
+
+// GET /rooms page sizes (room history uses the GET /messages sizes)
+const (
+	roomsDefaultLimit = 50
+	roomsMaxLimit     = 100
+)
+
+// RoomMessageRequest is sent by clients to post in a room they are a member of
+//
+//wsschema:client
+type RoomMessageRequest struct {
+	Type    string `json:"type"` // "room_message"
+	RoomID  int64  `json:"room_id"`
+	Content string `json:"content"`
+}
+
+// RoomMessageEvent is delivered to the other members of a room
+//
+//wsschema:server
+type RoomMessageEvent struct {
+	Type           string    `json:"type"` // "room_message"
+	MessageID      int64     `json:"message_id"`
+	RoomID         int64     `json:"room_id"`
+	SenderID       int32     `json:"sender_id"`
+	SenderUsername string    `json:"sender_username"`
+	Content        string    `json:"content"`
+	Preview        string    `json:"preview"`    // Short single-line text for notifications
+	CreatedAt      time.Time `json:"created_at"` // When the message was stored
+}
+
+// RoomMembershipEvent tells the members of a room that someone joined or left
+//
+//wsschema:server
+type RoomMembershipEvent struct {
+	Type      string    `json:"type"` // "room_member_joined" or "room_member_left"
+	RoomID    int64     `json:"room_id"`
+	UserID    int32     `json:"user_id"`
+	Username  string    `json:"username"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// --- Fan-out ---
+
+// sendJSONToRoom sends msg to every member of a room except excludeUserID (0 excludes nobody)
+func sendJSONToRoom(store *db.Queries, connectionHub *hub.Hub, roomID int64, excludeUserID int32, msg any) {
+	memberIDs, err := store.ListRoomMemberIDs(context.Background(), roomID)
+	if err != nil {
+		log.Printf("Error listing members of room %d: %v", roomID, err)
+		return
+	}
+
+	jsonMsg, err := json.Marshal(msg)
+	if err != nil {
+		log.Printf("Error marshalling event for room %d: %v", roomID, err)
+		return
+	}
+
+	recipients := make([]int32, 0, len(memberIDs))
+	for _, memberID := range memberIDs {
+		if memberID != excludeUserID {
+			recipients = append(recipients, memberID)
+		}
+	}
+	connectionHub.SendOrQueueToUsers(recipients, jsonMsg)
+}
+
+// errNotRoomMember is returned when someone who is not a member posts to a room
+var errNotRoomMember = errors.New("not a member of this room")
+
+// postRoomMessage stores a room message and fans it out to the other members.
+// Both the WebSocket and the REST path go through it, and so through the moderation word filter.
+func postRoomMessage(store *db.Queries, connectionHub *hub.Hub, wordFilter *wordfilter.Filter, roomID int64, senderID int32, senderUsername string, content string) (db.RoomMessage, error) {
+	// 1. Only members may post
+	isMember, err := store.IsRoomMember(context.Background(), db.IsRoomMemberParams{RoomID: roomID, UserID: senderID})
+	if err != nil {
+		return db.RoomMessage{}, err
+	}
+	if !isMember {
+		return db.RoomMessage{}, errNotRoomMember
+	}
+	if err := checkQuarantineSend(store, senderID); err != nil {
+		return db.RoomMessage{}, err
+	}
+	content, err = filterMessageContent(wordFilter, content)
+	if err != nil {
+		return db.RoomMessage{}, err
+	}
+
+	// 2. Store it
+	storedMsg, err := store.CreateRoomMessage(context.Background(), db.CreateRoomMessageParams{
+		RoomID:   roomID,
+		SenderID: senderID,
+		Content:  content,
+	})
+	if err != nil {
+		return db.RoomMessage{}, err
+	}
+
+	// 3. Fan out to the other members (queued for those who just disconnected)
+	sendJSONToRoom(store, connectionHub, roomID, senderID, RoomMessageEvent{
+		Type:           "room_message",
+		MessageID:      storedMsg.ID,
+		RoomID:         storedMsg.RoomID,
+		SenderID:       senderID,
+		SenderUsername: senderUsername,
+		Content:        storedMsg.Content,
+		Preview:        messagePreview(contentTypeText, storedMsg.Content),
+		CreatedAt:      storedMsg.CreatedAt,
+	})
+	return storedMsg, nil
+}
+
+// handleRoomMessage posts a room message received over WebSocket. Messages that are not posted
+// are answered with an error event.
+func handleRoomMessage(store *db.Queries, connectionHub *hub.Hub, wordFilter *wordfilter.Filter, client *hub.Client, username string, payload []byte) {
+	userID := client.UserID
+	var msg RoomMessageRequest
+	if err := json.Unmarshal(payload, &msg); err != nil {
+		log.Printf("WS Error: Failed to unmarshal room_message: %v. Payload: %s", err, string(payload))
+		sendWsError(client, wsErrorInvalidMessage, "room_message", "", "invalid room_message")
+		return
+	}
+	if msg.RoomID <= 0 || msg.Content == "" {
+		log.Printf("WS Warning: Invalid room_message from %s (ID: %d): RoomID=%d, Content empty=%t", username, userID, msg.RoomID, msg.Content == "")
+		sendWsError(client, wsErrorInvalidMessage, "room_message", "", "room_id and content are required")
+		return
+	}
+	if utf8.RuneCountInString(msg.Content) > maxMessageLength {
+		log.Printf("WS Warning: room_message from %s (ID: %d) exceeds %d characters", username, userID, maxMessageLength)
+		sendWsError(client, wsErrorMessageTooLong, "room_message", "", "content exceeds "+strconv.Itoa(maxMessageLength)+" characters")
+		return
+	}
+
+	if _, err := postRoomMessage(store, connectionHub, wordFilter, msg.RoomID, userID, username, msg.Content); err != nil {
+		if err == errNotRoomMember {
+			log.Printf("WS Warning: User %d posted to room %d without being a member", userID, msg.RoomID)
+		} else if err == errQuarantineLimit {
+			log.Printf("WS Warning: Quarantined user %d reached the send limit in room %d", userID, msg.RoomID)
+		} else if err == errBlockedWords {
+			log.Printf("WS Warning: Rejected room message from %d in room %d: %v", userID, msg.RoomID, err)
+		} else {
+			log.Printf("WS Error: Failed to post room message from %d in room %d: %v", userID, msg.RoomID, err)
+			return
+		}
+		sendWsError(client, wsErrorRejected, "room_message", "", err.Error())
+	}
+}
+
+// --- Room Endpoints ---
+
+// parseRoomIDParam loads the room referenced by the :room_id path parameter, writing the error response if needed
+func parseRoomIDParam(c *gin.Context, store *db.Queries) (db.Room, bool) {
+	roomID, err := strconv.ParseInt(c.Param("room_id"), 10, 64)
+	if err != nil || roomID <= 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid room ID"})
+		return db.Room{}, false
+	}
+
+	room, err := store.GetRoom(context.Background(), roomID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Room not found"})
+		} else {
+			log.Printf("Error fetching room %d: %v", roomID, err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch room"})
+		}
+		return db.Room{}, false
+	}
+	return room, true
+}
+
+// createRoomHandler creates a room with the authenticated user as its first member
+func createRoomHandler(store *db.Queries) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		payload := c.MustGet(authorizationPayloadKey).(*token.Payload)
+
+		type createRoomRequest struct {
+			Name     string `json:"name" binding:"required,max=100"`
+			IsPublic bool   `json:"is_public"` // Private by default: newcomers need an invite
+		}
+		var req createRoomRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		name := strings.TrimSpace(req.Name)
+		if name == "" {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "name must not be blank"})
+			return
+		}
+
+		room, err := store.CreateRoom(context.Background(), db.CreateRoomParams{
+			Name:      name,
+			CreatedBy: payload.UserID,
+			IsPublic:  req.IsPublic,
+		})
+		if err != nil {
+			log.Printf("Error creating room for user %d: %v", payload.UserID, err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create room"})
+			return
+		}
+		if _, err := store.AddRoomMember(context.Background(), db.AddRoomMemberParams{RoomID: room.ID, UserID: payload.UserID}); err != nil {
+			log.Printf("Error adding creator %d to room %d: %v", payload.UserID, room.ID, err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create room"})
+			return
+		}
+
+		log.Printf("User %d created room %d", payload.UserID, room.ID)
+		c.JSON(http.StatusCreated, room)
+	}
+}
+
+// listRoomsHandler returns the rooms the authenticated user is a member of
+func listRoomsHandler(store *db.Queries) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		payload := c.MustGet(authorizationPayloadKey).(*token.Payload)
+
+		page, afterID, ok := parseIDPage(c, roomsDefaultLimit, roomsMaxLimit)
+		if !ok {
+			return
+		}
+
+		rooms, err := store.ListRoomsForUser(context.Background(), db.ListRoomsForUserParams{
+			UserID:    payload.UserID,
+			AfterID:   afterID,
+			PageLimit: page.FetchLimit(),
+		})
+		if err != nil {
+			log.Printf("Error listing rooms of user %d: %v", payload.UserID, err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to list rooms"})
+			return
+		}
+
+		rooms, nextCursor := pagination.Trim(rooms, page, func(r db.Room) string { return pagination.IDKey(r.ID) })
+		c.JSON(http.StatusOK, gin.H{"rooms": rooms, "next_cursor": nextCursor})
+	}
+}
+
+// joinRoomHandler adds the authenticated user to a room. Anyone can join a public room; private
+// rooms take an invite, which joining uses up, and are closed to guests. Joining a room twice is a no-op.
+func joinRoomHandler(store *db.Queries, connectionHub *hub.Hub) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		payload := c.MustGet(authorizationPayloadKey).(*token.Payload)
+
+		room, ok := parseRoomIDParam(c, store)
+		if !ok {
+			return
+		}
+
+		if !room.IsPublic {
+			account, err := store.GetUserByID(context.Background(), payload.UserID)
+			if err != nil {
+				log.Printf("Error fetching user %d: %v", payload.UserID, err)
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to join room"})
+				return
+			}
+			if account.Role == roleGuest {
+				c.JSON(http.StatusForbidden, gin.H{"error": "Guests can only join public rooms"})
+				return
+			}
+
+			isMember, err := store.IsRoomMember(context.Background(), db.IsRoomMemberParams{RoomID: room.ID, UserID: payload.UserID})
+			if err != nil {
+				log.Printf("Error checking membership of user %d in room %d: %v", payload.UserID, room.ID, err)
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to join room"})
+				return
+			}
+			if isMember {
+				c.JSON(http.StatusOK, room)
+				return
+			}
+			invited, err := store.DeleteRoomInvite(context.Background(), db.DeleteRoomInviteParams{RoomID: room.ID, UserID: payload.UserID})
+			if err != nil {
+				log.Printf("Error using the invite of user %d to room %d: %v", payload.UserID, room.ID, err)
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to join room"})
+				return
+			}
+			if invited == 0 {
+				c.JSON(http.StatusForbidden, gin.H{"error": "This room is private: an invite is required"})
+				return
+			}
+		}
+
+		added, err := store.AddRoomMember(context.Background(), db.AddRoomMemberParams{RoomID: room.ID, UserID: payload.UserID})
+		if err != nil {
+			log.Printf("Error adding user %d to room %d: %v", payload.UserID, room.ID, err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to join room"})
+			return
+		}
+
+		if added > 0 {
+			sendJSONToRoom(store, connectionHub, room.ID, payload.UserID, RoomMembershipEvent{
+				Type:      "room_member_joined",
+				RoomID:    room.ID,
+				UserID:    payload.UserID,
+				Username:  payload.Username,
+				CreatedAt: time.Now().UTC(),
+			})
+		}
+		c.JSON(http.StatusOK, room)
+	}
+}
+
+// inviteRoomHandler lets a member of a private room invite another user, who can then join it once
+func inviteRoomHandler(store *db.Queries) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		payload := c.MustGet(authorizationPayloadKey).(*token.Payload)
+
+		room, ok := parseRoomIDParam(c, store)
+		if !ok {
+			return
+		}
+
+		type inviteRoomRequest struct {
+			UserID int32 `json:"user_id" binding:"required,min=1"`
+		}
+		var req inviteRoomRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		if room.IsPublic {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Public rooms need no invite"})
+			return
+		}
+
+		isMember, err := store.IsRoomMember(context.Background(), db.IsRoomMemberParams{RoomID: room.ID, UserID: payload.UserID})
+		if err != nil {
+			log.Printf("Error checking membership of user %d in room %d: %v", payload.UserID, room.ID, err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to invite user"})
+			return
+		}
+		if !isMember {
+			c.JSON(http.StatusForbidden, gin.H{"error": "Not a member of this room"})
+			return
+		}
+
+		invitee, err := store.GetUserByID(context.Background(), req.UserID)
+		if err != nil {
+			if err == sql.ErrNoRows {
+				c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+				return
+			}
+			log.Printf("Error fetching user %d: %v", req.UserID, err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to invite user"})
+			return
+		}
+		if invitee.DeactivatedAt.Valid || invitee.Role == roleGuest {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Deactivated accounts and guests cannot be invited"})
+			return
+		}
+
+		if _, err := store.CreateRoomInvite(context.Background(), db.CreateRoomInviteParams{
+			RoomID:    room.ID,
+			UserID:    req.UserID,
+			InvitedBy: payload.UserID,
+		}); err != nil {
+			log.Printf("Error inviting user %d to room %d: %v", req.UserID, room.ID, err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to invite user"})
+			return
+		}
+
+		log.Printf("User %d invited user %d to room %d", payload.UserID, req.UserID, room.ID)
+		c.Status(http.StatusNoContent)
+	}
+}
+
+// leaveRoomHandler removes the authenticated user from a room
+func leaveRoomHandler(store *db.Queries, connectionHub *hub.Hub) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		payload := c.MustGet(authorizationPayloadKey).(*token.Payload)
+
+		room, ok := parseRoomIDParam(c, store)
+		if !ok {
+			return
+		}
+
+		removed, err := store.RemoveRoomMember(context.Background(), db.RemoveRoomMemberParams{RoomID: room.ID, UserID: payload.UserID})
+		if err != nil {
+			log.Printf("Error removing user %d from room %d: %v", payload.UserID, room.ID, err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to leave room"})
+			return
+		}
+		if removed == 0 {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Not a member of this room"})
+			return
+		}
+
+		sendJSONToRoom(store, connectionHub, room.ID, 0, RoomMembershipEvent{
+			Type:      "room_member_left",
+			RoomID:    room.ID,
+			UserID:    payload.UserID,
+			Username:  payload.Username,
+			CreatedAt: time.Now().UTC(),
+		})
+		// An owner who leaves hands the room over to the remaining members
+		if room.OwnerID == payload.UserID {
+			passOnRoom(store, connectionHub, room, roomOwnerLeft)
+		}
+		c.Status(http.StatusNoContent)
+	}
+}
+
+// listRoomMessagesHandler returns the history of a room to its members, newest first
+func listRoomMessagesHandler(store *db.Queries) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		payload := c.MustGet(authorizationPayloadKey).(*token.Payload)
+
+		room, ok := parseRoomIDParam(c, store)
+		if !ok {
+			return
+		}
+
+		isMember, err := store.IsRoomMember(context.Background(), db.IsRoomMemberParams{RoomID: room.ID, UserID: payload.UserID})
+		if err != nil {
+			log.Printf("Error checking membership of user %d in room %d: %v", payload.UserID, room.ID, err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch messages"})
+			return
+		}
+		if !isMember {
+			c.JSON(http.StatusForbidden, gin.H{"error": "Not a member of this room"})
+			return
+		}
+
+		page, beforeID, ok := parseIDPage(c, messagesDefaultLimit, messagesMaxLimit)
+		if !ok {
+			return
+		}
+
+		messages, err := store.ListRoomMessages(context.Background(), db.ListRoomMessagesParams{
+			RoomID:    room.ID,
+			BeforeID:  beforeID,
+			PageLimit: page.FetchLimit(),
+		})
+		if err != nil {
+			log.Printf("Error fetching messages of room %d: %v", room.ID, err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch messages"})
+			return
+		}
+
+		messages, nextCursor := pagination.Trim(messages, page, func(m db.RoomMessage) string { return pagination.IDKey(m.ID) })
+		c.JSON(http.StatusOK, gin.H{"messages": messages, "next_cursor": nextCursor})
+	}
+}
+
+// createRoomMessageHandler posts a message into a room on behalf of an integration's account (an
+// API key's by default, see INTEGRATION_AUTH_METHODS). The account must be a member of the room.
+func createRoomMessageHandler(store *db.Queries, connectionHub *hub.Hub, wordFilter *wordfilter.Filter) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		account := c.MustGet(authorizationPayloadKey).(*token.Payload)
+
+		type createRoomMessageRequest struct {
+			Content string `json:"content" binding:"required"`
+		}
+		var req createRoomMessageRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		if utf8.RuneCountInString(req.Content) > maxMessageLength {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "content exceeds " + strconv.Itoa(maxMessageLength) + " characters"})
+			return
+		}
+
+		room, ok := parseRoomIDParam(c, store)
+		if !ok {
+			return
+		}
+
+		storedMsg, err := postRoomMessage(store, connectionHub, wordFilter, room.ID, account.UserID, account.Username, req.Content)
+		if err != nil {
+			if err == errNotRoomMember {
+				c.JSON(http.StatusForbidden, gin.H{"error": "The account is not a member of this room"})
+				return
+			}
+			if err == errQuarantineLimit {
+				c.JSON(http.StatusTooManyRequests, gin.H{"error": err.Error()})
+				return
+			}
+			if err == errBlockedWords {
+				c.JSON(http.StatusUnprocessableEntity, gin.H{"error": err.Error()})
+				return
+			}
+			log.Printf("Error posting to room %d as user %d: %v", room.ID, account.UserID, err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to post message"})
+			return
+		}
+
+		c.JSON(http.StatusCreated, storedMsg)
+	}
+}
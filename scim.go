@@ -0,0 +1,469 @@
+package main
+
+import (
+	"context"
+	"crypto/subtle"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	db "websocket-simple-chat-app/db/sqlc"
+	"websocket-simple-chat-app/hub"
+	"websocket-simple-chat-app/util/password"
+	"websocket-simple-chat-app/util/username"
+)
+
+// SCIM 2.0 constants (RFC 7643 / RFC 7644)
+const (
+	scimContentType      = "application/scim+json"
+	scimUserSchema       = "urn:ietf:params:scim:schemas:core:2.0:User"
+	scimListSchema       = "urn:ietf:params:scim:api:messages:2.0:ListResponse"
+	scimErrorSchema      = "urn:ietf:params:scim:api:messages:2.0:Error"
+	scimDefaultPageCount = 100
+	scimMaxPageCount     = 1000
+)
+
+// scimUserNameFilter matches the only filter supported on GET /Users: userName eq "value"
+var scimUserNameFilter = regexp.MustCompile(`(?i)^\s*userName\s+eq\s+"([^"]*)"\s*$`)
+
+// scimUser is the SCIM representation of a chat account
+type scimUser struct {
+	Schemas  []string `json:"schemas"`
+	ID       string   `json:"id"`
+	UserName string   `json:"userName"`
+	Active   bool     `json:"active"`
+	Meta     scimMeta `json:"meta"`
+}
+
+type scimMeta struct {
+	ResourceType string    `json:"resourceType"`
+	Created      time.Time `json:"created"`
+	Location     string    `json:"location"`
+}
+
+// scimUserRequest is the body of POST and PUT requests. Unknown attributes are ignored.
+type scimUserRequest struct {
+	UserName string `json:"userName" binding:"required"`
+	Password string `json:"password"` // Optional on create: a temporary password is generated when empty
+	Active   *bool  `json:"active"`   // Defaults to true
+}
+
+// scimPatchRequest is the body of PATCH requests. Only "replace" of userName and active is supported.
+type scimPatchRequest struct {
+	Operations []scimPatchOperation `json:"Operations" binding:"required"`
+}
+
+type scimPatchOperation struct {
+	Op    string `json:"op"`
+	Path  string `json:"path"`
+	Value any    `json:"value"`
+}
+
+func toSCIMUser(user db.User) scimUser {
+	id := strconv.Itoa(int(user.ID))
+	return scimUser{
+		Schemas:  []string{scimUserSchema},
+		ID:       id,
+		UserName: user.Username,
+		Active:   !user.DeactivatedAt.Valid,
+		Meta: scimMeta{
+			ResourceType: "User",
+			Created:      user.CreatedAt.UTC(),
+			Location:     "/scim/v2/Users/" + id,
+		},
+	}
+}
+
+// scimJSON writes a response with the SCIM media type
+func scimJSON(c *gin.Context, status int, body any) {
+	c.Header("Content-Type", scimContentType) // gin keeps an existing Content-Type
+	c.JSON(status, body)
+}
+
+// scimError writes a SCIM error response. scimType may be empty.
+func scimError(c *gin.Context, status int, scimType string, detail string) {
+	body := gin.H{"schemas": []string{scimErrorSchema}, "status": strconv.Itoa(status), "detail": detail}
+	if scimType != "" {
+		body["scimType"] = scimType
+	}
+	c.Header("Content-Type", scimContentType)
+	c.AbortWithStatusJSON(status, body)
+}
+
+// --- SCIM Middleware ---
+
+// scimAuthMiddleware checks the static bearer token configured for the identity provider.
+// SCIM is disabled (404) when no token is configured.
+func scimAuthMiddleware(bearerToken string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if bearerToken == "" {
+			scimError(c, http.StatusNotFound, "", "SCIM provisioning is not enabled")
+			return
+		}
+
+		fields := strings.Fields(c.GetHeader(authorizationHeaderKey))
+		if len(fields) != 2 || strings.ToLower(fields[0]) != authorizationTypeBearer ||
+			subtle.ConstantTimeCompare([]byte(fields[1]), []byte(bearerToken)) != 1 {
+			scimError(c, http.StatusUnauthorized, "", "invalid SCIM bearer token")
+			return
+		}
+
+		c.Next()
+	}
+}
+
+// --- SCIM Users ---
+
+// scimUserFromParam loads the user referenced by the :id path parameter, writing the error response if needed
+func scimUserFromParam(c *gin.Context, store *db.Queries) (db.User, bool) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 32)
+	if err != nil {
+		scimError(c, http.StatusNotFound, "", "User not found")
+		return db.User{}, false
+	}
+
+	user, err := store.GetUserByID(context.Background(), int32(id))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			scimError(c, http.StatusNotFound, "", "User not found")
+		} else {
+			log.Printf("SCIM Error: Failed to fetch user %d: %v", id, err)
+			scimError(c, http.StatusInternalServerError, "", "Failed to fetch user")
+		}
+		return db.User{}, false
+	}
+	return user, true
+}
+
+// scimCreateUserHandler provisions a new account
+func scimCreateUserHandler(store *db.Queries, usernameRules username.Rules) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var req scimUserRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			scimError(c, http.StatusBadRequest, "invalidValue", err.Error())
+			return
+		}
+
+		name := strings.TrimSpace(req.UserName)
+		if err := usernameRules.Validate(name); err != nil {
+			scimError(c, http.StatusBadRequest, "invalidValue", err.Error())
+			return
+		}
+
+		plaintext := req.Password
+		if plaintext == "" {
+			var err error
+			plaintext, err = generateTemporaryPassword()
+			if err != nil {
+				log.Printf("SCIM Error: Failed to generate password: %v", err)
+				scimError(c, http.StatusInternalServerError, "", "Failed to create user")
+				return
+			}
+		}
+		hash, err := password.Hash(plaintext)
+		if err != nil {
+			if errors.Is(err, password.ErrTooLong) {
+				scimError(c, http.StatusBadRequest, "invalidValue", "password is longer than 72 bytes")
+				return
+			}
+			log.Printf("SCIM Error: Failed to hash password: %v", err)
+			scimError(c, http.StatusInternalServerError, "", "Failed to create user")
+			return
+		}
+
+		user, err := store.CreateUser(context.Background(), db.CreateUserParams{
+			Username:     name,
+			PasswordHash: hash,
+		})
+		if err != nil {
+			if db.IsUniqueViolation(err) {
+				scimError(c, http.StatusConflict, "uniqueness", "userName already exists")
+				return
+			}
+			log.Printf("SCIM Error: Failed to create user %q: %v", req.UserName, err)
+			scimError(c, http.StatusInternalServerError, "", "Failed to create user")
+			return
+		}
+
+		if req.Active != nil && !*req.Active {
+			if user, err = store.DeactivateUser(context.Background(), db.DeactivateUserParams{ID: user.ID}); err != nil {
+				log.Printf("SCIM Error: Failed to deactivate new user %d: %v", user.ID, err)
+			}
+		}
+
+		log.Printf("SCIM: Provisioned user %s (ID: %d)", user.Username, user.ID)
+		c.Header("Location", toSCIMUser(user).Meta.Location)
+		scimJSON(c, http.StatusCreated, toSCIMUser(user))
+	}
+}
+
+// scimListUsersHandler lists accounts, supporting startIndex/count paging and the userName eq filter
+func scimListUsersHandler(store *db.Queries) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		resources := []scimUser{}
+
+		// Identity providers look users up by userName before creating them
+		if filter := c.Query("filter"); filter != "" {
+			match := scimUserNameFilter.FindStringSubmatch(filter)
+			if match == nil {
+				scimError(c, http.StatusBadRequest, "invalidFilter", "only 'userName eq \"value\"' filters are supported")
+				return
+			}
+			user, err := store.GetUserByUsername(context.Background(), match[1])
+			if err != nil && err != sql.ErrNoRows {
+				log.Printf("SCIM Error: Failed to look up user %q: %v", match[1], err)
+				scimError(c, http.StatusInternalServerError, "", "Failed to list users")
+				return
+			}
+			if err == nil {
+				resources = append(resources, toSCIMUser(user))
+			}
+			scimJSON(c, http.StatusOK, gin.H{
+				"schemas":      []string{scimListSchema},
+				"totalResults": len(resources),
+				"startIndex":   1,
+				"itemsPerPage": len(resources),
+				"Resources":    resources,
+			})
+			return
+		}
+
+		// SCIM paging is 1-based
+		startIndex, err := strconv.Atoi(c.DefaultQuery("startIndex", "1"))
+		if err != nil || startIndex < 1 {
+			startIndex = 1
+		}
+		count, err := strconv.Atoi(c.DefaultQuery("count", strconv.Itoa(scimDefaultPageCount)))
+		if err != nil || count < 0 {
+			count = scimDefaultPageCount
+		}
+		if count > scimMaxPageCount {
+			count = scimMaxPageCount
+		}
+
+		total, err := store.CountUsers(context.Background())
+		if err != nil {
+			log.Printf("SCIM Error: Failed to count users: %v", err)
+			scimError(c, http.StatusInternalServerError, "", "Failed to list users")
+			return
+		}
+
+		users, err := store.ListUsers(context.Background(), db.ListUsersParams{
+			Limit:  int32(count),
+			Offset: int32(startIndex - 1),
+		})
+		if err != nil {
+			log.Printf("SCIM Error: Failed to list users: %v", err)
+			scimError(c, http.StatusInternalServerError, "", "Failed to list users")
+			return
+		}
+		for _, user := range users {
+			resources = append(resources, toSCIMUser(user))
+		}
+
+		scimJSON(c, http.StatusOK, gin.H{
+			"schemas":      []string{scimListSchema},
+			"totalResults": total,
+			"startIndex":   startIndex,
+			"itemsPerPage": len(resources),
+			"Resources":    resources,
+		})
+	}
+}
+
+// scimGetUserHandler returns a single account
+func scimGetUserHandler(store *db.Queries) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		user, ok := scimUserFromParam(c, store)
+		if !ok {
+			return
+		}
+		scimJSON(c, http.StatusOK, toSCIMUser(user))
+	}
+}
+
+// scimReplaceUserHandler replaces userName and active (PUT)
+func scimReplaceUserHandler(store *db.Queries, connectionHub *hub.Hub, usernameRules username.Rules) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		user, ok := scimUserFromParam(c, store)
+		if !ok {
+			return
+		}
+
+		var req scimUserRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			scimError(c, http.StatusBadRequest, "invalidValue", err.Error())
+			return
+		}
+
+		active := true
+		if req.Active != nil {
+			active = *req.Active
+		}
+
+		user, status, err := applySCIMChanges(store, connectionHub, usernameRules, user, &req.UserName, &active)
+		if err != nil {
+			scimError(c, status, scimErrorType(status), err.Error())
+			return
+		}
+		scimJSON(c, http.StatusOK, toSCIMUser(user))
+	}
+}
+
+// scimPatchUserHandler applies "replace" operations on userName and active (PATCH)
+func scimPatchUserHandler(store *db.Queries, connectionHub *hub.Hub, usernameRules username.Rules) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		user, ok := scimUserFromParam(c, store)
+		if !ok {
+			return
+		}
+
+		var req scimPatchRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			scimError(c, http.StatusBadRequest, "invalidSyntax", err.Error())
+			return
+		}
+
+		var userName *string
+		var active *bool
+		for _, op := range req.Operations {
+			if !strings.EqualFold(op.Op, "replace") {
+				scimError(c, http.StatusBadRequest, "invalidValue", fmt.Sprintf("unsupported operation %q", op.Op))
+				return
+			}
+
+			// Without a path, the value is an object of attributes to replace
+			values := map[string]any{}
+			if op.Path == "" {
+				object, ok := op.Value.(map[string]any)
+				if !ok {
+					scimError(c, http.StatusBadRequest, "invalidValue", "value must be an object when path is omitted")
+					return
+				}
+				values = object
+			} else {
+				values[op.Path] = op.Value
+			}
+
+			for path, value := range values {
+				switch strings.ToLower(path) {
+				case "username":
+					v, ok := value.(string)
+					if !ok {
+						scimError(c, http.StatusBadRequest, "invalidValue", "userName must be a string")
+						return
+					}
+					userName = &v
+				case "active":
+					v, ok := value.(bool)
+					if !ok {
+						// Some identity providers send booleans as strings
+						parsed, err := strconv.ParseBool(fmt.Sprint(value))
+						if err != nil {
+							scimError(c, http.StatusBadRequest, "invalidValue", "active must be a boolean")
+							return
+						}
+						v = parsed
+					}
+					active = &v
+				default:
+					scimError(c, http.StatusBadRequest, "invalidPath", fmt.Sprintf("unsupported path %q", path))
+					return
+				}
+			}
+		}
+
+		user, status, err := applySCIMChanges(store, connectionHub, usernameRules, user, userName, active)
+		if err != nil {
+			scimError(c, status, scimErrorType(status), err.Error())
+			return
+		}
+		scimJSON(c, http.StatusOK, toSCIMUser(user))
+	}
+}
+
+// scimDeleteUserHandler deactivates an account. Messages are kept, so the account is never removed.
+func scimDeleteUserHandler(store *db.Queries, connectionHub *hub.Hub) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		user, ok := scimUserFromParam(c, store)
+		if !ok {
+			return
+		}
+
+		inactive := false
+		if _, status, err := applySCIMChanges(store, connectionHub, username.Rules{}, user, nil, &inactive); err != nil {
+			scimError(c, status, "", err.Error())
+			return
+		}
+		c.Status(http.StatusNoContent)
+	}
+}
+
+// applySCIMChanges renames and (de)activates a user. A nil argument leaves the attribute unchanged.
+// It returns the updated user, or the HTTP status and error to report.
+func applySCIMChanges(store *db.Queries, connectionHub *hub.Hub, usernameRules username.Rules, user db.User, userName *string, active *bool) (db.User, int, error) {
+	var err error
+
+	// 1. Rename
+	if userName != nil {
+		name := strings.TrimSpace(*userName)
+		if name == "" {
+			return user, http.StatusBadRequest, fmt.Errorf("userName must not be empty")
+		}
+		if name != user.Username {
+			if err := usernameRules.Validate(name); err != nil {
+				return user, http.StatusBadRequest, err
+			}
+			user, err = store.UpdateUsername(context.Background(), db.UpdateUsernameParams{ID: user.ID, Username: name})
+			if err != nil {
+				if db.IsUniqueViolation(err) {
+					return user, http.StatusConflict, fmt.Errorf("userName already exists")
+				}
+				log.Printf("SCIM Error: Failed to rename user %d: %v", user.ID, err)
+				return user, http.StatusInternalServerError, fmt.Errorf("failed to update user")
+			}
+			log.Printf("SCIM: Renamed user %d to %s", user.ID, user.Username)
+		}
+	}
+
+	// 2. Activate / deactivate
+	if active != nil && *active == user.DeactivatedAt.Valid {
+		if *active {
+			user, err = store.ReactivateUser(context.Background(), user.ID)
+		} else {
+			// Deactivated by the identity provider: no deactivated_by, the user cannot reactivate themselves
+			user, err = deactivateAccount(store, connectionHub, user.ID, sql.NullInt32{})
+		}
+		if err != nil {
+			log.Printf("SCIM Error: Failed to change active state of user %d: %v", user.ID, err)
+			return user, http.StatusInternalServerError, fmt.Errorf("failed to update user")
+		}
+
+		if *active {
+			log.Printf("SCIM: Reactivated user %s (ID: %d)", user.Username, user.ID)
+		} else {
+			log.Printf("SCIM: Deactivated user %s (ID: %d)", user.Username, user.ID)
+		}
+	}
+
+	return user, http.StatusOK, nil
+}
+
+// scimErrorType maps a status returned by applySCIMChanges to its scimType
+func scimErrorType(status int) string {
+	switch status {
+	case http.StatusConflict:
+		return "uniqueness"
+	case http.StatusBadRequest:
+		return "invalidValue"
+	}
+	return ""
+}
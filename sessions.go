@@ -0,0 +1,138 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"time"
+
+	"websocket-simple-chat-app/config"
+	db "websocket-simple-chat-app/db/sqlc"
+	"websocket-simple-chat-app/hub"
+	"websocket-simple-chat-app/token"
+)
+
+// sessionConfig holds the token lifetime settings
+type sessionConfig struct {
+	AccessTokenDuration  time.Duration // From config.Config
+	RefreshTokenDuration time.Duration // From config.Config
+	SlidingSessions      bool          // From config.Config: renew the token of active WebSocket sessions
+	AuthCookieName       string        // Cookie set at login when a route group accepts the cookie method, else empty
+}
+
+// loadSessionConfig takes the session settings from the server config
+func loadSessionConfig(cfg config.Config) sessionConfig {
+	sessions := sessionConfig{
+		AccessTokenDuration:  cfg.AccessTokenDuration,
+		RefreshTokenDuration: cfg.RefreshTokenDuration,
+		SlidingSessions:      cfg.SlidingSessions,
+	}
+	if cookieEnabled(cfg) {
+		sessions.AuthCookieName = cfg.AuthCookieName
+	}
+	return sessions
+}
+
+// shouldRenew reports whether the token of an active session is past the middle of its lifetime
+func (config sessionConfig) shouldRenew(payload *token.Payload) bool {
+	return config.SlidingSessions && time.Until(payload.ExpiredAt) < config.AccessTokenDuration/2
+}
+
+// TokenRenewedMessage carries a fresh access token to a client whose session was extended
+//
+//wsschema:server
+type TokenRenewedMessage struct {
+	Type      string         `json:"type"` // "token_renewed"
+	Token     string         `json:"token"`
+	Payload   *token.Payload `json:"payload"`
+	CreatedAt time.Time      `json:"created_at"`
+}
+
+// renewSessionToken issues a new access token for the session's user and sends it on the connection.
+// It returns the payload of the new token.
+func renewSessionToken(tokenMaker token.Maker, client *hub.Client, current *token.Payload, duration time.Duration) (*token.Payload, error) {
+	tokenStr, payload, err := tokenMaker.CreateToken(current.UserID, current.Username, duration)
+	if err != nil {
+		return nil, err
+	}
+
+	jsonMsg, err := json.Marshal(TokenRenewedMessage{
+		Type:      "token_renewed",
+		Token:     tokenStr,
+		Payload:   payload,
+		CreatedAt: time.Now().UTC(),
+	})
+	if err != nil {
+		return nil, err
+	}
+	if !client.Send(jsonMsg) {
+		return nil, errors.New("connection closed")
+	}
+	return payload, nil
+}
+
+// ReauthMessage is sent by clients to extend their session with a fresh token before the current one expires
+//
+//wsschema:client
+type ReauthMessage struct {
+	Type  string `json:"type"` // "reauth"
+	Token string `json:"token"`
+}
+
+// ReauthResultMessage answers a reauth request
+//
+//wsschema:server
+type ReauthResultMessage struct {
+	Type      string    `json:"type"`            // "reauth_ok" or "reauth_failed"
+	ExpiredAt time.Time `json:"expired_at"`      // When the session now expires
+	Error     string    `json:"error,omitempty"` // Why the token was rejected
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// startSessionTimer closes the connection with wsCloseTokenExpired once expiresAt passes.
+// Reset the returned timer whenever the session is extended.
+func startSessionTimer(client *hub.Client, expiresAt time.Time) *time.Timer {
+	return time.AfterFunc(time.Until(expiresAt), func() {
+		log.Printf("WS: Session of user %d expired, closing client %p", client.UserID, client)
+		client.Close(wsCloseTokenExpired, "token expired")
+	})
+}
+
+// handleReauth validates the token of a reauth request and answers on the connection.
+// It returns the payload of the new token, or nil if the session was not extended.
+func handleReauth(store *db.Queries, tokenMaker token.Maker, client *hub.Client, current *token.Payload, message []byte) *token.Payload {
+	var msg ReauthMessage
+	if err := json.Unmarshal(message, &msg); err != nil {
+		log.Printf("WS Error: Failed to unmarshal reauth: %v", err)
+		return nil
+	}
+
+	result := ReauthResultMessage{Type: "reauth_failed", ExpiredAt: current.ExpiredAt}
+	payload, err := tokenMaker.VerifyToken(msg.Token)
+	switch {
+	case err != nil:
+		result.Error = err.Error()
+	case payload.UserID != current.UserID:
+		result.Error = "token belongs to another user"
+	case checkTokenRevoked(store, payload) != nil:
+		result.Error = errTokenRevoked.Error()
+	default:
+		result.Type = "reauth_ok"
+		result.ExpiredAt = payload.ExpiredAt
+	}
+	if result.Type == "reauth_failed" {
+		log.Printf("WS Warning: Rejected reauth of user %d: %s", current.UserID, result.Error)
+		payload = nil
+	}
+
+	result.CreatedAt = time.Now().UTC()
+	jsonMsg, err := json.Marshal(result)
+	if err != nil {
+		log.Printf("WS Error: Failed to marshal reauth result: %v", err)
+		return payload
+	}
+	if !client.Send(jsonMsg) {
+		log.Printf("WS Error: Failed to send reauth result to user %d", current.UserID)
+	}
+	return payload
+}
@@ -0,0 +1,290 @@
+package main
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	db "websocket-simple-chat-app/db/sqlc"
+	"websocket-simple-chat-app/token"
+)
+
+// Share links give read-only access to a message or a range of messages of a conversation to
+// anyone who has the link, without an account. Like API keys, only the SHA-256 of the token is
+// stored and the token is returned once. A link shows what its creator can see: messages deleted
+// for everyone or cleared by the creator disappear from it, and encrypted messages are left out.
+const (
+	shareTokenPrefix     = "share_"
+	shareTokenRandomSize = 32  // Bytes of randomness, hex encoded in the token
+	shareLinkMaxMessages = 100 // Messages a link can cover
+	shareLinkListLimit   = 100
+)
+
+// shareLinkDurations maps the accepted link lifetimes to their length
+var shareLinkDurations = map[string]time.Duration{
+	"1h":  time.Hour,
+	"1d":  24 * time.Hour,
+	"7d":  7 * 24 * time.Hour,
+	"30d": 30 * 24 * time.Hour,
+}
+
+// shareLinkResponse is the API representation of a share link (its token is never returned again)
+type shareLinkResponse struct {
+	ID             int64     `json:"id"`
+	PartnerID      int32     `json:"partner_id"`
+	FirstMessageID int64     `json:"first_message_id"`
+	LastMessageID  int64     `json:"last_message_id"`
+	CreatedAt      time.Time `json:"created_at"`
+	ExpiresAt      time.Time `json:"expires_at"`
+}
+
+func newShareLinkResponse(link db.ShareLink) shareLinkResponse {
+	return shareLinkResponse{
+		ID:             link.ID,
+		PartnerID:      link.PartnerID,
+		FirstMessageID: link.FirstMessageID,
+		LastMessageID:  link.LastMessageID,
+		CreatedAt:      link.CreatedAt,
+		ExpiresAt:      link.ExpiresAt,
+	}
+}
+
+// generateShareToken returns a new random share link token
+func generateShareToken() (string, error) {
+	random := make([]byte, shareTokenRandomSize)
+	if _, err := rand.Read(random); err != nil {
+		return "", err
+	}
+	return shareTokenPrefix + hex.EncodeToString(random), nil
+}
+
+// hashShareToken returns the stored form of a share link token
+func hashShareToken(shareToken string) string {
+	sum := sha256.Sum256([]byte(shareToken))
+	return hex.EncodeToString(sum[:])
+}
+
+// getSharedMessage fetches a message the user can share. It writes the error response itself and
+// returns false on failure.
+func getSharedMessage(c *gin.Context, store *db.Queries, userID int32, messageID int64) (db.Message, bool) {
+	message, err := store.GetMessage(context.Background(), messageID)
+	if err != nil && err != sql.ErrNoRows {
+		log.Printf("Error fetching message %d: %v", messageID, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create share link"})
+		return db.Message{}, false
+	}
+	if err != nil || message.DeletedAt.Valid || (message.SenderID != userID && message.ReceiverID != userID) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Message not found"})
+		return db.Message{}, false
+	}
+	if message.ContentType == contentTypeEncrypted {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Encrypted messages cannot be shared"})
+		return db.Message{}, false
+	}
+	return message, true
+}
+
+// createShareLinkHandler creates a link to one message, or to the messages from message_id to
+// up_to_message_id of the same conversation. The token is only returned in this response.
+func createShareLinkHandler(store *db.Queries) gin.HandlerFunc {
+	type createShareLinkRequest struct {
+		MessageID     int64  `json:"message_id" binding:"required,min=1"`
+		UpToMessageID int64  `json:"up_to_message_id" binding:"omitempty,min=1"` // Last message of an excerpt
+		ExpiresIn     string `json:"expires_in" binding:"required"`              // "1h", "1d", "7d" or "30d"
+	}
+	return func(c *gin.Context) {
+		payload := c.MustGet(authorizationPayloadKey).(*token.Payload)
+
+		var req createShareLinkRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		lifetime, ok := shareLinkDurations[req.ExpiresIn]
+		if !ok {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid 'expires_in', must be one of: 1h, 1d, 7d, 30d"})
+			return
+		}
+		if req.UpToMessageID == 0 {
+			req.UpToMessageID = req.MessageID
+		}
+		if req.UpToMessageID < req.MessageID {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "'up_to_message_id' must not be before 'message_id'"})
+			return
+		}
+
+		first, ok := getSharedMessage(c, store, payload.UserID, req.MessageID)
+		if !ok {
+			return
+		}
+		partnerID := first.ReceiverID
+		if first.SenderID != payload.UserID {
+			partnerID = first.SenderID
+		}
+		if req.UpToMessageID != req.MessageID {
+			last, ok := getSharedMessage(c, store, payload.UserID, req.UpToMessageID)
+			if !ok {
+				return
+			}
+			if last.SenderID != partnerID && last.ReceiverID != partnerID {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "Both messages must be in the same conversation"})
+				return
+			}
+		}
+
+		// Check the size of the excerpt, and that the creator can still see something of it
+		messages, err := store.ListSharedMessages(context.Background(), db.ListSharedMessagesParams{
+			UserID:         payload.UserID,
+			PartnerID:      partnerID,
+			FirstMessageID: req.MessageID,
+			LastMessageID:  req.UpToMessageID,
+			PageLimit:      shareLinkMaxMessages + 1,
+		})
+		if err != nil {
+			log.Printf("Error listing messages %d-%d for user %d: %v", req.MessageID, req.UpToMessageID, payload.UserID, err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create share link"})
+			return
+		}
+		if len(messages) == 0 {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Message not found"})
+			return
+		}
+		if len(messages) > shareLinkMaxMessages {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "A share link can cover at most " + strconv.Itoa(shareLinkMaxMessages) + " messages"})
+			return
+		}
+
+		shareToken, err := generateShareToken()
+		if err != nil {
+			log.Printf("Error generating share link token: %v", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create share link"})
+			return
+		}
+
+		link, err := store.CreateShareLink(context.Background(), db.CreateShareLinkParams{
+			UserID:         payload.UserID,
+			PartnerID:      partnerID,
+			FirstMessageID: req.MessageID,
+			LastMessageID:  req.UpToMessageID,
+			TokenHash:      hashShareToken(shareToken),
+			ExpiresAt:      time.Now().UTC().Add(lifetime),
+		})
+		if err != nil {
+			log.Printf("Error storing share link for user %d: %v", payload.UserID, err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create share link"})
+			return
+		}
+
+		log.Printf("Share link %d created by user %d for messages %d-%d", link.ID, payload.UserID, link.FirstMessageID, link.LastMessageID)
+		c.JSON(http.StatusCreated, gin.H{
+			"share_link":    newShareLinkResponse(link),
+			"message_count": len(messages),
+			"token":         shareToken,
+			"path":          "/shared/" + shareToken,
+		})
+	}
+}
+
+// listShareLinksHandler lists the authenticated user's links that are neither expired nor revoked
+func listShareLinksHandler(store *db.Queries) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		payload := c.MustGet(authorizationPayloadKey).(*token.Payload)
+
+		links, err := store.ListActiveShareLinks(context.Background(), db.ListActiveShareLinksParams{
+			UserID:    payload.UserID,
+			PageLimit: shareLinkListLimit,
+		})
+		if err != nil {
+			log.Printf("Error listing share links of user %d: %v", payload.UserID, err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to list share links"})
+			return
+		}
+
+		response := make([]shareLinkResponse, len(links))
+		for i, link := range links {
+			response[i] = newShareLinkResponse(link)
+		}
+		c.JSON(http.StatusOK, gin.H{"share_links": response})
+	}
+}
+
+// revokeShareLinkHandler revokes one of the authenticated user's links. It stops working at once.
+func revokeShareLinkHandler(store *db.Queries) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		payload := c.MustGet(authorizationPayloadKey).(*token.Payload)
+
+		linkID, err := strconv.ParseInt(c.Param("link_id"), 10, 64)
+		if err != nil || linkID <= 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid share link ID"})
+			return
+		}
+
+		revoked, err := store.RevokeShareLink(context.Background(), db.RevokeShareLinkParams{ID: linkID, UserID: payload.UserID})
+		if err != nil {
+			log.Printf("Error revoking share link %d: %v", linkID, err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to revoke share link"})
+			return
+		}
+		if revoked == 0 {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Share link not found or already revoked"})
+			return
+		}
+
+		log.Printf("Share link %d revoked by user %d", linkID, payload.UserID)
+		c.Status(http.StatusNoContent)
+	}
+}
+
+// getSharedMessagesHandler serves the messages of a share link to anyone with its token. Unknown,
+// expired and revoked links, and links of deactivated accounts, all look the same: 404.
+func getSharedMessagesHandler(store *db.Queries) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		// Shared excerpts must not end up in shared caches or search engines
+		c.Header("Cache-Control", "no-store")
+		c.Header("X-Robots-Tag", "noindex")
+
+		link, err := store.GetActiveShareLinkByHash(context.Background(), hashShareToken(c.Param("token")))
+		if err != nil {
+			if err != sql.ErrNoRows {
+				log.Printf("Error looking up share link: %v", err)
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load shared messages"})
+				return
+			}
+			c.JSON(http.StatusNotFound, gin.H{"error": "Share link not found or expired"})
+			return
+		}
+
+		creator, err := store.GetUserByID(context.Background(), link.UserID)
+		if err != nil || creator.DeactivatedAt.Valid {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Share link not found or expired"})
+			return
+		}
+
+		messages, err := store.ListSharedMessages(context.Background(), db.ListSharedMessagesParams{
+			UserID:         link.UserID,
+			PartnerID:      link.PartnerID,
+			FirstMessageID: link.FirstMessageID,
+			LastMessageID:  link.LastMessageID,
+			PageLimit:      shareLinkMaxMessages,
+		})
+		if err != nil {
+			log.Printf("Error listing messages of share link %d: %v", link.ID, err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load shared messages"})
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{
+			"shared_by":  creator.Username,
+			"expires_at": link.ExpiresAt,
+			"messages":   messages,
+		})
+	}
+}
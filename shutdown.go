@@ -0,0 +1,115 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"log"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"sync/atomic"
+	"syscall"
+	"time"
+
+	"google.golang.org/grpc"
+
+	db "websocket-simple-chat-app/db/sqlc"
+	"websocket-simple-chat-app/hub"
+	"websocket-simple-chat-app/presence"
+)
+
+// Graceful shutdown limits
+const (
+	shutdownTimeout    = 15 * time.Second       // For in-flight HTTP requests and WebSocket draining together
+	drainPollInterval  = 100 * time.Millisecond // How often the remaining WebSocket connections are counted
+	shutdownCloseFrame = "server shutting down"
+)
+
+// serveUntilSignal serves HTTP, and gRPC if grpcServer is set, until SIGINT or SIGTERM, then shuts
+// down gracefully:
+//  1. Fail readiness probes (shuttingDown), stop accepting connections and streams and wait for
+//     in-flight HTTP requests
+//  2. Hand the replay state of the users off to the other instances, if any, and send a close
+//     frame to every WebSocket connection and ChatStream, after its pending messages
+//  3. Wait for the connections to unregister (which marks their users offline), up to the deadline,
+//     then end the remaining streams
+//  4. Mark the users whose connections did not finish in time offline, unless they are still
+//     connected to another instance
+func serveUntilSignal(server *http.Server, grpcServer *grpc.Server, grpcListener net.Listener, connectionHub *hub.Hub, store *db.Queries, presenceTracker presence.Tracker, shuttingDown *atomic.Bool) {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	serveErr := make(chan error, 2)
+	go func() {
+		serveErr <- server.ListenAndServe()
+	}()
+	if grpcServer != nil {
+		go func() {
+			serveErr <- grpcServer.Serve(grpcListener)
+		}()
+	}
+
+	select {
+	case err := <-serveErr:
+		log.Fatalf("server failed: %v", err)
+	case <-ctx.Done():
+	}
+	stop() // A second signal kills the process
+	shuttingDown.Store(true)
+	log.Println("Shutdown: Signal received, draining connections")
+
+	deadline, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancel()
+
+	// Streams already open are ended by the close frames below
+	if grpcServer != nil {
+		go grpcServer.GracefulStop()
+	}
+
+	// Hijacked (WebSocket) connections are not tracked by Shutdown, only the regular requests
+	if err := server.Shutdown(deadline); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		log.Printf("Shutdown: HTTP server did not stop cleanly: %v", err)
+	}
+
+	connectedUserIDs := connectionHub.CloseAll(wsCloseServerShutdown, shutdownCloseFrame)
+	log.Printf("Shutdown: Sent close frames to %d users", len(connectedUserIDs))
+	waitForDrain(deadline, connectionHub)
+	if grpcServer != nil {
+		grpcServer.Stop()
+	}
+
+	// Idempotent for the users already handled by their connection's disconnect
+	var offlineUserIDs []int32
+	for _, userID := range connectedUserIDs {
+		last, err := presenceTracker.Disconnect(context.Background(), userID)
+		if err != nil || last {
+			offlineUserIDs = append(offlineUserIDs, userID)
+		}
+	}
+	if len(offlineUserIDs) > 0 {
+		if err := store.SetUsersOffline(context.Background(), offlineUserIDs); err != nil {
+			log.Printf("Shutdown: Failed to set %d users offline: %v", len(offlineUserIDs), err)
+		}
+	}
+	log.Println("Shutdown: Done")
+}
+
+// waitForDrain returns once the hub has no connections left or the context is done
+func waitForDrain(ctx context.Context, connectionHub *hub.Hub) {
+	ticker := time.NewTicker(drainPollInterval)
+	defer ticker.Stop()
+
+	for {
+		remaining := connectionHub.ConnectionCount()
+		if remaining == 0 {
+			return
+		}
+		select {
+		case <-ctx.Done():
+			log.Printf("Shutdown: %d WebSocket connections still open at the deadline", remaining)
+			return
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,361 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+	"unicode/utf8"
+
+	"github.com/gin-gonic/gin"
+
+	db "websocket-simple-chat-app/db/sqlc"
+	"websocket-simple-chat-app/hub"
+	"websocket-simple-chat-app/pagination"
+	"websocket-simple-chat-app/token"
+)
+
+// Support inbox roles. Messages sent to a user with the support role are not delivered to that
+// account: they open a ticket that is fanned out to every agent until one of them claims it.
+const (
+	roleSupport = "support"
+	roleAgent   = "agent"
+)
+
+// Support ticket statuses
+const (
+	ticketStatusOpen    = "open"
+	ticketStatusClaimed = "claimed"
+	ticketStatusClosed  = "closed"
+)
+
+// GET /support/tickets page sizes
+const (
+	supportTicketsDefaultLimit = 50
+	supportTicketsMaxLimit     = 100
+)
+
+// SupportReplyMessage is sent by an agent to answer a ticket
+//
+//wsschema:client
+type SupportReplyMessage struct {
+	Type     string `json:"type"` // "support_reply"
+	TicketID int64  `json:"ticket_id"`
+	Content  string `json:"content"`
+}
+
+// SupportMessageEvent is sent to agents when a customer writes to a support identity
+//
+//wsschema:server
+type SupportMessageEvent struct {
+	Type             string    `json:"type"` // "support_message"
+	TicketID         int64     `json:"ticket_id"`
+	TicketStatus     string    `json:"ticket_status"`
+	CustomerID       int32     `json:"customer_id"`
+	CustomerUsername string    `json:"customer_username"`
+	MessageID        int64     `json:"message_id"`
+	Content          string    `json:"content"`
+	CreatedAt        time.Time `json:"created_at"`
+}
+
+// SupportTicketUpdatedEvent is sent to all agents when a ticket is claimed, assigned or closed
+//
+//wsschema:server
+type SupportTicketUpdatedEvent struct {
+	Type      string    `json:"type"` // "support_ticket_updated"
+	TicketID  int64     `json:"ticket_id"`
+	Status    string    `json:"status"`
+	AgentID   int32     `json:"agent_id,omitempty"` // 0 while the ticket is unclaimed
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// --- Routing ---
+
+// routeSupportMessage attaches a customer message to their ticket and delivers it to the assigned
+// agent, or to all agents while the ticket is unclaimed
+func routeSupportMessage(store *db.Queries, connectionHub *hub.Hub, storedMsg db.Message, customerUsername string) {
+	ticket, err := store.OpenSupportTicket(context.Background(), db.OpenSupportTicketParams{
+		SupportUserID: storedMsg.ReceiverID,
+		CustomerID:    storedMsg.SenderID,
+	})
+	if err != nil {
+		log.Printf("WS Error: Failed to open support ticket for user %d: %v", storedMsg.SenderID, err)
+		return
+	}
+
+	event := SupportMessageEvent{
+		Type:             "support_message",
+		TicketID:         ticket.ID,
+		TicketStatus:     ticket.Status,
+		CustomerID:       storedMsg.SenderID,
+		CustomerUsername: customerUsername,
+		MessageID:        storedMsg.ID,
+		Content:          storedMsg.Content,
+		CreatedAt:        storedMsg.CreatedAt,
+	}
+
+	if ticket.AgentID.Valid {
+		sendJSONToUser(connectionHub, ticket.AgentID.Int32, event)
+		return
+	}
+	sendJSONToAgents(store, connectionHub, event)
+}
+
+// handleSupportReply stores an agent's answer and delivers it to the customer as coming from the
+// support identity. Replies that are not accepted are answered with an error event.
+func handleSupportReply(store *db.Queries, connectionHub *hub.Hub, client *hub.Client, payload []byte) {
+	agentID := client.UserID
+	var msg SupportReplyMessage
+	if err := json.Unmarshal(payload, &msg); err != nil {
+		log.Printf("WS Error: Failed to unmarshal support_reply: %v. Payload: %s", err, string(payload))
+		sendWsError(client, wsErrorInvalidMessage, "support_reply", "", "invalid support_reply")
+		return
+	}
+	if msg.TicketID <= 0 || msg.Content == "" {
+		log.Printf("WS Warning: Invalid support_reply from agent %d: TicketID=%d, Content empty=%t", agentID, msg.TicketID, msg.Content == "")
+		sendWsError(client, wsErrorInvalidMessage, "support_reply", "", "ticket_id and content are required")
+		return
+	}
+
+	if utf8.RuneCountInString(msg.Content) > maxMessageLength {
+		log.Printf("WS Warning: support_reply from agent %d exceeds %d characters", agentID, maxMessageLength)
+		sendWsError(client, wsErrorMessageTooLong, "support_reply", "", "content exceeds "+strconv.Itoa(maxMessageLength)+" characters")
+		return
+	}
+
+	// 1. Only the agent the ticket is assigned to may answer
+	ticket, err := store.GetSupportTicket(context.Background(), msg.TicketID)
+	if err != nil {
+		log.Printf("WS Warning: support_reply from agent %d for unknown ticket %d: %v", agentID, msg.TicketID, err)
+		sendWsError(client, wsErrorRejected, "support_reply", "", "unknown ticket")
+		return
+	}
+	if ticket.Status != ticketStatusClaimed || ticket.AgentID.Int32 != agentID {
+		log.Printf("WS Warning: Agent %d replied to ticket %d which is not assigned to them", agentID, ticket.ID)
+		sendWsError(client, wsErrorRejected, "support_reply", "", "ticket is not assigned to you")
+		return
+	}
+
+	supportUser, err := store.GetUserByID(context.Background(), ticket.SupportUserID)
+	if err != nil {
+		log.Printf("WS Error: Failed to fetch support identity %d: %v", ticket.SupportUserID, err)
+		return
+	}
+
+	// 2. Store it in the customer's conversation with the support identity
+	storedMsg, err := store.CreateMessage(context.Background(), db.CreateMessageParams{
+		SenderID:    ticket.SupportUserID,
+		ReceiverID:  ticket.CustomerID,
+		Content:     msg.Content,
+		ContentType: contentTypeText,
+	})
+	if err != nil {
+		log.Printf("WS Error: Failed to store support reply for ticket %d: %v", ticket.ID, err)
+		return
+	}
+
+	// 3. Deliver it like any other private message
+	sendJSONToUser(connectionHub, ticket.CustomerID, OutgoingWsMessage{
+		Type:           "incoming_message",
+		SenderID:       supportUser.ID,
+		SenderUsername: supportUser.Username,
+		Content:        storedMsg.Content,
+		CreatedAt:      storedMsg.CreatedAt,
+	})
+	log.Printf("Agent %d replied to support ticket %d", agentID, ticket.ID)
+}
+
+// sendJSONToAgents sends msg to every active agent (queued for agents who just disconnected)
+func sendJSONToAgents(store *db.Queries, connectionHub *hub.Hub, msg any) {
+	agentIDs, err := store.ListActiveUserIDsByRole(context.Background(), roleAgent)
+	if err != nil {
+		log.Printf("Error listing support agents: %v", err)
+		return
+	}
+	for _, agentID := range agentIDs {
+		sendJSONToUser(connectionHub, agentID, msg)
+	}
+}
+
+// notifyTicketUpdated tells all agents about a ticket's new status so they can update their queues
+func notifyTicketUpdated(store *db.Queries, connectionHub *hub.Hub, ticket db.SupportTicket) {
+	sendJSONToAgents(store, connectionHub, SupportTicketUpdatedEvent{
+		Type:      "support_ticket_updated",
+		TicketID:  ticket.ID,
+		Status:    ticket.Status,
+		AgentID:   ticket.AgentID.Int32,
+		CreatedAt: time.Now().UTC(),
+	})
+}
+
+// --- Agent Endpoints ---
+
+// parseTicketIDParam loads the ticket referenced by the :ticket_id path parameter, writing the error response if needed
+func parseTicketIDParam(c *gin.Context, store *db.Queries) (db.SupportTicket, bool) {
+	ticketID, err := strconv.ParseInt(c.Param("ticket_id"), 10, 64)
+	if err != nil || ticketID <= 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ticket ID"})
+		return db.SupportTicket{}, false
+	}
+
+	ticket, err := store.GetSupportTicket(context.Background(), ticketID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Ticket not found"})
+		} else {
+			log.Printf("Error fetching support ticket %d: %v", ticketID, err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch ticket"})
+		}
+		return db.SupportTicket{}, false
+	}
+	return ticket, true
+}
+
+// listSupportTicketsHandler returns the oldest tickets with the requested status (default open)
+func listSupportTicketsHandler(store *db.Queries) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		status := c.DefaultQuery("status", ticketStatusOpen)
+		if status != ticketStatusOpen && status != ticketStatusClaimed && status != ticketStatusClosed {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "status must be open, claimed or closed"})
+			return
+		}
+
+		page, afterID, ok := parseIDPage(c, supportTicketsDefaultLimit, supportTicketsMaxLimit)
+		if !ok {
+			return
+		}
+
+		tickets, err := store.ListSupportTicketsByStatus(context.Background(), db.ListSupportTicketsByStatusParams{
+			Status:    status,
+			AfterID:   afterID,
+			PageLimit: page.FetchLimit(),
+		})
+		if err != nil {
+			log.Printf("Error listing %s support tickets: %v", status, err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to list tickets"})
+			return
+		}
+
+		tickets, nextCursor := pagination.Trim(tickets, page, func(t db.SupportTicket) string { return pagination.IDKey(t.ID) })
+		c.JSON(http.StatusOK, gin.H{"tickets": tickets, "next_cursor": nextCursor})
+	}
+}
+
+// claimSupportTicketHandler assigns an open ticket to the authenticated agent. Only one agent can win a claim.
+func claimSupportTicketHandler(store *db.Queries, connectionHub *hub.Hub) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		payload := c.MustGet(authorizationPayloadKey).(*token.Payload)
+
+		ticket, ok := parseTicketIDParam(c, store)
+		if !ok {
+			return
+		}
+
+		ticket, err := store.ClaimSupportTicket(context.Background(), db.ClaimSupportTicketParams{
+			ID:      ticket.ID,
+			AgentID: sql.NullInt32{Int32: payload.UserID, Valid: true},
+		})
+		if err != nil {
+			if err == sql.ErrNoRows {
+				c.JSON(http.StatusConflict, gin.H{"error": "Ticket is not open"})
+				return
+			}
+			log.Printf("Error claiming support ticket for agent %d: %v", payload.UserID, err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to claim ticket"})
+			return
+		}
+
+		log.Printf("Agent %d claimed support ticket %d", payload.UserID, ticket.ID)
+		notifyTicketUpdated(store, connectionHub, ticket)
+		c.JSON(http.StatusOK, ticket)
+	}
+}
+
+// assignSupportTicketHandler hands a ticket over to another agent
+func assignSupportTicketHandler(store *db.Queries, connectionHub *hub.Hub) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		type assignTicketRequest struct {
+			AgentID int32 `json:"agent_id" binding:"required,min=1"`
+		}
+		var req assignTicketRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		ticket, ok := parseTicketIDParam(c, store)
+		if !ok {
+			return
+		}
+
+		agent, err := store.GetUserByID(context.Background(), req.AgentID)
+		if err != nil || agent.Role != roleAgent || agent.DeactivatedAt.Valid {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "agent_id is not an active agent"})
+			return
+		}
+
+		ticket, err = store.AssignSupportTicket(context.Background(), db.AssignSupportTicketParams{
+			ID:      ticket.ID,
+			AgentID: sql.NullInt32{Int32: agent.ID, Valid: true},
+		})
+		if err != nil {
+			if err == sql.ErrNoRows {
+				c.JSON(http.StatusConflict, gin.H{"error": "Ticket is closed"})
+				return
+			}
+			log.Printf("Error assigning support ticket to agent %d: %v", agent.ID, err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to assign ticket"})
+			return
+		}
+
+		log.Printf("Support ticket %d assigned to agent %d", ticket.ID, agent.ID)
+		notifyTicketUpdated(store, connectionHub, ticket)
+		c.JSON(http.StatusOK, ticket)
+	}
+}
+
+// closeSupportTicketHandler closes a ticket. The customer's next message opens a new one.
+func closeSupportTicketHandler(store *db.Queries, connectionHub *hub.Hub) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ticket, ok := parseTicketIDParam(c, store)
+		if !ok {
+			return
+		}
+
+		ticket, err := store.CloseSupportTicket(context.Background(), ticket.ID)
+		if err != nil {
+			if err == sql.ErrNoRows {
+				c.JSON(http.StatusConflict, gin.H{"error": "Ticket is already closed"})
+				return
+			}
+			log.Printf("Error closing support ticket: %v", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to close ticket"})
+			return
+		}
+
+		notifyTicketUpdated(store, connectionHub, ticket)
+		c.JSON(http.StatusOK, ticket)
+	}
+}
+
+// getSupportTranscriptHandler returns the messages exchanged during a ticket, oldest first
+func getSupportTranscriptHandler(store *db.Queries) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ticket, ok := parseTicketIDParam(c, store)
+		if !ok {
+			return
+		}
+
+		messages, err := store.ListSupportTicketTranscript(context.Background(), ticket.ID)
+		if err != nil {
+			log.Printf("Error fetching transcript of support ticket %d: %v", ticket.ID, err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch transcript"})
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{"ticket": ticket, "messages": messages})
+	}
+}
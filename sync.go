@@ -0,0 +1,44 @@
+package main
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"websocket-simple-chat-app/hub"
+	"websocket-simple-chat-app/token"
+)
+
+// getSyncCheckpointHandler returns the event sequence state of the authenticated user
+func getSyncCheckpointHandler(connectionHub *hub.Hub) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		payload := c.MustGet(authorizationPayloadKey).(*token.Payload)
+		c.JSON(http.StatusOK, connectionHub.Checkpoint(payload.UserID))
+	}
+}
+
+// ackSyncHandler records the last event the client durably stored. The server frees the acked
+// events from the user's replay buffer.
+func ackSyncHandler(connectionHub *hub.Hub) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		payload := c.MustGet(authorizationPayloadKey).(*token.Payload)
+
+		type ackRequest struct {
+			Seq   int64  `json:"seq" binding:"required,min=1"`
+			Epoch string `json:"epoch" binding:"required"` // From the checkpoint the sequence numbers belong to
+		}
+		var req ackRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		// Sequence numbers of a previous server run must not ack events of this one
+		if checkpoint := connectionHub.Checkpoint(payload.UserID); req.Epoch != checkpoint.Epoch {
+			c.JSON(http.StatusConflict, gin.H{"error": "Epoch changed, resync required", "checkpoint": checkpoint})
+			return
+		}
+
+		c.JSON(http.StatusOK, connectionHub.Ack(payload.UserID, req.Seq))
+	}
+}
@@ -29,12 +29,13 @@ func NewPayload(userID int32, username string, duration time.Duration) (*Payload
 		return nil, err
 	}
 
+	now := time.Now().UTC()
 	payload := &Payload{
 		ID:        tokenID,
 		UserID:    userID,
 		Username:  username,
-		IssuedAt:  time.Now(),
-		ExpiredAt: time.Now().Add(duration),
+		IssuedAt:  now,
+		ExpiredAt: now.Add(duration),
 	}
 	return payload, nil
 }
@@ -0,0 +1,133 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"sync"
+	"time"
+
+	db "websocket-simple-chat-app/db/sqlc"
+	"websocket-simple-chat-app/hub"
+)
+
+// Private typing indicators expire on the server, so a partner who disconnected mid-typing is not
+// shown as typing forever: the recipient gets a typing_stop once the sender sent no typing_start
+// for typingTTL (clients repeat it while the user keeps typing), or when the sender's last
+// connection closes.
+const (
+	typingTTL            = 6 * time.Second
+	typingExpiryInterval = time.Second
+)
+
+// typingPair is a sender typing to a recipient
+type typingPair struct {
+	senderID    int32
+	recipientID int32
+}
+
+// typingTracker holds the open typing indicators of the senders connected to this instance
+type typingTracker struct {
+	expiresAt map[typingPair]time.Time
+
+	mu sync.Mutex
+}
+
+func newTypingTracker() *typingTracker {
+	return &typingTracker{expiresAt: make(map[typingPair]time.Time)}
+}
+
+// Start records that the sender is typing to the recipient, or extends the TTL
+func (t *typingTracker) Start(senderID int32, recipientID int32) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.expiresAt[typingPair{senderID: senderID, recipientID: recipientID}] = time.Now().Add(typingTTL)
+}
+
+// Stop forgets the indicator of the sender to the recipient and reports whether it was open
+func (t *typingTracker) Stop(senderID int32, recipientID int32) bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	pair := typingPair{senderID: senderID, recipientID: recipientID}
+	_, open := t.expiresAt[pair]
+	delete(t.expiresAt, pair)
+	return open
+}
+
+// RemoveUser forgets every indicator of the sender and returns the recipients that still see them typing
+func (t *typingTracker) RemoveUser(senderID int32) []int32 {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	var recipientIDs []int32
+	for pair := range t.expiresAt {
+		if pair.senderID == senderID {
+			recipientIDs = append(recipientIDs, pair.recipientID)
+			delete(t.expiresAt, pair)
+		}
+	}
+	return recipientIDs
+}
+
+// expire forgets and returns the indicators whose TTL passed
+func (t *typingTracker) expire() []typingPair {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	now := time.Now()
+	var expired []typingPair
+	for pair, expiresAt := range t.expiresAt {
+		if now.After(expiresAt) {
+			expired = append(expired, pair)
+			delete(t.expiresAt, pair)
+		}
+	}
+	return expired
+}
+
+// sendTypingStop tells the recipient that the sender no longer types, on behalf of the sender
+func sendTypingStop(connectionHub *hub.Hub, senderID int32, recipientID int32) {
+	jsonMsg, err := json.Marshal(TypingIndicatorMessage{
+		Type:        "typing_stop",
+		RecipientID: recipientID,
+		SenderID:    senderID,
+		CreatedAt:   time.Now().UTC(),
+		Expired:     true,
+	})
+	if err != nil {
+		log.Printf("WS Error: Failed to marshal typing_stop from %d to %d: %v", senderID, recipientID, err)
+		return
+	}
+	connectionHub.SendToUsers([]int32{recipientID}, jsonMsg)
+}
+
+// stopUserTyping ends the open indicators of a sender whose last connection closed
+func stopUserTyping(connectionHub *hub.Hub, tracker *typingTracker, senderID int32) {
+	for _, recipientID := range tracker.RemoveUser(senderID) {
+		sendTypingStop(connectionHub, senderID, recipientID)
+	}
+}
+
+// runTypingExpiry sends a typing_stop for every indicator that was not renewed in time
+func runTypingExpiry(connectionHub *hub.Hub, tracker *typingTracker) {
+	for range time.Tick(typingExpiryInterval) {
+		for _, pair := range tracker.expire() {
+			sendTypingStop(connectionHub, pair.senderID, pair.recipientID)
+		}
+	}
+}
+
+// typingHiddenFromPartner reports whether the sender turned off typing indicators in the
+// conversation with the recipient, or for everyone in their settings. Errors count as hidden, so
+// a failing lookup never leaks them.
+func typingHiddenFromPartner(store *db.Queries, senderID int32, recipientID int32) bool {
+	hidden, err := store.IsTypingHiddenFromPartner(context.Background(), db.IsTypingHiddenFromPartnerParams{
+		UserID:    senderID,
+		PartnerID: recipientID,
+	})
+	if err != nil {
+		log.Printf("WS Error: Failed to check typing privacy of %d towards %d: %v", senderID, recipientID, err)
+		return true
+	}
+	return hidden
+}
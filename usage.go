@@ -0,0 +1,162 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	db "websocket-simple-chat-app/db/sqlc"
+)
+
+// Usage metering for hosted deployments, which bill or enforce plan limits by active users,
+// messages and storage. Every instance records the usage of the current UTC day and its month once
+// per interval; the records are upserts, so instances do not need to coordinate.
+
+const (
+	usageMeteringInterval = time.Hour
+	usageMonthsDefault    = 12
+	usageMonthsMax        = 60
+	usageMonthLayout      = "2006-01"
+	usageDayLayout        = "2006-01-02"
+)
+
+// MonthlyUsage is the usage of a UTC month
+type MonthlyUsage struct {
+	Month            string    `json:"month"` // e.g. "2025-01"
+	ActiveUsers      int32     `json:"active_users"`
+	Messages         int64     `json:"messages"`
+	PeakStorageBytes int64     `json:"peak_storage_bytes"`
+	RolledUpAt       time.Time `json:"rolled_up_at"`
+}
+
+// DailyUsage is the usage of a UTC day
+type DailyUsage struct {
+	Day          string    `json:"day"` // e.g. "2025-01-31"
+	ActiveUsers  int32     `json:"active_users"`
+	Messages     int64     `json:"messages"`
+	StorageBytes int64     `json:"storage_bytes"`
+	RecordedAt   time.Time `json:"recorded_at"`
+}
+
+func newMonthlyUsage(usage db.UsageMonthly) MonthlyUsage {
+	return MonthlyUsage{
+		Month:            usage.Month.UTC().Format(usageMonthLayout),
+		ActiveUsers:      usage.ActiveUsers,
+		Messages:         usage.Messages,
+		PeakStorageBytes: usage.PeakStorageBytes,
+		RolledUpAt:       usage.RolledUpAt,
+	}
+}
+
+func newDailyUsage(usage db.UsageDaily) DailyUsage {
+	return DailyUsage{
+		Day:          usage.Day.UTC().Format(usageDayLayout),
+		ActiveUsers:  usage.ActiveUsers,
+		Messages:     usage.Messages,
+		StorageBytes: usage.StorageBytes,
+		RecordedAt:   usage.RecordedAt,
+	}
+}
+
+// firstOfMonth returns the first day of the UTC month of t
+func firstOfMonth(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, time.UTC)
+}
+
+// recordUsage records the usage of the day and rolls up its month
+func recordUsage(store *db.Queries, day time.Time) error {
+	if _, err := store.RecordDailyUsage(context.Background(), day); err != nil {
+		return err
+	}
+	_, err := store.RollUpMonthlyUsage(context.Background(), firstOfMonth(day))
+	return err
+}
+
+// runUsageMetering periodically records the usage of the current day. When the day changes, the
+// previous day is recorded a last time, so it covers the whole day.
+func runUsageMetering(store *db.Queries) {
+	var lastDay time.Time
+	for {
+		today := time.Now().UTC().Truncate(24 * time.Hour)
+		if !lastDay.IsZero() && lastDay.Before(today) {
+			if err := recordUsage(store, lastDay); err != nil {
+				log.Printf("Error recording the usage of %s: %v", lastDay.Format(usageDayLayout), err)
+			}
+		}
+		if err := recordUsage(store, today); err != nil {
+			log.Printf("Error recording the usage of %s: %v", today.Format(usageDayLayout), err)
+		}
+		lastDay = today
+
+		time.Sleep(usageMeteringInterval)
+	}
+}
+
+// listMonthlyUsageHandler lists the usage of the most recent months, newest first
+func listMonthlyUsageHandler(store *db.Queries) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		months := usageMonthsDefault
+		if monthsStr := c.Query("months"); monthsStr != "" {
+			var err error
+			months, err = strconv.Atoi(monthsStr)
+			if err != nil || months <= 0 {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid 'months', must be a positive number"})
+				return
+			}
+			months = min(months, usageMonthsMax)
+		}
+
+		usage, err := store.ListMonthlyUsage(context.Background(), int32(months))
+		if err != nil {
+			log.Printf("Error listing monthly usage: %v", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to list usage"})
+			return
+		}
+
+		response := make([]MonthlyUsage, len(usage))
+		for i, month := range usage {
+			response[i] = newMonthlyUsage(month)
+		}
+		c.JSON(http.StatusOK, gin.H{"months": response})
+	}
+}
+
+// getMonthlyUsageHandler returns the usage of a month and of each of its recorded days
+func getMonthlyUsageHandler(store *db.Queries) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		month, err := time.Parse(usageMonthLayout, c.Param("month"))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid 'month' format, expected YYYY-MM"})
+			return
+		}
+
+		usage, err := store.GetMonthlyUsage(context.Background(), month)
+		if errors.Is(err, sql.ErrNoRows) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "No usage recorded for this month"})
+			return
+		}
+		if err != nil {
+			log.Printf("Error getting the usage of %s: %v", c.Param("month"), err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get usage"})
+			return
+		}
+		days, err := store.ListDailyUsage(context.Background(), month)
+		if err != nil {
+			log.Printf("Error listing the daily usage of %s: %v", c.Param("month"), err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get usage"})
+			return
+		}
+
+		response := make([]DailyUsage, len(days))
+		for i, day := range days {
+			response[i] = newDailyUsage(day)
+		}
+		c.JSON(http.StatusOK, gin.H{"month": newMonthlyUsage(usage), "days": response})
+	}
+}
@@ -0,0 +1,213 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	db "websocket-simple-chat-app/db/sqlc"
+	"websocket-simple-chat-app/hub"
+	"websocket-simple-chat-app/presence"
+	"websocket-simple-chat-app/token"
+)
+
+// Users choose whether senders learn when they read messages, whether others see them typing and
+// whether others see them online. The server enforces the settings, so they hold for all of a
+// user's clients. Users who never changed them have no row and get the defaults, everything on.
+//   - read_receipts off: read_receipt_update is not sent, and the sender sees the messages as
+//     delivered, not read. The messages are still marked read for the user.
+//   - typing_indicators off: typing_start / typing_stop and room typing indicators are dropped,
+//     as if typing privacy were on for every partner.
+//   - online_visibility off: others see the user offline, as with the invisible presence, and
+//     the last-seen time is not refreshed.
+
+// userSettingsResponse is the API representation of a user's settings
+type userSettingsResponse struct {
+	ReadReceipts     bool                `json:"read_receipts"`
+	TypingIndicators bool                `json:"typing_indicators"`
+	OnlineVisibility bool                `json:"online_visibility"`
+	QuietHours       *quietHoursResponse `json:"quiet_hours"` // null without quiet hours, see quiet_hours.go
+	UpdatedAt        *time.Time          `json:"updated_at"`  // null if never changed
+}
+
+func newUserSettingsResponse(settings db.UserSetting) userSettingsResponse {
+	response := userSettingsResponse{
+		ReadReceipts:     settings.ReadReceipts,
+		TypingIndicators: settings.TypingIndicators,
+		OnlineVisibility: settings.OnlineVisibility,
+		QuietHours:       newQuietHoursResponse(settings),
+	}
+	if !settings.UpdatedAt.IsZero() {
+		response.UpdatedAt = &settings.UpdatedAt
+	}
+	return response
+}
+
+// getUserSettings returns the user's settings, the defaults if they never changed them
+func getUserSettings(store *db.Queries, userID int32) (db.UserSetting, error) {
+	settings, err := store.GetUserSettings(context.Background(), userID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return db.UserSetting{UserID: userID, ReadReceipts: true, TypingIndicators: true, OnlineVisibility: true}, nil
+	}
+	return settings, err
+}
+
+// loadUserSettings returns the user's settings for enforcing them. Errors give every setting off,
+// so a failing lookup never leaks what the user hides.
+func loadUserSettings(store *db.Queries, userID int32) db.UserSetting {
+	settings, err := getUserSettings(store, userID)
+	if err != nil {
+		log.Printf("Error loading the settings of user %d: %v", userID, err)
+		return db.UserSetting{UserID: userID}
+	}
+	return settings
+}
+
+// shownOnline reports whether others see the user online while connected
+func shownOnline(store *db.Queries, user db.User) bool {
+	return user.Presence != presenceInvisible && loadUserSettings(store, user.ID).OnlineVisibility
+}
+
+// usersHidingOnline returns the given users who turned online visibility off
+func usersHidingOnline(store *db.Queries, userIDs []int32) (map[int32]bool, error) {
+	hiding, err := store.ListUsersHidingOnline(context.Background(), userIDs)
+	if err != nil {
+		return nil, err
+	}
+	hidden := make(map[int32]bool, len(hiding))
+	for _, userID := range hiding {
+		hidden[userID] = true
+	}
+	return hidden, nil
+}
+
+// hideUnsharedReadReceipts shows the viewer's messages to recipients who turned read receipts off
+// as delivered instead of read
+func hideUnsharedReadReceipts(store *db.Queries, viewerID int32, messages []messageResponse) error {
+	var recipientIDs []int32
+	for _, message := range messages {
+		if message.SenderID == viewerID && message.ReadAt.Valid {
+			recipientIDs = append(recipientIDs, message.ReceiverID)
+		}
+	}
+	if len(recipientIDs) == 0 {
+		return nil
+	}
+
+	hiding, err := store.ListUsersHidingReadReceipts(context.Background(), recipientIDs)
+	if err != nil {
+		return err
+	}
+	hidden := make(map[int32]bool, len(hiding))
+	for _, userID := range hiding {
+		hidden[userID] = true
+	}
+	for i, message := range messages {
+		if message.SenderID == viewerID && hidden[message.ReceiverID] {
+			messages[i].ReadAt = sql.NullTime{}
+			messages[i].State = messageState(messages[i].Message)
+		}
+	}
+	return nil
+}
+
+// getUserSettingsHandler returns the authenticated user's settings
+func getUserSettingsHandler(store *db.Queries) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		payload := c.MustGet(authorizationPayloadKey).(*token.Payload)
+
+		settings, err := getUserSettings(store, payload.UserID)
+		if err != nil {
+			log.Printf("Error getting the settings of user %d: %v", payload.UserID, err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get settings"})
+			return
+		}
+		c.JSON(http.StatusOK, newUserSettingsResponse(settings))
+	}
+}
+
+// updateUserSettingsHandler changes the settings given in the request and keeps the others.
+// Turning online visibility off or on while connected looks like going offline or coming online
+// to the other users.
+func updateUserSettingsHandler(store *db.Queries, connectionHub *hub.Hub, presenceTracker presence.Tracker) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		payload := c.MustGet(authorizationPayloadKey).(*token.Payload)
+
+		type updateUserSettingsRequest struct {
+			ReadReceipts     *bool `json:"read_receipts"`
+			TypingIndicators *bool `json:"typing_indicators"`
+			OnlineVisibility *bool `json:"online_visibility"`
+		}
+		var req updateUserSettingsRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		previous, err := getUserSettings(store, payload.UserID)
+		if err != nil {
+			log.Printf("Error getting the settings of user %d: %v", payload.UserID, err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update settings"})
+			return
+		}
+		params := db.UpsertUserSettingsParams{
+			UserID:           payload.UserID,
+			ReadReceipts:     previous.ReadReceipts,
+			TypingIndicators: previous.TypingIndicators,
+			OnlineVisibility: previous.OnlineVisibility,
+		}
+		if req.ReadReceipts != nil {
+			params.ReadReceipts = *req.ReadReceipts
+		}
+		if req.TypingIndicators != nil {
+			params.TypingIndicators = *req.TypingIndicators
+		}
+		if req.OnlineVisibility != nil {
+			params.OnlineVisibility = *req.OnlineVisibility
+		}
+
+		settings, err := store.UpsertUserSettings(context.Background(), params)
+		if err != nil {
+			log.Printf("Error updating the settings of user %d: %v", payload.UserID, err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update settings"})
+			return
+		}
+
+		if settings.OnlineVisibility != previous.OnlineVisibility {
+			announceOnlineVisibility(store, connectionHub, presenceTracker, payload.UserID, settings.OnlineVisibility)
+		}
+		c.JSON(http.StatusOK, newUserSettingsResponse(settings))
+	}
+}
+
+// announceOnlineVisibility tells the other users that a connected user went offline or came
+// online by changing their online visibility. Invisible users look offline either way.
+func announceOnlineVisibility(store *db.Queries, connectionHub *hub.Hub, presenceTracker presence.Tracker, userID int32, visible bool) {
+	online, err := presenceTracker.AreOnline(context.Background(), []int32{userID})
+	if err != nil {
+		log.Printf("Error querying presence of user %d: %v", userID, err)
+		return
+	}
+	if !online[userID] {
+		return
+	}
+	user, err := store.GetUserByID(context.Background(), userID)
+	if err != nil {
+		log.Printf("Error fetching user %d to announce online visibility: %v", userID, err)
+		return
+	}
+	if user.Presence == presenceInvisible {
+		return
+	}
+
+	if visible {
+		broadcastPresenceEvent(connectionHub, UserStatusBroadcast{Type: "user_online", UserID: userID, CreatedAt: time.Now().UTC(), Presence: user.Presence}, userID)
+	} else {
+		broadcastPresenceEvent(connectionHub, newUserOfflineBroadcast(userID), userID)
+	}
+}
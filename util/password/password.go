@@ -0,0 +1,34 @@
+package password
+
+import (
+	"errors"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+// MaxLength is the longest password bcrypt accepts, in bytes
+const MaxLength = 72
+
+// ErrMismatch is returned by Check when the password does not match the hash
+var ErrMismatch = errors.New("password does not match")
+
+// ErrTooLong is returned by Hash for passwords longer than MaxLength bytes
+var ErrTooLong = bcrypt.ErrPasswordTooLong
+
+// Hash returns the bcrypt hash of a password, to be stored instead of the password itself
+func Hash(password string) (string, error) {
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		return "", err
+	}
+	return string(hash), nil
+}
+
+// Check compares a password with a hash created by Hash. It returns ErrMismatch if they don't match.
+func Check(password string, hash string) error {
+	err := bcrypt.CompareHashAndPassword([]byte(hash), []byte(password))
+	if errors.Is(err, bcrypt.ErrMismatchedHashAndPassword) {
+		return ErrMismatch
+	}
+	return err
+}
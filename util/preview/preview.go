@@ -0,0 +1,141 @@
+// Package preview renders message contents as short single-line texts for conversation lists,
+// notifications and other places with little room. Previews are cut between user-perceived
+// characters (grapheme clusters), so emoji sequences, flags and accented letters are never split.
+package preview
+
+import (
+	"regexp"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Ellipsis is appended to truncated previews
+const Ellipsis = "…"
+
+// Budget bounds the size of a preview. Zero fields are unlimited.
+type Budget struct {
+	Graphemes int // User-perceived characters, including the ellipsis
+	Bytes     int // UTF-8 bytes, including the ellipsis, e.g. for payload size limits
+}
+
+// Truncate returns s if it fits the budget, otherwise the longest prefix of whole grapheme
+// clusters that fits together with the ellipsis. A cut within the last fifth of the text moves
+// back to the preceding space, so words are not split when avoidable.
+func (b Budget) Truncate(s string) string {
+	if b.fits(s) {
+		return s
+	}
+
+	graphemes, end := 0, 0
+	for end < len(s) {
+		next := end + nextGrapheme(s[end:])
+		if b.Graphemes > 0 && graphemes+2 > b.Graphemes {
+			break
+		}
+		if b.Bytes > 0 && next+len(Ellipsis) > b.Bytes {
+			break
+		}
+		graphemes++
+		end = next
+	}
+
+	cut := s[:end]
+	if space := strings.LastIndexByte(cut, ' '); space > len(cut)*4/5 {
+		cut = cut[:space]
+	}
+	return strings.TrimRightFunc(cut, unicode.IsSpace) + Ellipsis
+}
+
+// fits reports whether s is within the budget
+func (b Budget) fits(s string) bool {
+	if b.Bytes > 0 && len(s) > b.Bytes {
+		return false
+	}
+	return b.Graphemes <= 0 || GraphemeCount(s) <= b.Graphemes
+}
+
+// GraphemeCount returns the number of user-perceived characters of s
+func GraphemeCount(s string) int {
+	count := 0
+	for i := 0; i < len(s); i += nextGrapheme(s[i:]) {
+		count++
+	}
+	return count
+}
+
+// nextGrapheme returns the length in bytes of the grapheme cluster s starts with. It covers the
+// clusters chat messages contain (combining marks, emoji modifier and ZWJ sequences, flags, tag
+// sequences, CRLF) rather than every rule of Unicode UAX #29.
+func nextGrapheme(s string) int {
+	r, size := utf8.DecodeRuneInString(s)
+	if r == '\r' && strings.HasPrefix(s[size:], "\n") {
+		return size + 1
+	}
+	regionalPair := isRegionalIndicator(r)
+
+	i := size
+	for i < len(s) {
+		next, nextSize := utf8.DecodeRuneInString(s[i:])
+		switch {
+		case isGraphemeExtend(next):
+			i += nextSize
+		case next == '\u200d': // Zero width joiner: the next character joins the cluster
+			i += nextSize
+			if i < len(s) {
+				_, joinedSize := utf8.DecodeRuneInString(s[i:])
+				i += joinedSize
+			}
+		case regionalPair && isRegionalIndicator(next): // Two regional indicators form a flag
+			i += nextSize
+			regionalPair = false
+		default:
+			return i
+		}
+	}
+	return i
+}
+
+// isGraphemeExtend reports whether r extends the preceding character
+func isGraphemeExtend(r rune) bool {
+	return unicode.In(r, unicode.Mn, unicode.Me, unicode.Mc) ||
+		(r >= 0x1F3FB && r <= 0x1F3FF) || // Emoji skin tone modifiers
+		(r >= 0xE0020 && r <= 0xE007F) // Tags, e.g. subdivision flags
+}
+
+func isRegionalIndicator(r rune) bool {
+	return r >= 0x1F1E6 && r <= 0x1F1FF
+}
+
+// SingleLine collapses all whitespace, including line breaks, into single spaces
+func SingleLine(s string) string {
+	return strings.Join(strings.Fields(s), " ")
+}
+
+// Markdown syntax removed by StripMarkdown, applied in order
+var markdownRules = []struct {
+	pattern     *regexp.Regexp
+	replacement string
+}{
+	{regexp.MustCompile("(?m)^\\s*(```|~~~).*$"), ""},                                // Code fence lines (the code is kept)
+	{regexp.MustCompile(`(?m)^\s{0,3}#{1,6}\s+`), ""},                                // Headings
+	{regexp.MustCompile(`(?m)^\s{0,3}>\s?`), ""},                                     // Block quotes
+	{regexp.MustCompile(`(?m)^\s*([-*+]|\d+[.)])\s+`), ""},                           // List items
+	{regexp.MustCompile(`(?m)^\s{0,3}([-*_]\s*){3,}$`), ""},                          // Horizontal rules
+	{regexp.MustCompile(`!\[([^\]]*)\]\([^)]*\)`), "$1"},                             // Images: their alt text
+	{regexp.MustCompile(`\[([^\]]+)\]\([^)]*\)`), "$1"},                              // Links: their text
+	{regexp.MustCompile("`([^`]+)`"), "$1"},                                          // Inline code
+	{regexp.MustCompile(`\*\*(\S(?:[^*]*\S)?)\*\*`), "$1"},                           // Bold
+	{regexp.MustCompile(`__(\S(?:[^_]*\S)?)__`), "$1"},                               // Bold
+	{regexp.MustCompile(`\*(\S(?:[^*]*\S)?)\*`), "$1"},                               // Italic
+	{regexp.MustCompile(`(^|[^\pL\pN_])_(\S(?:[^_]*\S)?)_($|[^\pL\pN_])`), "$1$2$3"}, // Italic, not within snake_case words
+	{regexp.MustCompile(`~~(\S(?:[^~]*\S)?)~~`), "$1"},                               // Strikethrough
+}
+
+// StripMarkdown removes markdown syntax from s, keeping the text a reader would see
+func StripMarkdown(s string) string {
+	for _, rule := range markdownRules {
+		s = rule.pattern.ReplaceAllString(s, rule.replacement)
+	}
+	return s
+}
@@ -0,0 +1,89 @@
+package username
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// ColumnLength is the longest username the users.username column holds
+const ColumnLength = 50
+
+// DefaultReserved are names nobody can register, compared case-insensitively
+var DefaultReserved = []string{
+	"admin", "administrator", "root", "system", "support", "help", "moderator",
+	"guest", "anonymous", "me", "null", "undefined",
+}
+
+// reservedPrefixes are kept for generated accounts (guests are named guest-xxxxxxxx)
+var reservedPrefixes = []string{"guest-"}
+
+// Error codes of a rejected username, returned to clients in the "code" field
+const (
+	CodeTooShort         = "username_too_short"
+	CodeTooLong          = "username_too_long"
+	CodeInvalidCharacter = "username_invalid_characters"
+	CodeReserved         = "username_reserved"
+)
+
+// Error describes why a username was rejected
+type Error struct {
+	Code    string
+	Message string
+}
+
+func (e *Error) Error() string {
+	return e.Message
+}
+
+// Rules are the constraints on new usernames. Existing accounts are not checked against them.
+type Rules struct {
+	MinLength int
+	MaxLength int
+	reserved  map[string]bool
+}
+
+// NewRules creates rules with the given length limits (capped to ColumnLength) and reserved names
+// in addition to DefaultReserved
+func NewRules(minLength int, maxLength int, reserved []string) Rules {
+	rules := Rules{
+		MinLength: max(minLength, 1),
+		MaxLength: min(maxLength, ColumnLength),
+		reserved:  make(map[string]bool),
+	}
+	for _, name := range slices.Concat(DefaultReserved, reserved) {
+		rules.reserved[strings.ToLower(strings.TrimSpace(name))] = true
+	}
+	return rules
+}
+
+// Validate checks a username, which should already be trimmed. Usernames are ASCII letters, digits,
+// '_', '.' or '-', starting with a letter or digit. It returns an *Error.
+func (r Rules) Validate(name string) error {
+	if len(name) < r.MinLength {
+		return &Error{Code: CodeTooShort, Message: fmt.Sprintf("username must be at least %d characters", r.MinLength)}
+	}
+	if len(name) > r.MaxLength {
+		return &Error{Code: CodeTooLong, Message: fmt.Sprintf("username must be at most %d characters", r.MaxLength)}
+	}
+
+	for i := 0; i < len(name); i++ {
+		ch := name[i]
+		alphanumeric := (ch >= 'a' && ch <= 'z') || (ch >= 'A' && ch <= 'Z') || (ch >= '0' && ch <= '9')
+		if alphanumeric || (i > 0 && (ch == '_' || ch == '.' || ch == '-')) {
+			continue
+		}
+		return &Error{Code: CodeInvalidCharacter, Message: "username may only contain letters, digits, '_', '.' and '-', and must start with a letter or digit"}
+	}
+
+	lower := strings.ToLower(name)
+	if r.reserved[lower] {
+		return &Error{Code: CodeReserved, Message: "username is reserved"}
+	}
+	for _, prefix := range reservedPrefixes {
+		if strings.HasPrefix(lower, prefix) {
+			return &Error{Code: CodeReserved, Message: "username is reserved"}
+		}
+	}
+	return nil
+}
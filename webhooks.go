@@ -0,0 +1,381 @@
+package main
+
+import (
+	"context"
+	"crypto/rand"
+	"database/sql"
+	"encoding/hex"
+	"log"
+	"math"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"github.com/gin-gonic/gin"
+
+	db "websocket-simple-chat-app/db/sqlc"
+	"websocket-simple-chat-app/hub"
+	"websocket-simple-chat-app/ratelimit"
+	"websocket-simple-chat-app/token"
+	"websocket-simple-chat-app/wordfilter"
+)
+
+// Incoming webhooks let external services (CI, monitoring) post into one room or conversation with
+// nothing but a URL: POST /hooks/<token>. Messages are posted as the webhook's account, typically
+// a dedicated bot account. As for API keys, only the SHA-256 of a token is stored.
+const (
+	webhookTokenPrefix      = "hook_"
+	webhookTokenRandomSize  = 32 // Bytes of randomness, hex encoded in the token
+	webhookDefaultRateLimit = 30 // Messages per minute
+	webhookListLimit        = 500
+)
+
+// Webhook is a webhook as returned to admins, without its token
+type Webhook struct {
+	ID          int64      `json:"id"`
+	Name        string     `json:"name"`
+	UserID      int32      `json:"user_id"`
+	RoomID      *int64     `json:"room_id,omitempty"`
+	RecipientID *int32     `json:"recipient_id,omitempty"`
+	RateLimit   int32      `json:"rate_limit"`
+	CreatedBy   int32      `json:"created_by"`
+	CreatedAt   time.Time  `json:"created_at"`
+	LastUsedAt  *time.Time `json:"last_used_at,omitempty"`
+	RevokedAt   *time.Time `json:"revoked_at,omitempty"`
+}
+
+func newWebhook(hook db.Webhook) Webhook {
+	response := Webhook{
+		ID:        hook.ID,
+		Name:      hook.Name,
+		UserID:    hook.UserID,
+		RateLimit: hook.RateLimit,
+		CreatedBy: hook.CreatedBy,
+		CreatedAt: hook.CreatedAt,
+	}
+	if hook.RoomID.Valid {
+		response.RoomID = &hook.RoomID.Int64
+	}
+	if hook.RecipientID.Valid {
+		response.RecipientID = &hook.RecipientID.Int32
+	}
+	if hook.LastUsedAt.Valid {
+		response.LastUsedAt = &hook.LastUsedAt.Time
+	}
+	if hook.RevokedAt.Valid {
+		response.RevokedAt = &hook.RevokedAt.Time
+	}
+	return response
+}
+
+// generateWebhookToken returns a new random webhook token
+func generateWebhookToken() (string, error) {
+	random := make([]byte, webhookTokenRandomSize)
+	if _, err := rand.Read(random); err != nil {
+		return "", err
+	}
+	return webhookTokenPrefix + hex.EncodeToString(random), nil
+}
+
+// webhookLimiters rate limits webhooks, each with its own bucket. Webhooks with the same limit
+// share a limiter.
+type webhookLimiters struct {
+	limiters map[int32]*ratelimit.Limiter[int64] // By messages per minute
+
+	mu sync.Mutex
+}
+
+// newWebhookLimiters creates the webhook limiters and sweeps them in the background
+func newWebhookLimiters() *webhookLimiters {
+	l := &webhookLimiters{limiters: make(map[int32]*ratelimit.Limiter[int64])}
+	go func() {
+		for range time.Tick(rateLimitSweepInterval) {
+			l.mu.Lock()
+			for _, limiter := range l.limiters {
+				limiter.Sweep()
+			}
+			l.mu.Unlock()
+		}
+	}()
+	return l
+}
+
+// Allow takes a token from the webhook's bucket, see ratelimit.Limiter.Allow
+func (l *webhookLimiters) Allow(hook db.Webhook) (bool, time.Duration) {
+	l.mu.Lock()
+	limiter, ok := l.limiters[hook.RateLimit]
+	if !ok {
+		limiter = ratelimit.PerMinute[int64](int(hook.RateLimit))
+		l.limiters[hook.RateLimit] = limiter
+	}
+	l.mu.Unlock()
+	return limiter.Allow(hook.ID)
+}
+
+// --- Hook Endpoint ---
+
+// postWebhookMessageHandler posts the message of an external service as the webhook's account
+func postWebhookMessageHandler(store *db.Queries, connectionHub *hub.Hub, wordFilter *wordfilter.Filter, limiters *webhookLimiters, unarchive bool) gin.HandlerFunc {
+	type webhookMessageRequest struct {
+		Content string `json:"content" binding:"required"`
+	}
+
+	return func(c *gin.Context) {
+		hook, err := store.GetActiveWebhookByHash(context.Background(), hashAPIKey(c.Param("hook_token")))
+		if err != nil {
+			if err != sql.ErrNoRows {
+				log.Printf("Error looking up webhook: %v", err)
+			}
+			c.JSON(http.StatusNotFound, gin.H{"error": "Invalid webhook"})
+			return
+		}
+
+		allowed, retryAfter := limiters.Allow(hook)
+		if !allowed {
+			log.Printf("Rate limit: Rejected message of webhook %d", hook.ID)
+			c.Header("Retry-After", strconv.Itoa(int(math.Ceil(retryAfter.Seconds()))))
+			c.JSON(http.StatusTooManyRequests, gin.H{"error": "Too many requests, try again later", "code": "rate_limited"})
+			return
+		}
+
+		var req webhookMessageRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		if utf8.RuneCountInString(req.Content) > maxMessageLength {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "content exceeds " + strconv.Itoa(maxMessageLength) + " characters"})
+			return
+		}
+
+		account, err := store.GetUserByID(context.Background(), hook.UserID)
+		if err != nil || account.DeactivatedAt.Valid || accountSuspended(account) {
+			c.JSON(http.StatusForbidden, gin.H{"error": "Webhook account is deactivated or suspended"})
+			return
+		}
+
+		if hook.RoomID.Valid {
+			storedMsg, err := postRoomMessage(store, connectionHub, wordFilter, hook.RoomID.Int64, account.ID, account.Username, req.Content)
+			if err != nil {
+				if err == errNotRoomMember {
+					c.JSON(http.StatusForbidden, gin.H{"error": "Webhook account is not a member of the room anymore"})
+					return
+				}
+				if err == errQuarantineLimit {
+					c.JSON(http.StatusTooManyRequests, gin.H{"error": err.Error()})
+					return
+				}
+				if err == errBlockedWords {
+					c.JSON(http.StatusUnprocessableEntity, gin.H{"error": err.Error()})
+					return
+				}
+				log.Printf("Error posting message of webhook %d to room %d: %v", hook.ID, hook.RoomID.Int64, err)
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to post message"})
+				return
+			}
+			touchWebhook(store, hook.ID)
+			c.JSON(http.StatusCreated, gin.H{"message_id": storedMsg.ID, "created_at": storedMsg.CreatedAt})
+			return
+		}
+
+		storedMsg, err := sendPrivateMessageAs(store, connectionHub, wordFilter, account, hook.RecipientID.Int32, req.Content, unarchive)
+		if err != nil {
+			if err == errBlockedWords {
+				c.JSON(http.StatusUnprocessableEntity, gin.H{"error": err.Error()})
+				return
+			}
+			log.Printf("Error sending message of webhook %d to user %d: %v", hook.ID, hook.RecipientID.Int32, err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to post message"})
+			return
+		}
+		touchWebhook(store, hook.ID)
+		c.JSON(http.StatusCreated, gin.H{"message_id": storedMsg.ID, "created_at": storedMsg.CreatedAt})
+	}
+}
+
+// sendPrivateMessageAs stores a text message of the account (a webhook's, a bot's) to the
+// recipient and delivers it like any other private message, unarchiving the conversation if unarchive is set
+func sendPrivateMessageAs(store *db.Queries, connectionHub *hub.Hub, wordFilter *wordfilter.Filter, account db.User, recipientID int32, content string, unarchive bool) (db.Message, error) {
+	recipient, err := store.GetUserByID(context.Background(), recipientID)
+	if err != nil {
+		return db.Message{}, err
+	}
+	content, err = filterMessageContent(wordFilter, content)
+	if err != nil {
+		return db.Message{}, err
+	}
+
+	storedMsg, err := store.CreateMessage(context.Background(), db.CreateMessageParams{
+		SenderID:    account.ID,
+		ReceiverID:  recipientID,
+		Content:     content,
+		ContentType: contentTypeText,
+	})
+	if err != nil {
+		return db.Message{}, err
+	}
+
+	unarchiveOnIncomingMessage(store, connectionHub, recipientID, account.ID, unarchive)
+	sendJSONToUser(connectionHub, recipientID, OutgoingWsMessage{
+		Type:           "incoming_message",
+		SenderID:       account.ID,
+		SenderUsername: account.Username,
+		Content:        storedMsg.Content,
+		Preview:        messagePreview(contentTypeText, storedMsg.Content),
+		CreatedAt:      storedMsg.CreatedAt,
+		Muted:          recipient.Presence == presenceDND || isConversationMuted(store, recipientID, account.ID),
+		Quiet:          inQuietHours(loadUserSettings(store, recipientID), time.Now()),
+	})
+	return storedMsg, nil
+}
+
+// touchWebhook records that a webhook was used. Failures are only logged.
+func touchWebhook(store *db.Queries, hookID int64) {
+	if err := store.TouchWebhook(context.Background(), hookID); err != nil {
+		log.Printf("Error updating last use of webhook %d: %v", hookID, err)
+	}
+}
+
+// --- Admin Endpoints ---
+
+// createWebhookHandler creates a webhook posting as the given account into a room or to a user.
+// The token is only returned in this response. For a room, the account is added to it.
+func createWebhookHandler(store *db.Queries, connectionHub *hub.Hub) gin.HandlerFunc {
+	type createWebhookRequest struct {
+		Name        string `json:"name" binding:"required,max=100"`
+		UserID      int32  `json:"user_id" binding:"required,min=1"`
+		RoomID      int64  `json:"room_id" binding:"omitempty,min=1"`
+		RecipientID int32  `json:"recipient_id" binding:"omitempty,min=1"`
+		RateLimit   int32  `json:"rate_limit" binding:"omitempty,min=1,max=600"` // Messages per minute
+	}
+
+	return func(c *gin.Context) {
+		payload := c.MustGet(authorizationPayloadKey).(*token.Payload)
+
+		var req createWebhookRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		if (req.RoomID == 0) == (req.RecipientID == 0) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "give either 'room_id' or 'recipient_id'"})
+			return
+		}
+		if req.RateLimit == 0 {
+			req.RateLimit = webhookDefaultRateLimit
+		}
+
+		account, err := store.GetUserByID(context.Background(), req.UserID)
+		if err != nil || account.DeactivatedAt.Valid || account.Role == roleGuest {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "user_id is not an active account"})
+			return
+		}
+		if req.RecipientID != 0 {
+			recipient, err := store.GetUserByID(context.Background(), req.RecipientID)
+			if err != nil || recipient.ID == account.ID {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "recipient_id is not another account"})
+				return
+			}
+			// Guests are deleted when they expire, and with them the webhooks sending to them
+			if recipient.Role == roleGuest {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "recipient_id is a guest account"})
+				return
+			}
+		}
+		if req.RoomID != 0 {
+			room, err := store.GetRoom(context.Background(), req.RoomID)
+			if err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "room_id is not a room"})
+				return
+			}
+			added, err := store.AddRoomMember(context.Background(), db.AddRoomMemberParams{RoomID: room.ID, UserID: account.ID})
+			if err != nil {
+				log.Printf("Error adding user %d to room %d: %v", account.ID, room.ID, err)
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create webhook"})
+				return
+			}
+			if added > 0 {
+				sendJSONToRoom(store, connectionHub, room.ID, account.ID, RoomMembershipEvent{
+					Type:      "room_member_joined",
+					RoomID:    room.ID,
+					UserID:    account.ID,
+					Username:  account.Username,
+					CreatedAt: time.Now().UTC(),
+				})
+			}
+		}
+
+		hookToken, err := generateWebhookToken()
+		if err != nil {
+			log.Printf("Error generating webhook token: %v", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create webhook"})
+			return
+		}
+		hook, err := store.CreateWebhook(context.Background(), db.CreateWebhookParams{
+			Name:        req.Name,
+			UserID:      account.ID,
+			RoomID:      sql.NullInt64{Int64: req.RoomID, Valid: req.RoomID != 0},
+			RecipientID: sql.NullInt32{Int32: req.RecipientID, Valid: req.RecipientID != 0},
+			TokenHash:   hashAPIKey(hookToken), // Stored like API keys
+			RateLimit:   req.RateLimit,
+			CreatedBy:   payload.UserID,
+		})
+		if err != nil {
+			log.Printf("Error storing webhook for user %d: %v", account.ID, err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create webhook"})
+			return
+		}
+
+		log.Printf("Webhook %d created by admin %d, posting as user %d", hook.ID, payload.UserID, account.ID)
+		c.JSON(http.StatusCreated, gin.H{
+			"webhook": newWebhook(hook),
+			"token":   hookToken,
+			"path":    "/hooks/" + hookToken,
+		})
+	}
+}
+
+// listWebhooksHandler lists the newest webhooks, revoked ones included
+func listWebhooksHandler(store *db.Queries) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		hooks, err := store.ListWebhooks(context.Background(), webhookListLimit)
+		if err != nil {
+			log.Printf("Error listing webhooks: %v", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to list webhooks"})
+			return
+		}
+
+		response := make([]Webhook, len(hooks))
+		for i, hook := range hooks {
+			response[i] = newWebhook(hook)
+		}
+		c.JSON(http.StatusOK, gin.H{"webhooks": response})
+	}
+}
+
+// revokeWebhookHandler revokes a webhook. Its URL is rejected from then on.
+func revokeWebhookHandler(store *db.Queries) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		hookID, err := strconv.ParseInt(c.Param("hook_id"), 10, 64)
+		if err != nil || hookID <= 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid webhook ID"})
+			return
+		}
+
+		revoked, err := store.RevokeWebhook(context.Background(), hookID)
+		if err != nil {
+			log.Printf("Error revoking webhook %d: %v", hookID, err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to revoke webhook"})
+			return
+		}
+		if revoked == 0 {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Webhook not found or already revoked"})
+			return
+		}
+
+		log.Printf("Webhook %d revoked", hookID)
+		c.Status(http.StatusNoContent)
+	}
+}
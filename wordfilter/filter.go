@@ -0,0 +1,127 @@
+// Package wordfilter checks message text against the moderation word list. Entries are plain words
+// or regular expressions, matched case-insensitively; the list can be replaced at any time while
+// messages are being checked.
+package wordfilter
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"sync"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Severities of an entry
+const (
+	SeverityLow  = "low"  // Matches are masked with asterisks
+	SeverityHigh = "high" // Messages with a match are rejected
+)
+
+// Entry is a word or pattern of the list
+type Entry struct {
+	Pattern  string
+	Regex    bool // Pattern is a regular expression (RE2 syntax) instead of a word
+	Severity string
+}
+
+// Result is the outcome of a check
+type Result struct {
+	Text     string // The text with the low severity matches masked
+	Rejected bool   // A high severity entry matched
+}
+
+type rule struct {
+	pattern  *regexp.Regexp
+	severity string
+}
+
+// Filter checks texts against the current list. The zero value has an empty list.
+type Filter struct {
+	mu    sync.RWMutex
+	rules []rule
+}
+
+// Compile returns the expression an entry is matched with. Words only match whole words.
+func Compile(entry Entry) (*regexp.Regexp, error) {
+	if entry.Severity != SeverityLow && entry.Severity != SeverityHigh {
+		return nil, fmt.Errorf("invalid severity %q", entry.Severity)
+	}
+	if strings.TrimSpace(entry.Pattern) == "" {
+		return nil, errors.New("empty pattern")
+	}
+
+	expr := "(?i)" + entry.Pattern
+	if !entry.Regex {
+		expr = "(?i)" + regexp.QuoteMeta(entry.Pattern)
+		if first, _ := utf8.DecodeRuneInString(entry.Pattern); isWordRune(first) {
+			expr = `(?i)\b` + regexp.QuoteMeta(entry.Pattern)
+		}
+		if last, _ := utf8.DecodeLastRuneInString(entry.Pattern); isWordRune(last) {
+			expr += `\b`
+		}
+	}
+	pattern, err := regexp.Compile(expr)
+	if err != nil {
+		return nil, err
+	}
+	if pattern.MatchString("") {
+		return nil, errors.New("pattern matches empty text")
+	}
+	return pattern, nil
+}
+
+func isWordRune(r rune) bool {
+	return r == '_' || unicode.IsLetter(r) || unicode.IsDigit(r)
+}
+
+// Load replaces the list. Invalid entries are left out; their errors are returned joined.
+func (f *Filter) Load(entries []Entry) error {
+	rules := make([]rule, 0, len(entries))
+	var errs []error
+	for _, entry := range entries {
+		pattern, err := Compile(entry)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%q: %w", entry.Pattern, err))
+			continue
+		}
+		rules = append(rules, rule{pattern: pattern, severity: entry.Severity})
+	}
+
+	f.mu.Lock()
+	f.rules = rules
+	f.mu.Unlock()
+	return errors.Join(errs...)
+}
+
+// Len returns the number of entries in use
+func (f *Filter) Len() int {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+	return len(f.rules)
+}
+
+// Check matches text against the list
+func (f *Filter) Check(text string) Result {
+	f.mu.RLock()
+	rules := f.rules
+	f.mu.RUnlock()
+
+	for _, rule := range rules {
+		if rule.severity == SeverityHigh && rule.pattern.MatchString(text) {
+			return Result{Text: text, Rejected: true}
+		}
+	}
+	for _, rule := range rules {
+		if rule.severity == SeverityLow {
+			text = rule.pattern.ReplaceAllStringFunc(text, mask)
+		}
+	}
+	return Result{Text: text}
+}
+
+// mask replaces every character of a match with an asterisk
+func mask(match string) string {
+	return strings.Repeat("*", utf8.RuneCountInString(match))
+}
@@ -0,0 +1,79 @@
+// Package ws dispatches the messages clients send over their WebSocket connections to handlers
+// registered per message type, so new message types do not grow the connection's read loop.
+package ws
+
+import (
+	"fmt"
+	"time"
+
+	db "websocket-simple-chat-app/db/sqlc"
+	"websocket-simple-chat-app/hub"
+	"websocket-simple-chat-app/token"
+)
+
+// HandlerFunc handles one client message
+type HandlerFunc func(c *Context)
+
+// Context is a client message and what its handler needs to act on it
+type Context struct {
+	Hub   *hub.Hub
+	Store *db.Queries
+
+	Client   *hub.Client // The connection the message arrived on
+	Session  *Session
+	UserID   int32 // The authenticated user
+	Username string
+	Guest    bool
+
+	Type       string // Message type
+	Message    []byte // The raw JSON message
+	ReceivedAt time.Time
+}
+
+// Session is the authenticated session of a connection. Its token changes when it is renewed.
+type Session struct {
+	Token   *token.Payload
+	renewed func(*token.Payload)
+}
+
+// NewSession creates the session of a connection; renewed is called whenever its token is renewed
+func NewSession(payload *token.Payload, renewed func(*token.Payload)) *Session {
+	return &Session{Token: payload, renewed: renewed}
+}
+
+// Renew replaces the token of the session
+func (s *Session) Renew(payload *token.Payload) {
+	s.Token = payload
+	if s.renewed != nil {
+		s.renewed(payload)
+	}
+}
+
+// Dispatcher routes client messages to the handler registered for their type. Handlers are
+// registered before the server starts; Dispatch is safe for concurrent use afterwards.
+type Dispatcher struct {
+	handlers map[string]HandlerFunc
+}
+
+// NewDispatcher creates a Dispatcher without handlers
+func NewDispatcher() *Dispatcher {
+	return &Dispatcher{handlers: make(map[string]HandlerFunc)}
+}
+
+// Handle registers the handler of a message type. It panics if the type already has one.
+func (d *Dispatcher) Handle(messageType string, handler HandlerFunc) {
+	if _, ok := d.handlers[messageType]; ok {
+		panic(fmt.Sprintf("ws: handler for %q registered twice", messageType))
+	}
+	d.handlers[messageType] = handler
+}
+
+// Dispatch calls the handler of the message's type. It returns false if the type has none.
+func (d *Dispatcher) Dispatch(c *Context) bool {
+	handler, ok := d.handlers[c.Type]
+	if !ok {
+		return false
+	}
+	handler(c)
+	return true
+}
@@ -0,0 +1,107 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/gorilla/websocket"
+
+	db "websocket-simple-chat-app/db/sqlc"
+	"websocket-simple-chat-app/token"
+)
+
+// WebSocket clients authenticate with their access token in one of these ways:
+//   - an "Authorization: Bearer <token>" header on the upgrade request, for clients that can set headers;
+//   - the subprotocols "chat" and "bearer.<token>" in Sec-WebSocket-Protocol, for browsers, which
+//     cannot set headers. The server selects "chat" and never echoes the token back;
+//   - an "auth" message as the first message on the connection, within wsAuthMessageTimeout;
+//   - the "token" query parameter. It is deprecated: URLs end up in access logs and proxy logs.
+//
+// Bots and integrations send an API key with the "read" scope in the X-API-Key header instead (see
+// bots.go). Their sessions do not expire and cannot be reauthenticated.
+
+const (
+	wsSubprotocol             = "chat"
+	wsBearerSubprotocolPrefix = "bearer."
+	wsAuthMessageTimeout      = 5 * time.Second
+)
+
+// How the token of a WebSocket connection was sent, for the logs
+const (
+	wsAuthSourceHeader      = "header"
+	wsAuthSourceSubprotocol = "subprotocol"
+	wsAuthSourceMessage     = "message"
+	wsAuthSourceQuery       = "query"
+	wsAuthSourceAPIKey      = "api_key"
+)
+
+var (
+	errWsAuthTimeout        = errors.New("authentication timeout")
+	errWsAuthMessageInvalid = errors.New("first message must be 'auth' with a token")
+	errWsAPIKeyNotReadable  = errors.New("API key lacks the 'read' scope")
+)
+
+// wsAuthMessage is the first message of a connection opened without a token
+//
+//wsschema:client
+type wsAuthMessage struct {
+	Type  string `json:"type"` // "auth"
+	Token string `json:"token"`
+}
+
+// handshakeToken returns the token sent with the upgrade request and how it was sent. It returns an
+// empty token when the client will send it in an "auth" message.
+func handshakeToken(r *http.Request) (string, string) {
+	fields := strings.Fields(r.Header.Get(authorizationHeaderKey))
+	if len(fields) == 2 && strings.ToLower(fields[0]) == authorizationTypeBearer {
+		return fields[1], wsAuthSourceHeader
+	}
+	for _, protocol := range websocket.Subprotocols(r) {
+		if token, ok := strings.CutPrefix(protocol, wsBearerSubprotocolPrefix); ok && token != "" {
+			return token, wsAuthSourceSubprotocol
+		}
+	}
+	if token := r.URL.Query().Get("token"); token != "" {
+		return token, wsAuthSourceQuery
+	}
+	return "", ""
+}
+
+// authenticateWsAPIKey checks the API key of a bot or integration connection and returns its
+// payload and scopes. Keys without the read scope are refused: the connection would receive events.
+func authenticateWsAPIKey(store *db.Queries, key string) (*token.Payload, []string, error) {
+	apiKey, account, err := lookupAPIKey(store, key)
+	if err != nil {
+		return nil, nil, err
+	}
+	if !slices.Contains(apiKey.Scopes, apiKeyScopeRead) {
+		return nil, nil, errWsAPIKeyNotReadable
+	}
+	return accountPayload(account), apiKey.Scopes, nil
+}
+
+// readAuthMessage waits for the "auth" message of a connection opened without a token. The read
+// deadline is cleared again for the hub's read pump.
+func readAuthMessage(conn *websocket.Conn) (string, error) {
+	conn.SetReadDeadline(time.Now().Add(wsAuthMessageTimeout))
+	defer conn.SetReadDeadline(time.Time{})
+
+	messageType, data, err := conn.ReadMessage()
+	if err != nil {
+		var netErr interface{ Timeout() bool }
+		if errors.As(err, &netErr) && netErr.Timeout() {
+			return "", errWsAuthTimeout
+		}
+		return "", err
+	}
+
+	var msg wsAuthMessage
+	if messageType != websocket.TextMessage || json.Unmarshal(data, &msg) != nil || msg.Type != "auth" || msg.Token == "" {
+		return "", errWsAuthMessageInvalid
+	}
+	return msg.Token, nil
+}
@@ -0,0 +1,63 @@
+package main
+
+import (
+	"encoding/json"
+	"log"
+	"time"
+
+	"websocket-simple-chat-app/hub"
+)
+
+// Client messages the server cannot handle are answered with an "error" event on the connection
+// they arrived on, instead of being dropped silently. private_message keeps its nack (see acks.go).
+// Frames larger than wsReadLimit are not read at all: the connection is closed with 1009.
+
+// Codes of the error event
+const (
+	wsErrorInvalidUTF8      = "invalid_utf8"      // The frame is not valid UTF-8
+	wsErrorInvalidJSON      = "invalid_json"      // The frame is not a JSON object with a string "type"
+	wsErrorUnsupportedFrame = "unsupported_frame" // Binary frames are not supported
+	wsErrorUnknownType      = "unknown_type"      // The server has no handler for the type
+	wsErrorNotAllowed       = "not_allowed"       // The user may not send the type, e.g. a guest
+	wsErrorInvalidMessage   = "invalid_message"   // Required fields are missing or malformed
+	wsErrorMessageTooLong   = "message_too_long"  // The content exceeds maxMessageLength
+	wsErrorRejected         = "rejected"          // The message was valid but refused, e.g. not a room member
+)
+
+// wsReadLimitOverhead is the room for the JSON around the content in a frame
+const wsReadLimitOverhead = 32 * 1024
+
+// WsErrorMessage tells a client that a message it sent was not handled
+//
+//wsschema:server
+type WsErrorMessage struct {
+	Type        string    `json:"type"`                    // "error"
+	Code        string    `json:"code"`                    // e.g. "invalid_json", "message_too_long"
+	Error       string    `json:"error"`                   // Human-readable detail, may change
+	MessageType string    `json:"message_type,omitempty"`  // Type of the message, if it had one
+	ClientMsgID string    `json:"client_msg_id,omitempty"` // Echoed from the message, if it had one
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+// wsReadLimit is the largest frame read from a connection, in bytes. Escaped in JSON, a character
+// of the content takes up to 12 bytes (a surrogate pair of \uXXXX escapes).
+func wsReadLimit() int64 {
+	return int64(maxMessageLength)*12 + wsReadLimitOverhead
+}
+
+// sendWsError answers a message on the connection it arrived on
+func sendWsError(client *hub.Client, code string, messageType string, clientMsgID string, detail string) {
+	jsonMsg, err := json.Marshal(WsErrorMessage{
+		Type:        "error",
+		Code:        code,
+		Error:       detail,
+		MessageType: messageType,
+		ClientMsgID: clientMsgID,
+		CreatedAt:   time.Now().UTC(),
+	})
+	if err != nil {
+		log.Printf("WS Error: Failed to marshal error for user %d: %v", client.UserID, err)
+		return
+	}
+	client.Send(jsonMsg)
+}
@@ -0,0 +1,539 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"log"
+	"slices"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	db "websocket-simple-chat-app/db/sqlc"
+	"websocket-simple-chat-app/metrics"
+	"websocket-simple-chat-app/notify"
+	"websocket-simple-chat-app/presence"
+	"websocket-simple-chat-app/token"
+	"websocket-simple-chat-app/wordfilter"
+	"websocket-simple-chat-app/ws"
+)
+
+// newWsDispatcher registers the handlers of the messages clients send over WebSocket. Guest
+// restrictions and rate limits are applied by the read loop before dispatching.
+func newWsDispatcher(tokenMaker token.Maker, presenceTracker presence.Tracker, pushDispatcher *notify.Dispatcher, typing *typingTracker, roomTyping *roomTypingTracker, wordFilter *wordfilter.Filter, unarchive bool) *ws.Dispatcher {
+	dispatcher := ws.NewDispatcher()
+
+	dispatcher.Handle("private_message", func(c *ws.Context) {
+		handlePrivateMessage(c, presenceTracker, pushDispatcher, wordFilter, unarchive)
+	})
+	dispatcher.Handle("contact_card", func(c *ws.Context) {
+		handleContactCard(c, unarchive)
+	})
+	dispatcher.Handle("delete_message", func(c *ws.Context) {
+		handleDeleteMessage(c.Store, c.Hub, c.UserID, c.Message)
+	})
+	dispatcher.Handle("message_read", handleMessageRead)
+	for _, messageType := range []string{"typing_start", "typing_stop"} {
+		dispatcher.Handle(messageType, func(c *ws.Context) { handleTypingIndicator(c, typing) })
+	}
+
+	dispatcher.Handle("set_presence", func(c *ws.Context) {
+		handleSetPresence(c.Store, c.Hub, c.UserID, c.Message)
+	})
+	dispatcher.Handle("reauth", func(c *ws.Context) {
+		if renewed := handleReauth(c.Store, tokenMaker, c.Client, c.Session.Token, c.Message); renewed != nil {
+			c.Session.Renew(renewed)
+		}
+	})
+	dispatcher.Handle("ping", handlePing)
+
+	dispatcher.Handle("room_message", func(c *ws.Context) {
+		handleRoomMessage(c.Store, c.Hub, wordFilter, c.Client, c.Username, c.Message)
+	})
+	for _, messageType := range []string{"room_typing_start", "room_typing_stop"} {
+		dispatcher.Handle(messageType, func(c *ws.Context) {
+			handleRoomTyping(c.Store, roomTyping, c.UserID, c.Username, c.Message)
+		})
+	}
+	dispatcher.Handle("announcement_seen", func(c *ws.Context) {
+		handleAnnouncementSeen(c.Store, c.UserID, c.Message)
+	})
+	dispatcher.Handle("support_reply", func(c *ws.Context) {
+		handleSupportReply(c.Store, c.Hub, c.Client, c.Message)
+	})
+
+	// WebRTC signalling is forwarded to the recipient's connections on this instance
+	dispatcher.Handle("offer", handleOffer)
+	dispatcher.Handle("ice-candidate", handleIceCandidate)
+	dispatcher.Handle("hangup", handleHangup)
+	dispatcher.Handle("answer", handleAnswer)
+
+	return dispatcher
+}
+
+// handlePrivateMessage stores a private message, delivers it to the recipient's connections or queues it,
+// and acks it on the sending connection. Recipients without a connection get a push notification.
+// Text and markdown content goes through the moderation word filter first.
+func handlePrivateMessage(c *ws.Context, presenceTracker presence.Tracker, pushDispatcher *notify.Dispatcher, wordFilter *wordfilter.Filter, unarchive bool) {
+	var msg IncomingWsMessage
+	if err := json.Unmarshal(c.Message, &msg); err != nil { // Unmarshal again into specific struct
+		log.Printf("WS Error: Failed to unmarshal private_message: %v. Payload: %s", err, string(c.Message))
+		sendMessageNack(c.Client, msg.ClientMsgID, ackStatusRejected, "invalid private_message")
+		return
+	}
+	// Basic validation
+	if msg.RecipientID <= 0 || msg.Content == "" {
+		log.Printf("WS Warning: Invalid private message from %s (ID: %d): RecipientID=%d, Content empty=%t", c.Username, c.UserID, msg.RecipientID, msg.Content == "")
+		sendMessageNack(c.Client, msg.ClientMsgID, ackStatusRejected, "recipient_id and content are required")
+		return
+	}
+	recipient, err := c.Store.GetUserByID(context.Background(), msg.RecipientID)
+	if err != nil {
+		log.Printf("WS Warning: Private message from %s (ID: %d) to unknown user %d: %v", c.Username, c.UserID, msg.RecipientID, err)
+		sendMessageNack(c.Client, msg.ClientMsgID, ackStatusRejected, "unknown recipient")
+		return
+	}
+	if utf8.RuneCountInString(msg.Content) > maxMessageLength {
+		log.Printf("WS Warning: Private message from %s (ID: %d) exceeds %d characters", c.Username, c.UserID, maxMessageLength)
+		sendMessageNack(c.Client, msg.ClientMsgID, ackStatusRejected, "message too long")
+		return
+	}
+	contentType, contentErr := validateClientContent(msg.ContentType, msg.Content)
+	if contentErr != nil {
+		log.Printf("WS Warning: Rejected private message from %s (ID: %d): %v", c.Username, c.UserID, contentErr)
+		sendMessageNack(c.Client, msg.ClientMsgID, ackStatusRejected, contentErr.Error())
+		return
+	}
+	if contentType == contentTypeText || contentType == contentTypeMarkdown {
+		msg.Content, err = filterMessageContent(wordFilter, msg.Content)
+		if err != nil {
+			log.Printf("WS Warning: Rejected private message from %s (ID: %d): %v", c.Username, c.UserID, err)
+			sendMessageNack(c.Client, msg.ClientMsgID, ackStatusRejected, err.Error())
+			return
+		}
+	}
+	if c.Guest && recipient.Role != roleSupport {
+		log.Printf("WS Warning: Guest %s (ID: %d) can only message support, not user %d", c.Username, c.UserID, msg.RecipientID)
+		sendMessageNack(c.Client, msg.ClientMsgID, ackStatusRejected, "guests can only message support")
+		return
+	}
+	if err := checkQuarantineSend(c.Store, c.UserID); err != nil {
+		log.Printf("WS Warning: Quarantined user %s (ID: %d) reached the send limit", c.Username, c.UserID)
+		sendMessageNack(c.Client, msg.ClientMsgID, ackStatusRejected, err.Error())
+		return
+	}
+	var replyTo *QuotedMessage
+	if msg.ReplyToMessageID != 0 {
+		parent, err := getReplyParent(c.Store, c.UserID, msg.RecipientID, msg.ReplyToMessageID)
+		if err == errInvalidReply {
+			log.Printf("WS Warning: Private message from %s (ID: %d) replies to message %d of another conversation", c.Username, c.UserID, msg.ReplyToMessageID)
+			sendMessageNack(c.Client, msg.ClientMsgID, ackStatusRejected, err.Error())
+			return
+		}
+		if err != nil {
+			log.Printf("WS Error: Failed to fetch message %d replied to by %d: %v", msg.ReplyToMessageID, c.UserID, err)
+			sendMessageNack(c.Client, msg.ClientMsgID, ackStatusFailed, "failed to store message")
+			return
+		}
+		replyTo = newQuotedMessage(parent)
+	}
+	// 1. Store the message in the database
+	storedMsg, dbErr := c.Store.CreateMessage(context.Background(), db.CreateMessageParams{
+		SenderID:         c.UserID,
+		ReceiverID:       msg.RecipientID,
+		Content:          msg.Content,
+		ContentType:      contentType,
+		ReplyToMessageID: sql.NullInt64{Int64: msg.ReplyToMessageID, Valid: replyTo != nil},
+	})
+	if dbErr != nil {
+		log.Printf("WS Error: Failed to store message from %d to %d: %v", c.UserID, msg.RecipientID, dbErr)
+		sendMessageNack(c.Client, msg.ClientMsgID, ackStatusFailed, "failed to store message")
+		return
+	}
+	log.Printf("Message from %d (%s) to %d stored successfully.", c.UserID, c.Username, msg.RecipientID)
+	// Messages to a support identity go to the agents handling the customer's ticket
+	if recipient.Role == roleSupport {
+		routeSupportMessage(c.Store, c.Hub, storedMsg, c.Username)
+		sendMessageAck(c.Client, msg.ClientMsgID, storedMsg, ackStatusStored)
+		return
+	}
+	unarchiveOnIncomingMessage(c.Store, c.Hub, msg.RecipientID, c.UserID, unarchive)
+	// 2. Attempt real-time delivery if recipient is online
+	outgoingMsg := OutgoingWsMessage{
+		Type:           "incoming_message",
+		SenderID:       c.UserID,
+		SenderUsername: c.Username,
+		Content:        msg.Content,
+		CreatedAt:      storedMsg.CreatedAt,
+		Muted:          recipient.Presence == presenceDND || isConversationMuted(c.Store, msg.RecipientID, c.UserID),
+		Quiet:          inQuietHours(loadUserSettings(c.Store, msg.RecipientID), time.Now()),
+		ReplyTo:        replyTo,
+	}
+	render, marshalErr := renderIncomingMessage(outgoingMsg, contentType)
+	if marshalErr != nil {
+		log.Printf("WS Error: Failed to marshal outgoing private message: %v", marshalErr)
+		sendMessageAck(c.Client, msg.ClientMsgID, storedMsg, ackStatusStored)
+		return // Skip sending if marshalling fails
+	}
+	undelivered := func() { deferDelivery(c.Store, c.Hub, presenceTracker, storedMsg) }
+	// Live delivery goes through the hub like queued delivery, so the event gets the recipient's
+	// next seq and is kept for their sync connections until acked (see hub/replay.go).
+	// The first connection that writes the message marks it delivered (see delivery_receipts.go).
+	var delivered sync.Once
+	observeDelivery := func() {
+		metrics.ObserveDelivery(metrics.RouteLocal, time.Since(c.ReceivedAt))
+		delivered.Do(func() { go markMessageDelivered(c.Store, c.Hub, storedMsg) })
+	}
+	recipientConnections := len(c.Hub.GetUserClients(msg.RecipientID))
+	queued := c.Hub.SendOrQueueAndNotify(msg.RecipientID, render, c.ReceivedAt, observeDelivery)
+	if recipientConnections > 0 {
+		log.Printf("Sent message from %d (%s) to %d (%d active connections)", c.UserID, c.Username, msg.RecipientID, recipientConnections)
+		sendMessageAck(c.Client, msg.ClientMsgID, storedMsg, ackStatusDelivered)
+	} else if queued {
+		// Recipient disconnected moments ago: the hub delivers the message when they reconnect
+		log.Printf("Recipient %d recently disconnected. Message stored and queued.", msg.RecipientID)
+		sendMessageAck(c.Client, msg.ClientMsgID, storedMsg, ackStatusQueued)
+		notifyOfflineRecipient(pushDispatcher, recipient, c.Username, storedMsg, outgoingMsg.Muted || outgoingMsg.Quiet, undelivered)
+	} else {
+		log.Printf("Recipient %d is offline. Message stored.", msg.RecipientID)
+		sendMessageAck(c.Client, msg.ClientMsgID, storedMsg, ackStatusStored)
+		notifyOfflineRecipient(pushDispatcher, recipient, c.Username, storedMsg, outgoingMsg.Muted || outgoingMsg.Quiet, undelivered)
+	}
+}
+
+// handleTypingIndicator forwards a typing_start or typing_stop to the recipient's connections
+func handleTypingIndicator(c *ws.Context, typing *typingTracker) {
+	var msg TypingIndicatorMessage
+	if err := json.Unmarshal(c.Message, &msg); err != nil {
+		log.Printf("WS Error: Failed to unmarshal typing indicator: %v. Payload: %s", err, string(c.Message))
+		return
+	}
+	// Basic validation
+	if msg.RecipientID <= 0 {
+		log.Printf("WS Warning: Invalid typing indicator from %s (ID: %d): RecipientID=%d", c.Username, c.UserID, msg.RecipientID)
+		return
+	}
+	// The sender may have turned off typing indicators for this partner. An indicator
+	// that was open when they did is ended; nothing else is sent.
+	if typingHiddenFromPartner(c.Store, c.UserID, msg.RecipientID) {
+		if typing.Stop(c.UserID, msg.RecipientID) {
+			sendTypingStop(c.Hub, c.UserID, msg.RecipientID)
+		}
+		return
+	}
+	// Track the indicator so it ends even if the sender never sends typing_stop
+	if msg.Type == "typing_start" {
+		typing.Start(c.UserID, msg.RecipientID)
+	} else {
+		typing.Stop(c.UserID, msg.RecipientID)
+	}
+	// Add SenderID and timestamp for forwarding
+	msg.SenderID = c.UserID
+	msg.CreatedAt = time.Now().UTC()
+	// Marshal for sending
+	jsonMsg, marshalErr := json.Marshal(msg)
+	if marshalErr != nil {
+		log.Printf("WS Error: Failed to marshal outgoing typing indicator: %v", marshalErr)
+		return
+	}
+	// Get recipient connections
+	recipientClients := c.Hub.GetUserClients(msg.RecipientID)
+	// Send to recipient, here and on other instances
+	for _, recipientClient := range recipientClients {
+		if !recipientClient.Send(jsonMsg) {
+			log.Printf("WS Error: Failed to send typing indicator to user %d", msg.RecipientID)
+		}
+	}
+	c.Hub.Relay(msg.RecipientID, jsonMsg)
+	log.Printf("Forwarded %s indicator from %d to %d", msg.Type, c.UserID, msg.RecipientID)
+}
+
+// handleMessageRead marks messages as read and sends a read receipt to their sender
+func handleMessageRead(c *ws.Context) {
+	var msg MessageReadMessage
+	if err := json.Unmarshal(c.Message, &msg); err != nil {
+		log.Printf("WS Error: Failed to unmarshal message_read: %v. Payload: %s", err, string(c.Message))
+		return
+	}
+	// Basic validation
+	if msg.SenderID <= 0 || msg.UpToID < 0 || len(msg.MessageIDs) > maxMessageReadIDs {
+		log.Printf("WS Warning: Invalid message_read from %s (ID: %d): SenderID=%d, UpToID=%d, %d message IDs", c.Username, c.UserID, msg.SenderID, msg.UpToID, len(msg.MessageIDs))
+		return
+	}
+	// Persist the read status so it survives reconnects and shows up in GET /messages
+	readIDs, dbErr := c.Store.MarkMessagesRead(context.Background(), db.MarkMessagesReadParams{
+		SenderID:   msg.SenderID,
+		ReaderID:   c.UserID,
+		UpToID:     msg.UpToID,
+		MessageIds: msg.MessageIDs,
+	})
+	if dbErr != nil {
+		log.Printf("WS Error: Failed to mark messages from %d to %d as read: %v", msg.SenderID, c.UserID, dbErr)
+		return
+	}
+	// Messages already read (or not in the conversation) produce no receipt, and neither do
+	// readers who turned read receipts off
+	if len(readIDs) == 0 || !loadUserSettings(c.Store, c.UserID).ReadReceipts {
+		return
+	}
+	slices.Sort(readIDs)
+	// Prepare the update message for the original sender
+	updateMsg := ReadReceiptUpdateMessage{
+		Type:       "read_receipt_update",
+		ReaderID:   c.UserID,     // The current user read the message
+		SenderID:   msg.SenderID, // The user whose messages were read
+		MessageIDs: readIDs,
+		CreatedAt:  time.Now().UTC(),
+	}
+	// Marshal for sending
+	jsonMsg, marshalErr := json.Marshal(updateMsg)
+	if marshalErr != nil {
+		log.Printf("WS Error: Failed to marshal read_receipt_update: %v", marshalErr)
+		return
+	}
+	// Send update to original sender (queued if they just disconnected)
+	c.Hub.SendOrQueue(msg.SenderID, jsonMsg)
+	log.Printf("Sent read receipt update for %d messages of sender %d from reader %d", len(readIDs), msg.SenderID, c.UserID)
+}
+
+// handleContactCard stores a shared contact card as a message and delivers it to the recipient
+func handleContactCard(c *ws.Context, unarchive bool) {
+	var msg ContactCardRequest
+	if err := json.Unmarshal(c.Message, &msg); err != nil {
+		log.Printf("WS Error: Failed to unmarshal contact_card: %v. Payload: %s", err, string(c.Message))
+		return
+	}
+	// Basic validation
+	if msg.RecipientID <= 0 || msg.UserID <= 0 {
+		log.Printf("WS Warning: Invalid contact_card from %s (ID: %d): RecipientID=%d, UserID=%d", c.Username, c.UserID, msg.RecipientID, msg.UserID)
+		return
+	}
+	// Cards are private messages, so they count against the send limit of quarantined accounts
+	if err := checkQuarantineSend(c.Store, c.UserID); err != nil {
+		log.Printf("WS Warning: Quarantined user %s (ID: %d) reached the send limit", c.Username, c.UserID)
+		sendMessageNack(c.Client, "", ackStatusRejected, err.Error())
+		return
+	}
+	// 1. Resolve the shared user so the card always reflects an existing account
+	sharedUser, dbErr := c.Store.GetUserByID(context.Background(), msg.UserID)
+	if dbErr != nil {
+		log.Printf("WS Warning: contact_card from %d references unknown user %d: %v", c.UserID, msg.UserID, dbErr)
+		return
+	}
+	card := ContactCard{UserID: sharedUser.ID, Username: sharedUser.Username}
+	// 2. Store the card as the message content so it is part of the history
+	cardJSON, marshalErr := json.Marshal(card)
+	if marshalErr != nil {
+		log.Printf("WS Error: Failed to marshal contact card: %v", marshalErr)
+		return
+	}
+	storedMsg, dbErr := c.Store.CreateMessage(context.Background(), db.CreateMessageParams{
+		SenderID:    c.UserID,
+		ReceiverID:  msg.RecipientID,
+		Content:     string(cardJSON),
+		ContentType: contentTypeContactCard,
+	})
+	if dbErr != nil {
+		log.Printf("WS Error: Failed to store contact_card from %d to %d: %v", c.UserID, msg.RecipientID, dbErr)
+		return
+	}
+	unarchiveOnIncomingMessage(c.Store, c.Hub, msg.RecipientID, c.UserID, unarchive)
+	// 3. Deliver to the recipient if online
+	sendContactCard(c.Hub, msg.RecipientID, ContactCardMessage{
+		Type:           "contact_card",
+		SenderID:       c.UserID,
+		SenderUsername: c.Username,
+		Card:           card,
+		CreatedAt:      storedMsg.CreatedAt,
+	})
+	log.Printf("Contact card for user %d sent from %d to %d", card.UserID, c.UserID, msg.RecipientID)
+}
+
+// handlePing records the latency the client measured and replies with a pong on the connection
+func handlePing(c *ws.Context) {
+	var msg PingMessage
+	if err := json.Unmarshal(c.Message, &msg); err != nil {
+		log.Printf("WS Error: Failed to unmarshal ping: %v. Payload: %s", err, string(c.Message))
+		return
+	}
+	// Record the latency the client measured for its previous ping
+	if msg.LastRttMs > 0 {
+		c.Hub.SetLatency(c.Client, time.Duration(msg.LastRttMs*float64(time.Millisecond)))
+	}
+	// Reply on this connection only
+	pongMsg := PongMessage{
+		Type:             "pong",
+		ClientTime:       msg.ClientTime,
+		ServerReceivedAt: c.ReceivedAt,
+		CreatedAt:        time.Now().UTC(),
+	}
+	jsonMsg, marshalErr := json.Marshal(pongMsg)
+	if marshalErr != nil {
+		log.Printf("WS Error: Failed to marshal pong: %v", marshalErr)
+		return
+	}
+	if !c.Client.Send(jsonMsg) {
+		log.Printf("WS Error: Failed to send pong to user %d", c.UserID)
+	}
+}
+
+// handleOffer forwards a WebRTC offer to the recipient's connections
+func handleOffer(c *ws.Context) {
+	var msg OfferMessage
+	if err := json.Unmarshal(c.Message, &msg); err != nil {
+		log.Printf("WS Error: Failed to unmarshal 'offer' message from %s (ID: %d): %v. Payload: %s", c.Username, c.UserID, err, string(c.Message))
+		return
+	}
+
+	// Basic validation: Ensure a recipient is specified
+	if msg.ReceiverID <= 0 {
+		log.Printf("WS Warning: Invalid 'offer' message from %s (ID: %d): Missing or invalid ReceiverID=%d", c.Username, c.UserID, msg.ReceiverID)
+		return
+	}
+
+	// Stamp the authenticated sender and server time; the WebRTC payload itself is forwarded untouched
+	msg.SenderID = c.UserID
+	msg.CreatedAt = time.Now().UTC()
+	jsonMsg, marshalErr := json.Marshal(msg)
+	if marshalErr != nil {
+		log.Printf("WS Error: Failed to marshal outgoing 'offer' message: %v", marshalErr)
+		return
+	}
+
+	// Get recipient's connections
+	recipientClients := c.Hub.GetUserClients(msg.ReceiverID)
+	if len(recipientClients) == 0 {
+		log.Printf("WS Info: Recipient %d for 'offer' message from %d is offline or has no connections.", msg.ReceiverID, c.UserID)
+		return // Skip if recipient is not connected
+	}
+
+	// Forward the stamped message to the recipient
+	log.Printf("Forwarding 'offer' message from %d (%s) to %d (%d connections)", c.UserID, c.Username, msg.ReceiverID, len(recipientClients))
+	for _, recipientClient := range recipientClients {
+		if !recipientClient.Send(jsonMsg) {
+			log.Printf("WS Error: Failed to forward 'offer' message to user %d client %p", msg.ReceiverID, recipientClient)
+			// The client was closed or is too slow; its read loop handles the cleanup
+		}
+	}
+}
+
+// handleIceCandidate forwards a WebRTC ICE candidate to the recipient's connections
+func handleIceCandidate(c *ws.Context) {
+	var msg IceCandidateMessage
+	if err := json.Unmarshal(c.Message, &msg); err != nil {
+		log.Printf("WS Error: Failed to unmarshal 'ice-candidate' message from %s (ID: %d): %v. Payload: %s", c.Username, c.UserID, err, string(c.Message))
+		return
+	}
+
+	// Basic validation: Ensure a recipient is specified
+	if msg.ReceiverID <= 0 {
+		log.Printf("WS Warning: Invalid 'ice-candidate' message from %s (ID: %d): Missing or invalid ReceiverID=%d", c.Username, c.UserID, msg.ReceiverID)
+		return
+	}
+
+	// Stamp the authenticated sender and server time; the WebRTC payload itself is forwarded untouched
+	msg.SenderID = c.UserID
+	msg.CreatedAt = time.Now().UTC()
+	jsonMsg, marshalErr := json.Marshal(msg)
+	if marshalErr != nil {
+		log.Printf("WS Error: Failed to marshal outgoing 'ice-candidate' message: %v", marshalErr)
+		return
+	}
+
+	// Get recipient's connections
+	recipientClients := c.Hub.GetUserClients(msg.ReceiverID)
+	if len(recipientClients) == 0 {
+		log.Printf("WS Info: Recipient %d for 'ice-candidate' message from %d is offline or has no connections.", msg.ReceiverID, c.UserID)
+		return // Skip if recipient is not connected
+	}
+
+	// Forward the stamped message to the recipient
+	log.Printf("Forwarding 'ice-candidate' message from %d (%s) to %d (%d connections)", c.UserID, c.Username, msg.ReceiverID, len(recipientClients))
+	for _, recipientClient := range recipientClients {
+		if !recipientClient.Send(jsonMsg) {
+			log.Printf("WS Error: Failed to forward 'ice-candidate' message to user %d client %p", msg.ReceiverID, recipientClient)
+			// The client was closed or is too slow; its read loop handles the cleanup
+		}
+	}
+}
+
+// handleHangup forwards the end of a call to the recipient's connections
+func handleHangup(c *ws.Context) {
+	var msg HangupMessage
+	if err := json.Unmarshal(c.Message, &msg); err != nil {
+		log.Printf("WS Error: Failed to unmarshal 'hangup' message from %s (ID: %d): %v. Payload: %s", c.Username, c.UserID, err, string(c.Message))
+		return
+	}
+
+	// Basic validation: Ensure a recipient is specified
+	if msg.ReceiverID <= 0 {
+		log.Printf("WS Warning: Invalid 'hangup' message from %s (ID: %d): Missing or invalid ReceiverID=%d", c.Username, c.UserID, msg.ReceiverID)
+		return
+	}
+
+	// Stamp the authenticated sender and server time; the WebRTC payload itself is forwarded untouched
+	msg.SenderID = c.UserID
+	msg.CreatedAt = time.Now().UTC()
+	jsonMsg, marshalErr := json.Marshal(msg)
+	if marshalErr != nil {
+		log.Printf("WS Error: Failed to marshal outgoing 'hangup' message: %v", marshalErr)
+		return
+	}
+
+	// Get recipient's connections
+	recipientClients := c.Hub.GetUserClients(msg.ReceiverID)
+	if len(recipientClients) == 0 {
+		log.Printf("WS Info: Recipient %d for 'hangup' message from %d is offline or has no connections.", msg.ReceiverID, c.UserID)
+		return // Skip if recipient is not connected
+	}
+
+	// Forward the stamped message to the recipient
+	log.Printf("Forwarding 'hangup' message from %d (%s) to %d (%d connections)", c.UserID, c.Username, msg.ReceiverID, len(recipientClients))
+	for _, recipientClient := range recipientClients {
+		if !recipientClient.Send(jsonMsg) {
+			log.Printf("WS Error: Failed to forward 'hangup' message to user %d client %p", msg.ReceiverID, recipientClient)
+			// The client was closed or is too slow; its read loop handles the cleanup
+		}
+	}
+}
+
+// handleAnswer forwards a WebRTC answer to the recipient's connections
+func handleAnswer(c *ws.Context) {
+	var msg AnswerMessage
+	if err := json.Unmarshal(c.Message, &msg); err != nil {
+		log.Printf("WS Error: Failed to unmarshal 'answer' message from %s (ID: %d): %v. Payload: %s", c.Username, c.UserID, err, string(c.Message))
+		return
+	}
+
+	// Basic validation: Ensure a recipient is specified
+	if msg.ReceiverID <= 0 {
+		log.Printf("WS Warning: Invalid 'answer' message from %s (ID: %d): Missing or invalid ReceiverID=%d", c.Username, c.UserID, msg.ReceiverID)
+		return
+	}
+
+	// Stamp the authenticated sender and server time; the WebRTC payload itself is forwarded untouched
+	msg.SenderID = c.UserID
+	msg.CreatedAt = time.Now().UTC()
+	jsonMsg, marshalErr := json.Marshal(msg)
+	if marshalErr != nil {
+		log.Printf("WS Error: Failed to marshal outgoing 'answer' message: %v", marshalErr)
+		return
+	}
+
+	// Get recipient's connections
+	recipientClients := c.Hub.GetUserClients(msg.ReceiverID)
+	if len(recipientClients) == 0 {
+		log.Printf("WS Info: Recipient %d for 'answer' message from %d is offline or has no connections.", msg.ReceiverID, c.UserID)
+		return // Skip if recipient is not connected
+	}
+
+	// Forward the stamped message to the recipient
+	log.Printf("Forwarding 'answer' message from %d (%s) to %d (%d connections)", c.UserID, c.Username, msg.ReceiverID, len(recipientClients))
+	for _, recipientClient := range recipientClients {
+		if !recipientClient.Send(jsonMsg) {
+			log.Printf("WS Error: Failed to forward 'answer' message to user %d client %p", msg.ReceiverID, recipientClient)
+			// The client was closed or is too slow; its read loop handles the cleanup
+		}
+	}
+}
@@ -0,0 +1,22 @@
+package main
+
+import (
+	_ "embed"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ws_schema.json describes every WebSocket message struct (fields, JSON types and direction), so
+// client SDKs in other languages can generate their models from a running server. It is generated
+// from the structs marked with a //wsschema: directive; run go generate after changing one.
+
+//go:generate go run ./cmd/wsschema -o ws_schema.json . hub token db/sqlc
+
+//go:embed ws_schema.json
+var wsSchema []byte
+
+// wsSchemaHandler serves the generated WebSocket message schema
+func wsSchemaHandler(c *gin.Context) {
+	c.Data(http.StatusOK, "application/json; charset=utf-8", wsSchema)
+}